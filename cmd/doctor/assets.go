@@ -0,0 +1,75 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"welcomebot/internal/audio"
+	"welcomebot/internal/worker"
+)
+
+// runAssetAudit audits every guide's audio and images, as installed under
+// audio/ and assets/images/onboarding/ of the working directory, and
+// prints a checklist per guide. It returns the exit code: 0 if every file
+// passed, 1 if any failed, 2 if the audit couldn't run.
+func runAssetAudit(w io.Writer, limits audio.Limits) int {
+	audits, err := worker.AuditAssets(limits)
+	if err != nil {
+		fmt.Fprintf(w, "FAIL  list guides: %v\n", err)
+		return 2
+	}
+	if len(audits) == 0 {
+		fmt.Fprintln(w, "FAIL  no guides installed")
+		return 1
+	}
+
+	if !printAudits(w, audits) {
+		return 1
+	}
+	return 0
+}
+
+// printAudits writes each guide's checklist to w. It returns false if any
+// guide failed.
+func printAudits(w io.Writer, audits []worker.GuideAudit) bool {
+	ok := true
+	for _, audit := range audits {
+		if !audit.OK() {
+			ok = false
+		}
+		fmt.Fprintf(w, "%s\n", audit.Guide)
+		if audit.Err != nil {
+			fmt.Fprintf(w, "  FAIL  manifest  %v\n", audit.Err)
+			continue
+		}
+
+		for _, c := range audit.Checks {
+			label := fmt.Sprintf("%-7s %-5s %-24s", c.Key, c.Kind, c.File)
+			switch {
+			case c.Err != nil:
+				fmt.Fprintf(w, "  FAIL  %s  %v\n", label, c.Err)
+			case c.Absent && c.Locale != "":
+				fmt.Fprintf(w, "  -     %s  not installed (falls back)\n", label)
+			case c.Absent:
+				fmt.Fprintf(w, "  -     %s  not installed (optional)\n", label)
+			case c.Kind == worker.AssetAudio:
+				fmt.Fprintf(w, "  ok    %s  %9s  %s\n", label, formatSize(c.Size), c.Duration.Round(100*time.Millisecond))
+			default:
+				fmt.Fprintf(w, "  ok    %s  %9s  %dx%d\n", label, formatSize(c.Size), c.Width, c.Height)
+			}
+		}
+	}
+	return ok
+}
+
+// formatSize formats a byte count for the checklist, e.g. "1.4MB".
+func formatSize(n int64) string {
+	switch {
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1fMB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1fKB", float64(n)/(1<<10))
+	}
+	return fmt.Sprintf("%dB", n)
+}
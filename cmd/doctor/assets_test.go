@@ -0,0 +1,43 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"welcomebot/internal/worker"
+)
+
+func TestPrintAudits(t *testing.T) {
+	var out bytes.Buffer
+	ok := printAudits(&out, []worker.GuideAudit{
+		{Guide: "kk", Checks: []worker.AssetCheck{
+			{Key: "welcome", Kind: worker.AssetAudio, File: "greeting.dca", Absent: true},
+			{Key: "step1", Kind: worker.AssetAudio, File: "1-intro.dca", Size: 3 << 20, Duration: 62 * time.Second},
+			{Key: "step1", Kind: worker.AssetAudio, File: "en/1-intro.dca", Locale: "en", Absent: true},
+			{Key: "step1", Kind: worker.AssetImage, File: "step1.png", Size: 2048, Width: 640, Height: 480},
+		}},
+		{Guide: "yy", Checks: []worker.AssetCheck{
+			{Key: "step2", Kind: worker.AssetImage, File: "step2.png", Err: errors.New("no such file")},
+		}},
+	})
+	if ok {
+		t.Error("expected a failed file to fail the audit")
+	}
+
+	got := out.String()
+	for _, want := range []string{
+		"kk\n",
+		"not installed (optional)",
+		"en/1-intro.dca            not installed (falls back)",
+		"3.0MB  1m2s",
+		"2.0KB  640x480",
+		"yy\n  FAIL  step2   image step2.png",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected %q in\n%s", want, got)
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"welcomebot/internal/core/cache"
+	"welcomebot/internal/core/database"
+	"welcomebot/internal/core/queue"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// check is one dependency to exercise. run does a real round trip and
+// returns the first thing that went wrong.
+type check struct {
+	name string
+	run  func(ctx context.Context) error
+}
+
+// runChecks runs each check with its own timeout, reporting its latency or
+// error to w. It returns false if any check failed.
+func runChecks(ctx context.Context, w io.Writer, timeout time.Duration, checks []check) bool {
+	ok := true
+	for _, c := range checks {
+		checkCtx, cancel := context.WithTimeout(ctx, timeout)
+		start := time.Now()
+		err := c.run(checkCtx)
+		elapsed := time.Since(start).Round(time.Millisecond)
+		cancel()
+
+		if err != nil {
+			ok = false
+			fmt.Fprintf(w, "FAIL  %-9s %8s  %v\n", c.name, elapsed, err)
+			continue
+		}
+		fmt.Fprintf(w, "ok    %-9s %8s\n", c.name, elapsed)
+	}
+	return ok
+}
+
+// maxRunIDLen is the width of doctor_checks.id.
+const maxRunIDLen = 64
+
+// newRunID names this run's scratch row, keys and consumer. The pid helps
+// find a stuck run; the random part keeps runs on different hosts apart.
+// Hostnames are left out since a pod name alone can fill the column.
+func newRunID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return fmt.Sprintf("doctor-%d-%s", os.Getpid(), hex.EncodeToString(b))
+}
+
+// checkDatabase writes a row to doctor_checks on the primary, reads it back
+// and deletes it.
+func checkDatabase(ctx context.Context, db database.Client, id string) error {
+	if _, err := db.Exec(ctx, `INSERT INTO doctor_checks (id) VALUES ($1)`, id); err != nil {
+		return fmt.Errorf("write: %w (have migrations been applied?)", err)
+	}
+	defer db.Exec(context.Background(), `DELETE FROM doctor_checks WHERE id = $1`, id)
+
+	var got string
+	if err := db.QueryRow(ctx, `SELECT id FROM doctor_checks WHERE id = $1`, id).Scan(&got); err != nil {
+		return fmt.Errorf("read: %w", err)
+	}
+	if got != id {
+		return fmt.Errorf("read back %q, wrote %q", got, id)
+	}
+	return nil
+}
+
+// checkCache sets, gets and deletes a short-lived key. A cache that fell
+// back to memory still answers, so degraded mode counts as a failure.
+func checkCache(ctx context.Context, c cache.Client, key string) error {
+	if err := c.Set(ctx, key, "ok", time.Minute); err != nil {
+		return fmt.Errorf("set: %w", err)
+	}
+	got, err := c.Get(ctx, key)
+	if err != nil {
+		return fmt.Errorf("get: %w", err)
+	}
+	if got != "ok" {
+		return fmt.Errorf("got %q back, set %q", got, "ok")
+	}
+	if err := c.Delete(ctx, key); err != nil {
+		return fmt.Errorf("delete: %w", err)
+	}
+	if c.Degraded() {
+		return errors.New("redis unreachable, cache is running in memory")
+	}
+	return nil
+}
+
+// checkQueue enqueues a task on q, dequeues it again and acks it. q must
+// use a list of its own so real tasks are never taken.
+func checkQueue(ctx context.Context, q queue.Client, id string) error {
+	task := queue.Task{ID: id, Type: "doctor", CreatedAt: time.Now()}
+	if err := q.Enqueue(ctx, task); err != nil {
+		return fmt.Errorf("enqueue: %w", err)
+	}
+
+	got, err := q.Dequeue(ctx, time.Second)
+	if err != nil {
+		return fmt.Errorf("dequeue: %w", err)
+	}
+	if got == nil {
+		return errors.New("enqueued task was not dequeued")
+	}
+	if err := q.Ack(ctx, got); err != nil {
+		return fmt.Errorf("ack: %w", err)
+	}
+	if got.ID != id {
+		return fmt.Errorf("dequeued task %q, enqueued %q", got.ID, id)
+	}
+	return nil
+}
+
+// checkDiscord fetches the bot's own user, which needs a valid token but
+// no gateway connection.
+func checkDiscord(ctx context.Context, s *discordgo.Session) error {
+	user, err := s.User("@me", discordgo.WithContext(ctx))
+	if err != nil {
+		return fmt.Errorf("get bot user: %w", err)
+	}
+	if user.ID == "" {
+		return errors.New("bot user has no ID")
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"welcomebot/internal/fakes"
+)
+
+func TestRunChecks(t *testing.T) {
+	var out bytes.Buffer
+	ok := runChecks(context.Background(), &out, time.Second, []check{
+		{"postgres", func(ctx context.Context) error { return nil }},
+		{"redis", func(ctx context.Context) error { return errors.New("connection refused") }},
+		{"discord", func(ctx context.Context) error {
+			if _, ok := ctx.Deadline(); !ok {
+				return errors.New("no timeout")
+			}
+			return nil
+		}},
+	})
+	if ok {
+		t.Error("expected a failed check to fail the run")
+	}
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("expected a line per check, got %q", out.String())
+	}
+	if !strings.HasPrefix(lines[0], "ok    postgres") {
+		t.Errorf("got %q", lines[0])
+	}
+	if !strings.HasPrefix(lines[1], "FAIL  redis") || !strings.HasSuffix(lines[1], "connection refused") {
+		t.Errorf("got %q", lines[1])
+	}
+	if !strings.HasPrefix(lines[2], "ok    discord") {
+		t.Errorf("expected each check to get a timeout, got %q", lines[2])
+	}
+}
+
+func TestCheckCache(t *testing.T) {
+	ctx := context.Background()
+	c := fakes.NewCache()
+
+	if err := checkCache(ctx, c, "welcomebot:doctor:run-1"); err != nil {
+		t.Fatalf("checkCache: %v", err)
+	}
+	if exists, _ := c.Exists(ctx, "welcomebot:doctor:run-1"); exists {
+		t.Error("expected the scratch key to be deleted")
+	}
+}
+
+func TestCheckQueue(t *testing.T) {
+	q := fakes.NewQueue()
+
+	if err := checkQueue(context.Background(), q, "run-1"); err != nil {
+		t.Fatalf("checkQueue: %v", err)
+	}
+	if len(q.Tasks()) != 0 || len(q.Pending()) != 0 {
+		t.Errorf("expected the task to be dequeued and acked, got %v queued and %v pending", q.Tasks(), q.Pending())
+	}
+}
+
+func TestCheckDiscord(t *testing.T) {
+	d := fakes.NewDiscord()
+	if err := checkDiscord(context.Background(), d.Session()); err != nil {
+		t.Fatalf("checkDiscord: %v", err)
+	}
+
+	d.SetNotFound("users/@me")
+	if err := checkDiscord(context.Background(), d.Session()); err == nil {
+		t.Error("expected a failed API call to fail the check")
+	}
+}
+
+func TestNewRunIDFitsColumn(t *testing.T) {
+	id := newRunID()
+	if len(id) > maxRunIDLen {
+		t.Errorf("run ID %q is %d bytes, doctor_checks.id holds %d", id, len(id), maxRunIDLen)
+	}
+	if id == newRunID() {
+		t.Error("expected run IDs to differ")
+	}
+}
@@ -0,0 +1,136 @@
+// Command doctor checks that the bot's dependencies are reachable and
+// working, using the same environment as master and the workers. It does a
+// real round trip against each one (a Postgres write and read, a Redis set,
+// get and delete, a queue enqueue and dequeue, and a Discord API call),
+// prints each latency or error and exits non-zero if any check failed.
+//
+// "doctor assets" instead audits every guide's audio and images in the
+// working directory, printing a checklist per guide with sizes and
+// durations, and exits non-zero if any file is missing or broken. It needs
+// no other environment than AUDIO_LIMITS.
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"welcomebot/internal/audio"
+	"welcomebot/internal/core/cache"
+	"welcomebot/internal/core/database"
+	"welcomebot/internal/core/env"
+	"welcomebot/internal/core/queue"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// defaultCheckTimeout bounds each check, connecting included.
+const defaultCheckTimeout = 10 * time.Second
+
+func main() {
+	if len(os.Args) > 1 && os.Args[1] == "assets" {
+		// The same limits the workers apply at startup
+		limits, err := audio.ParseLimits(env.Get("AUDIO_LIMITS", ""))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Invalid AUDIO_LIMITS: %v\n", err)
+			os.Exit(2)
+		}
+		os.Exit(runAssetAudit(os.Stdout, limits))
+	}
+
+	timeout := defaultCheckTimeout
+	if value := env.Get("DOCTOR_TIMEOUT", ""); value != "" {
+		var err error
+		timeout, err = time.ParseDuration(value)
+		if err != nil || timeout <= 0 {
+			fmt.Fprintf(os.Stderr, "Invalid DOCTOR_TIMEOUT: %q\n", value)
+			os.Exit(2)
+		}
+	}
+
+	botToken := env.Get("DISCORD_BOT_TOKEN", "")
+	if botToken == "" {
+		fmt.Fprintf(os.Stderr, "DISCORD_BOT_TOKEN is required\n")
+		os.Exit(2)
+	}
+
+	cacheKey, err := cache.ParseEncryptionKey(env.Get("CACHE_ENCRYPTION_KEY", ""))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid CACHE_ENCRYPTION_KEY: %v\n", err)
+		os.Exit(2)
+	}
+
+	// Scratch keys and rows are named after this run so concurrent runs
+	// can't see each other's
+	runID := newRunID()
+
+	dbCfg := database.Config{
+		Host:     env.Get("POSTGRES_HOST", "localhost"),
+		Port:     env.Get("POSTGRES_PORT", "5432"),
+		User:     env.Get("POSTGRES_USER", "welcomebot"),
+		Password: env.Get("POSTGRES_PASSWORD", ""),
+		Database: env.Get("POSTGRES_DB", "welcomebot"),
+		SSLMode:  env.Get("POSTGRES_SSLMODE", "disable"),
+
+		ReplicaHosts: env.ReplicaHosts(),
+	}
+	cacheCfg := cache.Config{
+		ClusterAddrs:  env.ClusterAddrs(),
+		SentinelAddrs: env.SentinelAddrs(),
+		MasterName:    env.Get("REDIS_MASTER_NAME", ""),
+		Addr:          env.Get("REDIS_ADDR", "localhost:6379"),
+		Password:      env.Get("REDIS_PASSWORD", ""),
+		DB:            0,
+		EncryptionKey: cacheKey,
+	}
+	// A list of the doctor's own, never the task queue
+	queueCfg := queue.Config{
+		ClusterAddrs:  env.ClusterAddrs(),
+		SentinelAddrs: env.SentinelAddrs(),
+		MasterName:    env.Get("REDIS_MASTER_NAME", ""),
+		RedisAddr:     env.Get("REDIS_ADDR", "localhost:6379"),
+		RedisPassword: env.Get("REDIS_PASSWORD", ""),
+		RedisDB:       0,
+		QueueKey:      "welcomebot:doctor:" + runID,
+		ConsumerID:    runID,
+	}
+
+	checks := []check{
+		{"postgres", func(ctx context.Context) error {
+			db, err := database.New(dbCfg)
+			if err != nil {
+				return fmt.Errorf("connect: %w", err)
+			}
+			defer db.Close()
+			return checkDatabase(ctx, db, runID)
+		}},
+		{"redis", func(ctx context.Context) error {
+			c, err := cache.New(cacheCfg)
+			if err != nil {
+				return fmt.Errorf("connect: %w", err)
+			}
+			defer c.Close()
+			return checkCache(ctx, c, "welcomebot:doctor:"+runID)
+		}},
+		{"queue", func(ctx context.Context) error {
+			q, err := queue.New(queueCfg)
+			if err != nil {
+				return fmt.Errorf("connect: %w", err)
+			}
+			defer q.Close()
+			return checkQueue(ctx, q, runID)
+		}},
+		{"discord", func(ctx context.Context) error {
+			s, err := discordgo.New("Bot " + botToken)
+			if err != nil {
+				return fmt.Errorf("create session: %w", err)
+			}
+			return checkDiscord(ctx, s)
+		}},
+	}
+
+	if !runChecks(context.Background(), os.Stdout, timeout, checks) {
+		os.Exit(1)
+	}
+}
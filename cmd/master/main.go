@@ -1,17 +1,26 @@
 package main
 
 import (
+	"context"
 	"log"
+	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
+	_ "time/tzdata" // Onboarding schedules load timezones; the runtime image has no zoneinfo
 
+	"welcomebot/internal/audio"
 	"welcomebot/internal/bot"
 	"welcomebot/internal/core/cache"
 	"welcomebot/internal/core/database"
+	"welcomebot/internal/core/env"
 	"welcomebot/internal/core/logger"
+	"welcomebot/internal/core/metrics"
+	"welcomebot/internal/core/notify"
 	"welcomebot/internal/core/queue"
 	"welcomebot/internal/features/botinfo"
+	"welcomebot/internal/features/broadcast"
 	"welcomebot/internal/features/gender"
 	"welcomebot/internal/features/initialization"
 	"welcomebot/internal/features/language"
@@ -21,41 +30,61 @@ import (
 	"welcomebot/internal/features/welcome"
 	"welcomebot/internal/features/agerange"
 	"welcomebot/internal/features/voicetype"
-	"welcomebot/internal/features/otherroles1"
-	"welcomebot/internal/features/otherroles2"
+	"welcomebot/internal/features/otherroles"
+	"welcomebot/internal/onboard"
+
+	"github.com/bwmarrin/discordgo"
 )
 
 func main() {
+	logLevels, err := logger.ParseLevels(env.Get("LOG_LEVELS", ""))
+	if err != nil {
+		log.Fatalf("Invalid LOG_LEVELS: %v", err)
+	}
+
+	cacheKey, err := cache.ParseEncryptionKey(env.Get("CACHE_ENCRYPTION_KEY", ""))
+	if err != nil {
+		log.Fatalf("Invalid CACHE_ENCRYPTION_KEY: %v", err)
+	}
+
 	// Load configuration from environment
 	cfg := bot.Config{
-		Token: getEnv("DISCORD_BOT_TOKEN", ""),
+		Token: env.Get("DISCORD_BOT_TOKEN", ""),
 		Database: database.Config{
-			Host:     getEnv("POSTGRES_HOST", "localhost"),
-			Port:     getEnv("POSTGRES_PORT", "5432"),
-			User:     getEnv("POSTGRES_USER", "welcomebot"),
-			Password: getEnv("POSTGRES_PASSWORD", ""),
-			Database: getEnv("POSTGRES_DB", "welcomebot"),
-			SSLMode:  getEnv("POSTGRES_SSLMODE", "disable"),
+			Host:     env.Get("POSTGRES_HOST", "localhost"),
+			Port:     env.Get("POSTGRES_PORT", "5432"),
+			User:     env.Get("POSTGRES_USER", "welcomebot"),
+			Password: env.Get("POSTGRES_PASSWORD", ""),
+			Database: env.Get("POSTGRES_DB", "welcomebot"),
+			SSLMode:  env.Get("POSTGRES_SSLMODE", "disable"),
+
+			ReplicaHosts: env.ReplicaHosts(),
 		},
 		Cache: cache.Config{
-			SentinelAddrs: getSentinelAddrs(),
-			MasterName:    getEnv("REDIS_MASTER_NAME", ""),
-			Addr:          getEnv("REDIS_ADDR", "localhost:6379"),
-			Password:      getEnv("REDIS_PASSWORD", ""),
+			ClusterAddrs:  env.ClusterAddrs(),
+			SentinelAddrs: env.SentinelAddrs(),
+			MasterName:    env.Get("REDIS_MASTER_NAME", ""),
+			Addr:          env.Get("REDIS_ADDR", "localhost:6379"),
+			Password:      env.Get("REDIS_PASSWORD", ""),
 			DB:            0,
+			EncryptionKey: cacheKey,
 		},
 		Queue: queue.Config{
-			SentinelAddrs: getSentinelAddrs(),
-			MasterName:    getEnv("REDIS_MASTER_NAME", ""),
-			RedisAddr:     getEnv("REDIS_ADDR", "localhost:6379"),
-			RedisPassword: getEnv("REDIS_PASSWORD", ""),
+			ClusterAddrs:  env.ClusterAddrs(),
+			SentinelAddrs: env.SentinelAddrs(),
+			MasterName:    env.Get("REDIS_MASTER_NAME", ""),
+			RedisAddr:     env.Get("REDIS_ADDR", "localhost:6379"),
+			RedisPassword: env.Get("REDIS_PASSWORD", ""),
 			RedisDB:       0,
 			QueueKey:      "welcomebot:tasks",
+			MaxDepth:      int64(env.Int("QUEUE_MAX_DEPTH", 0)),
 		},
 		Logger: logger.Config{
-			Level:  getEnv("LOG_LEVEL", "info"),
-			Format: getEnv("LOG_FORMAT", "json"),
+			Level:  env.Get("LOG_LEVEL", "info"),
+			Format: env.Get("LOG_FORMAT", "json"),
+			Levels: logLevels,
 		},
+		SlowInteraction: time.Duration(env.Int("SLOW_INTERACTION_MS", int(bot.DefaultSlowInteraction/time.Millisecond))) * time.Millisecond,
 	}
 
 	if cfg.Token == "" {
@@ -83,6 +112,7 @@ func main() {
 
 	// 2. Bot Info feature
 	botinfoFeature, err := botinfo.New(botinfo.Dependencies{
+		I18n:   deps.I18n,
 		Logger: deps.Logger,
 	})
 	if err != nil {
@@ -92,6 +122,21 @@ func main() {
 		log.Fatalf("Failed to register botinfo feature: %v", err)
 	}
 
+	// 2.5 Broadcast feature (bot owner only)
+	broadcastFeature, err := broadcast.New(broadcast.Dependencies{
+		DB:       deps.DB,
+		Cache:    deps.Cache,
+		I18n:     deps.I18n,
+		Logger:   deps.Logger,
+		OwnerIDs: env.List("BOT_OWNER_IDS"),
+	})
+	if err != nil {
+		log.Fatalf("Failed to create broadcast feature: %v", err)
+	}
+	if err := bot.Registry().Register(broadcastFeature); err != nil {
+		log.Fatalf("Failed to register broadcast feature: %v", err)
+	}
+
 	// 3. Language feature
 	languageFeature, err := language.New(language.Dependencies{
 		I18n:   deps.I18n,
@@ -132,6 +177,36 @@ func main() {
 		log.Fatalf("Failed to register selfintro feature: %v", err)
 	}
 
+	// Worker -> master event queue (failure reports)
+	eventsQueue, err := queue.New(queue.Config{
+		ClusterAddrs:  cfg.Queue.ClusterAddrs,
+		SentinelAddrs: cfg.Queue.SentinelAddrs,
+		MasterName:    cfg.Queue.MasterName,
+		RedisAddr:     cfg.Queue.RedisAddr,
+		RedisPassword: cfg.Queue.RedisPassword,
+		RedisDB:       cfg.Queue.RedisDB,
+		QueueKey:      queue.MasterQueueKey,
+		ConsumerID:    "master",
+	})
+	if err != nil {
+		log.Fatalf("Failed to create events queue: %v", err)
+	}
+	if recovered, err := eventsQueue.Recover(context.Background()); err != nil {
+		deps.Logger.Error("Failed to recover unfinished worker events", "error", err)
+	} else if recovered > 0 {
+		deps.Logger.Warn("Requeued unfinished worker events from previous run", "count", recovered)
+	}
+
+	// Extra destinations for failure and completion notifications
+	var notifier notify.Notifier
+	if spec := env.Get("NOTIFY_ROUTES", ""); spec != "" {
+		router, err := notify.ParseRoutes(spec, bot.Session(), &http.Client{Timeout: 10 * time.Second})
+		if err != nil {
+			log.Fatalf("Invalid NOTIFY_ROUTES: %v", err)
+		}
+		notifier = router
+	}
+
 	// 3.7 Welcome feature
 	welcomeFeature, err := welcome.New(welcome.Dependencies{
 		DB:      deps.DB,
@@ -140,6 +215,19 @@ func main() {
 		I18n:    deps.I18n,
 		Logger:  deps.Logger,
 		Session: bot.Session(),
+		Events:  eventsQueue,
+
+		Notifier: notifier,
+
+		MaxSessionsPerGuild: env.Int("MAX_SESSIONS_PER_GUILD", welcome.DefaultMaxSessionsPerGuild),
+		MaxAttemptsPerUser:  env.Int("MAX_ONBOARDING_ATTEMPTS", welcome.DefaultMaxAttemptsPerUser),
+		AttemptWindow:       time.Duration(env.Int("ONBOARDING_ATTEMPT_WINDOW_HOURS", int(welcome.DefaultAttemptWindow/time.Hour))) * time.Hour,
+		ResumeExpiry:        time.Duration(env.Int("ONBOARDING_RESUME_HOURS", 0)) * time.Hour,
+		WizardTTL:           time.Duration(env.Int("WELCOME_WIZARD_TTL_MINUTES", int(welcome.DefaultWizardTTL/time.Minute))) * time.Minute,
+
+		NewAudioPlayer: func(ctx context.Context, s *discordgo.Session) onboard.AudioPlayer {
+			return audio.NewDCAPlayer(ctx, s, deps.Logger)
+		},
 	})
 	if err != nil {
 		log.Fatalf("Failed to create welcome feature: %v", err)
@@ -148,6 +236,14 @@ func main() {
 		log.Fatalf("Failed to register welcome feature: %v", err)
 	}
 
+	// Giving a role on join needs the privileged Server Members intent, so
+	// it is only registered when asked for
+	if env.Get("JOIN_ROLE_ENABLED", "") == "true" {
+		if err := bot.Registry().Register(welcomeFeature.JoinRoles()); err != nil {
+			log.Fatalf("Failed to register join role feature: %v", err)
+		}
+	}
+
 	// 3.8 Age Range feature
 	ageRangeFeature, err := agerange.New(agerange.Dependencies{
 		DB:     deps.DB,
@@ -176,32 +272,18 @@ func main() {
 		log.Fatalf("Failed to register voice type feature: %v", err)
 	}
 
-	// 3.10 Other Roles 1 feature
-	otherRoles1Feature, err := otherroles1.New(otherroles1.Dependencies{
-		DB:     deps.DB,
-		Cache:  deps.Cache,
-		I18n:   deps.I18n,
-		Logger: deps.Logger,
-	})
-	if err != nil {
-		log.Fatalf("Failed to create other roles 1 feature: %v", err)
-	}
-	if err := bot.Registry().Register(otherRoles1Feature); err != nil {
-		log.Fatalf("Failed to register other roles 1 feature: %v", err)
-	}
-
-	// 3.11 Other Roles 2 feature
-	otherRoles2Feature, err := otherroles2.New(otherroles2.Dependencies{
+	// 3.10 Other Roles feature
+	otherRolesFeature, err := otherroles.New(otherroles.Dependencies{
 		DB:     deps.DB,
 		Cache:  deps.Cache,
 		I18n:   deps.I18n,
 		Logger: deps.Logger,
 	})
 	if err != nil {
-		log.Fatalf("Failed to create other roles 2 feature: %v", err)
+		log.Fatalf("Failed to create other roles feature: %v", err)
 	}
-	if err := bot.Registry().Register(otherRoles2Feature); err != nil {
-		log.Fatalf("Failed to register other roles 2 feature: %v", err)
+	if err := bot.Registry().Register(otherRolesFeature); err != nil {
+		log.Fatalf("Failed to register other roles feature: %v", err)
 	}
 
 	// 4. Initialization feature
@@ -222,6 +304,7 @@ func main() {
 	menuFeature, err := menu.New(menu.Dependencies{
 		Registry: bot.Registry(),
 		Init:     initFeature,
+		Status:   welcomeFeature,
 		I18n:     deps.I18n,
 		Logger:   deps.Logger,
 	})
@@ -237,6 +320,74 @@ func main() {
 		log.Fatalf("Failed to start bot: %v", err)
 	}
 
+	// Preload guild configs so the first onboarding after a deploy doesn't
+	// wait on Postgres. Runs in the background; misses fall back to the DB.
+	if concurrency := env.Int("CACHE_WARMUP_CONCURRENCY", 0); concurrency > 0 {
+		go func() {
+			started := time.Now()
+			warmed, err := welcomeFeature.WarmCache(context.Background(), concurrency)
+			if err != nil {
+				deps.Logger.Warn("cache warm-up failed", "error", err, "warmed", warmed)
+				return
+			}
+			deps.Logger.Info("cache warm-up finished", "warmed", warmed, "duration", time.Since(started))
+		}()
+	}
+
+	// Bring welcome buttons posted by older versions up to date
+	refreshCtx, stopRefresh := context.WithCancel(context.Background())
+	refreshDone := make(chan struct{})
+	go func() {
+		defer close(refreshDone)
+		refreshed, err := welcomeFeature.RefreshOutdatedButtons(refreshCtx)
+		if err != nil {
+			deps.Logger.Warn("welcome button refresh failed", "error", err, "refreshed", refreshed)
+			return
+		}
+		if refreshed > 0 {
+			deps.Logger.Info("refreshed outdated welcome buttons", "refreshed", refreshed)
+		}
+	}()
+
+	// Metrics for autoscaling workers on the task backlog
+	if addr := env.Get("METRICS_ADDR", ""); addr != "" {
+		registry := metrics.NewRegistry()
+		registry.Gauge("welcomebot_queue_depth", "Tasks waiting in the queue.", metrics.Labels{"queue": "tasks"}, queueDepth(deps.Queue))
+		registry.Gauge("welcomebot_queue_depth", "Tasks waiting in the queue.", metrics.Labels{"queue": "events"}, queueDepth(eventsQueue))
+		registry.Histogram("welcomebot_interaction_latency_seconds", "Time from interaction creation to its handler returning.", bot.InteractionLatency())
+		for _, prefix := range cache.Labels() {
+			registry.Counter("welcomebot_cache_hits_total", "Cache lookups that found the key.", metrics.Labels{"prefix": prefix}, cacheHits(deps.Cache.Stats(), prefix))
+			registry.Counter("welcomebot_cache_misses_total", "Cache lookups that missed the key.", metrics.Labels{"prefix": prefix}, cacheMisses(deps.Cache.Stats(), prefix))
+		}
+
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", registry.Handler())
+		metricsServer := &http.Server{Addr: addr, Handler: mux, ReadHeaderTimeout: 5 * time.Second}
+		go func() {
+			if err := metricsServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				deps.Logger.Error("metrics server failed", "error", err)
+			}
+		}()
+		defer metricsServer.Close()
+		deps.Logger.Info("serving metrics", "addr", addr)
+	}
+
+	// Log cache hit rates to help tune TTLs
+	statsCtx, stopStats := context.WithCancel(context.Background())
+	go cache.LogHitRates(statsCtx, deps.Cache.Stats(), deps.Logger.Named("cache"), cache.HitRateInterval)
+
+	// Consume worker events (onboarding failures)
+	eventsCtx, stopEvents := context.WithCancel(context.Background())
+	eventsDone := make(chan struct{})
+	go func() {
+		defer close(eventsDone)
+		welcomeFeature.ConsumeEvents(eventsCtx)
+	}()
+
+	// Fail sessions whose worker died mid-onboarding
+	staleAfter := time.Duration(env.Int("SESSION_HEARTBEAT_STALE_SECONDS", int(welcome.DefaultSessionHeartbeatStale/time.Second))) * time.Second
+	go welcomeFeature.MonitorSessions(eventsCtx, staleAfter)
+
 	deps.Logger.Info("welcomebot Master Bot is running. Press CTRL-C to exit.")
 
 	// Wait for interrupt signal
@@ -246,6 +397,13 @@ func main() {
 
 	// Graceful shutdown
 	deps.Logger.Info("Shutting down...")
+	stopStats()
+	// The refresh stops between guilds; let the edit in hand finish
+	stopRefresh()
+	<-refreshDone
+	// Let the event in hand finish before its queue is closed
+	stopEvents()
+	<-eventsDone
 	if err := bot.Stop(); err != nil {
 		deps.Logger.Error("Error during shutdown", "error", err)
 	}
@@ -260,70 +418,29 @@ func main() {
 	if err := deps.Queue.Close(); err != nil {
 		deps.Logger.Error("Error closing queue", "error", err)
 	}
-}
-
-func getEnv(key, defaultValue string) string {
-	if value := os.Getenv(key); value != "" {
-		return value
-	}
-	return defaultValue
-}
-
-func getSentinelAddrs() []string {
-	addrs := getEnv("REDIS_SENTINEL_ADDRS", "")
-	if addrs == "" {
-		return nil
-	}
-	// Split by comma: "host1:26379,host2:26379"
-	parts := make([]string, 0)
-	for _, addr := range splitAndTrim(addrs, ",") {
-		if addr != "" {
-			parts = append(parts, addr)
-		}
+	if err := eventsQueue.Close(); err != nil {
+		deps.Logger.Error("Error closing events queue", "error", err)
 	}
-	return parts
 }
 
-func splitAndTrim(s, sep string) []string {
-	parts := make([]string, 0)
-	for _, part := range splitString(s, sep) {
-		trimmed := trimString(part)
-		if trimmed != "" {
-			parts = append(parts, trimmed)
-		}
+// cacheHits reads a prefix's cache hits for a metrics counter.
+func cacheHits(stats *cache.Stats, prefix string) metrics.GaugeFunc {
+	return func(ctx context.Context) (float64, error) {
+		return float64(stats.Hits(prefix)), nil
 	}
-	return parts
 }
 
-func splitString(s, sep string) []string {
-	if s == "" {
-		return nil
-	}
-	result := make([]string, 0)
-	current := ""
-	for _, char := range s {
-		if string(char) == sep {
-			result = append(result, current)
-			current = ""
-		} else {
-			current += string(char)
-		}
-	}
-	if current != "" {
-		result = append(result, current)
+// cacheMisses reads a prefix's cache misses for a metrics counter.
+func cacheMisses(stats *cache.Stats, prefix string) metrics.GaugeFunc {
+	return func(ctx context.Context) (float64, error) {
+		return float64(stats.Misses(prefix)), nil
 	}
-	return result
 }
 
-func trimString(s string) string {
-	start := 0
-	end := len(s)
-	for start < end && (s[start] == ' ' || s[start] == '\t' || s[start] == '\n') {
-		start++
+// queueDepth reads a queue's backlog for a metrics gauge.
+func queueDepth(q queue.Client) metrics.GaugeFunc {
+	return func(ctx context.Context) (float64, error) {
+		n, err := q.Depth(ctx)
+		return float64(n), err
 	}
-	for end > start && (s[end-1] == ' ' || s[end-1] == '\t' || s[end-1] == '\n') {
-		end--
-	}
-	return s[start:end]
 }
-
@@ -0,0 +1,187 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"welcomebot/internal/core/queue"
+	"welcomebot/internal/fakes"
+	"welcomebot/internal/onboard"
+	"welcomebot/internal/worker"
+)
+
+func TestProcessNextTaskDeadLettersMalformedPayload(t *testing.T) {
+	q := fakes.NewQueue()
+	events := fakes.NewQueue()
+	w := &Worker{
+		slaveID:        "slave-1",
+		cache:          fakes.NewCache(),
+		queue:          q,
+		events:         events,
+		logger:         fakes.Logger{},
+		i18n:           fakes.I18n{},
+		activeSessions: make(map[string]*worker.OnboardingSession),
+	}
+
+	ctx := context.Background()
+	_ = q.Enqueue(ctx, queue.Task{
+		ID:      "task-missing",
+		Type:    "onboarding_start",
+		GuildID: "guild-1",
+		Payload: map[string]interface{}{"category_id": "category-1", "slave_id": "slave-1"},
+	})
+	_ = q.Enqueue(ctx, queue.Task{
+		ID:      "task-wrong-type",
+		Type:    "onboarding_start",
+		GuildID: "guild-1",
+		Payload: map[string]interface{}{"user_id": "user-1", "category_id": "category-1", "slave_id": 7},
+	})
+
+	w.processNextTask(ctx, q)
+	w.processNextTask(ctx, q)
+
+	dead := q.TasksFor(queue.DeadLetterQueueKey)
+	if len(dead) != 2 || dead[0].ID != "task-missing" || dead[1].ID != "task-wrong-type" {
+		t.Fatalf("dead-lettered tasks = %+v, want both malformed tasks", dead)
+	}
+	if pending := q.Pending(); len(pending) != 0 {
+		t.Errorf("malformed tasks left unacked: %+v", pending)
+	}
+
+	// Only the task naming its member can be reported to master
+	reported := events.Tasks()
+	if len(reported) != 1 {
+		t.Fatalf("reported %d failures, want 1", len(reported))
+	}
+	if got := reported[0].Payload["user_id"]; got != "user-1" {
+		t.Errorf("reported user_id = %v, want user-1", got)
+	}
+	if got := reported[0].Payload["step"]; got != "invalid_payload" {
+		t.Errorf("reported step = %v, want invalid_payload", got)
+	}
+}
+
+func TestProcessNextTaskAcksFailedStart(t *testing.T) {
+	// Without any guide installed every start fails
+	t.Chdir(t.TempDir())
+
+	d := fakes.NewDiscord()
+	q := fakes.NewQueue()
+	events := fakes.NewQueue()
+	w := &Worker{
+		slaveID:        "slave-1",
+		session:        d.Session(),
+		db:             fakes.NewDB(),
+		cache:          fakes.NewCache(),
+		queue:          q,
+		events:         events,
+		logger:         fakes.Logger{},
+		i18n:           fakes.I18n{},
+		activeSessions: make(map[string]*worker.OnboardingSession),
+		playerFactory:  func() onboard.AudioPlayer { return &fakes.AudioPlayer{} },
+		memberRoles:    worker.NewMemberRoles(d.Session()),
+		deleteGrace:    new(time.Duration),
+	}
+
+	ctx := context.Background()
+	_ = q.Enqueue(ctx, queue.Task{
+		ID:      "task-1",
+		Type:    "onboarding_start",
+		GuildID: "guild-1",
+		Payload: map[string]interface{}{"user_id": "user-1", "category_id": "category-1", "slave_id": "slave-1"},
+	})
+
+	w.processNextTask(ctx, q)
+
+	if depth, _ := q.Depth(ctx); depth != 0 {
+		t.Errorf("failed start requeued: %d tasks waiting", depth)
+	}
+	if dead := q.TasksFor(queue.DeadLetterQueueKey); len(dead) != 0 {
+		t.Errorf("failed start dead-lettered: %+v", dead)
+	}
+	if pending := q.Pending(); len(pending) != 0 {
+		t.Errorf("failed start left unacked: %+v", pending)
+	}
+	if n := d.Count(http.MethodPost, "guilds/guild-1/channels"); n != 1 {
+		t.Errorf("created %d voice channels, want 1", n)
+	}
+	if reported := events.Tasks(); len(reported) != 1 {
+		t.Errorf("reported %d failures, want 1", len(reported))
+	}
+}
+
+func TestFailedStartIsNotRecovered(t *testing.T) {
+	// Without any guide installed every start fails
+	t.Chdir(t.TempDir())
+
+	d := fakes.NewDiscord()
+	q := fakes.NewQueue()
+	w := &Worker{
+		slaveID:        "slave-1",
+		session:        d.Session(),
+		db:             fakes.NewDB(),
+		cache:          fakes.NewCache(),
+		queue:          q,
+		events:         fakes.NewQueue(),
+		logger:         fakes.Logger{},
+		i18n:           fakes.I18n{},
+		activeSessions: make(map[string]*worker.OnboardingSession),
+		playerFactory:  func() onboard.AudioPlayer { return &fakes.AudioPlayer{} },
+		memberRoles:    worker.NewMemberRoles(d.Session()),
+		deleteGrace:    new(time.Duration),
+	}
+
+	ctx := context.Background()
+	_ = q.Enqueue(ctx, queue.Task{
+		ID:      "task-1",
+		Type:    "onboarding_start",
+		GuildID: "guild-1",
+		Payload: map[string]interface{}{"user_id": "user-1", "category_id": "category-1", "slave_id": "slave-1"},
+	})
+
+	w.processNextTask(ctx, q)
+
+	// A restart must not replay the start and open a second channel
+	recovered, err := q.Recover(ctx)
+	if err != nil {
+		t.Fatalf("Recover: %v", err)
+	}
+	if recovered != 0 {
+		t.Errorf("recovered %d tasks after a failed start, want 0", recovered)
+	}
+	if depth, _ := q.Depth(ctx); depth != 0 {
+		t.Errorf("failed start queued again: %d tasks waiting", depth)
+	}
+}
+
+func TestRetryTaskCapsRetries(t *testing.T) {
+	q := fakes.NewQueue()
+	w := &Worker{
+		slaveID: "slave-1",
+		queue:   q,
+		logger:  fakes.Logger{},
+	}
+
+	ctx := context.Background()
+	_ = q.Enqueue(ctx, queue.Task{ID: "task-1", Type: "onboarding_start", GuildID: "guild-1"})
+
+	for n := 0; n <= queue.MaxRetries; n++ {
+		task, err := q.Dequeue(ctx, time.Second)
+		if err != nil || task == nil {
+			t.Fatalf("delivery %d: task = %v, err = %v", n, task, err)
+		}
+		if !w.retryTask(ctx, q, task) {
+			t.Fatalf("delivery %d: task not moved", n)
+		}
+		_ = q.Ack(ctx, task)
+	}
+
+	if dead := q.TasksFor(queue.DeadLetterQueueKey); len(dead) != 1 || dead[0].Retries != queue.MaxRetries {
+		t.Fatalf("expected the task dead-lettered after %d retries, got %+v", queue.MaxRetries, dead)
+	}
+	if depth, _ := q.Depth(ctx); depth != 0 {
+		t.Errorf("expected nothing left to retry, got %d tasks", depth)
+	}
+}
@@ -0,0 +1,24 @@
+package main
+
+import "testing"
+
+func TestInactivityWarningFromEnv(t *testing.T) {
+	t.Setenv("INACTIVITY_WARNING_MESSAGE", "")
+
+	t.Setenv("INACTIVITY_WARNING_PERCENT", "")
+	if warning, err := inactivityWarningFromEnv(); warning != nil || err != nil {
+		t.Errorf("unset: got %+v, %v", warning, err)
+	}
+
+	t.Setenv("INACTIVITY_WARNING_PERCENT", "75")
+	if warning, err := inactivityWarningFromEnv(); err != nil || warning.Threshold != 0.75 {
+		t.Errorf("75: got %+v, %v", warning, err)
+	}
+
+	for _, invalid := range []string{"-1", "100", "half"} {
+		t.Setenv("INACTIVITY_WARNING_PERCENT", invalid)
+		if _, err := inactivityWarningFromEnv(); err == nil {
+			t.Errorf("%q: expected an error", invalid)
+		}
+	}
+}
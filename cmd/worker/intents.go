@@ -0,0 +1,36 @@
+package main
+
+import "github.com/bwmarrin/discordgo"
+
+// baseIntents are always requested: guild state for the role hierarchy
+// and voice states, without which joining the onboarding channel never
+// completes. Neither is privileged.
+const baseIntents = discordgo.IntentsGuilds | discordgo.IntentsGuildVoiceStates
+
+// workerIntents returns the gateway intents the worker needs for the
+// event handlers it registers. Privileged ones are only requested when a
+// handler takes those events, so a slave without them still starts.
+func workerIntents(handlers ...interface{}) discordgo.Intent {
+	intents := baseIntents
+	for _, handler := range handlers {
+		intents |= handlerIntents(handler)
+	}
+	return intents
+}
+
+// handlerIntents maps an event handler to the intents that deliver its
+// events. Interactions arrive without any.
+func handlerIntents(handler interface{}) discordgo.Intent {
+	switch handler.(type) {
+	case func(*discordgo.Session, *discordgo.GuildCreate):
+		return discordgo.IntentsGuilds
+	case func(*discordgo.Session, *discordgo.VoiceStateUpdate):
+		return discordgo.IntentsGuildVoiceStates
+	case func(*discordgo.Session, *discordgo.MessageCreate):
+		return discordgo.IntentsGuildMessages | discordgo.IntentsMessageContent
+	case func(*discordgo.Session, *discordgo.GuildMemberAdd),
+		func(*discordgo.Session, *discordgo.GuildMemberRemove):
+		return discordgo.IntentsGuildMembers
+	}
+	return 0
+}
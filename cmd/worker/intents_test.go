@@ -0,0 +1,22 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func TestWorkerIntents(t *testing.T) {
+	w := &Worker{}
+	if got := workerIntents(w.handleInteraction, w.handleGuildCreate); got != baseIntents {
+		t.Errorf("expected only the base intents, got %d", got)
+	}
+
+	memberJoin := func(*discordgo.Session, *discordgo.GuildMemberAdd) {}
+	if workerIntents(memberJoin)&discordgo.IntentsGuildMembers == 0 {
+		t.Error("expected a member handler to request the members intent")
+	}
+	if workerIntents(w.handleInteraction)&discordgo.IntentsMessageContent != 0 {
+		t.Error("expected no message content intent without a message handler")
+	}
+}
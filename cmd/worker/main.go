@@ -2,19 +2,28 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"os/signal"
+	"slices"
+	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"syscall"
 	"time"
 
+	"welcomebot/internal/audio"
 	"welcomebot/internal/core/cache"
 	"welcomebot/internal/core/database"
+	"welcomebot/internal/core/discord"
+	"welcomebot/internal/core/env"
 	"welcomebot/internal/core/i18n"
 	"welcomebot/internal/core/logger"
 	"welcomebot/internal/core/queue"
+	"welcomebot/internal/features/welcome"
+	"welcomebot/internal/onboard"
 	"welcomebot/internal/worker"
 
 	"github.com/bwmarrin/discordgo"
@@ -22,16 +31,27 @@ import (
 
 func main() {
 	// Load configuration from environment
-	slaveID := getEnv("SLAVE_ID", "slave-1")
-	botToken := getEnv("DISCORD_BOT_TOKEN", "")
+	slaveID, err := normalizeSlaveID(env.Get("SLAVE_ID", "slave-1"))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid SLAVE_ID: %v\n", err)
+		os.Exit(1)
+	}
+	botToken := env.Get("DISCORD_BOT_TOKEN", "")
 	if botToken == "" {
 		fmt.Fprintf(os.Stderr, "DISCORD_BOT_TOKEN is required\n")
 		os.Exit(1)
 	}
 
+	logLevels, err := logger.ParseLevels(env.Get("LOG_LEVELS", ""))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid LOG_LEVELS: %v\n", err)
+		os.Exit(1)
+	}
+
 	logCfg := logger.Config{
-		Level:  getEnv("LOG_LEVEL", "info"),
-		Format: getEnv("LOG_FORMAT", "json"),
+		Level:  env.Get("LOG_LEVEL", "info"),
+		Format: env.Get("LOG_FORMAT", "json"),
+		Levels: logLevels,
 	}
 
 	// Initialize logger
@@ -43,14 +63,46 @@ func main() {
 
 	lgr.Info("Starting Welcomebot Worker Bot", "slave_id", slaveID)
 
+	// Without a guide every session would end at guide selection
+	guides, err := onboard.UsableGuides()
+	if err != nil {
+		lgr.Error("No usable onboarding guides", "error", err)
+		os.Exit(1)
+	}
+	lgr.Info("Onboarding guides loaded", "guides", guides)
+
+	// Oversized or broken files are treated as missing rather than tying
+	// up the worker
+	audioLimits, err := audio.ParseLimits(env.Get("AUDIO_LIMITS", ""))
+	if err != nil {
+		lgr.Error("Invalid AUDIO_LIMITS", "error", err)
+		os.Exit(1)
+	}
+
+	// Preloading keeps the most used guides' audio in memory, within a budget
+	audioPreload, err := audio.ParsePreload(env.Get("AUDIO_PRELOAD", ""))
+	if err != nil {
+		lgr.Error("Invalid AUDIO_PRELOAD", "error", err)
+		os.Exit(1)
+	}
+
+	// The same audit as "doctor assets". Language variants fall back file
+	// by file, so the gaps are reported up front too.
+	defaultAudioLocale := env.Get("DEFAULT_AUDIO_LOCALE", "")
+	for _, guide := range guides {
+		auditGuideAtStartup(lgr, worker.AuditGuide(guide, audioLimits), defaultAudioLocale)
+	}
+
 	// Initialize database
 	dbCfg := database.Config{
-		Host:     getEnv("POSTGRES_HOST", "localhost"),
-		Port:     getEnv("POSTGRES_PORT", "5432"),
-		User:     getEnv("POSTGRES_USER", "welcomebot"),
-		Password: getEnv("POSTGRES_PASSWORD", ""),
-		Database: getEnv("POSTGRES_DB", "welcomebot"),
-		SSLMode:  getEnv("POSTGRES_SSLMODE", "disable"),
+		Host:     env.Get("POSTGRES_HOST", "localhost"),
+		Port:     env.Get("POSTGRES_PORT", "5432"),
+		User:     env.Get("POSTGRES_USER", "welcomebot"),
+		Password: env.Get("POSTGRES_PASSWORD", ""),
+		Database: env.Get("POSTGRES_DB", "welcomebot"),
+		SSLMode:  env.Get("POSTGRES_SSLMODE", "disable"),
+
+		ReplicaHosts: env.ReplicaHosts(),
 	}
 
 	db, err := database.New(dbCfg)
@@ -63,12 +115,26 @@ func main() {
 	lgr.Info("Database connected")
 
 	// Initialize cache
+	cacheKey, err := cache.ParseEncryptionKey(env.Get("CACHE_ENCRYPTION_KEY", ""))
+	if err != nil {
+		lgr.Error("Invalid CACHE_ENCRYPTION_KEY", "error", err)
+		os.Exit(1)
+	}
 	cacheCfg := cache.Config{
-		SentinelAddrs: getSentinelAddrs(),
-		MasterName:    getEnv("REDIS_MASTER_NAME", ""),
-		Addr:          getEnv("REDIS_ADDR", "localhost:6379"),
-		Password:      getEnv("REDIS_PASSWORD", ""),
+		ClusterAddrs:  env.ClusterAddrs(),
+		SentinelAddrs: env.SentinelAddrs(),
+		MasterName:    env.Get("REDIS_MASTER_NAME", ""),
+		Addr:          env.Get("REDIS_ADDR", "localhost:6379"),
+		Password:      env.Get("REDIS_PASSWORD", ""),
 		DB:            0,
+		EncryptionKey: cacheKey,
+		OnStateChange: func(degraded bool) {
+			if degraded {
+				lgr.Error("CACHE DEGRADED: redis unreachable, using in-memory sessions only", "slave_id", slaveID)
+				return
+			}
+			lgr.Info("cache recovered, leaving degraded mode", "slave_id", slaveID)
+		},
 	}
 
 	cacheClient, err := cache.New(cacheCfg)
@@ -80,14 +146,28 @@ func main() {
 
 	lgr.Info("Cache connected")
 
+	// Refuse to run alongside another worker using the same slave ID
+	slaveOwnerID := slaveOwner()
+	err = claimSlaveID(context.Background(), cacheClient, slaveID, slaveOwnerID)
+	if errors.Is(err, errSlaveIDContended) {
+		err = claimSlaveID(context.Background(), cacheClient, slaveID, slaveOwnerID)
+	}
+	if err != nil {
+		lgr.Error("Failed to claim slave ID, is another worker running with the same SLAVE_ID?", "slave_id", slaveID, "error", err)
+		os.Exit(1)
+	}
+	defer releaseSlaveID(context.Background(), cacheClient, slaveID, slaveOwnerID)
+
 	// Initialize queue
 	queueCfg := queue.Config{
-		SentinelAddrs: getSentinelAddrs(),
-		MasterName:    getEnv("REDIS_MASTER_NAME", ""),
-		RedisAddr:     getEnv("REDIS_ADDR", "localhost:6379"),
-		RedisPassword: getEnv("REDIS_PASSWORD", ""),
+		ClusterAddrs:  env.ClusterAddrs(),
+		SentinelAddrs: env.SentinelAddrs(),
+		MasterName:    env.Get("REDIS_MASTER_NAME", ""),
+		RedisAddr:     env.Get("REDIS_ADDR", "localhost:6379"),
+		RedisPassword: env.Get("REDIS_PASSWORD", ""),
 		RedisDB:       0,
 		QueueKey:      "welcomebot:tasks",
+		ConsumerID:    slaveID,
 	}
 
 	queueClient, err := queue.New(queueCfg)
@@ -97,6 +177,40 @@ func main() {
 	}
 	defer queueClient.Close()
 
+	// Requeue tasks a previous run of this slave took but did not finish
+	if recovered, err := queueClient.Recover(context.Background()); err != nil {
+		lgr.Error("Failed to recover unfinished tasks", "error", err)
+	} else if recovered > 0 {
+		lgr.Warn("Requeued unfinished tasks from previous run", "count", recovered)
+	}
+
+	// Events queue for reporting back to master (enqueue only)
+	eventsCfg := queueCfg
+	eventsCfg.QueueKey = queue.MasterQueueKey
+	eventsCfg.ConsumerID = ""
+	eventsClient, err := queue.New(eventsCfg)
+	if err != nil {
+		lgr.Error("Failed to connect to events queue", "error", err)
+		os.Exit(1)
+	}
+	defer eventsClient.Close()
+
+	// Direct queue for messages master sends to this slave only
+	directCfg := queueCfg
+	directCfg.QueueKey = queue.SlaveQueueKey(slaveID)
+	directClient, err := queue.New(directCfg)
+	if err != nil {
+		lgr.Error("Failed to connect to direct queue", "error", err)
+		os.Exit(1)
+	}
+	defer directClient.Close()
+
+	if recovered, err := directClient.Recover(context.Background()); err != nil {
+		lgr.Error("Failed to recover unfinished direct tasks", "error", err)
+	} else if recovered > 0 {
+		lgr.Warn("Requeued unfinished direct tasks from previous run", "count", recovered)
+	}
+
 	lgr.Info("Queue connected")
 
 	// Initialize i18n
@@ -109,6 +223,13 @@ func main() {
 		os.Exit(1)
 	}
 
+	// Optional TTS fallback for guides without recorded audio
+	ttsProvider, err := newTTSProvider()
+	if err != nil {
+		lgr.Error("Failed to configure TTS", "error", err)
+		os.Exit(1)
+	}
+
 	// Initialize Discord session
 	discordSession, err := discordgo.New("Bot " + botToken)
 	if err != nil {
@@ -116,11 +237,6 @@ func main() {
 		os.Exit(1)
 	}
 
-	// Set intents
-	discordSession.Identify.Intents = discordgo.IntentsGuilds |
-		discordgo.IntentsGuildVoiceStates |
-		discordgo.IntentsGuildMessages
-
 	// Create worker
 	workerBot := &Worker{
 		slaveID:        slaveID,
@@ -128,23 +244,153 @@ func main() {
 		db:             db,
 		cache:          cacheClient,
 		queue:          queueClient,
+		events:         eventsClient,
+		direct:         directClient,
 		logger:         lgr,
 		i18n:           i18nClient,
 		activeSessions: make(map[string]*worker.OnboardingSession),
+		memberRoles:    worker.NewMemberRoles(discordSession),
+	}
+
+	// Restarts after a lost session go through the master's start checks,
+	// so the limits are read from the same variables. Their tasks are
+	// pushed through a producer client capped like master's, so a restart
+	// can't grow the backlog past QUEUE_MAX_DEPTH.
+	startCfg := queueCfg
+	startCfg.ConsumerID = ""
+	startCfg.MaxDepth = int64(env.Int("QUEUE_MAX_DEPTH", 0))
+	startClient, err := queue.New(startCfg)
+	if err != nil {
+		lgr.Error("Failed to connect to start queue", "error", err)
+		os.Exit(1)
+	}
+	defer startClient.Close()
+
+	starter, err := welcome.New(welcome.Dependencies{
+		DB:      db,
+		Cache:   cacheClient,
+		Queue:   startClient,
+		I18n:    i18nClient,
+		Logger:  lgr,
+		Session: discordSession,
+
+		MaxSessionsPerGuild: env.Int("MAX_SESSIONS_PER_GUILD", welcome.DefaultMaxSessionsPerGuild),
+		MaxAttemptsPerUser:  env.Int("MAX_ONBOARDING_ATTEMPTS", welcome.DefaultMaxAttemptsPerUser),
+		AttemptWindow:       time.Duration(env.Int("ONBOARDING_ATTEMPT_WINDOW_HOURS", int(welcome.DefaultAttemptWindow/time.Hour))) * time.Hour,
+		ResumeExpiry:        time.Duration(env.Int("ONBOARDING_RESUME_HOURS", 0)) * time.Hour,
+	})
+	if err != nil {
+		lgr.Error("Failed to create onboarding starter", "error", err)
+		os.Exit(1)
+	}
+	workerBot.starter = starter
+
+	if ttsProvider != nil {
+		tts := worker.NewTTS(ttsProvider)
+		workerBot.playerFactory = func() onboard.AudioPlayer {
+			return worker.NewTTSPlayer(discordSession, lgr, i18nClient, tts)
+		}
+		lgr.Info("TTS audio fallback enabled", "provider", env.Get("TTS_PROVIDER", ""))
+	}
+
+	inactivityWarning, err := inactivityWarningFromEnv()
+	if err != nil {
+		lgr.Error("Invalid INACTIVITY_WARNING_PERCENT", "value", env.Get("INACTIVITY_WARNING_PERCENT", ""), "error", err)
+		os.Exit(1)
+	}
+	workerBot.inactivityWarning = inactivityWarning
+
+	if value := env.Get("VC_DELETE_GRACE", ""); value != "" {
+		grace, err := time.ParseDuration(value)
+		if err != nil || grace < 0 {
+			lgr.Error("Invalid VC_DELETE_GRACE", "value", value, "error", err)
+			os.Exit(1)
+		}
+		workerBot.deleteGrace = &grace
+	}
+
+	stepTimeouts, err := worker.ParseStepTimeouts(env.Get("STEP_TIMEOUTS", ""))
+	if err != nil {
+		lgr.Error("Invalid STEP_TIMEOUTS", "error", err)
+		os.Exit(1)
+	}
+	workerBot.stepTimeouts = stepTimeouts
+
+	replayModes, err := worker.ParseReplayModes(env.Get("REPLAY_MODES", ""))
+	if err != nil {
+		lgr.Error("Invalid REPLAY_MODES", "error", err)
+		os.Exit(1)
+	}
+	workerBot.replayModes = replayModes
+
+	buttonStyles, err := worker.ParseButtonStyles(env.Get("BUTTON_STYLES", ""))
+	if err != nil {
+		lgr.Error("Invalid BUTTON_STYLES", "error", err)
+		os.Exit(1)
+	}
+	workerBot.buttonStyles = buttonStyles
+	workerBot.defaultAudioLocale = defaultAudioLocale
+
+	stepConditions, err := worker.ParseStepConditions(env.Get("STEP_CONDITIONS", ""))
+	if err != nil {
+		lgr.Error("Invalid STEP_CONDITIONS", "error", err)
+		os.Exit(1)
+	}
+	workerBot.stepConditions = stepConditions
+
+	audioTransitions, err := audio.ParseTransitions(env.Get("AUDIO_TRANSITIONS", ""))
+	if err != nil {
+		lgr.Error("Invalid AUDIO_TRANSITIONS", "error", err)
+		os.Exit(1)
+	}
+	if err := audioTransitions.Check(); err != nil {
+		lgr.Error("AUDIO_TRANSITIONS can't be applied", "error", err)
+		os.Exit(1)
+	}
+	workerBot.audioTransitions = audioTransitions
+
+	voiceReadiness, err := audio.ParseReadiness(env.Get("VOICE_READY", ""))
+	if err != nil {
+		lgr.Error("Invalid VOICE_READY", "error", err)
+		os.Exit(1)
+	}
+	workerBot.voiceReadiness = voiceReadiness
+	workerBot.audioLimits = audioLimits
+
+	sweepInterval := defaultSweepInterval
+	if value := env.Get("SESSION_SWEEP_INTERVAL", ""); value != "" {
+		sweepInterval, err = time.ParseDuration(value)
+		if err != nil || sweepInterval <= 0 {
+			lgr.Error("Invalid SESSION_SWEEP_INTERVAL", "value", value, "error", err)
+			os.Exit(1)
+		}
+	}
+
+	handlers := []interface{}{
+		// Guide selection and the other onboarding buttons
+		workerBot.handleInteraction,
+		// Check the role hierarchy as guilds become available
+		workerBot.handleGuildCreate,
+	}
+	for _, handler := range handlers {
+		discordSession.AddHandler(handler)
 	}
 
-	// Add interaction handler for guide selection
-	discordSession.AddHandler(workerBot.handleInteraction)
+	// Ask only for the events the handlers above take
+	intents := workerIntents(handlers...)
+	discordSession.Identify.Intents = intents
 
 	// Open Discord connection
 	if err := discordSession.Open(); err != nil {
-		lgr.Error("Failed to open Discord connection", "error", err)
+		lgr.Error("Failed to open Discord connection", "error", discord.CheckIntents(err, intents))
 		os.Exit(1)
 	}
 	defer discordSession.Close()
 
 	lgr.Info("Discord connected", "user", discordSession.State.User.String())
 
+	workerBot.publishBotUser(context.Background())
+
 	// Mark slave as available
 	statusKey := fmt.Sprintf("welcomebot:slaves:status:%s", slaveID)
 	if err := cacheClient.Set(context.Background(), statusKey, "available", 30*time.Minute); err != nil {
@@ -153,13 +399,22 @@ func main() {
 
 	lgr.Info("Welcomebot Worker Bot is running. Press CTRL-C to exit.", "slave_id", slaveID)
 
-	// Start heartbeat
-	go workerBot.sendHeartbeats(context.Background())
-
 	// Start processing tasks
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
+	// Start heartbeat
+	go workerBot.sendHeartbeats(context.Background())
+	go cache.LogHitRates(ctx, cacheClient.Stats(), lgr.Named("cache"), cache.HitRateInterval)
+	go holdSlaveID(ctx, cacheClient, lgr, slaveID, slaveOwnerID)
+	go workerBot.sweepSessions(ctx, sweepInterval)
+	if audioPreload.Budget > 0 {
+		go preloadAudio(ctx, cacheClient, lgr, guides, audioPreload)
+	}
+	if addr := env.Get("METRICS_ADDR", ""); addr != "" {
+		go workerBot.serveMetrics(ctx, addr)
+	}
+
 	// Handle shutdown signals
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM, os.Interrupt)
@@ -172,10 +427,26 @@ func main() {
 
 	// Process tasks until shutdown
 	workerBot.Run(ctx)
+	worker.WaitPendingDeletes()
 
 	lgr.Info("Worker stopped gracefully")
 }
 
+// preloadAudio warms the in-memory audio cache. Sessions read guides from
+// disk until it is done.
+func preloadAudio(ctx context.Context, cacheClient cache.Client, lgr logger.Logger, guides []string, cfg audio.Preload) {
+	result, err := audio.PreloadGuides(ctx, cacheClient, guides, cfg, lgr)
+	if err != nil {
+		lgr.Warn("Audio preload interrupted", "error", err)
+	}
+	lgr.Info("Guide audio preloaded",
+		"guides", result.Loaded,
+		"skipped", result.Skipped,
+		"bytes", result.Bytes,
+		"budget", cfg.Budget,
+	)
+}
+
 // Worker processes tasks from the queue.
 type Worker struct {
 	slaveID        string
@@ -183,33 +454,65 @@ type Worker struct {
 	db             database.Client
 	cache          cache.Client
 	queue          queue.Client
+	events         queue.Client // Master-bound events (failures)
+	direct         queue.Client // This slave's own list; nil disables
 	logger         logger.Logger
 	i18n           i18n.I18n
 	activeSessions map[string]*worker.OnboardingSession // Map of guildID:userID -> session
 	sessionsMutex  sync.RWMutex                         // Protect the map
+	sessionsSwept  atomic.Int64                         // Stale sessions removed by sweepSessions
+	playerFactory  func() onboard.AudioPlayer           // nil uses the default DCA player
+	starter        onboardingStarter                    // Starts onboarding again after a lost session
+
+	inactivityWarning  *worker.InactivityWarning // nil uses worker.DefaultInactivityWarning
+	stepTimeouts       worker.StepTimeouts       // Per-step idle timeouts; empty disables
+	replayModes        worker.ReplayModes        // Per-step replay modes; empty replays audio only
+	buttonStyles       worker.ButtonStyles       // Per-role button styles; empty keeps the defaults
+	defaultAudioLocale string                    // Audio variant played when the guild's language has none
+	stepConditions     worker.StepConditions     // Per-step show_if rules; empty shows every step
+	memberRoles        *worker.MemberRoles       // Members' current roles; nil always calls Discord
+	deleteGrace        *time.Duration            // nil uses worker.DefaultDeleteGrace
+	audioTransitions   audio.Transitions         // Fades and gap between clips; zero disables
+	voiceReadiness     audio.Readiness           // Wait for voice connections; zero uses the default
+	audioLimits        audio.Limits              // Longest a clip may play; zero uses the default
 }
 
 // Run starts the worker task processing loop.
 func (w *Worker) Run(ctx context.Context) {
 	w.logger.Info("Worker started, waiting for tasks...")
 
+	// Onboarding tasks block the shared loop for a whole session, so
+	// direct messages get a loop of their own
+	if w.direct != nil {
+		go w.consume(ctx, w.direct)
+	}
+	w.consume(ctx, w.queue)
+	w.logger.Info("Context cancelled, stopping worker")
+}
+
+// consume processes tasks from q until ctx is cancelled.
+func (w *Worker) consume(ctx context.Context, q queue.Client) {
 	for {
 		select {
 		case <-ctx.Done():
-			w.logger.Info("Context cancelled, stopping worker")
 			return
 		default:
-			w.processNextTask(ctx)
+			w.processNextTask(ctx, q)
 		}
 	}
 }
 
-// processNextTask dequeues and processes one task.
-func (w *Worker) processNextTask(ctx context.Context) {
+// processNextTask dequeues and processes one task from q.
+func (w *Worker) processNextTask(ctx context.Context, q queue.Client) {
+	log := w.logger.Named("queue")
+
 	// Wait for task (30 second timeout)
-	task, err := w.queue.Dequeue(ctx, 30*time.Second)
+	task, err := q.Dequeue(ctx, 30*time.Second)
 	if err != nil {
-		w.logger.Error("Failed to dequeue task", "error", err)
+		if ctx.Err() != nil {
+			return
+		}
+		log.Error("Failed to dequeue task", "error", err)
 		time.Sleep(5 * time.Second)
 		return
 	}
@@ -219,7 +522,7 @@ func (w *Worker) processNextTask(ctx context.Context) {
 		return
 	}
 
-	w.logger.Info("Processing task",
+	log.Info("Processing task",
 		"task_id", task.ID,
 		"task_type", task.Type,
 		"guild_id", task.GuildID,
@@ -227,26 +530,103 @@ func (w *Worker) processNextTask(ctx context.Context) {
 
 	// Process task based on type
 	if err := w.handleTask(ctx, task); err != nil {
-		w.logger.Error("Task processing failed",
+		var payloadErr *onboard.PayloadError
+		if errors.As(err, &payloadErr) {
+			// Retrying can't fix the payload, so set it aside and ack it
+			w.deadLetter(ctx, q, task, err)
+		} else if errors.Is(err, errSessionFailed) {
+			// The session already cleaned up and told master and the
+			// member; a retry would open another channel and alert staff
+			// again
+			log.Error("Onboarding session failed",
+				"task_id", task.ID,
+				"guild_id", task.GuildID,
+				"error", err,
+			)
+		} else {
+			log.Error("Task processing failed",
+				"task_id", task.ID,
+				"task_type", task.Type,
+				"retries", task.Retries,
+				"error", err,
+			)
+			if !w.retryTask(ctx, q, task) {
+				// Left unacked: it is requeued when this slave restarts
+				return
+			}
+		}
+	} else {
+		log.Info("Task completed",
 			"task_id", task.ID,
 			"task_type", task.Type,
-			"error", err,
 		)
-		// TODO: Implement retry logic if needed
-		return
 	}
 
-	w.logger.Info("Task completed",
+	// Ack even if shutdown has begun, or the finished task would be redone
+	if err := q.Ack(context.Background(), task); err != nil {
+		log.Warn("Failed to ack task", "task_id", task.ID, "error", err)
+	}
+}
+
+// deadLetter moves a task that can never be processed to the dead-letter
+// list and tells master the member's onboarding failed, if the member is
+// known.
+func (w *Worker) deadLetter(ctx context.Context, q queue.Client, task *queue.Task, cause error) {
+	w.logger.Error("Malformed task, moving to dead-letter queue",
 		"task_id", task.ID,
 		"task_type", task.Type,
+		"guild_id", task.GuildID,
+		"error", cause,
 	)
+
+	if err := q.EnqueueTo(context.Background(), queue.DeadLetterQueueKey, *task); err != nil {
+		w.logger.Error("Failed to dead-letter task", "task_id", task.ID, "error", err)
+	}
+
+	if userID, ok := task.Payload["user_id"].(string); ok && userID != "" {
+		w.reportFailure(ctx, task.GuildID, userID, "invalid_payload", cause)
+	}
 }
 
+// retryTask puts a task whose handling failed back on q, or on the
+// dead-letter list once it has failed queue.MaxRetries times, so a task
+// that keeps failing can't loop through the queue forever. It reports
+// whether the task was moved, and so can be acked.
+func (w *Worker) retryTask(ctx context.Context, q queue.Client, task *queue.Task) bool {
+	deadLettered, err := queue.Retry(context.Background(), q, *task)
+	if err != nil {
+		w.logger.Error("Failed to requeue task", "task_id", task.ID, "error", err)
+		return false
+	}
+	if deadLettered {
+		w.logger.Warn("Task failed too often, moved to dead-letter queue",
+			"task_id", task.ID,
+			"task_type", task.Type,
+			"guild_id", task.GuildID,
+		)
+	}
+	return true
+}
+
+// errSessionFailed marks an onboarding_start task whose session failed after
+// reporting the failure itself. Such tasks are acked rather than retried.
+var errSessionFailed = errors.New("onboarding session failed")
+
 // handleTask routes tasks to appropriate handlers.
 func (w *Worker) handleTask(ctx context.Context, task *queue.Task) error {
 	switch task.Type {
 	case "onboarding_start":
-		return w.handleOnboardingStart(ctx, task)
+		payload, err := onboard.ParseStartPayload(task.Payload)
+		if err != nil {
+			return err
+		}
+		if len(payload.SkippedGuideRoles) > 0 {
+			w.logger.Warn("Malformed guide completion roles skipped; those guides use the default roles",
+				"task_id", task.ID,
+				"guides", payload.SkippedGuideRoles,
+			)
+		}
+		return w.handleOnboardingStart(ctx, task, payload)
 	case "onboarding_complete":
 		return w.handleOnboardingComplete(ctx, task)
 	default:
@@ -256,24 +636,46 @@ func (w *Worker) handleTask(ctx context.Context, task *queue.Task) error {
 }
 
 // handleOnboardingStart handles the start of an onboarding session.
-func (w *Worker) handleOnboardingStart(ctx context.Context, task *queue.Task) error {
+func (w *Worker) handleOnboardingStart(ctx context.Context, task *queue.Task, payload *onboard.StartPayload) error {
 	w.logger.Info("Starting onboarding session", "task_id", task.ID)
 
 	// Create onboarding session
 	session, err := worker.NewOnboardingSession(
 		ctx,
-		task,
+		task.GuildID,
+		payload,
 		w.session,
 		w.db,
 		w.cache,
 		w.queue,
-		w.logger,
+		w.events,
+		w.logger.Named("session"),
 		w.i18n,
+		w.newAudioPlayer(),
 	)
 	if err != nil {
 		w.logger.Error("Failed to create onboarding session", "error", err)
-		return err
+		w.reportFailure(ctx, task.GuildID, payload.UserID, "create_session", err)
+		return fmt.Errorf("%w: %w", errSessionFailed, err)
+	}
+	if w.inactivityWarning != nil {
+		session.SetInactivityWarning(*w.inactivityWarning)
+	}
+	session.SetStepTimeouts(w.stepTimeouts)
+	session.SetReplayModes(w.replayModes)
+	session.SetButtonStyles(w.buttonStyles)
+	session.SetDefaultAudioLocale(w.defaultAudioLocale)
+	session.SetStepConditions(w.stepConditions)
+	session.SetMemberRoles(w.memberRoles)
+	if w.deleteGrace != nil {
+		session.SetDeleteGrace(*w.deleteGrace)
 	}
+	session.SetAudioTransitions(w.audioTransitions)
+	session.SetVoiceReadiness(w.voiceReadiness)
+	session.SetAudioLimits(w.audioLimits)
+
+	// Keep the task so the member can restart if the session is lost
+	w.saveRestartTask(ctx, task, session.GetUserID())
 
 	// Store session in active sessions map for interaction handling
 	sessionKey := fmt.Sprintf("%s:%s", task.GuildID, session.GetUserID())
@@ -283,24 +685,59 @@ func (w *Worker) handleOnboardingStart(ctx context.Context, task *queue.Task) er
 
 	// Start the session (blocks until complete)
 	err = session.Start()
-	
-	// Remove from active sessions when done
+
+	// Remove from active sessions when done, unless the sweeper already
+	// has and a new session for the member took its place
 	w.sessionsMutex.Lock()
-	delete(w.activeSessions, sessionKey)
+	if w.activeSessions[sessionKey] == session {
+		delete(w.activeSessions, sessionKey)
+	}
 	w.sessionsMutex.Unlock()
+	w.memberRoles.Forget(task.GuildID, session.GetUserID())
+	if session.Outcome() == onboard.OutcomeCompleted {
+		w.forgetRestartTask(context.Background(), task.GuildID, session.GetUserID())
+	}
 
 	if err != nil {
-		w.logger.Error("Failed to start onboarding session", "error", err)
-		return err
+		return fmt.Errorf("%w: %w", errSessionFailed, err)
 	}
 
 	return nil
 }
 
-// handleOnboardingComplete handles completion notification from master.
+// newAudioPlayer returns the audio backend for a new session.
+func (w *Worker) newAudioPlayer() onboard.AudioPlayer {
+	if w.playerFactory == nil {
+		return nil
+	}
+	return w.playerFactory()
+}
+
+// reportFailure tells master that a session could not be created. Failures
+// after creation are reported by the session itself.
+func (w *Worker) reportFailure(ctx context.Context, guildID, userID, step string, cause error) {
+	task := queue.Task{
+		ID:      fmt.Sprintf("failed-%s-%s-%d", guildID, userID, time.Now().Unix()),
+		Type:    "onboarding_failed",
+		GuildID: guildID,
+		Payload: map[string]interface{}{
+			"user_id":  userID,
+			"slave_id": w.slaveID,
+			"step":     step,
+			"error":    cause.Error(),
+		},
+		CreatedAt: time.Now(),
+	}
+
+	if err := w.events.Enqueue(ctx, task); err != nil {
+		w.logger.Error("Failed to report onboarding failure", "error", err, "user_id", userID)
+	}
+}
+
+// handleOnboardingComplete drains completion events that older slaves put
+// on the task queue. Completions now go to master on the events queue.
 func (w *Worker) handleOnboardingComplete(ctx context.Context, task *queue.Task) error {
 	w.logger.Info("Onboarding completion received", "task_id", task.ID)
-	// Master has acknowledged completion - no action needed
 	return nil
 }
 
@@ -337,12 +774,35 @@ func (w *Worker) handleInteraction(s *discordgo.Session, i *discordgo.Interactio
 		return
 	}
 
+	w.recordClick(i, customID)
+
+	// Replies follow the language the member picked for their session
+	ctx = w.sessionContext(ctx, i)
+
+	// Handle restart after a lost session: onboarding:restart:{userID}
+	if strings.HasPrefix(customID, "onboarding:restart:") {
+		w.handleRestart(ctx, s, i, customID)
+		return
+	}
+
 	// Handle preview button: onboarding:preview:{guide}:{userID}
 	if strings.HasPrefix(customID, "onboarding:preview:") {
 		w.handlePreviewButton(ctx, s, i, customID)
 		return
 	}
 
+	// Handle greeting skip button: onboarding:skip_greeting:{userID}
+	if strings.HasPrefix(customID, "onboarding:skip_greeting:") {
+		w.handleSkipGreeting(ctx, s, i, customID)
+		return
+	}
+
+	// Handle language selection: onboarding:language:{locale}:{userID}
+	if strings.HasPrefix(customID, "onboarding:language:") {
+		w.handleLanguageSelection(ctx, s, i, customID)
+		return
+	}
+
 	// Handle guide selection: onboarding:select_guide:{userID}
 	if strings.HasPrefix(customID, "onboarding:select_guide:") {
 		w.handleGuideSelection(ctx, s, i, customID)
@@ -367,6 +827,12 @@ func (w *Worker) handleInteraction(s *discordgo.Session, i *discordgo.Interactio
 		return
 	}
 
+	// Handle full replay buttons: onboarding:step{N}_replay_full:{userID}
+	if strings.HasPrefix(customID, "onboarding:step") && strings.Contains(customID, "_replay_full:") {
+		w.handleStepReplayFull(ctx, s, i, customID)
+		return
+	}
+
 	// Handle Step 1 Replay button: onboarding:step1_replay:{userID}
 	if strings.HasPrefix(customID, "onboarding:step1_replay:") {
 		w.handleStep1Replay(ctx, s, i, customID)
@@ -487,6 +953,12 @@ func (w *Worker) handleInteraction(s *discordgo.Session, i *discordgo.Interactio
 		return
 	}
 
+	// Handle Step 3 Event done: onboarding:event_done:{userID}
+	if strings.HasPrefix(customID, "onboarding:event_done:") {
+		w.handleStep3EventDone(ctx, s, i, customID)
+		return
+	}
+
 	// Handle Step 3 Next button: onboarding:step3_next:{userID}
 	if strings.HasPrefix(customID, "onboarding:step3_next:") {
 		w.handleStep3Next(ctx, s, i, customID)
@@ -494,69 +966,84 @@ func (w *Worker) handleInteraction(s *discordgo.Session, i *discordgo.Interactio
 	}
 }
 
-// handlePreviewButton handles guide preview button clicks.
-func getEnv(key, defaultValue string) string {
-	if value := os.Getenv(key); value != "" {
-		return value
+// auditGuideAtStartup rejects the guide's audio files that failed the
+// audit and logs the audit's findings.
+func auditGuideAtStartup(lgr logger.Logger, audit worker.GuideAudit, defaultAudioLocale string) {
+	guide := audit.Guide
+	if audit.Err != nil {
+		lgr.Warn("Guide assets could not be audited", "guide", guide, "error", audit.Err)
+		return
 	}
-	return defaultValue
-}
 
-func getSentinelAddrs() []string {
-	addrs := getEnv("REDIS_SENTINEL_ADDRS", "")
-	if addrs == "" {
-		return nil
+	for _, rejected := range worker.RejectFailedAudio(audit) {
+		lgr.Warn("Guide audio rejected", "guide", guide, "file", rejected.File, "error", rejected.Err)
 	}
-	// Split by comma: "host1:26379,host2:26379"
-	parts := make([]string, 0)
-	for _, addr := range splitAndTrim(addrs, ",") {
-		if addr != "" {
-			parts = append(parts, addr)
+
+	missing := make(map[string][]string)
+	for _, c := range audit.Checks {
+		switch {
+		case c.Locale != "" && c.Absent:
+			missing[c.Locale] = append(missing[c.Locale], c.Key)
+		case c.Kind == worker.AssetImage && c.Err != nil:
+			lgr.Warn("Guide image can't be sent", "guide", guide, "step", c.Key, "file", c.File, "error", c.Err)
 		}
 	}
-	return parts
-}
 
-func splitAndTrim(s, sep string) []string {
-	parts := make([]string, 0)
-	for _, part := range splitString(s, sep) {
-		trimmed := trimString(part)
-		if trimmed != "" {
-			parts = append(parts, trimmed)
+	locales := worker.AudioLocales(guide)
+	if len(locales) == 0 {
+		return
+	}
+
+	lgr.Info("Guide audio locales", "guide", guide, "locales", locales)
+	for _, locale := range locales {
+		if len(missing[locale]) > 0 {
+			lgr.Warn("Guide audio locale is incomplete; missing files fall back",
+				"guide", guide, "locale", locale, "missing", missing[locale])
 		}
 	}
-	return parts
+	if defaultAudioLocale != "" && !slices.Contains(locales, defaultAudioLocale) {
+		lgr.Warn("Guide has no audio for DEFAULT_AUDIO_LOCALE", "guide", guide, "locale", defaultAudioLocale)
+	}
 }
 
-func splitString(s, sep string) []string {
-	if s == "" {
-		return nil
+// inactivityWarningFromEnv reads INACTIVITY_WARNING_PERCENT (0 disables,
+// 1-99 warns at that share of the inactivity timeout) and
+// INACTIVITY_WARNING_MESSAGE. It returns nil when neither is set, and an
+// error for a percent outside that range.
+func inactivityWarningFromEnv() (*worker.InactivityWarning, error) {
+	percent := env.Get("INACTIVITY_WARNING_PERCENT", "")
+	message := env.Get("INACTIVITY_WARNING_MESSAGE", "")
+	if percent == "" && message == "" {
+		return nil, nil
 	}
-	result := make([]string, 0)
-	current := ""
-	for _, char := range s {
-		if string(char) == sep {
-			result = append(result, current)
-			current = ""
-		} else {
-			current += string(char)
+
+	warning := worker.DefaultInactivityWarning
+	if percent != "" {
+		value, err := strconv.Atoi(percent)
+		if err != nil || value < 0 || value > 99 {
+			return nil, fmt.Errorf("invalid percent %q, want 0 to 99", percent)
 		}
+		warning.Threshold = float64(value) / 100
 	}
-	if current != "" {
-		result = append(result, current)
+	if message != "" {
+		warning.Message = message
 	}
-	return result
+	return &warning, nil
 }
 
-func trimString(s string) string {
-	start := 0
-	end := len(s)
-	for start < end && (s[start] == ' ' || s[start] == '\t' || s[start] == '\n') {
-		start++
-	}
-	for end > start && (s[end-1] == ' ' || s[end-1] == '\t' || s[end-1] == '\n') {
-		end--
+// newTTSProvider builds the TTS provider selected by TTS_PROVIDER.
+// TTS is opt-in; an unset provider returns nil.
+func newTTSProvider() (worker.TTSProvider, error) {
+	switch provider := env.Get("TTS_PROVIDER", ""); provider {
+	case "":
+		return nil, nil
+	case "http":
+		url := env.Get("TTS_URL", "")
+		if url == "" {
+			return nil, fmt.Errorf("TTS_URL is required for the http provider")
+		}
+		return worker.NewHTTPTTSProvider(url, env.Get("TTS_API_KEY", "")), nil
+	default:
+		return nil, fmt.Errorf("unknown TTS_PROVIDER %q", provider)
 	}
-	return s[start:end]
 }
-
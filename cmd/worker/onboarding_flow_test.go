@@ -0,0 +1,900 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"welcomebot/internal/core/queue"
+	"welcomebot/internal/fakes"
+	"welcomebot/internal/features/welcome"
+	"welcomebot/internal/onboard"
+	"welcomebot/internal/worker"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+const (
+	testGuildID = "guild-1"
+	testUserID  = "user-1"
+	testGuide   = "kk"
+)
+
+// testRoles maps task payload keys to the role IDs used by the flow tests.
+var testRoles = map[string]string{
+	"entrance_role":          "role-entrance",
+	"nyukai_role":            "role-nyukai",
+	"setsumeikai_1_role":     "role-setsumeikai1",
+	"setsumeikai_2_role":     "role-setsumeikai2",
+	"setsumeikai_3_role":     "role-setsumeikai3",
+	"member_role":            "role-member",
+	"visitor_role":           "role-visitor",
+	"initial_role":           "role-initial",
+	"age_20_early_role":      "role-age20early",
+	"male_role":              "role-male",
+	"mid_voice_role":         "role-midvoice",
+	"ero_ok_role":            "role-erook",
+	"neochi_ok_role":         "role-neochiok",
+	"neochi_disconnect_role": "role-neochidisconnect",
+	"dm_ok_role":             "role-dmok",
+	"friend_ok_role":         "role-friendok",
+	"bunnyclub_event_role":   "role-bunnyclub",
+	"user_event_role":        "role-userevent",
+}
+
+// flowHarness drives a worker through an onboarding session against fakes.
+type flowHarness struct {
+	t       *testing.T
+	w       *Worker
+	discord *fakes.Discord
+	db      *fakes.DB
+	queue   *fakes.Queue
+	player  *fakes.AudioPlayer
+	done    chan error
+	nextID  int
+}
+
+func newFlowHarness(t *testing.T, initialRoles ...string) *flowHarness {
+	t.Helper()
+
+	return newFlowHarnessWithConfig(t, nil, initialRoles...)
+}
+
+// newFlowHarnessWithConfig starts a session whose task payload also carries
+// the given guild settings.
+func newFlowHarnessWithConfig(t *testing.T, config map[string]interface{}, initialRoles ...string) *flowHarness {
+	t.Helper()
+
+	return newFlowHarnessWithSetup(t, config, nil, initialRoles...)
+}
+
+// newFlowHarnessWithSetup lets setup adjust the worker and fakes before the
+// session starts.
+func newFlowHarnessWithSetup(t *testing.T, config map[string]interface{}, setup func(*flowHarness), initialRoles ...string) *flowHarness {
+	t.Helper()
+
+	prevTransition, prevSelection := transitionDelay, selectionDelay
+	transitionDelay, selectionDelay = 0, 0
+	t.Cleanup(func() {
+		transitionDelay, selectionDelay = prevTransition, prevSelection
+	})
+
+	h := &flowHarness{
+		t:       t,
+		discord: fakes.NewDiscord(),
+		db:      fakes.NewDB(),
+		queue:   fakes.NewQueue(),
+		player:  &fakes.AudioPlayer{},
+		done:    make(chan error, 1),
+	}
+	h.discord.SetMemberRoles(testGuildID, testUserID, initialRoles...)
+
+	// Sessions only start with a guide installed
+	t.Chdir(t.TempDir())
+	if err := os.MkdirAll(filepath.Join("audio", testGuide), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	h.w = &Worker{
+		slaveID:        "slave-1",
+		session:        h.discord.Session(),
+		db:             h.db,
+		cache:          fakes.NewCache(),
+		queue:          h.queue,
+		events:         h.queue, // One fake records tasks and master-bound events alike
+		logger:         fakes.Logger{},
+		i18n:           fakes.I18n{},
+		activeSessions: make(map[string]*worker.OnboardingSession),
+		playerFactory:  func() onboard.AudioPlayer { return h.player },
+		memberRoles:    worker.NewMemberRoles(h.discord.Session()),
+		deleteGrace:    new(time.Duration),
+	}
+
+	payload := map[string]interface{}{
+		"user_id":     testUserID,
+		"category_id": "category-1",
+		"slave_id":    "slave-1",
+	}
+	for key, roleID := range testRoles {
+		payload[key] = roleID
+	}
+	for key, value := range config {
+		payload[key] = value
+	}
+	task := &queue.Task{
+		ID:        "task-1",
+		Type:      "onboarding_start",
+		GuildID:   testGuildID,
+		Payload:   payload,
+		CreatedAt: time.Now(),
+	}
+
+	if setup != nil {
+		setup(h)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	go func() {
+		h.done <- h.w.handleTask(ctx, task)
+	}()
+
+	return h
+}
+
+// click sends a component interaction from the onboarding user.
+func (h *flowHarness) click(customID string, values ...string) {
+	h.t.Helper()
+
+	h.nextID++
+	h.w.handleInteraction(h.w.session, &discordgo.InteractionCreate{
+		Interaction: &discordgo.Interaction{
+			ID:      fmt.Sprintf("interaction-%d", h.nextID),
+			Token:   "token",
+			Type:    discordgo.InteractionMessageComponent,
+			GuildID: testGuildID,
+			Member:  &discordgo.Member{User: &discordgo.User{ID: testUserID}},
+			Data: discordgo.MessageComponentInteractionData{
+				CustomID: customID,
+				Values:   values,
+			},
+		},
+	})
+}
+
+// expectComponent waits until a component with the given custom ID has been
+// sent to Discord, i.e. the user can click it.
+func (h *flowHarness) expectComponent(customID string) {
+	h.t.Helper()
+
+	h.waitFor("component "+customID, func() bool {
+		return h.sent(customID)
+	})
+}
+
+// expectAudio waits until the player has been asked to play file.
+func (h *flowHarness) expectAudio(file string) {
+	h.t.Helper()
+
+	h.waitFor("audio "+file, func() bool {
+		return h.playCount(file) > 0
+	})
+}
+
+// playCount returns how many times file has been played.
+func (h *flowHarness) playCount(file string) int {
+	count := 0
+	for _, played := range h.player.Played() {
+		if played == testGuide+"/"+file {
+			count++
+		}
+	}
+	return count
+}
+
+// step waits for a step's button and audio, then clicks the button.
+func (h *flowHarness) step(customID, audioFile string) {
+	h.t.Helper()
+
+	h.expectComponent(customID)
+	h.expectAudio(audioFile)
+	h.click(customID)
+}
+
+// sent reports whether any request body mentioned s.
+func (h *flowHarness) sent(s string) bool {
+	for _, req := range h.discord.Requests() {
+		if strings.Contains(string(req.Body), s) {
+			return true
+		}
+	}
+	return false
+}
+
+func (h *flowHarness) waitFor(what string, cond func() bool) {
+	h.t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			h.t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// confirmations returns the interaction responses acknowledging step 3
+// selections.
+func (h *flowHarness) confirmations() []*discordgo.InteractionResponse {
+	h.t.Helper()
+
+	var responses []*discordgo.InteractionResponse
+	for _, req := range h.discord.Requests() {
+		if !strings.HasPrefix(req.Path, "interactions/") || !strings.Contains(string(req.Body), "のロールを付与しました") {
+			continue
+		}
+		var resp discordgo.InteractionResponse
+		if err := json.Unmarshal(req.Body, &resp); err != nil {
+			h.t.Fatalf("decode interaction response: %v", err)
+		}
+		responses = append(responses, &resp)
+	}
+	return responses
+}
+
+// timeline returns the outcome and events of the flushed session timeline.
+func (h *flowHarness) timeline() (string, []onboard.TimelineEvent) {
+	h.t.Helper()
+
+	for _, exec := range h.db.Execs() {
+		if !strings.Contains(exec.Query, "INSERT INTO session_timelines") {
+			continue
+		}
+		var events []onboard.TimelineEvent
+		if err := json.Unmarshal([]byte(exec.Args[6].(string)), &events); err != nil {
+			h.t.Fatalf("decode timeline: %v", err)
+		}
+		return exec.Args[4].(string), events
+	}
+	h.t.Fatal("expected session timeline to be saved")
+	return "", nil
+}
+
+// selectGuide walks through guide selection into step 1.
+func (h *flowHarness) selectGuide() {
+	h.t.Helper()
+
+	h.expectComponent("onboarding:select_guide:" + testUserID)
+	h.click("onboarding:select_guide:"+testUserID, testGuide)
+
+	confirmID := fmt.Sprintf("onboarding:confirm_guide:%s:%s", testGuide, testUserID)
+	h.expectComponent(confirmID)
+	h.click(confirmID)
+}
+
+// complete finishes step 7 and waits for the session to end.
+func (h *flowHarness) complete() {
+	h.t.Helper()
+
+	h.step("onboarding:step7_complete:"+testUserID, "7-end.dca")
+
+	select {
+	case err := <-h.done:
+		if err != nil {
+			h.t.Fatalf("session ended with error: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		h.t.Fatal("timed out waiting for session to end")
+	}
+}
+
+func (h *flowHarness) assertRoles(want map[string]bool) {
+	h.t.Helper()
+
+	for roleID, has := range want {
+		if got := h.discord.HasRole(testGuildID, testUserID, roleID); got != has {
+			h.t.Errorf("role %s: got %v, want %v", roleID, got, has)
+		}
+	}
+}
+
+func (h *flowHarness) assertCompleted() {
+	h.t.Helper()
+
+	h.w.sessionsMutex.RLock()
+	remaining := len(h.w.activeSessions)
+	h.w.sessionsMutex.RUnlock()
+	if remaining != 0 {
+		h.t.Errorf("expected no active sessions, got %d", remaining)
+	}
+
+	completed := false
+	for _, task := range h.queue.Tasks() {
+		if task.Type == "onboarding_complete" {
+			completed = true
+		}
+	}
+	if !completed {
+		h.t.Error("expected onboarding_complete task to be enqueued")
+	}
+
+	deleted := false
+	for _, req := range h.discord.Requests() {
+		if req.Method == http.MethodDelete && strings.HasPrefix(req.Path, "channels/") {
+			deleted = true
+		}
+	}
+	if !deleted {
+		h.t.Error("expected voice channel to be deleted")
+	}
+}
+
+func TestOnboardingFlow(t *testing.T) {
+	h := newFlowHarness(t, "role-initial", "role-entrance", "role-nyukai", "role-setsumeikai1")
+
+	h.selectGuide()
+
+	// Replay restarts the current step's audio
+	h.expectAudio("1-intro.dca")
+	h.click("onboarding:step1_replay:" + testUserID)
+	if got := h.playCount("1-intro.dca"); got != 2 {
+		t.Errorf("expected step 1 audio to play twice after replay, got %d", got)
+	}
+
+	h.step("onboarding:step1_next:"+testUserID, "1-intro.dca")
+	h.step("onboarding:step2_next:"+testUserID, "2-profile.dca")
+
+	h.assertRoles(map[string]bool{"role-setsumeikai2": true})
+
+	// Step 3 role selection
+	for _, choice := range []string{
+		"gender:male",
+		"age:20early",
+		"voice:mid",
+		"eroipu:ok",
+		"neochi:ok",
+		"neochi_handling:room",
+		"dm:ok",
+		"friend:ok",
+		"event:bunnyclub",
+		"event:user",
+	} {
+		customID := fmt.Sprintf("onboarding:%s:%s", choice, testUserID)
+		h.expectComponent(customID)
+		h.click(customID)
+	}
+
+	// Both event roles can be picked; the summary waits for 完了
+	if h.sent("onboarding:step3_next:") {
+		t.Error("expected step 3 completion to wait for the done button")
+	}
+	h.click("onboarding:event_done:" + testUserID)
+	h.step("onboarding:step3_next:"+testUserID, "3-role.dca")
+
+	h.assertRoles(map[string]bool{
+		"role-male":         true,
+		"role-age20early":   true,
+		"role-midvoice":     true,
+		"role-erook":        true,
+		"role-neochiok":     true,
+		"role-dmok":         true,
+		"role-friendok":     true,
+		"role-bunnyclub":    true,
+		"role-userevent":    true,
+		"role-setsumeikai3": true,
+	})
+
+	h.step("onboarding:step4_next:"+testUserID, "4-point.dca")
+	h.step("onboarding:step5_next:"+testUserID, "5-club.dca")
+	h.step("onboarding:step6_next:"+testUserID, "6-membership.dca")
+	h.complete()
+
+	h.assertRoles(map[string]bool{
+		"role-member":       true,
+		"role-visitor":      true,
+		"role-male":         true,
+		"role-initial":      false,
+		"role-entrance":     false,
+		"role-nyukai":       false,
+		"role-setsumeikai1": false,
+		"role-setsumeikai2": false,
+		"role-setsumeikai3": false,
+	})
+	h.assertCompleted()
+
+	outcome, events := h.timeline()
+	if outcome != onboard.OutcomeCompleted {
+		t.Errorf("expected timeline outcome %q, got %q", onboard.OutcomeCompleted, outcome)
+	}
+	seen := map[string]bool{}
+	for _, event := range events {
+		seen[event.Kind+" "+event.Detail] = true
+	}
+	for _, want := range []string{
+		"step step1",
+		"step step3.1",
+		"click onboarding:gender:male:" + testUserID,
+		"audio " + testGuide + "/1-intro.dca",
+		"audio replay",
+		"role +role-male",
+		"role -role-entrance",
+	} {
+		if !seen[want] {
+			t.Errorf("expected timeline event %q", want)
+		}
+	}
+
+	// Choices are kept for /onboarding-summary
+	var selections onboard.Selections
+	for _, exec := range h.db.Execs() {
+		if strings.Contains(exec.Query, "INSERT INTO member_onboarding_selections") {
+			if err := json.Unmarshal([]byte(exec.Args[3].(string)), &selections); err != nil {
+				t.Fatalf("decode selections: %v", err)
+			}
+		}
+	}
+	if got := fmt.Sprint(selections["age"], selections["neochi_handling"], selections["event"]); got != "[20early] [room] [bunnyclub user]" {
+		t.Errorf("saved selections %v", selections)
+	}
+}
+
+func TestOnboardingFlowSkipsStep3WithSetsumeikai3(t *testing.T) {
+	h := newFlowHarness(t, "role-entrance", "role-nyukai", "role-setsumeikai3")
+
+	h.selectGuide()
+	h.step("onboarding:step1_next:"+testUserID, "1-intro.dca")
+	h.step("onboarding:step2_next:"+testUserID, "2-profile.dca")
+
+	// Step 2 goes straight to step 4, and says so
+	h.step("onboarding:step4_next:"+testUserID, "4-point.dca")
+	if !h.sent("onboarding.moving_to_step step=4") {
+		t.Error("expected the step 2 button to say it leads to step 4")
+	}
+	if h.sent("onboarding:gender:") {
+		t.Error("expected step 3 role selection to be skipped")
+	}
+	if h.playCount("3-role.dca") != 0 {
+		t.Error("expected step 3 audio to be skipped")
+	}
+
+	h.step("onboarding:step5_next:"+testUserID, "5-club.dca")
+	h.step("onboarding:step6_next:"+testUserID, "6-membership.dca")
+	h.complete()
+
+	h.assertRoles(map[string]bool{
+		"role-member":       true,
+		"role-visitor":      true,
+		"role-male":         false,
+		"role-entrance":     false,
+		"role-setsumeikai3": false,
+	})
+	h.assertCompleted()
+}
+
+func TestOnboardingFlowGuideCompletionRoles(t *testing.T) {
+	h := newFlowHarnessWithConfig(t, map[string]interface{}{
+		"guide_completion_roles": map[string]interface{}{
+			testGuide: map[string]interface{}{"member_role": "role-tier2"},
+		},
+	}, "role-entrance", "role-nyukai", "role-setsumeikai3")
+
+	h.selectGuide()
+	h.step("onboarding:step1_next:"+testUserID, "1-intro.dca")
+	h.step("onboarding:step2_next:"+testUserID, "2-profile.dca")
+	h.step("onboarding:step4_next:"+testUserID, "4-point.dca")
+	h.step("onboarding:step5_next:"+testUserID, "5-club.dca")
+	h.step("onboarding:step6_next:"+testUserID, "6-membership.dca")
+	h.complete()
+
+	// The guide's member role replaces the guild's; visitor is not overridden
+	h.assertRoles(map[string]bool{
+		"role-tier2":   true,
+		"role-member":  false,
+		"role-visitor": true,
+	})
+	h.assertCompleted()
+}
+
+func TestOnboardingFlowEventToggle(t *testing.T) {
+	h := newFlowHarness(t, "role-entrance", "role-nyukai", "role-setsumeikai1")
+
+	h.selectGuide()
+	h.step("onboarding:step1_next:"+testUserID, "1-intro.dca")
+	h.step("onboarding:step2_next:"+testUserID, "2-profile.dca")
+
+	for _, choice := range []string{
+		"gender:male", "age:20early", "voice:mid", "eroipu:ok", "neochi:ok",
+		"neochi_handling:room", "dm:ok", "friend:ok",
+	} {
+		customID := fmt.Sprintf("onboarding:%s:%s", choice, testUserID)
+		h.expectComponent(customID)
+		h.click(customID)
+	}
+
+	// A second click deselects the event and takes the role back
+	bunnyclub := "onboarding:event:bunnyclub:" + testUserID
+	h.expectComponent(bunnyclub)
+	h.click(bunnyclub)
+	h.assertRoles(map[string]bool{"role-bunnyclub": true})
+	h.click(bunnyclub)
+	h.assertRoles(map[string]bool{"role-bunnyclub": false})
+
+	// A double click on 完了 shows the step's completion once
+	h.click("onboarding:event_done:" + testUserID)
+	h.click("onboarding:event_done:" + testUserID)
+	h.expectComponent("onboarding:step3_next:" + testUserID)
+	h.assertRoles(map[string]bool{"role-bunnyclub": false, "role-userevent": false})
+
+	completions := 0
+	for _, req := range h.discord.Requests() {
+		if strings.Contains(string(req.Body), "onboarding:step3_next:") {
+			completions++
+		}
+	}
+	if completions != 1 {
+		t.Errorf("expected the step 3 completion once, got %d", completions)
+	}
+}
+
+func TestOnboardingFlowConditionalSteps(t *testing.T) {
+	conditions, err := worker.ParseStepConditions(`{
+		"step3.eroipu": {"show_if": {"age": ["30late", "40early", "40late"]}},
+		"step6": {"show_if": {"gender": ["female"]}}
+	}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := newFlowHarnessWithSetup(t, nil, func(h *flowHarness) { h.w.stepConditions = conditions },
+		"role-entrance", "role-nyukai", "role-setsumeikai1")
+
+	h.selectGuide()
+	h.step("onboarding:step1_next:"+testUserID, "1-intro.dca")
+	h.step("onboarding:step2_next:"+testUserID, "2-profile.dca")
+
+	// 20early skips the eroipu prompt and goes straight to neochi
+	for _, choice := range []string{
+		"gender:male", "age:20early", "voice:mid", "neochi:ok",
+		"neochi_handling:room", "dm:ok", "friend:ok", "event:bunnyclub",
+	} {
+		customID := fmt.Sprintf("onboarding:%s:%s", choice, testUserID)
+		h.expectComponent(customID)
+		h.click(customID)
+	}
+	if h.sent("onboarding:eroipu:") {
+		t.Error("expected the eroipu prompt to be hidden")
+	}
+	h.click("onboarding:event_done:" + testUserID)
+	h.step("onboarding:step3_next:"+testUserID, "3-role.dca")
+
+	// Step 6 is only for female members; step 5 leads to step 7
+	h.step("onboarding:step4_next:"+testUserID, "4-point.dca")
+	h.step("onboarding:step5_next:"+testUserID, "5-club.dca")
+	h.complete()
+
+	if h.sent("onboarding:step6_next:") {
+		t.Error("expected step 6 to be hidden")
+	}
+	h.assertCompleted()
+}
+
+func TestOnboardingFlowSkipsUnconfiguredPrompts(t *testing.T) {
+	// Only age ranges are configured; the other step 3 role groups are empty
+	config := map[string]interface{}{}
+	for _, key := range []string{
+		"male_role", "mid_voice_role", "ero_ok_role", "neochi_ok_role", "neochi_disconnect_role",
+		"dm_ok_role", "friend_ok_role", "bunnyclub_event_role", "user_event_role",
+	} {
+		config[key] = ""
+	}
+	h := newFlowHarnessWithConfig(t, config, "role-entrance", "role-nyukai", "role-setsumeikai1")
+
+	h.selectGuide()
+	h.step("onboarding:step1_next:"+testUserID, "1-intro.dca")
+	h.step("onboarding:step2_next:"+testUserID, "2-profile.dca")
+
+	h.expectComponent("onboarding:age:20early:" + testUserID)
+	h.click("onboarding:age:20early:" + testUserID)
+	h.step("onboarding:step3_next:"+testUserID, "3-role.dca")
+
+	for _, prompt := range []string{"gender", "voice", "eroipu", "neochi", "neochi_handling", "dm", "friend", "event"} {
+		if h.sent("onboarding:" + prompt + ":") {
+			t.Errorf("expected the %s prompt to be skipped", prompt)
+		}
+	}
+	h.assertRoles(map[string]bool{"role-age20early": true})
+
+	h.step("onboarding:step4_next:"+testUserID, "4-point.dca")
+	h.step("onboarding:step5_next:"+testUserID, "5-club.dca")
+	h.step("onboarding:step6_next:"+testUserID, "6-membership.dca")
+	h.complete()
+	h.assertCompleted()
+}
+
+func TestOnboardingFlowConfirmationVisibility(t *testing.T) {
+	tests := []struct {
+		name   string
+		config map[string]interface{}
+		want   discordgo.MessageFlags
+	}{
+		{"public by default", nil, 0},
+		{"ephemeral when configured", map[string]interface{}{"ephemeral_confirmations": true}, discordgo.MessageFlagsEphemeral},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newFlowHarnessWithConfig(t, tt.config, "role-entrance", "role-nyukai", "role-setsumeikai1")
+
+			h.selectGuide()
+			h.step("onboarding:step1_next:"+testUserID, "1-intro.dca")
+			h.step("onboarding:step2_next:"+testUserID, "2-profile.dca")
+
+			for _, choice := range []string{"gender:male", "age:20early"} {
+				customID := fmt.Sprintf("onboarding:%s:%s", choice, testUserID)
+				h.expectComponent(customID)
+				h.click(customID)
+			}
+			h.expectComponent("onboarding:voice:")
+
+			responses := h.confirmations()
+			if len(responses) != 2 {
+				t.Fatalf("expected 2 confirmations, got %d", len(responses))
+			}
+			for _, resp := range responses {
+				if resp.Data.Flags != tt.want {
+					t.Errorf("confirmation flags = %d, want %d", resp.Data.Flags, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestOnboardingFlowSharedVC(t *testing.T) {
+	h := newFlowHarnessWithConfig(t, map[string]interface{}{"shared_vc_channel_id": "vc-shared"},
+		"role-entrance", "role-nyukai", "role-setsumeikai3")
+
+	h.selectGuide()
+	for _, step := range []string{"step1_next", "step2_next", "step4_next", "step5_next", "step6_next", "step7_complete"} {
+		customID := fmt.Sprintf("onboarding:%s:%s", step, testUserID)
+		h.expectComponent(customID)
+		h.click(customID)
+	}
+
+	select {
+	case err := <-h.done:
+		if err != nil {
+			t.Fatalf("session ended with error: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for session to end")
+	}
+
+	h.assertRoles(map[string]bool{"role-member": true, "role-entrance": false})
+	if played := h.player.Played(); len(played) != 0 {
+		t.Errorf("expected no audio in a shared VC, got %v", played)
+	}
+	for _, req := range h.discord.Requests() {
+		switch {
+		case req.Method == http.MethodPost && strings.HasSuffix(req.Path, "/channels"):
+			t.Errorf("expected no voice channel to be created, got %s %s", req.Method, req.Path)
+		case req.Method == http.MethodDelete && req.Path == "channels/vc-shared":
+			t.Error("expected the shared voice channel to be kept")
+		case req.Method == http.MethodPost && strings.HasPrefix(req.Path, "channels/") && req.Path != "channels/vc-shared/messages":
+			t.Errorf("expected messages in the shared VC, got %s", req.Path)
+		}
+	}
+	if !h.sent("onboarding.shared_vc_notice") {
+		t.Error("expected the welcome message to explain the shared VC")
+	}
+}
+
+// channelCategories returns the parent of every voice channel the worker
+// tried to create, in order.
+func (h *flowHarness) channelCategories() []string {
+	var categories []string
+	for _, req := range h.discord.Requests() {
+		if req.Method != http.MethodPost || req.Path != "guilds/"+testGuildID+"/channels" {
+			continue
+		}
+		var data struct {
+			ParentID string `json:"parent_id"`
+		}
+		_ = json.Unmarshal(req.Body, &data)
+		categories = append(categories, data.ParentID)
+	}
+	return categories
+}
+
+func TestOnboardingFlowOverflowCategory(t *testing.T) {
+	config := map[string]interface{}{"overflow_category_ids": []interface{}{"category-2", "category-3"}}
+	h := newFlowHarnessWithSetup(t, config, func(h *flowHarness) {
+		h.discord.SetCategoryFull("category-1")
+	}, "role-entrance", "role-nyukai", "role-setsumeikai1")
+
+	h.selectGuide()
+
+	// The full category is skipped and the first with room is used
+	if got := h.channelCategories(); strings.Join(got, ",") != "category-1,category-2" {
+		t.Errorf("tried categories %v, want category-1 then category-2", got)
+	}
+}
+
+func TestOnboardingFlowAllCategoriesFull(t *testing.T) {
+	config := map[string]interface{}{"overflow_category_ids": []interface{}{"category-2"}}
+	h := newFlowHarnessWithSetup(t, config, func(h *flowHarness) {
+		h.discord.SetCategoryFull("category-1")
+		h.discord.SetCategoryFull("category-2")
+	})
+
+	select {
+	case err := <-h.done:
+		if !errors.Is(err, worker.ErrCategoriesFull) {
+			t.Fatalf("session ended with %v, want ErrCategoriesFull", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for session to fail")
+	}
+
+	if got := h.channelCategories(); strings.Join(got, ",") != "category-1,category-2" {
+		t.Errorf("tried categories %v, want category-1 then category-2", got)
+	}
+
+	// Master is told the guild is out of room rather than given the raw API error
+	var reported string
+	for _, task := range h.w.events.(*fakes.Queue).Tasks() {
+		if task.Type == "onboarding_failed" {
+			reported, _ = task.Payload["error"].(string)
+		}
+	}
+	if reported != worker.ErrCategoriesFull.Error() {
+		t.Errorf("reported error %q, want %q", reported, worker.ErrCategoriesFull.Error())
+	}
+}
+
+func TestOnboardingFlowNoGuides(t *testing.T) {
+	h := newFlowHarnessWithSetup(t, nil, func(h *flowHarness) {
+		if err := os.RemoveAll("audio"); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	select {
+	case err := <-h.done:
+		if !errors.Is(err, onboard.ErrNoGuides) {
+			t.Fatalf("session ended with %v, want ErrNoGuides", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for session to fail")
+	}
+
+	// The member is told instead of getting an empty guide menu
+	if !h.sent("onboarding.no_guides_description") {
+		t.Error("expected the no guides message to be posted")
+	}
+	if h.sent("onboarding:select_guide:") {
+		t.Error("expected no guide selection to be offered")
+	}
+
+	var step string
+	for _, task := range h.w.events.(*fakes.Queue).Tasks() {
+		if task.Type == "onboarding_failed" {
+			step, _ = task.Payload["step"].(string)
+		}
+	}
+	if step != "list_guides" {
+		t.Errorf("reported step %q, want list_guides", step)
+	}
+}
+
+func TestOnboardingFlowRejectsOtherMembers(t *testing.T) {
+	h := newFlowHarness(t, "role-entrance", "role-nyukai", "role-setsumeikai1")
+
+	h.selectGuide()
+	next := "onboarding:step1_next:" + testUserID
+	h.expectComponent(next)
+
+	h.nextID++
+	h.w.handleInteraction(h.w.session, &discordgo.InteractionCreate{
+		Interaction: &discordgo.Interaction{
+			ID:      fmt.Sprintf("interaction-%d", h.nextID),
+			Token:   "token",
+			Type:    discordgo.InteractionMessageComponent,
+			GuildID: testGuildID,
+			Member:  &discordgo.Member{User: &discordgo.User{ID: "intruder"}},
+			Data:    discordgo.MessageComponentInteractionData{CustomID: next},
+		},
+	})
+
+	// The fake translator echoes keys, so the notice is the localized one
+	if !h.sent("onboarding.not_your_button") {
+		t.Error("expected the localized not-your-button notice")
+	}
+	if h.sent("onboarding:step2_next:") {
+		t.Error("expected another member's click not to advance the session")
+	}
+}
+
+func TestOnboardingFlowRestartAfterLostSession(t *testing.T) {
+	h := newFlowHarness(t, "role-entrance", "role-nyukai", "role-setsumeikai1")
+
+	h.selectGuide()
+	h.expectComponent("onboarding:step1_next:" + testUserID)
+
+	// Simulate a worker restart: the session is gone but its buttons remain
+	h.w.sessionsMutex.Lock()
+	delete(h.w.activeSessions, testGuildID+":"+testUserID)
+	h.w.sessionsMutex.Unlock()
+
+	h.click("onboarding:step1_next:" + testUserID)
+	if !h.sent("onboarding:restart:" + testUserID) {
+		t.Fatal("expected a restart button for the lost session")
+	}
+
+	// Restarts start through the master's checks, which need the guild's
+	// config and a free slave
+	ctx := context.Background()
+	starter, err := welcome.New(welcome.Dependencies{
+		DB:      h.db,
+		Cache:   h.w.cache,
+		Queue:   h.queue,
+		I18n:    fakes.I18n{},
+		Logger:  fakes.Logger{},
+		Session: h.discord.Session(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.w.starter = starter
+	for key, value := range map[string]interface{}{
+		"welcomebot:config:":            &welcome.WelcomeConfig{GuildID: testGuildID},
+		"welcomebot:agerange:config:":   &welcome.AgeRangeConfig{GuildID: testGuildID},
+		"welcomebot:gender:":            &welcome.GenderConfig{GuildID: testGuildID},
+		"welcomebot:voicetype:config:":  &welcome.VoiceTypeConfig{GuildID: testGuildID},
+		"welcomebot:otherroles:config:": &welcome.OtherRolesConfig{GuildID: testGuildID},
+		"welcomebot:guide_roles:":       map[string]welcome.GuideCompletionRoles{},
+	} {
+		if err := h.w.cache.SetJSON(ctx, key+testGuildID, value, 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, slaveID := range welcome.SlaveIDs {
+		if err := h.w.cache.Set(ctx, "welcomebot:slaves:status:"+slaveID, "available", 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Two restarts are allowed, a third is refused
+	for n := 1; n <= 3; n++ {
+		h.click("onboarding:restart:" + testUserID)
+	}
+
+	var restarts []queue.Task
+	for _, task := range h.queue.Tasks() {
+		if strings.HasPrefix(task.ID, "onboard-") {
+			restarts = append(restarts, task)
+		}
+	}
+	if len(restarts) != 2 {
+		t.Fatalf("expected 2 restart tasks, got %d", len(restarts))
+	}
+	if restarts[0].Type != "onboarding_start" || restarts[0].Payload["user_id"] != testUserID {
+		t.Errorf("unexpected restart task: %+v", restarts[0])
+	}
+	// Each restart claims a free slave rather than the one that lost the
+	// session, and counts against the member's attempts
+	if restarts[0].Payload["slave_id"] == restarts[1].Payload["slave_id"] {
+		t.Errorf("expected the restarts to claim different slaves, got %v twice", restarts[0].Payload["slave_id"])
+	}
+	if attempts, _ := h.w.cache.Get(ctx, "welcomebot:onboard_attempts:"+testGuildID+":"+testUserID); attempts != "2" {
+		t.Errorf("expected both restarts counted as attempts, got %q", attempts)
+	}
+	if !h.sent("onboarding.restart_limit") {
+		t.Error("expected the third restart to be refused")
+	}
+}
@@ -3,12 +3,108 @@ package main
 import (
 	"context"
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
+	"welcomebot/internal/onboard"
+	"welcomebot/internal/worker"
+
 	"github.com/bwmarrin/discordgo"
 )
 
+// Pauses that give users time to read a confirmation before the next prompt.
+var (
+	transitionDelay = 1 * time.Second         // Before starting the next step
+	selectionDelay  = 1500 * time.Millisecond // Between step 3 selections
+)
+
+// respondConfirmation acknowledges a step 3 selection. Whether the reply is
+// public or ephemeral follows the guild's welcome configuration.
+func respondConfirmation(s *discordgo.Session, i *discordgo.InteractionCreate, session *worker.OnboardingSession, content string) {
+	_ = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content: content,
+			Flags:   session.ReplyFlags(),
+		},
+	})
+}
+
+// verifyOwner reports whether the interaction comes from userID, the
+// member the button or menu was sent to. Anyone else gets a localized
+// ephemeral notice.
+func (w *Worker) verifyOwner(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate, userID string) bool {
+	if i.Member != nil && i.Member.User != nil && i.Member.User.ID == userID {
+		return true
+	}
+
+	_ = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content: w.i18n.T(ctx, i.GuildID, "onboarding.not_your_button"),
+			Flags:   discordgo.MessageFlagsEphemeral,
+		},
+	})
+	return false
+}
+
+// addRole grants a role the member does not already have, through the
+// same guarded path as the session's own role changes.
+func (w *Worker) addRole(ctx context.Context, guildID, userID, roleID string) error {
+	applied, err := worker.ChangeRole(ctx, w.session, w.memberRoles, w.logger, guildID, userID, roleID, true)
+	if applied {
+		w.record(guildID, userID, onboard.TimelineRole, "+"+roleID)
+	}
+	return err
+}
+
+// removeRole revokes a role the member has, through the same guarded path
+// as the session's own role changes.
+func (w *Worker) removeRole(ctx context.Context, guildID, userID, roleID string) error {
+	applied, err := worker.ChangeRole(ctx, w.session, w.memberRoles, w.logger, guildID, userID, roleID, false)
+	if applied {
+		w.record(guildID, userID, onboard.TimelineRole, "-"+roleID)
+	}
+	return err
+}
+
+// recordClick adds a button click to the clicking user's session timeline.
+func (w *Worker) recordClick(i *discordgo.InteractionCreate, customID string) {
+	if i.Member == nil || i.Member.User == nil {
+		return
+	}
+	w.record(i.GuildID, i.Member.User.ID, onboard.TimelineClick, customID)
+}
+
+// record adds an event to a user's session timeline, if they have one here.
+func (w *Worker) record(guildID, userID, kind, detail string) {
+	w.sessionsMutex.RLock()
+	session, ok := w.activeSessions[fmt.Sprintf("%s:%s", guildID, userID)]
+	w.sessionsMutex.RUnlock()
+
+	if ok {
+		session.Record(kind, detail)
+	}
+}
+
+// sessionContext returns ctx set to the language the clicking member
+// picked for their session, so replies follow it.
+func (w *Worker) sessionContext(ctx context.Context, i *discordgo.InteractionCreate) context.Context {
+	if i.Member == nil || i.Member.User == nil {
+		return ctx
+	}
+
+	w.sessionsMutex.RLock()
+	session, ok := w.activeSessions[fmt.Sprintf("%s:%s", i.GuildID, i.Member.User.ID)]
+	w.sessionsMutex.RUnlock()
+
+	if !ok {
+		return ctx
+	}
+	return session.LocaleContext(ctx)
+}
+
 // handlePreviewButton handles guide preview button clicks.
 func (w *Worker) handlePreviewButton(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate, customID string) {
 	// Extract guide name from customID: onboarding:preview:{guide}:{userID}
@@ -22,14 +118,7 @@ func (w *Worker) handlePreviewButton(ctx context.Context, s *discordgo.Session,
 	userID := parts[3]
 
 	// Verify user is the one who started onboarding
-	if i.Member.User.ID != userID {
-		_ = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-			Type: discordgo.InteractionResponseChannelMessageWithSource,
-			Data: &discordgo.InteractionResponseData{
-				Content: "This button is not for you!",
-				Flags:   discordgo.MessageFlagsEphemeral,
-			},
-		})
+	if !w.verifyOwner(ctx, s, i, userID) {
 		return
 	}
 
@@ -42,49 +131,157 @@ func (w *Worker) handlePreviewButton(ctx context.Context, s *discordgo.Session,
 		return
 	}
 
-	// Get session from cache
-	sessionKey := fmt.Sprintf("welcomebot:session:%s:%s", i.GuildID, userID)
-	var sessionData map[string]interface{}
-	if err := w.cache.GetJSON(ctx, sessionKey, &sessionData); err != nil {
-		w.logger.Error("session not found", "error", err)
+	// Get the active session
+	sessionKey := fmt.Sprintf("%s:%s", i.GuildID, userID)
+	w.sessionsMutex.RLock()
+	activeSession, exists := w.activeSessions[sessionKey]
+	w.sessionsMutex.RUnlock()
+
+	if !exists {
+		w.logger.Error("active session not found", "session_key", sessionKey)
 		return
 	}
 
-	vcChannelID, _ := sessionData["vc_channel_id"].(string)
+	vcChannelID := activeSession.VCChannelID()
 	if vcChannelID == "" {
 		w.logger.Error("vc_channel_id not found in session")
 		return
 	}
 
 	w.logger.Info("preview button clicked", "guide", guide, "user_id", userID, "vc_channel_id", vcChannelID)
-	
-	// Get the active session
-	sessionKey = fmt.Sprintf("%s:%s", i.GuildID, userID)
-	w.sessionsMutex.RLock()
-	activeSession, exists := w.activeSessions[sessionKey]
-	w.sessionsMutex.RUnlock()
 
-	if !exists {
-		w.logger.Error("active session not found", "session_key", sessionKey)
+	// Update activity timestamp
+	activeSession.UpdateActivity()
+
+	// Guides may ship without a preview; say so rather than play nothing
+	if !activeSession.HasPreview(guide) {
+		w.logger.Info("guide has no preview audio", "guide", guide)
+		_, err := s.FollowupMessageCreate(i.Interaction, true, &discordgo.WebhookParams{
+			Content: w.i18n.T(ctx, i.GuildID, "onboarding.preview_unavailable"),
+			Flags:   discordgo.MessageFlagsEphemeral,
+		})
+		if err != nil {
+			w.logger.Warn("failed to send preview unavailable message", "error", err)
+		}
 		return
 	}
 
-	// Update activity timestamp
-	activeSession.UpdateActivity()
+	// Replaces the greeting or another guide's preview
+	if err := activeSession.PlayPreview(guide); err != nil {
+		w.logger.Info("preview refused", "guide", guide, "user_id", userID, "error", err)
+		_, err := s.FollowupMessageCreate(i.Interaction, true, &discordgo.WebhookParams{
+			Content: w.i18n.T(ctx, i.GuildID, "onboarding.preview_closed"),
+			Flags:   discordgo.MessageFlagsEphemeral,
+		})
+		if err != nil {
+			w.logger.Warn("failed to send preview closed message", "error", err)
+		}
+		return
+	}
 
 	// Send a message in the VC to indicate audio is playing
-	previewMessage := w.i18n.T(ctx, i.GuildID, "onboarding.preview_playing")
+	previewMessage := w.i18n.TWithArgs(ctx, i.GuildID, "onboarding.preview_playing", map[string]string{
+		"guide": w.i18n.T(ctx, i.GuildID, fmt.Sprintf("onboarding.guides.%s.name", guide)),
+	})
 	_, err = s.ChannelMessageSend(vcChannelID, previewMessage)
 	if err != nil {
 		w.logger.Warn("failed to send preview message", "error", err)
 	}
 
-	// Play the preview audio (0-voice-select.dca)
-	go func() {
-		if err := activeSession.PlayAudioFile(guide, "0-voice-select.dca"); err != nil {
-			w.logger.Error("failed to play preview audio", "error", err)
-		}
-	}()
+	// Mark the guide being previewed on the selection message
+	components := activeSession.BuildGuideSelectionComponents()
+	if _, err := s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{Components: &components}); err != nil {
+		w.logger.Warn("failed to mark previewing guide", "error", err)
+	}
+}
+
+// handleSkipGreeting stops the greeting audio played when the session starts.
+func (w *Worker) handleSkipGreeting(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate, customID string) {
+	// Extract userID from customID: onboarding:skip_greeting:{userID}
+	parts := strings.Split(customID, ":")
+	if len(parts) < 3 {
+		w.logger.Error("invalid skip_greeting customID", "custom_id", customID)
+		return
+	}
+
+	userID := parts[2]
+
+	// Verify user
+	if !w.verifyOwner(ctx, s, i, userID) {
+		return
+	}
+
+	sessionKey := fmt.Sprintf("%s:%s", i.GuildID, userID)
+	w.sessionsMutex.RLock()
+	activeSession, exists := w.activeSessions[sessionKey]
+	w.sessionsMutex.RUnlock()
+
+	// Keep the guide selection UI as it is
+	if err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredMessageUpdate,
+	}); err != nil {
+		w.logger.Error("failed to acknowledge skip greeting", "error", err)
+	}
+
+	if exists {
+		activeSession.UpdateActivity()
+		activeSession.StopCurrentAudio()
+	}
+}
+
+// handleLanguageSelection starts the session in the language the member
+// picked, replacing the language buttons with a confirmation in it.
+func (w *Worker) handleLanguageSelection(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate, customID string) {
+	// Extract locale and userID from customID: onboarding:language:{locale}:{userID}
+	parts := strings.Split(customID, ":")
+	if len(parts) < 4 {
+		w.logger.Error("invalid language customID", "custom_id", customID)
+		return
+	}
+
+	locale := parts[2]
+	userID := parts[3]
+
+	// Verify user
+	if !w.verifyOwner(ctx, s, i, userID) {
+		return
+	}
+
+	// Acknowledge interaction
+	err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredMessageUpdate,
+	})
+	if err != nil {
+		w.logger.Error("failed to respond to interaction", "error", err)
+		return
+	}
+
+	sessionKey := fmt.Sprintf("%s:%s", i.GuildID, userID)
+	w.sessionsMutex.RLock()
+	activeSession, exists := w.activeSessions[sessionKey]
+	w.sessionsMutex.RUnlock()
+
+	if !exists {
+		w.logger.Error("active session not found", "session_key", sessionKey)
+		return
+	}
+
+	// Greets the member and offers the guides in the new language
+	if err := activeSession.ChooseLocale(locale); err != nil {
+		w.logger.Info("language choice refused", "locale", locale, "user_id", userID, "error", err)
+		return
+	}
+
+	content := w.i18n.T(activeSession.LocaleContext(ctx), i.GuildID, "onboarding.language_chosen")
+	embeds := []*discordgo.MessageEmbed{}
+	components := []discordgo.MessageComponent{} // Clear buttons
+	if _, err := s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+		Content:    &content,
+		Embeds:     &embeds,
+		Components: &components,
+	}); err != nil {
+		w.logger.Warn("failed to confirm language", "error", err)
+	}
 }
 
 // handleGuideSelection handles guide dropdown selection.
@@ -99,14 +296,7 @@ func (w *Worker) handleGuideSelection(ctx context.Context, s *discordgo.Session,
 	userID := parts[2]
 
 	// Verify user
-	if i.Member.User.ID != userID {
-		_ = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-			Type: discordgo.InteractionResponseChannelMessageWithSource,
-			Data: &discordgo.InteractionResponseData{
-				Content: "This selection is not for you!",
-				Flags:   discordgo.MessageFlagsEphemeral,
-			},
-		})
+	if !w.verifyOwner(ctx, s, i, userID) {
 		return
 	}
 
@@ -119,20 +309,22 @@ func (w *Worker) handleGuideSelection(ctx context.Context, s *discordgo.Session,
 
 	selectedGuide := values[0]
 
-	// Update session with selected guide
-	sessionKey := fmt.Sprintf("welcomebot:session:%s:%s", i.GuildID, userID)
-	var sessionData map[string]interface{}
-	if err := w.cache.GetJSON(ctx, sessionKey, &sessionData); err != nil {
-		w.logger.Error("session not found", "error", err)
-		return
-	}
+	// Update cached session with selected guide. The in-memory session is
+	// authoritative, so this is skipped while the cache is degraded.
+	if !w.cache.Degraded() {
+		sessionKey := fmt.Sprintf("welcomebot:session:%s:%s", i.GuildID, userID)
+		var sessionData map[string]interface{}
+		if err := w.cache.GetJSON(ctx, sessionKey, &sessionData); err != nil {
+			w.logger.Error("session not found", "error", err)
+			return
+		}
 
-	sessionData["selected_guide"] = selectedGuide
-	sessionData["current_step"] = 0 // Still at step 0 (confirmation pending)
+		sessionData["selected_guide"] = selectedGuide
+		sessionData["current_step"] = 0 // Still at step 0 (confirmation pending)
 
-	if err := w.cache.SetJSON(ctx, sessionKey, sessionData, 10*time.Minute); err != nil {
-		w.logger.Error("failed to update session", "error", err)
-		return
+		if err := w.cache.SetJSON(ctx, sessionKey, sessionData, 10*time.Minute); err != nil {
+			w.logger.Warn("failed to update session", "error", err)
+		}
 	}
 
 	// Respond with confirmation prompt
@@ -151,12 +343,12 @@ func (w *Worker) handleGuideSelection(ctx context.Context, s *discordgo.Session,
 					Components: []discordgo.MessageComponent{
 						discordgo.Button{
 							Label:    w.i18n.T(ctx, i.GuildID, "onboarding.confirm_guide"),
-							Style:    discordgo.SuccessButton,
+							Style:    w.buttonStyles.Style(worker.ButtonConfirm),
 							CustomID: fmt.Sprintf("onboarding:confirm_guide:%s:%s", selectedGuide, userID),
 						},
 						discordgo.Button{
 							Label:    w.i18n.T(ctx, i.GuildID, "onboarding.button_back"),
-							Style:    discordgo.SecondaryButton,
+							Style:    w.buttonStyles.Style(worker.ButtonBack),
 							CustomID: fmt.Sprintf("onboarding:back_to_guide_selection:%s", userID),
 						},
 					},
@@ -185,14 +377,7 @@ func (w *Worker) handleGuideConfirmation(ctx context.Context, s *discordgo.Sessi
 	userID := parts[3]
 
 	// Verify user
-	if i.Member.User.ID != userID {
-		_ = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-			Type: discordgo.InteractionResponseChannelMessageWithSource,
-			Data: &discordgo.InteractionResponseData{
-				Content: w.i18n.T(ctx, i.GuildID, "onboarding.not_your_button"),
-				Flags:   discordgo.MessageFlagsEphemeral,
-			},
-		})
+	if !w.verifyOwner(ctx, s, i, userID) {
 		return
 	}
 
@@ -229,11 +414,12 @@ func (w *Worker) handleGuideConfirmation(ctx context.Context, s *discordgo.Sessi
 	// Start step 1 of the tutorial
 	go func() {
 		// Small delay to let the user see the confirmation message
-		time.Sleep(1 * time.Second)
+		time.Sleep(transitionDelay)
 
 		// Start Step 1 (removes entrance role, shows UI, plays audio)
 		if err := activeSession.StartStep1(guide); err != nil {
 			w.logger.Error("failed to start step 1", "error", err)
+			activeSession.Fail("step1", err)
 			return
 		}
 
@@ -253,14 +439,7 @@ func (w *Worker) handleBackToGuideSelection(ctx context.Context, s *discordgo.Se
 	userID := parts[2]
 
 	// Verify user
-	if i.Member.User.ID != userID {
-		_ = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-			Type: discordgo.InteractionResponseChannelMessageWithSource,
-			Data: &discordgo.InteractionResponseData{
-				Content: w.i18n.T(ctx, i.GuildID, "onboarding.not_your_button"),
-				Flags:   discordgo.MessageFlagsEphemeral,
-			},
-		})
+	if !w.verifyOwner(ctx, s, i, userID) {
 		return
 	}
 
@@ -272,13 +451,7 @@ func (w *Worker) handleBackToGuideSelection(ctx context.Context, s *discordgo.Se
 
 	if !exists {
 		w.logger.Error("active session not found for back to guide selection", "session_key", sessionKey)
-		_ = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-			Type: discordgo.InteractionResponseChannelMessageWithSource,
-			Data: &discordgo.InteractionResponseData{
-				Content: w.i18n.T(ctx, i.GuildID, "onboarding.session_not_found"),
-				Flags:   discordgo.MessageFlagsEphemeral,
-			},
-		})
+		w.offerRestart(ctx, s, i, userID)
 		return
 	}
 
@@ -313,6 +486,11 @@ func (w *Worker) handleBackToGuideSelection(ctx context.Context, s *discordgo.Se
 		return
 	}
 
+	// Guide selection may now be showing in a later message than the pinned one
+	if i.Message != nil {
+		activeSession.PinGuideMessage(i.Message.ID)
+	}
+
 	w.logger.Info("user went back to guide selection", "user_id", userID)
 }
 
@@ -328,14 +506,7 @@ func (w *Worker) handleStep1Next(ctx context.Context, s *discordgo.Session, i *d
 	userID := parts[2]
 
 	// Verify user
-	if i.Member.User.ID != userID {
-		_ = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-			Type: discordgo.InteractionResponseChannelMessageWithSource,
-			Data: &discordgo.InteractionResponseData{
-				Content: w.i18n.T(ctx, i.GuildID, "onboarding.not_your_button"),
-				Flags:   discordgo.MessageFlagsEphemeral,
-			},
-		})
+	if !w.verifyOwner(ctx, s, i, userID) {
 		return
 	}
 
@@ -347,12 +518,14 @@ func (w *Worker) handleStep1Next(ctx context.Context, s *discordgo.Session, i *d
 
 	if !exists {
 		w.logger.Error("active session not found for step1 next", "session_key", sessionKey)
+		w.offerRestart(ctx, s, i, userID)
+		return
+	}
+
+	// The step was already left, e.g. by a timeout or a double click
+	if !activeSession.LeaveStep(1) {
 		_ = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-			Type: discordgo.InteractionResponseChannelMessageWithSource,
-			Data: &discordgo.InteractionResponseData{
-				Content: w.i18n.T(ctx, i.GuildID, "onboarding.session_not_found"),
-				Flags:   discordgo.MessageFlagsEphemeral,
-			},
+			Type: discordgo.InteractionResponseDeferredMessageUpdate,
 		})
 		return
 	}
@@ -360,9 +533,6 @@ func (w *Worker) handleStep1Next(ctx context.Context, s *discordgo.Session, i *d
 	// Update activity timestamp
 	activeSession.UpdateActivity()
 
-	// Stop current audio
-	activeSession.StopCurrentAudio()
-
 	// Acknowledge button click
 	err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 		Type: discordgo.InteractionResponseUpdateMessage,
@@ -372,6 +542,11 @@ func (w *Worker) handleStep1Next(ctx context.Context, s *discordgo.Session, i *d
 			Components: []discordgo.MessageComponent{}, // Clear buttons
 		},
 	})
+
+	// Stop audio once the click is acknowledged, so a slow stop can't
+	// expire the interaction
+	activeSession.StopCurrentAudio()
+
 	if err != nil {
 		w.logger.Error("failed to respond to interaction", "error", err)
 		return
@@ -382,10 +557,11 @@ func (w *Worker) handleStep1Next(ctx context.Context, s *discordgo.Session, i *d
 	// Start Step 2
 	go func() {
 		// Small delay to let the user see the transition message
-		time.Sleep(1 * time.Second)
+		time.Sleep(transitionDelay)
 
-		if err := activeSession.StartStep2(); err != nil {
+		if err := activeSession.Advance(1); err != nil {
 			w.logger.Error("failed to start step 2", "error", err)
+			activeSession.Fail("step2", err)
 			return
 		}
 
@@ -405,14 +581,7 @@ func (w *Worker) handleStep1Replay(ctx context.Context, s *discordgo.Session, i
 	userID := parts[2]
 
 	// Verify user
-	if i.Member.User.ID != userID {
-		_ = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-			Type: discordgo.InteractionResponseChannelMessageWithSource,
-			Data: &discordgo.InteractionResponseData{
-				Content: w.i18n.T(ctx, i.GuildID, "onboarding.not_your_button"),
-				Flags:   discordgo.MessageFlagsEphemeral,
-			},
-		})
+	if !w.verifyOwner(ctx, s, i, userID) {
 		return
 	}
 
@@ -424,13 +593,7 @@ func (w *Worker) handleStep1Replay(ctx context.Context, s *discordgo.Session, i
 
 	if !exists {
 		w.logger.Error("active session not found for step1 replay", "session_key", sessionKey)
-		_ = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-			Type: discordgo.InteractionResponseChannelMessageWithSource,
-			Data: &discordgo.InteractionResponseData{
-				Content: w.i18n.T(ctx, i.GuildID, "onboarding.session_not_found"),
-				Flags:   discordgo.MessageFlagsEphemeral,
-			},
-		})
+		w.offerRestart(ctx, s, i, userID)
 		return
 	}
 
@@ -467,14 +630,7 @@ func (w *Worker) handleStep2Next(ctx context.Context, s *discordgo.Session, i *d
 	userID := parts[2]
 
 	// Verify user
-	if i.Member.User.ID != userID {
-		_ = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-			Type: discordgo.InteractionResponseChannelMessageWithSource,
-			Data: &discordgo.InteractionResponseData{
-				Content: w.i18n.T(ctx, i.GuildID, "onboarding.not_your_button"),
-				Flags:   discordgo.MessageFlagsEphemeral,
-			},
-		})
+	if !w.verifyOwner(ctx, s, i, userID) {
 		return
 	}
 
@@ -486,12 +642,14 @@ func (w *Worker) handleStep2Next(ctx context.Context, s *discordgo.Session, i *d
 
 	if !exists {
 		w.logger.Error("active session not found for step2 next", "session_key", sessionKey)
+		w.offerRestart(ctx, s, i, userID)
+		return
+	}
+
+	// The step was already left, e.g. by a timeout or a double click
+	if !activeSession.LeaveStep(2) {
 		_ = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-			Type: discordgo.InteractionResponseChannelMessageWithSource,
-			Data: &discordgo.InteractionResponseData{
-				Content: w.i18n.T(ctx, i.GuildID, "onboarding.session_not_found"),
-				Flags:   discordgo.MessageFlagsEphemeral,
-			},
+			Type: discordgo.InteractionResponseDeferredMessageUpdate,
 		})
 		return
 	}
@@ -499,63 +657,41 @@ func (w *Worker) handleStep2Next(ctx context.Context, s *discordgo.Session, i *d
 	// Update activity timestamp
 	activeSession.UpdateActivity()
 
-	// Stop current audio
-	activeSession.StopCurrentAudio()
-
-	// Check if user already has 説明会③ role (skip Step 3 if they do)
-	skipStep3 := false
-	if activeSession.Setsumeikai3RoleID != "" {
-		// Get guild member to check roles
-		member, err := s.GuildMember(i.GuildID, userID)
-		if err == nil {
-			// Check if user has the setsumeikai3 role
-			for _, roleID := range member.Roles {
-				if roleID == activeSession.Setsumeikai3RoleID {
-					skipStep3 = true
-					w.logger.Info("user already has setsumeikai3 role, skipping step 3", "user_id", userID)
-					break
-				}
-			}
-		}
+	// The flow skips step 3 for members with the 説明会③ role
+	next, err := activeSession.NextStep(2)
+	if err != nil {
+		w.logger.Error("failed to find the step after step 2", "error", err)
+		activeSession.Fail("step2", err)
+		return
 	}
 
 	// Acknowledge button click
-	var responseContent string
-	if skipStep3 {
-		responseContent = "⏭️ ステップ4に進んでいます..."
-	} else {
-		responseContent = w.i18n.T(ctx, i.GuildID, "onboarding.moving_to_step3")
-	}
-
-	err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+	err = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 		Type: discordgo.InteractionResponseUpdateMessage,
 		Data: &discordgo.InteractionResponseData{
-			Content:    responseContent,
+			Content: w.i18n.TWithArgs(ctx, i.GuildID, "onboarding.moving_to_step", map[string]string{
+				"step": strconv.Itoa(next),
+			}),
 			Embeds:     []*discordgo.MessageEmbed{},
 			Components: []discordgo.MessageComponent{}, // Clear buttons
 		},
 	})
+
+	// Stop audio once the click is acknowledged, so a slow stop can't
+	// expire the interaction
+	activeSession.StopCurrentAudio()
+
 	if err != nil {
 		w.logger.Error("failed to respond to interaction", "error", err)
 		return
 	}
 
-	if skipStep3 {
-		w.logger.Info("skipping step 3, moving directly to step 4", "user_id", userID)
-		
-		// Start Step 4
-		if err := activeSession.StartStep4(); err != nil {
-			w.logger.Error("failed to start step 4", "error", err)
-			return
-		}
-	} else {
-		w.logger.Info("user clicked next, moving to step 3", "user_id", userID)
-		
-		// Start Step 3
-		if err := activeSession.StartStep3(); err != nil {
-			w.logger.Error("failed to start step 3", "error", err)
-			return
-		}
+	w.logger.Info("user clicked next, moving on from step 2", "user_id", userID, "next_step", next)
+
+	if err := activeSession.EnterStep(next); err != nil {
+		w.logger.Error("failed to start step", "error", err, "step", next)
+		activeSession.Fail(fmt.Sprintf("step%d", next), err)
+		return
 	}
 }
 
@@ -571,14 +707,7 @@ func (w *Worker) handleStep2Replay(ctx context.Context, s *discordgo.Session, i
 	userID := parts[2]
 
 	// Verify user
-	if i.Member.User.ID != userID {
-		_ = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-			Type: discordgo.InteractionResponseChannelMessageWithSource,
-			Data: &discordgo.InteractionResponseData{
-				Content: w.i18n.T(ctx, i.GuildID, "onboarding.not_your_button"),
-				Flags:   discordgo.MessageFlagsEphemeral,
-			},
-		})
+	if !w.verifyOwner(ctx, s, i, userID) {
 		return
 	}
 
@@ -590,13 +719,7 @@ func (w *Worker) handleStep2Replay(ctx context.Context, s *discordgo.Session, i
 
 	if !exists {
 		w.logger.Error("active session not found for step2 replay", "session_key", sessionKey)
-		_ = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-			Type: discordgo.InteractionResponseChannelMessageWithSource,
-			Data: &discordgo.InteractionResponseData{
-				Content: w.i18n.T(ctx, i.GuildID, "onboarding.session_not_found"),
-				Flags:   discordgo.MessageFlagsEphemeral,
-			},
-		})
+		w.offerRestart(ctx, s, i, userID)
 		return
 	}
 
@@ -634,14 +757,7 @@ func (w *Worker) handleStep3GenderSelection(ctx context.Context, s *discordgo.Se
 	userID := parts[3]
 
 	// Verify user is the one who started onboarding
-	if i.Member.User.ID != userID {
-		_ = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-			Type: discordgo.InteractionResponseChannelMessageWithSource,
-			Data: &discordgo.InteractionResponseData{
-				Content: "This button is not for you!",
-				Flags:   discordgo.MessageFlagsEphemeral,
-			},
-		})
+	if !w.verifyOwner(ctx, s, i, userID) {
 		return
 	}
 
@@ -652,18 +768,13 @@ func (w *Worker) handleStep3GenderSelection(ctx context.Context, s *discordgo.Se
 	w.sessionsMutex.RUnlock()
 
 	if !exists {
-		_ = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-			Type: discordgo.InteractionResponseChannelMessageWithSource,
-			Data: &discordgo.InteractionResponseData{
-				Content: "エラー: アクティブなセッションが見つかりません",
-				Flags:   discordgo.MessageFlagsEphemeral,
-			},
-		})
+		w.offerRestart(ctx, s, i, userID)
 		return
 	}
 
 	// Update activity timestamp
 	activeSession.UpdateActivity()
+	activeSession.RecordSelection("gender", genderType)
 
 	// Map gender type to role ID
 	var roleID string
@@ -679,25 +790,21 @@ func (w *Worker) handleStep3GenderSelection(ctx context.Context, s *discordgo.Se
 
 	// Assign role if configured
 	if roleID != "" {
-		if err := s.GuildMemberRoleAdd(i.GuildID, userID, roleID); err != nil {
+		if err := w.addRole(ctx, i.GuildID, userID, roleID); err != nil {
 			w.logger.Error("failed to add gender role", "error", err, "role_id", roleID)
 		}
 	}
 
 	// Acknowledge interaction with confirmation
-	_ = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-		Type: discordgo.InteractionResponseChannelMessageWithSource,
-		Data: &discordgo.InteractionResponseData{
-			Content: fmt.Sprintf("%s のロールを付与しました", roleName),
-		},
-	})
+	respondConfirmation(s, i, activeSession, fmt.Sprintf("%s のロールを付与しました", roleName))
 
 	// Wait before showing next selection
-	time.Sleep(1500 * time.Millisecond)
+	time.Sleep(selectionDelay)
 
 	// Show age selection next
 	if err := activeSession.ShowAgeSelection(); err != nil {
 		w.logger.Error("failed to show age selection", "error", err)
+		activeSession.Fail("step3_age", err)
 	}
 }
 
@@ -714,14 +821,7 @@ func (w *Worker) handleStep3AgeSelection(ctx context.Context, s *discordgo.Sessi
 	userID := parts[3]
 
 	// Verify user is the one who started onboarding
-	if i.Member.User.ID != userID {
-		_ = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-			Type: discordgo.InteractionResponseChannelMessageWithSource,
-			Data: &discordgo.InteractionResponseData{
-				Content: "This button is not for you!",
-				Flags:   discordgo.MessageFlagsEphemeral,
-			},
-		})
+	if !w.verifyOwner(ctx, s, i, userID) {
 		return
 	}
 
@@ -732,18 +832,13 @@ func (w *Worker) handleStep3AgeSelection(ctx context.Context, s *discordgo.Sessi
 	w.sessionsMutex.RUnlock()
 
 	if !exists {
-		_ = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-			Type: discordgo.InteractionResponseChannelMessageWithSource,
-			Data: &discordgo.InteractionResponseData{
-				Content: "エラー: アクティブなセッションが見つかりません",
-				Flags:   discordgo.MessageFlagsEphemeral,
-			},
-		})
+		w.offerRestart(ctx, s, i, userID)
 		return
 	}
 
 	// Update activity timestamp
 	activeSession.UpdateActivity()
+	activeSession.RecordSelection("age", ageType)
 
 	// Map age type to role ID
 	var roleID string
@@ -771,25 +866,21 @@ func (w *Worker) handleStep3AgeSelection(ctx context.Context, s *discordgo.Sessi
 
 	// Assign role if configured
 	if roleID != "" {
-		if err := s.GuildMemberRoleAdd(i.GuildID, userID, roleID); err != nil {
+		if err := w.addRole(ctx, i.GuildID, userID, roleID); err != nil {
 			w.logger.Error("failed to add age role", "error", err, "role_id", roleID)
 		}
 	}
 
 	// Acknowledge interaction with confirmation
-	_ = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-		Type: discordgo.InteractionResponseChannelMessageWithSource,
-		Data: &discordgo.InteractionResponseData{
-			Content: fmt.Sprintf("%s のロールを付与しました", roleName),
-		},
-	})
+	respondConfirmation(s, i, activeSession, fmt.Sprintf("%s のロールを付与しました", roleName))
 
 	// Wait before showing next selection
-	time.Sleep(1500 * time.Millisecond)
+	time.Sleep(selectionDelay)
 
 	// Show voice type selection
 	if err := activeSession.ShowVoiceTypeSelection(); err != nil {
 		w.logger.Error("failed to show voice selection", "error", err)
+		activeSession.Fail("step3_voice", err)
 	}
 }
 
@@ -804,14 +895,7 @@ func (w *Worker) handleStep3VoiceSelection(ctx context.Context, s *discordgo.Ses
 	voiceType := parts[2]
 	userID := parts[3]
 
-	if i.Member.User.ID != userID {
-		_ = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-			Type: discordgo.InteractionResponseChannelMessageWithSource,
-			Data: &discordgo.InteractionResponseData{
-				Content: "This button is not for you!",
-				Flags:   discordgo.MessageFlagsEphemeral,
-			},
-		})
+	if !w.verifyOwner(ctx, s, i, userID) {
 		return
 	}
 
@@ -821,18 +905,13 @@ func (w *Worker) handleStep3VoiceSelection(ctx context.Context, s *discordgo.Ses
 	w.sessionsMutex.RUnlock()
 
 	if !exists {
-		_ = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-			Type: discordgo.InteractionResponseChannelMessageWithSource,
-			Data: &discordgo.InteractionResponseData{
-				Content: "エラー: アクティブなセッションが見つかりません",
-				Flags:   discordgo.MessageFlagsEphemeral,
-			},
-		})
+		w.offerRestart(ctx, s, i, userID)
 		return
 	}
 
 	// Update activity timestamp
 	activeSession.UpdateActivity()
+	activeSession.RecordSelection("voice", voiceType)
 
 	var roleID string
 	var roleName string
@@ -855,22 +934,18 @@ func (w *Worker) handleStep3VoiceSelection(ctx context.Context, s *discordgo.Ses
 	}
 
 	if roleID != "" {
-		if err := s.GuildMemberRoleAdd(i.GuildID, userID, roleID); err != nil {
+		if err := w.addRole(ctx, i.GuildID, userID, roleID); err != nil {
 			w.logger.Error("failed to add voice role", "error", err, "role_id", roleID)
 		}
 	}
 
-	_ = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-		Type: discordgo.InteractionResponseChannelMessageWithSource,
-		Data: &discordgo.InteractionResponseData{
-			Content: fmt.Sprintf("%s のロールを付与しました", roleName),
-		},
-	})
+	respondConfirmation(s, i, activeSession, fmt.Sprintf("%s のロールを付与しました", roleName))
 
-	time.Sleep(1500 * time.Millisecond)
+	time.Sleep(selectionDelay)
 
 	if err := activeSession.ShowEroipuSelection(); err != nil {
 		w.logger.Error("failed to show eroipu selection", "error", err)
+		activeSession.Fail("step3_eroipu", err)
 	}
 }
 
@@ -885,14 +960,7 @@ func (w *Worker) handleStep3EroipuSelection(ctx context.Context, s *discordgo.Se
 	choice := parts[2]
 	userID := parts[3]
 
-	if i.Member.User.ID != userID {
-		_ = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-			Type: discordgo.InteractionResponseChannelMessageWithSource,
-			Data: &discordgo.InteractionResponseData{
-				Content: "This button is not for you!",
-				Flags:   discordgo.MessageFlagsEphemeral,
-			},
-		})
+	if !w.verifyOwner(ctx, s, i, userID) {
 		return
 	}
 
@@ -902,18 +970,13 @@ func (w *Worker) handleStep3EroipuSelection(ctx context.Context, s *discordgo.Se
 	w.sessionsMutex.RUnlock()
 
 	if !exists {
-		_ = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-			Type: discordgo.InteractionResponseChannelMessageWithSource,
-			Data: &discordgo.InteractionResponseData{
-				Content: "エラー: アクティブなセッションが見つかりません",
-				Flags:   discordgo.MessageFlagsEphemeral,
-			},
-		})
+		w.offerRestart(ctx, s, i, userID)
 		return
 	}
 
 	// Update activity timestamp
 	activeSession.UpdateActivity()
+	activeSession.RecordSelection("eroipu", choice)
 
 	var roleID string
 	var roleName string
@@ -927,22 +990,18 @@ func (w *Worker) handleStep3EroipuSelection(ctx context.Context, s *discordgo.Se
 	}
 
 	if roleID != "" {
-		if err := s.GuildMemberRoleAdd(i.GuildID, userID, roleID); err != nil {
+		if err := w.addRole(ctx, i.GuildID, userID, roleID); err != nil {
 			w.logger.Error("failed to add eroipu role", "error", err, "role_id", roleID)
 		}
 	}
 
-	_ = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-		Type: discordgo.InteractionResponseChannelMessageWithSource,
-		Data: &discordgo.InteractionResponseData{
-			Content: fmt.Sprintf("%s のロールを付与しました", roleName),
-		},
-	})
+	respondConfirmation(s, i, activeSession, fmt.Sprintf("%s のロールを付与しました", roleName))
 
-	time.Sleep(1500 * time.Millisecond)
+	time.Sleep(selectionDelay)
 
 	if err := activeSession.ShowNeochiOkNgSelection(); err != nil {
 		w.logger.Error("failed to show neochi selection", "error", err)
+		activeSession.Fail("step3_neochi", err)
 	}
 }
 
@@ -957,14 +1016,7 @@ func (w *Worker) handleStep3NeochiOkNgSelection(ctx context.Context, s *discordg
 	choice := parts[2]
 	userID := parts[3]
 
-	if i.Member.User.ID != userID {
-		_ = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-			Type: discordgo.InteractionResponseChannelMessageWithSource,
-			Data: &discordgo.InteractionResponseData{
-				Content: "This button is not for you!",
-				Flags:   discordgo.MessageFlagsEphemeral,
-			},
-		})
+	if !w.verifyOwner(ctx, s, i, userID) {
 		return
 	}
 
@@ -974,18 +1026,13 @@ func (w *Worker) handleStep3NeochiOkNgSelection(ctx context.Context, s *discordg
 	w.sessionsMutex.RUnlock()
 
 	if !exists {
-		_ = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-			Type: discordgo.InteractionResponseChannelMessageWithSource,
-			Data: &discordgo.InteractionResponseData{
-				Content: "エラー: アクティブなセッションが見つかりません",
-				Flags:   discordgo.MessageFlagsEphemeral,
-			},
-		})
+		w.offerRestart(ctx, s, i, userID)
 		return
 	}
 
 	// Update activity timestamp
 	activeSession.UpdateActivity()
+	activeSession.RecordSelection("neochi", choice)
 
 	var roleID string
 	var roleName string
@@ -999,22 +1046,18 @@ func (w *Worker) handleStep3NeochiOkNgSelection(ctx context.Context, s *discordg
 	}
 
 	if roleID != "" {
-		if err := s.GuildMemberRoleAdd(i.GuildID, userID, roleID); err != nil {
+		if err := w.addRole(ctx, i.GuildID, userID, roleID); err != nil {
 			w.logger.Error("failed to add neochi role", "error", err, "role_id", roleID)
 		}
 	}
 
-	_ = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-		Type: discordgo.InteractionResponseChannelMessageWithSource,
-		Data: &discordgo.InteractionResponseData{
-			Content: fmt.Sprintf("%s のロールを付与しました", roleName),
-		},
-	})
+	respondConfirmation(s, i, activeSession, fmt.Sprintf("%s のロールを付与しました", roleName))
 
-	time.Sleep(1500 * time.Millisecond)
+	time.Sleep(selectionDelay)
 
 	if err := activeSession.ShowNeochiHandlingSelection(); err != nil {
 		w.logger.Error("failed to show neochi handling selection", "error", err)
+		activeSession.Fail("step3_neochi_handling", err)
 	}
 }
 
@@ -1029,14 +1072,7 @@ func (w *Worker) handleStep3NeochiHandlingSelection(ctx context.Context, s *disc
 	choice := parts[2]
 	userID := parts[3]
 
-	if i.Member.User.ID != userID {
-		_ = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-			Type: discordgo.InteractionResponseChannelMessageWithSource,
-			Data: &discordgo.InteractionResponseData{
-				Content: "This button is not for you!",
-				Flags:   discordgo.MessageFlagsEphemeral,
-			},
-		})
+	if !w.verifyOwner(ctx, s, i, userID) {
 		return
 	}
 
@@ -1046,24 +1082,19 @@ func (w *Worker) handleStep3NeochiHandlingSelection(ctx context.Context, s *disc
 	w.sessionsMutex.RUnlock()
 
 	if !exists {
-		_ = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-			Type: discordgo.InteractionResponseChannelMessageWithSource,
-			Data: &discordgo.InteractionResponseData{
-				Content: "エラー: アクティブなセッションが見つかりません",
-				Flags:   discordgo.MessageFlagsEphemeral,
-			},
-		})
+		w.offerRestart(ctx, s, i, userID)
 		return
 	}
 
 	// Update activity timestamp
 	activeSession.UpdateActivity()
+	activeSession.RecordSelection("neochi_handling", choice)
 
 	var roleName string
 	if choice == "disconnect" {
 		// Give disconnect role
 		if activeSession.NeochiDisconnectRoleID != "" {
-			if err := s.GuildMemberRoleAdd(i.GuildID, userID, activeSession.NeochiDisconnectRoleID); err != nil {
+			if err := w.addRole(ctx, i.GuildID, userID, activeSession.NeochiDisconnectRoleID); err != nil {
 				w.logger.Error("failed to add neochi disconnect role", "error", err)
 			}
 		}
@@ -1073,17 +1104,13 @@ func (w *Worker) handleStep3NeochiHandlingSelection(ctx context.Context, s *disc
 		roleName = "寝落ち部屋"
 	}
 
-	_ = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-		Type: discordgo.InteractionResponseChannelMessageWithSource,
-		Data: &discordgo.InteractionResponseData{
-			Content: fmt.Sprintf("%s を選択しました", roleName),
-		},
-	})
+	respondConfirmation(s, i, activeSession, fmt.Sprintf("%s を選択しました", roleName))
 
-	time.Sleep(1500 * time.Millisecond)
+	time.Sleep(selectionDelay)
 
 	if err := activeSession.ShowDMSelection(); err != nil {
 		w.logger.Error("failed to show DM selection", "error", err)
+		activeSession.Fail("step3_dm", err)
 	}
 }
 
@@ -1098,14 +1125,7 @@ func (w *Worker) handleStep3DMSelection(ctx context.Context, s *discordgo.Sessio
 	choice := parts[2]
 	userID := parts[3]
 
-	if i.Member.User.ID != userID {
-		_ = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-			Type: discordgo.InteractionResponseChannelMessageWithSource,
-			Data: &discordgo.InteractionResponseData{
-				Content: "This button is not for you!",
-				Flags:   discordgo.MessageFlagsEphemeral,
-			},
-		})
+	if !w.verifyOwner(ctx, s, i, userID) {
 		return
 	}
 
@@ -1115,18 +1135,13 @@ func (w *Worker) handleStep3DMSelection(ctx context.Context, s *discordgo.Sessio
 	w.sessionsMutex.RUnlock()
 
 	if !exists {
-		_ = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-			Type: discordgo.InteractionResponseChannelMessageWithSource,
-			Data: &discordgo.InteractionResponseData{
-				Content: "エラー: アクティブなセッションが見つかりません",
-				Flags:   discordgo.MessageFlagsEphemeral,
-			},
-		})
+		w.offerRestart(ctx, s, i, userID)
 		return
 	}
 
 	// Update activity timestamp
 	activeSession.UpdateActivity()
+	activeSession.RecordSelection("dm", choice)
 
 	var roleID string
 	var roleName string
@@ -1140,22 +1155,18 @@ func (w *Worker) handleStep3DMSelection(ctx context.Context, s *discordgo.Sessio
 	}
 
 	if roleID != "" {
-		if err := s.GuildMemberRoleAdd(i.GuildID, userID, roleID); err != nil {
+		if err := w.addRole(ctx, i.GuildID, userID, roleID); err != nil {
 			w.logger.Error("failed to add dm role", "error", err, "role_id", roleID)
 		}
 	}
 
-	_ = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-		Type: discordgo.InteractionResponseChannelMessageWithSource,
-		Data: &discordgo.InteractionResponseData{
-			Content: fmt.Sprintf("%s のロールを付与しました", roleName),
-		},
-	})
+	respondConfirmation(s, i, activeSession, fmt.Sprintf("%s のロールを付与しました", roleName))
 
-	time.Sleep(1500 * time.Millisecond)
+	time.Sleep(selectionDelay)
 
 	if err := activeSession.ShowFriendSelection(); err != nil {
 		w.logger.Error("failed to show friend selection", "error", err)
+		activeSession.Fail("step3_friend", err)
 	}
 }
 
@@ -1170,14 +1181,7 @@ func (w *Worker) handleStep3FriendSelection(ctx context.Context, s *discordgo.Se
 	choice := parts[2]
 	userID := parts[3]
 
-	if i.Member.User.ID != userID {
-		_ = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-			Type: discordgo.InteractionResponseChannelMessageWithSource,
-			Data: &discordgo.InteractionResponseData{
-				Content: "This button is not for you!",
-				Flags:   discordgo.MessageFlagsEphemeral,
-			},
-		})
+	if !w.verifyOwner(ctx, s, i, userID) {
 		return
 	}
 
@@ -1187,18 +1191,13 @@ func (w *Worker) handleStep3FriendSelection(ctx context.Context, s *discordgo.Se
 	w.sessionsMutex.RUnlock()
 
 	if !exists {
-		_ = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-			Type: discordgo.InteractionResponseChannelMessageWithSource,
-			Data: &discordgo.InteractionResponseData{
-				Content: "エラー: アクティブなセッションが見つかりません",
-				Flags:   discordgo.MessageFlagsEphemeral,
-			},
-		})
+		w.offerRestart(ctx, s, i, userID)
 		return
 	}
 
 	// Update activity timestamp
 	activeSession.UpdateActivity()
+	activeSession.RecordSelection("friend", choice)
 
 	var roleID string
 	var roleName string
@@ -1212,22 +1211,18 @@ func (w *Worker) handleStep3FriendSelection(ctx context.Context, s *discordgo.Se
 	}
 
 	if roleID != "" {
-		if err := s.GuildMemberRoleAdd(i.GuildID, userID, roleID); err != nil {
+		if err := w.addRole(ctx, i.GuildID, userID, roleID); err != nil {
 			w.logger.Error("failed to add friend role", "error", err, "role_id", roleID)
 		}
 	}
 
-	_ = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-		Type: discordgo.InteractionResponseChannelMessageWithSource,
-		Data: &discordgo.InteractionResponseData{
-			Content: fmt.Sprintf("%s のロールを付与しました", roleName),
-		},
-	})
+	respondConfirmation(s, i, activeSession, fmt.Sprintf("%s のロールを付与しました", roleName))
 
-	time.Sleep(1500 * time.Millisecond)
+	time.Sleep(selectionDelay)
 
 	if err := activeSession.ShowEventSelection(); err != nil {
 		w.logger.Error("failed to show event selection", "error", err)
+		activeSession.Fail("step3_event", err)
 	}
 }
 
@@ -1242,14 +1237,7 @@ func (w *Worker) handleStep3EventSelection(ctx context.Context, s *discordgo.Ses
 	eventType := parts[2]
 	userID := parts[3]
 
-	if i.Member.User.ID != userID {
-		_ = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-			Type: discordgo.InteractionResponseChannelMessageWithSource,
-			Data: &discordgo.InteractionResponseData{
-				Content: "This button is not for you!",
-				Flags:   discordgo.MessageFlagsEphemeral,
-			},
-		})
+	if !w.verifyOwner(ctx, s, i, userID) {
 		return
 	}
 
@@ -1259,13 +1247,7 @@ func (w *Worker) handleStep3EventSelection(ctx context.Context, s *discordgo.Ses
 	w.sessionsMutex.RUnlock()
 
 	if !exists {
-		_ = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-			Type: discordgo.InteractionResponseChannelMessageWithSource,
-			Data: &discordgo.InteractionResponseData{
-				Content: "エラー: アクティブなセッションが見つかりません",
-				Flags:   discordgo.MessageFlagsEphemeral,
-			},
-		})
+		w.offerRestart(ctx, s, i, userID)
 		return
 	}
 
@@ -1273,39 +1255,84 @@ func (w *Worker) handleStep3EventSelection(ctx context.Context, s *discordgo.Ses
 	activeSession.UpdateActivity()
 
 	var roleID string
-	var roleName string
 	switch eventType {
 	case "bunnyclub":
 		roleID = activeSession.BunnyclubEventRoleID
-		roleName = "BunnyClub イベント"
 	case "user":
 		roleID = activeSession.UserEventRoleID
-		roleName = "ユーザーイベント"
+	default:
+		w.logger.Error("unknown event type", "custom_id", customID)
+		return
 	}
 
+	selected := activeSession.ToggleEvent(eventType)
 	if roleID != "" {
-		if err := s.GuildMemberRoleAdd(i.GuildID, userID, roleID); err != nil {
-			w.logger.Error("failed to add event role", "error", err, "role_id", roleID)
+		if selected {
+			if err := w.addRole(ctx, i.GuildID, userID, roleID); err != nil {
+				w.logger.Error("failed to add event role", "error", err, "role_id", roleID)
+			}
+		} else {
+			if err := w.removeRole(ctx, i.GuildID, userID, roleID); err != nil {
+				w.logger.Error("failed to remove event role", "error", err, "role_id", roleID)
+			}
 		}
 	}
 
+	// Redraw the toggles in place so they show the current selection
 	_ = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Type: discordgo.InteractionResponseUpdateMessage,
 		Data: &discordgo.InteractionResponseData{
-			Content: fmt.Sprintf("%s のロールを付与しました", roleName),
+			Components: activeSession.EventSelectionComponents(),
 		},
 	})
+}
 
-	// Don't auto-progress since users can select both event roles
-	// They'll need to wait for both selections to complete, then we show completion
-	// To handle this, we check if this is the first or second event role selection
-	// For simplicity, we'll show completion after a delay
-	go func() {
-		time.Sleep(2 * time.Second)
-		if err := activeSession.ShowStep3Completion(); err != nil {
-			w.logger.Error("failed to show step 3 completion", "error", err)
-		}
-	}()
+// handleStep3EventDone handles the 完了 button after event selection.
+func (w *Worker) handleStep3EventDone(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate, customID string) {
+	parts := strings.Split(customID, ":")
+	if len(parts) < 3 {
+		w.logger.Error("invalid event_done customID", "custom_id", customID)
+		return
+	}
+
+	userID := parts[2]
+
+	if !w.verifyOwner(ctx, s, i, userID) {
+		return
+	}
+
+	sessionKey := fmt.Sprintf("%s:%s", i.GuildID, userID)
+	w.sessionsMutex.RLock()
+	activeSession, exists := w.activeSessions[sessionKey]
+	w.sessionsMutex.RUnlock()
+
+	if !exists {
+		w.offerRestart(ctx, s, i, userID)
+		return
+	}
+
+	// 完了 was already clicked, e.g. a double click
+	if !activeSession.FinishEventSelection() {
+		_ = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseDeferredMessageUpdate,
+		})
+		return
+	}
+
+	activeSession.UpdateActivity()
+
+	// Lock in the selection so later clicks cannot change it
+	_ = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseUpdateMessage,
+		Data: &discordgo.InteractionResponseData{
+			Components: []discordgo.MessageComponent{},
+		},
+	})
+
+	if err := activeSession.ShowStep3Completion(); err != nil {
+		w.logger.Error("failed to show step 3 completion", "error", err)
+		activeSession.Fail("step3_complete", err)
+	}
 }
 
 // handleStep3Next handles the next button at the end of step 3.
@@ -1318,14 +1345,7 @@ func (w *Worker) handleStep3Next(ctx context.Context, s *discordgo.Session, i *d
 
 	userID := parts[2]
 
-	if i.Member.User.ID != userID {
-		_ = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-			Type: discordgo.InteractionResponseChannelMessageWithSource,
-			Data: &discordgo.InteractionResponseData{
-				Content: "This button is not for you!",
-				Flags:   discordgo.MessageFlagsEphemeral,
-			},
-		})
+	if !w.verifyOwner(ctx, s, i, userID) {
 		return
 	}
 
@@ -1335,43 +1355,46 @@ func (w *Worker) handleStep3Next(ctx context.Context, s *discordgo.Session, i *d
 	w.sessionsMutex.RUnlock()
 
 	if !exists {
-		_ = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-			Type: discordgo.InteractionResponseChannelMessageWithSource,
-			Data: &discordgo.InteractionResponseData{
-				Content: "エラー: アクティブなセッションが見つかりません",
-				Flags:   discordgo.MessageFlagsEphemeral,
-			},
-		})
+		w.offerRestart(ctx, s, i, userID)
 		return
 	}
 
 	// Update activity timestamp
 	activeSession.UpdateActivity()
 
+	next, err := activeSession.NextStep(3)
+	if err != nil {
+		w.logger.Error("failed to find the step after step 3", "error", err)
+		activeSession.Fail("step3", err)
+		return
+	}
+
 	// Acknowledge interaction
 	_ = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 		Type: discordgo.InteractionResponseChannelMessageWithSource,
 		Data: &discordgo.InteractionResponseData{
-			Content: "ステップ4に進んでいます...",
+			Content: w.i18n.TWithArgs(ctx, i.GuildID, "onboarding.moving_to_step", map[string]string{
+				"step": strconv.Itoa(next),
+			}),
 		},
 	})
 
 	// Add "説明会③" role if configured
 	if activeSession.Setsumeikai3RoleID != "" {
-		if err := s.GuildMemberRoleAdd(i.GuildID, userID, activeSession.Setsumeikai3RoleID); err != nil {
+		if err := w.addRole(ctx, i.GuildID, userID, activeSession.Setsumeikai3RoleID); err != nil {
 			w.logger.Warn("failed to add setsumeikai3 role", "error", err, "role_id", activeSession.Setsumeikai3RoleID)
 		} else {
 			w.logger.Info("added setsumeikai3 role", "user_id", userID, "role_id", activeSession.Setsumeikai3RoleID)
 		}
 	}
 
-	// Start Step 4
-	if err := activeSession.StartStep4(); err != nil {
-		w.logger.Error("failed to start step 4", "error", err)
+	if err := activeSession.EnterStep(next); err != nil {
+		w.logger.Error("failed to start step", "error", err, "step", next)
+		activeSession.Fail(fmt.Sprintf("step%d", next), err)
 		return
 	}
-	
-	w.logger.Info("step 3 completed, moving to step 4", "user_id", userID)
+
+	w.logger.Info("step 3 completed", "user_id", userID, "next_step", next)
 }
 
 // handleStep4Next handles the [次へ] (Next) button click in Step 4.
@@ -1386,14 +1409,7 @@ func (w *Worker) handleStep4Next(ctx context.Context, s *discordgo.Session, i *d
 	userID := parts[2]
 
 	// Verify user
-	if i.Member.User.ID != userID {
-		_ = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-			Type: discordgo.InteractionResponseChannelMessageWithSource,
-			Data: &discordgo.InteractionResponseData{
-				Content: w.i18n.T(ctx, i.GuildID, "onboarding.not_your_button"),
-				Flags:   discordgo.MessageFlagsEphemeral,
-			},
-		})
+	if !w.verifyOwner(ctx, s, i, userID) {
 		return
 	}
 
@@ -1405,12 +1421,14 @@ func (w *Worker) handleStep4Next(ctx context.Context, s *discordgo.Session, i *d
 
 	if !exists {
 		w.logger.Error("active session not found for step4 next", "session_key", sessionKey)
+		w.offerRestart(ctx, s, i, userID)
+		return
+	}
+
+	// The step was already left, e.g. by a timeout or a double click
+	if !activeSession.LeaveStep(4) {
 		_ = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-			Type: discordgo.InteractionResponseChannelMessageWithSource,
-			Data: &discordgo.InteractionResponseData{
-				Content: w.i18n.T(ctx, i.GuildID, "onboarding.session_not_found"),
-				Flags:   discordgo.MessageFlagsEphemeral,
-			},
+			Type: discordgo.InteractionResponseDeferredMessageUpdate,
 		})
 		return
 	}
@@ -1418,9 +1436,6 @@ func (w *Worker) handleStep4Next(ctx context.Context, s *discordgo.Session, i *d
 	// Update activity timestamp
 	activeSession.UpdateActivity()
 
-	// Stop current audio
-	activeSession.StopCurrentAudio()
-
 	// Acknowledge button click
 	err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 		Type: discordgo.InteractionResponseUpdateMessage,
@@ -1430,6 +1445,11 @@ func (w *Worker) handleStep4Next(ctx context.Context, s *discordgo.Session, i *d
 			Components: []discordgo.MessageComponent{}, // Clear buttons
 		},
 	})
+
+	// Stop audio once the click is acknowledged, so a slow stop can't
+	// expire the interaction
+	activeSession.StopCurrentAudio()
+
 	if err != nil {
 		w.logger.Error("failed to respond to interaction", "error", err)
 		return
@@ -1438,8 +1458,9 @@ func (w *Worker) handleStep4Next(ctx context.Context, s *discordgo.Session, i *d
 	w.logger.Info("user clicked next, moving to step 5", "user_id", userID)
 	
 	// Start Step 5
-	if err := activeSession.StartStep5(); err != nil {
+	if err := activeSession.Advance(4); err != nil {
 		w.logger.Error("failed to start step 5", "error", err)
+		activeSession.Fail("step5", err)
 		return
 	}
 }
@@ -1456,14 +1477,7 @@ func (w *Worker) handleStep4Replay(ctx context.Context, s *discordgo.Session, i
 	userID := parts[2]
 
 	// Verify user
-	if i.Member.User.ID != userID {
-		_ = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-			Type: discordgo.InteractionResponseChannelMessageWithSource,
-			Data: &discordgo.InteractionResponseData{
-				Content: w.i18n.T(ctx, i.GuildID, "onboarding.not_your_button"),
-				Flags:   discordgo.MessageFlagsEphemeral,
-			},
-		})
+	if !w.verifyOwner(ctx, s, i, userID) {
 		return
 	}
 
@@ -1475,13 +1489,7 @@ func (w *Worker) handleStep4Replay(ctx context.Context, s *discordgo.Session, i
 
 	if !exists {
 		w.logger.Error("active session not found for step4 replay", "session_key", sessionKey)
-		_ = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-			Type: discordgo.InteractionResponseChannelMessageWithSource,
-			Data: &discordgo.InteractionResponseData{
-				Content: w.i18n.T(ctx, i.GuildID, "onboarding.session_not_found"),
-				Flags:   discordgo.MessageFlagsEphemeral,
-			},
-		})
+		w.offerRestart(ctx, s, i, userID)
 		return
 	}
 
@@ -1518,14 +1526,7 @@ func (w *Worker) handleStep5Next(ctx context.Context, s *discordgo.Session, i *d
 	userID := parts[2]
 
 	// Verify user
-	if i.Member.User.ID != userID {
-		_ = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-			Type: discordgo.InteractionResponseChannelMessageWithSource,
-			Data: &discordgo.InteractionResponseData{
-				Content: w.i18n.T(ctx, i.GuildID, "onboarding.not_your_button"),
-				Flags:   discordgo.MessageFlagsEphemeral,
-			},
-		})
+	if !w.verifyOwner(ctx, s, i, userID) {
 		return
 	}
 
@@ -1537,12 +1538,14 @@ func (w *Worker) handleStep5Next(ctx context.Context, s *discordgo.Session, i *d
 
 	if !exists {
 		w.logger.Error("active session not found for step5 next", "session_key", sessionKey)
+		w.offerRestart(ctx, s, i, userID)
+		return
+	}
+
+	// The step was already left, e.g. by a timeout or a double click
+	if !activeSession.LeaveStep(5) {
 		_ = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-			Type: discordgo.InteractionResponseChannelMessageWithSource,
-			Data: &discordgo.InteractionResponseData{
-				Content: w.i18n.T(ctx, i.GuildID, "onboarding.session_not_found"),
-				Flags:   discordgo.MessageFlagsEphemeral,
-			},
+			Type: discordgo.InteractionResponseDeferredMessageUpdate,
 		})
 		return
 	}
@@ -1550,9 +1553,6 @@ func (w *Worker) handleStep5Next(ctx context.Context, s *discordgo.Session, i *d
 	// Update activity timestamp
 	activeSession.UpdateActivity()
 
-	// Stop current audio
-	activeSession.StopCurrentAudio()
-
 	// Acknowledge button click
 	err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 		Type: discordgo.InteractionResponseUpdateMessage,
@@ -1562,6 +1562,11 @@ func (w *Worker) handleStep5Next(ctx context.Context, s *discordgo.Session, i *d
 			Components: []discordgo.MessageComponent{}, // Clear buttons
 		},
 	})
+
+	// Stop audio once the click is acknowledged, so a slow stop can't
+	// expire the interaction
+	activeSession.StopCurrentAudio()
+
 	if err != nil {
 		w.logger.Error("failed to respond to interaction", "error", err)
 		return
@@ -1570,8 +1575,9 @@ func (w *Worker) handleStep5Next(ctx context.Context, s *discordgo.Session, i *d
 	w.logger.Info("user clicked next, moving to step 6", "user_id", userID)
 	
 	// Start Step 6
-	if err := activeSession.StartStep6(); err != nil {
+	if err := activeSession.Advance(5); err != nil {
 		w.logger.Error("failed to start step 6", "error", err)
+		activeSession.Fail("step6", err)
 		return
 	}
 }
@@ -1588,14 +1594,7 @@ func (w *Worker) handleStep5Replay(ctx context.Context, s *discordgo.Session, i
 	userID := parts[2]
 
 	// Verify user
-	if i.Member.User.ID != userID {
-		_ = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-			Type: discordgo.InteractionResponseChannelMessageWithSource,
-			Data: &discordgo.InteractionResponseData{
-				Content: w.i18n.T(ctx, i.GuildID, "onboarding.not_your_button"),
-				Flags:   discordgo.MessageFlagsEphemeral,
-			},
-		})
+	if !w.verifyOwner(ctx, s, i, userID) {
 		return
 	}
 
@@ -1607,13 +1606,7 @@ func (w *Worker) handleStep5Replay(ctx context.Context, s *discordgo.Session, i
 
 	if !exists {
 		w.logger.Error("active session not found for step5 replay", "session_key", sessionKey)
-		_ = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-			Type: discordgo.InteractionResponseChannelMessageWithSource,
-			Data: &discordgo.InteractionResponseData{
-				Content: w.i18n.T(ctx, i.GuildID, "onboarding.session_not_found"),
-				Flags:   discordgo.MessageFlagsEphemeral,
-			},
-		})
+		w.offerRestart(ctx, s, i, userID)
 		return
 	}
 
@@ -1650,14 +1643,7 @@ func (w *Worker) handleStep6Next(ctx context.Context, s *discordgo.Session, i *d
 	userID := parts[2]
 
 	// Verify user
-	if i.Member.User.ID != userID {
-		_ = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-			Type: discordgo.InteractionResponseChannelMessageWithSource,
-			Data: &discordgo.InteractionResponseData{
-				Content: w.i18n.T(ctx, i.GuildID, "onboarding.not_your_button"),
-				Flags:   discordgo.MessageFlagsEphemeral,
-			},
-		})
+	if !w.verifyOwner(ctx, s, i, userID) {
 		return
 	}
 
@@ -1669,12 +1655,14 @@ func (w *Worker) handleStep6Next(ctx context.Context, s *discordgo.Session, i *d
 
 	if !exists {
 		w.logger.Error("active session not found for step6 next", "session_key", sessionKey)
+		w.offerRestart(ctx, s, i, userID)
+		return
+	}
+
+	// The step was already left, e.g. by a timeout or a double click
+	if !activeSession.LeaveStep(6) {
 		_ = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-			Type: discordgo.InteractionResponseChannelMessageWithSource,
-			Data: &discordgo.InteractionResponseData{
-				Content: w.i18n.T(ctx, i.GuildID, "onboarding.session_not_found"),
-				Flags:   discordgo.MessageFlagsEphemeral,
-			},
+			Type: discordgo.InteractionResponseDeferredMessageUpdate,
 		})
 		return
 	}
@@ -1682,9 +1670,6 @@ func (w *Worker) handleStep6Next(ctx context.Context, s *discordgo.Session, i *d
 	// Update activity timestamp
 	activeSession.UpdateActivity()
 
-	// Stop current audio
-	activeSession.StopCurrentAudio()
-
 	// Acknowledge button click
 	err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 		Type: discordgo.InteractionResponseUpdateMessage,
@@ -1694,6 +1679,11 @@ func (w *Worker) handleStep6Next(ctx context.Context, s *discordgo.Session, i *d
 			Components: []discordgo.MessageComponent{}, // Clear buttons
 		},
 	})
+
+	// Stop audio once the click is acknowledged, so a slow stop can't
+	// expire the interaction
+	activeSession.StopCurrentAudio()
+
 	if err != nil {
 		w.logger.Error("failed to respond to interaction", "error", err)
 		return
@@ -1702,8 +1692,9 @@ func (w *Worker) handleStep6Next(ctx context.Context, s *discordgo.Session, i *d
 	w.logger.Info("user clicked next, moving to step 7", "user_id", userID)
 	
 	// Start Step 7
-	if err := activeSession.StartStep7(); err != nil {
+	if err := activeSession.Advance(6); err != nil {
 		w.logger.Error("failed to start step 7", "error", err)
+		activeSession.Fail("step7", err)
 		return
 	}
 }
@@ -1720,14 +1711,7 @@ func (w *Worker) handleStep6Replay(ctx context.Context, s *discordgo.Session, i
 	userID := parts[2]
 
 	// Verify user
-	if i.Member.User.ID != userID {
-		_ = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-			Type: discordgo.InteractionResponseChannelMessageWithSource,
-			Data: &discordgo.InteractionResponseData{
-				Content: w.i18n.T(ctx, i.GuildID, "onboarding.not_your_button"),
-				Flags:   discordgo.MessageFlagsEphemeral,
-			},
-		})
+	if !w.verifyOwner(ctx, s, i, userID) {
 		return
 	}
 
@@ -1739,13 +1723,7 @@ func (w *Worker) handleStep6Replay(ctx context.Context, s *discordgo.Session, i
 
 	if !exists {
 		w.logger.Error("active session not found for step6 replay", "session_key", sessionKey)
-		_ = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-			Type: discordgo.InteractionResponseChannelMessageWithSource,
-			Data: &discordgo.InteractionResponseData{
-				Content: w.i18n.T(ctx, i.GuildID, "onboarding.session_not_found"),
-				Flags:   discordgo.MessageFlagsEphemeral,
-			},
-		})
+		w.offerRestart(ctx, s, i, userID)
 		return
 	}
 
@@ -1782,14 +1760,7 @@ func (w *Worker) handleStep7Complete(ctx context.Context, s *discordgo.Session,
 	userID := parts[2]
 
 	// Verify user
-	if i.Member.User.ID != userID {
-		_ = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-			Type: discordgo.InteractionResponseChannelMessageWithSource,
-			Data: &discordgo.InteractionResponseData{
-				Content: w.i18n.T(ctx, i.GuildID, "onboarding.not_your_button"),
-				Flags:   discordgo.MessageFlagsEphemeral,
-			},
-		})
+	if !w.verifyOwner(ctx, s, i, userID) {
 		return
 	}
 
@@ -1801,22 +1772,13 @@ func (w *Worker) handleStep7Complete(ctx context.Context, s *discordgo.Session,
 
 	if !exists {
 		w.logger.Error("active session not found for step7 complete", "session_key", sessionKey)
-		_ = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-			Type: discordgo.InteractionResponseChannelMessageWithSource,
-			Data: &discordgo.InteractionResponseData{
-				Content: w.i18n.T(ctx, i.GuildID, "onboarding.session_not_found"),
-				Flags:   discordgo.MessageFlagsEphemeral,
-			},
-		})
+		w.offerRestart(ctx, s, i, userID)
 		return
 	}
 
 	// Update activity timestamp
 	activeSession.UpdateActivity()
 
-	// Stop current audio
-	activeSession.StopCurrentAudio()
-
 	// Acknowledge button click
 	err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 		Type: discordgo.InteractionResponseUpdateMessage,
@@ -1826,6 +1788,11 @@ func (w *Worker) handleStep7Complete(ctx context.Context, s *discordgo.Session,
 			Components: []discordgo.MessageComponent{}, // Clear buttons
 		},
 	})
+
+	// Stop audio once the click is acknowledged, so a slow stop can't
+	// expire the interaction
+	activeSession.StopCurrentAudio()
+
 	if err != nil {
 		w.logger.Error("failed to respond to interaction", "error", err)
 		return
@@ -1833,63 +1800,48 @@ func (w *Worker) handleStep7Complete(ctx context.Context, s *discordgo.Session,
 
 	w.logger.Info("user completed onboarding, applying final roles", "user_id", userID)
 
-	// Add "visitor" role
-	if activeSession.VisitorRoleID != "" {
-		if err := s.GuildMemberRoleAdd(i.GuildID, userID, activeSession.VisitorRoleID); err != nil {
-			w.logger.Error("failed to add visitor role", "error", err, "role_id", activeSession.VisitorRoleID)
-		} else {
-			w.logger.Info("added visitor role", "user_id", userID, "role_id", activeSession.VisitorRoleID)
-		}
-	}
-
-	// Add "会員" (member) role
-	if activeSession.MemberRoleID != "" {
-		if err := s.GuildMemberRoleAdd(i.GuildID, userID, activeSession.MemberRoleID); err != nil {
-			w.logger.Error("failed to add member role", "error", err, "role_id", activeSession.MemberRoleID)
-		} else {
-			w.logger.Info("added member role", "user_id", userID, "role_id", activeSession.MemberRoleID)
-		}
-	}
-
-	// Remove "説明会" role (setsumeikai1)
-	if activeSession.Setsumeikai1RoleID != "" {
-		if err := s.GuildMemberRoleRemove(i.GuildID, userID, activeSession.Setsumeikai1RoleID); err != nil {
-			w.logger.Error("failed to remove setsumeikai1 role", "error", err, "role_id", activeSession.Setsumeikai1RoleID)
-		} else {
-			w.logger.Info("removed setsumeikai1 role", "user_id", userID, "role_id", activeSession.Setsumeikai1RoleID)
-		}
-	}
+	// The selected guide may grant its own roles instead of the guild's,
+	// and the guild may set its own roles to grant and revoke
+	grant, revoke := activeSession.CompletionRoleChanges()
 
-	// Remove "説明会②" role (setsumeikai2)
-	if activeSession.Setsumeikai2RoleID != "" {
-		if err := s.GuildMemberRoleRemove(i.GuildID, userID, activeSession.Setsumeikai2RoleID); err != nil {
-			w.logger.Error("failed to remove setsumeikai2 role", "error", err, "role_id", activeSession.Setsumeikai2RoleID)
+	for _, roleID := range grant {
+		if err := w.addRole(ctx, i.GuildID, userID, roleID); err != nil {
+			w.logger.Error("failed to add completion role", "error", err, "role_id", roleID)
 		} else {
-			w.logger.Info("removed setsumeikai2 role", "user_id", userID, "role_id", activeSession.Setsumeikai2RoleID)
+			w.logger.Info("added completion role", "user_id", userID, "role_id", roleID)
 		}
 	}
 
-	// Remove "説明会③" role (setsumeikai3)
-	if activeSession.Setsumeikai3RoleID != "" {
-		if err := s.GuildMemberRoleRemove(i.GuildID, userID, activeSession.Setsumeikai3RoleID); err != nil {
-			w.logger.Error("failed to remove setsumeikai3 role", "error", err, "role_id", activeSession.Setsumeikai3RoleID)
+	for _, roleID := range revoke {
+		if err := w.removeRole(ctx, i.GuildID, userID, roleID); err != nil {
+			w.logger.Error("failed to remove completion role", "error", err, "role_id", roleID)
 		} else {
-			w.logger.Info("removed setsumeikai3 role", "user_id", userID, "role_id", activeSession.Setsumeikai3RoleID)
+			w.logger.Info("removed completion role", "user_id", userID, "role_id", roleID)
 		}
 	}
 
 	// Remove "Entrance" role (entrance) - MOVED FROM STEP 1
 	if activeSession.EntranceRoleID != "" {
-		if err := s.GuildMemberRoleRemove(i.GuildID, userID, activeSession.EntranceRoleID); err != nil {
+		if err := w.removeRole(ctx, i.GuildID, userID, activeSession.EntranceRoleID); err != nil {
 			w.logger.Error("failed to remove entrance role", "error", err, "role_id", activeSession.EntranceRoleID)
 		} else {
 			w.logger.Info("removed entrance role", "user_id", userID, "role_id", activeSession.EntranceRoleID)
 		}
 	}
 
+	// Remove the role given on join, unless it doubles as the entrance
+	// role removed above
+	if activeSession.InitialRoleID != "" && activeSession.InitialRoleID != activeSession.EntranceRoleID {
+		if err := w.removeRole(ctx, i.GuildID, userID, activeSession.InitialRoleID); err != nil {
+			w.logger.Error("failed to remove initial role", "error", err, "role_id", activeSession.InitialRoleID)
+		} else {
+			w.logger.Info("removed initial role", "user_id", userID, "role_id", activeSession.InitialRoleID)
+		}
+	}
+
 	// Remove "入会手続き" role (nyukai) - MOVED FROM STEP 2
 	if activeSession.NyukaiRoleID != "" {
-		if err := s.GuildMemberRoleRemove(i.GuildID, userID, activeSession.NyukaiRoleID); err != nil {
+		if err := w.removeRole(ctx, i.GuildID, userID, activeSession.NyukaiRoleID); err != nil {
 			w.logger.Error("failed to remove nyukai role", "error", err, "role_id", activeSession.NyukaiRoleID)
 		} else {
 			w.logger.Info("removed nyukai role", "user_id", userID, "role_id", activeSession.NyukaiRoleID)
@@ -1919,14 +1871,7 @@ func (w *Worker) handleStep7Replay(ctx context.Context, s *discordgo.Session, i
 	userID := parts[2]
 
 	// Verify user
-	if i.Member.User.ID != userID {
-		_ = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-			Type: discordgo.InteractionResponseChannelMessageWithSource,
-			Data: &discordgo.InteractionResponseData{
-				Content: w.i18n.T(ctx, i.GuildID, "onboarding.not_your_button"),
-				Flags:   discordgo.MessageFlagsEphemeral,
-			},
-		})
+	if !w.verifyOwner(ctx, s, i, userID) {
 		return
 	}
 
@@ -1938,13 +1883,7 @@ func (w *Worker) handleStep7Replay(ctx context.Context, s *discordgo.Session, i
 
 	if !exists {
 		w.logger.Error("active session not found for step7 replay", "session_key", sessionKey)
-		_ = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-			Type: discordgo.InteractionResponseChannelMessageWithSource,
-			Data: &discordgo.InteractionResponseData{
-				Content: w.i18n.T(ctx, i.GuildID, "onboarding.session_not_found"),
-				Flags:   discordgo.MessageFlagsEphemeral,
-			},
-		})
+		w.offerRestart(ctx, s, i, userID)
 		return
 	}
 
@@ -1969,3 +1908,66 @@ func (w *Worker) handleStep7Replay(ctx context.Context, s *discordgo.Session, i
 	w.logger.Info("replaying step 7 audio", "user_id", userID)
 }
 
+// handleStepReplayFull handles the full replay button, which re-sends the
+// current step's text and images along with its audio.
+func (w *Worker) handleStepReplayFull(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate, customID string) {
+	// Extract step and userID from customID: onboarding:step{N}_replay_full:{userID}
+	parts := strings.Split(customID, ":")
+	var step int
+	if len(parts) < 3 {
+		w.logger.Error("invalid replay_full customID", "custom_id", customID)
+		return
+	}
+	if _, err := fmt.Sscanf(parts[1], "step%d_replay_full", &step); err != nil {
+		w.logger.Error("invalid replay_full customID", "custom_id", customID)
+		return
+	}
+
+	userID := parts[2]
+
+	// Verify user
+	if !w.verifyOwner(ctx, s, i, userID) {
+		return
+	}
+
+	// Get active session
+	sessionKey := fmt.Sprintf("%s:%s", i.GuildID, userID)
+	w.sessionsMutex.RLock()
+	activeSession, exists := w.activeSessions[sessionKey]
+	w.sessionsMutex.RUnlock()
+
+	if !exists {
+		w.logger.Error("active session not found for full replay", "session_key", sessionKey)
+		w.offerRestart(ctx, s, i, userID)
+		return
+	}
+
+	// The step was already left, e.g. by a timeout or a double click
+	if activeSession.CurrentStep() != step {
+		_ = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseDeferredMessageUpdate,
+		})
+		return
+	}
+
+	activeSession.UpdateActivity()
+
+	// Clear the old buttons; the step is sent again with fresh ones
+	err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseUpdateMessage,
+		Data: &discordgo.InteractionResponseData{
+			Content:    w.i18n.T(ctx, i.GuildID, "onboarding.replaying_step"),
+			Embeds:     []*discordgo.MessageEmbed{},
+			Components: []discordgo.MessageComponent{},
+		},
+	})
+	if err != nil {
+		w.logger.Error("failed to respond to interaction", "error", err)
+		return
+	}
+
+	w.logger.Info("replaying full step", "user_id", userID, "step", step)
+	if err := activeSession.ReplayStep(step); err != nil {
+		w.logger.Error("failed to replay step", "error", err, "step", step)
+	}
+}
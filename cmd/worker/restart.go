@@ -0,0 +1,155 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"welcomebot/internal/core/queue"
+	"welcomebot/internal/onboard"
+	"welcomebot/internal/worker"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+const (
+	// restartTaskKeyPrefix holds the start task of a member's latest
+	// session, so it can be re-run after the session itself was lost.
+	restartTaskKeyPrefix = "welcomebot:onboarding:restart_task:"
+	restartTaskTTL       = 24 * time.Hour
+
+	// restartCountKeyPrefix counts restarts so a restart that keeps
+	// failing can't loop forever.
+	restartCountKeyPrefix = "welcomebot:onboarding:restarts:"
+	restartCountWindow    = time.Hour
+	maxRestarts           = 2
+)
+
+// onboardingStarter starts onboarding through the master's checks. The
+// welcome feature implements it.
+type onboardingStarter interface {
+	RestartOnboarding(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) error
+}
+
+// saveRestartTask remembers task so the member can restart from it.
+func (w *Worker) saveRestartTask(ctx context.Context, task *queue.Task, userID string) {
+	key := restartTaskKeyPrefix + task.GuildID + ":" + userID
+	if err := w.cache.SetJSON(ctx, key, task, restartTaskTTL); err != nil {
+		w.logger.Warn("failed to save restart task", "error", err, "user_id", userID)
+	}
+}
+
+// forgetRestartTask drops the saved task once the member has completed
+// onboarding, so old buttons can't start it again.
+func (w *Worker) forgetRestartTask(ctx context.Context, guildID, userID string) {
+	_ = w.cache.Delete(ctx, restartTaskKeyPrefix+guildID+":"+userID)
+	_ = w.cache.Delete(ctx, restartCountKeyPrefix+guildID+":"+userID)
+}
+
+// offerRestart answers a click whose session this worker no longer has,
+// e.g. after a restart of the worker, with a button to start over.
+func (w *Worker) offerRestart(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate, userID string) {
+	w.logger.Info("offering onboarding restart", "guild_id", i.GuildID, "user_id", userID)
+
+	_ = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content: w.i18n.T(ctx, i.GuildID, "onboarding.session_lost"),
+			Flags:   discordgo.MessageFlagsEphemeral,
+			Components: []discordgo.MessageComponent{
+				discordgo.ActionsRow{
+					Components: []discordgo.MessageComponent{
+						discordgo.Button{
+							Label:    w.i18n.T(ctx, i.GuildID, "onboarding.button_restart"),
+							Style:    w.buttonStyles.Style(worker.ButtonNext),
+							CustomID: fmt.Sprintf("onboarding:restart:%s", userID),
+						},
+					},
+				},
+			},
+		},
+	})
+}
+
+// handleRestart starts the member's onboarding over after removing the
+// channel left over from the lost session. The start itself goes through
+// the master's checks, so a restart claims a free slave and a guild slot
+// and counts against the member's attempts like a welcome button click.
+// Custom ID format: onboarding:restart:{userID}
+func (w *Worker) handleRestart(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate, customID string) {
+	userID := strings.TrimPrefix(customID, "onboarding:restart:")
+	if !w.verifyOwner(ctx, s, i, userID) {
+		return
+	}
+
+	respond := func(key string) {
+		_ = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: w.i18n.T(ctx, i.GuildID, key),
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+	}
+
+	// A second click after the new session started has nothing to restart
+	w.sessionsMutex.RLock()
+	_, running := w.activeSessions[fmt.Sprintf("%s:%s", i.GuildID, userID)]
+	w.sessionsMutex.RUnlock()
+	if running {
+		respond("welcome.session_already_active")
+		return
+	}
+
+	var task queue.Task
+	if err := w.cache.GetJSON(ctx, restartTaskKeyPrefix+i.GuildID+":"+userID, &task); err != nil {
+		w.logger.Info("no task to restart", "guild_id", i.GuildID, "user_id", userID, "error", err)
+		respond("onboarding.session_not_found")
+		return
+	}
+
+	countKey := restartCountKeyPrefix + i.GuildID + ":" + userID
+	restarts, err := w.cache.Incr(ctx, countKey)
+	if err != nil {
+		w.logger.Warn("failed to count restarts", "error", err)
+	} else if restarts == 1 {
+		_ = w.cache.Expire(ctx, countKey, restartCountWindow)
+	}
+	if restarts > maxRestarts {
+		w.logger.Warn("restart limit reached", "guild_id", i.GuildID, "user_id", userID, "restarts", restarts)
+		respond("onboarding.restart_limit")
+		return
+	}
+
+	// The lost session's record would read as an active session
+	w.deleteStaleChannel(ctx, i.GuildID, userID, i.ChannelID, task.Payload)
+
+	w.logger.Info("restarting onboarding", "guild_id", i.GuildID, "user_id", userID, "restarts", restarts)
+	if err := w.starter.RestartOnboarding(ctx, s, i); err != nil {
+		w.logger.Error("failed to restart onboarding", "error", err, "guild_id", i.GuildID, "user_id", userID)
+	}
+}
+
+// deleteStaleChannel removes the lost session's voice channel and cache
+// entry. Only a channel in an onboarding category is deleted, and never
+// the shared onboarding VC.
+func (w *Worker) deleteStaleChannel(ctx context.Context, guildID, userID, channelID string, raw map[string]interface{}) {
+	_ = w.cache.Delete(ctx, fmt.Sprintf("welcomebot:session:%s:%s", guildID, userID))
+
+	payload, err := onboard.ParseStartPayload(raw)
+	if err != nil || payload.SharedVCChannelID != "" {
+		return
+	}
+	ch, err := w.session.Channel(channelID)
+	if err != nil || ch.Type != discordgo.ChannelTypeGuildVoice {
+		return
+	}
+	if !slices.Contains(payload.Categories(), ch.ParentID) {
+		return
+	}
+	if _, err := w.session.ChannelDelete(channelID); err != nil {
+		w.logger.Warn("failed to delete stale voice channel", "channel_id", channelID, "error", err)
+	}
+}
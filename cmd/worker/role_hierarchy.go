@@ -0,0 +1,143 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"welcomebot/internal/core/discord"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/lib/pq"
+)
+
+// slaveUserKeyPrefix maps a slave ID to its bot's Discord user ID, so the
+// master can check the role hierarchy on each slave's behalf.
+const slaveUserKeyPrefix = "welcomebot:slaves:user:"
+
+// publishBotUser records this slave's bot user ID for the master.
+func (w *Worker) publishBotUser(ctx context.Context) {
+	if err := w.cache.Set(ctx, slaveUserKeyPrefix+w.slaveID, w.session.State.User.ID, 0); err != nil {
+		w.logger.Warn("failed to publish bot user id", "error", err)
+	}
+}
+
+// handleGuildCreate checks, as each guild becomes available, that this
+// bot can manage the guild's onboarding roles. A role above the bot's top
+// role is the most common reason roles silently fail to be assigned.
+func (w *Worker) handleGuildCreate(s *discordgo.Session, g *discordgo.GuildCreate) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	roleIDs, err := w.welcomeRoleIDs(ctx, g.ID)
+	if err == sql.ErrNoRows {
+		return
+	}
+	if err != nil {
+		w.logger.Warn("failed to load welcome roles for hierarchy check", "error", err, "guild_id", g.ID)
+		return
+	}
+
+	blocked, err := discord.UnmanageableRoles(s, g.ID, s.State.User.ID, roleIDs)
+	if err != nil {
+		w.logger.Warn("failed to check role hierarchy", "error", err, "guild_id", g.ID)
+		return
+	}
+	for _, role := range blocked {
+		w.logger.Error("bot cannot assign onboarding role; move the bot's role above it",
+			"guild_id", g.ID,
+			"role_id", role.ID,
+			"role_name", role.Name,
+			"role_position", role.Position,
+			"managed", role.Managed,
+		)
+	}
+}
+
+// welcomeRoleIDs returns the roles the onboarding flow grants or revokes
+// in a guild: those configured by the welcome wizard, including the
+// custom completion roles, and those of the gender, age range, voice type
+// and other roles features.
+func (w *Worker) welcomeRoleIDs(ctx context.Context, guildID string) ([]string, error) {
+	query := `
+		SELECT COALESCE(in_progress_role_id, ''), COALESCE(completed_role_id, ''),
+		       COALESCE(entrance_role_id, ''), COALESCE(nyukai_role_id, ''),
+		       COALESCE(setsumeikai_1_role_id, ''), COALESCE(setsumeikai_2_role_id, ''),
+		       COALESCE(setsumeikai_3_role_id, ''),
+		       COALESCE(member_role_id, ''), COALESCE(visitor_role_id, ''),
+		       completion_grant_role_ids, completion_revoke_role_ids
+		FROM guild_welcome_config
+		WHERE guild_id = $1
+	`
+	ids := make([]string, 9)
+	dest := make([]interface{}, len(ids))
+	for n := range ids {
+		dest[n] = &ids[n]
+	}
+
+	// Custom completion roles replace the member, visitor and 説明会 roles
+	// when the guild has them on, so a misconfigured list is caught here
+	// rather than at a member's completion
+	var grant, revoke []string
+	dest = append(dest, pq.Array(&grant), pq.Array(&revoke))
+	if err := w.db.QueryRow(ctx, query, guildID).Scan(dest...); err != nil {
+		return nil, err
+	}
+	ids = append(ids, grant...)
+	ids = append(ids, revoke...)
+
+	for _, roles := range roleFeatureQueries {
+		more := make([]string, roles.columns)
+		dest := make([]interface{}, len(more))
+		for n := range more {
+			dest[n] = &more[n]
+		}
+		err := w.db.QueryRow(ctx, roles.query, guildID).Scan(dest...)
+		if err == sql.ErrNoRows {
+			// The guild hasn't set this feature's roles up
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, more...)
+	}
+	return ids, nil
+}
+
+// roleFeatureQueries select the roles of the role features whose roles
+// the onboarding flow grants from the member's step 3 selections.
+var roleFeatureQueries = []struct {
+	query   string
+	columns int
+}{
+	{`
+		SELECT COALESCE(male_role_id, ''), COALESCE(female_role_id, '')
+		FROM guild_gender_roles
+		WHERE guild_id = $1
+	`, 2},
+	{`
+		SELECT COALESCE(age_20_early_role_id, ''), COALESCE(age_20_late_role_id, ''),
+		       COALESCE(age_30_early_role_id, ''), COALESCE(age_30_late_role_id, ''),
+		       COALESCE(age_40_early_role_id, ''), COALESCE(age_40_late_role_id, '')
+		FROM guild_age_range_config
+		WHERE guild_id = $1
+	`, 6},
+	{`
+		SELECT COALESCE(high_role_id, ''), COALESCE(mid_high_role_id, ''),
+		       COALESCE(mid_role_id, ''), COALESCE(mid_low_role_id, ''),
+		       COALESCE(low_role_id, '')
+		FROM guild_voice_type_config
+		WHERE guild_id = $1
+	`, 5},
+	{`
+		SELECT COALESCE(ero_ok_role_id, ''), COALESCE(ero_ng_role_id, ''),
+		       COALESCE(neochi_ok_role_id, ''), COALESCE(neochi_ng_role_id, ''),
+		       COALESCE(neochi_disconnect_role_id, ''),
+		       COALESCE(dm_ok_role_id, ''), COALESCE(dm_ng_role_id, ''),
+		       COALESCE(friend_ok_role_id, ''), COALESCE(friend_ng_role_id, ''),
+		       COALESCE(bunnyclub_event_role_id, ''), COALESCE(user_event_role_id, '')
+		FROM guild_other_roles_config
+		WHERE guild_id = $1
+	`, 11},
+}
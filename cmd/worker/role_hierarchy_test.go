@@ -0,0 +1,34 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"welcomebot/internal/fakes"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func TestAddRoleChecksHierarchy(t *testing.T) {
+	d := fakes.NewDiscord()
+	d.SetGuildRoles(testGuildID,
+		&discordgo.Role{ID: "role-bot", Position: 5},
+		&discordgo.Role{ID: "role-member", Position: 2},
+		&discordgo.Role{ID: "role-staff", Position: 7},
+	)
+	d.SetMemberRoles(testGuildID, fakes.BotUserID, "role-bot")
+
+	w := &Worker{session: d.Session(), logger: fakes.Logger{}}
+	ctx := context.Background()
+
+	if err := w.addRole(ctx, testGuildID, testUserID, "role-member"); err != nil {
+		t.Fatalf("expected role below the bot to be added: %v", err)
+	}
+	if err := w.addRole(ctx, testGuildID, testUserID, "role-staff"); err == nil {
+		t.Error("expected role above the bot to be refused")
+	}
+
+	if !d.HasRole(testGuildID, testUserID, "role-member") || d.HasRole(testGuildID, testUserID, "role-staff") {
+		t.Errorf("unexpected roles: %v", d.MemberRoles(testGuildID, testUserID))
+	}
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"welcomebot/internal/core/metrics"
+)
+
+// defaultSweepInterval is how often activeSessions is checked for
+// sessions that ended without being removed.
+const defaultSweepInterval = 5 * time.Minute
+
+// sweepSessions removes stale entries from activeSessions every interval
+// until ctx is cancelled. Sessions remove themselves when Start returns;
+// this catches the ones that don't, e.g. after a panic in a handler.
+func (w *Worker) sweepSessions(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			w.sweepOnce(now)
+		}
+	}
+}
+
+// sweepOnce removes the sessions that are stale at now and returns how
+// many it removed.
+func (w *Worker) sweepOnce(now time.Time) int {
+	w.sessionsMutex.Lock()
+	var swept []string
+	for key, session := range w.activeSessions {
+		if session.Stale(now) {
+			delete(w.activeSessions, key)
+			swept = append(swept, key)
+		}
+	}
+	remaining := len(w.activeSessions)
+	w.sessionsMutex.Unlock()
+
+	for _, key := range swept {
+		guildID, userID, _ := strings.Cut(key, ":")
+		w.memberRoles.Forget(guildID, userID)
+		w.logger.Warn("Removed stale session", "guild_id", guildID, "user_id", userID)
+	}
+	if len(swept) > 0 {
+		w.sessionsSwept.Add(int64(len(swept)))
+		w.logger.Info("Session sweep finished", "removed", len(swept), "active", remaining)
+	}
+	return len(swept)
+}
+
+// activeSessionCount returns the size of activeSessions.
+func (w *Worker) activeSessionCount() int {
+	w.sessionsMutex.RLock()
+	defer w.sessionsMutex.RUnlock()
+
+	return len(w.activeSessions)
+}
+
+// serveMetrics serves the worker's session metrics on addr until ctx is
+// cancelled. A steadily growing session count with no onboarding traffic
+// points at sessions that are never removed.
+func (w *Worker) serveMetrics(ctx context.Context, addr string) {
+	labels := metrics.Labels{"slave": w.slaveID}
+	registry := metrics.NewRegistry()
+	registry.Gauge("welcomebot_worker_active_sessions", "Sessions held in the worker's memory.", labels,
+		func(context.Context) (float64, error) {
+			return float64(w.activeSessionCount()), nil
+		})
+	registry.Counter("welcomebot_worker_sessions_swept_total", "Stale sessions removed by the sweeper.", labels,
+		func(context.Context) (float64, error) {
+			return float64(w.sessionsSwept.Load()), nil
+		})
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", registry.Handler())
+	server := &http.Server{Addr: addr, Handler: mux, ReadHeaderTimeout: 5 * time.Second}
+	go func() {
+		<-ctx.Done()
+		server.Close()
+	}()
+
+	w.logger.Info("Serving metrics", "addr", addr)
+	if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		w.logger.Error("Metrics server failed", "error", err)
+	}
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"welcomebot/internal/fakes"
+	"welcomebot/internal/onboard"
+	"welcomebot/internal/worker"
+)
+
+func TestSweepSessions(t *testing.T) {
+	d := fakes.NewDiscord()
+	w := &Worker{
+		session:        d.Session(),
+		logger:         fakes.Logger{},
+		activeSessions: make(map[string]*worker.OnboardingSession),
+		memberRoles:    worker.NewMemberRoles(d.Session()),
+	}
+
+	newSession := func(ctx context.Context, userID string) *worker.OnboardingSession {
+		t.Helper()
+		payload, err := onboard.ParseStartPayload(map[string]interface{}{"user_id": userID, "slave_id": "slave-1", "category_id": "category-1"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		session, err := worker.NewOnboardingSession(ctx, testGuildID, payload, w.session, fakes.NewDB(), fakes.NewCache(),
+			fakes.NewQueue(), fakes.NewQueue(), fakes.Logger{}, fakes.I18n{}, &fakes.AudioPlayer{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return session
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ended, endedCancel := context.WithCancel(context.Background())
+	endedCancel()
+
+	w.activeSessions[testGuildID+":user-live"] = newSession(ctx, "user-live")
+	w.activeSessions[testGuildID+":user-ended"] = newSession(ended, "user-ended")
+
+	if swept := w.sweepOnce(time.Now()); swept != 1 {
+		t.Errorf("expected one stale session to be removed, got %d", swept)
+	}
+	if _, ok := w.activeSessions[testGuildID+":user-live"]; !ok || w.activeSessionCount() != 1 {
+		t.Errorf("expected only the live session to remain, got %v", w.activeSessions)
+	}
+	if w.sessionsSwept.Load() != 1 {
+		t.Errorf("expected the sweep to be counted, got %d", w.sessionsSwept.Load())
+	}
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"welcomebot/internal/core/cache"
+	"welcomebot/internal/core/logger"
+)
+
+const (
+	// slaveLockKeyPrefix marks a slave ID as taken by a running worker.
+	slaveLockKeyPrefix = "welcomebot:slaves:lock:"
+
+	// slaveLockTTL is how long a claim outlives its last refresh, so the ID
+	// of a crashed worker frees up on its own.
+	slaveLockTTL = 30 * time.Second
+)
+
+var (
+	// errSlaveIDTaken means another running worker already holds the slave ID.
+	errSlaveIDTaken = errors.New("slave id is in use by another worker")
+
+	// errSlaveIDContended means the claim expired while it was being taken
+	// and someone else set it first. Claiming again finds out who holds it.
+	errSlaveIDContended = errors.New("slave id claim changed while claiming")
+)
+
+var slaveIDUnsafe = regexp.MustCompile(`[^a-z0-9_-]+`)
+
+// normalizeSlaveID lowercases the ID and replaces anything other than
+// letters, digits, '-' and '_' with '-', so it is safe to embed in cache keys.
+func normalizeSlaveID(raw string) (string, error) {
+	id := slaveIDUnsafe.ReplaceAllString(strings.ToLower(strings.TrimSpace(raw)), "-")
+	id = strings.Trim(id, "-")
+	if id == "" {
+		return "", fmt.Errorf("invalid slave id %q", raw)
+	}
+	return id, nil
+}
+
+// slaveOwner identifies this process as the holder of a slave ID claim. A
+// restarted container keeps its hostname and usually its PID, so it can
+// reclaim its own ID without waiting for the old claim to expire.
+func slaveOwner() string {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unknown"
+	}
+	return fmt.Sprintf("%s:%d", host, os.Getpid())
+}
+
+// claimSlaveID takes the slave ID for this worker. It fails with
+// errSlaveIDTaken if another worker holds it; two workers sharing an ID
+// overwrite each other's status and steal each other's tasks. A claim that
+// changes hands mid-way fails with errSlaveIDContended and can be retried.
+func claimSlaveID(ctx context.Context, c cache.Client, slaveID, owner string) error {
+	key := slaveLockKeyPrefix + slaveID
+
+	ok, err := c.SetNX(ctx, key, owner, slaveLockTTL)
+	if err != nil {
+		return fmt.Errorf("claim slave id: %w", err)
+	}
+	if ok {
+		return nil
+	}
+
+	holder, err := c.Get(ctx, key)
+	if errors.Is(err, cache.ErrNotFound) {
+		// Expired between the two calls; try once more
+		ok, err := c.SetNX(ctx, key, owner, slaveLockTTL)
+		if err != nil {
+			return fmt.Errorf("claim slave id: %w", err)
+		}
+		if !ok {
+			return errSlaveIDContended
+		}
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("read slave id claim: %w", err)
+	}
+	if holder != owner {
+		return fmt.Errorf("%w: held by %s", errSlaveIDTaken, holder)
+	}
+	if err := c.Expire(ctx, key, slaveLockTTL); err != nil {
+		return fmt.Errorf("refresh slave id claim: %w", err)
+	}
+	return nil
+}
+
+// holdSlaveID refreshes the slave ID claim until ctx is cancelled.
+func holdSlaveID(ctx context.Context, c cache.Client, log logger.Logger, slaveID, owner string) {
+	ticker := time.NewTicker(slaveLockTTL / 3)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			err := claimSlaveID(ctx, c, slaveID, owner)
+			if errors.Is(err, errSlaveIDTaken) {
+				log.Error("SLAVE ID CONFLICT: another worker claimed this slave id", "slave_id", slaveID, "error", err)
+			} else if err != nil {
+				log.Warn("failed to refresh slave id claim", "slave_id", slaveID, "error", err)
+			}
+		}
+	}
+}
+
+// releaseSlaveID drops the claim if this process still holds it.
+func releaseSlaveID(ctx context.Context, c cache.Client, slaveID, owner string) error {
+	key := slaveLockKeyPrefix + slaveID
+	holder, err := c.Get(ctx, key)
+	if err != nil || holder != owner {
+		return nil
+	}
+	return c.Delete(ctx, key)
+}
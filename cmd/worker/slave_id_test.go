@@ -0,0 +1,87 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"welcomebot/internal/fakes"
+)
+
+func TestNormalizeSlaveID(t *testing.T) {
+	tests := map[string]string{
+		"slave-1":         "slave-1",
+		" Slave-2 ":       "slave-2",
+		"slave:3":         "slave-3",
+		"pod/slave_1.xyz": "pod-slave_1-xyz",
+	}
+	for in, want := range tests {
+		got, err := normalizeSlaveID(in)
+		if err != nil {
+			t.Errorf("normalizeSlaveID(%q) error: %v", in, err)
+			continue
+		}
+		if got != want {
+			t.Errorf("normalizeSlaveID(%q) = %q, want %q", in, got, want)
+		}
+	}
+
+	for _, in := range []string{"", "  ", "***"} {
+		if _, err := normalizeSlaveID(in); err == nil {
+			t.Errorf("normalizeSlaveID(%q) should fail", in)
+		}
+	}
+}
+
+func TestClaimSlaveID(t *testing.T) {
+	ctx := context.Background()
+	c := fakes.NewCache()
+
+	if err := claimSlaveID(ctx, c, "slave-1", "host-a:1"); err != nil {
+		t.Fatalf("first claim failed: %v", err)
+	}
+	// The same process (e.g. a restarted container) may reclaim its own ID
+	if err := claimSlaveID(ctx, c, "slave-1", "host-a:1"); err != nil {
+		t.Fatalf("reclaim by owner failed: %v", err)
+	}
+	if err := claimSlaveID(ctx, c, "slave-1", "host-b:1"); !errors.Is(err, errSlaveIDTaken) {
+		t.Fatalf("expected errSlaveIDTaken, got %v", err)
+	}
+	if err := claimSlaveID(ctx, c, "slave-2", "host-b:1"); err != nil {
+		t.Fatalf("claim of a different ID failed: %v", err)
+	}
+
+	// Only the owner can release
+	if err := releaseSlaveID(ctx, c, "slave-1", "host-b:1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := claimSlaveID(ctx, c, "slave-1", "host-b:1"); !errors.Is(err, errSlaveIDTaken) {
+		t.Fatalf("non-owner release should keep the claim, got %v", err)
+	}
+	if err := releaseSlaveID(ctx, c, "slave-1", "host-a:1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := claimSlaveID(ctx, c, "slave-1", "host-b:1"); err != nil {
+		t.Fatalf("claim after release failed: %v", err)
+	}
+}
+
+// racingCache loses every SetNX, as if another worker set the key each
+// time just before.
+type racingCache struct {
+	*fakes.Cache
+}
+
+func (racingCache) SetNX(ctx context.Context, key, value string, ttl time.Duration) (bool, error) {
+	return false, nil
+}
+
+func TestClaimSlaveIDLostRace(t *testing.T) {
+	c := racingCache{fakes.NewCache()}
+
+	// The claim is gone by the time it is read, and taken again before the retry
+	if err := claimSlaveID(context.Background(), c, "slave-1", "host-a:1"); !errors.Is(err, errSlaveIDContended) {
+		t.Fatalf("expected errSlaveIDContended, got %v", err)
+	}
+}
@@ -0,0 +1,7 @@
+// Package audio streams guide audio over Discord voice connections.
+//
+// It holds the DCA player used by onboarding sessions and by the master's
+// test audio tool, along with the limits, transitions and preloading
+// applied to guide audio. The AudioPlayer interface it implements lives
+// in onboard.
+package audio
@@ -0,0 +1,174 @@
+package audio
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"welcomebot/internal/onboard"
+
+	"github.com/jonas747/dca"
+)
+
+// Limits caps the guide audio a worker will play, so an oversized
+// custom guide can't hold a worker for hours. Zero fields use
+// DefaultLimits.
+type Limits struct {
+	MaxSize     int64         // Largest file accepted, in bytes
+	MaxDuration time.Duration // Longest file accepted, and longest a stream may run
+}
+
+// DefaultLimits comfortably fits the built-in guides.
+var DefaultLimits = Limits{MaxSize: 50 << 20, MaxDuration: 15 * time.Minute}
+
+var (
+	ErrTooLarge = errors.New("audio file is too large")
+	ErrTooLong  = errors.New("audio file is too long")
+	ErrCut      = errors.New("audio file ends mid-frame")
+)
+
+// sizeUnits are the suffixes ParseLimits accepts for sizes, longest
+// first so "MB" isn't read as "B".
+var sizeUnits = []struct {
+	suffix string
+	bytes  int64
+}{
+	{"GB", 1 << 30},
+	{"MB", 1 << 20},
+	{"KB", 1 << 10},
+	{"B", 1},
+}
+
+// ParseLimits parses a comma-separated list of key=value entries, e.g.
+// "size=20MB,duration=10m". Sizes take a B, KB, MB or GB suffix. Omitted
+// keys use the defaults.
+func ParseLimits(s string) (Limits, error) {
+	l := DefaultLimits
+	for _, entry := range strings.Split(s, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		key, value, ok := strings.Cut(entry, "=")
+		if !ok {
+			return Limits{}, fmt.Errorf("invalid audio limit %q, want key=value", entry)
+		}
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+
+		switch key {
+		case "size":
+			size, err := parseSize(value)
+			if err != nil {
+				return Limits{}, err
+			}
+			l.MaxSize = size
+		case "duration":
+			duration, err := time.ParseDuration(value)
+			if err != nil || duration <= 0 {
+				return Limits{}, fmt.Errorf("invalid duration for %s: %q", key, value)
+			}
+			l.MaxDuration = duration
+		default:
+			return Limits{}, fmt.Errorf("unknown audio limit %q", key)
+		}
+	}
+	return l, nil
+}
+
+// parseSize parses a positive size such as "512KB" into bytes.
+func parseSize(value string) (int64, error) {
+	upper := strings.ToUpper(value)
+	for _, unit := range sizeUnits {
+		number, ok := strings.CutSuffix(upper, unit.suffix)
+		if !ok {
+			continue
+		}
+		n, err := strconv.ParseInt(strings.TrimSpace(number), 10, 64)
+		if err != nil || n <= 0 {
+			break
+		}
+		return n * unit.bytes, nil
+	}
+	return 0, fmt.Errorf("invalid size %q, want e.g. 50MB", value)
+}
+
+// WithDefaults fills zero fields from DefaultLimits.
+func (l Limits) WithDefaults() Limits {
+	if l.MaxSize <= 0 {
+		l.MaxSize = DefaultLimits.MaxSize
+	}
+	if l.MaxDuration <= 0 {
+		l.MaxDuration = DefaultLimits.MaxDuration
+	}
+	return l
+}
+
+// DCADuration decodes every frame of the DCA file at path and returns how
+// long it plays. It returns ErrTooLong as soon as the file runs past
+// max, and ErrCut if the last frame is incomplete, as it is after an
+// interrupted upload.
+func DCADuration(path string, max time.Duration) (time.Duration, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	decoder := dca.NewDecoder(file)
+	var duration time.Duration
+	for {
+		if _, err := decoder.OpusFrame(); err != nil {
+			if errors.Is(err, io.ErrUnexpectedEOF) {
+				return duration, fmt.Errorf("%w after %s", ErrCut, duration)
+			}
+			if errors.Is(err, io.EOF) {
+				return duration, nil
+			}
+			return duration, fmt.Errorf("read frames: %w", err)
+		}
+		duration += dcaFrameDuration
+		if duration > max {
+			return duration, fmt.Errorf("%w: over %s", ErrTooLong, max)
+		}
+	}
+}
+
+var (
+	rejectedAudioMu sync.RWMutex
+	rejectedAudio   = make(map[string]error) // By AudioPath
+)
+
+// Reject treats a guide's audio file as missing from then on, because it
+// failed the asset audit: sessions fall back to TTS or continue without
+// it, exactly as for a file that isn't installed.
+func Reject(guide, filename string, cause error) {
+	rejectedAudioMu.Lock()
+	defer rejectedAudioMu.Unlock()
+
+	rejectedAudio[onboard.AudioPath(guide, filename)] = cause
+}
+
+// Rejected returns why a guide's audio file was rejected by Reject, or
+// nil.
+func Rejected(guide, filename string) error {
+	rejectedAudioMu.RLock()
+	defer rejectedAudioMu.RUnlock()
+
+	return rejectedAudio[onboard.AudioPath(guide, filename)]
+}
+
+// Exists reports whether a guide's audio file is on disk and was not
+// rejected for being over the audio limits.
+func Exists(guide, filename string) bool {
+	if Rejected(guide, filename) != nil {
+		return false
+	}
+	_, err := os.Stat(onboard.AudioPath(guide, filename))
+	return err == nil
+}
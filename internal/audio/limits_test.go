@@ -0,0 +1,106 @@
+package audio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"welcomebot/internal/fakes"
+)
+
+func TestParseLimits(t *testing.T) {
+	l, err := ParseLimits("size=512KB, duration=90s")
+	if err != nil {
+		t.Fatalf("ParseLimits: %v", err)
+	}
+	if l.MaxSize != 512<<10 || l.MaxDuration != 90*time.Second {
+		t.Errorf("got %+v", l)
+	}
+
+	if l, err := ParseLimits(""); err != nil || l != DefaultLimits {
+		t.Errorf("empty: got %+v, %v", l, err)
+	}
+
+	for _, s := range []string{"size", "size=big", "size=0MB", "size=-1B", "duration=0s", "length=1m"} {
+		if _, err := ParseLimits(s); err == nil {
+			t.Errorf("ParseLimits(%q): expected an error", s)
+		}
+	}
+}
+
+// writeDCA writes a raw DCA file of n empty frames.
+func writeDCA(t *testing.T, path string, n int) {
+	t.Helper()
+
+	var buf bytes.Buffer
+	frame := make([]byte, 3)
+	for range n {
+		binary.Write(&buf, binary.LittleEndian, int16(len(frame)))
+		buf.Write(frame)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReject(t *testing.T) {
+	t.Chdir(t.TempDir())
+	t.Cleanup(func() {
+		rejectedAudioMu.Lock()
+		rejectedAudio = make(map[string]error)
+		rejectedAudioMu.Unlock()
+	})
+
+	writeDCA(t, "audio/kk/1-intro.dca", 50)
+	writeDCA(t, "audio/kk/2-profile.dca", 200)
+	Reject("kk", "2-profile.dca", ErrTooLong)
+
+	// Rejected files play as if they weren't installed
+	if !Exists("kk", "1-intro.dca") {
+		t.Error("expected the other file to be kept")
+	}
+	if Exists("kk", "2-profile.dca") {
+		t.Error("expected the rejected file to count as missing")
+	}
+	if err := NewDCAPlayer(t.Context(), nil, fakes.Logger{}).Play("kk", "2-profile.dca"); !errors.Is(err, ErrTooLong) {
+		t.Errorf("expected the player to refuse the rejected file, got %v", err)
+	}
+
+	// Files that aren't installed yet are left to be added later
+	if Exists("kk", "4-point.dca") {
+		t.Error("expected a missing file to count as missing")
+	}
+	writeDCA(t, "audio/kk/4-point.dca", 50)
+	if !Exists("kk", "4-point.dca") {
+		t.Error("expected a file installed later to be used")
+	}
+}
+
+func TestDCADurationReportsCutFrames(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cut.dca")
+	writeDCA(t, path, 50)
+
+	// Drop the last byte, as an interrupted upload would
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, data[:len(data)-1], 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	duration, err := DCADuration(path, time.Minute)
+	if !errors.Is(err, ErrCut) {
+		t.Fatalf("expected ErrCut, got %v", err)
+	}
+	if duration != 49*dcaFrameDuration {
+		t.Errorf("expected the whole frames to be counted, got %s", duration)
+	}
+}
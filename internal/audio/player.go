@@ -0,0 +1,338 @@
+package audio
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"welcomebot/internal/core/logger"
+	"welcomebot/internal/onboard"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/jonas747/dca"
+)
+
+// ReplayDelay gives a stopped stream time to wind down before replaying.
+const ReplayDelay = 500 * time.Millisecond
+
+// DCAPlayer streams local DCA files over a Discord voice connection.
+type DCAPlayer struct {
+	session *discordgo.Session
+	logger  logger.Logger
+	ctx     context.Context // Session lifetime; cancels playback
+
+	mu            sync.Mutex
+	voiceConn     *discordgo.VoiceConnection
+	currentStream *dca.StreamingSession // Active audio stream
+	stopStream    context.CancelFunc    // Ends the active stream's goroutine
+	guide         string                // Guide of the last played file
+	filename      string                // Last played file, for Replay
+	transitions   Transitions           // Fades and gap applied to every clip
+	played        bool                  // A clip has been streamed; later ones get the gap
+	readiness     Readiness             // Wait for a joined connection to be ready
+	limits        Limits                // Longest a stream may run
+}
+
+// NewDCAPlayer creates a file-based player. Playback stops when ctx is
+// done.
+func NewDCAPlayer(ctx context.Context, session *discordgo.Session, log logger.Logger) *DCAPlayer {
+	return &DCAPlayer{
+		session: session,
+		logger:  log,
+		ctx:     ctx,
+	}
+}
+
+// Connect joins the voice channel and waits until the connection is ready.
+// A connection that isn't ready in time is dropped and joined once more.
+func (p *DCAPlayer) Connect(ctx context.Context, guildID, channelID string) error {
+	err := p.join(ctx, guildID, channelID)
+	if !errors.Is(err, errVoiceNotReady) {
+		return err
+	}
+
+	p.logger.Warn("voice connection not ready, rejoining", "channel_id", channelID)
+	if err := p.Disconnect(ctx); err != nil {
+		p.logger.Debug("failed to drop voice connection before rejoining", "error", err)
+	}
+	return p.join(ctx, guildID, channelID)
+}
+
+// join makes one attempt at joining the voice channel.
+func (p *DCAPlayer) join(ctx context.Context, guildID, channelID string) error {
+	p.mu.Lock()
+	readiness := p.readiness.WithDefaults()
+	p.mu.Unlock()
+
+	joinCtx, cancel := context.WithTimeout(ctx, readiness.Timeout)
+	defer cancel()
+
+	// ChannelVoiceJoin returns once the connection is ready, so the time it
+	// takes is the time to ready
+	start := time.Now()
+	vc, err := p.session.ChannelVoiceJoin(joinCtx, guildID, channelID, false, true)
+	elapsed := time.Since(start)
+
+	// Kept even if the join failed, so Disconnect can drop a half-open
+	// connection before rejoining
+	if vc != nil {
+		p.mu.Lock()
+		p.voiceConn = vc
+		p.mu.Unlock()
+	}
+
+	if err != nil {
+		// Only the join's own timeout means the connection was slow to
+		// become ready; the session ending is not worth a retry
+		if errors.Is(err, context.DeadlineExceeded) && ctx.Err() == nil {
+			p.logger.Warn("voice connection not ready", "channel_id", channelID, "waited", elapsed)
+			return errVoiceNotReady
+		}
+		return fmt.Errorf("join voice: %w", err)
+	}
+
+	// Slow regions show up as a long time to ready
+	p.logger.Info("joined voice channel successfully", "channel_id", channelID, "time_to_ready", elapsed)
+	return nil
+}
+
+// SetReadiness changes how long Connect waits for the connection.
+func (p *DCAPlayer) SetReadiness(r Readiness) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.readiness = r
+}
+
+// Play plays a DCA file using a StreamingSession.
+// Playback runs in a goroutine and can be stopped via Stop().
+func (p *DCAPlayer) Play(guide, filename string) error {
+	audioPath := onboard.AudioPath(guide, filename)
+	p.logger.Info("playing audio", "path", audioPath)
+
+	// Audio preloaded at startup is streamed from memory
+	var src io.ReadCloser
+	if data, ok := preloadedAudioFile(guide, filename); ok {
+		src = io.NopCloser(bytes.NewReader(data))
+	} else {
+		// Check if file exists
+		if _, err := os.Stat(audioPath); os.IsNotExist(err) {
+			return fmt.Errorf("audio file not found: %s", audioPath)
+		}
+		if err := Rejected(guide, filename); err != nil {
+			return fmt.Errorf("audio file rejected: %s: %w", audioPath, err)
+		}
+
+		// Open DCA file
+		file, err := os.Open(audioPath)
+		if err != nil {
+			return fmt.Errorf("open audio file: %w", err)
+		}
+		src = file
+	}
+
+	if err := p.Stream(audioPath, src); err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	p.guide = guide
+	p.filename = filename
+	p.mu.Unlock()
+
+	return nil
+}
+
+// SetTransitions replaces the fades and gap applied to clips played from
+// now on.
+func (p *DCAPlayer) SetTransitions(t Transitions) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.transitions = t
+}
+
+// SetLimits changes how long a single stream may run before it is stopped.
+func (p *DCAPlayer) SetLimits(l Limits) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.limits = l
+}
+
+// Stream plays DCA data from src, replacing the active stream. src is closed
+// when playback ends, or straight away if it can't start; label identifies
+// the audio in logs.
+func (p *DCAPlayer) Stream(label string, src io.ReadCloser) error {
+	p.mu.Lock()
+	ready := p.voiceConn != nil && p.voiceConn.Status == discordgo.VoiceConnectionStatusReady
+	transitions, afterClip := p.transitions, p.played
+	p.mu.Unlock()
+
+	// Check if voice connection is ready
+	if !ready {
+		src.Close()
+		return fmt.Errorf("voice connection not ready")
+	}
+
+	// Create decoder (implements OpusReader interface), wrapped with any
+	// configured transitions. Fades buffer the clip and start ffmpeg, so
+	// this runs without the lock and Stop or Pause aren't held up.
+	source, release, err := transitions.source(dca.NewDecoder(src), afterClip)
+	if err != nil {
+		src.Close()
+		return fmt.Errorf("apply audio transitions: %w", err)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	// The connection may have gone while the source was prepared
+	if p.voiceConn == nil || p.voiceConn.Status != discordgo.VoiceConnectionStatusReady {
+		release()
+		src.Close()
+		return fmt.Errorf("voice connection not ready")
+	}
+
+	// Stop any currently playing audio
+	p.stopLocked()
+	p.played = true
+
+	// Create streaming session - this handles sending frames automatically
+	done := make(chan error)
+	stream := dca.NewStream(source, p.voiceConn, done)
+	streamCtx, stop := context.WithCancel(p.ctx)
+	p.currentStream = stream
+	p.stopStream = stop
+
+	// Files are checked when guides load, but audio that slipped past
+	// them (or TTS) still can't hold the worker past the limit. Paused
+	// time counts towards it.
+	maxDuration := p.limits.WithDefaults().MaxDuration
+
+	// Run in goroutine to allow non-blocking playback. Each stream has its
+	// own stop signal so a Stop can't be picked up by a replaced stream.
+	go func() {
+		defer src.Close()
+		defer release()
+		defer stop()
+
+		limit := time.NewTimer(maxDuration)
+		defer limit.Stop()
+
+		// Wait for playback to complete or stop signal
+		select {
+		case err := <-done:
+			if err != nil && err != io.EOF {
+				p.logger.Error("playback error", "error", err)
+			} else {
+				p.logger.Info("audio playback completed", "path", label)
+			}
+		case <-limit.C:
+			stream.SetPaused(true)
+			p.logger.Warn("audio playback exceeded the duration limit, stopping",
+				"path", label, "max_duration", maxDuration)
+		case <-streamCtx.Done():
+			stream.SetPaused(true)
+			if p.ctx.Err() != nil {
+				p.logger.Info("audio playback cancelled", "path", label)
+			} else {
+				p.logger.Info("audio playback stopped", "path", label)
+			}
+		}
+
+		p.mu.Lock()
+		if p.currentStream == stream {
+			p.currentStream = nil
+		}
+		p.mu.Unlock()
+	}()
+
+	return nil
+}
+
+// Stop pauses the active stream and ends its playback goroutine.
+func (p *DCAPlayer) Stop() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.stopLocked()
+}
+
+// stopLocked stops the active stream. p.mu must be held.
+func (p *DCAPlayer) stopLocked() {
+	if p.currentStream != nil {
+		p.currentStream.SetPaused(true)
+		p.currentStream = nil
+	}
+	if p.stopStream != nil {
+		p.stopStream()
+		p.stopStream = nil
+	}
+}
+
+// Replay stops the current stream and plays the last file again.
+func (p *DCAPlayer) Replay() error {
+	p.mu.Lock()
+	guide, filename := p.guide, p.filename
+	p.mu.Unlock()
+
+	if filename == "" {
+		return fmt.Errorf("no audio file to replay")
+	}
+
+	p.logger.Info("replaying audio", "guide", guide, "file", filename)
+
+	p.Stop()
+
+	// Small delay to ensure previous playback stops
+	time.Sleep(ReplayDelay)
+
+	return p.Play(guide, filename)
+}
+
+// Pause pauses the active stream.
+func (p *DCAPlayer) Pause() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.currentStream != nil {
+		p.currentStream.SetPaused(true)
+	}
+}
+
+// Resume continues a paused stream.
+func (p *DCAPlayer) Resume() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.currentStream != nil {
+		p.currentStream.SetPaused(false)
+	}
+}
+
+// Playing reports whether a stream is active.
+func (p *DCAPlayer) Playing() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.currentStream != nil
+}
+
+// Disconnect leaves the voice channel, if connected.
+func (p *DCAPlayer) Disconnect(ctx context.Context) error {
+	p.mu.Lock()
+	vc := p.voiceConn
+	p.voiceConn = nil
+	p.mu.Unlock()
+
+	if vc == nil {
+		return nil
+	}
+	return vc.Disconnect(ctx)
+}
@@ -0,0 +1,67 @@
+package audio
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"welcomebot/internal/fakes"
+)
+
+func TestDCAPlayerReplayWithoutAudio(t *testing.T) {
+	p := NewDCAPlayer(context.Background(), nil, fakes.Logger{})
+
+	if err := p.Replay(); err == nil {
+		t.Error("expected error when nothing has been played")
+	}
+}
+
+func TestDCAPlayerPlayMissingFile(t *testing.T) {
+	p := NewDCAPlayer(context.Background(), nil, fakes.Logger{})
+
+	if err := p.Play("missing-guide", "missing.dca"); err == nil {
+		t.Error("expected error for missing audio file")
+	}
+
+	// Failed plays are not remembered for replay
+	if err := p.Replay(); err == nil {
+		t.Error("expected replay to fail after a failed play")
+	}
+}
+
+func TestDCAPlayerPauseWithoutStream(t *testing.T) {
+	p := NewDCAPlayer(context.Background(), nil, fakes.Logger{})
+
+	// Must not panic when nothing is playing
+	p.Pause()
+	p.Resume()
+	p.Stop()
+
+	if err := p.Disconnect(context.Background()); err != nil {
+		t.Errorf("disconnect without connection: %v", err)
+	}
+}
+
+// closeRecorder records whether it was closed.
+type closeRecorder struct {
+	io.Reader
+	closed bool
+}
+
+func (r *closeRecorder) Close() error {
+	r.closed = true
+	return nil
+}
+
+func TestDCAPlayerStreamClosesSourceOnError(t *testing.T) {
+	p := NewDCAPlayer(context.Background(), nil, fakes.Logger{})
+
+	src := &closeRecorder{Reader: strings.NewReader("")}
+	if err := p.Stream("test", src); err == nil {
+		t.Fatal("expected error without a voice connection")
+	}
+	if !src.closed {
+		t.Error("expected the source closed when the stream can't start")
+	}
+}
@@ -0,0 +1,236 @@
+package audio
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"welcomebot/internal/core/cache"
+	"welcomebot/internal/core/logger"
+	"welcomebot/internal/onboard"
+)
+
+// Preload configures loading guide audio into memory at startup, so
+// sessions stream it without reading the disk. A zero Budget preloads
+// nothing.
+type Preload struct {
+	Workers int   // Guides loaded at once; zero uses DefaultPreload's
+	Budget  int64 // Most bytes of audio held in memory
+}
+
+// DefaultPreload loads two guides at a time, once a budget is set.
+var DefaultPreload = Preload{Workers: 2}
+
+// guideUsageKeyPrefix counts the sessions that picked each guide. The
+// counts live in the cache so they outlast restarts and are shared by the
+// slaves.
+const guideUsageKeyPrefix = "welcomebot:audio:usage:"
+
+var (
+	preloadedAudioMu sync.RWMutex
+	preloadedAudio   = make(map[string][]byte) // By AudioPath
+)
+
+// ParsePreload parses a comma-separated list of key=value entries,
+// e.g. "budget=256MB,workers=4". The budget takes a B, KB, MB or GB
+// suffix. Omitted keys use the defaults.
+func ParsePreload(s string) (Preload, error) {
+	p := DefaultPreload
+	for _, entry := range strings.Split(s, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		key, value, ok := strings.Cut(entry, "=")
+		if !ok {
+			return Preload{}, fmt.Errorf("invalid audio preload setting %q, want key=value", entry)
+		}
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+
+		switch key {
+		case "budget":
+			budget, err := parseSize(value)
+			if err != nil {
+				return Preload{}, err
+			}
+			p.Budget = budget
+		case "workers":
+			workers, err := strconv.Atoi(value)
+			if err != nil || workers <= 0 {
+				return Preload{}, fmt.Errorf("invalid count for %s: %q", key, value)
+			}
+			p.Workers = workers
+		default:
+			return Preload{}, fmt.Errorf("unknown audio preload setting %q", key)
+		}
+	}
+	return p, nil
+}
+
+// PreloadResult is what PreloadGuides kept in memory.
+type PreloadResult struct {
+	Loaded  []string // Guides held in memory, most used first
+	Skipped []string // Guides larger than what was left of the budget
+	Bytes   int64    // Audio held in memory
+}
+
+// guideAudio is the audio of one guide a preload would load.
+type guideAudio struct {
+	guide string
+	uses  int64
+	paths []string
+	size  int64
+}
+
+// PreloadGuides loads guides' audio into memory within the budget, going
+// through them from the most used. A guide larger than what is left of the
+// budget is skipped, though smaller ones after it may still fit. At most
+// Workers guides are read at a time, and files rejected by
+// Reject are left out, so call it after the audit.
+func PreloadGuides(ctx context.Context, c cache.Client, guides []string, cfg Preload, log logger.Logger) (PreloadResult, error) {
+	var result PreloadResult
+	if cfg.Budget <= 0 || len(guides) == 0 {
+		return result, nil
+	}
+	workers := cfg.Workers
+	if workers <= 0 {
+		workers = DefaultPreload.Workers
+	}
+
+	candidates := make([]guideAudio, len(guides))
+	for n, guide := range guides {
+		paths, size := guideAudioFiles(guide)
+		candidates[n] = guideAudio{guide: guide, paths: paths, size: size}
+	}
+	for n, uses := range guideUses(ctx, c, guides, log) {
+		candidates[n].uses = uses
+	}
+	slices.SortStableFunc(candidates, func(a, b guideAudio) int {
+		return cmp.Compare(b.uses, a.uses)
+	})
+
+	// Reserve the budget in usage order before reading anything
+	var selected []guideAudio
+	remaining := cfg.Budget
+	for _, candidate := range candidates {
+		if candidate.size > remaining {
+			log.Info("guide audio over the preload budget, skipping", "guide", candidate.guide, "size", candidate.size, "remaining", remaining)
+			result.Skipped = append(result.Skipped, candidate.guide)
+			continue
+		}
+		remaining -= candidate.size
+		selected = append(selected, candidate)
+	}
+
+	var (
+		wg     sync.WaitGroup
+		loaded = make([]bool, len(selected))
+		sem    = make(chan struct{}, workers)
+	)
+	for n, candidate := range selected {
+		if ctx.Err() != nil {
+			break
+		}
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(n int, candidate guideAudio) {
+			defer func() { <-sem; wg.Done() }()
+
+			files := make(map[string][]byte, len(candidate.paths))
+			for _, path := range candidate.paths {
+				data, err := os.ReadFile(path)
+				if err != nil {
+					log.Warn("failed to preload guide audio", "guide", candidate.guide, "path", path, "error", err)
+					return
+				}
+				files[path] = data
+			}
+
+			preloadedAudioMu.Lock()
+			for path, data := range files {
+				preloadedAudio[path] = data
+			}
+			preloadedAudioMu.Unlock()
+			loaded[n] = true
+		}(n, candidate)
+	}
+	wg.Wait()
+
+	for n, candidate := range selected {
+		if loaded[n] {
+			result.Loaded = append(result.Loaded, candidate.guide)
+			result.Bytes += candidate.size
+		}
+	}
+	return result, ctx.Err()
+}
+
+// guideAudioFiles returns the paths of a guide's DCA files, locale
+// variants included, and their total size. Rejected files are left out.
+func guideAudioFiles(guide string) ([]string, int64) {
+	var (
+		paths []string
+		size  int64
+	)
+	filepath.WalkDir(onboard.GuideDir(guide), func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() || filepath.Ext(path) != ".dca" {
+			return nil
+		}
+		rejectedAudioMu.RLock()
+		rejected := rejectedAudio[path] != nil
+		rejectedAudioMu.RUnlock()
+		if rejected {
+			return nil
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return nil
+		}
+		paths = append(paths, path)
+		size += info.Size()
+		return nil
+	})
+	return paths, size
+}
+
+// guideUses returns how many sessions picked each guide, in one cache
+// round trip. Guides without a count, or all of them if the cache can't
+// be read, count as unused.
+func guideUses(ctx context.Context, c cache.Client, guides []string, log logger.Logger) []int64 {
+	uses := make([]int64, len(guides))
+	keys := make([]string, len(guides))
+	dests := make([]interface{}, len(guides))
+	for n, guide := range guides {
+		keys[n] = guideUsageKeyPrefix + guide
+		dests[n] = &uses[n]
+	}
+	if _, err := c.GetJSONMulti(ctx, keys, dests...); err != nil {
+		log.Warn("failed to read guide usage, preloading guides in the order given", "error", err)
+	}
+	return uses
+}
+
+// preloadedAudioFile returns a guide file held in memory by PreloadGuides.
+func preloadedAudioFile(guide, filename string) ([]byte, bool) {
+	preloadedAudioMu.RLock()
+	defer preloadedAudioMu.RUnlock()
+
+	data, ok := preloadedAudio[onboard.AudioPath(guide, filename)]
+	return data, ok
+}
+
+// CountGuideUse counts a session's guide towards the order PreloadGuides
+// loads guides in.
+func CountGuideUse(ctx context.Context, c cache.Client, guide string) error {
+	_, err := c.Incr(ctx, guideUsageKeyPrefix+guide)
+	return err
+}
@@ -0,0 +1,81 @@
+package audio
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"welcomebot/internal/fakes"
+)
+
+func TestParsePreload(t *testing.T) {
+	p, err := ParsePreload("budget=256MB, workers=4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Budget != 256<<20 || p.Workers != 4 {
+		t.Errorf("got %+v", p)
+	}
+
+	if p, err := ParsePreload(""); err != nil || p != DefaultPreload {
+		t.Errorf("expected the defaults, got %+v, %v", p, err)
+	}
+	for _, invalid := range []string{"budget", "budget=lots", "workers=0", "threads=2"} {
+		if _, err := ParsePreload(invalid); err == nil {
+			t.Errorf("expected %q to be rejected", invalid)
+		}
+	}
+}
+
+func TestPreloadGuides(t *testing.T) {
+	t.Chdir(t.TempDir())
+	t.Cleanup(func() {
+		preloadedAudioMu.Lock()
+		preloadedAudio = make(map[string][]byte)
+		preloadedAudioMu.Unlock()
+	})
+
+	for path, size := range map[string]int{
+		"kk/1-intro.dca":    600,
+		"kk/ja/1-intro.dca": 300,
+		"mio/1-intro.dca":   700,
+		"rin/1-intro.dca":   100,
+	} {
+		path = filepath.Join("audio", path)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, make([]byte, size), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ctx := context.Background()
+	c := fakes.NewCache()
+	// mio is picked most, then kk; rin has never been picked
+	for range 3 {
+		CountGuideUse(ctx, c, "mio")
+	}
+	CountGuideUse(ctx, c, "kk")
+
+	// kk's 900 bytes don't fit after mio's 700, but rin's 100 do
+	result, err := PreloadGuides(ctx, c, []string{"kk", "mio", "rin"}, Preload{Workers: 2, Budget: 1000}, fakes.Logger{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(result.Loaded, []string{"mio", "rin"}) || !reflect.DeepEqual(result.Skipped, []string{"kk"}) {
+		t.Errorf("expected mio and rin loaded and kk skipped, got %+v", result)
+	}
+	if result.Bytes != 800 {
+		t.Errorf("expected 800 bytes held, got %d", result.Bytes)
+	}
+
+	if data, ok := preloadedAudioFile("mio", "1-intro.dca"); !ok || len(data) != 700 {
+		t.Errorf("expected mio's audio in memory, got %d bytes", len(data))
+	}
+	if _, ok := preloadedAudioFile("kk", "1-intro.dca"); ok {
+		t.Error("expected kk's audio to be left on disk")
+	}
+}
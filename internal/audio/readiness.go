@@ -0,0 +1,60 @@
+package audio
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// errVoiceNotReady is returned when a voice connection doesn't become
+// ready in time.
+var errVoiceNotReady = errors.New("timeout waiting for voice connection to be ready")
+
+// Readiness controls how Connect waits for a new voice connection.
+// Zero fields use DefaultReadiness.
+type Readiness struct {
+	Timeout time.Duration // Wait for each join attempt; one retry follows a timeout
+}
+
+// DefaultReadiness suits most voice regions.
+var DefaultReadiness = Readiness{Timeout: 10 * time.Second}
+
+// ParseReadiness parses a comma-separated list of key=duration
+// entries, e.g. "timeout=20s". Omitted keys use the defaults.
+func ParseReadiness(s string) (Readiness, error) {
+	r := DefaultReadiness
+	for _, entry := range strings.Split(s, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		key, value, ok := strings.Cut(entry, "=")
+		if !ok {
+			return Readiness{}, fmt.Errorf("invalid voice readiness setting %q, want key=duration", entry)
+		}
+		key = strings.TrimSpace(key)
+
+		duration, err := time.ParseDuration(strings.TrimSpace(value))
+		if err != nil || duration <= 0 {
+			return Readiness{}, fmt.Errorf("invalid duration for %s: %q", key, value)
+		}
+
+		switch key {
+		case "timeout":
+			r.Timeout = duration
+		default:
+			return Readiness{}, fmt.Errorf("unknown voice readiness setting %q", key)
+		}
+	}
+	return r, nil
+}
+
+// WithDefaults fills zero fields from DefaultReadiness.
+func (r Readiness) WithDefaults() Readiness {
+	if r.Timeout <= 0 {
+		r.Timeout = DefaultReadiness.Timeout
+	}
+	return r
+}
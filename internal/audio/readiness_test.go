@@ -0,0 +1,40 @@
+package audio
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseReadiness(t *testing.T) {
+	readiness, err := ParseReadiness(" timeout=20s ")
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	want := Readiness{Timeout: 20 * time.Second}
+	if readiness != want {
+		t.Errorf("got %+v, want %+v", readiness, want)
+	}
+
+	if readiness, err := ParseReadiness(""); err != nil || readiness != DefaultReadiness {
+		t.Errorf("empty: got %+v, %v; want the defaults", readiness, err)
+	}
+
+	for _, bad := range []string{
+		"timeout",
+		"timeout=soon",
+		"timeout=0s",
+		"timeout=-1s",
+		"poll=250ms",
+		"retries=2s",
+	} {
+		if _, err := ParseReadiness(bad); err == nil {
+			t.Errorf("ParseReadiness(%q) should fail", bad)
+		}
+	}
+}
+
+func TestReadinessDefaults(t *testing.T) {
+	if (Readiness{}).WithDefaults() != DefaultReadiness {
+		t.Error("expected a zero Readiness to use the defaults")
+	}
+}
@@ -0,0 +1,249 @@
+package audio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/jonas747/dca"
+)
+
+const (
+	// dcaFrameDuration and opusFrameSamples describe one frame of the DCA
+	// files the bot plays (dca.StdEncodeOptions: 20ms at 48kHz).
+	dcaFrameDuration = 20 * time.Millisecond
+	opusFrameSamples = 960
+)
+
+// opusSilence is the Opus frame Discord recommends for silence.
+var opusSilence = []byte{0xF8, 0xFF, 0xFE}
+
+// Transitions smooths the edges of guide audio. The zero value plays
+// each clip exactly as recorded, straight after the previous one.
+type Transitions struct {
+	FadeIn  time.Duration // Volume ramp up at the start of each clip
+	FadeOut time.Duration // Volume ramp down at the end of each clip
+	Gap     time.Duration // Silence before each clip after the first
+}
+
+// ParseTransitions parses a comma-separated list of key=duration
+// entries, e.g. "fade_in=150ms,fade_out=300ms,gap=500ms". Omitted keys
+// stay zero.
+func ParseTransitions(s string) (Transitions, error) {
+	var t Transitions
+	for _, entry := range strings.Split(s, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		key, value, ok := strings.Cut(entry, "=")
+		if !ok {
+			return Transitions{}, fmt.Errorf("invalid audio transition %q, want key=duration", entry)
+		}
+		key = strings.TrimSpace(key)
+
+		duration, err := time.ParseDuration(strings.TrimSpace(value))
+		if err != nil || duration < 0 {
+			return Transitions{}, fmt.Errorf("invalid duration for %s: %q", key, value)
+		}
+
+		switch key {
+		case "fade_in":
+			t.FadeIn = duration
+		case "fade_out":
+			t.FadeOut = duration
+		case "gap":
+			t.Gap = duration
+		default:
+			return Transitions{}, fmt.Errorf("unknown audio transition %q", key)
+		}
+	}
+	return t, nil
+}
+
+// fades reports whether clips need re-encoding to apply a fade.
+func (t Transitions) fades() bool {
+	return t.FadeIn > 0 || t.FadeOut > 0
+}
+
+// Check reports whether the transitions can be applied on this host. Fades
+// re-encode clips with ffmpeg, so it must be on PATH.
+func (t Transitions) Check() error {
+	if !t.fades() {
+		return nil
+	}
+	if _, err := exec.LookPath("ffmpeg"); err != nil {
+		return fmt.Errorf("fades need ffmpeg: %w", err)
+	}
+	return nil
+}
+
+// filter returns the ffmpeg audio filter fading a clip of the given length.
+func (t Transitions) filter(length time.Duration) string {
+	var filters []string
+	if t.FadeIn > 0 {
+		filters = append(filters, fmt.Sprintf("afade=t=in:d=%.3f", t.FadeIn.Seconds()))
+	}
+	if t.FadeOut > 0 {
+		start := length - t.FadeOut
+		if start < 0 {
+			start = 0
+		}
+		filters = append(filters, fmt.Sprintf("afade=t=out:st=%.3f:d=%.3f", start.Seconds(), t.FadeOut.Seconds()))
+	}
+	return strings.Join(filters, ",")
+}
+
+// source wraps a clip's frames with the configured fades, and with the gap
+// when afterClip is set. release stops any encoder the fades started and
+// must be called once playback ends.
+func (t Transitions) source(frames dca.OpusReader, afterClip bool) (dca.OpusReader, func(), error) {
+	source, release := frames, func() {}
+
+	// Fading needs PCM, so the clip is decoded and re-encoded by ffmpeg.
+	// Frames are streamed as they are encoded, so playback doesn't wait for
+	// the whole clip.
+	if t.fades() {
+		var clip [][]byte
+		for {
+			frame, err := frames.OpusFrame()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return nil, nil, fmt.Errorf("read audio frames: %w", err)
+			}
+			clip = append(clip, frame)
+		}
+		if len(clip) == 0 {
+			return nil, nil, fmt.Errorf("audio clip is empty")
+		}
+
+		options := *dca.StdEncodeOptions
+		options.AudioFilter = t.filter(time.Duration(len(clip)) * dcaFrameDuration)
+		encoder, err := dca.EncodeMem(bytes.NewReader(oggOpus(clip)), &options)
+		if err != nil {
+			return nil, nil, fmt.Errorf("start fade encoder: %w", err)
+		}
+		source, release = encoder, encoder.Cleanup
+	}
+
+	if afterClip && t.Gap > 0 {
+		source = &silenceReader{frames: int(t.Gap / dcaFrameDuration), next: source}
+	}
+	return source, release, nil
+}
+
+// silenceReader sends a number of silent frames before the frames of next.
+type silenceReader struct {
+	frames int
+	next   dca.OpusReader
+}
+
+func (r *silenceReader) OpusFrame() ([]byte, error) {
+	if r.frames > 0 {
+		r.frames--
+		return opusSilence, nil
+	}
+	return r.next.OpusFrame()
+}
+
+func (r *silenceReader) FrameDuration() time.Duration {
+	return r.next.FrameDuration()
+}
+
+const (
+	oggBOS = 0x02 // First page of a stream
+	oggEOS = 0x04 // Last page of a stream
+)
+
+// oggOpus muxes Opus frames into an Ogg Opus stream, one frame per page,
+// so ffmpeg can decode audio that was only kept as DCA.
+func oggOpus(frames [][]byte) []byte {
+	w := &oggWriter{serial: 1}
+
+	// Version 1, stereo, no pre-skip, 48kHz, no gain, mapping family 0
+	head := make([]byte, 19)
+	copy(head, "OpusHead")
+	head[8] = 1
+	head[9] = 2
+	binary.LittleEndian.PutUint32(head[12:], 48000)
+	w.page(head, 0, oggBOS)
+
+	// Empty vendor string and no comments
+	tags := append([]byte("OpusTags"), 0, 0, 0, 0, 0, 0, 0, 0)
+	w.page(tags, 0, 0)
+
+	var granule int64
+	for i, frame := range frames {
+		granule += opusFrameSamples
+		var flags byte
+		if i == len(frames)-1 {
+			flags = oggEOS
+		}
+		w.page(frame, granule, flags)
+	}
+	return w.buf.Bytes()
+}
+
+// oggWriter writes single-packet Ogg pages.
+type oggWriter struct {
+	buf    bytes.Buffer
+	serial uint32
+	seq    uint32
+}
+
+func (w *oggWriter) page(packet []byte, granule int64, flags byte) {
+	// Packets end with a lacing value below 255, so one that is an exact
+	// multiple of 255 gets a trailing zero
+	segments := len(packet)/255 + 1
+
+	header := make([]byte, 27+segments)
+	copy(header, "OggS")
+	header[5] = flags
+	binary.LittleEndian.PutUint64(header[6:], uint64(granule))
+	binary.LittleEndian.PutUint32(header[14:], w.serial)
+	binary.LittleEndian.PutUint32(header[18:], w.seq)
+	header[26] = byte(segments)
+	for i := 0; i < segments-1; i++ {
+		header[27+i] = 255
+	}
+	header[27+segments-1] = byte(len(packet) % 255)
+
+	// The checksum covers the page with its own field zeroed
+	binary.LittleEndian.PutUint32(header[22:], oggCRC(oggCRC(0, header), packet))
+	w.seq++
+
+	w.buf.Write(header)
+	w.buf.Write(packet)
+}
+
+// oggCRCTable is the CRC-32 table for Ogg's polynomial 0x04c11db7, which,
+// unlike hash/crc32, is not bit-reflected.
+var oggCRCTable = func() [256]uint32 {
+	var table [256]uint32
+	for i := range table {
+		r := uint32(i) << 24
+		for j := 0; j < 8; j++ {
+			if r&0x80000000 != 0 {
+				r = r<<1 ^ 0x04c11db7
+			} else {
+				r <<= 1
+			}
+		}
+		table[i] = r
+	}
+	return table
+}()
+
+func oggCRC(crc uint32, data []byte) uint32 {
+	for _, b := range data {
+		crc = crc<<8 ^ oggCRCTable[byte(crc>>24)^b]
+	}
+	return crc
+}
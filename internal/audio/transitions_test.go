@@ -0,0 +1,148 @@
+package audio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"testing"
+	"time"
+)
+
+func TestParseTransitions(t *testing.T) {
+	transitions, err := ParseTransitions("fade_in=150ms, gap=1s")
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	want := Transitions{FadeIn: 150 * time.Millisecond, Gap: time.Second}
+	if transitions != want {
+		t.Errorf("got %+v, want %+v", transitions, want)
+	}
+
+	if transitions, err := ParseTransitions(""); err != nil || transitions != (Transitions{}) {
+		t.Errorf("empty: got %+v, %v; want no transitions", transitions, err)
+	}
+
+	for _, bad := range []string{
+		"fade_in",
+		"fade_in=soon",
+		"fade_out=-1s",
+		"crossfade=1s",
+	} {
+		if _, err := ParseTransitions(bad); err == nil {
+			t.Errorf("ParseTransitions(%q) should fail", bad)
+		}
+	}
+}
+
+func TestTransitionsFilter(t *testing.T) {
+	transitions := Transitions{FadeIn: 200 * time.Millisecond, FadeOut: 500 * time.Millisecond}
+	if got, want := transitions.filter(3*time.Second), "afade=t=in:d=0.200,afade=t=out:st=2.500:d=0.500"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	// A fade-out longer than the clip starts at its beginning
+	if got, want := (Transitions{FadeOut: time.Second}).filter(400*time.Millisecond), "afade=t=out:st=0.000:d=1.000"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+// frameList is an OpusReader over fixed frames.
+type frameList [][]byte
+
+func (l *frameList) OpusFrame() ([]byte, error) {
+	if len(*l) == 0 {
+		return nil, io.EOF
+	}
+	frame := (*l)[0]
+	*l = (*l)[1:]
+	return frame, nil
+}
+
+func (l *frameList) FrameDuration() time.Duration { return dcaFrameDuration }
+
+func TestTransitionsGap(t *testing.T) {
+	transitions := Transitions{Gap: 100 * time.Millisecond}
+
+	frames := &frameList{[]byte("a")}
+	source, release, err := transitions.source(frames, false)
+	if err != nil {
+		t.Fatalf("source: %v", err)
+	}
+	defer release()
+	if frame, _ := source.OpusFrame(); string(frame) != "a" {
+		t.Errorf("expected the first clip to start without a gap, got %v", frame)
+	}
+
+	frames = &frameList{[]byte("a")}
+	source, release, err = transitions.source(frames, true)
+	if err != nil {
+		t.Fatalf("source: %v", err)
+	}
+	defer release()
+	for i := 0; i < 5; i++ {
+		if frame, _ := source.OpusFrame(); !bytes.Equal(frame, opusSilence) {
+			t.Fatalf("frame %d: expected silence, got %v", i, frame)
+		}
+	}
+	if frame, _ := source.OpusFrame(); string(frame) != "a" {
+		t.Errorf("expected the clip after 5 silent frames, got %v", frame)
+	}
+}
+
+func TestOggCRC(t *testing.T) {
+	// Check value for CRC-32 with Ogg's parameters
+	if got := oggCRC(0, []byte("123456789")); got != 0x89a1897f {
+		t.Errorf("got %#x, want 0x89a1897f", got)
+	}
+}
+
+func TestOggOpusPages(t *testing.T) {
+	data := oggOpus([][]byte{[]byte("one"), bytes.Repeat([]byte{1}, 255)})
+
+	type page struct {
+		flags   byte
+		granule uint64
+		packet  []byte
+	}
+	var pages []page
+	for len(data) > 0 {
+		if !bytes.HasPrefix(data, []byte("OggS")) {
+			t.Fatalf("page %d: missing capture pattern", len(pages))
+		}
+		segments := int(data[26])
+		size := 0
+		for _, lacing := range data[27 : 27+segments] {
+			size += int(lacing)
+		}
+		end := 27 + segments + size
+
+		header := append([]byte(nil), data[:27+segments]...)
+		crc := binary.LittleEndian.Uint32(header[22:])
+		binary.LittleEndian.PutUint32(header[22:], 0)
+		if got := oggCRC(oggCRC(0, header), data[27+segments:end]); got != crc {
+			t.Errorf("page %d: checksum %#x, want %#x", len(pages), crc, got)
+		}
+		if seq := binary.LittleEndian.Uint32(data[18:]); seq != uint32(len(pages)) {
+			t.Errorf("page %d: sequence number %d", len(pages), seq)
+		}
+
+		pages = append(pages, page{data[5], binary.LittleEndian.Uint64(data[6:]), data[27+segments : end]})
+		data = data[end:]
+	}
+
+	if len(pages) != 4 {
+		t.Fatalf("expected header, tags and 2 audio pages, got %d pages", len(pages))
+	}
+	if pages[0].flags != oggBOS || !bytes.HasPrefix(pages[0].packet, []byte("OpusHead")) {
+		t.Errorf("expected the stream to begin with OpusHead, got %+v", pages[0])
+	}
+	if !bytes.HasPrefix(pages[1].packet, []byte("OpusTags")) {
+		t.Errorf("expected OpusTags second, got %+v", pages[1])
+	}
+	if string(pages[2].packet) != "one" || pages[2].granule != 960 || pages[2].flags != 0 {
+		t.Errorf("unexpected first audio page %+v", pages[2])
+	}
+	if len(pages[3].packet) != 255 || pages[3].granule != 1920 || pages[3].flags != oggEOS {
+		t.Errorf("unexpected last audio page %+v", pages[3])
+	}
+}
@@ -3,12 +3,14 @@ package bot
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"welcomebot/internal/core/cache"
 	"welcomebot/internal/core/database"
 	"welcomebot/internal/core/discord"
 	"welcomebot/internal/core/i18n"
 	"welcomebot/internal/core/logger"
+	"welcomebot/internal/core/metrics"
 	"welcomebot/internal/core/queue"
 
 	"github.com/bwmarrin/discordgo"
@@ -19,6 +21,7 @@ type Bot struct {
 	session  *discordgo.Session
 	registry *Registry
 	logger   logger.Logger
+	latency  *latencyTracker
 }
 
 // Config contains bot configuration.
@@ -28,6 +31,10 @@ type Config struct {
 	Cache    cache.Config
 	Queue    queue.Config
 	Logger   logger.Config
+
+	// SlowInteraction is the latency above which an interaction is logged;
+	// 0 uses DefaultSlowInteraction
+	SlowInteraction time.Duration
 }
 
 // Dependencies contains all bot dependencies.
@@ -55,6 +62,15 @@ func New(cfg Config) (*Bot, *Dependencies, error) {
 	}
 
 	// Initialize cache
+	if cfg.Cache.OnStateChange == nil {
+		cfg.Cache.OnStateChange = func(degraded bool) {
+			if degraded {
+				log.Error("CACHE DEGRADED: redis unreachable, config reads falling back to database")
+				return
+			}
+			log.Info("cache recovered, leaving degraded mode")
+		}
+	}
 	cacheClient, err := cache.New(cfg.Cache)
 	if err != nil {
 		return nil, nil, fmt.Errorf("create cache: %w", err)
@@ -102,12 +118,13 @@ func New(cfg Config) (*Bot, *Dependencies, error) {
 	}
 
 	// Create feature registry
-	registry := NewRegistry(log)
+	registry := NewRegistry(log, i18nManager)
 
 	bot := &Bot{
 		session:  session,
 		registry: registry,
 		logger:   log,
+		latency:  newLatencyTracker(cfg.SlowInteraction, log),
 	}
 
 	return bot, deps, nil
@@ -115,13 +132,12 @@ func New(cfg Config) (*Bot, *Dependencies, error) {
 
 // Start starts the bot and connects to Discord.
 func (b *Bot) Start() error {
-	// Set intents
-	b.session.Identify.Intents = discordgo.IntentsGuilds |
-		discordgo.IntentsGuildMessages |
-		discordgo.IntentsMessageContent |
-		discordgo.IntentsGuildVoiceStates |
-		discordgo.IntentsGuildMessageReactions |
-		discordgo.IntentsGuildMembers
+	// Request only the intents registered features need
+	intents := b.registry.Intents()
+	b.session.Identify.Intents = intents
+	if privileged := discord.PrivilegedIntentNames(intents); len(privileged) > 0 {
+		b.logger.Info("requesting privileged intents", "intents", privileged)
+	}
 
 	// Register event handlers
 	b.session.AddHandler(b.handleInteraction)
@@ -129,10 +145,12 @@ func (b *Bot) Start() error {
 	b.session.AddHandler(b.handleMessageDelete)
 	b.session.AddHandler(b.handleReactionAdd)
 	b.session.AddHandler(b.handleVoiceStateUpdate)
+	b.session.AddHandler(b.handleMemberJoin)
+	b.session.AddHandler(b.handleMemberLeave)
 
 	// Open connection
 	if err := b.session.Open(); err != nil {
-		return fmt.Errorf("open discord connection: %w", err)
+		return fmt.Errorf("open discord connection: %w", discord.CheckIntents(err, intents))
 	}
 
 	b.logger.Info("bot connected", "user", b.session.State.User.String())
@@ -165,10 +183,18 @@ func (b *Bot) Session() *discordgo.Session {
 	return b.session
 }
 
-// handleInteraction routes interaction events to features.
+// InteractionLatency returns the histogram of interaction latencies, in
+// seconds and labelled by handler, for serving as a metric.
+func (b *Bot) InteractionLatency() *metrics.Histogram {
+	return b.latency.histogram
+}
+
+// handleInteraction routes interaction events to features, timing each.
 func (b *Bot) handleInteraction(s *discordgo.Session, i *discordgo.InteractionCreate) {
 	ctx := context.Background()
+	received := time.Now()
 	b.registry.HandleInteraction(ctx, s, i)
+	b.latency.observe(i, received, time.Now())
 }
 
 // handleMessageCreate routes message creation events to features.
@@ -199,3 +225,14 @@ func (b *Bot) handleVoiceStateUpdate(s *discordgo.Session, v *discordgo.VoiceSta
 	b.registry.HandleVoiceStateUpdate(ctx, s, v)
 }
 
+// handleMemberJoin routes member join events to features.
+func (b *Bot) handleMemberJoin(s *discordgo.Session, m *discordgo.GuildMemberAdd) {
+	ctx := context.Background()
+	b.registry.HandleMemberJoin(ctx, s, m)
+}
+
+// handleMemberLeave routes member leave events to features.
+func (b *Bot) handleMemberLeave(s *discordgo.Session, m *discordgo.GuildMemberRemove) {
+	ctx := context.Background()
+	b.registry.HandleMemberLeave(ctx, s, m)
+}
@@ -33,6 +33,22 @@ type Feature interface {
 	GetMenuButton() *MenuButton
 }
 
+// MenuButtonsFeature is an optional interface for features that put more
+// than one button in /menu. When implemented, the menu uses GetMenuButtons
+// instead of GetMenuButton.
+type MenuButtonsFeature interface {
+	Feature
+	GetMenuButtons() []*MenuButton
+}
+
+// IntentsFeature is an optional interface for features that need gateway
+// intents beyond what their event interfaces imply, e.g. member data in
+// interaction handlers.
+type IntentsFeature interface {
+	Feature
+	Intents() discordgo.Intent
+}
+
 // MessageFeature is an optional interface for features that handle messages.
 type MessageFeature interface {
 	Feature
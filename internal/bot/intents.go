@@ -0,0 +1,46 @@
+package bot
+
+import "github.com/bwmarrin/discordgo"
+
+// baseIntents are always requested: guild and channel state for the menus
+// and voice states for the onboarding channels. Neither is privileged.
+const baseIntents = discordgo.IntentsGuilds | discordgo.IntentsGuildVoiceStates
+
+// Intents returns the gateway intents the registered features need. The
+// privileged ones (members, message content) are only requested when a
+// feature handles those events, so a bot without them still starts.
+func (r *Registry) Intents() discordgo.Intent {
+	intents := baseIntents
+	for _, feature := range r.features {
+		intents |= featureIntents(feature)
+	}
+	return intents
+}
+
+// featureIntents maps the event interfaces a feature implements to the
+// intents that deliver those events.
+func featureIntents(feature Feature) discordgo.Intent {
+	var intents discordgo.Intent
+	if f, ok := feature.(IntentsFeature); ok {
+		intents |= f.Intents()
+	}
+	if _, ok := feature.(MessageFeature); ok {
+		intents |= discordgo.IntentsGuildMessages | discordgo.IntentsMessageContent
+	}
+	if _, ok := feature.(MessageEventFeature); ok {
+		intents |= discordgo.IntentsGuildMessages | discordgo.IntentsMessageContent
+	}
+	if _, ok := feature.(ReactionFeature); ok {
+		intents |= discordgo.IntentsGuildMessageReactions
+	}
+	if _, ok := feature.(VoiceFeature); ok {
+		intents |= discordgo.IntentsGuildVoiceStates
+	}
+	if _, ok := feature.(VoiceEventFeature); ok {
+		intents |= discordgo.IntentsGuildVoiceStates
+	}
+	if _, ok := feature.(MemberFeature); ok {
+		intents |= discordgo.IntentsGuildMembers
+	}
+	return intents
+}
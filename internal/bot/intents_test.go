@@ -0,0 +1,48 @@
+package bot
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+
+	"welcomebot/internal/fakes"
+)
+
+type stubFeature struct{ name string }
+
+func (f stubFeature) Name() string { return f.name }
+func (f stubFeature) HandleInteraction(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	return ErrNotHandled
+}
+func (f stubFeature) RegisterCommands() []*discordgo.ApplicationCommand { return nil }
+func (f stubFeature) GetMenuButton() *MenuButton                        { return nil }
+
+type memberStub struct{ stubFeature }
+
+func (memberStub) HandleMemberJoin(ctx context.Context, s *discordgo.Session, m *discordgo.GuildMemberAdd) error {
+	return nil
+}
+func (memberStub) HandleMemberLeave(ctx context.Context, s *discordgo.Session, m *discordgo.GuildMemberRemove) error {
+	return nil
+}
+
+func TestRegistryIntents(t *testing.T) {
+	r := NewRegistry(fakes.Logger{}, nil)
+	if err := r.Register(stubFeature{name: "menu"}); err != nil {
+		t.Fatal(err)
+	}
+	if got := r.Intents(); got != baseIntents {
+		t.Errorf("interaction-only features should need base intents, got %b", got)
+	}
+
+	if err := r.Register(memberStub{stubFeature{name: "join_dm"}}); err != nil {
+		t.Fatal(err)
+	}
+	if r.Intents()&discordgo.IntentsGuildMembers == 0 {
+		t.Error("expected a member feature to request the members intent")
+	}
+	if r.Intents()&discordgo.IntentsMessageContent != 0 {
+		t.Error("message content should not be requested without a message feature")
+	}
+}
@@ -0,0 +1,87 @@
+package bot
+
+import (
+	"strings"
+	"time"
+
+	"welcomebot/internal/core/logger"
+	"welcomebot/internal/core/metrics"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// DefaultSlowInteraction leaves a second of margin before Discord's
+// 3-second deadline for answering an interaction.
+const DefaultSlowInteraction = 2 * time.Second
+
+// latencyBuckets are the upper bounds, in seconds, of the interaction
+// latency histogram. They are dense around the 3-second deadline.
+var latencyBuckets = []float64{0.05, 0.1, 0.25, 0.5, 1, 1.5, 2, 2.5, 3, 5, 10}
+
+// latencyTracker times each interaction from when Discord created it,
+// going by its snowflake ID, to when its handler returned. Handlers answer
+// before returning, so this bounds how long the member waited for a reply.
+type latencyTracker struct {
+	slow      time.Duration
+	histogram *metrics.Histogram
+	logger    logger.Logger
+}
+
+func newLatencyTracker(slow time.Duration, log logger.Logger) *latencyTracker {
+	if slow <= 0 {
+		slow = DefaultSlowInteraction
+	}
+	return &latencyTracker{
+		slow:      slow,
+		histogram: metrics.NewHistogram("handler", latencyBuckets),
+		logger:    log,
+	}
+}
+
+// observe records an interaction received at received and handled at done.
+// Interactions slower than the threshold are logged with how much of the
+// time was spent before the bot received them.
+func (t *latencyTracker) observe(i *discordgo.InteractionCreate, received, done time.Time) {
+	created, err := discordgo.SnowflakeTimestamp(i.ID)
+	if err != nil || created.After(received) {
+		// Clock skew; time from receipt alone
+		created = received
+	}
+
+	label := interactionLabel(i)
+	latency := done.Sub(created)
+	t.histogram.Observe(label, latency.Seconds())
+
+	if latency >= t.slow {
+		t.logger.Warn("slow interaction",
+			"handler", label,
+			"latency", latency.Round(time.Millisecond),
+			"handler_time", done.Sub(received).Round(time.Millisecond),
+			"guild_id", i.GuildID,
+		)
+	}
+}
+
+// interactionLabel names the handler of an interaction for metrics and
+// logs: "/<command>" for slash commands, and the first two parts of the
+// custom ID for components and modals, e.g. "welcome:start_onboarding".
+// Later parts often hold IDs, which would make a label per member.
+func interactionLabel(i *discordgo.InteractionCreate) string {
+	var customID string
+	switch i.Type {
+	case discordgo.InteractionApplicationCommand:
+		return "/" + i.ApplicationCommandData().Name
+	case discordgo.InteractionMessageComponent:
+		customID = i.MessageComponentData().CustomID
+	case discordgo.InteractionModalSubmit:
+		customID = i.ModalSubmitData().CustomID
+	default:
+		return "other"
+	}
+
+	parts := strings.SplitN(customID, ":", 3)
+	if len(parts) > 2 {
+		parts = parts[:2]
+	}
+	return strings.Join(parts, ":")
+}
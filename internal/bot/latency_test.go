@@ -0,0 +1,83 @@
+package bot
+
+import (
+	"io"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+
+	"welcomebot/internal/core/metrics"
+	"welcomebot/internal/fakes"
+)
+
+// warnLogger keeps the messages of Warn calls.
+type warnLogger struct {
+	fakes.Logger
+	warnings []string
+}
+
+func (l *warnLogger) Warn(msg string, fields ...interface{}) {
+	l.warnings = append(l.warnings, msg)
+}
+
+// componentAt returns a button click whose ID was created at created.
+func componentAt(customID string, created time.Time) *discordgo.InteractionCreate {
+	id := uint64(created.UnixMilli()-1420070400000) << 22
+	return &discordgo.InteractionCreate{Interaction: &discordgo.Interaction{
+		ID:   strconv.FormatUint(id, 10),
+		Type: discordgo.InteractionMessageComponent,
+		Data: discordgo.MessageComponentInteractionData{CustomID: customID},
+	}}
+}
+
+func TestInteractionLabel(t *testing.T) {
+	command := &discordgo.InteractionCreate{Interaction: &discordgo.Interaction{
+		Type: discordgo.InteractionApplicationCommand,
+		Data: discordgo.ApplicationCommandInteractionData{Name: "onboarding-summary"},
+	}}
+	for i, want := range map[*discordgo.InteractionCreate]string{
+		command: "/onboarding-summary",
+		componentAt("welcome:start_onboarding:v1", time.Now()):     "welcome:start_onboarding",
+		componentAt("welcome:remap_roles:pick:role-1", time.Now()): "welcome:remap_roles",
+		componentAt("ping", time.Now()):                            "ping",
+	} {
+		if got := interactionLabel(i); got != want {
+			t.Errorf("expected %q, got %q", want, got)
+		}
+	}
+}
+
+func TestLatencyTracker(t *testing.T) {
+	log := &warnLogger{}
+	tracker := newLatencyTracker(time.Second, log)
+
+	received := time.Now()
+	tracker.observe(componentAt("welcome:start_onboarding:v1", received.Add(-200*time.Millisecond)), received, received.Add(100*time.Millisecond))
+	if len(log.warnings) != 0 {
+		t.Errorf("expected a fast interaction not to be logged, got %v", log.warnings)
+	}
+	tracker.observe(componentAt("menu:welcome:setup", received.Add(-500*time.Millisecond)), received, received.Add(2*time.Second))
+	if len(log.warnings) != 1 {
+		t.Errorf("expected the slow interaction to be logged, got %v", log.warnings)
+	}
+
+	registry := metrics.NewRegistry()
+	registry.Histogram("latency", "Latency.", tracker.histogram)
+	rec := httptest.NewRecorder()
+	registry.Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	body, _ := io.ReadAll(rec.Body)
+
+	for _, want := range []string{
+		`latency_bucket{handler="welcome:start_onboarding",le="0.5"} 1`,
+		`latency_bucket{handler="menu:welcome",le="2.5"} 0`,
+		`latency_bucket{handler="menu:welcome",le="3"} 1`,
+	} {
+		if !strings.Contains(string(body), want) {
+			t.Errorf("expected %q in\n%s", want, body)
+		}
+	}
+}
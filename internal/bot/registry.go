@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 
+	"welcomebot/internal/core/i18n"
 	"welcomebot/internal/core/logger"
 
 	"github.com/bwmarrin/discordgo"
@@ -14,14 +15,16 @@ import (
 type Registry struct {
 	features    map[string]Feature
 	logger      logger.Logger
+	i18n        i18n.I18n // Translates validation errors; nil replies in English
 	eventRouter *EventRouter
 }
 
 // NewRegistry creates a new feature registry.
-func NewRegistry(log logger.Logger) *Registry {
+func NewRegistry(log logger.Logger, translator i18n.I18n) *Registry {
 	return &Registry{
 		features:    make(map[string]Feature),
 		logger:      log,
+		i18n:        translator,
 		eventRouter: NewEventRouter(log),
 	}
 }
@@ -61,16 +64,33 @@ func (r *Registry) HandleInteraction(ctx context.Context, s *discordgo.Session,
 
 	// Try each feature until one handles it
 	for name, feature := range r.features {
-		if err := feature.HandleInteraction(ctx, s, i); err == nil {
-			return // Feature handled it successfully
-		} else if !errors.Is(err, ErrNotHandled) {
+		err := feature.HandleInteraction(ctx, s, i)
+		if errors.Is(err, ErrNotHandled) {
+			continue
+		}
+
+		var verr *ValidationError
+		switch {
+		case err == nil:
+			// Feature handled it successfully
+		case errors.As(err, &verr):
+			// Tell the user what to fix rather than failing silently
+			r.logger.Debug("interaction rejected",
+				"feature", name,
+				"command", commandName,
+				"reason", verr.Reason,
+			)
+			if err := s.InteractionRespond(i.Interaction, r.validationResponse(ctx, i.GuildID, verr)); err != nil {
+				r.logger.Warn("failed to send validation error", "error", err)
+			}
+		default:
 			r.logger.Error("feature error handling interaction",
 				"feature", name,
 				"command", commandName,
 				"error", err,
 			)
-			return
 		}
+		return
 	}
 
 	r.logger.Debug("no feature handled interaction", "command", commandName)
@@ -137,6 +157,38 @@ func (r *Registry) HandleVoiceStateUpdate(ctx context.Context, s *discordgo.Sess
 	}
 }
 
+// HandleMemberJoin routes member join events to features.
+func (r *Registry) HandleMemberJoin(ctx context.Context, s *discordgo.Session, m *discordgo.GuildMemberAdd) {
+	for name, feature := range r.features {
+		if memberFeature, ok := feature.(MemberFeature); ok {
+			if err := memberFeature.HandleMemberJoin(ctx, s, m); err != nil {
+				if !errors.Is(err, ErrNotHandled) {
+					r.logger.Error("feature error handling member join",
+						"feature", name,
+						"error", err,
+					)
+				}
+			}
+		}
+	}
+}
+
+// HandleMemberLeave routes member leave events to features.
+func (r *Registry) HandleMemberLeave(ctx context.Context, s *discordgo.Session, m *discordgo.GuildMemberRemove) {
+	for name, feature := range r.features {
+		if memberFeature, ok := feature.(MemberFeature); ok {
+			if err := memberFeature.HandleMemberLeave(ctx, s, m); err != nil {
+				if !errors.Is(err, ErrNotHandled) {
+					r.logger.Error("feature error handling member leave",
+						"feature", name,
+						"error", err,
+					)
+				}
+			}
+		}
+	}
+}
+
 // RegisterSlashCommands registers all feature commands with Discord.
 func (r *Registry) RegisterSlashCommands(s *discordgo.Session) error {
 	var commands []*discordgo.ApplicationCommand
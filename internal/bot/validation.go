@@ -0,0 +1,69 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+
+	"welcomebot/internal/shared"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// ValidationError is a problem with what the user entered or picked, which
+// they can fix themselves. When a feature returns one from
+// HandleInteraction, the registry replies with its message instead of
+// only logging it.
+type ValidationError struct {
+	Reason string            // Logged, e.g. "no role selected"
+	Key    string            // Translation key of the message shown to the user
+	Args   map[string]string // Values for the message's placeholders
+}
+
+func (e *ValidationError) Error() string {
+	return e.Reason
+}
+
+// Validation errors shared by the configuration screens.
+var (
+	ErrNoRoleSelected     = &ValidationError{Reason: "no role selected", Key: "validation.no_role_selected"}
+	ErrNoChannelSelected  = &ValidationError{Reason: "no channel selected", Key: "validation.no_channel_selected"}
+	ErrNoCategorySelected = &ValidationError{Reason: "no category selected", Key: "validation.no_category_selected"}
+
+	// ErrWizardExpired means a wizard panel was used after its state
+	// expired or the wizard finished.
+	ErrWizardExpired = &ValidationError{Reason: "wizard state expired", Key: "validation.wizard_expired"}
+	// ErrWizardOutdated means a panel for one step was used while the
+	// wizard is on another, e.g. one left over from a restarted wizard.
+	ErrWizardOutdated = &ValidationError{Reason: "wizard is on another step", Key: "validation.wizard_outdated"}
+)
+
+// RoleAboveBotError reports a role the bot can't assign because it is at
+// or above the bot's highest role.
+func RoleAboveBotError(roleID string) *ValidationError {
+	return &ValidationError{
+		Reason: fmt.Sprintf("role %s is above the bot's highest role", roleID),
+		Key:    "validation.role_above_bot",
+		Args:   map[string]string{"role": fmt.Sprintf("<@&%s>", roleID)},
+	}
+}
+
+// validationResponse builds the ephemeral reply for a validation error.
+func (r *Registry) validationResponse(ctx context.Context, guildID string, verr *ValidationError) *discordgo.InteractionResponse {
+	title, message := "Error", verr.Reason
+	if r.i18n != nil {
+		title = r.i18n.T(ctx, guildID, "common.error")
+		message = r.i18n.TWithArgs(ctx, guildID, verr.Key, verr.Args)
+	}
+
+	return &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Embeds: []*discordgo.MessageEmbed{{
+				Title:       title,
+				Description: message,
+				Color:       int(shared.ColorError),
+			}},
+			Flags: discordgo.MessageFlagsEphemeral,
+		},
+	}
+}
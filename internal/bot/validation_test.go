@@ -0,0 +1,77 @@
+package bot
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+
+	"welcomebot/internal/fakes"
+)
+
+type failingFeature struct {
+	stubFeature
+	err error
+}
+
+func (f failingFeature) HandleInteraction(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	return f.err
+}
+
+func componentInteraction(customID string) *discordgo.InteractionCreate {
+	return &discordgo.InteractionCreate{Interaction: &discordgo.Interaction{
+		ID: "interaction-1", Token: "token", GuildID: "guild-1",
+		Type: discordgo.InteractionMessageComponent,
+		Data: discordgo.MessageComponentInteractionData{CustomID: customID},
+	}}
+}
+
+func TestValidationErrorReply(t *testing.T) {
+	dc := fakes.NewDiscord()
+	r := NewRegistry(fakes.Logger{}, fakes.I18n{})
+	err := fmt.Errorf("step 3: %w", ErrNoRoleSelected)
+	if err := r.Register(failingFeature{stubFeature{name: "wizard"}, err}); err != nil {
+		t.Fatal(err)
+	}
+
+	r.HandleInteraction(context.Background(), dc.Session(), componentInteraction("wizard:role"))
+
+	requests := dc.Requests()
+	if len(requests) != 1 || !strings.HasSuffix(requests[0].Path, "/callback") {
+		t.Fatalf("expected one interaction response, got %+v", requests)
+	}
+	var callback discordgo.InteractionResponse
+	if err := json.Unmarshal(requests[0].Body, &callback); err != nil {
+		t.Fatal(err)
+	}
+	if callback.Data.Flags != discordgo.MessageFlagsEphemeral {
+		t.Errorf("expected an ephemeral reply, got flags %d", callback.Data.Flags)
+	}
+	if got := callback.Data.Embeds[0].Description; got != ErrNoRoleSelected.Key {
+		t.Errorf("expected the translated message, got %q", got)
+	}
+}
+
+func TestOtherErrorsAreOnlyLogged(t *testing.T) {
+	dc := fakes.NewDiscord()
+	r := NewRegistry(fakes.Logger{}, fakes.I18n{})
+	if err := r.Register(failingFeature{stubFeature{name: "wizard"}, fmt.Errorf("save config: boom")}); err != nil {
+		t.Fatal(err)
+	}
+
+	r.HandleInteraction(context.Background(), dc.Session(), componentInteraction("wizard:role"))
+
+	if requests := dc.Requests(); len(requests) != 0 {
+		t.Errorf("expected no reply, got %+v", requests)
+	}
+}
+
+func TestRoleAboveBotError(t *testing.T) {
+	verr := RoleAboveBotError("role-1")
+	if verr.Args["role"] != "<@&role-1>" || !strings.Contains(verr.Error(), "role-1") {
+		t.Errorf("unexpected error %+v", verr)
+	}
+}
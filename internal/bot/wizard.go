@@ -0,0 +1,31 @@
+package bot
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// SeedWizardState copies an existing configuration into a wizard state, so
+// a wizard re-run to edit it opens each step with the current value
+// selected. Fields are matched by JSON name; settings the state has no
+// field for are left out.
+func SeedWizardState(config, state any) error {
+	data, err := json.Marshal(config)
+	if err != nil {
+		return fmt.Errorf("marshal config: %w", err)
+	}
+	if err := json.Unmarshal(data, state); err != nil {
+		return fmt.Errorf("unmarshal wizard state: %w", err)
+	}
+	return nil
+}
+
+// WizardDefaults returns the wizard state a step pre-selects its menus
+// from. Pass it the result of loading the state; when that failed, an
+// empty state is returned and the menus open with nothing selected.
+func WizardDefaults[S any](state *S, err error) *S {
+	if err != nil || state == nil {
+		return new(S)
+	}
+	return state
+}
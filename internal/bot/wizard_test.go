@@ -0,0 +1,38 @@
+package bot
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestSeedWizardState(t *testing.T) {
+	config := struct {
+		GuildID string `json:"guild_id"`
+		RoleID  string `json:"role_id,omitempty"`
+		Extra   string `json:"extra"`
+	}{GuildID: "guild-1", RoleID: "role-1", Extra: "dropped"}
+	state := struct {
+		GuildID     string `json:"guild_id"`
+		RoleID      string `json:"role_id"`
+		CurrentStep int    `json:"current_step"`
+	}{CurrentStep: 1}
+
+	if err := SeedWizardState(config, &state); err != nil {
+		t.Fatalf("seed: %v", err)
+	}
+	if state.GuildID != "guild-1" || state.RoleID != "role-1" || state.CurrentStep != 1 {
+		t.Errorf("unexpected state: %+v", state)
+	}
+}
+
+func TestWizardDefaults(t *testing.T) {
+	type state struct{ RoleID string }
+
+	saved := &state{RoleID: "role-1"}
+	if got := WizardDefaults(saved, nil); got != saved {
+		t.Errorf("expected the saved state, got %+v", got)
+	}
+	if got := WizardDefaults[state](nil, errors.New("key not found")); got == nil || got.RoleID != "" {
+		t.Errorf("expected an empty state, got %+v", got)
+	}
+}
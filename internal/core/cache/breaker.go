@@ -0,0 +1,102 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrUnavailable is returned while the circuit breaker is open and Redis
+// calls are being short-circuited.
+var ErrUnavailable = errors.New("cache unavailable")
+
+const (
+	defaultFailureThreshold = 5
+	defaultOpenTimeout      = 30 * time.Second
+)
+
+// breaker is a consecutive-failure circuit breaker guarding Redis calls.
+// After threshold failures in a row it opens and rejects calls until
+// openTimeout has passed, then lets a single probe through (half-open).
+type breaker struct {
+	mu          sync.Mutex
+	threshold   int
+	openTimeout time.Duration
+	failures    int
+	open        bool
+	openedAt    time.Time
+	probing     bool
+	onChange    func(degraded bool)
+	now         func() time.Time
+}
+
+func newBreaker(threshold int, openTimeout time.Duration, onChange func(degraded bool)) *breaker {
+	if threshold <= 0 {
+		threshold = defaultFailureThreshold
+	}
+	if openTimeout <= 0 {
+		openTimeout = defaultOpenTimeout
+	}
+	return &breaker{
+		threshold:   threshold,
+		openTimeout: openTimeout,
+		onChange:    onChange,
+		now:         time.Now,
+	}
+}
+
+// allow reports whether a call may proceed.
+func (b *breaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !b.open {
+		return true
+	}
+	if b.probing || b.now().Sub(b.openedAt) < b.openTimeout {
+		return false
+	}
+	b.probing = true
+	return true
+}
+
+// record updates breaker state with the outcome of a call. A call whose
+// context was cancelled, e.g. at shutdown, says nothing about Redis and
+// only frees the probe slot.
+func (b *breaker) record(err error) {
+	b.mu.Lock()
+	var changed, degraded bool
+
+	if errors.Is(err, context.Canceled) {
+		b.probing = false
+	} else if err == nil {
+		changed = b.open
+		b.failures = 0
+		b.open = false
+		b.probing = false
+	} else {
+		b.failures++
+		if b.probing || (!b.open && b.failures >= b.threshold) {
+			changed = !b.open
+			b.open = true
+			b.openedAt = b.now()
+			degraded = true
+		}
+		b.probing = false
+	}
+
+	onChange := b.onChange
+	b.mu.Unlock()
+
+	if changed && onChange != nil {
+		onChange(degraded)
+	}
+}
+
+// isOpen reports whether the breaker is currently rejecting calls.
+func (b *breaker) isOpen() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.open
+}
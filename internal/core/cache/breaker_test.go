@@ -0,0 +1,103 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestBreaker_TripsAfterThreshold(t *testing.T) {
+	var changes []bool
+	b := newBreaker(3, time.Minute, func(degraded bool) {
+		changes = append(changes, degraded)
+	})
+
+	errBoom := errors.New("boom")
+	for n := 0; n < 2; n++ {
+		if !b.allow() {
+			t.Fatalf("call %d rejected before threshold", n)
+		}
+		b.record(errBoom)
+	}
+	if b.isOpen() {
+		t.Fatal("breaker opened before threshold")
+	}
+
+	b.allow()
+	b.record(errBoom)
+	if !b.isOpen() {
+		t.Fatal("expected breaker to be open after threshold")
+	}
+	if b.allow() {
+		t.Error("expected calls to be rejected while open")
+	}
+	if len(changes) != 1 || !changes[0] {
+		t.Errorf("expected one degraded notification, got %v", changes)
+	}
+}
+
+func TestBreaker_SuccessResetsFailures(t *testing.T) {
+	b := newBreaker(2, time.Minute, nil)
+
+	b.record(errors.New("boom"))
+	b.record(nil)
+	b.record(errors.New("boom"))
+
+	if b.isOpen() {
+		t.Error("expected non-consecutive failures not to trip the breaker")
+	}
+}
+
+func TestBreaker_HalfOpenProbe(t *testing.T) {
+	now := time.Now()
+	var changes []bool
+	b := newBreaker(1, 10*time.Second, func(degraded bool) {
+		changes = append(changes, degraded)
+	})
+	b.now = func() time.Time { return now }
+
+	b.record(errors.New("boom"))
+	if b.allow() {
+		t.Fatal("expected rejection while open")
+	}
+
+	now = now.Add(11 * time.Second)
+	if !b.allow() {
+		t.Fatal("expected a probe after the open timeout")
+	}
+	if b.allow() {
+		t.Error("expected only one concurrent probe")
+	}
+
+	// Failed probe re-opens without a duplicate notification.
+	b.record(errors.New("still down"))
+	if !b.isOpen() {
+		t.Fatal("expected breaker to re-open after failed probe")
+	}
+
+	now = now.Add(11 * time.Second)
+	b.allow()
+	b.record(nil)
+	if b.isOpen() {
+		t.Error("expected breaker to close after successful probe")
+	}
+
+	want := []bool{true, false}
+	if len(changes) != len(want) || changes[0] != want[0] || changes[1] != want[1] {
+		t.Errorf("expected notifications %v, got %v", want, changes)
+	}
+}
+
+func TestBreaker_IgnoresCancelledCalls(t *testing.T) {
+	b := newBreaker(2, time.Minute, nil)
+
+	for n := 0; n < 3; n++ {
+		b.allow()
+		b.record(fmt.Errorf("get: %w", context.Canceled))
+	}
+	if b.isOpen() {
+		t.Error("expected cancelled calls not to open the breaker")
+	}
+}
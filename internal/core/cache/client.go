@@ -3,26 +3,71 @@ package cache
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/go-redis/redis/v8"
 )
 
+// ErrNotFound is returned by Get and GetJSON for a key that isn't
+// cached, as opposed to a cache that couldn't be read.
+var ErrNotFound = errors.New("key not found")
+
 // Client provides caching operations.
 type Client interface {
 	Get(ctx context.Context, key string) (string, error)
 	Set(ctx context.Context, key string, value string, ttl time.Duration) error
+	// SetNX stores a value only if the key does not exist yet and reports
+	// whether it did.
+	SetNX(ctx context.Context, key string, value string, ttl time.Duration) (bool, error)
 	Delete(ctx context.Context, key string) error
 	Exists(ctx context.Context, key string) (bool, error)
+	// Keys returns the keys matching a glob pattern. It walks the keyspace
+	// with SCAN (on every master in cluster mode), so keep patterns narrow.
+	Keys(ctx context.Context, pattern string) ([]string, error)
 	GetJSON(ctx context.Context, key string, dest interface{}) error
+	// GetDelJSON is GetJSON that also deletes the key, in one GETDEL, so
+	// only one caller can take a value.
+	GetDelJSON(ctx context.Context, key string, dest interface{}) error
+	// GetJSONMulti fetches keys in one round trip, pipelining the reads,
+	// and unmarshals each value into the destination at the same index.
+	// found reports which keys were read; misses, and values that fail to
+	// decode, leave their destination untouched. An error means nothing
+	// could be read.
+	GetJSONMulti(ctx context.Context, keys []string, dests ...interface{}) (found []bool, err error)
 	SetJSON(ctx context.Context, key string, value interface{}, ttl time.Duration) error
+	// SetNXJSON is SetNX for a JSON value, encrypted like SetJSON.
+	SetNXJSON(ctx context.Context, key string, value interface{}, ttl time.Duration) (bool, error)
+	// Incr atomically increments an integer key and returns the new value.
+	// Missing keys start at 0.
+	Incr(ctx context.Context, key string) (int64, error)
+	// Decr atomically decrements an integer key and returns the new value.
+	Decr(ctx context.Context, key string) (int64, error)
+	// Expire sets a key's time to live.
+	Expire(ctx context.Context, key string, ttl time.Duration) error
+	// TTL returns a key's remaining time to live, or a negative duration if
+	// the key is missing or never expires.
+	TTL(ctx context.Context, key string) (time.Duration, error)
+	// Degraded reports whether the circuit breaker is open and calls are
+	// failing fast with ErrUnavailable.
+	Degraded() bool
+	// Stats returns the hit and miss counts of Get, GetJSON, GetDelJSON
+	// and GetJSONMulti.
+	Stats() *Stats
 	Close() error
 }
 
 // Config contains Redis configuration.
+//
+// The connection mode is chosen by precedence: ClusterAddrs, then
+// SentinelAddrs with MasterName, then Addr.
 type Config struct {
-	// Sentinel Configuration (preferred)
+	// Cluster Configuration (takes precedence over Sentinel)
+	ClusterAddrs []string // Cluster seed nodes (e.g., ["redis-0:6379", "redis-1:6379"]); DB must be 0
+
+	// Sentinel Configuration (preferred over a single Redis)
 	SentinelAddrs  []string // Sentinel addresses (e.g., ["sentinel1:26379", "sentinel2:26379"])
 	MasterName     string   // Sentinel master name
 	
@@ -31,42 +76,48 @@ type Config struct {
 	
 	Password string
 	DB       int
+
+	// Circuit breaker (zero values use defaults)
+	FailureThreshold int                 // Consecutive failures before tripping
+	OpenTimeout      time.Duration       // How long to short-circuit before probing again
+	OnStateChange    func(degraded bool) // Called when the breaker trips or recovers
+
+	// EncryptionKey encrypts SetJSON values with AES-256-GCM; nil stores
+	// them as plaintext. GetJSON reads both, so it can be turned on while
+	// plaintext values are still cached.
+	EncryptionKey []byte
 }
 
 // DefaultConfig returns default cache configuration.
 func DefaultConfig() Config {
 	return Config{
-		Addr:     "localhost:6379",
-		Password: "",
-		DB:       0,
+		Addr:             "localhost:6379",
+		Password:         "",
+		DB:               0,
+		FailureThreshold: defaultFailureThreshold,
+		OpenTimeout:      defaultOpenTimeout,
 	}
 }
 
 // redisClient implements Client using Redis.
 type redisClient struct {
-	client *redis.Client
+	client  redis.UniversalClient
+	breaker *breaker
+	stats   Stats
+	sealer  *sealer // nil leaves JSON values unencrypted
 }
 
 // New creates a new cache client with the given configuration.
-// Supports both Redis Sentinel (HA) and single Redis instance.
+// Supports Redis Cluster, Redis Sentinel (HA) and a single Redis instance.
 func New(cfg Config) (Client, error) {
-	var rdb *redis.Client
-	
-	// Use Sentinel if configured
-	if len(cfg.SentinelAddrs) > 0 && cfg.MasterName != "" {
-		rdb = redis.NewFailoverClient(&redis.FailoverOptions{
-			MasterName:    cfg.MasterName,
-			SentinelAddrs: cfg.SentinelAddrs,
-			Password:      cfg.Password,
-			DB:            cfg.DB,
-		})
-	} else {
-		// Fallback to single Redis
-		rdb = redis.NewClient(&redis.Options{
-			Addr:     cfg.Addr,
-			Password: cfg.Password,
-			DB:       cfg.DB,
-		})
+	sealer, err := newSealer(cfg.EncryptionKey)
+	if err != nil {
+		return nil, err
+	}
+
+	rdb, err := newRedisClient(cfg)
+	if err != nil {
+		return nil, err
 	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
@@ -76,32 +127,93 @@ func New(cfg Config) (Client, error) {
 		return nil, fmt.Errorf("ping redis: %w", err)
 	}
 
-	return &redisClient{client: rdb}, nil
+	return &redisClient{
+		client:  rdb,
+		breaker: newBreaker(cfg.FailureThreshold, cfg.OpenTimeout, cfg.OnStateChange),
+		sealer:  sealer,
+	}, nil
+}
+
+// newRedisClient builds the client for the configured connection mode.
+// Every cache operation but Keys touches a single key, so all of them are
+// safe to run against a cluster.
+func newRedisClient(cfg Config) (redis.UniversalClient, error) {
+	switch {
+	case len(cfg.ClusterAddrs) > 0:
+		// Cluster nodes only have database 0
+		if cfg.DB != 0 {
+			return nil, fmt.Errorf("redis cluster does not support db %d", cfg.DB)
+		}
+		return redis.NewClusterClient(&redis.ClusterOptions{
+			Addrs:    cfg.ClusterAddrs,
+			Password: cfg.Password,
+		}), nil
+	case len(cfg.SentinelAddrs) > 0 && cfg.MasterName != "":
+		return redis.NewFailoverClient(&redis.FailoverOptions{
+			MasterName:    cfg.MasterName,
+			SentinelAddrs: cfg.SentinelAddrs,
+			Password:      cfg.Password,
+			DB:            cfg.DB,
+		}), nil
+	default:
+		return redis.NewClient(&redis.Options{
+			Addr:     cfg.Addr,
+			Password: cfg.Password,
+			DB:       cfg.DB,
+		}), nil
+	}
 }
 
 // Get retrieves a value from the cache.
 func (c *redisClient) Get(ctx context.Context, key string) (string, error) {
-	val, err := c.client.Get(ctx, key).Result()
+	var val string
+	err := c.do(func() error {
+		var err error
+		val, err = c.client.Get(ctx, key).Result()
+		return err
+	})
 	if err == redis.Nil {
-		return "", fmt.Errorf("key not found: %s", key)
+		c.stats.Record(key, false)
+		return "", fmt.Errorf("%w: %s", ErrNotFound, key)
 	}
 	if err != nil {
 		return "", fmt.Errorf("get key %s: %w", key, err)
 	}
+	c.stats.Record(key, true)
 	return val, nil
 }
 
 // Set stores a value in the cache with the given TTL.
 func (c *redisClient) Set(ctx context.Context, key string, value string, ttl time.Duration) error {
-	if err := c.client.Set(ctx, key, value, ttl).Err(); err != nil {
+	err := c.do(func() error {
+		return c.client.Set(ctx, key, value, ttl).Err()
+	})
+	if err != nil {
 		return fmt.Errorf("set key %s: %w", key, err)
 	}
 	return nil
 }
 
+// SetNX stores a value if the key is not already set.
+func (c *redisClient) SetNX(ctx context.Context, key string, value string, ttl time.Duration) (bool, error) {
+	var ok bool
+	err := c.do(func() error {
+		var err error
+		ok, err = c.client.SetNX(ctx, key, value, ttl).Result()
+		return err
+	})
+	if err != nil {
+		return false, fmt.Errorf("setnx key %s: %w", key, err)
+	}
+	return ok, nil
+}
+
 // Delete removes a key from the cache.
 func (c *redisClient) Delete(ctx context.Context, key string) error {
-	if err := c.client.Del(ctx, key).Err(); err != nil {
+	err := c.do(func() error {
+		return c.client.Del(ctx, key).Err()
+	})
+	if err != nil {
 		return fmt.Errorf("delete key %s: %w", key, err)
 	}
 	return nil
@@ -109,37 +221,252 @@ func (c *redisClient) Delete(ctx context.Context, key string) error {
 
 // Exists checks if a key exists in the cache.
 func (c *redisClient) Exists(ctx context.Context, key string) (bool, error) {
-	count, err := c.client.Exists(ctx, key).Result()
+	var count int64
+	err := c.do(func() error {
+		var err error
+		count, err = c.client.Exists(ctx, key).Result()
+		return err
+	})
 	if err != nil {
 		return false, fmt.Errorf("check exists %s: %w", key, err)
 	}
 	return count > 0, nil
 }
 
-// GetJSON retrieves and unmarshals JSON from the cache.
+// TTL returns a key's remaining time to live.
+func (c *redisClient) TTL(ctx context.Context, key string) (time.Duration, error) {
+	var ttl time.Duration
+	err := c.do(func() error {
+		var err error
+		ttl, err = c.client.TTL(ctx, key).Result()
+		return err
+	})
+	if err != nil {
+		return 0, fmt.Errorf("ttl key %s: %w", key, err)
+	}
+	return ttl, nil
+}
+
+// Keys returns every key matching pattern.
+func (c *redisClient) Keys(ctx context.Context, pattern string) ([]string, error) {
+	var keys []string
+	err := c.do(func() error {
+		if cluster, ok := c.client.(*redis.ClusterClient); ok {
+			var mu sync.Mutex
+			return cluster.ForEachMaster(ctx, func(ctx context.Context, node *redis.Client) error {
+				found, err := scanKeys(ctx, node, pattern)
+				mu.Lock()
+				keys = append(keys, found...)
+				mu.Unlock()
+				return err
+			})
+		}
+		var err error
+		keys, err = scanKeys(ctx, c.client, pattern)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("scan keys %s: %w", pattern, err)
+	}
+	return keys, nil
+}
+
+// scanKeys collects the keys matching pattern on one node.
+func scanKeys(ctx context.Context, client redis.Cmdable, pattern string) ([]string, error) {
+	var keys []string
+	iter := client.Scan(ctx, 0, pattern, 100).Iterator()
+	for iter.Next(ctx) {
+		keys = append(keys, iter.Val())
+	}
+	return keys, iter.Err()
+}
+
+// GetJSON retrieves and unmarshals JSON from the cache, decrypting it
+// first if it was stored encrypted.
 func (c *redisClient) GetJSON(ctx context.Context, key string, dest interface{}) error {
 	val, err := c.Get(ctx, key)
 	if err != nil {
 		return err
 	}
 
-	if err := json.Unmarshal([]byte(val), dest); err != nil {
+	data, err := c.sealer.open(key, []byte(val))
+	if err != nil {
+		return err
+	}
+
+	if err := json.Unmarshal(data, dest); err != nil {
 		return fmt.Errorf("unmarshal json for key %s: %w", key, err)
 	}
 
 	return nil
 }
 
-// SetJSON marshals and stores JSON in the cache.
+// GetDelJSON retrieves, deletes and unmarshals a JSON value.
+func (c *redisClient) GetDelJSON(ctx context.Context, key string, dest interface{}) error {
+	var val string
+	err := c.do(func() error {
+		var err error
+		val, err = c.client.GetDel(ctx, key).Result()
+		return err
+	})
+	if err == redis.Nil {
+		c.stats.Record(key, false)
+		return fmt.Errorf("%w: %s", ErrNotFound, key)
+	}
+	if err != nil {
+		return fmt.Errorf("getdel key %s: %w", key, err)
+	}
+	c.stats.Record(key, true)
+
+	data, err := c.sealer.open(key, []byte(val))
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, dest); err != nil {
+		return fmt.Errorf("unmarshal json for key %s: %w", key, err)
+	}
+	return nil
+}
+
+// GetJSONMulti retrieves several JSON values in one pipelined round trip.
+// Each key is its own GET, so keys may live on different cluster nodes.
+func (c *redisClient) GetJSONMulti(ctx context.Context, keys []string, dests ...interface{}) ([]bool, error) {
+	if len(keys) != len(dests) {
+		return nil, fmt.Errorf("get json multi: %d keys for %d destinations", len(keys), len(dests))
+	}
+
+	cmds := make([]*redis.StringCmd, len(keys))
+	err := c.do(func() error {
+		pipe := c.client.Pipeline()
+		for i, key := range keys {
+			cmds[i] = pipe.Get(ctx, key)
+		}
+		_, err := pipe.Exec(ctx)
+		return err
+	})
+	// Exec reports the first failed command, which is redis.Nil for a miss
+	if err != nil && err != redis.Nil {
+		return nil, fmt.Errorf("get keys %v: %w", keys, err)
+	}
+
+	found := make([]bool, len(keys))
+	for i, cmd := range cmds {
+		val, err := cmd.Result()
+		if err != nil {
+			c.stats.Record(keys[i], false)
+			continue
+		}
+		c.stats.Record(keys[i], true)
+
+		data, err := c.sealer.open(keys[i], []byte(val))
+		if err != nil {
+			continue
+		}
+		if err := json.Unmarshal(data, dests[i]); err != nil {
+			continue
+		}
+		found[i] = true
+	}
+	return found, nil
+}
+
+// SetJSON marshals and stores JSON in the cache, encrypted if the client
+// has a key.
 func (c *redisClient) SetJSON(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
 	data, err := json.Marshal(value)
 	if err != nil {
 		return fmt.Errorf("marshal json for key %s: %w", key, err)
 	}
 
+	data, err = c.sealer.seal(key, data)
+	if err != nil {
+		return err
+	}
+
 	return c.Set(ctx, key, string(data), ttl)
 }
 
+// SetNXJSON marshals and stores JSON if the key is not already set,
+// encrypted if the client has a key.
+func (c *redisClient) SetNXJSON(ctx context.Context, key string, value interface{}, ttl time.Duration) (bool, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return false, fmt.Errorf("marshal json for key %s: %w", key, err)
+	}
+
+	data, err = c.sealer.seal(key, data)
+	if err != nil {
+		return false, err
+	}
+
+	return c.SetNX(ctx, key, string(data), ttl)
+}
+
+// Incr atomically increments an integer key.
+func (c *redisClient) Incr(ctx context.Context, key string) (int64, error) {
+	var val int64
+	err := c.do(func() error {
+		var err error
+		val, err = c.client.Incr(ctx, key).Result()
+		return err
+	})
+	if err != nil {
+		return 0, fmt.Errorf("incr key %s: %w", key, err)
+	}
+	return val, nil
+}
+
+// Decr atomically decrements an integer key.
+func (c *redisClient) Decr(ctx context.Context, key string) (int64, error) {
+	var val int64
+	err := c.do(func() error {
+		var err error
+		val, err = c.client.Decr(ctx, key).Result()
+		return err
+	})
+	if err != nil {
+		return 0, fmt.Errorf("decr key %s: %w", key, err)
+	}
+	return val, nil
+}
+
+// Expire sets a key's time to live.
+func (c *redisClient) Expire(ctx context.Context, key string, ttl time.Duration) error {
+	err := c.do(func() error {
+		return c.client.Expire(ctx, key, ttl).Err()
+	})
+	if err != nil {
+		return fmt.Errorf("expire key %s: %w", key, err)
+	}
+	return nil
+}
+
+// Degraded reports whether the circuit breaker is open.
+func (c *redisClient) Degraded() bool {
+	return c.breaker.isOpen()
+}
+
+// Stats returns the client's hit and miss counts.
+func (c *redisClient) Stats() *Stats {
+	return &c.stats
+}
+
+// do runs fn through the circuit breaker. A cache miss (redis.Nil) counts
+// as a healthy round trip; a cancelled call counts as neither.
+func (c *redisClient) do(fn func() error) error {
+	if !c.breaker.allow() {
+		return ErrUnavailable
+	}
+
+	err := fn()
+	if err == redis.Nil {
+		c.breaker.record(nil)
+	} else {
+		c.breaker.record(err)
+	}
+	return err
+}
+
 // Close closes the cache client connection.
 func (c *redisClient) Close() error {
 	if err := c.client.Close(); err != nil {
@@ -29,3 +29,10 @@ func TestNew_InvalidConfig(t *testing.T) {
 		t.Error("expected error for invalid config, got nil")
 	}
 }
+
+func TestNew_ClusterRejectsDB(t *testing.T) {
+	_, err := cache.New(cache.Config{ClusterAddrs: []string{"localhost:7000"}, DB: 1})
+	if err == nil {
+		t.Error("expected error for non-zero db in cluster mode")
+	}
+}
@@ -2,4 +2,11 @@
 //
 // It offers a clean interface for caching operations with
 // support for TTLs and atomic operations.
+//
+// The client connects to Redis Cluster when ClusterAddrs is set, otherwise
+// to Sentinel when SentinelAddrs and MasterName are set, otherwise to the
+// single instance at Addr. Every operation except Keys and GetJSONMulti
+// touches a single key, so none of them need hash tags in cluster mode;
+// Keys scans each master in turn, and GetJSONMulti pipelines one GET per
+// key, which the cluster client sends to each key's node.
 package cache
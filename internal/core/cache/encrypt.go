@@ -0,0 +1,100 @@
+package cache
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+)
+
+// encryptedV1 starts values sealed with AES-256-GCM: the version byte, a
+// 12-byte nonce, then the ciphertext and tag. JSON never starts with this
+// byte, so plaintext values written before encryption was turned on are
+// still read as they are.
+const encryptedV1 byte = 0x01
+
+// EncryptionKeySize is the length of Config.EncryptionKey.
+const EncryptionKeySize = 32
+
+// ErrEncrypted is returned by GetJSON for an encrypted value when the
+// client has no key, as after turning encryption off. Callers treat it as
+// a miss like any other read error.
+var ErrEncrypted = errors.New("cache: value is encrypted and no key is configured")
+
+// ParseEncryptionKey decodes a base64 key of EncryptionKeySize bytes. An
+// empty string returns a nil key, which leaves encryption off.
+func ParseEncryptionKey(s string) ([]byte, error) {
+	if s == "" {
+		return nil, nil
+	}
+	key, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("decode encryption key: %w", err)
+	}
+	if len(key) != EncryptionKeySize {
+		return nil, fmt.Errorf("encryption key is %d bytes, want %d", len(key), EncryptionKeySize)
+	}
+	return key, nil
+}
+
+// sealer encrypts JSON values. A nil sealer stores them as plaintext.
+type sealer struct {
+	aead cipher.AEAD
+}
+
+func newSealer(key []byte) (*sealer, error) {
+	if key == nil {
+		return nil, nil
+	}
+	if len(key) != EncryptionKeySize {
+		return nil, fmt.Errorf("encryption key is %d bytes, want %d", len(key), EncryptionKeySize)
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("create cipher: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("create gcm: %w", err)
+	}
+	return &sealer{aead: aead}, nil
+}
+
+// seal encrypts a value stored under key. The key is authenticated with
+// it, so a value copied to another key fails to open.
+func (s *sealer) seal(key string, plaintext []byte) ([]byte, error) {
+	if s == nil {
+		return plaintext, nil
+	}
+
+	out := make([]byte, 1+s.aead.NonceSize(), 1+s.aead.NonceSize()+len(plaintext)+s.aead.Overhead())
+	out[0] = encryptedV1
+	if _, err := rand.Read(out[1:]); err != nil {
+		return nil, fmt.Errorf("generate nonce: %w", err)
+	}
+	return s.aead.Seal(out, out[1:], plaintext, []byte(key)), nil
+}
+
+// open returns the plaintext of a value read from key. Values without the
+// version byte are returned unchanged.
+func (s *sealer) open(key string, value []byte) ([]byte, error) {
+	if len(value) == 0 || value[0] != encryptedV1 {
+		return value, nil
+	}
+	if s == nil {
+		return nil, ErrEncrypted
+	}
+
+	nonceSize := s.aead.NonceSize()
+	if len(value) < 1+nonceSize {
+		return nil, fmt.Errorf("encrypted value for key %s is truncated", key)
+	}
+	plaintext, err := s.aead.Open(nil, value[1:1+nonceSize], value[1+nonceSize:], []byte(key))
+	if err != nil {
+		return nil, fmt.Errorf("decrypt value for key %s: %w", key, err)
+	}
+	return plaintext, nil
+}
@@ -0,0 +1,109 @@
+package cache
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"testing"
+)
+
+var testKey = bytes.Repeat([]byte{7}, EncryptionKeySize)
+
+func TestSealerRoundTrip(t *testing.T) {
+	s, err := newSealer(testKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	plaintext := []byte(`{"user_id":"123","dm":"ng"}`)
+	sealed, err := s.seal("welcomebot:session:1", plaintext)
+	if err != nil {
+		t.Fatalf("seal: %v", err)
+	}
+	if sealed[0] != encryptedV1 || bytes.Contains(sealed, []byte("user_id")) {
+		t.Fatalf("expected a versioned ciphertext, got %q", sealed)
+	}
+
+	opened, err := s.open("welcomebot:session:1", sealed)
+	if err != nil || !bytes.Equal(opened, plaintext) {
+		t.Errorf("open: got %q, %v", opened, err)
+	}
+
+	// The value is bound to its key
+	if _, err := s.open("welcomebot:session:2", sealed); err == nil {
+		t.Error("expected a value moved to another key to fail")
+	}
+}
+
+func TestSealerMixedValues(t *testing.T) {
+	s, err := newSealer(testKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Plaintext cached before encryption was turned on is still readable
+	plaintext := []byte(`{"guild_id":"1"}`)
+	if opened, err := s.open("k", plaintext); err != nil || !bytes.Equal(opened, plaintext) {
+		t.Errorf("plaintext: got %q, %v", opened, err)
+	}
+
+	// Without a key, values pass through and encrypted ones are refused
+	var off *sealer
+	if stored, _ := off.seal("k", plaintext); !bytes.Equal(stored, plaintext) {
+		t.Errorf("expected plaintext to be stored without a key, got %q", stored)
+	}
+	sealed, _ := s.seal("k", plaintext)
+	if _, err := off.open("k", sealed); !errors.Is(err, ErrEncrypted) {
+		t.Errorf("expected ErrEncrypted, got %v", err)
+	}
+}
+
+func TestParseEncryptionKey(t *testing.T) {
+	if key, err := ParseEncryptionKey(""); key != nil || err != nil {
+		t.Errorf("empty: got %v, %v; want encryption off", key, err)
+	}
+
+	key, err := ParseEncryptionKey(base64.StdEncoding.EncodeToString(testKey))
+	if err != nil || !bytes.Equal(key, testKey) {
+		t.Errorf("got %v, %v", key, err)
+	}
+
+	for _, bad := range []string{"not base64!", base64.StdEncoding.EncodeToString([]byte("short"))} {
+		if _, err := ParseEncryptionKey(bad); err == nil {
+			t.Errorf("ParseEncryptionKey(%q) should fail", bad)
+		}
+	}
+}
+
+// benchmarkValue is about the size of a cached welcome config.
+var benchmarkValue = bytes.Repeat([]byte(`{"role_id":"123456789012345678"},`), 24)
+
+func BenchmarkSeal(b *testing.B) {
+	s, err := newSealer(testKey)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.SetBytes(int64(len(benchmarkValue)))
+	for i := 0; i < b.N; i++ {
+		if _, err := s.seal("welcomebot:config:1", benchmarkValue); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkOpen(b *testing.B) {
+	s, err := newSealer(testKey)
+	if err != nil {
+		b.Fatal(err)
+	}
+	sealed, err := s.seal("welcomebot:config:1", benchmarkValue)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.SetBytes(int64(len(benchmarkValue)))
+	for i := 0; i < b.N; i++ {
+		if _, err := s.open("welcomebot:config:1", sealed); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
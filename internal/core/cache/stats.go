@@ -0,0 +1,173 @@
+package cache
+
+import (
+	"context"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"welcomebot/internal/core/logger"
+)
+
+// keyPrefixes maps key prefixes to the label their hits and misses are
+// counted under; the first match wins. An entry with a suffix only matches
+// keys ending in it. Keys matching none of them count as "other".
+var keyPrefixes = [...]struct {
+	prefix string
+	suffix string
+	label  string
+}{
+	{"welcomebot:config:", "", "welcome_config"},
+	{"welcomebot:session:", ":hb", "session_heartbeat"}, // Checked by master, not a session lookup
+	{"welcomebot:session:", "", "session"},
+	{"welcomebot:wizard:", "", "wizard"},
+	{"welcomebot:agerange:", "", "agerange"},
+	{"welcomebot:gender:", "", "gender"},
+	{"welcomebot:voicetype:", "", "voicetype"},
+	{"welcomebot:otherroles:", "", "otherroles"},
+	{"welcomebot:selfintro:", "", "selfintro"},
+	{"welcomebot:slaves:", "", "slaves"},
+	{"welcomebot:i18n:", "", "i18n"},
+	{"welcomebot:onboarding:", "", "onboarding"},
+	{"welcomebot:guide_roles:", "", "guide_roles"},
+}
+
+const otherLabel = "other"
+
+// Stats counts cache hits and misses per key prefix. Recording a lookup
+// does not allocate. The zero value is ready to use, and a nil *Stats
+// records nothing.
+type Stats struct {
+	hits   [len(keyPrefixes) + 1]atomic.Uint64
+	misses [len(keyPrefixes) + 1]atomic.Uint64
+}
+
+// PrefixStats is the hit and miss count of one key prefix.
+type PrefixStats struct {
+	Label  string
+	Hits   uint64
+	Misses uint64
+}
+
+// HitRate returns hits over lookups, or 0 if there were none.
+func (p PrefixStats) HitRate() float64 {
+	if p.Hits+p.Misses == 0 {
+		return 0
+	}
+	return float64(p.Hits) / float64(p.Hits+p.Misses)
+}
+
+// Record counts one lookup of key.
+func (s *Stats) Record(key string, hit bool) {
+	if s == nil {
+		return
+	}
+
+	n := prefixIndex(key)
+	if hit {
+		s.hits[n].Add(1)
+	} else {
+		s.misses[n].Add(1)
+	}
+}
+
+// Snapshot returns the counts of every prefix, "other" last.
+func (s *Stats) Snapshot() []PrefixStats {
+	if s == nil {
+		return nil
+	}
+
+	snapshot := make([]PrefixStats, len(keyPrefixes)+1)
+	for n := range snapshot {
+		snapshot[n] = PrefixStats{Label: labelAt(n), Hits: s.hits[n].Load(), Misses: s.misses[n].Load()}
+	}
+	return snapshot
+}
+
+// Labels returns every label counts are kept under.
+func Labels() []string {
+	labels := make([]string, len(keyPrefixes)+1)
+	for n := range labels {
+		labels[n] = labelAt(n)
+	}
+	return labels
+}
+
+// Hits returns the hits counted under label.
+func (s *Stats) Hits(label string) uint64 {
+	if n := labelIndex(label); s != nil && n >= 0 {
+		return s.hits[n].Load()
+	}
+	return 0
+}
+
+// Misses returns the misses counted under label.
+func (s *Stats) Misses(label string) uint64 {
+	if n := labelIndex(label); s != nil && n >= 0 {
+		return s.misses[n].Load()
+	}
+	return 0
+}
+
+// HitRateInterval is how often the bots log cache hit rates.
+const HitRateInterval = 5 * time.Minute
+
+// LogHitRates logs the hit rate of each prefix looked up during the last
+// interval until ctx is cancelled.
+func LogHitRates(ctx context.Context, s *Stats, log logger.Logger, interval time.Duration) {
+	if s == nil {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	last := s.Snapshot()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		current := s.Snapshot()
+		for n, p := range current {
+			delta := PrefixStats{Label: p.Label, Hits: p.Hits - last[n].Hits, Misses: p.Misses - last[n].Misses}
+			if delta.Hits+delta.Misses == 0 {
+				continue
+			}
+			log.Info("cache hit rate",
+				"prefix", delta.Label,
+				"hits", delta.Hits,
+				"misses", delta.Misses,
+				"hit_rate", delta.HitRate(),
+			)
+		}
+		last = current
+	}
+}
+
+func prefixIndex(key string) int {
+	for n, p := range keyPrefixes {
+		if strings.HasPrefix(key, p.prefix) && strings.HasSuffix(key, p.suffix) {
+			return n
+		}
+	}
+	return len(keyPrefixes)
+}
+
+func labelIndex(label string) int {
+	for n := 0; n <= len(keyPrefixes); n++ {
+		if labelAt(n) == label {
+			return n
+		}
+	}
+	return -1
+}
+
+func labelAt(n int) string {
+	if n == len(keyPrefixes) {
+		return otherLabel
+	}
+	return keyPrefixes[n].label
+}
@@ -0,0 +1,59 @@
+package cache_test
+
+import (
+	"testing"
+
+	"welcomebot/internal/core/cache"
+)
+
+func TestStats(t *testing.T) {
+	var stats cache.Stats
+	stats.Record("welcomebot:config:guild-1", true)
+	stats.Record("welcomebot:config:guild-1", false)
+	stats.Record("welcomebot:session:guild-1:user-1", true)
+	stats.Record("welcomebot:session:guild-1:user-1:hb", true)
+	stats.Record("unrelated", false)
+
+	if got := stats.Hits("welcome_config"); got != 1 {
+		t.Errorf("welcome_config hits = %d, want 1", got)
+	}
+	if got := stats.Misses("welcome_config"); got != 1 {
+		t.Errorf("welcome_config misses = %d, want 1", got)
+	}
+	if got := stats.Hits("session"); got != 1 {
+		t.Errorf("session hits = %d, want 1", got)
+	}
+	if got := stats.Hits("session_heartbeat"); got != 1 {
+		t.Errorf("session_heartbeat hits = %d, want 1", got)
+	}
+	if got := stats.Misses("other"); got != 1 {
+		t.Errorf("other misses = %d, want 1", got)
+	}
+
+	snapshot := stats.Snapshot()
+	if last := snapshot[len(snapshot)-1]; last.Label != "other" {
+		t.Errorf("expected other last, got %q", last.Label)
+	}
+	if rate := snapshot[0].HitRate(); rate != 0.5 {
+		t.Errorf("welcome_config hit rate = %v, want 0.5", rate)
+	}
+}
+
+func TestStats_RecordDoesNotAllocate(t *testing.T) {
+	var stats cache.Stats
+	allocs := testing.AllocsPerRun(100, func() {
+		stats.Record("welcomebot:wizard:guild-1", true)
+		stats.Record("unrelated", false)
+	})
+	if allocs != 0 {
+		t.Errorf("Record allocated %v times per run", allocs)
+	}
+}
+
+func TestStats_Nil(t *testing.T) {
+	var stats *cache.Stats
+	stats.Record("welcomebot:config:guild-1", true)
+	if stats.Snapshot() != nil || stats.Hits("welcome_config") != 0 {
+		t.Error("expected a nil Stats to record nothing")
+	}
+}
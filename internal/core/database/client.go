@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"sync/atomic"
 	"time"
 
 	_ "github.com/lib/pq"
@@ -26,6 +27,11 @@ type Config struct {
 	Password string
 	Database string
 	SSLMode  string
+
+	// ReplicaHosts are optional read replicas ("host" or "host:port"),
+	// sharing the primary's credentials. Only reads whose context is
+	// marked with ReadFromReplica use them.
+	ReplicaHosts []string
 }
 
 // DefaultConfig returns default database configuration.
@@ -39,36 +45,90 @@ func DefaultConfig() Config {
 
 // postgresClient implements Client using PostgreSQL.
 type postgresClient struct {
-	db *sql.DB
+	db       *sql.DB
+	replicas []*replica    // Read-only pools; empty without replicas
+	next     atomic.Uint64 // Round-robin position in replicas
+	// lastWrite is when Exec last ran, in Unix nanoseconds
+	lastWrite atomic.Int64
 }
 
 // New creates a new database client with the given configuration.
 func New(cfg Config) (Client, error) {
-	connStr := buildConnectionString(cfg)
-
-	db, err := sql.Open("postgres", connStr)
+	db, err := openPool(cfg)
 	if err != nil {
 		return nil, fmt.Errorf("open database: %w", err)
 	}
 
+	c := &postgresClient{db: db}
+	for _, host := range cfg.ReplicaHosts {
+		pool, err := newPool(replicaConfig(cfg, host))
+		if err != nil {
+			_ = c.Close()
+			return nil, fmt.Errorf("open replica %s: %w", host, err)
+		}
+		// A replica that is down at startup is tried again later; reads
+		// go to the primary meanwhile
+		r := &replica{db: pool}
+		if err := ping(pool); err != nil {
+			r.markDown()
+		}
+		c.replicas = append(c.replicas, r)
+	}
+	return c, nil
+}
+
+// openPool opens and pings a connection pool.
+func openPool(cfg Config) (*sql.DB, error) {
+	db, err := newPool(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if err := ping(db); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+// newPool opens a connection pool without connecting.
+func newPool(cfg Config) (*sql.DB, error) {
+	db, err := sql.Open("postgres", buildConnectionString(cfg))
+	if err != nil {
+		return nil, err
+	}
+
 	// Configure connection pool
 	db.SetMaxOpenConns(25)
 	db.SetMaxIdleConns(5)
 	db.SetConnMaxLifetime(5 * time.Minute)
+	return db, nil
+}
 
-	// Test connection
+// ping tests a pool's connection.
+func ping(db *sql.DB) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
 	if err := db.PingContext(ctx); err != nil {
-		return nil, fmt.Errorf("ping database: %w", err)
+		return fmt.Errorf("ping: %w", err)
 	}
-
-	return &postgresClient{db: db}, nil
+	return nil
 }
 
-// Query executes a query that returns rows.
+// Query executes a query that returns rows. A failed replica read is
+// retried on the primary, and the replica is skipped for a while.
 func (c *postgresClient) Query(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	if r := c.reader(ctx); r != nil {
+		rows, err := r.db.QueryContext(ctx, query, args...)
+		if err == nil {
+			return rows, nil
+		}
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("query: %w", err)
+		}
+		r.markDown()
+	}
+
 	rows, err := c.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("query: %w", err)
@@ -76,22 +136,36 @@ func (c *postgresClient) Query(ctx context.Context, query string, args ...interf
 	return rows, nil
 }
 
-// QueryRow executes a query that returns at most one row.
+// QueryRow executes a query that returns at most one row. Like Query, a
+// failed replica read is retried on the primary.
 func (c *postgresClient) QueryRow(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	if r := c.reader(ctx); r != nil {
+		// Err reports a failed query before Scan; no rows isn't one
+		row := r.db.QueryRowContext(ctx, query, args...)
+		if row.Err() == nil || ctx.Err() != nil {
+			return row
+		}
+		r.markDown()
+	}
 	return c.db.QueryRowContext(ctx, query, args...)
 }
 
-// Exec executes a query without returning any rows.
+// Exec executes a query without returning any rows. Exec always runs on
+// the primary.
 func (c *postgresClient) Exec(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
 	result, err := c.db.ExecContext(ctx, query, args...)
+	c.lastWrite.Store(time.Now().UnixNano())
 	if err != nil {
 		return nil, fmt.Errorf("exec: %w", err)
 	}
 	return result, nil
 }
 
-// Close closes the database connection.
+// Close closes the database connections.
 func (c *postgresClient) Close() error {
+	for _, r := range c.replicas {
+		_ = r.db.Close()
+	}
 	if err := c.db.Close(); err != nil {
 		return fmt.Errorf("close database: %w", err)
 	}
@@ -2,5 +2,13 @@
 //
 // It offers a clean interface for database operations with
 // proper connection pooling and context support.
+//
+// Reads can be spread over read replicas (Config.ReplicaHosts). Routing is
+// opt-in per call: only contexts marked with ReadFromReplica use a replica,
+// and for a few seconds after a write through the same client all reads go
+// to the primary. Writes from other processes aren't seen, so values read
+// from a replica are cached for at most ReplicaCacheTTL. A replica that
+// fails a read is skipped for a while and the read is served by the
+// primary.
 package database
 
@@ -0,0 +1,4 @@
+-- Add staff notification settings to guild_welcome_config table
+ALTER TABLE guild_welcome_config
+    ADD COLUMN staff_notify_channel_id VARCHAR(20),
+    ADD COLUMN staff_role_id VARCHAR(20);
@@ -0,0 +1,3 @@
+-- Add onboarding voice channel name template to guild_welcome_config table
+ALTER TABLE guild_welcome_config
+    ADD COLUMN vc_name_template VARCHAR(100);
@@ -0,0 +1,3 @@
+-- Add ephemeral step confirmation setting to guild_welcome_config table
+ALTER TABLE guild_welcome_config
+    ADD COLUMN ephemeral_confirmations BOOLEAN NOT NULL DEFAULT FALSE;
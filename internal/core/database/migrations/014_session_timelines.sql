@@ -0,0 +1,22 @@
+-- Migration: Onboarding session timelines
+-- Created: 2026-10-16
+
+CREATE TABLE IF NOT EXISTS session_timelines (
+    id BIGSERIAL PRIMARY KEY,
+    guild_id VARCHAR(20) NOT NULL,
+    user_id VARCHAR(20) NOT NULL,
+    slave_id VARCHAR(50),
+    guide VARCHAR(50),
+    outcome VARCHAR(20) NOT NULL,
+    started_at TIMESTAMP NOT NULL,
+    ended_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    events JSONB NOT NULL DEFAULT '[]'
+);
+
+-- Index for fetching a member's most recent sessions
+CREATE INDEX IF NOT EXISTS idx_session_timelines_user ON session_timelines(guild_id, user_id, ended_at DESC);
+
+-- Comments
+COMMENT ON TABLE session_timelines IS 'Event trace of each onboarding session, for support investigations';
+COMMENT ON COLUMN session_timelines.outcome IS 'completed, failed, inactive, timed_out or ended';
+COMMENT ON COLUMN session_timelines.events IS 'Ordered list of {at, kind, detail} events';
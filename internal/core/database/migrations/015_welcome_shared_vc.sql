@@ -0,0 +1,3 @@
+-- Add shared onboarding voice channel to guild_welcome_config table
+ALTER TABLE guild_welcome_config
+    ADD COLUMN shared_vc_channel_id VARCHAR(20);
@@ -0,0 +1,7 @@
+-- Add self-introduction post formatting to guild_selfintro_channels table
+ALTER TABLE guild_selfintro_channels
+    ADD COLUMN post_title VARCHAR(256),
+    ADD COLUMN post_color INTEGER;
+
+COMMENT ON COLUMN guild_selfintro_channels.post_title IS 'Title template for intro posts made on onboarding completion; {name} is the member';
+COMMENT ON COLUMN guild_selfintro_channels.post_color IS 'Embed color for intro posts; NULL uses the default';
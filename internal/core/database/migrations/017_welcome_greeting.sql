@@ -0,0 +1,3 @@
+-- Add the guide whose greeting plays when a member joins their onboarding VC
+ALTER TABLE guild_welcome_config
+    ADD COLUMN greeting_guide VARCHAR(64);
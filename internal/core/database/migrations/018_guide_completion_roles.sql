@@ -0,0 +1,10 @@
+-- Per-guide overrides for the roles granted when onboarding completes.
+-- An empty column falls back to the guild's member/visitor role.
+CREATE TABLE IF NOT EXISTS guild_guide_completion_roles (
+    guild_id VARCHAR(20) NOT NULL,
+    guide VARCHAR(64) NOT NULL,
+    member_role_id VARCHAR(20),
+    visitor_role_id VARCHAR(20),
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (guild_id, guide)
+);
@@ -0,0 +1,3 @@
+-- Add overflow voice channel categories to guild_welcome_config table
+ALTER TABLE guild_welcome_config
+    ADD COLUMN overflow_category_ids TEXT[];
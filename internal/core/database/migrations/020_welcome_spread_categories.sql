@@ -0,0 +1,3 @@
+-- Add voice channel categories that share new onboarding VCs to guild_welcome_config table
+ALTER TABLE guild_welcome_config
+    ADD COLUMN spread_category_ids TEXT[];
@@ -0,0 +1,15 @@
+-- Migration: Member onboarding selections
+-- Created: 2026-10-16
+
+CREATE TABLE IF NOT EXISTS member_onboarding_selections (
+    guild_id VARCHAR(20) NOT NULL,
+    user_id VARCHAR(20) NOT NULL,
+    guide VARCHAR(50),
+    selections JSONB NOT NULL DEFAULT '{}',
+    completed_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (guild_id, user_id)
+);
+
+-- Comments
+COMMENT ON TABLE member_onboarding_selections IS 'Choices each member made in their last completed onboarding, for staff audits';
+COMMENT ON COLUMN member_onboarding_selections.selections IS 'Step 3 selection name to chosen values, e.g. {"age": ["30late"]}';
@@ -0,0 +1,3 @@
+-- Add the role given to members as they join to guild_welcome_config table
+ALTER TABLE guild_welcome_config
+    ADD COLUMN initial_role_id VARCHAR(20);
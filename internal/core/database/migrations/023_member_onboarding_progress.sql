@@ -0,0 +1,17 @@
+-- Migration: Member onboarding progress
+-- Created: 2026-10-16
+
+CREATE TABLE IF NOT EXISTS member_onboarding_progress (
+    guild_id VARCHAR(20) NOT NULL,
+    user_id VARCHAR(20) NOT NULL,
+    guide VARCHAR(50) NOT NULL,
+    step INTEGER NOT NULL,
+    selections JSONB NOT NULL DEFAULT '{}',
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (guild_id, user_id)
+);
+
+-- Comments
+COMMENT ON TABLE member_onboarding_progress IS 'How far each member got in an unfinished onboarding, so a restart can resume';
+COMMENT ON COLUMN member_onboarding_progress.step IS 'Last tutorial step the member completed (1-6)';
+COMMENT ON COLUMN member_onboarding_progress.selections IS 'Step 3 selection name to chosen values, as in member_onboarding_selections';
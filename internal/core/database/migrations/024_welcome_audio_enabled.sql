@@ -0,0 +1,4 @@
+-- Add the audio switch to guild_welcome_config. With audio off, sessions
+-- run text-only in a private text channel instead of a voice channel.
+ALTER TABLE guild_welcome_config
+    ADD COLUMN audio_enabled BOOLEAN NOT NULL DEFAULT TRUE;
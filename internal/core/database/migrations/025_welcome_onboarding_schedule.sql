@@ -0,0 +1,4 @@
+-- Add the onboarding schedule to guild_welcome_config, stored as
+-- "<days> <HH:MM-HH:MM> <timezone>". NULL keeps onboarding always open.
+ALTER TABLE guild_welcome_config
+    ADD COLUMN onboarding_schedule TEXT;
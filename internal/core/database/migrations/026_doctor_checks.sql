@@ -0,0 +1,11 @@
+-- Migration: Doctor checks
+-- Created: 2026-10-16
+
+-- Scratch rows written and read back by cmd/doctor. Each run deletes its
+-- row again, so the table is normally empty.
+CREATE TABLE IF NOT EXISTS doctor_checks (
+    id VARCHAR(64) PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+COMMENT ON TABLE doctor_checks IS 'Scratch rows for the doctor command''s write and read check';
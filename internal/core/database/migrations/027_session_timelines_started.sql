@@ -0,0 +1,5 @@
+-- Migration: Index session timelines by start time
+-- Created: 2026-10-16
+
+-- Index for exporting a guild's sessions over a date range
+CREATE INDEX IF NOT EXISTS idx_session_timelines_started ON session_timelines(guild_id, started_at);
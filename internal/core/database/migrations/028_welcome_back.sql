@@ -0,0 +1,5 @@
+-- Add the welcome back switch to guild_welcome_config. When on, members
+-- who completed onboarding before are offered their previous roles back
+-- instead of going through every step again.
+ALTER TABLE guild_welcome_config
+    ADD COLUMN welcome_back_enabled BOOLEAN NOT NULL DEFAULT FALSE;
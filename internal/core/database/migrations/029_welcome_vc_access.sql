@@ -0,0 +1,6 @@
+-- Add extra access to onboarding channels to guild_welcome_config. The
+-- listed roles and members may see and join every session's channel, for
+-- example staff assisting members; @everyone stays hidden.
+ALTER TABLE guild_welcome_config
+    ADD COLUMN vc_access_role_ids TEXT[],
+    ADD COLUMN vc_access_user_ids TEXT[];
@@ -0,0 +1,5 @@
+-- Record which version of the welcome button message is posted, so master
+-- can edit buttons posted by older versions (with outdated custom IDs) at
+-- startup. Existing buttons predate versioning and start at 0.
+ALTER TABLE guild_welcome_config
+    ADD COLUMN button_version INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,8 @@
+-- Add the guild's own completion roles to guild_welcome_config. With
+-- custom_completion_roles on, completing onboarding grants and revokes the
+-- listed roles instead of granting the visitor and member roles and
+-- revoking the 説明会 roles.
+ALTER TABLE guild_welcome_config
+    ADD COLUMN custom_completion_roles BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN completion_grant_role_ids TEXT[],
+    ADD COLUMN completion_revoke_role_ids TEXT[];
@@ -0,0 +1,5 @@
+-- Add the languages members pick from before guide selection to
+-- guild_welcome_config. With fewer than two the step is skipped and the
+-- session follows the guild's language.
+ALTER TABLE guild_welcome_config
+    ADD COLUMN session_locales TEXT[];
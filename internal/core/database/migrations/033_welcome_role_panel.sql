@@ -0,0 +1,6 @@
+-- Add the channel and message of the self-service role panel to
+-- guild_welcome_config. Members use it to change the step 3 roles
+-- onboarding gave them.
+ALTER TABLE guild_welcome_config
+    ADD COLUMN role_panel_channel_id TEXT,
+    ADD COLUMN role_panel_message_id TEXT;
@@ -0,0 +1,4 @@
+-- Add whether onboarding channels keep their category's permissions to
+-- guild_welcome_config. Off keeps every channel hidden from @everyone.
+ALTER TABLE guild_welcome_config
+    ADD COLUMN sync_category_permissions BOOLEAN NOT NULL DEFAULT FALSE;
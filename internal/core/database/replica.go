@@ -0,0 +1,85 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"net"
+	"sync/atomic"
+	"time"
+)
+
+// replicaLagWindow is how long after a write every read goes to the
+// primary, so a caller never reads back data the replicas have not caught
+// up with yet. Only writes made through the same client count; a write
+// from another process is not seen.
+const replicaLagWindow = 5 * time.Second
+
+// replicaDownFor is how long reads skip a replica after it failed one.
+const replicaDownFor = 30 * time.Second
+
+// ReplicaCacheTTL bounds how long a value read with ReadFromReplica may be
+// cached. The value may come from a replica that hadn't caught up with a
+// write made by another process, and must not stay cached for good.
+const ReplicaCacheTTL = 10 * time.Minute
+
+// replica is a read-only pool and when it may be used again after a
+// failed read.
+type replica struct {
+	db        *sql.DB
+	downUntil atomic.Int64 // Unix nanoseconds
+}
+
+// markDown makes reads skip r for replicaDownFor.
+func (r *replica) markDown() {
+	r.downUntil.Store(time.Now().Add(replicaDownFor).UnixNano())
+}
+
+func (r *replica) up() bool {
+	return time.Now().UnixNano() >= r.downUntil.Load()
+}
+
+type replicaKey struct{}
+
+// ReadFromReplica marks ctx so Query and QueryRow may be served by a read
+// replica. Use it for reads that tolerate slightly stale data, such as
+// config lookups on a cache miss, and cache what they return for at most
+// ReplicaCacheTTL. Without replicas it has no effect.
+func ReadFromReplica(ctx context.Context) context.Context {
+	return context.WithValue(ctx, replicaKey{}, true)
+}
+
+func wantsReplica(ctx context.Context) bool {
+	ok, _ := ctx.Value(replicaKey{}).(bool)
+	return ok
+}
+
+// reader returns the replica a read should use: the next one in turn that
+// is up, if ctx allows it and nothing was written recently. It returns nil
+// when the read should go to the primary.
+func (c *postgresClient) reader(ctx context.Context) *replica {
+	if len(c.replicas) == 0 || !wantsReplica(ctx) {
+		return nil
+	}
+	if time.Since(time.Unix(0, c.lastWrite.Load())) < replicaLagWindow {
+		return nil
+	}
+	n := c.next.Add(1)
+	for i := range c.replicas {
+		r := c.replicas[int((n+uint64(i))%uint64(len(c.replicas)))]
+		if r.up() {
+			return r
+		}
+	}
+	return nil
+}
+
+// replicaConfig returns cfg pointed at a replica. host may carry its own
+// port; otherwise the primary's port is used.
+func replicaConfig(cfg Config, host string) Config {
+	if h, port, err := net.SplitHostPort(host); err == nil {
+		cfg.Host, cfg.Port = h, port
+		return cfg
+	}
+	cfg.Host = host
+	return cfg
+}
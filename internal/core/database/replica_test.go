@@ -0,0 +1,77 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+)
+
+func TestReaderRouting(t *testing.T) {
+	// sql.Open does not connect, so these pools are only compared
+	primary, _ := sql.Open("postgres", "")
+	r1, _ := sql.Open("postgres", "")
+	r2, _ := sql.Open("postgres", "")
+	c := &postgresClient{db: primary, replicas: []*replica{{db: r1}, {db: r2}}}
+
+	if c.reader(context.Background()) != nil {
+		t.Error("unmarked reads must use the primary")
+	}
+
+	ctx := ReadFromReplica(context.Background())
+	first, second := c.reader(ctx), c.reader(ctx)
+	if first == nil || second == nil || first == second {
+		t.Error("marked reads should alternate between replicas")
+	}
+
+	c.lastWrite.Store(time.Now().UnixNano())
+	if c.reader(ctx) != nil {
+		t.Error("reads right after a write must use the primary")
+	}
+
+	c.lastWrite.Store(time.Now().Add(-2 * replicaLagWindow).UnixNano())
+	if c.reader(ctx) == nil {
+		t.Error("expected replicas once the lag window has passed")
+	}
+
+	noReplicas := &postgresClient{db: primary}
+	if noReplicas.reader(ctx) != nil {
+		t.Error("without replicas every read uses the primary")
+	}
+}
+
+func TestReaderSkipsDownReplicas(t *testing.T) {
+	primary, _ := sql.Open("postgres", "")
+	r1, _ := sql.Open("postgres", "")
+	r2, _ := sql.Open("postgres", "")
+	c := &postgresClient{db: primary, replicas: []*replica{{db: r1}, {db: r2}}}
+	ctx := ReadFromReplica(context.Background())
+
+	c.replicas[0].markDown()
+	for i := 0; i < 3; i++ {
+		if got := c.reader(ctx); got != c.replicas[1] {
+			t.Fatal("reads must skip a replica that is down")
+		}
+	}
+
+	c.replicas[1].markDown()
+	if c.reader(ctx) != nil {
+		t.Error("with every replica down reads must use the primary")
+	}
+
+	c.replicas[0].downUntil.Store(time.Now().Add(-time.Second).UnixNano())
+	if c.reader(ctx) != c.replicas[0] {
+		t.Error("a replica should be used again once its down period ends")
+	}
+}
+
+func TestReplicaConfig(t *testing.T) {
+	cfg := Config{Host: "db", Port: "5432", User: "u"}
+
+	if got := replicaConfig(cfg, "replica-1"); got.Host != "replica-1" || got.Port != "5432" || got.User != "u" {
+		t.Errorf("unexpected config %+v", got)
+	}
+	if got := replicaConfig(cfg, "replica-2:6432"); got.Host != "replica-2" || got.Port != "6432" {
+		t.Errorf("unexpected config %+v", got)
+	}
+}
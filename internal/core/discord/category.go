@@ -0,0 +1,27 @@
+package discord
+
+import "github.com/bwmarrin/discordgo"
+
+// MaxCategoryChannels is how many channels Discord allows in one category.
+const MaxCategoryChannels = 50
+
+// CategoryFull reports whether the state cache shows categoryID already
+// holding MaxCategoryChannels channels. Without the guild in state it
+// reports false and leaves the decision to the API.
+func CategoryFull(s *discordgo.Session, guildID, categoryID string) bool {
+	guild, err := s.State.Guild(guildID)
+	if err != nil {
+		return false
+	}
+
+	s.State.RLock()
+	defer s.State.RUnlock()
+
+	count := 0
+	for _, ch := range guild.Channels {
+		if ch.ParentID == categoryID {
+			count++
+		}
+	}
+	return count >= MaxCategoryChannels
+}
@@ -0,0 +1,32 @@
+package discord_test
+
+import (
+	"fmt"
+	"testing"
+
+	"welcomebot/internal/core/discord"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func TestCategoryFull(t *testing.T) {
+	s, _ := discordgo.New("")
+	guild := &discordgo.Guild{ID: "guild-1"}
+	for n := 0; n < discord.MaxCategoryChannels; n++ {
+		guild.Channels = append(guild.Channels, &discordgo.Channel{ID: fmt.Sprintf("vc-%d", n), ParentID: "category-full"})
+	}
+	guild.Channels = append(guild.Channels, &discordgo.Channel{ID: "vc-other", ParentID: "category-open"})
+	if err := s.State.GuildAdd(guild); err != nil {
+		t.Fatalf("GuildAdd: %v", err)
+	}
+
+	if !discord.CategoryFull(s, "guild-1", "category-full") {
+		t.Error("expected a category at the limit to be full")
+	}
+	if discord.CategoryFull(s, "guild-1", "category-open") {
+		t.Error("expected a category below the limit not to be full")
+	}
+	if discord.CategoryFull(s, "guild-unknown", "category-full") {
+		t.Error("expected a guild missing from state not to be full")
+	}
+}
@@ -0,0 +1,106 @@
+package discord
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// MaxChannelNameLength is Discord's limit for channel names.
+const MaxChannelNameLength = 100
+
+// DefaultChannelNameTemplate names onboarding voice channels after the user.
+const DefaultChannelNameTemplate = "onboarding-{username}"
+
+// ChannelNameVars are the values substituted into a channel name template.
+type ChannelNameVars struct {
+	Username string // {username}
+	UserID   string // {userid}
+	Seq      string // {n}
+}
+
+// ExampleChannelNameVars are sample values for previewing a template.
+var ExampleChannelNameVars = ChannelNameVars{Username: "user", UserID: "123456789", Seq: "1"}
+
+// ValidChannelNameTemplate reports whether a template produces a usable
+// name on its own, without relying on the fallback.
+func ValidChannelNameTemplate(template string) bool {
+	if strings.TrimSpace(template) == "" {
+		return false
+	}
+	return SanitizeChannelName(expand(template, ExampleChannelNameVars)) != ""
+}
+
+// RenderChannelName expands a template and sanitizes the result. An empty
+// template uses DefaultChannelNameTemplate; a template that sanitizes to
+// nothing falls back to "onboarding-<userid>".
+func RenderChannelName(template string, vars ChannelNameVars) string {
+	if strings.TrimSpace(template) == "" {
+		template = DefaultChannelNameTemplate
+	}
+
+	if name := SanitizeChannelName(expand(template, vars)); name != "" {
+		return name
+	}
+	return SanitizeChannelName("onboarding-" + vars.UserID)
+}
+
+// patternVars mark where each placeholder lands after sanitization. They are
+// plain lowercase letters so SanitizeChannelName leaves them intact.
+var patternVars = ChannelNameVars{Username: "qxusernameqx", UserID: "qxuseridqx", Seq: "qxseqqx"}
+
+// MatchesChannelTemplate reports whether name could have been produced by
+// RenderChannelName for template, including the "onboarding-<userid>" fallback.
+func MatchesChannelTemplate(template, name string) bool {
+	if strings.TrimSpace(template) == "" {
+		template = DefaultChannelNameTemplate
+	}
+
+	pattern := regexp.QuoteMeta(SanitizeChannelName(expand(template, patternVars)))
+	pattern = strings.NewReplacer(
+		patternVars.Username, ".+",
+		patternVars.UserID, `\d+`,
+		patternVars.Seq, `\d+`,
+	).Replace(pattern)
+
+	if regexp.MustCompile("^" + pattern + "$").MatchString(name) {
+		return true
+	}
+	return regexp.MustCompile(`^onboarding-\d+$`).MatchString(name)
+}
+
+func expand(template string, vars ChannelNameVars) string {
+	return strings.NewReplacer(
+		"{username}", vars.Username,
+		"{userid}", vars.UserID,
+		"{n}", vars.Seq,
+	).Replace(template)
+}
+
+// SanitizeChannelName applies Discord's text-style channel naming rules:
+// lowercase letters, digits, '-' and '_', no leading or trailing dashes,
+// at most MaxChannelNameLength characters.
+func SanitizeChannelName(name string) string {
+	var b strings.Builder
+	dash := false
+
+	for _, r := range strings.ToLower(name) {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_':
+			b.WriteRune(r)
+			dash = false
+		case r == '-' || unicode.IsSpace(r):
+			// Collapse runs of dashes and whitespace into one dash
+			if !dash && b.Len() > 0 {
+				b.WriteRune('-')
+				dash = true
+			}
+		}
+	}
+
+	runes := []rune(strings.TrimRight(b.String(), "-"))
+	if len(runes) > MaxChannelNameLength {
+		runes = []rune(strings.TrimRight(string(runes[:MaxChannelNameLength]), "-"))
+	}
+	return string(runes)
+}
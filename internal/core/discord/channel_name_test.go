@@ -0,0 +1,107 @@
+package discord_test
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"welcomebot/internal/core/discord"
+)
+
+func TestRenderChannelName(t *testing.T) {
+	vars := discord.ChannelNameVars{Username: "Alice", UserID: "123", Seq: "7"}
+
+	tests := []struct {
+		name     string
+		template string
+		want     string
+	}{
+		{"default", "", "onboarding-alice"},
+		{"sequence", "onboarding-{n}", "onboarding-7"},
+		{"user id", "welcome {userid}", "welcome-123"},
+		{"all placeholders", "{n}_{username}_{userid}", "7_alice_123"},
+		{"sanitizes to empty", "!!!", "onboarding-123"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := discord.RenderChannelName(tt.template, vars); got != tt.want {
+				t.Errorf("RenderChannelName(%q) = %q, want %q", tt.template, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSanitizeChannelName(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"Onboarding-Alice", "onboarding-alice"},
+		{"  hello   world  ", "hello-world"},
+		{"a--b", "a-b"},
+		{"-lead-and-trail-", "lead-and-trail"},
+		{"emoji 🎉 name!", "emoji-name"},
+		{"ようこそ-さん", "ようこそ-さん"},
+	}
+
+	for _, tt := range tests {
+		if got := discord.SanitizeChannelName(tt.in); got != tt.want {
+			t.Errorf("SanitizeChannelName(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestSanitizeChannelNameLength(t *testing.T) {
+	got := discord.SanitizeChannelName(strings.Repeat("あ", 150))
+	if n := utf8.RuneCountInString(got); n != discord.MaxChannelNameLength {
+		t.Errorf("expected %d characters, got %d", discord.MaxChannelNameLength, n)
+	}
+
+	// Truncation must not leave a trailing dash
+	got = discord.SanitizeChannelName(strings.Repeat("a", 99) + "-bbbb")
+	if strings.HasSuffix(got, "-") {
+		t.Errorf("expected no trailing dash, got %q", got)
+	}
+}
+
+func TestValidChannelNameTemplate(t *testing.T) {
+	valid := []string{"onboarding-{n}", "{username}", "welcome"}
+	invalid := []string{"", "   ", "!!!", "🎉"}
+
+	for _, template := range valid {
+		if !discord.ValidChannelNameTemplate(template) {
+			t.Errorf("expected %q to be valid", template)
+		}
+	}
+	for _, template := range invalid {
+		if discord.ValidChannelNameTemplate(template) {
+			t.Errorf("expected %q to be invalid", template)
+		}
+	}
+}
+
+func TestMatchesChannelTemplate(t *testing.T) {
+	tests := []struct {
+		template string
+		name     string
+		want     bool
+	}{
+		{"", "onboarding-alice", true},
+		{"", "onboarding-", false},
+		{"", "general", false},
+		{"onboarding-{n}", "onboarding-42", true},
+		{"onboarding-{n}", "onboarding-bob", false},
+		{"Welcome {username} #{n}", "welcome-alice-3", true},
+		{"Welcome {username} #{n}", "welcome-alice", false},
+		{"room_{userid}", "room_123", true},
+		{"room_{userid}", "roomx123", false},
+		{"!!!", "onboarding-123", true}, // fallback name
+	}
+
+	for _, tt := range tests {
+		if got := discord.MatchesChannelTemplate(tt.template, tt.name); got != tt.want {
+			t.Errorf("MatchesChannelTemplate(%q, %q) = %v, want %v", tt.template, tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package discord
+
+import "github.com/bwmarrin/discordgo"
+
+// RoleSelectMenu builds a single-choice role select menu with the given
+// roles pre-selected, so an admin editing a setting sees its current value.
+// Empty IDs are ignored.
+func RoleSelectMenu(customID, placeholder string, defaults ...string) discordgo.SelectMenu {
+	return discordgo.SelectMenu{
+		MenuType:      discordgo.RoleSelectMenu,
+		CustomID:      customID,
+		Placeholder:   placeholder,
+		DefaultValues: defaultValues(discordgo.SelectMenuDefaultValueRole, defaults),
+	}
+}
+
+// UserSelectMenu builds a single-choice member select menu with the given
+// members pre-selected. Empty IDs are ignored.
+func UserSelectMenu(customID, placeholder string, defaults ...string) discordgo.SelectMenu {
+	return discordgo.SelectMenu{
+		MenuType:      discordgo.UserSelectMenu,
+		CustomID:      customID,
+		Placeholder:   placeholder,
+		DefaultValues: defaultValues(discordgo.SelectMenuDefaultValueUser, defaults),
+	}
+}
+
+// ChannelSelectMenu builds a single-choice channel select menu limited to
+// channelTypes, with the given channels pre-selected. Empty IDs are ignored.
+func ChannelSelectMenu(customID, placeholder string, channelTypes []discordgo.ChannelType, defaults ...string) discordgo.SelectMenu {
+	return discordgo.SelectMenu{
+		MenuType:      discordgo.ChannelSelectMenu,
+		CustomID:      customID,
+		Placeholder:   placeholder,
+		ChannelTypes:  channelTypes,
+		DefaultValues: defaultValues(discordgo.SelectMenuDefaultValueChannel, defaults),
+	}
+}
+
+// defaultValues converts IDs to select menu defaults, skipping empty ones.
+func defaultValues(kind discordgo.SelectMenuDefaultValueType, ids []string) []discordgo.SelectMenuDefaultValue {
+	var values []discordgo.SelectMenuDefaultValue
+	for _, id := range ids {
+		if id != "" {
+			values = append(values, discordgo.SelectMenuDefaultValue{ID: id, Type: kind})
+		}
+	}
+	return values
+}
@@ -0,0 +1,34 @@
+package discord_test
+
+import (
+	"testing"
+
+	"welcomebot/internal/core/discord"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func TestRoleSelectMenuDefaults(t *testing.T) {
+	menu := discord.RoleSelectMenu("feature:role:select", "Pick a role", "role-1")
+	if menu.MenuType != discordgo.RoleSelectMenu || len(menu.DefaultValues) != 1 {
+		t.Fatalf("unexpected menu: %+v", menu)
+	}
+	if got := menu.DefaultValues[0]; got.ID != "role-1" || got.Type != discordgo.SelectMenuDefaultValueRole {
+		t.Errorf("unexpected default: %+v", got)
+	}
+
+	if menu := discord.RoleSelectMenu("feature:role:select", "Pick a role", ""); menu.DefaultValues != nil {
+		t.Errorf("expected no defaults for an unset role, got %+v", menu.DefaultValues)
+	}
+}
+
+func TestChannelSelectMenuDefaults(t *testing.T) {
+	types := []discordgo.ChannelType{discordgo.ChannelTypeGuildText}
+	menu := discord.ChannelSelectMenu("feature:channel:select", "Pick a channel", types, "channel-1")
+	if menu.MenuType != discordgo.ChannelSelectMenu || len(menu.ChannelTypes) != 1 {
+		t.Fatalf("unexpected menu: %+v", menu)
+	}
+	if got := menu.DefaultValues[0]; got.ID != "channel-1" || got.Type != discordgo.SelectMenuDefaultValueChannel {
+		t.Errorf("unexpected default: %+v", got)
+	}
+}
@@ -0,0 +1,29 @@
+package discord
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// IsNotFound reports whether err is Discord saying the object (message,
+// channel, member...) no longer exists.
+func IsNotFound(err error) bool {
+	var restErr *discordgo.RESTError
+	if !errors.As(err, &restErr) || restErr.Response == nil {
+		return false
+	}
+	return restErr.Response.StatusCode == http.StatusNotFound
+}
+
+// IsCategoryFull reports whether err is Discord refusing to create a
+// channel because its category already holds MaxCategoryChannels.
+func IsCategoryFull(err error) bool {
+	var restErr *discordgo.RESTError
+	if !errors.As(err, &restErr) {
+		return false
+	}
+	return bytes.Contains(restErr.ResponseBody, []byte("CHANNEL_PARENT_MAX_CHANNELS"))
+}
@@ -0,0 +1,70 @@
+package discord
+
+import (
+	"fmt"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// TopRolePosition returns the highest position among the member's roles.
+// A member with no roles sits at 0, the position of @everyone.
+func TopRolePosition(roles []*discordgo.Role, member *discordgo.Member) int {
+	top := 0
+	for _, role := range roles {
+		for _, id := range member.Roles {
+			if role.ID == id && role.Position > top {
+				top = role.Position
+			}
+		}
+	}
+	return top
+}
+
+// RolesAbove returns the roles in roleIDs that a member whose top role is
+// at top cannot grant or revoke: those at or above it, and roles managed
+// by an integration. Unknown and empty IDs are skipped.
+func RolesAbove(roles []*discordgo.Role, top int, roleIDs []string) []*discordgo.Role {
+	var blocked []*discordgo.Role
+	for _, id := range roleIDs {
+		for _, role := range roles {
+			if role.ID == id && (role.Position >= top || role.Managed) {
+				blocked = append(blocked, role)
+				break
+			}
+		}
+	}
+	return blocked
+}
+
+// UnmanageableRoles returns the roles in roleIDs that userID, normally a
+// bot, cannot assign in the guild because of the role hierarchy. Roles and
+// the member are read from the state cache, falling back to the API.
+func UnmanageableRoles(s *discordgo.Session, guildID, userID string, roleIDs []string) ([]*discordgo.Role, error) {
+	roles, err := guildRoles(s, guildID)
+	if err != nil {
+		return nil, err
+	}
+
+	member, err := s.State.Member(guildID, userID)
+	if err != nil {
+		member, err = s.GuildMember(guildID, userID)
+		if err != nil {
+			return nil, fmt.Errorf("get member %s: %w", userID, err)
+		}
+	}
+
+	return RolesAbove(roles, TopRolePosition(roles, member), roleIDs), nil
+}
+
+// guildRoles returns the guild's roles, from state when available.
+func guildRoles(s *discordgo.Session, guildID string) ([]*discordgo.Role, error) {
+	if guild, err := s.State.Guild(guildID); err == nil && len(guild.Roles) > 0 {
+		return guild.Roles, nil
+	}
+
+	roles, err := s.GuildRoles(guildID)
+	if err != nil {
+		return nil, fmt.Errorf("get roles for guild %s: %w", guildID, err)
+	}
+	return roles, nil
+}
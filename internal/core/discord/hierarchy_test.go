@@ -0,0 +1,34 @@
+package discord_test
+
+import (
+	"testing"
+
+	"welcomebot/internal/core/discord"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func TestRolesAbove(t *testing.T) {
+	roles := []*discordgo.Role{
+		{ID: "everyone", Position: 0},
+		{ID: "member", Position: 2},
+		{ID: "bot", Position: 5},
+		{ID: "booster", Position: 3, Managed: true},
+		{ID: "staff", Position: 8},
+	}
+	member := &discordgo.Member{Roles: []string{"member", "bot"}}
+
+	top := discord.TopRolePosition(roles, member)
+	if top != 5 {
+		t.Fatalf("expected top position 5, got %d", top)
+	}
+
+	blocked := discord.RolesAbove(roles, top, []string{"member", "bot", "booster", "staff", "", "deleted"})
+	var ids []string
+	for _, role := range blocked {
+		ids = append(ids, role.ID)
+	}
+	if len(ids) != 3 || ids[0] != "bot" || ids[1] != "booster" || ids[2] != "staff" {
+		t.Errorf("expected bot, booster and staff to be blocked, got %v", ids)
+	}
+}
@@ -0,0 +1,55 @@
+package discord
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/gorilla/websocket"
+)
+
+// PrivilegedIntents must be switched on for the application in the
+// Discord Developer Portal before the gateway accepts them.
+const PrivilegedIntents = discordgo.IntentsGuildMembers |
+	discordgo.IntentsGuildPresences |
+	discordgo.IntentsMessageContent
+
+// closeDisallowedIntents is the gateway close code for an identify that
+// asked for privileged intents the application was not granted.
+const closeDisallowedIntents = 4014
+
+var privilegedIntentNames = []struct {
+	intent discordgo.Intent
+	name   string
+}{
+	{discordgo.IntentsGuildMembers, "Server Members Intent"},
+	{discordgo.IntentsGuildPresences, "Presence Intent"},
+	{discordgo.IntentsMessageContent, "Message Content Intent"},
+}
+
+// PrivilegedIntentNames lists the privileged intents in intents by the
+// names the Developer Portal uses.
+func PrivilegedIntentNames(intents discordgo.Intent) []string {
+	var names []string
+	for _, p := range privilegedIntentNames {
+		if intents&p.intent != 0 {
+			names = append(names, p.name)
+		}
+	}
+	return names
+}
+
+// CheckIntents turns the gateway's "disallowed intents" close from
+// Session.Open into an error saying which toggles to enable. Other errors
+// are returned unchanged.
+func CheckIntents(err error, intents discordgo.Intent) error {
+	if err == nil || !websocket.IsCloseError(err, closeDisallowedIntents) {
+		return err
+	}
+	names := PrivilegedIntentNames(intents)
+	if len(names) == 0 {
+		return fmt.Errorf("discord rejected gateway intents: %w", err)
+	}
+	return fmt.Errorf("discord rejected privileged intents; enable %s under Bot > Privileged Gateway Intents in the Developer Portal: %w",
+		strings.Join(names, ", "), err)
+}
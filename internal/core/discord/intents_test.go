@@ -0,0 +1,41 @@
+package discord
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/gorilla/websocket"
+)
+
+func TestCheckIntents(t *testing.T) {
+	intents := discordgo.IntentsGuilds | discordgo.IntentsGuildMembers
+
+	rejected := &websocket.CloseError{Code: closeDisallowedIntents, Text: "Disallowed intent(s)."}
+	err := CheckIntents(rejected, intents)
+	if err == nil || !strings.Contains(err.Error(), "Server Members Intent") {
+		t.Errorf("expected the missing intent to be named, got %v", err)
+	}
+	if !errors.Is(err, rejected) {
+		t.Error("expected the gateway error to be wrapped")
+	}
+
+	other := errors.New("dial tcp: timeout")
+	if got := CheckIntents(other, intents); got != other {
+		t.Errorf("expected other errors unchanged, got %v", got)
+	}
+	if CheckIntents(nil, intents) != nil {
+		t.Error("expected nil for a successful open")
+	}
+}
+
+func TestPrivilegedIntentNames(t *testing.T) {
+	if names := PrivilegedIntentNames(discordgo.IntentsGuilds | discordgo.IntentsGuildVoiceStates); len(names) != 0 {
+		t.Errorf("expected no privileged intents, got %v", names)
+	}
+	names := PrivilegedIntentNames(discordgo.IntentsMessageContent | discordgo.IntentsGuildMembers)
+	if len(names) != 2 {
+		t.Errorf("expected two privileged intents, got %v", names)
+	}
+}
@@ -0,0 +1,21 @@
+package discord
+
+// unsetMention stands in for a setting that has no role or channel yet.
+const unsetMention = "-"
+
+// RoleMention formats a role for an embed, or "-" when roleID is empty.
+func RoleMention(roleID string) string {
+	if roleID == "" {
+		return unsetMention
+	}
+	return "<@&" + roleID + ">"
+}
+
+// ChannelMention formats a channel for an embed, or "-" when channelID is
+// empty.
+func ChannelMention(channelID string) string {
+	if channelID == "" {
+		return unsetMention
+	}
+	return "<#" + channelID + ">"
+}
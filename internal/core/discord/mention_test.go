@@ -0,0 +1,19 @@
+package discord_test
+
+import (
+	"testing"
+
+	"welcomebot/internal/core/discord"
+)
+
+func TestMentions(t *testing.T) {
+	if got := discord.RoleMention("123"); got != "<@&123>" {
+		t.Errorf("RoleMention = %q", got)
+	}
+	if got := discord.ChannelMention("456"); got != "<#456>" {
+		t.Errorf("ChannelMention = %q", got)
+	}
+	if discord.RoleMention("") != "-" || discord.ChannelMention("") != "-" {
+		t.Error("expected unset IDs to show as -")
+	}
+}
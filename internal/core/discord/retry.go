@@ -0,0 +1,114 @@
+package discord
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"welcomebot/internal/core/logger"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// RetryPolicy bounds how often and how long Retry waits between attempts.
+type RetryPolicy struct {
+	MaxAttempts  int           // Total attempts, including the first
+	BaseDelay    time.Duration // Backoff before the second attempt, doubled after each retry
+	MaxDelay     time.Duration // Upper bound for any single wait, including Retry-After
+	ServerErrors bool          // Also retry server errors (5xx); only safe for requests that can be repeated
+}
+
+// DefaultRetryPolicy retries a few times over roughly ten seconds. It suits
+// requests that can be repeated, like role changes.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts:  4,
+	BaseDelay:    500 * time.Millisecond,
+	MaxDelay:     5 * time.Second,
+	ServerErrors: true,
+}
+
+// SendRetryPolicy is DefaultRetryPolicy for message sends. A server error
+// may come after the message was posted, so retrying it could post twice.
+var SendRetryPolicy = RetryPolicy{
+	MaxAttempts: 4,
+	BaseDelay:   500 * time.Millisecond,
+	MaxDelay:    5 * time.Second,
+}
+
+// Retry calls fn until it succeeds or fails with an error that is not a
+// rate limit (429), a request that never reached Discord, or, if the
+// policy allows, a server error (5xx). Discord's Retry-After is honored
+// when present; otherwise the wait backs off exponentially. The last error
+// is returned once the policy's attempts are used up or ctx is done.
+func Retry(ctx context.Context, log logger.Logger, op string, policy RetryPolicy, fn func() error) error {
+	delay := policy.BaseDelay
+
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err == nil {
+			return nil
+		}
+
+		wait, retryable := retryAfter(err, policy.ServerErrors)
+		if !retryable || attempt >= policy.MaxAttempts {
+			return err
+		}
+		if wait <= 0 {
+			wait = delay
+			delay *= 2
+		}
+		if wait > policy.MaxDelay {
+			wait = policy.MaxDelay
+		}
+
+		log.Warn("discord request failed, retrying",
+			"op", op,
+			"attempt", attempt,
+			"wait", wait.String(),
+			"error", err,
+		)
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+	}
+}
+
+// retryAfter reports whether err is worth retrying and how long Discord
+// asked us to wait (zero when it did not say).
+func retryAfter(err error, serverErrors bool) (time.Duration, bool) {
+	var rateLimit *discordgo.RateLimitError
+	if errors.As(err, &rateLimit) {
+		return rateLimit.RetryAfter, true
+	}
+
+	// A request that couldn't connect was never sent
+	var opErr *net.OpError
+	if errors.As(err, &opErr) && opErr.Op == "dial" {
+		return 0, true
+	}
+
+	var restErr *discordgo.RESTError
+	if !errors.As(err, &restErr) || restErr.Response == nil {
+		return 0, false
+	}
+
+	status := restErr.Response.StatusCode
+	if status != http.StatusTooManyRequests && (!serverErrors || status < http.StatusInternalServerError) {
+		return 0, false
+	}
+
+	// Retry-After is given in (possibly fractional) seconds
+	seconds, parseErr := strconv.ParseFloat(restErr.Response.Header.Get("Retry-After"), 64)
+	if parseErr != nil {
+		return 0, true
+	}
+	return time.Duration(seconds * float64(time.Second)), true
+}
@@ -0,0 +1,153 @@
+package discord_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"welcomebot/internal/core/discord"
+	"welcomebot/internal/fakes"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+var testPolicy = discord.RetryPolicy{
+	MaxAttempts:  3,
+	BaseDelay:    time.Millisecond,
+	MaxDelay:     10 * time.Millisecond,
+	ServerErrors: true,
+}
+
+func restError(status int, retryAfter string) error {
+	resp := &http.Response{StatusCode: status, Header: http.Header{}}
+	if retryAfter != "" {
+		resp.Header.Set("Retry-After", retryAfter)
+	}
+	return &discordgo.RESTError{Response: resp}
+}
+
+func TestRetry(t *testing.T) {
+	tests := []struct {
+		name      string
+		errs      []error
+		wantCalls int
+		wantErr   bool
+	}{
+		{"success", nil, 1, false},
+		{"rate limited then success", []error{restError(http.StatusTooManyRequests, "0.001")}, 2, false},
+		{"server errors then success", []error{restError(http.StatusBadGateway, ""), restError(http.StatusInternalServerError, "")}, 3, false},
+		{"gives up after max attempts", []error{
+			restError(http.StatusTooManyRequests, ""),
+			restError(http.StatusTooManyRequests, ""),
+			restError(http.StatusTooManyRequests, ""),
+			restError(http.StatusTooManyRequests, ""),
+		}, 3, true},
+		{"client error is not retried", []error{restError(http.StatusForbidden, "")}, 1, true},
+		{"non-REST error is not retried", []error{errors.New("boom")}, 1, true},
+		{"connection refused is retried", []error{&url.Error{Op: "Post", Err: &net.OpError{Op: "dial", Err: errors.New("connection refused")}}}, 2, false},
+		{"connection reset is not retried", []error{&url.Error{Op: "Post", Err: &net.OpError{Op: "read", Err: errors.New("connection reset")}}}, 1, true},
+		{"rate limit error", []error{&discordgo.RateLimitError{RateLimit: &discordgo.RateLimit{
+			TooManyRequests: &discordgo.TooManyRequests{RetryAfter: time.Millisecond},
+		}}}, 2, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			err := discord.Retry(context.Background(), fakes.Logger{}, "test", testPolicy, func() error {
+				calls++
+				if calls <= len(tt.errs) {
+					return tt.errs[calls-1]
+				}
+				return nil
+			})
+
+			if calls != tt.wantCalls {
+				t.Errorf("expected %d calls, got %d", tt.wantCalls, calls)
+			}
+			if (err != nil) != tt.wantErr {
+				t.Errorf("unexpected error result: %v", err)
+			}
+		})
+	}
+}
+
+func TestRetryWithoutServerErrors(t *testing.T) {
+	policy := testPolicy
+	policy.ServerErrors = false
+
+	calls := 0
+	err := discord.Retry(context.Background(), fakes.Logger{}, "test", policy, func() error {
+		calls++
+		if calls == 1 {
+			return restError(http.StatusTooManyRequests, "0.001")
+		}
+		return restError(http.StatusBadGateway, "")
+	})
+	if err == nil || calls != 2 {
+		t.Errorf("expected the rate limit retried and the server error returned, got %d calls and %v", calls, err)
+	}
+}
+
+func TestRetryCapsRetryAfter(t *testing.T) {
+	start := time.Now()
+	calls := 0
+	_ = discord.Retry(context.Background(), fakes.Logger{}, "test", testPolicy, func() error {
+		calls++
+		if calls == 1 {
+			return restError(http.StatusTooManyRequests, "60")
+		}
+		return nil
+	})
+
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("expected Retry-After to be capped by MaxDelay, waited %v", elapsed)
+	}
+}
+
+func TestRetryStopsWhenContextDone(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	calls := 0
+	err := discord.Retry(ctx, fakes.Logger{}, "test", testPolicy, func() error {
+		calls++
+		return restError(http.StatusTooManyRequests, "")
+	})
+	if err == nil || calls != 1 {
+		t.Errorf("expected a single attempt and an error, got %d calls and %v", calls, err)
+	}
+}
+
+func TestIsNotFound(t *testing.T) {
+	if !discord.IsNotFound(restError(http.StatusNotFound, "")) {
+		t.Error("expected 404 to be not found")
+	}
+	if discord.IsNotFound(restError(http.StatusForbidden, "")) {
+		t.Error("expected 403 not to be not found")
+	}
+	if discord.IsNotFound(errors.New("network down")) {
+		t.Error("expected non-REST error not to be not found")
+	}
+}
+
+func TestIsCategoryFull(t *testing.T) {
+	full := &discordgo.RESTError{
+		Response:     &http.Response{StatusCode: http.StatusBadRequest},
+		ResponseBody: []byte(`{"code": 50035, "errors": {"parent_id": {"_errors": [{"code": "CHANNEL_PARENT_MAX_CHANNELS", "message": "Maximum number of channels in category reached (50)"}]}}, "message": "Invalid Form Body"}`),
+	}
+	if !discord.IsCategoryFull(fmt.Errorf("create channel: %w", full)) {
+		t.Error("expected a wrapped max channels error to be category full")
+	}
+	if discord.IsCategoryFull(restError(http.StatusBadRequest, "")) {
+		t.Error("expected another 400 not to be category full")
+	}
+	if discord.IsCategoryFull(errors.New("network down")) {
+		t.Error("expected non-REST error not to be category full")
+	}
+}
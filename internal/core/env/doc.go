@@ -0,0 +1,7 @@
+// Package env reads the environment variables the bots are configured
+// with.
+//
+// The master, the slaves and the doctor command share one set of
+// connection settings, so they read them through here and can't drift
+// apart on defaults or list syntax.
+package env
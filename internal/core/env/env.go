@@ -0,0 +1,54 @@
+package env
+
+import (
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Get returns the variable, or defaultValue when it is unset or empty.
+func Get(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
+
+// Int reads a positive integer, falling back to defaultValue when the
+// variable is unset or invalid.
+func Int(key string, defaultValue int) int {
+	value, err := strconv.Atoi(os.Getenv(key))
+	if err != nil || value <= 0 {
+		return defaultValue
+	}
+	return value
+}
+
+// List reads a comma-separated list, e.g. "host1:26379, host2:26379".
+// Entries are trimmed and empty ones dropped; an unset variable is nil.
+func List(key string) []string {
+	var parts []string
+	for _, part := range strings.Split(os.Getenv(key), ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return parts
+}
+
+// ReplicaHosts returns the Postgres read replicas from
+// POSTGRES_REPLICA_HOSTS, e.g. "replica-1,replica-2:6432".
+func ReplicaHosts() []string {
+	return List("POSTGRES_REPLICA_HOSTS")
+}
+
+// ClusterAddrs returns the Redis Cluster seed nodes. When set they take
+// precedence over Sentinel and REDIS_ADDR.
+func ClusterAddrs() []string {
+	return List("REDIS_CLUSTER_ADDRS")
+}
+
+// SentinelAddrs returns the Redis Sentinel addresses.
+func SentinelAddrs() []string {
+	return List("REDIS_SENTINEL_ADDRS")
+}
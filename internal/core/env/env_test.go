@@ -0,0 +1,37 @@
+package env
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestGet(t *testing.T) {
+	t.Setenv("WELCOMEBOT_TEST_VALUE", "")
+	if got := Get("WELCOMEBOT_TEST_VALUE", "fallback"); got != "fallback" {
+		t.Errorf("expected the default for an empty variable, got %q", got)
+	}
+	t.Setenv("WELCOMEBOT_TEST_VALUE", "set")
+	if got := Get("WELCOMEBOT_TEST_VALUE", "fallback"); got != "set" {
+		t.Errorf("expected the variable, got %q", got)
+	}
+}
+
+func TestInt(t *testing.T) {
+	for value, want := range map[string]int{"": 5, "12": 12, "0": 5, "-3": 5, "ten": 5} {
+		t.Setenv("WELCOMEBOT_TEST_INT", value)
+		if got := Int("WELCOMEBOT_TEST_INT", 5); got != want {
+			t.Errorf("Int(%q) = %d, want %d", value, got, want)
+		}
+	}
+}
+
+func TestList(t *testing.T) {
+	t.Setenv("WELCOMEBOT_TEST_LIST", " redis-0:6379, ,redis-1:6379 ,")
+	if got := List("WELCOMEBOT_TEST_LIST"); !slices.Equal(got, []string{"redis-0:6379", "redis-1:6379"}) {
+		t.Errorf("unexpected list %q", got)
+	}
+	t.Setenv("WELCOMEBOT_TEST_LIST", "")
+	if got := List("WELCOMEBOT_TEST_LIST"); got != nil {
+		t.Errorf("expected nil for an unset list, got %q", got)
+	}
+}
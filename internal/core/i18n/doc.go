@@ -1,6 +1,6 @@
 // Package i18n provides internationalization support.
 //
 // It handles per-guild language preferences and translation
-// management with fallback support.
+// management with fallback support, plus locale-aware formatting
+// of numbers, dates and durations.
 package i18n
-
@@ -0,0 +1,94 @@
+package i18n
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FormatNumber formats an integer with thousands separators (1,234,567).
+// English and Japanese both group by three with commas, as does the
+// fallback for unknown locales.
+func FormatNumber(locale string, n int64) string {
+	digits := strconv.FormatInt(n, 10)
+	sign := ""
+	if n < 0 {
+		sign, digits = "-", digits[1:]
+	}
+
+	var b strings.Builder
+	b.WriteString(sign)
+	for i, d := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(d)
+	}
+	return b.String()
+}
+
+// FormatDate formats a date and time in the locale's usual order, e.g.
+// "Jan 2, 2006 15:04" or "2006年1月2日 15:04". Unknown locales use the
+// English order.
+func FormatDate(locale string, t time.Time) string {
+	switch locale {
+	case "ja":
+		return fmt.Sprintf("%d年%d月%d日 %s", t.Year(), t.Month(), t.Day(), t.Format("15:04"))
+	default:
+		return t.Format("Jan 2, 2006 15:04")
+	}
+}
+
+// weekdayNames are the short day names for a locale, Sunday first.
+var weekdayNames = map[string][7]string{
+	"en": {"Sun", "Mon", "Tue", "Wed", "Thu", "Fri", "Sat"},
+	"ja": {"日", "月", "火", "水", "木", "金", "土"},
+}
+
+// FormatWeekday returns the locale's short name for a day of the week,
+// "Mon" or "月". Unknown locales use English.
+func FormatWeekday(locale string, d time.Weekday) string {
+	names, ok := weekdayNames[locale]
+	if !ok {
+		names = weekdayNames[defaultLanguage]
+	}
+	return names[d]
+}
+
+// durationUnits are the day/hour/minute/second suffixes for a locale.
+var durationUnits = map[string][4]string{
+	"en": {"d", "h", "m", "s"},
+	"ja": {"日", "時間", "分", "秒"},
+}
+
+// FormatDuration formats a duration to the second, leaving out zero parts:
+// "1d 2h 5s" or "1日2時間5秒". Durations under a second format as zero
+// seconds; negative durations format as their magnitude.
+func FormatDuration(locale string, d time.Duration) string {
+	units, ok := durationUnits[locale]
+	if !ok {
+		units = durationUnits[defaultLanguage]
+	}
+	sep := " "
+	if locale == "ja" {
+		sep = ""
+	}
+
+	if d < 0 {
+		d = -d
+	}
+	total := int64(d / time.Second)
+	parts := []int64{total / 86400, total % 86400 / 3600, total % 3600 / 60, total % 60}
+
+	var out []string
+	for i, v := range parts {
+		if v > 0 {
+			out = append(out, fmt.Sprintf("%d%s", v, units[i]))
+		}
+	}
+	if len(out) == 0 {
+		return "0" + units[3]
+	}
+	return strings.Join(out, sep)
+}
@@ -0,0 +1,91 @@
+package i18n
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFormatNumber(t *testing.T) {
+	tests := []struct {
+		n    int64
+		want string
+	}{
+		{0, "0"},
+		{999, "999"},
+		{1000, "1,000"},
+		{1234567, "1,234,567"},
+		{-45000, "-45,000"},
+	}
+
+	for _, tt := range tests {
+		for _, locale := range []string{"en", "ja"} {
+			if got := FormatNumber(locale, tt.n); got != tt.want {
+				t.Errorf("FormatNumber(%q, %d) = %q, want %q", locale, tt.n, got, tt.want)
+			}
+		}
+	}
+}
+
+func TestFormatDate(t *testing.T) {
+	at := time.Date(2026, time.March, 7, 9, 5, 0, 0, time.UTC)
+
+	tests := []struct {
+		locale string
+		want   string
+	}{
+		{"en", "Mar 7, 2026 09:05"},
+		{"ja", "2026年3月7日 09:05"},
+		{"fr", "Mar 7, 2026 09:05"}, // unknown locales use English
+	}
+
+	for _, tt := range tests {
+		if got := FormatDate(tt.locale, at); got != tt.want {
+			t.Errorf("FormatDate(%q) = %q, want %q", tt.locale, got, tt.want)
+		}
+	}
+}
+
+func TestFormatWeekday(t *testing.T) {
+	tests := []struct {
+		locale string
+		day    time.Weekday
+		want   string
+	}{
+		{"en", time.Monday, "Mon"},
+		{"ja", time.Monday, "月"},
+		{"ja", time.Sunday, "日"},
+		{"fr", time.Saturday, "Sat"}, // unknown locales use English
+	}
+
+	for _, tt := range tests {
+		if got := FormatWeekday(tt.locale, tt.day); got != tt.want {
+			t.Errorf("FormatWeekday(%q, %v) = %q, want %q", tt.locale, tt.day, got, tt.want)
+		}
+	}
+}
+
+func TestFormatDuration(t *testing.T) {
+	tests := []struct {
+		name   string
+		locale string
+		d      time.Duration
+		want   string
+	}{
+		{"minutes only", "en", 30 * time.Minute, "30m"},
+		{"hours and minutes", "en", 2*time.Hour + 15*time.Minute, "2h 15m"},
+		{"days hours minutes", "en", 3*24*time.Hour + 5*time.Hour + 20*time.Minute, "3d 5h 20m"},
+		{"zero parts skipped", "en", 24*time.Hour + 5*time.Second, "1d 5s"},
+		{"sub-second", "en", 300 * time.Millisecond, "0s"},
+		{"japanese", "ja", 2*time.Hour + 3*time.Minute + 4*time.Second, "2時間3分4秒"},
+		{"japanese zero", "ja", 0, "0秒"},
+		{"unknown locale", "de", 90 * time.Second, "1m 30s"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FormatDuration(tt.locale, tt.d); got != tt.want {
+				t.Errorf("FormatDuration(%q, %v) = %q, want %q", tt.locale, tt.d, got, tt.want)
+			}
+		})
+	}
+}
@@ -28,6 +28,22 @@ type I18n interface {
 	AvailableLanguages() []string
 }
 
+// localeKey is the context key of WithLocale.
+type localeKey struct{}
+
+// WithLocale returns a context in which T, TWithArgs and GetGuildLanguage
+// use locale instead of the guild's language, e.g. the language a member
+// picked for their own session.
+func WithLocale(ctx context.Context, locale string) context.Context {
+	return context.WithValue(ctx, localeKey{}, locale)
+}
+
+// LocaleFrom returns the locale set with WithLocale, or "" if none is.
+func LocaleFrom(ctx context.Context) string {
+	locale, _ := ctx.Value(localeKey{}).(string)
+	return locale
+}
+
 // Dependencies contains i18n dependencies.
 type Dependencies struct {
 	DB    database.Client
@@ -104,7 +120,7 @@ func (m *manager) T(ctx context.Context, guildID, key string) string {
 
 // TWithArgs translates a key with variable substitution.
 func (m *manager) TWithArgs(ctx context.Context, guildID, key string, args map[string]string) string {
-	lang, err := m.getGuildLang(ctx, guildID)
+	lang, err := m.contextLang(ctx, guildID)
 	if err != nil {
 		lang = defaultLanguage
 	}
@@ -149,9 +165,10 @@ func (m *manager) SetGuildLanguage(ctx context.Context, guildID, langCode string
 	return nil
 }
 
-// GetGuildLanguage gets the language for a guild.
+// GetGuildLanguage gets the language for a guild, or the one set on ctx
+// with WithLocale.
 func (m *manager) GetGuildLanguage(ctx context.Context, guildID string) (string, error) {
-	lang, err := m.getGuildLang(ctx, guildID)
+	lang, err := m.contextLang(ctx, guildID)
 	if err != nil {
 		return defaultLanguage, nil
 	}
@@ -176,6 +193,20 @@ func (m *manager) AvailableLanguages() []string {
 	return langs
 }
 
+// contextLang returns the locale set on ctx if it is one with
+// translations, otherwise the guild's language.
+func (m *manager) contextLang(ctx context.Context, guildID string) (string, error) {
+	if locale := LocaleFrom(ctx); locale != "" {
+		m.mu.RLock()
+		_, ok := m.translations[locale]
+		m.mu.RUnlock()
+		if ok {
+			return locale, nil
+		}
+	}
+	return m.getGuildLang(ctx, guildID)
+}
+
 // getGuildLang retrieves guild language from cache or DB.
 func (m *manager) getGuildLang(ctx context.Context, guildID string) (string, error) {
 	cacheKey := cacheKeyPrefix + guildID
@@ -188,15 +219,15 @@ func (m *manager) getGuildLang(ctx context.Context, guildID string) (string, err
 
 	// Query database
 	query := "SELECT language_code FROM guild_languages WHERE guild_id = $1"
-	row := m.db.QueryRow(ctx, query, guildID)
+	row := m.db.QueryRow(database.ReadFromReplica(ctx), query, guildID)
 
 	var langCode string
 	if err := row.Scan(&langCode); err != nil {
 		return "", fmt.Errorf("query guild language: %w", err)
 	}
 
-	// Cache indefinitely
-	m.cache.Set(ctx, cacheKey, langCode, 0)
+	// Cache until it may be stale, in case it came from a lagging replica
+	m.cache.Set(ctx, cacheKey, langCode, database.ReplicaCacheTTL)
 
 	return langCode, nil
 }
@@ -1,11 +1,13 @@
 package i18n_test
 
 import (
+	"context"
 	"os"
 	"path/filepath"
 	"testing"
 
 	"welcomebot/internal/core/i18n"
+	"welcomebot/internal/fakes"
 )
 
 func TestNew(t *testing.T) {
@@ -48,3 +50,31 @@ func TestAvailableLanguages(t *testing.T) {
 	}
 }
 
+func TestWithLocale(t *testing.T) {
+	tmpDir := t.TempDir()
+	os.WriteFile(filepath.Join(tmpDir, "en.json"), []byte(`{"test": {"key": "value"}}`), 0644)
+	os.WriteFile(filepath.Join(tmpDir, "ja.json"), []byte(`{"test": {"key": "値"}}`), 0644)
+
+	ctx := context.Background()
+	cache := fakes.NewCache()
+	cache.Set(ctx, "welcomebot:i18n:guild:guild-1", "en", 0)
+	mgr, err := i18n.New(i18n.Dependencies{DB: fakes.NewDB(), Cache: cache}, tmpDir)
+	if err != nil {
+		t.Fatalf("failed to create manager: %v", err)
+	}
+
+	if got := mgr.T(i18n.WithLocale(ctx, "ja"), "guild-1", "test.key"); got != "値" {
+		t.Errorf("ja session: got %q", got)
+	}
+	if got, _ := mgr.GetGuildLanguage(i18n.WithLocale(ctx, "ja"), "guild-1"); got != "ja" {
+		t.Errorf("ja session language: got %q", got)
+	}
+
+	// A locale without translations falls back to the guild's language
+	if got := mgr.T(i18n.WithLocale(ctx, "fr"), "guild-1", "test.key"); got != "value" {
+		t.Errorf("fr session: got %q", got)
+	}
+	if got := mgr.T(ctx, "guild-1", "test.key"); got != "value" {
+		t.Errorf("guild language: got %q", got)
+	}
+}
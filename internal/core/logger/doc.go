@@ -2,5 +2,8 @@
 //
 // It wraps logrus to provide a clean, testable logging interface
 // with structured fields and multiple log levels.
+//
+// Named child loggers can run at their own level, set per name through
+// Config.Levels, so one feature can log at debug while the rest stay quiet.
 package logger
 
@@ -0,0 +1,55 @@
+package logger
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestParseLevels(t *testing.T) {
+	levels, err := ParseLevels(" welcome=debug, queue=warn,")
+	if err != nil {
+		t.Fatalf("ParseLevels: %v", err)
+	}
+	if len(levels) != 2 || levels["welcome"] != "debug" || levels["queue"] != "warn" {
+		t.Errorf("unexpected levels: %v", levels)
+	}
+
+	for _, bad := range []string{"welcome", "=debug", "welcome=loud"} {
+		if _, err := ParseLevels(bad); err == nil {
+			t.Errorf("expected error for %q", bad)
+		}
+	}
+}
+
+func TestNamedLevelOverrides(t *testing.T) {
+	log, err := New(Config{
+		Level:  "info",
+		Format: "text",
+		Levels: map[string]string{"welcome": "debug", "queue": "warn"},
+	})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	var buf bytes.Buffer
+	log.(*logrusLogger).logger.SetOutput(&buf)
+
+	log.Debug("root debug")
+	log.Info("root info")
+	log.Named("welcome").WithField("guild_id", "1").Debug("welcome debug")
+	log.Named("queue").Info("queue info")
+	log.Named("queue").Warn("queue warn")
+	log.Named("menu").Debug("menu debug")
+
+	out := buf.String()
+	for _, want := range []string{"root info", "welcome debug", "logger=welcome", "queue warn"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in output:\n%s", want, out)
+		}
+	}
+	for _, unwanted := range []string{"root debug", "queue info", "menu debug"} {
+		if strings.Contains(out, unwanted) {
+			t.Errorf("expected %q to be filtered:\n%s", unwanted, out)
+		}
+	}
+}
@@ -1,6 +1,9 @@
 package logger
 
 import (
+	"fmt"
+	"strings"
+
 	"github.com/sirupsen/logrus"
 )
 
@@ -12,12 +15,17 @@ type Logger interface {
 	Error(msg string, fields ...interface{})
 	WithField(key string, value interface{}) Logger
 	WithFields(fields map[string]interface{}) Logger
+
+	// Named returns a child logger tagged with name, logging at the level
+	// configured for that name if there is one.
+	Named(name string) Logger
 }
 
 // Config contains logger configuration.
 type Config struct {
-	Level  string // "debug", "info", "warn", "error"
-	Format string // "json", "text"
+	Level  string            // "debug", "info", "warn", "error"
+	Format string            // "json", "text"
+	Levels map[string]string // Per-name overrides for Named loggers
 }
 
 // DefaultConfig returns the default logger configuration.
@@ -28,10 +36,37 @@ func DefaultConfig() Config {
 	}
 }
 
+// ParseLevels parses per-name level overrides such as
+// "welcome=debug,queue=warn".
+func ParseLevels(s string) (map[string]string, error) {
+	levels := make(map[string]string)
+	for _, pair := range strings.Split(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		name, level, ok := strings.Cut(pair, "=")
+		name, level = strings.TrimSpace(name), strings.TrimSpace(level)
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid log level override %q", pair)
+		}
+		if _, err := logrus.ParseLevel(level); err != nil {
+			return nil, fmt.Errorf("invalid log level for %s: %w", name, err)
+		}
+		levels[name] = level
+	}
+	return levels, nil
+}
+
 // logrusLogger wraps logrus.Logger to implement our Logger interface.
+// The underlying logger runs at the most verbose configured level; each
+// wrapper drops messages below its own level.
 type logrusLogger struct {
-	logger *logrus.Logger
-	entry  *logrus.Entry
+	logger    *logrus.Logger
+	entry     *logrus.Entry
+	level     logrus.Level
+	baseLevel logrus.Level
+	overrides map[string]logrus.Level
 }
 
 // New creates a new logger with the given configuration.
@@ -43,7 +78,17 @@ func New(cfg Config) (Logger, error) {
 	if err != nil {
 		return nil, err
 	}
-	log.SetLevel(level)
+	overrides := make(map[string]logrus.Level, len(cfg.Levels))
+	verbosest := level
+	for name, value := range cfg.Levels {
+		override, err := logrus.ParseLevel(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid log level for %s: %w", name, err)
+		}
+		overrides[name] = override
+		verbosest = max(verbosest, override)
+	}
+	log.SetLevel(verbosest)
 
 	// Set format
 	if cfg.Format == "json" {
@@ -53,44 +98,74 @@ func New(cfg Config) (Logger, error) {
 	}
 
 	return &logrusLogger{
-		logger: log,
-		entry:  logrus.NewEntry(log),
+		logger:    log,
+		entry:     logrus.NewEntry(log),
+		level:     level,
+		baseLevel: level,
+		overrides: overrides,
 	}, nil
 }
 
 // Debug logs a debug message with structured fields.
 func (l *logrusLogger) Debug(msg string, fields ...interface{}) {
+	if l.level < logrus.DebugLevel {
+		return
+	}
 	l.entry.WithFields(parseFields(fields...)).Debug(msg)
 }
 
 // Info logs an info message with structured fields.
 func (l *logrusLogger) Info(msg string, fields ...interface{}) {
+	if l.level < logrus.InfoLevel {
+		return
+	}
 	l.entry.WithFields(parseFields(fields...)).Info(msg)
 }
 
 // Warn logs a warning message with structured fields.
 func (l *logrusLogger) Warn(msg string, fields ...interface{}) {
+	if l.level < logrus.WarnLevel {
+		return
+	}
 	l.entry.WithFields(parseFields(fields...)).Warn(msg)
 }
 
 // Error logs an error message with structured fields.
 func (l *logrusLogger) Error(msg string, fields ...interface{}) {
+	if l.level < logrus.ErrorLevel {
+		return
+	}
 	l.entry.WithFields(parseFields(fields...)).Error(msg)
 }
 
 // WithField returns a new logger with the added field.
 func (l *logrusLogger) WithField(key string, value interface{}) Logger {
-	return &logrusLogger{
-		logger: l.logger,
-		entry:  l.entry.WithField(key, value),
-	}
+	return l.with(l.entry.WithField(key, value), l.level)
 }
 
 // WithFields returns a new logger with the added fields.
 func (l *logrusLogger) WithFields(fields map[string]interface{}) Logger {
+	return l.with(l.entry.WithFields(fields), l.level)
+}
+
+// Named returns a logger with a "logger" field of name. Names without an
+// override log at the global level.
+func (l *logrusLogger) Named(name string) Logger {
+	level, ok := l.overrides[name]
+	if !ok {
+		level = l.baseLevel
+	}
+	return l.with(l.entry.WithField("logger", name), level)
+}
+
+// with returns a copy of l that logs entry at level.
+func (l *logrusLogger) with(entry *logrus.Entry, level logrus.Level) *logrusLogger {
 	return &logrusLogger{
-		logger: l.logger,
-		entry:  l.entry.WithFields(fields),
+		logger:    l.logger,
+		entry:     entry,
+		level:     level,
+		baseLevel: l.baseLevel,
+		overrides: l.overrides,
 	}
 }
 
@@ -28,6 +28,15 @@ func TestNew(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "invalid override",
+			config: logger.Config{
+				Level:  "info",
+				Format: "json",
+				Levels: map[string]string{"welcome": "loud"},
+			},
+			wantErr: true,
+		},
 		{
 			name: "invalid level",
 			config: logger.Config{
@@ -0,0 +1,7 @@
+// Package metrics exposes bot metrics in the Prometheus text format.
+//
+// Values are read when the endpoint is scraped: each gauge or counter is a
+// function that is called on every request, so there is nothing to keep in
+// sync. Histograms are the exception: they count observations as they
+// happen and are read from those counts.
+package metrics
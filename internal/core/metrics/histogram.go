@@ -0,0 +1,101 @@
+package metrics
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// maxHistogramSeries caps the label values a histogram keeps. Later values
+// are counted under "other", so a label fed from user input can't grow
+// without bound.
+const maxHistogramSeries = 100
+
+// Histogram counts observations into cumulative buckets, with one set of
+// buckets per value of a single label. Unlike gauges and counters it keeps
+// its own state: callers Observe values as they happen and scrapes read
+// the totals.
+type Histogram struct {
+	label   string
+	buckets []float64 // Upper bounds, ascending; +Inf is implied
+
+	mu     sync.Mutex
+	series map[string]*histogramSeries
+}
+
+type histogramSeries struct {
+	counts []uint64 // Per bucket, not cumulative
+	sum    float64
+	count  uint64
+}
+
+// NewHistogram creates a histogram whose series are told apart by label,
+// with the given bucket upper bounds.
+func NewHistogram(label string, buckets []float64) *Histogram {
+	sorted := append([]float64(nil), buckets...)
+	sort.Float64s(sorted)
+	return &Histogram{label: label, buckets: sorted, series: make(map[string]*histogramSeries)}
+}
+
+// Observe records value in the series for labelValue.
+func (h *Histogram) Observe(labelValue string, value float64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	s, ok := h.series[labelValue]
+	if !ok {
+		if len(h.series) >= maxHistogramSeries {
+			labelValue = "other"
+			s = h.series[labelValue]
+		}
+		if s == nil {
+			s = &histogramSeries{counts: make([]uint64, len(h.buckets))}
+			h.series[labelValue] = s
+		}
+	}
+
+	for n, bound := range h.buckets {
+		if value <= bound {
+			s.counts[n]++
+			break
+		}
+	}
+	s.sum += value
+	s.count++
+}
+
+// Histogram registers h under name.
+func (r *Registry) Histogram(name, help string, h *Histogram) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.metrics[name] = &metric{help: help, kind: "histogram", histogram: h}
+}
+
+// write renders every series of h as the _bucket, _sum and _count lines of
+// metric name, sorted by label value.
+func (h *Histogram) write(b *strings.Builder, name string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	values := make([]string, 0, len(h.series))
+	for value := range h.series {
+		values = append(values, value)
+	}
+	sort.Strings(values)
+
+	for _, value := range values {
+		s := h.series[value]
+		var cumulative uint64
+		for n, bound := range h.buckets {
+			cumulative += s.counts[n]
+			labels := Labels{h.label: value, "le": strconv.FormatFloat(bound, 'g', -1, 64)}
+			fmt.Fprintf(b, "%s_bucket%s %d\n", name, formatLabels(labels), cumulative)
+		}
+		fmt.Fprintf(b, "%s_bucket%s %d\n", name, formatLabels(Labels{h.label: value, "le": "+Inf"}), s.count)
+		fmt.Fprintf(b, "%s_sum%s %g\n", name, formatLabels(Labels{h.label: value}), s.sum)
+		fmt.Fprintf(b, "%s_count%s %d\n", name, formatLabels(Labels{h.label: value}), s.count)
+	}
+}
@@ -0,0 +1,136 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// collectTimeout bounds how long one scrape may spend reading values.
+const collectTimeout = 5 * time.Second
+
+// GaugeFunc reads a gauge's current value.
+type GaugeFunc func(ctx context.Context) (float64, error)
+
+// Labels are the label names and values of one series.
+type Labels map[string]string
+
+// series is one labelled value of a metric.
+type series struct {
+	labels Labels
+	read   GaugeFunc
+}
+
+// metric is a named gauge, counter or histogram and all of its series.
+type metric struct {
+	help      string
+	kind      string // "gauge", "counter" or "histogram"
+	series    []series
+	histogram *Histogram // Histograms only
+}
+
+// Registry holds the metrics served by Handler.
+type Registry struct {
+	mu      sync.Mutex
+	metrics map[string]*metric
+}
+
+// NewRegistry creates an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{metrics: make(map[string]*metric)}
+}
+
+// Gauge registers a series of the named gauge. Registering the same name
+// again with different labels adds another series under the first help text.
+func (r *Registry) Gauge(name, help string, labels Labels, read GaugeFunc) {
+	r.add(name, help, "gauge", labels, read)
+}
+
+// Counter registers a series of the named counter. read must return a
+// value that only grows, such as a running total kept elsewhere.
+func (r *Registry) Counter(name, help string, labels Labels, read GaugeFunc) {
+	r.add(name, help, "counter", labels, read)
+}
+
+func (r *Registry) add(name, help, kind string, labels Labels, read GaugeFunc) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	m, ok := r.metrics[name]
+	if !ok {
+		m = &metric{help: help, kind: kind}
+		r.metrics[name] = m
+	}
+	m.series = append(m.series, series{labels: labels, read: read})
+}
+
+// Handler serves the registered metrics. A series that fails to read is
+// left out of the response rather than reported as zero.
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		ctx, cancel := context.WithTimeout(req.Context(), collectTimeout)
+		defer cancel()
+
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		_, _ = w.Write([]byte(r.render(ctx)))
+	})
+}
+
+// render formats every metric, sorted by name.
+func (r *Registry) render(ctx context.Context) string {
+	r.mu.Lock()
+	names := make([]string, 0, len(r.metrics))
+	for name := range r.metrics {
+		names = append(names, name)
+	}
+	metrics := make(map[string]metric, len(r.metrics))
+	for name, m := range r.metrics {
+		metrics[name] = metric{help: m.help, kind: m.kind, series: append([]series(nil), m.series...), histogram: m.histogram}
+	}
+	r.mu.Unlock()
+
+	sort.Strings(names)
+
+	var b strings.Builder
+	for _, name := range names {
+		m := metrics[name]
+		fmt.Fprintf(&b, "# HELP %s %s\n", name, m.help)
+		fmt.Fprintf(&b, "# TYPE %s %s\n", name, m.kind)
+		if m.histogram != nil {
+			m.histogram.write(&b, name)
+			continue
+		}
+		for _, s := range m.series {
+			value, err := s.read(ctx)
+			if err != nil {
+				continue
+			}
+			fmt.Fprintf(&b, "%s%s %g\n", name, formatLabels(s.labels), value)
+		}
+	}
+	return b.String()
+}
+
+// formatLabels renders labels as {a="1",b="2"}, sorted by name.
+func formatLabels(labels Labels) string {
+	if len(labels) == 0 {
+		return ""
+	}
+
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, len(keys))
+	for n, k := range keys {
+		value := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(labels[k])
+		pairs[n] = fmt.Sprintf(`%s="%s"`, k, value)
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
@@ -0,0 +1,102 @@
+package metrics_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"welcomebot/internal/core/metrics"
+)
+
+func TestHandler(t *testing.T) {
+	registry := metrics.NewRegistry()
+	registry.Gauge("welcomebot_queue_depth", "Tasks waiting.", metrics.Labels{"queue": "tasks"},
+		func(ctx context.Context) (float64, error) { return 12, nil })
+	registry.Gauge("welcomebot_queue_depth", "ignored", metrics.Labels{"queue": "events"},
+		func(ctx context.Context) (float64, error) { return 0, errors.New("redis down") })
+	registry.Gauge("welcomebot_a_gauge", "Sorted first.", nil,
+		func(ctx context.Context) (float64, error) { return 1.5, nil })
+
+	rec := httptest.NewRecorder()
+	registry.Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	body, _ := io.ReadAll(rec.Body)
+
+	want := `# HELP welcomebot_a_gauge Sorted first.
+# TYPE welcomebot_a_gauge gauge
+welcomebot_a_gauge 1.5
+# HELP welcomebot_queue_depth Tasks waiting.
+# TYPE welcomebot_queue_depth gauge
+welcomebot_queue_depth{queue="tasks"} 12
+`
+	if string(body) != want {
+		t.Errorf("unexpected output:\n%s", body)
+	}
+}
+
+func TestHandler_Counter(t *testing.T) {
+	registry := metrics.NewRegistry()
+	registry.Counter("welcomebot_cache_hits_total", "Cache hits.", metrics.Labels{"prefix": "session"},
+		func(ctx context.Context) (float64, error) { return 3, nil })
+
+	rec := httptest.NewRecorder()
+	registry.Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	body, _ := io.ReadAll(rec.Body)
+
+	want := `# HELP welcomebot_cache_hits_total Cache hits.
+# TYPE welcomebot_cache_hits_total counter
+welcomebot_cache_hits_total{prefix="session"} 3
+`
+	if string(body) != want {
+		t.Errorf("unexpected output:\n%s", body)
+	}
+}
+
+func TestHandler_Histogram(t *testing.T) {
+	h := metrics.NewHistogram("handler", []float64{1, 0.5})
+	h.Observe("ping", 0.2)
+	h.Observe("ping", 0.7)
+	h.Observe("ping", 4)
+
+	registry := metrics.NewRegistry()
+	registry.Histogram("welcomebot_latency_seconds", "Latency.", h)
+
+	rec := httptest.NewRecorder()
+	registry.Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	body, _ := io.ReadAll(rec.Body)
+
+	want := `# HELP welcomebot_latency_seconds Latency.
+# TYPE welcomebot_latency_seconds histogram
+welcomebot_latency_seconds_bucket{handler="ping",le="0.5"} 1
+welcomebot_latency_seconds_bucket{handler="ping",le="1"} 2
+welcomebot_latency_seconds_bucket{handler="ping",le="+Inf"} 3
+welcomebot_latency_seconds_sum{handler="ping"} 4.9
+welcomebot_latency_seconds_count{handler="ping"} 3
+`
+	if string(body) != want {
+		t.Errorf("unexpected output:\n%s", body)
+	}
+}
+
+func TestHistogram_SeriesCap(t *testing.T) {
+	h := metrics.NewHistogram("handler", []float64{1})
+	for n := range 150 {
+		h.Observe(fmt.Sprintf("handler-%d", n), 0.1)
+	}
+
+	registry := metrics.NewRegistry()
+	registry.Histogram("latency", "Latency.", h)
+	rec := httptest.NewRecorder()
+	registry.Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	body, _ := io.ReadAll(rec.Body)
+
+	if n := strings.Count(string(body), "latency_count{"); n != 101 {
+		t.Errorf("expected 100 series and other, got %d", n)
+	}
+	if !strings.Contains(string(body), `latency_count{handler="other"} 50`) {
+		t.Errorf("expected the overflow counted as other:\n%s", body)
+	}
+}
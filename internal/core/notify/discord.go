@@ -0,0 +1,78 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// Channel posts notifications as embeds in a Discord channel, pinging
+// MentionRoleID when one is set.
+type Channel struct {
+	Session   *discordgo.Session
+	ChannelID string
+}
+
+// Notify posts n in the channel.
+func (c *Channel) Notify(ctx context.Context, n Notification) error {
+	msg := &discordgo.MessageSend{
+		Embeds: []*discordgo.MessageEmbed{embed(n)},
+		AllowedMentions: &discordgo.MessageAllowedMentions{
+			Roles: []string{},
+		},
+	}
+	if n.MentionRoleID != "" {
+		msg.Content = fmt.Sprintf("<@&%s>", n.MentionRoleID)
+		msg.AllowedMentions.Roles = []string{n.MentionRoleID}
+	}
+
+	if _, err := c.Session.ChannelMessageSendComplex(c.ChannelID, msg, discordgo.WithContext(ctx)); err != nil {
+		return fmt.Errorf("send to channel %s: %w", c.ChannelID, err)
+	}
+	return nil
+}
+
+// DM sends notifications as embeds in a direct message to one user. The
+// user must share a guild with the bot and accept DMs from it.
+type DM struct {
+	Session *discordgo.Session
+	UserID  string
+}
+
+// Notify sends n to the user.
+func (d *DM) Notify(ctx context.Context, n Notification) error {
+	channel, err := d.Session.UserChannelCreate(d.UserID, discordgo.WithContext(ctx))
+	if err != nil {
+		return fmt.Errorf("open DM with %s: %w", d.UserID, err)
+	}
+
+	_, err = d.Session.ChannelMessageSendComplex(channel.ID, &discordgo.MessageSend{
+		Embeds:          []*discordgo.MessageEmbed{embed(n)},
+		AllowedMentions: &discordgo.MessageAllowedMentions{},
+	}, discordgo.WithContext(ctx))
+	if err != nil {
+		return fmt.Errorf("send DM to %s: %w", d.UserID, err)
+	}
+	return nil
+}
+
+// embed lays out a notification for Discord.
+func embed(n Notification) *discordgo.MessageEmbed {
+	e := &discordgo.MessageEmbed{
+		Title:       n.Title,
+		Description: n.Message,
+		Color:       n.Color,
+	}
+	for _, field := range n.Fields {
+		e.Fields = append(e.Fields, &discordgo.MessageEmbedField{Name: field.Name, Value: field.Value, Inline: field.Inline})
+	}
+	if n.Footer != "" {
+		e.Footer = &discordgo.MessageEmbedFooter{Text: n.Footer}
+	}
+	if !n.Time.IsZero() {
+		e.Timestamp = n.Time.Format(time.RFC3339)
+	}
+	return e
+}
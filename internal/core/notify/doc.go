@@ -0,0 +1,9 @@
+// Package notify sends structured notifications to configurable sinks.
+//
+// Features describe what happened as a Notification and hand it to a
+// Notifier. Sinks decide how it looks where it lands: an embed in a
+// Discord channel or DM, or JSON posted to an HTTP webhook. A Router sends
+// each kind of notification to the sinks configured for it, so where
+// failure alerts or completion events go is deployment configuration
+// rather than feature code.
+package notify
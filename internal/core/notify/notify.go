@@ -0,0 +1,162 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// Kind names what a notification is about. Sinks are configured per kind.
+type Kind string
+
+// Notification kinds.
+const (
+	KindOnboardingFailed    Kind = "onboarding_failed"
+	KindOnboardingCompleted Kind = "onboarding_completed"
+)
+
+// Kinds lists every notification kind, for validating configuration.
+var Kinds = []Kind{KindOnboardingFailed, KindOnboardingCompleted}
+
+// Field is one labelled value of a notification.
+type Field struct {
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	Inline bool   `json:"-"` // Discord layout only
+}
+
+// Notification is one event to tell someone about. Title, Message and
+// Fields are already localized; sinks only lay them out.
+type Notification struct {
+	Kind    Kind      `json:"kind"`
+	GuildID string    `json:"guild_id"`
+	UserID  string    `json:"user_id,omitempty"`
+	Title   string    `json:"title"`
+	Message string    `json:"message,omitempty"`
+	Fields  []Field   `json:"fields,omitempty"`
+	Footer  string    `json:"footer,omitempty"`
+	Time    time.Time `json:"time"`
+
+	// Color is the Discord embed color
+	Color int `json:"-"`
+
+	// MentionRoleID is pinged by channel sinks; other sinks ignore it
+	MentionRoleID string `json:"-"`
+}
+
+// Notifier delivers notifications to one destination, or to several.
+type Notifier interface {
+	Notify(ctx context.Context, n Notification) error
+}
+
+// Router sends each notification to the sinks added for its kind and
+// guild. A kind without sinks is dropped. The zero value has no sinks; a
+// nil *Router drops everything.
+type Router struct {
+	sinks map[Kind][]route
+}
+
+// route is a sink and the guild it is for.
+type route struct {
+	guildID string // Empty for every guild
+	sink    Notifier
+}
+
+// Add sends notifications of kind from every guild to sink as well.
+func (r *Router) Add(kind Kind, sink Notifier) {
+	r.AddForGuild(kind, "", sink)
+}
+
+// AddForGuild sends notifications of kind from guildID to sink as well.
+// An empty guildID means every guild.
+func (r *Router) AddForGuild(kind Kind, guildID string, sink Notifier) {
+	if r.sinks == nil {
+		r.sinks = make(map[Kind][]route)
+	}
+	r.sinks[kind] = append(r.sinks[kind], route{guildID: guildID, sink: sink})
+}
+
+// Has reports whether any sink receives notifications of kind.
+func (r *Router) Has(kind Kind) bool {
+	return r != nil && len(r.sinks[kind]) > 0
+}
+
+// Notify sends n to every sink for its kind and guild. One failing sink
+// doesn't stop the others; their errors are joined.
+func (r *Router) Notify(ctx context.Context, n Notification) error {
+	if r == nil {
+		return nil
+	}
+	var errs []error
+	for _, route := range r.sinks[n.Kind] {
+		if route.guildID != "" && route.guildID != n.GuildID {
+			continue
+		}
+		if err := route.sink.Notify(ctx, n); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// ParseRoutes parses sink configuration such as
+// "onboarding_failed=webhook:https://example.com/hook,onboarding_completed@456=channel:123".
+// Each entry is a kind, optionally "@<guild ID>" to only route that guild's
+// notifications, "=", and a sink: "channel:<channel ID>", "dm:<user ID>"
+// or "webhook:<URL>". A kind may be listed more than once to reach several
+// sinks. An empty spec gives a Router without sinks.
+func ParseRoutes(spec string, session *discordgo.Session, client *http.Client) (*Router, error) {
+	router := &Router{}
+
+	// Errors leave out the sink, since webhook URLs often hold a secret
+	for n, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		kind, sink, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, fmt.Errorf("notify route %d: want kind=sink", n+1)
+		}
+		kind, guildID, scoped := strings.Cut(kind, "@")
+		if scoped && guildID == "" {
+			return nil, fmt.Errorf("notify route %d: %s@ has no guild", n+1, kind)
+		}
+		if !slices.Contains(Kinds, Kind(kind)) {
+			return nil, fmt.Errorf("notify route %d: unknown kind %q", n+1, kind)
+		}
+
+		notifier, err := parseSink(sink, session, client)
+		if err != nil {
+			return nil, fmt.Errorf("notify route %d (%s): %w", n+1, kind, err)
+		}
+		router.AddForGuild(Kind(kind), guildID, notifier)
+	}
+	return router, nil
+}
+
+func parseSink(sink string, session *discordgo.Session, client *http.Client) (Notifier, error) {
+	scheme, target, _ := strings.Cut(sink, ":")
+	if target == "" {
+		return nil, fmt.Errorf("%s sink has no target", scheme)
+	}
+	switch scheme {
+	case "channel":
+		return &Channel{Session: session, ChannelID: target}, nil
+	case "dm":
+		return &DM{Session: session, UserID: target}, nil
+	case "webhook":
+		if !strings.HasPrefix(target, "https://") && !strings.HasPrefix(target, "http://") {
+			return nil, fmt.Errorf("webhook target is not an http(s) URL")
+		}
+		return &Webhook{URL: target, Client: client}, nil
+	}
+	return nil, fmt.Errorf("unknown sink %q, want channel, dm or webhook", scheme)
+}
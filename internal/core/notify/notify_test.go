@@ -0,0 +1,155 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"welcomebot/internal/fakes"
+)
+
+// recorder is a Notifier that keeps what it is sent.
+type recorder struct {
+	got []Notification
+	err error
+}
+
+func (r *recorder) Notify(ctx context.Context, n Notification) error {
+	r.got = append(r.got, n)
+	return r.err
+}
+
+func TestRouter(t *testing.T) {
+	failures, failing, completions := &recorder{}, &recorder{err: errors.New("down")}, &recorder{}
+	router := &Router{}
+	router.Add(KindOnboardingFailed, failing)
+	router.Add(KindOnboardingFailed, failures)
+	router.Add(KindOnboardingCompleted, completions)
+
+	err := router.Notify(context.Background(), Notification{Kind: KindOnboardingFailed})
+	if err == nil || !strings.Contains(err.Error(), "down") {
+		t.Errorf("expected the failing sink's error, got %v", err)
+	}
+	if len(failures.got) != 1 || len(completions.got) != 0 {
+		t.Errorf("expected only the failure sinks to be sent to, got %d and %d", len(failures.got), len(completions.got))
+	}
+
+	// A guild's own sink only gets that guild's notifications
+	guild1 := &recorder{}
+	router.AddForGuild(KindOnboardingCompleted, "guild-1", guild1)
+	_ = router.Notify(context.Background(), Notification{Kind: KindOnboardingCompleted, GuildID: "guild-2"})
+	_ = router.Notify(context.Background(), Notification{Kind: KindOnboardingCompleted, GuildID: "guild-1"})
+	if len(guild1.got) != 1 || guild1.got[0].GuildID != "guild-1" || len(completions.got) != 2 {
+		t.Errorf("expected guild-1's sink to get only its notification, got %d (every guild: %d)", len(guild1.got), len(completions.got))
+	}
+
+	var none *Router
+	if err := none.Notify(context.Background(), Notification{Kind: KindOnboardingFailed}); err != nil || none.Has(KindOnboardingFailed) {
+		t.Errorf("expected a nil router to drop notifications, got %v", err)
+	}
+}
+
+func TestParseRoutes(t *testing.T) {
+	router, err := ParseRoutes(" onboarding_failed=channel:123, onboarding_failed=webhook:https://example.com/hook?key=x,"+
+		"onboarding_completed=dm:456, onboarding_completed@789=channel:321 ", nil, nil)
+	if err != nil {
+		t.Fatalf("ParseRoutes: %v", err)
+	}
+	if n := len(router.sinks[KindOnboardingFailed]); n != 2 {
+		t.Errorf("expected two failure sinks, got %d", n)
+	}
+	if hook, ok := router.sinks[KindOnboardingFailed][1].sink.(*Webhook); !ok || hook.URL != "https://example.com/hook?key=x" {
+		t.Errorf("expected the webhook URL kept whole, got %+v", router.sinks[KindOnboardingFailed][1])
+	}
+	if dm, ok := router.sinks[KindOnboardingCompleted][0].sink.(*DM); !ok || dm.UserID != "456" {
+		t.Errorf("expected a DM sink, got %+v", router.sinks[KindOnboardingCompleted][0])
+	}
+	if scoped := router.sinks[KindOnboardingCompleted][1]; scoped.guildID != "789" || router.sinks[KindOnboardingCompleted][0].guildID != "" {
+		t.Errorf("expected only the second completion sink scoped to guild 789, got %+v", router.sinks[KindOnboardingCompleted])
+	}
+
+	for _, spec := range []string{
+		"onboarding_failed",
+		"onboarding_started=channel:1",
+		"onboarding_failed=email:staff@example.com",
+		"onboarding_failed=channel:",
+		"onboarding_failed@=channel:1",
+		"onboarding_failed=webhook:ftp://secret@example.com",
+	} {
+		_, err := ParseRoutes(spec, nil, nil)
+		if err == nil {
+			t.Errorf("%q: expected an error", spec)
+		} else if strings.Contains(err.Error(), "secret") {
+			t.Errorf("%q: error leaks the sink: %v", spec, err)
+		}
+	}
+}
+
+func TestWebhook(t *testing.T) {
+	var got map[string]interface{}
+	status := http.StatusNoContent
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("expected JSON, got %q", r.Header.Get("Content-Type"))
+		}
+		_ = json.NewDecoder(r.Body).Decode(&got)
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	hook := &Webhook{URL: server.URL}
+	n := Notification{
+		Kind: KindOnboardingFailed, GuildID: "guild-1", UserID: "user-1", Title: "Failed",
+		Fields: []Field{{Name: "Step", Value: "step3", Inline: true}}, Color: 0xff0000,
+		MentionRoleID: "role-staff", Time: time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC),
+	}
+	if err := hook.Notify(context.Background(), n); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+	if got["kind"] != "onboarding_failed" || got["user_id"] != "user-1" || got["time"] != "2026-10-16T12:00:00Z" {
+		t.Errorf("unexpected body %v", got)
+	}
+	if _, ok := got["color"]; ok {
+		t.Errorf("expected Discord-only fields left out, got %v", got)
+	}
+
+	status = http.StatusInternalServerError
+	if err := hook.Notify(context.Background(), n); err == nil {
+		t.Error("expected an error status to fail")
+	}
+}
+
+func TestChannel(t *testing.T) {
+	d := fakes.NewDiscord()
+	channel := &Channel{Session: d.Session(), ChannelID: "channel-1"}
+
+	n := Notification{Kind: KindOnboardingFailed, Title: "Failed", Message: "<@user-1> got stuck", MentionRoleID: "role-staff"}
+	if err := channel.Notify(context.Background(), n); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+
+	req := d.Requests()[0]
+	if req.Path != "channels/channel-1/messages" {
+		t.Fatalf("expected a message in channel-1, got %s", req.Path)
+	}
+	var msg struct {
+		Content         string `json:"content"`
+		AllowedMentions struct {
+			Roles []string `json:"roles"`
+		} `json:"allowed_mentions"`
+		Embeds []struct {
+			Title string `json:"title"`
+		} `json:"embeds"`
+	}
+	if err := json.Unmarshal(req.Body, &msg); err != nil {
+		t.Fatal(err)
+	}
+	if msg.Content != "<@&role-staff>" || len(msg.AllowedMentions.Roles) != 1 || msg.Embeds[0].Title != "Failed" {
+		t.Errorf("unexpected message %s", req.Body)
+	}
+}
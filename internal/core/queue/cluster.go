@@ -0,0 +1,49 @@
+package queue
+
+import (
+	"fmt"
+	"strings"
+)
+
+// queueKeys returns every key a queue touches. In cluster mode they must
+// all hash to one slot so that blocking pops and multi-key commands work;
+// give them a shared hash tag such as "{welcomebot:tasks}" when adding keys.
+func queueKeys(queueKey, processingKey string) []string {
+	if processingKey == "" {
+		return []string{queueKey}
+	}
+	return []string{queueKey, processingKey}
+}
+
+// processingKeyFor returns the consumer's processing list. It is tagged
+// with the queue key's hash tag so BLMOVE between them stays in one
+// cluster slot: an untagged key hashes as a whole, the same as a tag
+// holding that whole key.
+func processingKeyFor(queueKey, consumerID string) string {
+	return "{" + hashTag(queueKey) + "}:processing:" + consumerID
+}
+
+// hashTag returns the part of key Redis Cluster hashes: the text inside
+// the first non-empty {...}, or the whole key if there is none.
+func hashTag(key string) string {
+	start := strings.IndexByte(key, '{')
+	if start < 0 {
+		return key
+	}
+	end := strings.IndexByte(key[start+1:], '}')
+	if end <= 0 {
+		return key
+	}
+	return key[start+1 : start+1+end]
+}
+
+// checkSameSlot returns an error unless all keys share a hash tag, which
+// guarantees they land in the same cluster slot.
+func checkSameSlot(keys ...string) error {
+	for _, key := range keys[1:] {
+		if hashTag(key) != hashTag(keys[0]) {
+			return fmt.Errorf("keys %q and %q are in different cluster slots; use a shared hash tag", keys[0], key)
+		}
+	}
+	return nil
+}
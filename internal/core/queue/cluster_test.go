@@ -0,0 +1,52 @@
+package queue
+
+import "testing"
+
+func TestHashTag(t *testing.T) {
+	tests := []struct {
+		key  string
+		want string
+	}{
+		{"welcomebot:tasks", "welcomebot:tasks"},
+		{"{welcomebot:tasks}", "welcomebot:tasks"},
+		{"{welcomebot:tasks}:processing", "welcomebot:tasks"},
+		{"a{b}{c}", "b"},
+		{"a{}b", "a{}b"},
+		{"a{b", "a{b"},
+	}
+
+	for _, tt := range tests {
+		if got := hashTag(tt.key); got != tt.want {
+			t.Errorf("hashTag(%q) = %q, want %q", tt.key, got, tt.want)
+		}
+	}
+}
+
+func TestCheckSameSlot(t *testing.T) {
+	if err := checkSameSlot("{q}", "{q}:processing"); err != nil {
+		t.Errorf("expected shared hash tag to pass, got %v", err)
+	}
+	if err := checkSameSlot("q", "q:processing"); err == nil {
+		t.Error("expected untagged keys to fail")
+	}
+}
+
+func TestNew_ClusterRejectsDB(t *testing.T) {
+	_, err := New(Config{ClusterAddrs: []string{"localhost:7000"}, RedisDB: 1})
+	if err == nil {
+		t.Error("expected error for non-zero db in cluster mode")
+	}
+}
+
+func TestProcessingKeyFor_SharesSlot(t *testing.T) {
+	for _, queueKey := range []string{"welcomebot:tasks", "{welcomebot}:tasks"} {
+		processing := processingKeyFor(queueKey, "slave1")
+		if err := checkSameSlot(queueKey, processing); err != nil {
+			t.Errorf("processing key %q for %q: %v", processing, queueKey, err)
+		}
+	}
+
+	if got := processingKeyFor("welcomebot:tasks", "slave1"); got != "{welcomebot:tasks}:processing:slave1" {
+		t.Errorf("unexpected processing key %q", got)
+	}
+}
@@ -0,0 +1,88 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestWaitForStopsOnCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	pops := 0
+	pop := func(ctx context.Context, wait time.Duration) (string, bool, error) {
+		if wait > 10*time.Millisecond {
+			t.Errorf("expected waits of at most the step, got %v", wait)
+		}
+		if pops++; pops == 3 {
+			cancel()
+		}
+		time.Sleep(wait)
+		return "", false, nil
+	}
+
+	start := time.Now()
+	_, ok, err := waitFor(ctx, 30*time.Second, 10*time.Millisecond, pop)
+	if ok || !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected the context's error, got ok=%v err=%v", ok, err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("expected to stop promptly, took %v", elapsed)
+	}
+	if pops != 3 {
+		t.Errorf("expected no pop after cancel, got %d", pops)
+	}
+}
+
+func TestWaitForTimeout(t *testing.T) {
+	var total time.Duration
+	pop := func(ctx context.Context, wait time.Duration) (string, bool, error) {
+		total += wait
+		time.Sleep(wait)
+		return "", false, nil
+	}
+
+	_, ok, err := waitFor(context.Background(), 35*time.Millisecond, 10*time.Millisecond, pop)
+	if ok || err != nil {
+		t.Fatalf("expected a plain timeout, got ok=%v err=%v", ok, err)
+	}
+	if total > 35*time.Millisecond {
+		t.Errorf("expected the waits to fit the timeout, got %v", total)
+	}
+}
+
+func TestBlockWait(t *testing.T) {
+	tests := map[time.Duration]time.Duration{
+		time.Millisecond:        time.Second,
+		time.Second:             time.Second,
+		1500 * time.Millisecond: 2 * time.Second,
+		5 * time.Second:         5 * time.Second,
+	}
+	for wait, want := range tests {
+		if got := blockWait(wait); got != want {
+			t.Errorf("blockWait(%v) = %v, want %v", wait, got, want)
+		}
+	}
+}
+
+func TestWaitForTask(t *testing.T) {
+	pops := 0
+	pop := func(ctx context.Context, wait time.Duration) (string, bool, error) {
+		if pops++; pops == 2 {
+			return `{"id":"task-1"}`, true, nil
+		}
+		return "", false, nil
+	}
+
+	data, ok, err := waitFor(context.Background(), 0, time.Millisecond, pop)
+	if !ok || err != nil || data != `{"id":"task-1"}` {
+		t.Fatalf("expected the task, got %q ok=%v err=%v", data, ok, err)
+	}
+
+	failing := func(ctx context.Context, wait time.Duration) (string, bool, error) {
+		return "", false, errors.New("connection refused")
+	}
+	if _, _, err := waitFor(context.Background(), time.Second, time.Millisecond, failing); err == nil {
+		t.Error("expected the pop error")
+	}
+}
@@ -2,5 +2,26 @@
 //
 // It enables async task processing between master and worker bots
 // using Redis as the backing store.
+//
+// A client with a ConsumerID delivers at least once: Dequeue moves each
+// task into the consumer's processing list with BLMOVE, Ack removes it
+// once handled, and Recover requeues whatever a crashed run left behind.
+// Recovery counts as a retry, so a task that keeps crashing its consumer
+// ends up on DeadLetterQueueKey.
+//
+// Besides the shared task queue each worker consumes its own list,
+// SlaveQueueKey, so master can address a single worker with EnqueueTo.
+// Tasks that can never be processed are moved to DeadLetterQueueKey
+// instead of being retried, and Retry moves a task there once it has
+// failed MaxRetries times.
+//
+// With Config.MaxDepth set, Enqueue refuses tasks with ErrFull once that
+// many are waiting, so callers can turn users away instead of queueing
+// work that would start long after they gave up.
+//
+// The client connects to Redis Cluster when ClusterAddrs is set, otherwise
+// to Sentinel when SentinelAddrs and MasterName are set, otherwise to the
+// single instance at RedisAddr. In cluster mode every key a queue uses must
+// share a hash tag; New checks this before connecting.
 package queue
 
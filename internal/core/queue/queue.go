@@ -3,6 +3,7 @@ package queue
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 
@@ -11,12 +12,61 @@ import (
 
 const (
 	defaultQueueKey = "welcomebot:tasks"
+
+	// MasterQueueKey is the list workers use to report events back to master.
+	MasterQueueKey = "welcomebot:master:tasks"
+
+	// DeadLetterQueueKey holds tasks a worker could never process, such as
+	// ones with a malformed payload, for an operator to inspect.
+	DeadLetterQueueKey = "welcomebot:tasks_dead"
+
+	// MaxRetries is how often Retry requeues a failed task before moving
+	// it to DeadLetterQueueKey.
+	MaxRetries = 3
+
+	slaveQueuePrefix = "welcomebot:tasks:"
+
+	// dequeueBlockStep bounds each blocking pop in Dequeue, so a cancelled
+	// context is noticed within about a second.
+	dequeueBlockStep = time.Second
 )
 
+// ErrFull is returned by Enqueue when the queue already holds MaxDepth
+// tasks. The task is not added; callers should ask the user to try again
+// shortly rather than leave them waiting behind a backlog.
+var ErrFull = errors.New("queue is full")
+
+// SlaveQueueKey returns the list only the given worker consumes. Master
+// uses it for messages aimed at one worker.
+func SlaveQueueKey(slaveID string) string {
+	return slaveQueuePrefix + slaveID
+}
+
 // Client provides task queue operations.
 type Client interface {
+	// Enqueue adds a task to the queue. It returns ErrFull, without adding
+	// the task, when the queue already holds Config.MaxDepth tasks.
 	Enqueue(ctx context.Context, task Task) error
+	// EnqueueTo adds a task to another list on the same connection, e.g.
+	// a worker's SlaveQueueKey. MaxDepth doesn't apply.
+	EnqueueTo(ctx context.Context, queueKey string, task Task) error
+	// Dequeue takes the oldest task. With a ConsumerID the task is moved to
+	// the consumer's processing list and stays there until it is acked.
+	// It returns nil when timeout passes without a task, and ctx's error
+	// soon after ctx is done.
 	Dequeue(ctx context.Context, timeout time.Duration) (*Task, error)
+	// Ack removes a handled task from the processing list. It is a no-op
+	// without a ConsumerID.
+	Ack(ctx context.Context, task *Task) error
+	// Recover moves every task left in the processing list back to the
+	// front of the queue, counting a retry for each, and returns how many
+	// were moved. Tasks already retried MaxRetries times go to
+	// DeadLetterQueueKey instead. Call it on startup, before the first
+	// Dequeue.
+	Recover(ctx context.Context) (int, error)
+	// Depth returns how many tasks are waiting, not counting tasks being
+	// processed.
+	Depth(ctx context.Context) (int64, error)
 	Close() error
 }
 
@@ -28,11 +78,19 @@ type Task struct {
 	Payload   map[string]interface{} `json:"payload"`
 	CreatedAt time.Time              `json:"created_at"`
 	Retries   int                    `json:"retries"`
+
+	raw string // Encoded form as dequeued, needed to ack it
 }
 
 // Config contains queue configuration.
+//
+// The connection mode is chosen by precedence: ClusterAddrs, then
+// SentinelAddrs with MasterName, then RedisAddr.
 type Config struct {
-	// Sentinel Configuration (preferred)
+	// Cluster Configuration (takes precedence over Sentinel)
+	ClusterAddrs []string // Cluster seed nodes (e.g., ["redis-0:6379", "redis-1:6379"]); RedisDB must be 0
+
+	// Sentinel Configuration (preferred over a single Redis)
 	SentinelAddrs []string // Sentinel addresses (e.g., ["sentinel1:26379", "sentinel2:26379"])
 	MasterName    string   // Sentinel master name
 
@@ -42,6 +100,15 @@ type Config struct {
 	RedisPassword string
 	RedisDB       int
 	QueueKey      string
+
+	// ConsumerID names this consumer's processing list. Dequeued tasks are
+	// kept there until acked so a crash cannot lose them. Each process
+	// needs its own ID; leave empty for enqueue-only clients.
+	ConsumerID string
+
+	// MaxDepth is the most tasks Enqueue lets wait in the queue; past it
+	// Enqueue returns ErrFull. 0 means no limit.
+	MaxDepth int64
 }
 
 // DefaultConfig returns default queue configuration.
@@ -54,60 +121,122 @@ func DefaultConfig() Config {
 	}
 }
 
-// redisQueue implements Client using Redis lists.
+// redisQueue implements Client using Redis lists. Tasks are pushed on the
+// right and taken from the left.
 type redisQueue struct {
-	client   *redis.Client
-	queueKey string
+	client        redis.UniversalClient
+	queueKey      string
+	processingKey string // Empty without a ConsumerID
+	maxDepth      int64  // 0 means no limit
 }
 
+// pushIfRoom pushes ARGV[1] onto KEYS[1] unless the list already holds
+// ARGV[2] entries, and returns whether it did. Checking and pushing in one
+// script keeps concurrent enqueues from overshooting the limit.
+var pushIfRoom = redis.NewScript(`
+if redis.call("LLEN", KEYS[1]) >= tonumber(ARGV[2]) then
+	return 0
+end
+redis.call("RPUSH", KEYS[1], ARGV[1])
+return 1
+`)
+
 // New creates a new queue client with the given configuration.
-// Supports both Redis Sentinel (HA) and single Redis instance.
+// Supports Redis Cluster, Redis Sentinel (HA) and a single Redis instance.
 func New(cfg Config) (Client, error) {
-	var rdb *redis.Client
+	queueKey := cfg.QueueKey
+	if queueKey == "" {
+		queueKey = defaultQueueKey
+	}
+
+	processingKey := ""
+	if cfg.ConsumerID != "" {
+		processingKey = processingKeyFor(queueKey, cfg.ConsumerID)
+	}
+
+	rdb, err := newRedisClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if len(cfg.ClusterAddrs) > 0 {
+		if err := checkSameSlot(queueKeys(queueKey, processingKey)...); err != nil {
+			return nil, err
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := rdb.Ping(ctx).Err(); err != nil {
+		return nil, fmt.Errorf("ping redis: %w", err)
+	}
+
+	return &redisQueue{
+		client:        rdb,
+		queueKey:      queueKey,
+		processingKey: processingKey,
+		maxDepth:      cfg.MaxDepth,
+	}, nil
+}
 
-	// Use Sentinel if configured
-	if len(cfg.SentinelAddrs) > 0 && cfg.MasterName != "" {
-		rdb = redis.NewFailoverClient(&redis.FailoverOptions{
+// newRedisClient builds the client for the configured connection mode.
+func newRedisClient(cfg Config) (redis.UniversalClient, error) {
+	switch {
+	case len(cfg.ClusterAddrs) > 0:
+		// Cluster nodes only have database 0
+		if cfg.RedisDB != 0 {
+			return nil, fmt.Errorf("redis cluster does not support db %d", cfg.RedisDB)
+		}
+		return redis.NewClusterClient(&redis.ClusterOptions{
+			Addrs:    cfg.ClusterAddrs,
+			Password: cfg.RedisPassword,
+		}), nil
+	case len(cfg.SentinelAddrs) > 0 && cfg.MasterName != "":
+		return redis.NewFailoverClient(&redis.FailoverOptions{
 			MasterName:    cfg.MasterName,
 			SentinelAddrs: cfg.SentinelAddrs,
 			Password:      cfg.RedisPassword,
 			DB:            cfg.RedisDB,
-		})
-	} else {
-		// Fallback to single Redis
-		rdb = redis.NewClient(&redis.Options{
+		}), nil
+	default:
+		return redis.NewClient(&redis.Options{
 			Addr:     cfg.RedisAddr,
 			Password: cfg.RedisPassword,
 			DB:       cfg.RedisDB,
-		})
+		}), nil
 	}
+}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-
-	if err := rdb.Ping(ctx).Err(); err != nil {
-		return nil, fmt.Errorf("ping redis: %w", err)
+// Enqueue adds a task to the queue, or returns ErrFull if it holds
+// maxDepth tasks already.
+func (q *redisQueue) Enqueue(ctx context.Context, task Task) error {
+	if q.maxDepth <= 0 {
+		return q.EnqueueTo(ctx, q.queueKey, task)
 	}
 
-	queueKey := cfg.QueueKey
-	if queueKey == "" {
-		queueKey = defaultQueueKey
+	data, err := json.Marshal(task)
+	if err != nil {
+		return fmt.Errorf("marshal task: %w", err)
 	}
 
-	return &redisQueue{
-		client:   rdb,
-		queueKey: queueKey,
-	}, nil
+	pushed, err := pushIfRoom.Run(ctx, q.client, []string{q.queueKey}, data, q.maxDepth).Int()
+	if err != nil {
+		return fmt.Errorf("enqueue task %s: %w", task.ID, err)
+	}
+	if pushed == 0 {
+		return fmt.Errorf("enqueue task %s: %w (%d tasks waiting)", task.ID, ErrFull, q.maxDepth)
+	}
+	return nil
 }
 
-// Enqueue adds a task to the queue.
-func (q *redisQueue) Enqueue(ctx context.Context, task Task) error {
+// EnqueueTo adds a task to the named list.
+func (q *redisQueue) EnqueueTo(ctx context.Context, queueKey string, task Task) error {
 	data, err := json.Marshal(task)
 	if err != nil {
 		return fmt.Errorf("marshal task: %w", err)
 	}
 
-	if err := q.client.RPush(ctx, q.queueKey, data).Err(); err != nil {
+	if err := q.client.RPush(ctx, queueKey, data).Err(); err != nil {
 		return fmt.Errorf("enqueue task %s: %w", task.ID, err)
 	}
 
@@ -115,26 +244,220 @@ func (q *redisQueue) Enqueue(ctx context.Context, task Task) error {
 }
 
 // Dequeue removes and returns a task from the queue.
-// Blocks until a task is available or timeout is reached.
+// Blocks until a task is available, timeout is reached or ctx is done.
+// Redis blocks for whole seconds, so a timeout that isn't one may run up
+// to a second over.
 func (q *redisQueue) Dequeue(ctx context.Context, timeout time.Duration) (*Task, error) {
-	result, err := q.client.BLPop(ctx, timeout, q.queueKey).Result()
-	if err == redis.Nil {
+	data, ok, err := waitFor(ctx, timeout, dequeueBlockStep, q.pop)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
 		return nil, nil // No task available
 	}
-	if err != nil {
-		return nil, fmt.Errorf("dequeue task: %w", err)
+
+	var task Task
+	if err := json.Unmarshal([]byte(data), &task); err != nil {
+		// Leaving it in the processing list would requeue it forever
+		q.drop(ctx, data)
+		return nil, fmt.Errorf("unmarshal task: %w", err)
 	}
+	task.raw = data
+
+	return &task, nil
+}
 
+// pop blocks for up to wait for a task and returns it encoded. The bool is
+// false if none arrived in time.
+func (q *redisQueue) pop(ctx context.Context, wait time.Duration) (string, bool, error) {
+	wait = blockWait(wait)
+	if q.processingKey != "" {
+		result, err := q.client.BLMove(ctx, q.queueKey, q.processingKey, "LEFT", "RIGHT", wait).Result()
+		if err == redis.Nil {
+			return "", false, nil
+		}
+		if err != nil {
+			return "", false, fmt.Errorf("dequeue task: %w", err)
+		}
+		return result, true, nil
+	}
+
+	result, err := q.client.BLPop(ctx, wait, q.queueKey).Result()
+	if err == redis.Nil {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, fmt.Errorf("dequeue task: %w", err)
+	}
 	if len(result) < 2 {
-		return nil, fmt.Errorf("invalid blpop result")
+		return "", false, fmt.Errorf("invalid blpop result")
+	}
+	return result[1], true, nil
+}
+
+// blockWait rounds a blocking pop's wait up to whole seconds, the unit
+// BLPOP takes. The client would truncate it, and warn for any wait under a
+// second.
+func blockWait(wait time.Duration) time.Duration {
+	if rounded := wait.Truncate(time.Second); rounded < wait {
+		return rounded + time.Second
+	}
+	return wait
+}
+
+// waitFor calls pop with waits of at most step until it returns a task,
+// timeout has passed or ctx is done. The Redis client doesn't interrupt a
+// blocking pop when its context is cancelled, so short waits are what let
+// shutdown stop a consumer promptly. A timeout of zero or less waits until
+// a task arrives or ctx is done.
+func waitFor(ctx context.Context, timeout, step time.Duration, pop func(context.Context, time.Duration) (string, bool, error)) (string, bool, error) {
+	var deadline time.Time
+	if timeout > 0 {
+		deadline = time.Now().Add(timeout)
+	}
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return "", false, err
+		}
+
+		wait := step
+		if !deadline.IsZero() {
+			left := time.Until(deadline)
+			if left <= 0 {
+				return "", false, nil
+			}
+			wait = min(wait, left)
+		}
+
+		data, ok, err := pop(ctx, wait)
+		if err != nil {
+			if ctx.Err() != nil {
+				return "", false, ctx.Err()
+			}
+			return "", false, err
+		}
+		if ok {
+			return data, true, nil
+		}
+	}
+}
+
+// Ack removes a handled task from the processing list.
+func (q *redisQueue) Ack(ctx context.Context, task *Task) error {
+	if q.processingKey == "" || task.raw == "" {
+		return nil
+	}
+	if err := q.client.LRem(ctx, q.processingKey, 1, task.raw).Err(); err != nil {
+		return fmt.Errorf("ack task %s: %w", task.ID, err)
+	}
+	return nil
+}
+
+// Retry puts a task whose handling failed back on q with its Retries
+// counted, or on DeadLetterQueueKey once it has been retried MaxRetries
+// times. It reports whether the task was dead-lettered. The caller acks
+// the failed delivery once Retry succeeds.
+func Retry(ctx context.Context, q Client, task Task) (deadLettered bool, err error) {
+	task, ok := retried(task)
+	if !ok {
+		if err := q.EnqueueTo(ctx, DeadLetterQueueKey, task); err != nil {
+			return false, fmt.Errorf("dead-letter task %s: %w", task.ID, err)
+		}
+		return true, nil
+	}
+
+	if err := q.Enqueue(ctx, task); err != nil {
+		return false, fmt.Errorf("requeue task %s: %w", task.ID, err)
+	}
+	return false, nil
+}
+
+// retried returns task with one more retry counted. It reports false,
+// with task unchanged, once the task has been retried MaxRetries times and
+// belongs on DeadLetterQueueKey.
+func retried(task Task) (Task, bool) {
+	if task.Retries >= MaxRetries {
+		return task, false
 	}
+	task.Retries++
+	return task, true
+}
+
+// Recover requeues the tasks a previous run of this consumer took but
+// never acked. A task that crashes its consumer comes back here every
+// time, so each recovery counts as a retry. Tasks are handled from the
+// most recently taken one and pushed on the front of the queue, so they
+// run again first and in their original order. A task leaves the
+// processing list only after it is requeued, so a crash here cannot lose
+// it, though it may be requeued twice.
+func (q *redisQueue) Recover(ctx context.Context) (int, error) {
+	if q.processingKey == "" {
+		return 0, nil
+	}
+
+	moved := 0
+	for {
+		data, err := q.client.LIndex(ctx, q.processingKey, -1).Result()
+		if err == redis.Nil {
+			return moved, nil
+		}
+		if err != nil {
+			return moved, fmt.Errorf("recover tasks: %w", err)
+		}
+
+		if err := q.recoverTask(ctx, data); err != nil {
+			return moved, fmt.Errorf("recover tasks: %w", err)
+		}
+		if err := q.client.LRem(ctx, q.processingKey, 1, data).Err(); err != nil {
+			return moved, fmt.Errorf("recover tasks: %w", err)
+		}
+		moved++
+	}
+}
 
+// recoverTask puts an encoded task from the processing list back on the
+// front of the queue with a retry counted, or on DeadLetterQueueKey. An
+// entry that doesn't decode is not put anywhere, so Recover drops it the
+// way Dequeue would.
+func (q *redisQueue) recoverTask(ctx context.Context, data string) error {
 	var task Task
-	if err := json.Unmarshal([]byte(result[1]), &task); err != nil {
-		return nil, fmt.Errorf("unmarshal task: %w", err)
+	if err := json.Unmarshal([]byte(data), &task); err != nil {
+		return nil
 	}
 
-	return &task, nil
+	task, ok := retried(task)
+	encoded, err := json.Marshal(task)
+	if err != nil {
+		return fmt.Errorf("marshal task %s: %w", task.ID, err)
+	}
+	if !ok {
+		if err := q.client.RPush(ctx, DeadLetterQueueKey, encoded).Err(); err != nil {
+			return fmt.Errorf("dead-letter task %s: %w", task.ID, err)
+		}
+		return nil
+	}
+	if err := q.client.LPush(ctx, q.queueKey, encoded).Err(); err != nil {
+		return fmt.Errorf("requeue task %s: %w", task.ID, err)
+	}
+	return nil
+}
+
+// Depth returns the length of the task list.
+func (q *redisQueue) Depth(ctx context.Context) (int64, error) {
+	n, err := q.client.LLen(ctx, q.queueKey).Result()
+	if err != nil {
+		return 0, fmt.Errorf("queue depth: %w", err)
+	}
+	return n, nil
+}
+
+// drop removes an entry from the processing list without handling it.
+func (q *redisQueue) drop(ctx context.Context, data string) {
+	if q.processingKey == "" {
+		return
+	}
+	_ = q.client.LRem(ctx, q.processingKey, 1, data).Err()
 }
 
 // Close closes the queue client connection.
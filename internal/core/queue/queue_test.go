@@ -1,9 +1,11 @@
 package queue_test
 
 import (
+	"context"
 	"testing"
 
 	"welcomebot/internal/core/queue"
+	"welcomebot/internal/fakes"
 )
 
 func TestDefaultConfig(t *testing.T) {
@@ -34,3 +36,31 @@ func TestNew_InvalidConfig(t *testing.T) {
 	}
 }
 
+func TestRetry(t *testing.T) {
+	ctx := context.Background()
+	q := fakes.NewQueue()
+
+	task := queue.Task{ID: "task-1", Type: "onboarding_complete"}
+	for n := 1; n <= queue.MaxRetries; n++ {
+		deadLettered, err := queue.Retry(ctx, q, task)
+		if err != nil || deadLettered {
+			t.Fatalf("retry %d: expected a requeue, got dead-lettered=%v err=%v", n, deadLettered, err)
+		}
+		tasks := q.Tasks()
+		task = tasks[len(tasks)-1]
+		if task.Retries != n {
+			t.Fatalf("retry %d: expected the retries counted, got %d", n, task.Retries)
+		}
+	}
+
+	deadLettered, err := queue.Retry(ctx, q, task)
+	if err != nil || !deadLettered {
+		t.Fatalf("expected the task dead-lettered after %d retries, got dead-lettered=%v err=%v", queue.MaxRetries, deadLettered, err)
+	}
+	if dead := q.TasksFor(queue.DeadLetterQueueKey); len(dead) != 1 || dead[0].ID != "task-1" {
+		t.Errorf("expected the task on the dead-letter list, got %+v", dead)
+	}
+	if n := len(q.Tasks()); n != queue.MaxRetries {
+		t.Errorf("expected no requeue past the limit, got %d tasks", n)
+	}
+}
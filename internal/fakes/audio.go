@@ -0,0 +1,120 @@
+package fakes
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// AudioPlayer records playback requests without touching voice.
+type AudioPlayer struct {
+	mu      sync.Mutex
+	played  []string
+	last    string
+	paused  bool
+	stopped bool
+	channel string
+}
+
+// Connect records the channel joined.
+func (p *AudioPlayer) Connect(ctx context.Context, guildID, channelID string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.channel = channelID
+	return nil
+}
+
+// Play records guide/filename.
+func (p *AudioPlayer) Play(guide, filename string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.last = guide + "/" + filename
+	p.played = append(p.played, p.last)
+	p.paused = false
+	p.stopped = false
+	return nil
+}
+
+// Stop marks the last played file as stopped.
+func (p *AudioPlayer) Stop() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.stopped = true
+}
+
+// Stopped reports whether Stop was called since the last Play.
+func (p *AudioPlayer) Stopped() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.stopped
+}
+
+// Replay records the last played file again.
+func (p *AudioPlayer) Replay() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.last == "" {
+		return errors.New("no audio file to replay")
+	}
+	p.played = append(p.played, p.last)
+	return nil
+}
+
+// Pause marks playback as paused.
+func (p *AudioPlayer) Pause() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.paused = true
+}
+
+// Resume clears the paused flag.
+func (p *AudioPlayer) Resume() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.paused = false
+}
+
+// Paused reports whether playback is paused.
+func (p *AudioPlayer) Paused() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.paused
+}
+
+// Playing always reports false; playback finishes immediately.
+func (p *AudioPlayer) Playing() bool {
+	return false
+}
+
+// Disconnect forgets the channel joined.
+func (p *AudioPlayer) Disconnect(ctx context.Context) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.channel = ""
+	return nil
+}
+
+// Channel returns the voice channel currently joined, if any.
+func (p *AudioPlayer) Channel() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.channel
+}
+
+// Played returns the files played so far, as guide/filename.
+func (p *AudioPlayer) Played() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return append([]string(nil), p.played...)
+}
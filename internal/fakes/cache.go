@@ -0,0 +1,247 @@
+package fakes
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"strconv"
+	"sync"
+	"time"
+
+	"welcomebot/internal/core/cache"
+)
+
+// Cache is an in-memory cache.Client. TTLs are recorded but keys never
+// expire.
+type Cache struct {
+	mu       sync.Mutex
+	data     map[string]string
+	ttls     map[string]time.Duration
+	degraded bool
+	stats    cache.Stats
+	reads    int
+}
+
+// NewCache creates an empty cache.
+func NewCache() *Cache {
+	return &Cache{data: make(map[string]string), ttls: make(map[string]time.Duration)}
+}
+
+// Get retrieves a value from the cache.
+func (c *Cache) Get(ctx context.Context, key string) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.reads++
+	val, ok := c.data[key]
+	c.stats.Record(key, ok)
+	if !ok {
+		return "", fmt.Errorf("%w: %s", cache.ErrNotFound, key)
+	}
+	return val, nil
+}
+
+// Reads returns how many round trips Get, GetJSON and GetJSONMulti have
+// made, for tests and benchmarks counting them.
+func (c *Cache) Reads() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.reads
+}
+
+// Set stores a value in the cache.
+func (c *Cache) Set(ctx context.Context, key string, value string, ttl time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.data[key] = value
+	if ttl > 0 {
+		c.ttls[key] = ttl
+	} else {
+		delete(c.ttls, key)
+	}
+	return nil
+}
+
+// SetNX stores a value if the key is not already set.
+func (c *Cache) SetNX(ctx context.Context, key string, value string, ttl time.Duration) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.data[key]; ok {
+		return false, nil
+	}
+	c.data[key] = value
+	if ttl > 0 {
+		c.ttls[key] = ttl
+	}
+	return true, nil
+}
+
+// Delete removes a key from the cache.
+func (c *Cache) Delete(ctx context.Context, key string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.data, key)
+	delete(c.ttls, key)
+	return nil
+}
+
+// Exists checks if a key exists in the cache.
+func (c *Cache) Exists(ctx context.Context, key string) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	_, ok := c.data[key]
+	return ok, nil
+}
+
+// Keys returns the keys matching a glob pattern, in no particular order.
+func (c *Cache) Keys(ctx context.Context, pattern string) ([]string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var keys []string
+	for key := range c.data {
+		if ok, _ := path.Match(pattern, key); ok {
+			keys = append(keys, key)
+		}
+	}
+	return keys, nil
+}
+
+// GetJSON retrieves and unmarshals JSON from the cache.
+func (c *Cache) GetJSON(ctx context.Context, key string, dest interface{}) error {
+	val, err := c.Get(ctx, key)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal([]byte(val), dest)
+}
+
+// GetDelJSON retrieves, unmarshals and deletes a JSON value.
+func (c *Cache) GetDelJSON(ctx context.Context, key string, dest interface{}) error {
+	if err := c.GetJSON(ctx, key, dest); err != nil {
+		return err
+	}
+	return c.Delete(ctx, key)
+}
+
+// GetJSONMulti retrieves and unmarshals several JSON values, counting as
+// one read.
+func (c *Cache) GetJSONMulti(ctx context.Context, keys []string, dests ...interface{}) ([]bool, error) {
+	if len(keys) != len(dests) {
+		return nil, fmt.Errorf("get json multi: %d keys for %d destinations", len(keys), len(dests))
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.reads++
+	found := make([]bool, len(keys))
+	for i, key := range keys {
+		val, ok := c.data[key]
+		c.stats.Record(key, ok)
+		if ok && json.Unmarshal([]byte(val), dests[i]) == nil {
+			found[i] = true
+		}
+	}
+	return found, nil
+}
+
+// SetJSON marshals and stores JSON in the cache.
+func (c *Cache) SetJSON(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("marshal json for key %s: %w", key, err)
+	}
+	return c.Set(ctx, key, string(data), ttl)
+}
+
+// SetNXJSON marshals and stores JSON if the key is not already set.
+func (c *Cache) SetNXJSON(ctx context.Context, key string, value interface{}, ttl time.Duration) (bool, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return false, fmt.Errorf("marshal json for key %s: %w", key, err)
+	}
+	return c.SetNX(ctx, key, string(data), ttl)
+}
+
+// Incr increments an integer key.
+func (c *Cache) Incr(ctx context.Context, key string) (int64, error) {
+	return c.add(key, 1)
+}
+
+// Decr decrements an integer key.
+func (c *Cache) Decr(ctx context.Context, key string) (int64, error) {
+	return c.add(key, -1)
+}
+
+func (c *Cache) add(key string, delta int64) (int64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var n int64
+	if val, ok := c.data[key]; ok {
+		parsed, err := strconv.ParseInt(val, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("add to key %s: %w", key, err)
+		}
+		n = parsed
+	}
+	n += delta
+	c.data[key] = strconv.FormatInt(n, 10)
+	return n, nil
+}
+
+// Expire records the key's TTL.
+func (c *Cache) Expire(ctx context.Context, key string, ttl time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.data[key]; ok {
+		c.ttls[key] = ttl
+	}
+	return nil
+}
+
+// TTL returns the last TTL set on the key, -1 if it has none, or -2 if it
+// is missing, mirroring Redis.
+func (c *Cache) TTL(ctx context.Context, key string) (time.Duration, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.data[key]; !ok {
+		return -2, nil
+	}
+	if ttl, ok := c.ttls[key]; ok {
+		return ttl, nil
+	}
+	return -1, nil
+}
+
+// Degraded reports the value set with SetDegraded.
+func (c *Cache) Degraded() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.degraded
+}
+
+// SetDegraded simulates a tripped circuit breaker.
+func (c *Cache) SetDegraded(degraded bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.degraded = degraded
+}
+
+// Stats returns the hits and misses of Get, GetJSON and GetJSONMulti.
+func (c *Cache) Stats() *cache.Stats {
+	return &c.stats
+}
+
+// Close is a no-op.
+func (c *Cache) Close() error {
+	return nil
+}
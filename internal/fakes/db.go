@@ -0,0 +1,83 @@
+package fakes
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sync"
+)
+
+// errQueryUnsupported is returned for reads, which DB does not model.
+var errQueryUnsupported = errors.New("fakes: queries are not supported")
+
+// Exec is a statement recorded by DB.
+type Exec struct {
+	Query string
+	Args  []interface{}
+}
+
+// DB is a database.Client that records writes. Reads are not supported.
+type DB struct {
+	mu      sync.Mutex
+	execs   []Exec
+	execErr error
+}
+
+// NewDB creates an empty DB.
+func NewDB() *DB {
+	return &DB{}
+}
+
+// Query always fails.
+func (d *DB) Query(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return nil, errQueryUnsupported
+}
+
+// QueryRow panics; *sql.Row cannot be constructed outside database/sql.
+func (d *DB) QueryRow(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	panic(errQueryUnsupported)
+}
+
+// Exec records the statement and fails if FailExecs was called.
+func (d *DB) Exec(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.execs = append(d.execs, Exec{Query: query, Args: args})
+	if d.execErr != nil {
+		return nil, d.execErr
+	}
+	return driverResult{}, nil
+}
+
+// FailExecs makes every later Exec fail with err, after recording it.
+func (d *DB) FailExecs(err error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.execErr = err
+}
+
+// Execs returns the recorded statements in order.
+func (d *DB) Execs() []Exec {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return append([]Exec(nil), d.execs...)
+}
+
+// Close does nothing.
+func (d *DB) Close() error {
+	return nil
+}
+
+// Ping always succeeds.
+func (d *DB) Ping(ctx context.Context) error {
+	return nil
+}
+
+// driverResult reports a single affected row.
+type driverResult struct{}
+
+func (driverResult) LastInsertId() (int64, error) { return 0, nil }
+func (driverResult) RowsAffected() (int64, error) { return 1, nil }
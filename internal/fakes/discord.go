@@ -0,0 +1,297 @@
+package fakes
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// BotUserID is the user ID of the bot in sessions created by Discord.
+const BotUserID = "bot"
+
+var apiPrefix = regexp.MustCompile(`^/api/v\d+/`)
+
+// Request is a REST call received by the fake.
+type Request struct {
+	Method string
+	Path   string // Path relative to the API root, e.g. "channels/123/messages"
+	Body   []byte
+}
+
+// Discord fakes the Discord REST API behind a *discordgo.Session.
+// It tracks member roles so tests can assert on role changes.
+type Discord struct {
+	mu       sync.Mutex
+	requests []Request
+	roles    map[string]map[string]bool   // guildID:userID -> role set
+	guilds   map[string][]*discordgo.Role // guildID -> guild roles
+	notFound map[string]bool              // paths answered with 404
+	failures map[string]int               // "METHOD path" -> remaining 500s
+	full     map[string]bool              // category IDs that take no more channels
+	nextID   int
+}
+
+// NewDiscord creates an empty fake.
+func NewDiscord() *Discord {
+	return &Discord{
+		roles:    make(map[string]map[string]bool),
+		guilds:   make(map[string][]*discordgo.Role),
+		notFound: make(map[string]bool),
+		failures: make(map[string]int),
+		full:     make(map[string]bool),
+	}
+}
+
+// Session returns a discordgo session whose REST calls are served by d.
+func (d *Discord) Session() *discordgo.Session {
+	s, _ := discordgo.New("Bot fake-token")
+	s.Client = &http.Client{Transport: d}
+	s.State.User = &discordgo.User{ID: BotUserID, Username: "welcomebot"}
+	return s
+}
+
+// Requests returns the calls received so far.
+func (d *Discord) Requests() []Request {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return append([]Request(nil), d.requests...)
+}
+
+// Count returns how many calls were made to path with method.
+func (d *Discord) Count(method, path string) int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	n := 0
+	for _, r := range d.requests {
+		if r.Method == method && r.Path == path {
+			n++
+		}
+	}
+	return n
+}
+
+// SetGuildRoles replaces a guild's roles, as served by GET guilds/{g}/roles.
+func (d *Discord) SetGuildRoles(guildID string, roles ...*discordgo.Role) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.guilds[guildID] = roles
+}
+
+// SetNotFound makes every request to path (e.g. "channels/1/messages/2")
+// fail with 404, as if the object had been deleted.
+func (d *Discord) SetNotFound(path string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.notFound[path] = true
+}
+
+// FailRequests answers the next n requests matching method and path with
+// 500 Internal Server Error, before any other routing.
+func (d *Discord) FailRequests(method, path string, n int) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.failures[method+" "+path] = n
+}
+
+// SetCategoryFull makes channel creation in categoryID fail the way Discord
+// answers once a category holds its maximum of 50 channels.
+func (d *Discord) SetCategoryFull(categoryID string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.full[categoryID] = true
+}
+
+// SetMemberRoles replaces a member's roles.
+func (d *Discord) SetMemberRoles(guildID, userID string, roleIDs ...string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	set := make(map[string]bool, len(roleIDs))
+	for _, id := range roleIDs {
+		set[id] = true
+	}
+	d.roles[guildID+":"+userID] = set
+}
+
+// MemberRoles returns a member's current roles, sorted.
+func (d *Discord) MemberRoles(guildID, userID string) []string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return d.memberRolesLocked(guildID, userID)
+}
+
+// HasRole reports whether a member currently has a role.
+func (d *Discord) HasRole(guildID, userID, roleID string) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return d.roles[guildID+":"+userID][roleID]
+}
+
+func (d *Discord) memberRolesLocked(guildID, userID string) []string {
+	roles := []string{}
+	for id := range d.roles[guildID+":"+userID] {
+		roles = append(roles, id)
+	}
+	sort.Strings(roles)
+	return roles
+}
+
+// listMembersLocked returns the guild's members with roles set through
+// SetMemberRoles or role requests, ordered by user ID after after.
+func (d *Discord) listMembersLocked(guildID, after, limit string) []map[string]interface{} {
+	var userIDs []string
+	for key := range d.roles {
+		if userID, ok := strings.CutPrefix(key, guildID+":"); ok && userID > after {
+			userIDs = append(userIDs, userID)
+		}
+	}
+	sort.Strings(userIDs)
+	if n, err := strconv.Atoi(limit); err == nil && n < len(userIDs) {
+		userIDs = userIDs[:n]
+	}
+
+	members := make([]map[string]interface{}, 0, len(userIDs))
+	for _, userID := range userIDs {
+		members = append(members, map[string]interface{}{
+			"user":  map[string]string{"id": userID},
+			"roles": d.memberRolesLocked(guildID, userID),
+		})
+	}
+	return members
+}
+
+// RoundTrip implements http.RoundTripper.
+func (d *Discord) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		body, _ = io.ReadAll(req.Body)
+	}
+
+	path := apiPrefix.ReplaceAllString(req.URL.Path, "")
+
+	d.mu.Lock()
+	d.requests = append(d.requests, Request{Method: req.Method, Path: path, Body: body})
+	status, payload := d.route(req.Method, strings.Split(path, "/"), req.URL.Query(), body)
+	d.mu.Unlock()
+
+	var respBody []byte
+	if payload != nil {
+		respBody, _ = json.Marshal(payload)
+	}
+
+	return &http.Response{
+		StatusCode: status,
+		Status:     http.StatusText(status),
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(bytes.NewReader(respBody)),
+		Request:    req,
+	}, nil
+}
+
+// route produces a response for a request. Callers hold d.mu.
+func (d *Discord) route(method string, parts []string, query url.Values, body []byte) (int, interface{}) {
+	if key := method + " " + strings.Join(parts, "/"); d.failures[key] > 0 {
+		d.failures[key]--
+		return http.StatusInternalServerError, map[string]interface{}{"code": 0, "message": "Internal Server Error"}
+	}
+	if d.notFound[strings.Join(parts, "/")] {
+		return http.StatusNotFound, map[string]interface{}{"code": 10008, "message": "Unknown Message"}
+	}
+
+	switch {
+	// users/{id}
+	case len(parts) == 2 && parts[0] == "users" && method == http.MethodGet:
+		return http.StatusOK, map[string]string{"id": parts[1], "username": "user-" + parts[1]}
+
+	// guilds/{g}/channels
+	case len(parts) == 3 && parts[0] == "guilds" && parts[2] == "channels" && method == http.MethodPost:
+		var data struct {
+			Name     string `json:"name"`
+			Type     int    `json:"type"`
+			ParentID string `json:"parent_id"`
+		}
+		_ = json.Unmarshal(body, &data)
+		if d.full[data.ParentID] {
+			return http.StatusBadRequest, map[string]interface{}{
+				"code":    50035,
+				"message": "Invalid Form Body",
+				"errors": map[string]interface{}{"parent_id": map[string]interface{}{"_errors": []map[string]string{
+					{"code": "CHANNEL_PARENT_MAX_CHANNELS", "message": "Maximum number of channels in category reached (50)"},
+				}}},
+			}
+		}
+		return http.StatusCreated, map[string]interface{}{
+			"id": d.newID("channel"), "guild_id": parts[1], "name": data.Name, "type": data.Type, "parent_id": data.ParentID,
+		}
+
+	// guilds/{g}/roles
+	case len(parts) == 3 && parts[0] == "guilds" && parts[2] == "roles" && method == http.MethodGet:
+		roles := d.guilds[parts[1]]
+		if roles == nil {
+			roles = []*discordgo.Role{}
+		}
+		return http.StatusOK, roles
+
+	// guilds/{g}/members, paged by user ID
+	case len(parts) == 3 && parts[0] == "guilds" && parts[2] == "members" && method == http.MethodGet:
+		return http.StatusOK, d.listMembersLocked(parts[1], query.Get("after"), query.Get("limit"))
+
+	// guilds/{g}/members/{u}
+	case len(parts) == 4 && parts[0] == "guilds" && parts[2] == "members" && method == http.MethodGet:
+		return http.StatusOK, map[string]interface{}{
+			"user":  map[string]string{"id": parts[3]},
+			"roles": d.memberRolesLocked(parts[1], parts[3]),
+		}
+
+	// guilds/{g}/members/{u}/roles/{r}
+	case len(parts) == 6 && parts[0] == "guilds" && parts[2] == "members" && parts[4] == "roles":
+		key := parts[1] + ":" + parts[3]
+		if d.roles[key] == nil {
+			d.roles[key] = make(map[string]bool)
+		}
+		switch method {
+		case http.MethodPut:
+			d.roles[key][parts[5]] = true
+		case http.MethodDelete:
+			delete(d.roles[key], parts[5])
+		}
+		return http.StatusNoContent, nil
+
+	// channels/{c}/messages
+	case len(parts) == 3 && parts[0] == "channels" && parts[2] == "messages" && method == http.MethodPost:
+		return http.StatusOK, map[string]string{"id": d.newID("message"), "channel_id": parts[1]}
+
+	// channels/{c}
+	case len(parts) == 2 && parts[0] == "channels":
+		return http.StatusOK, map[string]string{"id": parts[1]}
+
+	// interactions/{id}/{token}/callback
+	case len(parts) == 4 && parts[0] == "interactions":
+		return http.StatusNoContent, nil
+	}
+
+	return http.StatusOK, map[string]string{"id": d.newID("object")}
+}
+
+func (d *Discord) newID(kind string) string {
+	d.nextID++
+	return fmt.Sprintf("%s-%d", kind, d.nextID)
+}
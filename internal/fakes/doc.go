@@ -0,0 +1,5 @@
+// Package fakes provides in-memory stand-ins for the core clients.
+//
+// The fakes are meant for tests that exercise features and worker
+// flows without Redis, PostgreSQL, Discord or a voice connection.
+package fakes
@@ -0,0 +1,53 @@
+package fakes
+
+import (
+	"context"
+	"strings"
+
+	"welcomebot/internal/core/i18n"
+)
+
+// I18n is an i18n.I18n that returns keys instead of translations.
+// TWithArgs appends the arguments so tests can still assert on them.
+type I18n struct{}
+
+// T returns the key.
+func (I18n) T(ctx context.Context, guildID, key string) string {
+	return key
+}
+
+// TWithArgs returns the key followed by its arguments.
+func (I18n) TWithArgs(ctx context.Context, guildID, key string, args map[string]string) string {
+	parts := []string{key}
+	for k, v := range args {
+		parts = append(parts, k+"="+v)
+	}
+	return strings.Join(parts, " ")
+}
+
+// SetGuildLanguage is a no-op.
+func (I18n) SetGuildLanguage(ctx context.Context, guildID, langCode string) error {
+	return nil
+}
+
+// GetGuildLanguage returns the locale set with i18n.WithLocale, or "en".
+// Tests may leave ctx nil.
+func (I18n) GetGuildLanguage(ctx context.Context, guildID string) (string, error) {
+	if ctx == nil {
+		return "en", nil
+	}
+	if locale := i18n.LocaleFrom(ctx); locale != "" {
+		return locale, nil
+	}
+	return "en", nil
+}
+
+// HasGuildLanguage always returns true.
+func (I18n) HasGuildLanguage(ctx context.Context, guildID string) bool {
+	return true
+}
+
+// AvailableLanguages returns "en".
+func (I18n) AvailableLanguages() []string {
+	return []string{"en"}
+}
@@ -0,0 +1,33 @@
+package fakes
+
+import "welcomebot/internal/core/logger"
+
+// Logger discards all log output.
+type Logger struct{}
+
+// Debug is a no-op.
+func (Logger) Debug(msg string, fields ...interface{}) {}
+
+// Info is a no-op.
+func (Logger) Info(msg string, fields ...interface{}) {}
+
+// Warn is a no-op.
+func (Logger) Warn(msg string, fields ...interface{}) {}
+
+// Error is a no-op.
+func (Logger) Error(msg string, fields ...interface{}) {}
+
+// WithField returns the same logger.
+func (l Logger) WithField(key string, value interface{}) logger.Logger {
+	return l
+}
+
+// WithFields returns the same logger.
+func (l Logger) WithFields(fields map[string]interface{}) logger.Logger {
+	return l
+}
+
+// Named returns the same logger.
+func (l Logger) Named(name string) logger.Logger {
+	return l
+}
@@ -0,0 +1,142 @@
+package fakes
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"welcomebot/internal/core/queue"
+)
+
+// Queue is an in-memory FIFO queue.Client. Dequeue never blocks.
+// Dequeued tasks are held as pending until acked.
+type Queue struct {
+	// MaxDepth makes Enqueue return queue.ErrFull once that many tasks
+	// are queued; 0 means no limit
+	MaxDepth int
+
+	mu      sync.Mutex
+	tasks   []queue.Task
+	pending []queue.Task
+	routed  map[string][]queue.Task
+}
+
+// NewQueue creates an empty queue.
+func NewQueue() *Queue {
+	return &Queue{}
+}
+
+// Enqueue appends a task, or returns queue.ErrFull at MaxDepth.
+func (q *Queue) Enqueue(ctx context.Context, task queue.Task) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.MaxDepth > 0 && len(q.tasks) >= q.MaxDepth {
+		return queue.ErrFull
+	}
+	q.tasks = append(q.tasks, task)
+	return nil
+}
+
+// EnqueueTo records a task sent to another list.
+func (q *Queue) EnqueueTo(ctx context.Context, queueKey string, task queue.Task) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.routed == nil {
+		q.routed = make(map[string][]queue.Task)
+	}
+	q.routed[queueKey] = append(q.routed[queueKey], task)
+	return nil
+}
+
+// Dequeue pops the oldest task, or returns nil if the queue is empty.
+func (q *Queue) Dequeue(ctx context.Context, timeout time.Duration) (*queue.Task, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if len(q.tasks) == 0 {
+		return nil, nil
+	}
+	task := q.tasks[0]
+	q.tasks = q.tasks[1:]
+	q.pending = append(q.pending, task)
+	return &task, nil
+}
+
+// Ack drops the first pending task with the same ID.
+func (q *Queue) Ack(ctx context.Context, task *queue.Task) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for i, t := range q.pending {
+		if t.ID == task.ID {
+			q.pending = append(q.pending[:i], q.pending[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+// Recover moves every pending task back to the front of the queue with a
+// retry counted, or to queue.DeadLetterQueueKey once it has been retried
+// queue.MaxRetries times.
+func (q *Queue) Recover(ctx context.Context) (int, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	var requeued []queue.Task
+	for _, task := range q.pending {
+		if task.Retries >= queue.MaxRetries {
+			if q.routed == nil {
+				q.routed = make(map[string][]queue.Task)
+			}
+			q.routed[queue.DeadLetterQueueKey] = append(q.routed[queue.DeadLetterQueueKey], task)
+			continue
+		}
+		task.Retries++
+		requeued = append(requeued, task)
+	}
+
+	n := len(q.pending)
+	q.tasks = append(requeued, q.tasks...)
+	q.pending = nil
+	return n, nil
+}
+
+// Depth returns the number of queued tasks.
+func (q *Queue) Depth(ctx context.Context) (int64, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return int64(len(q.tasks)), nil
+}
+
+// Pending returns a copy of the dequeued but unacked tasks.
+func (q *Queue) Pending() []queue.Task {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return append([]queue.Task(nil), q.pending...)
+}
+
+// Tasks returns a copy of the queued tasks.
+func (q *Queue) Tasks() []queue.Task {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return append([]queue.Task(nil), q.tasks...)
+}
+
+// TasksFor returns a copy of the tasks sent to queueKey with EnqueueTo.
+func (q *Queue) TasksFor(queueKey string) []queue.Task {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return append([]queue.Task(nil), q.routed[queueKey]...)
+}
+
+// Close is a no-op.
+func (q *Queue) Close() error {
+	return nil
+}
@@ -2,6 +2,7 @@ package agerange
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
@@ -9,6 +10,7 @@ import (
 	"welcomebot/internal/bot"
 	"welcomebot/internal/core/cache"
 	"welcomebot/internal/core/database"
+	"welcomebot/internal/core/discord"
 	"welcomebot/internal/core/i18n"
 	"welcomebot/internal/core/logger"
 	"welcomebot/internal/shared"
@@ -36,7 +38,7 @@ func New(deps Dependencies) (*Feature, error) {
 		db:     deps.DB,
 		cache:  deps.Cache,
 		i18n:   deps.I18n,
-		logger: deps.Logger,
+		logger: deps.Logger.Named(featureName),
 	}, nil
 }
 
@@ -57,7 +59,16 @@ func (f *Feature) HandleInteraction(ctx context.Context, s *discordgo.Session, i
 
 	// Overwrite confirmation
 	if customID == "agerange:confirm_overwrite" {
-		return f.showStep1(ctx, s, i)
+		return f.editWizard(ctx, s, i)
+	}
+
+	// Reset: confirm, then delete the configuration
+	if customID == "agerange:reset" {
+		return f.showResetConfirmation(ctx, s, i)
+	}
+
+	if customID == "agerange:reset:confirm" {
+		return f.resetConfig(ctx, s, i)
 	}
 
 	if customID == "agerange:cancel" {
@@ -105,7 +116,7 @@ func (f *Feature) RegisterCommands() []*discordgo.ApplicationCommand {
 // GetMenuButton returns the menu button for this feature.
 func (f *Feature) GetMenuButton() *bot.MenuButton {
 	return &bot.MenuButton{
-		Label:       "📅 Set Age Range Roles",
+		Label:       shared.WithEmoji(shared.EmojiAgeRange, "Set Age Range Roles"),
 		CustomID:    "menu:agerange:setup",
 		Tier:        3,
 		Category:    "admin",
@@ -137,6 +148,28 @@ func (f *Feature) deleteWizardState(ctx context.Context, guildID string) error {
 	return f.cache.Delete(ctx, key)
 }
 
+// wizardStateAt returns the wizard state for a panel of the given step.
+// An old panel would overwrite a role picked since and move the wizard
+// back to its step, so it is rejected.
+func (f *Feature) wizardStateAt(ctx context.Context, guildID string, step int) (*WizardState, error) {
+	state, err := f.getWizardState(ctx, guildID)
+	if errors.Is(err, cache.ErrNotFound) {
+		return nil, bot.ErrWizardExpired
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get wizard state: %w", err)
+	}
+	if state.CurrentStep != step {
+		f.logger.Info("outdated wizard panel used",
+			"guild_id", guildID,
+			"panel_step", step,
+			"current_step", state.CurrentStep,
+		)
+		return nil, bot.ErrWizardOutdated
+	}
+	return state, nil
+}
+
 // startWizard initiates the age range configuration wizard.
 func (f *Feature) startWizard(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) error {
 	guildID := i.GuildID
@@ -158,11 +191,45 @@ func (f *Feature) startWizard(ctx context.Context, s *discordgo.Session, i *disc
 	return f.showStep1(ctx, s, i)
 }
 
+// editWizard restarts the wizard from an existing configuration, so each
+// step's menu opens with the currently configured role selected.
+func (f *Feature) editWizard(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	guildID := i.GuildID
+
+	state := &WizardState{GuildID: guildID, CurrentStep: 1}
+	if config, err := f.getAgeRangeConfig(ctx, guildID); err == nil && config != nil {
+		if err := bot.SeedWizardState(config, state); err != nil {
+			f.logger.Error("failed to seed wizard state", "error", err)
+		}
+	}
+	if err := f.saveWizardState(ctx, state); err != nil {
+		f.logger.Error("failed to save wizard state", "error", err)
+	}
+
+	return f.showStep1(ctx, s, i)
+}
+
 // showOverwriteConfirmation shows confirmation for overwriting existing config.
 func (f *Feature) showOverwriteConfirmation(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate, config *AgeRangeConfig) error {
 	guildID := i.GuildID
 
-	desc := f.i18n.T(ctx, guildID, "agerange.current_config")
+	// One line per age group, unset ones included, since the wizard asks
+	// for all six again
+	groups := []struct{ key, roleID string }{
+		{"agerange.label_age_20_early", config.Age20EarlyRoleID},
+		{"agerange.label_age_20_late", config.Age20LateRoleID},
+		{"agerange.label_age_30_early", config.Age30EarlyRoleID},
+		{"agerange.label_age_30_late", config.Age30LateRoleID},
+		{"agerange.label_age_40_early", config.Age40EarlyRoleID},
+		{"agerange.label_age_40_late", config.Age40LateRoleID},
+	}
+	lines := make([]string, len(groups))
+	for n, g := range groups {
+		lines[n] = f.i18n.T(ctx, guildID, g.key) + ": " + discord.RoleMention(g.roleID)
+	}
+	desc := f.i18n.TWithArgs(ctx, guildID, "agerange.current_config", map[string]string{
+		"roles": strings.Join(lines, "\n"),
+	})
 
 	embed := &discordgo.MessageEmbed{
 		Title:       f.i18n.T(ctx, guildID, "agerange.overwrite_title"),
@@ -178,6 +245,11 @@ func (f *Feature) showOverwriteConfirmation(ctx context.Context, s *discordgo.Se
 					Style:    discordgo.DangerButton,
 					CustomID: "agerange:confirm_overwrite",
 				},
+				discordgo.Button{
+					Label:    f.i18n.T(ctx, guildID, "common.reset"),
+					Style:    discordgo.SecondaryButton,
+					CustomID: "agerange:reset",
+				},
 				discordgo.Button{
 					Label:    f.i18n.T(ctx, guildID, "common.cancel"),
 					Style:    discordgo.SecondaryButton,
@@ -193,6 +265,7 @@ func (f *Feature) showOverwriteConfirmation(ctx context.Context, s *discordgo.Se
 // showStep1 shows 20代前半 role selection.
 func (f *Feature) showStep1(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) error {
 	guildID := i.GuildID
+	state := bot.WizardDefaults(f.getWizardState(ctx, guildID))
 
 	embed := &discordgo.MessageEmbed{
 		Title:       f.i18n.T(ctx, guildID, "agerange.step1_title"),
@@ -203,11 +276,8 @@ func (f *Feature) showStep1(ctx context.Context, s *discordgo.Session, i *discor
 	components := []discordgo.MessageComponent{
 		discordgo.ActionsRow{
 			Components: []discordgo.MessageComponent{
-				discordgo.SelectMenu{
-					MenuType:    discordgo.RoleSelectMenu,
-					CustomID:    "agerange:age_20_early_role:select",
-					Placeholder: f.i18n.T(ctx, guildID, "agerange.select_age_20_early_role"),
-				},
+				discord.RoleSelectMenu("agerange:age_20_early_role:select",
+					f.i18n.T(ctx, guildID, "agerange.select_age_20_early_role"), state.Age20EarlyRoleID),
 			},
 		},
 	}
@@ -218,6 +288,7 @@ func (f *Feature) showStep1(ctx context.Context, s *discordgo.Session, i *discor
 // showStep2 shows 20代後半 role selection.
 func (f *Feature) showStep2(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) error {
 	guildID := i.GuildID
+	state := bot.WizardDefaults(f.getWizardState(ctx, guildID))
 
 	embed := &discordgo.MessageEmbed{
 		Title:       f.i18n.T(ctx, guildID, "agerange.step2_title"),
@@ -228,11 +299,8 @@ func (f *Feature) showStep2(ctx context.Context, s *discordgo.Session, i *discor
 	components := []discordgo.MessageComponent{
 		discordgo.ActionsRow{
 			Components: []discordgo.MessageComponent{
-				discordgo.SelectMenu{
-					MenuType:    discordgo.RoleSelectMenu,
-					CustomID:    "agerange:age_20_late_role:select",
-					Placeholder: f.i18n.T(ctx, guildID, "agerange.select_age_20_late_role"),
-				},
+				discord.RoleSelectMenu("agerange:age_20_late_role:select",
+					f.i18n.T(ctx, guildID, "agerange.select_age_20_late_role"), state.Age20LateRoleID),
 			},
 		},
 	}
@@ -243,6 +311,7 @@ func (f *Feature) showStep2(ctx context.Context, s *discordgo.Session, i *discor
 // showStep3 shows 30代前半 role selection.
 func (f *Feature) showStep3(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) error {
 	guildID := i.GuildID
+	state := bot.WizardDefaults(f.getWizardState(ctx, guildID))
 
 	embed := &discordgo.MessageEmbed{
 		Title:       f.i18n.T(ctx, guildID, "agerange.step3_title"),
@@ -253,11 +322,8 @@ func (f *Feature) showStep3(ctx context.Context, s *discordgo.Session, i *discor
 	components := []discordgo.MessageComponent{
 		discordgo.ActionsRow{
 			Components: []discordgo.MessageComponent{
-				discordgo.SelectMenu{
-					MenuType:    discordgo.RoleSelectMenu,
-					CustomID:    "agerange:age_30_early_role:select",
-					Placeholder: f.i18n.T(ctx, guildID, "agerange.select_age_30_early_role"),
-				},
+				discord.RoleSelectMenu("agerange:age_30_early_role:select",
+					f.i18n.T(ctx, guildID, "agerange.select_age_30_early_role"), state.Age30EarlyRoleID),
 			},
 		},
 	}
@@ -268,6 +334,7 @@ func (f *Feature) showStep3(ctx context.Context, s *discordgo.Session, i *discor
 // showStep4 shows 30代後半 role selection.
 func (f *Feature) showStep4(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) error {
 	guildID := i.GuildID
+	state := bot.WizardDefaults(f.getWizardState(ctx, guildID))
 
 	embed := &discordgo.MessageEmbed{
 		Title:       f.i18n.T(ctx, guildID, "agerange.step4_title"),
@@ -278,11 +345,8 @@ func (f *Feature) showStep4(ctx context.Context, s *discordgo.Session, i *discor
 	components := []discordgo.MessageComponent{
 		discordgo.ActionsRow{
 			Components: []discordgo.MessageComponent{
-				discordgo.SelectMenu{
-					MenuType:    discordgo.RoleSelectMenu,
-					CustomID:    "agerange:age_30_late_role:select",
-					Placeholder: f.i18n.T(ctx, guildID, "agerange.select_age_30_late_role"),
-				},
+				discord.RoleSelectMenu("agerange:age_30_late_role:select",
+					f.i18n.T(ctx, guildID, "agerange.select_age_30_late_role"), state.Age30LateRoleID),
 			},
 		},
 	}
@@ -293,6 +357,7 @@ func (f *Feature) showStep4(ctx context.Context, s *discordgo.Session, i *discor
 // showStep5 shows 40代前半 role selection.
 func (f *Feature) showStep5(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) error {
 	guildID := i.GuildID
+	state := bot.WizardDefaults(f.getWizardState(ctx, guildID))
 
 	embed := &discordgo.MessageEmbed{
 		Title:       f.i18n.T(ctx, guildID, "agerange.step5_title"),
@@ -303,11 +368,8 @@ func (f *Feature) showStep5(ctx context.Context, s *discordgo.Session, i *discor
 	components := []discordgo.MessageComponent{
 		discordgo.ActionsRow{
 			Components: []discordgo.MessageComponent{
-				discordgo.SelectMenu{
-					MenuType:    discordgo.RoleSelectMenu,
-					CustomID:    "agerange:age_40_early_role:select",
-					Placeholder: f.i18n.T(ctx, guildID, "agerange.select_age_40_early_role"),
-				},
+				discord.RoleSelectMenu("agerange:age_40_early_role:select",
+					f.i18n.T(ctx, guildID, "agerange.select_age_40_early_role"), state.Age40EarlyRoleID),
 			},
 		},
 	}
@@ -318,6 +380,7 @@ func (f *Feature) showStep5(ctx context.Context, s *discordgo.Session, i *discor
 // showStep6 shows 40代後半 role selection.
 func (f *Feature) showStep6(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) error {
 	guildID := i.GuildID
+	state := bot.WizardDefaults(f.getWizardState(ctx, guildID))
 
 	embed := &discordgo.MessageEmbed{
 		Title:       f.i18n.T(ctx, guildID, "agerange.step6_title"),
@@ -328,11 +391,8 @@ func (f *Feature) showStep6(ctx context.Context, s *discordgo.Session, i *discor
 	components := []discordgo.MessageComponent{
 		discordgo.ActionsRow{
 			Components: []discordgo.MessageComponent{
-				discordgo.SelectMenu{
-					MenuType:    discordgo.RoleSelectMenu,
-					CustomID:    "agerange:age_40_late_role:select",
-					Placeholder: f.i18n.T(ctx, guildID, "agerange.select_age_40_late_role"),
-				},
+				discord.RoleSelectMenu("agerange:age_40_late_role:select",
+					f.i18n.T(ctx, guildID, "agerange.select_age_40_late_role"), state.Age40LateRoleID),
 			},
 		},
 	}
@@ -346,15 +406,18 @@ func (f *Feature) handleAge20EarlyRoleSelection(ctx context.Context, s *discordg
 	values := i.MessageComponentData().Values
 
 	if len(values) == 0 {
-		return fmt.Errorf("no role selected")
+		return bot.ErrNoRoleSelected
 	}
 
 	roleID := values[0]
 
-	// Update wizard state
-	state, err := f.getWizardState(ctx, guildID)
+	// Update wizard state. Step 1 can begin again after the state expired.
+	state, err := f.wizardStateAt(ctx, guildID, 1)
+	if errors.Is(err, bot.ErrWizardExpired) {
+		state, err = &WizardState{GuildID: guildID, CurrentStep: 1}, nil
+	}
 	if err != nil {
-		state = &WizardState{GuildID: guildID}
+		return err
 	}
 	state.Age20EarlyRoleID = roleID
 	state.CurrentStep = 2
@@ -371,15 +434,15 @@ func (f *Feature) handleAge20LateRoleSelection(ctx context.Context, s *discordgo
 	values := i.MessageComponentData().Values
 
 	if len(values) == 0 {
-		return fmt.Errorf("no role selected")
+		return bot.ErrNoRoleSelected
 	}
 
 	roleID := values[0]
 
 	// Update wizard state
-	state, err := f.getWizardState(ctx, guildID)
+	state, err := f.wizardStateAt(ctx, guildID, 2)
 	if err != nil {
-		return fmt.Errorf("get wizard state: %w", err)
+		return err
 	}
 	state.Age20LateRoleID = roleID
 	state.CurrentStep = 3
@@ -396,15 +459,15 @@ func (f *Feature) handleAge30EarlyRoleSelection(ctx context.Context, s *discordg
 	values := i.MessageComponentData().Values
 
 	if len(values) == 0 {
-		return fmt.Errorf("no role selected")
+		return bot.ErrNoRoleSelected
 	}
 
 	roleID := values[0]
 
 	// Update wizard state
-	state, err := f.getWizardState(ctx, guildID)
+	state, err := f.wizardStateAt(ctx, guildID, 3)
 	if err != nil {
-		return fmt.Errorf("get wizard state: %w", err)
+		return err
 	}
 	state.Age30EarlyRoleID = roleID
 	state.CurrentStep = 4
@@ -421,15 +484,15 @@ func (f *Feature) handleAge30LateRoleSelection(ctx context.Context, s *discordgo
 	values := i.MessageComponentData().Values
 
 	if len(values) == 0 {
-		return fmt.Errorf("no role selected")
+		return bot.ErrNoRoleSelected
 	}
 
 	roleID := values[0]
 
 	// Update wizard state
-	state, err := f.getWizardState(ctx, guildID)
+	state, err := f.wizardStateAt(ctx, guildID, 4)
 	if err != nil {
-		return fmt.Errorf("get wizard state: %w", err)
+		return err
 	}
 	state.Age30LateRoleID = roleID
 	state.CurrentStep = 5
@@ -446,15 +509,15 @@ func (f *Feature) handleAge40EarlyRoleSelection(ctx context.Context, s *discordg
 	values := i.MessageComponentData().Values
 
 	if len(values) == 0 {
-		return fmt.Errorf("no role selected")
+		return bot.ErrNoRoleSelected
 	}
 
 	roleID := values[0]
 
 	// Update wizard state
-	state, err := f.getWizardState(ctx, guildID)
+	state, err := f.wizardStateAt(ctx, guildID, 5)
 	if err != nil {
-		return fmt.Errorf("get wizard state: %w", err)
+		return err
 	}
 	state.Age40EarlyRoleID = roleID
 	state.CurrentStep = 6
@@ -471,15 +534,15 @@ func (f *Feature) handleAge40LateRoleSelection(ctx context.Context, s *discordgo
 	values := i.MessageComponentData().Values
 
 	if len(values) == 0 {
-		return fmt.Errorf("no role selected")
+		return bot.ErrNoRoleSelected
 	}
 
 	roleID := values[0]
 
 	// Get final wizard state
-	state, err := f.getWizardState(ctx, guildID)
+	state, err := f.wizardStateAt(ctx, guildID, 6)
 	if err != nil {
-		return fmt.Errorf("get wizard state: %w", err)
+		return err
 	}
 	state.Age40LateRoleID = roleID
 
@@ -569,7 +632,7 @@ func (f *Feature) getAgeRangeConfig(ctx context.Context, guildID string) (*AgeRa
 		FROM guild_age_range_config 
 		WHERE guild_id = $1
 	`
-	row := f.db.QueryRow(ctx, query, guildID)
+	row := f.db.QueryRow(database.ReadFromReplica(ctx), query, guildID)
 
 	var age20Early, age20Late, age30Early, age30Late, age40Early, age40Late *string
 	err := row.Scan(&config.GuildID,
@@ -600,7 +663,7 @@ func (f *Feature) getAgeRangeConfig(ctx context.Context, guildID string) (*AgeRa
 		config.Age40LateRoleID = *age40Late
 	}
 
-	f.cache.SetJSON(ctx, cacheKey, &config, 0)
+	f.cache.SetJSON(ctx, cacheKey, &config, database.ReplicaCacheTTL)
 
 	return &config, nil
 }
@@ -1,7 +1,16 @@
 package agerange
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
 	"testing"
+
+	"welcomebot/internal/bot"
+	"welcomebot/internal/fakes"
+
+	"github.com/bwmarrin/discordgo"
 )
 
 func TestNew(t *testing.T) {
@@ -51,3 +60,113 @@ func TestGetMenuButton(t *testing.T) {
 // TestHandleInteraction_NotHandled is skipped because it requires proper mock setup
 // The actual functionality is tested through integration tests
 
+func TestConfirmOverwritePreselectsCurrentRoles(t *testing.T) {
+	ctx := context.Background()
+	cache, dc := fakes.NewCache(), fakes.NewDiscord()
+	f := &Feature{cache: cache, i18n: fakes.I18n{}, logger: fakes.Logger{}}
+
+	_ = cache.SetJSON(ctx, cacheKeyPrefix+"guild-1", &AgeRangeConfig{
+		GuildID:          "guild-1",
+		Age20EarlyRoleID: "role-20e",
+		Age40LateRoleID:  "role-40l",
+	}, 0)
+
+	i := &discordgo.InteractionCreate{Interaction: &discordgo.Interaction{
+		ID: "interaction-1", Token: "token", GuildID: "guild-1",
+		Type: discordgo.InteractionMessageComponent,
+		Data: discordgo.MessageComponentInteractionData{CustomID: "agerange:confirm_overwrite"},
+	}}
+	if err := f.HandleInteraction(ctx, dc.Session(), i); err != nil {
+		t.Fatalf("HandleInteraction: %v", err)
+	}
+
+	state, err := f.getWizardState(ctx, "guild-1")
+	if err != nil || state.Age20EarlyRoleID != "role-20e" || state.Age40LateRoleID != "role-40l" {
+		t.Fatalf("expected wizard state seeded from config, got %+v (%v)", state, err)
+	}
+
+	var callback struct {
+		Data struct {
+			Components []struct {
+				Components []struct {
+					CustomID      string                             `json:"custom_id"`
+					DefaultValues []discordgo.SelectMenuDefaultValue `json:"default_values"`
+				} `json:"components"`
+			} `json:"components"`
+		} `json:"data"`
+	}
+	requests := dc.Requests()
+	if len(requests) != 1 || !strings.HasSuffix(requests[0].Path, "/callback") {
+		t.Fatalf("expected one interaction response, got %+v", requests)
+	}
+	if err := json.Unmarshal(requests[0].Body, &callback); err != nil {
+		t.Fatal(err)
+	}
+	menu := callback.Data.Components[0].Components[0]
+	if menu.CustomID != "agerange:age_20_early_role:select" || len(menu.DefaultValues) != 1 || menu.DefaultValues[0].ID != "role-20e" {
+		t.Errorf("expected step 1 to pre-select role-20e, got %+v", menu)
+	}
+}
+
+func TestOverwriteConfirmationListsCurrentRoles(t *testing.T) {
+	ctx := context.Background()
+	dc := fakes.NewDiscord()
+	f := &Feature{i18n: fakes.I18n{}, logger: fakes.Logger{}}
+
+	i := &discordgo.InteractionCreate{Interaction: &discordgo.Interaction{
+		ID: "interaction-1", Token: "token", GuildID: "guild-1",
+		Type: discordgo.InteractionMessageComponent,
+		Data: discordgo.MessageComponentInteractionData{CustomID: "menu:agerange:setup"},
+	}}
+	config := &AgeRangeConfig{GuildID: "guild-1", Age20EarlyRoleID: "role-20e"}
+	if err := f.showOverwriteConfirmation(ctx, dc.Session(), i, config); err != nil {
+		t.Fatalf("showOverwriteConfirmation: %v", err)
+	}
+
+	var callback struct {
+		Data struct {
+			Embeds []discordgo.MessageEmbed `json:"embeds"`
+		} `json:"data"`
+	}
+	requests := dc.Requests()
+	if len(requests) != 1 {
+		t.Fatalf("expected one interaction response, got %+v", requests)
+	}
+	if err := json.Unmarshal(requests[0].Body, &callback); err != nil {
+		t.Fatal(err)
+	}
+	desc := callback.Data.Embeds[0].Description
+	if !strings.Contains(desc, "agerange.label_age_20_early: <@&role-20e>") || !strings.Contains(desc, "agerange.label_age_40_late: -") {
+		t.Errorf("expected set and unset roles to be listed, got %q", desc)
+	}
+}
+
+func TestOutdatedStepPanelIsRejected(t *testing.T) {
+	ctx := context.Background()
+	cache, dc := fakes.NewCache(), fakes.NewDiscord()
+	f := &Feature{cache: cache, i18n: fakes.I18n{}, logger: fakes.Logger{}}
+
+	// The wizard was restarted, leaving a step 3 panel on screen
+	if err := f.saveWizardState(ctx, &WizardState{GuildID: "guild-1", CurrentStep: 1}); err != nil {
+		t.Fatal(err)
+	}
+	i := &discordgo.InteractionCreate{Interaction: &discordgo.Interaction{
+		ID: "interaction-1", Token: "token", GuildID: "guild-1",
+		Type: discordgo.InteractionMessageComponent,
+		Data: discordgo.MessageComponentInteractionData{CustomID: "agerange:age_30_early_role:select", Values: []string{"role-30e"}},
+	}}
+	if err := f.HandleInteraction(ctx, dc.Session(), i); !errors.Is(err, bot.ErrWizardOutdated) {
+		t.Fatalf("expected ErrWizardOutdated, got %v", err)
+	}
+
+	state, err := f.getWizardState(ctx, "guild-1")
+	if err != nil || state.CurrentStep != 1 || state.Age30EarlyRoleID != "" {
+		t.Errorf("expected the wizard state to be left alone, got %+v (%v)", state, err)
+	}
+
+	// Once expired, nothing is left to apply it to
+	_ = f.deleteWizardState(ctx, "guild-1")
+	if err := f.HandleInteraction(ctx, dc.Session(), i); !errors.Is(err, bot.ErrWizardExpired) {
+		t.Errorf("expected ErrWizardExpired, got %v", err)
+	}
+}
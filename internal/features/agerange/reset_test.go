@@ -0,0 +1,37 @@
+package agerange
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"welcomebot/internal/fakes"
+)
+
+func TestDeleteAgeRangeConfig(t *testing.T) {
+	ctx := context.Background()
+	db, cache := fakes.NewDB(), fakes.NewCache()
+	f := &Feature{db: db, cache: cache, logger: fakes.Logger{}}
+
+	_ = cache.SetJSON(ctx, cacheKeyPrefix+"guild-1", &AgeRangeConfig{GuildID: "guild-1"}, 0)
+	_ = f.saveWizardState(ctx, &WizardState{GuildID: "guild-1", CurrentStep: 3})
+
+	if err := f.deleteAgeRangeConfig(ctx, "guild-1"); err != nil {
+		t.Fatalf("deleteAgeRangeConfig: %v", err)
+	}
+
+	execs := db.Execs()
+	if len(execs) != 1 || !strings.Contains(execs[0].Query, "DELETE FROM guild_age_range_config") {
+		t.Fatalf("expected one delete, got %+v", execs)
+	}
+	if execs[0].Args[0] != "guild-1" {
+		t.Errorf("expected guild-1, got %v", execs[0].Args[0])
+	}
+
+	if exists, _ := cache.Exists(ctx, cacheKeyPrefix+"guild-1"); exists {
+		t.Error("expected cached config to be removed")
+	}
+	if _, err := f.getWizardState(ctx, "guild-1"); err == nil {
+		t.Error("expected wizard state to be removed")
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"welcomebot/internal/bot"
+	"welcomebot/internal/core/i18n"
 	"welcomebot/internal/core/logger"
 
 	"github.com/bwmarrin/discordgo"
@@ -19,6 +20,7 @@ const (
 
 // Feature implements the botinfo command.
 type Feature struct {
+	i18n      i18n.I18n
 	logger    logger.Logger
 	startTime time.Time
 }
@@ -30,7 +32,8 @@ func New(deps Dependencies) (*Feature, error) {
 	}
 
 	return &Feature{
-		logger:    deps.Logger,
+		i18n:      deps.I18n,
+		logger:    deps.Logger.Named(featureName),
 		startTime: time.Now(),
 	}, nil
 }
@@ -62,7 +65,8 @@ func (f *Feature) HandleInteraction(ctx context.Context, s *discordgo.Session, i
 		"guild_id", i.GuildID,
 	)
 
-	embed := f.buildInfoEmbed(s)
+	locale, _ := f.i18n.GetGuildLanguage(ctx, i.GuildID)
+	embed := f.buildInfoEmbed(s, locale)
 
 	err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
 		Type: discordgo.InteractionResponseChannelMessageWithSource,
@@ -101,10 +105,10 @@ func (f *Feature) GetMenuButton() *bot.MenuButton {
 	}
 }
 
-// buildInfoEmbed creates the bot info embed.
-func (f *Feature) buildInfoEmbed(s *discordgo.Session) *discordgo.MessageEmbed {
-	uptime := formatUptime(time.Since(f.startTime))
-	guildCount := len(s.State.Guilds)
+// buildInfoEmbed creates the bot info embed, formatting numbers for locale.
+func (f *Feature) buildInfoEmbed(s *discordgo.Session, locale string) *discordgo.MessageEmbed {
+	uptime := i18n.FormatDuration(locale, time.Since(f.startTime).Truncate(time.Minute))
+	guildCount := int64(len(s.State.Guilds))
 
 	return &discordgo.MessageEmbed{
 		Title:       "🤖 welcomebot Bot Information",
@@ -118,7 +122,7 @@ func (f *Feature) buildInfoEmbed(s *discordgo.Session) *discordgo.MessageEmbed {
 			},
 			{
 				Name:   "Servers",
-				Value:  fmt.Sprintf("%d", guildCount),
+				Value:  i18n.FormatNumber(locale, guildCount),
 				Inline: true,
 			},
 			{
@@ -138,19 +142,3 @@ func (f *Feature) buildInfoEmbed(s *discordgo.Session) *discordgo.MessageEmbed {
 		Timestamp: time.Now().Format(time.RFC3339),
 	}
 }
-
-// formatUptime converts duration to readable format.
-func formatUptime(d time.Duration) string {
-	days := int(d.Hours() / 24)
-	hours := int(d.Hours()) % 24
-	minutes := int(d.Minutes()) % 60
-
-	if days > 0 {
-		return fmt.Sprintf("%dd %dh %dm", days, hours, minutes)
-	}
-	if hours > 0 {
-		return fmt.Sprintf("%dh %dm", hours, minutes)
-	}
-	return fmt.Sprintf("%dm", minutes)
-}
-
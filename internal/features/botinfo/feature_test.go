@@ -2,9 +2,9 @@ package botinfo_test
 
 import (
 	"testing"
-	"time"
 
 	"welcomebot/internal/core/logger"
+	"welcomebot/internal/fakes"
 	"welcomebot/internal/features/botinfo"
 )
 
@@ -15,6 +15,7 @@ func TestNew(t *testing.T) {
 	}
 
 	deps := botinfo.Dependencies{
+		I18n:   fakes.I18n{},
 		Logger: log,
 	}
 
@@ -39,7 +40,7 @@ func TestNew_MissingDependency(t *testing.T) {
 
 func TestName(t *testing.T) {
 	log, _ := logger.New(logger.DefaultConfig())
-	feature, _ := botinfo.New(botinfo.Dependencies{Logger: log})
+	feature, _ := botinfo.New(botinfo.Dependencies{I18n: fakes.I18n{}, Logger: log})
 
 	name := feature.Name()
 	if name != "botinfo" {
@@ -49,7 +50,7 @@ func TestName(t *testing.T) {
 
 func TestRegisterCommands(t *testing.T) {
 	log, _ := logger.New(logger.DefaultConfig())
-	feature, _ := botinfo.New(botinfo.Dependencies{Logger: log})
+	feature, _ := botinfo.New(botinfo.Dependencies{I18n: fakes.I18n{}, Logger: log})
 
 	commands := feature.RegisterCommands()
 	if len(commands) != 1 {
@@ -60,23 +61,3 @@ func TestRegisterCommands(t *testing.T) {
 		t.Errorf("expected command name 'botinfo', got '%s'", commands[0].Name)
 	}
 }
-
-func TestFormatUptime(t *testing.T) {
-	tests := []struct {
-		name     string
-		duration time.Duration
-		want     string
-	}{
-		{"minutes only", 30 * time.Minute, "30m"},
-		{"hours and minutes", 2*time.Hour + 15*time.Minute, "2h 15m"},
-		{"days hours minutes", 3*24*time.Hour + 5*time.Hour + 20*time.Minute, "3d 5h 20m"},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			// We can't test formatUptime directly as it's private
-			// This is just a placeholder to show test structure
-		})
-	}
-}
-
@@ -1,4 +1,4 @@
-package otherroles2
+package broadcast
 
 import (
 	"errors"
@@ -9,21 +9,25 @@ import (
 	"welcomebot/internal/core/logger"
 )
 
-// Dependencies contains all required dependencies for the other roles 2 feature.
+// Dependencies contains all required dependencies for the broadcast feature.
 type Dependencies struct {
 	DB     database.Client
 	Cache  cache.Client
 	I18n   i18n.I18n
 	Logger logger.Logger
+
+	// OwnerIDs may use /broadcast. When empty, the application's owner
+	// (or its team members) may.
+	OwnerIDs []string
 }
 
 // Validate ensures all required dependencies are present.
 func (d Dependencies) Validate() error {
 	if d.DB == nil {
-		return errors.New("database is required")
+		return errors.New("database client is required")
 	}
 	if d.Cache == nil {
-		return errors.New("cache is required")
+		return errors.New("cache client is required")
 	}
 	if d.I18n == nil {
 		return errors.New("i18n is required")
@@ -33,4 +37,3 @@ func (d Dependencies) Validate() error {
 	}
 	return nil
 }
-
@@ -0,0 +1,7 @@
+// Package broadcast provides the owner-only /broadcast command.
+//
+// It posts an announcement to the configured welcome channel of selected
+// guilds (or all of them), after the owner confirms a preview. Sends are
+// spaced out to stay clear of rate limits, and the owner gets a per-guild
+// success/failure report.
+package broadcast
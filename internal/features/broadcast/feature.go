@@ -0,0 +1,364 @@
+package broadcast
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"welcomebot/internal/bot"
+	"welcomebot/internal/core/cache"
+	"welcomebot/internal/core/database"
+	"welcomebot/internal/core/i18n"
+	"welcomebot/internal/core/logger"
+	"welcomebot/internal/shared"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+const (
+	featureName = "broadcast"
+
+	// pendingTTL is how long the owner has to confirm a broadcast.
+	pendingTTL = 10 * time.Minute
+)
+
+// Feature implements the /broadcast command.
+type Feature struct {
+	db       database.Client
+	cache    cache.Client
+	i18n     i18n.I18n
+	logger   logger.Logger
+	ownerIDs map[string]bool
+}
+
+// New creates a new broadcast feature.
+func New(deps Dependencies) (*Feature, error) {
+	if err := deps.Validate(); err != nil {
+		return nil, fmt.Errorf("validate dependencies: %w", err)
+	}
+
+	owners := make(map[string]bool, len(deps.OwnerIDs))
+	for _, id := range deps.OwnerIDs {
+		owners[id] = true
+	}
+
+	return &Feature{
+		db:       deps.DB,
+		cache:    deps.Cache,
+		i18n:     deps.I18n,
+		logger:   deps.Logger.Named(featureName),
+		ownerIDs: owners,
+	}, nil
+}
+
+// Name returns the feature name.
+func (f *Feature) Name() string {
+	return featureName
+}
+
+// HandleInteraction handles /broadcast and its confirmation buttons.
+func (f *Feature) HandleInteraction(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	switch i.Type {
+	case discordgo.InteractionApplicationCommand:
+		if i.ApplicationCommandData().Name != "broadcast" {
+			return bot.ErrNotHandled
+		}
+		return f.handleCommand(ctx, s, i)
+
+	case discordgo.InteractionMessageComponent:
+		customID := i.MessageComponentData().CustomID
+		if id, ok := strings.CutPrefix(customID, "broadcast:confirm:"); ok {
+			return f.handleConfirm(ctx, s, i, id)
+		}
+		if id, ok := strings.CutPrefix(customID, "broadcast:cancel:"); ok {
+			return f.handleCancel(ctx, s, i, id)
+		}
+	}
+	return bot.ErrNotHandled
+}
+
+// RegisterCommands returns the slash commands for this feature.
+func (f *Feature) RegisterCommands() []*discordgo.ApplicationCommand {
+	return []*discordgo.ApplicationCommand{
+		{
+			Name:        "broadcast",
+			Description: "Post an announcement to welcome channels (bot owner only)",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "message",
+					Description: "Announcement text",
+					Required:    true,
+					MaxLength:   2000,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "guilds",
+					Description: "Comma-separated guild IDs; omit for all guilds",
+				},
+			},
+		},
+	}
+}
+
+// GetMenuButton returns nil; /broadcast is not for guild admins.
+func (f *Feature) GetMenuButton() *bot.MenuButton {
+	return nil
+}
+
+// handleCommand validates the request and shows a preview to confirm.
+func (f *Feature) handleCommand(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	userID := interactionUserID(i)
+	if !f.isOwner(s, userID) {
+		f.logger.Warn("broadcast denied", "user_id", userID, "guild_id", i.GuildID)
+		return f.respondEphemeral(s, i, f.i18n.T(ctx, i.GuildID, "broadcast.owner_only"))
+	}
+
+	var message, guilds string
+	for _, opt := range i.ApplicationCommandData().Options {
+		switch opt.Name {
+		case "message":
+			message = opt.StringValue()
+		case "guilds":
+			guilds = opt.StringValue()
+		}
+	}
+
+	targets, err := f.listTargets(ctx)
+	if err != nil {
+		f.logger.Error("failed to list welcome channels", "error", err)
+		return f.respondEphemeral(s, i, f.i18n.T(ctx, i.GuildID, "broadcast.error_targets"))
+	}
+	targets, missing := selectTargets(targets, parseGuildIDs(guilds))
+	if len(targets) == 0 {
+		return f.respondEphemeral(s, i, f.i18n.T(ctx, i.GuildID, "broadcast.no_targets"))
+	}
+
+	pending := &Pending{OwnerID: userID, Message: message, Targets: targets}
+	if err := f.cache.SetJSON(ctx, pendingKeyPrefix+i.ID, pending, pendingTTL); err != nil {
+		return fmt.Errorf("save pending broadcast: %w", err)
+	}
+
+	description := f.i18n.TWithArgs(ctx, i.GuildID, "broadcast.confirm_description", map[string]string{
+		"count": fmt.Sprint(len(targets)),
+	})
+	if len(missing) > 0 {
+		description += "\n" + f.i18n.TWithArgs(ctx, i.GuildID, "broadcast.skipped_guilds", map[string]string{
+			"guilds": strings.Join(missing, ", "),
+		})
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Title:       f.i18n.T(ctx, i.GuildID, "broadcast.confirm_title"),
+		Description: description,
+		Color:       int(shared.ColorWarning),
+		Fields: []*discordgo.MessageEmbedField{
+			{Name: f.i18n.T(ctx, i.GuildID, "broadcast.preview"), Value: truncate(message, 1024)},
+		},
+	}
+	components := []discordgo.MessageComponent{
+		discordgo.ActionsRow{
+			Components: []discordgo.MessageComponent{
+				discordgo.Button{
+					Label:    f.i18n.T(ctx, i.GuildID, "broadcast.send"),
+					Style:    discordgo.DangerButton,
+					CustomID: "broadcast:confirm:" + i.ID,
+				},
+				discordgo.Button{
+					Label:    f.i18n.T(ctx, i.GuildID, "common.cancel"),
+					Style:    discordgo.SecondaryButton,
+					CustomID: "broadcast:cancel:" + i.ID,
+				},
+			},
+		},
+	}
+
+	return s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Embeds:     []*discordgo.MessageEmbed{embed},
+			Components: components,
+			Flags:      discordgo.MessageFlagsEphemeral,
+		},
+	})
+}
+
+// handleConfirm sends a pending broadcast and replaces the preview with
+// the per-guild report.
+func (f *Feature) handleConfirm(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate, id string) error {
+	pending, ok := f.takePending(ctx, s, i, id)
+	if !ok {
+		return nil
+	}
+
+	// Sending takes a while; acknowledge now and edit in the report
+	err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredMessageUpdate,
+	})
+	if err != nil {
+		return fmt.Errorf("acknowledge broadcast: %w", err)
+	}
+
+	f.logger.Info("broadcast started", "user_id", pending.OwnerID, "guilds", len(pending.Targets))
+	results := f.send(ctx, s, pending.Message, pending.Targets)
+
+	embed := f.buildReport(ctx, i.GuildID, s, results)
+	_, err = s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+		Embeds:     &[]*discordgo.MessageEmbed{embed},
+		Components: &[]discordgo.MessageComponent{},
+	})
+	if err != nil {
+		return fmt.Errorf("post broadcast report: %w", err)
+	}
+	return nil
+}
+
+// handleCancel discards a pending broadcast.
+func (f *Feature) handleCancel(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate, id string) error {
+	if _, ok := f.takePending(ctx, s, i, id); !ok {
+		return nil
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Title:       f.i18n.T(ctx, i.GuildID, "common.cancelled"),
+		Description: f.i18n.T(ctx, i.GuildID, "broadcast.cancelled"),
+		Color:       int(shared.ColorInfo),
+	}
+	return s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseUpdateMessage,
+		Data: &discordgo.InteractionResponseData{
+			Embeds:     []*discordgo.MessageEmbed{embed},
+			Components: []discordgo.MessageComponent{},
+		},
+	})
+}
+
+// takePending loads and removes a pending broadcast, so a double click
+// cannot send it twice. It responds itself when there is nothing to take.
+func (f *Feature) takePending(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate, id string) (*Pending, bool) {
+	userID := interactionUserID(i)
+	if !f.isOwner(s, userID) {
+		_ = f.respondEphemeral(s, i, f.i18n.T(ctx, i.GuildID, "broadcast.owner_only"))
+		return nil, false
+	}
+
+	// One GETDEL, so two clicks handled at the same time can't both read
+	// it before either deletes it
+	var pending Pending
+	if err := f.cache.GetDelJSON(ctx, pendingKeyPrefix+id, &pending); err != nil {
+		_ = f.respondEphemeral(s, i, f.i18n.T(ctx, i.GuildID, "broadcast.expired"))
+		return nil, false
+	}
+	return &pending, true
+}
+
+// isOwner reports whether userID may broadcast.
+func (f *Feature) isOwner(s *discordgo.Session, userID string) bool {
+	if userID == "" {
+		return false
+	}
+	if len(f.ownerIDs) > 0 {
+		return f.ownerIDs[userID]
+	}
+
+	app, err := s.Application("@me")
+	if err != nil {
+		f.logger.Warn("failed to look up application owner", "error", err)
+		return false
+	}
+	if app.Team != nil {
+		for _, member := range app.Team.Members {
+			if member.User != nil && member.User.ID == userID {
+				return true
+			}
+		}
+		return false
+	}
+	return app.Owner != nil && app.Owner.ID == userID
+}
+
+// listTargets returns every configured welcome channel.
+func (f *Feature) listTargets(ctx context.Context) ([]Target, error) {
+	rows, err := f.db.Query(ctx, `
+		SELECT guild_id, welcome_channel_id
+		FROM guild_welcome_config
+		WHERE welcome_channel_id <> ''
+		ORDER BY guild_id
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("query welcome channels: %w", err)
+	}
+	defer rows.Close()
+
+	var targets []Target
+	for rows.Next() {
+		var t Target
+		if err := rows.Scan(&t.GuildID, &t.ChannelID); err != nil {
+			return nil, fmt.Errorf("scan welcome channel: %w", err)
+		}
+		targets = append(targets, t)
+	}
+	return targets, rows.Err()
+}
+
+func (f *Feature) respondEphemeral(s *discordgo.Session, i *discordgo.InteractionCreate, content string) error {
+	return s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content: content,
+			Flags:   discordgo.MessageFlagsEphemeral,
+		},
+	})
+}
+
+// interactionUserID returns the invoking user in guilds and DMs alike.
+func interactionUserID(i *discordgo.InteractionCreate) string {
+	if i.Member != nil && i.Member.User != nil {
+		return i.Member.User.ID
+	}
+	if i.User != nil {
+		return i.User.ID
+	}
+	return ""
+}
+
+// parseGuildIDs splits the guilds option. An empty option selects all.
+func parseGuildIDs(s string) []string {
+	var ids []string
+	for _, id := range strings.Split(s, ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// selectTargets keeps the targets for the given guilds, or all of them when
+// none are given. missing lists requested guilds without a welcome channel.
+func selectTargets(all []Target, guildIDs []string) (targets []Target, missing []string) {
+	if len(guildIDs) == 0 {
+		return all, nil
+	}
+
+	byGuild := make(map[string]Target, len(all))
+	for _, t := range all {
+		byGuild[t.GuildID] = t
+	}
+	for _, id := range guildIDs {
+		if t, ok := byGuild[id]; ok {
+			targets = append(targets, t)
+		} else {
+			missing = append(missing, id)
+		}
+	}
+	return targets, missing
+}
+
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n-1]) + "…"
+}
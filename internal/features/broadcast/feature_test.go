@@ -0,0 +1,145 @@
+package broadcast
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"welcomebot/internal/fakes"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func TestNew(t *testing.T) {
+	deps := Dependencies{}
+	_, err := New(deps)
+	if err == nil {
+		t.Error("expected error for empty dependencies, got nil")
+	}
+}
+
+func TestName(t *testing.T) {
+	f := &Feature{}
+	if f.Name() != "broadcast" {
+		t.Errorf("expected name 'broadcast', got '%s'", f.Name())
+	}
+}
+
+func TestRegisterCommands(t *testing.T) {
+	f := &Feature{}
+	commands := f.RegisterCommands()
+	if len(commands) != 1 || commands[0].Name != "broadcast" {
+		t.Fatalf("expected the broadcast command, got %+v", commands)
+	}
+}
+
+func TestGetMenuButton(t *testing.T) {
+	f := &Feature{}
+	if f.GetMenuButton() != nil {
+		t.Error("expected broadcast to stay out of /menu")
+	}
+}
+
+func TestSelectTargets(t *testing.T) {
+	all := []Target{{"g1", "c1"}, {"g2", "c2"}}
+
+	if got, _ := selectTargets(all, parseGuildIDs("")); !reflect.DeepEqual(got, all) {
+		t.Errorf("expected all targets, got %v", got)
+	}
+
+	got, missing := selectTargets(all, parseGuildIDs(" g2 , g3"))
+	if !reflect.DeepEqual(got, []Target{{"g2", "c2"}}) {
+		t.Errorf("expected g2 only, got %v", got)
+	}
+	if !reflect.DeepEqual(missing, []string{"g3"}) {
+		t.Errorf("expected g3 to be reported missing, got %v", missing)
+	}
+}
+
+func newTestFeature(t *testing.T) (*Feature, *fakes.Cache) {
+	t.Helper()
+	interval := sendInterval
+	sendInterval = 0
+	t.Cleanup(func() { sendInterval = interval })
+
+	c := fakes.NewCache()
+	f, err := New(Dependencies{
+		DB: fakes.NewDB(), Cache: c, I18n: fakes.I18n{}, Logger: fakes.Logger{},
+		OwnerIDs: []string{"owner-1"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return f, c
+}
+
+func clickFrom(userID, customID string) *discordgo.InteractionCreate {
+	return &discordgo.InteractionCreate{Interaction: &discordgo.Interaction{
+		ID: "interaction-2", Token: "token", GuildID: "g1",
+		Type:   discordgo.InteractionMessageComponent,
+		Member: &discordgo.Member{User: &discordgo.User{ID: userID}},
+		Data:   discordgo.MessageComponentInteractionData{CustomID: customID},
+	}}
+}
+
+func TestConfirmSendsOncePerGuild(t *testing.T) {
+	ctx := context.Background()
+	f, c := newTestFeature(t)
+	d := fakes.NewDiscord()
+	d.SetNotFound("channels/c2/messages")
+
+	_ = c.SetJSON(ctx, pendingKeyPrefix+"cmd-1", &Pending{
+		OwnerID: "owner-1",
+		Message: "New onboarding flow from Monday",
+		Targets: []Target{{"g1", "c1"}, {"g2", "c2"}, {"g3", "c3"}},
+	}, 0)
+
+	if err := f.HandleInteraction(ctx, d.Session(), clickFrom("owner-1", "broadcast:confirm:cmd-1")); err != nil {
+		t.Fatalf("confirm: %v", err)
+	}
+	// A second click finds nothing left to send
+	if err := f.HandleInteraction(ctx, d.Session(), clickFrom("owner-1", "broadcast:confirm:cmd-1")); err != nil {
+		t.Fatalf("second confirm: %v", err)
+	}
+
+	for _, channel := range []string{"c1", "c3"} {
+		if n := d.Count(http.MethodPost, "channels/"+channel+"/messages"); n != 1 {
+			t.Errorf("expected one post to %s, got %d", channel, n)
+		}
+	}
+
+	var report string
+	for _, r := range d.Requests() {
+		if r.Method == http.MethodPost && r.Path == "channels/c1/messages" && !strings.Contains(string(r.Body), `"allowed_mentions":{"parse":null,`) {
+			t.Errorf("expected the broadcast to ping no one, got %s", r.Body)
+		}
+		if r.Method == http.MethodPatch && strings.HasSuffix(r.Path, "/messages/@original") {
+			report = string(r.Body)
+		}
+	}
+	if !strings.Contains(report, "✅ g1") || !strings.Contains(report, "❌ g2") || !strings.Contains(report, "✅ g3") {
+		t.Errorf("expected a per-guild report, got %s", report)
+	}
+}
+
+func TestNonOwnerCannotConfirm(t *testing.T) {
+	ctx := context.Background()
+	f, c := newTestFeature(t)
+	d := fakes.NewDiscord()
+
+	_ = c.SetJSON(ctx, pendingKeyPrefix+"cmd-1", &Pending{
+		OwnerID: "owner-1", Message: "hi", Targets: []Target{{"g1", "c1"}},
+	}, 0)
+
+	if err := f.HandleInteraction(ctx, d.Session(), clickFrom("user-2", "broadcast:confirm:cmd-1")); err != nil {
+		t.Fatal(err)
+	}
+	if n := d.Count(http.MethodPost, "channels/c1/messages"); n != 0 {
+		t.Errorf("expected nothing to be sent, got %d posts", n)
+	}
+	if exists, _ := c.Exists(ctx, pendingKeyPrefix+"cmd-1"); !exists {
+		t.Error("expected the pending broadcast to be kept for the owner")
+	}
+}
@@ -0,0 +1,80 @@
+package broadcast
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"welcomebot/internal/core/discord"
+	"welcomebot/internal/shared"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// sendInterval spaces out sends so a large broadcast does not run into the
+// global rate limit.
+var sendInterval = time.Second
+
+// send posts message to each target in turn. A failed guild does not stop
+// the rest.
+func (f *Feature) send(ctx context.Context, s *discordgo.Session, message string, targets []Target) []Result {
+	results := make([]Result, 0, len(targets))
+	for n, target := range targets {
+		if n > 0 {
+			select {
+			case <-time.After(sendInterval):
+			case <-ctx.Done():
+				results = append(results, Result{Target: target, Err: ctx.Err()})
+				continue
+			}
+		}
+
+		err := discord.Retry(ctx, f.logger, "broadcast", discord.SendRetryPolicy, func() error {
+			_, err := s.ChannelMessageSendComplex(target.ChannelID, &discordgo.MessageSend{
+				Content: message,
+				// An @everyone or role mention in the text must not ping
+				// every guild the bot is in
+				AllowedMentions: &discordgo.MessageAllowedMentions{},
+			})
+			return err
+		})
+		if err != nil {
+			f.logger.Warn("broadcast failed", "guild_id", target.GuildID, "channel_id", target.ChannelID, "error", err)
+		}
+		results = append(results, Result{Target: target, Err: err})
+	}
+	return results
+}
+
+// buildReport summarizes the results, one line per guild.
+func (f *Feature) buildReport(ctx context.Context, guildID string, s *discordgo.Session, results []Result) *discordgo.MessageEmbed {
+	sent := 0
+	lines := make([]string, 0, len(results))
+	for _, r := range results {
+		name := r.Target.GuildID
+		if g, err := s.State.Guild(r.Target.GuildID); err == nil && g.Name != "" {
+			name = fmt.Sprintf("%s (%s)", g.Name, r.Target.GuildID)
+		}
+		if r.Err != nil {
+			lines = append(lines, fmt.Sprintf("❌ %s: %v", name, r.Err))
+			continue
+		}
+		sent++
+		lines = append(lines, "✅ "+name)
+	}
+
+	color := shared.ColorSuccess
+	if sent < len(results) {
+		color = shared.ColorWarning
+	}
+
+	return &discordgo.MessageEmbed{
+		Title: f.i18n.TWithArgs(ctx, guildID, "broadcast.report_title", map[string]string{
+			"sent":  fmt.Sprint(sent),
+			"total": fmt.Sprint(len(results)),
+		}),
+		Description: truncate(strings.Join(lines, "\n"), 4096),
+		Color:       int(color),
+	}
+}
@@ -0,0 +1,22 @@
+package broadcast
+
+const pendingKeyPrefix = "welcomebot:broadcast:pending:"
+
+// Target is a guild's welcome channel.
+type Target struct {
+	GuildID   string `json:"guild_id"`
+	ChannelID string `json:"channel_id"`
+}
+
+// Pending is a broadcast waiting for the owner's confirmation.
+type Pending struct {
+	OwnerID string   `json:"owner_id"`
+	Message string   `json:"message"`
+	Targets []Target `json:"targets"`
+}
+
+// Result is the outcome of sending to one guild.
+type Result struct {
+	Target Target
+	Err    error
+}
@@ -9,6 +9,7 @@ import (
 	"welcomebot/internal/bot"
 	"welcomebot/internal/core/cache"
 	"welcomebot/internal/core/database"
+	"welcomebot/internal/core/discord"
 	"welcomebot/internal/core/i18n"
 	"welcomebot/internal/core/logger"
 	"welcomebot/internal/shared"
@@ -36,7 +37,7 @@ func New(deps Dependencies) (*Feature, error) {
 		db:     deps.DB,
 		cache:  deps.Cache,
 		i18n:   deps.I18n,
-		logger: deps.Logger,
+		logger: deps.Logger.Named(featureName),
 	}, nil
 }
 
@@ -85,7 +86,7 @@ func (f *Feature) RegisterCommands() []*discordgo.ApplicationCommand {
 // GetMenuButton returns the menu button for this feature.
 func (f *Feature) GetMenuButton() *bot.MenuButton {
 	return &bot.MenuButton{
-		Label:       "🚻 性別ロール設定",
+		Label:       shared.WithEmoji(shared.EmojiGender, "性別ロール設定"),
 		CustomID:    "menu:gender:setup",
 		Tier:        3,
 		Category:    "admin",
@@ -111,13 +112,10 @@ func (f *Feature) startWizard(ctx context.Context, s *discordgo.Session, i *disc
 func (f *Feature) showOverwriteConfirmation(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate, config *GenderConfig) error {
 	guildID := i.GuildID
 
-	maleRole := fmt.Sprintf("<@&%s>", config.MaleRoleID)
-	femaleRole := fmt.Sprintf("<@&%s>", config.FemaleRoleID)
-
 	desc := f.i18n.TWithArgs(ctx, guildID, "gender.current_config",
 		map[string]string{
-			"male":   maleRole,
-			"female": femaleRole,
+			"male":   discord.RoleMention(config.MaleRoleID),
+			"female": discord.RoleMention(config.FemaleRoleID),
 		})
 
 	embed := &discordgo.MessageEmbed{
@@ -156,7 +154,7 @@ func (f *Feature) showStep1(ctx context.Context, s *discordgo.Session, i *discor
 		Color:       int(shared.ColorInfo),
 	}
 
-	components := f.buildRoleSelectMenu(ctx, guildID, s, "gender:male:", "gender.select_male")
+	components := f.buildRoleSelectMenu(ctx, guildID, s, "gender:male:", "gender.select_male", f.currentConfig(ctx, guildID).MaleRoleID)
 
 	return respond(s, i, embed, components)
 }
@@ -172,7 +170,7 @@ func (f *Feature) showStep2(ctx context.Context, s *discordgo.Session, i *discor
 	}
 
 	customIDPrefix := fmt.Sprintf("gender:female:%s:", maleRoleID)
-	components := f.buildRoleSelectMenu(ctx, guildID, s, customIDPrefix, "gender.select_female")
+	components := f.buildRoleSelectMenu(ctx, guildID, s, customIDPrefix, "gender.select_female", f.currentConfig(ctx, guildID).FemaleRoleID)
 
 	return respond(s, i, embed, components)
 }
@@ -181,7 +179,7 @@ func (f *Feature) showStep2(ctx context.Context, s *discordgo.Session, i *discor
 func (f *Feature) handleMaleRoleSelection(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) error {
 	values := i.MessageComponentData().Values
 	if len(values) == 0 {
-		return fmt.Errorf("no role selected")
+		return bot.ErrNoRoleSelected
 	}
 
 	maleRoleID := values[0]
@@ -201,7 +199,7 @@ func (f *Feature) handleFemaleRoleSelection(ctx context.Context, s *discordgo.Se
 	values := i.MessageComponentData().Values
 
 	if len(values) == 0 {
-		return fmt.Errorf("no role selected")
+		return bot.ErrNoRoleSelected
 	}
 
 	femaleRoleID := values[0]
@@ -271,29 +269,35 @@ func (f *Feature) getGenderConfig(ctx context.Context, guildID string) (*GenderC
 	}
 
 	query := "SELECT guild_id, male_role_id, female_role_id, created_at, updated_at FROM guild_gender_roles WHERE guild_id = $1"
-	row := f.db.QueryRow(ctx, query, guildID)
+	row := f.db.QueryRow(database.ReadFromReplica(ctx), query, guildID)
 
 	err := row.Scan(&config.GuildID, &config.MaleRoleID, &config.FemaleRoleID, &config.CreatedAt, &config.UpdatedAt)
 	if err != nil {
 		return nil, err
 	}
 
-	f.cache.SetJSON(ctx, cacheKey, &config, 0)
+	f.cache.SetJSON(ctx, cacheKey, &config, database.ReplicaCacheTTL)
 
 	return &config, nil
 }
 
-// buildRoleSelectMenu builds a role select menu.
-func (f *Feature) buildRoleSelectMenu(ctx context.Context, guildID string, s *discordgo.Session, customIDPrefix, placeholderKey string) []discordgo.MessageComponent {
+// currentConfig returns the saved configuration, or an empty one when the
+// guild has none, for pre-selecting the wizard's menus.
+func (f *Feature) currentConfig(ctx context.Context, guildID string) *GenderConfig {
+	config, err := f.getGenderConfig(ctx, guildID)
+	if err != nil || config == nil {
+		return &GenderConfig{GuildID: guildID}
+	}
+	return config
+}
+
+// buildRoleSelectMenu builds a role select menu with defaultRoleID, if set,
+// pre-selected.
+func (f *Feature) buildRoleSelectMenu(ctx context.Context, guildID string, s *discordgo.Session, customIDPrefix, placeholderKey, defaultRoleID string) []discordgo.MessageComponent {
 	return []discordgo.MessageComponent{
 		discordgo.ActionsRow{
 			Components: []discordgo.MessageComponent{
-				discordgo.SelectMenu{
-					MenuType:      discordgo.RoleSelectMenu,
-					CustomID:      customIDPrefix + "select",
-					Placeholder:   f.i18n.T(ctx, guildID, placeholderKey),
-					DefaultValues: []discordgo.SelectMenuDefaultValue{}, // Explicitly empty
-				},
+				discord.RoleSelectMenu(customIDPrefix+"select", f.i18n.T(ctx, guildID, placeholderKey), defaultRoleID),
 			},
 		},
 	}
@@ -33,7 +33,7 @@ func New(deps Dependencies) (*Feature, error) {
 
 	return &Feature{
 		i18n:   deps.I18n,
-		logger: deps.Logger,
+		logger: deps.Logger.Named(featureName),
 	}, nil
 }
 
@@ -29,7 +29,7 @@ func New(deps Dependencies) (*Feature, error) {
 
 	return &Feature{
 		i18n:   deps.I18n,
-		logger: deps.Logger,
+		logger: deps.Logger.Named(featureName),
 	}, nil
 }
 
@@ -63,7 +63,7 @@ func (f *Feature) RegisterCommands() []*discordgo.ApplicationCommand {
 // GetMenuButton returns the menu button for this feature.
 func (f *Feature) GetMenuButton() *bot.MenuButton {
 	return &bot.MenuButton{
-		Label:       "🌐 Language Settings",
+		Label:       shared.WithEmoji(shared.EmojiLanguage, "Language Settings"),
 		CustomID:    "menu:language:setup",
 		Tier:        3,
 		Category:    "admin",
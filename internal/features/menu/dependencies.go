@@ -17,6 +17,12 @@ type InitChecker interface {
 	StartInitWizard(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate, missing []string) error
 }
 
+// SetupStatus reports whether the settings behind each setup button are in
+// place, keyed by the button's CustomID.
+type SetupStatus interface {
+	SetupBadges(ctx context.Context, guildID string) (map[string]bool, error)
+}
+
 // FeatureRegistry provides access to registered features.
 type FeatureRegistry interface {
 	GetAllFeatures() []bot.Feature
@@ -26,6 +32,7 @@ type FeatureRegistry interface {
 type Dependencies struct {
 	Registry FeatureRegistry
 	Init     InitChecker
+	Status   SetupStatus // Optional; without it setup buttons have no badge
 	I18n     i18n.I18n
 	Logger   logger.Logger
 }
@@ -19,6 +19,7 @@ const featureName = "menu"
 type Feature struct {
 	registry FeatureRegistry
 	init     InitChecker
+	status   SetupStatus
 	i18n     i18n.I18n
 	logger   logger.Logger
 }
@@ -32,8 +33,9 @@ func New(deps Dependencies) (*Feature, error) {
 	return &Feature{
 		registry: deps.Registry,
 		init:     deps.Init,
+		status:   deps.Status,
 		i18n:     deps.I18n,
-		logger:   deps.Logger,
+		logger:   deps.Logger.Named(featureName),
 	}, nil
 }
 
@@ -276,32 +278,34 @@ func (f *Feature) buildCategoryButtons(ctx context.Context, category, guildID st
 func (f *Feature) buildFeatureButtons(ctx context.Context, guildID, category, subCategory string, isAdmin bool) []discordgo.MessageComponent {
 	components := []discordgo.MessageComponent{}
 	buttons := []discordgo.MessageComponent{}
+	badges := f.setupBadges(ctx, guildID)
 	
 	// Collect features for this sub-category
 	for _, feature := range f.registry.GetAllFeatures() {
-		btn := feature.GetMenuButton()
-		if btn == nil {
-			continue
-		}
-		
-		// Filter by category and subcategory
-		if btn.Category != category || btn.SubCategory != subCategory {
-			continue
-		}
-		
-		// Filter by permission
-		if btn.AdminOnly && !isAdmin {
-			continue
+		for idx, btn := range menuButtons(feature) {
+			// Filter by category and subcategory
+			if btn.Category != category || btn.SubCategory != subCategory {
+				continue
+			}
+			
+			// Filter by permission
+			if btn.AdminOnly && !isAdmin {
+				continue
+			}
+			
+			// Translate button label; extra buttons are keyed by their action
+			labelKey := feature.Name()
+			if idx > 0 {
+				labelKey += "_" + btn.CustomID[strings.LastIndex(btn.CustomID, ":")+1:]
+			}
+			label := withBadge(f.translateFeatureLabel(ctx, guildID, labelKey, btn.Label), badges, btn.CustomID)
+			
+			buttons = append(buttons, discordgo.Button{
+				Label:    label,
+				Style:    discordgo.PrimaryButton,
+				CustomID: btn.CustomID,
+			})
 		}
-		
-		// Translate button label
-		label := f.translateFeatureLabel(ctx, guildID, feature.Name(), btn.Label)
-		
-		buttons = append(buttons, discordgo.Button{
-			Label:    label,
-			Style:    discordgo.PrimaryButton,
-			CustomID: btn.CustomID,
-		})
 	}
 	
 	// Add feature buttons (max 5 per row)
@@ -338,6 +342,48 @@ func (f *Feature) buildFeatureButtons(ctx context.Context, guildID, category, su
 	return components
 }
 
+// setupBadges returns which setup buttons' settings are in place. Without a
+// status source, or if it fails, buttons are shown without badges.
+func (f *Feature) setupBadges(ctx context.Context, guildID string) map[string]bool {
+	if f.status == nil {
+		return nil
+	}
+	badges, err := f.status.SetupBadges(ctx, guildID)
+	if err != nil {
+		f.logger.Warn("failed to get setup status", "guild_id", guildID, "error", err)
+		return nil
+	}
+	return badges
+}
+
+// withBadge appends ✅ or ⚠️ to a setup button's label. Buttons without a
+// status are left as they are.
+func withBadge(label string, badges map[string]bool, customID string) string {
+	set, ok := badges[customID]
+	if !ok {
+		return label
+	}
+	key := shared.EmojiMissing
+	if set {
+		key = shared.EmojiConfigured
+	}
+	if e := shared.Emoji[key]; e != "" {
+		return label + " " + e
+	}
+	return label
+}
+
+// menuButtons returns the buttons a feature contributes to /menu.
+func menuButtons(feature bot.Feature) []*bot.MenuButton {
+	if mf, ok := feature.(bot.MenuButtonsFeature); ok {
+		return mf.GetMenuButtons()
+	}
+	if btn := feature.GetMenuButton(); btn != nil {
+		return []*bot.MenuButton{btn}
+	}
+	return nil
+}
+
 // translateFeatureLabel translates feature button label.
 func (f *Feature) translateFeatureLabel(ctx context.Context, guildID, featureName, fallback string) string {
 	key := fmt.Sprintf("menu.features.%s", featureName)
@@ -1,4 +1,4 @@
-package otherroles1
+package otherroles
 
 import (
 	"errors"
@@ -9,7 +9,7 @@ import (
 	"welcomebot/internal/core/logger"
 )
 
-// Dependencies contains all required dependencies for the other roles 1 feature.
+// Dependencies contains all required dependencies for the other roles feature.
 type Dependencies struct {
 	DB     database.Client
 	Cache  cache.Client
@@ -33,4 +33,3 @@ func (d Dependencies) Validate() error {
 	}
 	return nil
 }
-
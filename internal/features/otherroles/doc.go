@@ -0,0 +1,8 @@
+// Package otherroles provides other roles configuration functionality.
+//
+// It allows administrators to configure all other roles for guild members
+// (エロイプ, 寝落ち, DM, フレンド and イベント) in one wizard, replacing the
+// former otherroles1 and otherroles2 features. Their menu buttons and
+// custom IDs still open this wizard.
+// The configuration is stored in both PostgreSQL and Redis cache.
+package otherroles
@@ -0,0 +1,453 @@
+package otherroles
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"welcomebot/internal/bot"
+	"welcomebot/internal/core/cache"
+	"welcomebot/internal/core/database"
+	"welcomebot/internal/core/discord"
+	"welcomebot/internal/core/i18n"
+	"welcomebot/internal/core/logger"
+	"welcomebot/internal/shared"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+const featureName = "otherroles"
+
+// legacyPrefixes are the custom ID prefixes of the former otherroles1 and
+// otherroles2 features. Their buttons may still be on old messages, so
+// they open this wizard instead.
+var legacyPrefixes = []string{
+	"menu:otherroles1:", "menu:otherroles2:",
+	"otherroles1:", "otherroles2:",
+}
+
+// Feature implements other roles configuration.
+type Feature struct {
+	db     database.Client
+	cache  cache.Client
+	i18n   i18n.I18n
+	logger logger.Logger
+}
+
+// New creates a new other roles feature.
+func New(deps Dependencies) (*Feature, error) {
+	if err := deps.Validate(); err != nil {
+		return nil, fmt.Errorf("validate dependencies: %w", err)
+	}
+
+	return &Feature{
+		db:     deps.DB,
+		cache:  deps.Cache,
+		i18n:   deps.I18n,
+		logger: deps.Logger.Named(featureName),
+	}, nil
+}
+
+// Name returns the feature name.
+func (f *Feature) Name() string {
+	return featureName
+}
+
+// HandleInteraction handles other roles configuration interactions.
+func (f *Feature) HandleInteraction(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	customID := extractCustomID(i)
+	guildID := i.GuildID
+
+	if customID == "menu:otherroles:setup" {
+		return f.startWizard(ctx, s, i)
+	}
+
+	if customID == "otherroles:confirm_overwrite" {
+		return f.editWizard(ctx, s, i)
+	}
+
+	// Reset: confirm, then delete the configuration
+	if customID == "otherroles:reset" {
+		return f.showResetConfirmation(ctx, s, i)
+	}
+
+	if customID == "otherroles:reset:confirm" {
+		return f.resetConfig(ctx, s, i)
+	}
+
+	if customID == "otherroles:cancel" {
+		return f.respondCancelled(ctx, s, i, guildID)
+	}
+
+	if strings.HasPrefix(customID, "otherroles:role:") {
+		return f.handleRoleSelection(ctx, s, i, customID)
+	}
+
+	for _, prefix := range legacyPrefixes {
+		if strings.HasPrefix(customID, prefix) {
+			f.logger.Debug("deprecated other roles button used", "custom_id", customID)
+			return f.startWizard(ctx, s, i)
+		}
+	}
+
+	return bot.ErrNotHandled
+}
+
+// RegisterCommands returns slash commands for this feature.
+func (f *Feature) RegisterCommands() []*discordgo.ApplicationCommand {
+	return nil
+}
+
+// GetMenuButton returns the menu button for this feature.
+func (f *Feature) GetMenuButton() *bot.MenuButton {
+	return &bot.MenuButton{
+		Label:       shared.WithEmoji(shared.EmojiOtherRoles, "Set Other Roles"),
+		CustomID:    "menu:otherroles:setup",
+		Tier:        3,
+		Category:    "admin",
+		SubCategory: "configuration",
+		AdminOnly:   true,
+		IsCategory:  false,
+	}
+}
+
+func (f *Feature) getWizardState(ctx context.Context, guildID string) (*WizardState, error) {
+	key := fmt.Sprintf("welcomebot:otherroles:wizard:%s", guildID)
+	var state WizardState
+	if err := f.cache.GetJSON(ctx, key, &state); err != nil {
+		return nil, err
+	}
+	return &state, nil
+}
+
+func (f *Feature) saveWizardState(ctx context.Context, state *WizardState) error {
+	key := fmt.Sprintf("welcomebot:otherroles:wizard:%s", state.GuildID)
+	return f.cache.SetJSON(ctx, key, state, 30*time.Minute)
+}
+
+func (f *Feature) deleteWizardState(ctx context.Context, guildID string) error {
+	key := fmt.Sprintf("welcomebot:otherroles:wizard:%s", guildID)
+	return f.cache.Delete(ctx, key)
+}
+
+// wizardStateAt returns the wizard state if the wizard is on step n.
+// Menus of earlier steps stay on screen after a restart, and picking from
+// one must not overwrite the current run.
+func (f *Feature) wizardStateAt(ctx context.Context, guildID string, n int) (*WizardState, error) {
+	state, err := f.getWizardState(ctx, guildID)
+	if errors.Is(err, cache.ErrNotFound) {
+		return nil, bot.ErrWizardExpired
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get wizard state: %w", err)
+	}
+	if state.CurrentStep != n {
+		f.logger.Info("outdated wizard panel used",
+			"guild_id", guildID,
+			"panel_step", roleSteps[n].id,
+			"current_step", state.CurrentStep,
+		)
+		return nil, bot.ErrWizardOutdated
+	}
+	return state, nil
+}
+
+func (f *Feature) startWizard(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	guildID := i.GuildID
+
+	config, err := f.getOtherRolesConfig(ctx, guildID)
+	if err == nil && config != nil && config.configured() {
+		return f.showOverwriteConfirmation(ctx, s, i, config)
+	}
+
+	state := &WizardState{GuildID: guildID, CurrentStep: 0}
+	state.Config.GuildID = guildID
+	if err := f.saveWizardState(ctx, state); err != nil {
+		f.logger.Error("failed to save wizard state", "error", err)
+	}
+
+	return f.showStep(ctx, s, i, state)
+}
+
+// editWizard restarts the wizard from an existing configuration, so each
+// step's menu opens with the currently configured role selected.
+func (f *Feature) editWizard(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	guildID := i.GuildID
+
+	state := &WizardState{GuildID: guildID, CurrentStep: 0}
+	if config, err := f.getOtherRolesConfig(ctx, guildID); err == nil && config != nil {
+		state.Config = *config
+	}
+	state.Config.GuildID = guildID
+	if err := f.saveWizardState(ctx, state); err != nil {
+		f.logger.Error("failed to save wizard state", "error", err)
+	}
+
+	return f.showStep(ctx, s, i, state)
+}
+
+// configured reports whether any role is set.
+func (c *OtherRolesConfig) configured() bool {
+	for _, step := range roleSteps {
+		if *step.field(c) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+func (f *Feature) showOverwriteConfirmation(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate, config *OtherRolesConfig) error {
+	guildID := i.GuildID
+
+	// List every role so admins see what the wizard will overwrite
+	var lines []string
+	for _, step := range roleSteps {
+		lines = append(lines, fmt.Sprintf("%s: %s", f.i18n.T(ctx, guildID, step.label), discord.RoleMention(*step.field(config))))
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Title: f.i18n.T(ctx, guildID, "otherroles.overwrite_title"),
+		Description: f.i18n.TWithArgs(ctx, guildID, "otherroles.current_config", map[string]string{
+			"roles": strings.Join(lines, "\n"),
+		}),
+		Color: int(shared.ColorWarning),
+	}
+
+	components := []discordgo.MessageComponent{
+		discordgo.ActionsRow{
+			Components: []discordgo.MessageComponent{
+				discordgo.Button{
+					Label:    f.i18n.T(ctx, guildID, "otherroles.reconfigure"),
+					Style:    discordgo.DangerButton,
+					CustomID: "otherroles:confirm_overwrite",
+				},
+				discordgo.Button{
+					Label:    f.i18n.T(ctx, guildID, "common.reset"),
+					Style:    discordgo.SecondaryButton,
+					CustomID: "otherroles:reset",
+				},
+				discordgo.Button{
+					Label:    f.i18n.T(ctx, guildID, "common.cancel"),
+					Style:    discordgo.SecondaryButton,
+					CustomID: "otherroles:cancel",
+				},
+			},
+		},
+	}
+
+	return respond(s, i, embed, components)
+}
+
+// showStep shows the role menu for the state's current step.
+func (f *Feature) showStep(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate, state *WizardState) error {
+	guildID := i.GuildID
+	step := roleSteps[state.CurrentStep]
+	args := map[string]string{
+		"step":  strconv.Itoa(state.CurrentStep + 1),
+		"total": strconv.Itoa(len(roleSteps)),
+		"role":  f.i18n.T(ctx, guildID, step.label),
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Title:       f.i18n.TWithArgs(ctx, guildID, "otherroles.step_title", args),
+		Description: f.i18n.TWithArgs(ctx, guildID, "otherroles.step_description", args),
+		Color:       int(shared.ColorInfo),
+	}
+	components := []discordgo.MessageComponent{
+		discordgo.ActionsRow{
+			Components: []discordgo.MessageComponent{
+				discord.RoleSelectMenu("otherroles:role:"+step.id+":select",
+					f.i18n.TWithArgs(ctx, guildID, "otherroles.select_role", args), *step.field(&state.Config)),
+			},
+		},
+	}
+	return respond(s, i, embed, components)
+}
+
+// handleRoleSelection records the role chosen for a step and shows the
+// next one. The last step saves the whole configuration.
+func (f *Feature) handleRoleSelection(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate, customID string) error {
+	guildID := i.GuildID
+	values := i.MessageComponentData().Values
+	if len(values) == 0 {
+		return bot.ErrNoRoleSelected
+	}
+
+	id := strings.TrimSuffix(strings.TrimPrefix(customID, "otherroles:role:"), ":select")
+	n := stepIndex(id)
+	if n < 0 {
+		return fmt.Errorf("unknown other roles step %q", id)
+	}
+
+	// The first step can begin again after the state expired
+	state, err := f.wizardStateAt(ctx, guildID, n)
+	if n == 0 && errors.Is(err, bot.ErrWizardExpired) {
+		state, err = &WizardState{GuildID: guildID}, nil
+	}
+	if err != nil {
+		return err
+	}
+	state.Config.GuildID = guildID
+	*roleSteps[n].field(&state.Config) = values[0]
+
+	if n+1 < len(roleSteps) {
+		state.CurrentStep = n + 1
+		if err := f.saveWizardState(ctx, state); err != nil {
+			f.logger.Error("failed to save wizard state", "error", err)
+		}
+		return f.showStep(ctx, s, i, state)
+	}
+
+	if err := f.saveOtherRolesConfig(ctx, &state.Config); err != nil {
+		return f.respondError(ctx, s, i, guildID, err)
+	}
+
+	if err := f.deleteWizardState(ctx, guildID); err != nil {
+		f.logger.Error("failed to delete wizard state", "error", err)
+	}
+
+	return f.respondSuccess(ctx, s, i, guildID)
+}
+
+// saveOtherRolesConfig writes every role in one statement.
+func (f *Feature) saveOtherRolesConfig(ctx context.Context, config *OtherRolesConfig) error {
+	query := `
+		INSERT INTO guild_other_roles_config (
+			guild_id, ero_ok_role_id, ero_ng_role_id,
+			neochi_ok_role_id, neochi_ng_role_id, neochi_disconnect_role_id,
+			dm_ok_role_id, dm_ng_role_id, friend_ok_role_id, friend_ng_role_id,
+			bunnyclub_event_role_id, user_event_role_id, updated_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, NOW())
+		ON CONFLICT (guild_id)
+		DO UPDATE SET
+			ero_ok_role_id = $2,
+			ero_ng_role_id = $3,
+			neochi_ok_role_id = $4,
+			neochi_ng_role_id = $5,
+			neochi_disconnect_role_id = $6,
+			dm_ok_role_id = $7,
+			dm_ng_role_id = $8,
+			friend_ok_role_id = $9,
+			friend_ng_role_id = $10,
+			bunnyclub_event_role_id = $11,
+			user_event_role_id = $12,
+			updated_at = NOW()
+	`
+
+	_, err := f.db.Exec(ctx, query,
+		config.GuildID,
+		config.EroOKRoleID, config.EroNGRoleID,
+		config.NeochiOKRoleID, config.NeochiNGRoleID, config.NeochiDisconnectRoleID,
+		config.DMOKRoleID, config.DMNGRoleID,
+		config.FriendOKRoleID, config.FriendNGRoleID,
+		config.BunnyclubEventRoleID, config.UserEventRoleID,
+	)
+	if err != nil {
+		return fmt.Errorf("save to database: %w", err)
+	}
+
+	config.UpdatedAt = time.Now()
+	cacheKey := cacheKeyPrefix + config.GuildID
+	if err := f.cache.SetJSON(ctx, cacheKey, config, 0); err != nil {
+		f.logger.Warn("failed to cache other roles config", "error", err)
+	}
+
+	f.logger.Info("other roles config saved", "guild_id", config.GuildID)
+	return nil
+}
+
+func (f *Feature) getOtherRolesConfig(ctx context.Context, guildID string) (*OtherRolesConfig, error) {
+	cacheKey := cacheKeyPrefix + guildID
+
+	var config OtherRolesConfig
+	if err := f.cache.GetJSON(ctx, cacheKey, &config); err == nil {
+		return &config, nil
+	}
+
+	query := `
+		SELECT guild_id, ero_ok_role_id, ero_ng_role_id,
+		       neochi_ok_role_id, neochi_ng_role_id, neochi_disconnect_role_id,
+		       dm_ok_role_id, dm_ng_role_id, friend_ok_role_id, friend_ng_role_id,
+		       bunnyclub_event_role_id, user_event_role_id,
+		       created_at, updated_at
+		FROM guild_other_roles_config
+		WHERE guild_id = $1
+	`
+	row := f.db.QueryRow(database.ReadFromReplica(ctx), query, guildID)
+
+	roles := make([]*string, len(roleSteps))
+	dest := []interface{}{&config.GuildID}
+	for n := range roles {
+		dest = append(dest, &roles[n])
+	}
+	dest = append(dest, &config.CreatedAt, &config.UpdatedAt)
+	if err := row.Scan(dest...); err != nil {
+		return nil, err
+	}
+
+	// roleSteps is in column order
+	for n, step := range roleSteps {
+		if roles[n] != nil {
+			*step.field(&config) = *roles[n]
+		}
+	}
+
+	f.cache.SetJSON(ctx, cacheKey, &config, database.ReplicaCacheTTL)
+	return &config, nil
+}
+
+func (f *Feature) respondSuccess(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate, guildID string) error {
+	embed := &discordgo.MessageEmbed{
+		Title:       f.i18n.T(ctx, guildID, "common.success"),
+		Description: f.i18n.T(ctx, guildID, "otherroles.success"),
+		Color:       int(shared.ColorSuccess),
+	}
+	return respond(s, i, embed, []discordgo.MessageComponent{})
+}
+
+func (f *Feature) respondError(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate, guildID string, err error) error {
+	f.logger.Error("other roles configuration error", "error", err)
+	embed := &discordgo.MessageEmbed{
+		Title:       f.i18n.T(ctx, guildID, "common.error"),
+		Description: f.i18n.T(ctx, guildID, "otherroles.error_save"),
+		Color:       int(shared.ColorError),
+	}
+	return s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Embeds: []*discordgo.MessageEmbed{embed},
+			Flags:  discordgo.MessageFlagsEphemeral,
+		},
+	})
+}
+
+func (f *Feature) respondCancelled(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate, guildID string) error {
+	embed := &discordgo.MessageEmbed{
+		Title:       f.i18n.T(ctx, guildID, "common.cancelled"),
+		Description: f.i18n.T(ctx, guildID, "otherroles.cancelled"),
+		Color:       int(shared.ColorInfo),
+	}
+	return respond(s, i, embed, []discordgo.MessageComponent{})
+}
+
+func respond(s *discordgo.Session, i *discordgo.InteractionCreate, embed *discordgo.MessageEmbed, components []discordgo.MessageComponent) error {
+	return s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseUpdateMessage,
+		Data: &discordgo.InteractionResponseData{
+			Embeds:     []*discordgo.MessageEmbed{embed},
+			Components: components,
+			Flags:      discordgo.MessageFlagsEphemeral,
+		},
+	})
+}
+
+func extractCustomID(i *discordgo.InteractionCreate) string {
+	if i.Type == discordgo.InteractionMessageComponent {
+		return i.MessageComponentData().CustomID
+	}
+	return ""
+}
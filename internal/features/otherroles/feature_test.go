@@ -0,0 +1,196 @@
+package otherroles
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"welcomebot/internal/bot"
+	"welcomebot/internal/fakes"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func TestNew(t *testing.T) {
+	deps := Dependencies{}
+	_, err := New(deps)
+	if err == nil {
+		t.Error("expected error for empty dependencies, got nil")
+	}
+}
+
+func TestName(t *testing.T) {
+	f := &Feature{}
+	if f.Name() != "otherroles" {
+		t.Errorf("expected name 'otherroles', got '%s'", f.Name())
+	}
+}
+
+func TestRegisterCommands(t *testing.T) {
+	f := &Feature{}
+	commands := f.RegisterCommands()
+	if commands != nil {
+		t.Error("expected nil commands for menu-driven feature")
+	}
+}
+
+func TestGetMenuButton(t *testing.T) {
+	f := &Feature{}
+	button := f.GetMenuButton()
+	if button == nil {
+		t.Fatal("expected menu button, got nil")
+	}
+	if button.CustomID != "menu:otherroles:setup" {
+		t.Errorf("expected custom ID 'menu:otherroles:setup', got '%s'", button.CustomID)
+	}
+	if button.Category != "admin" {
+		t.Errorf("expected category 'admin', got '%s'", button.Category)
+	}
+	if button.SubCategory != "configuration" {
+		t.Errorf("expected subcategory 'configuration', got '%s'", button.SubCategory)
+	}
+	if !button.AdminOnly {
+		t.Error("expected AdminOnly to be true")
+	}
+}
+
+func componentInteraction(customID string, values ...string) *discordgo.InteractionCreate {
+	return &discordgo.InteractionCreate{Interaction: &discordgo.Interaction{
+		ID: "interaction-1", Token: "token", GuildID: "guild-1",
+		Type: discordgo.InteractionMessageComponent,
+		Data: discordgo.MessageComponentInteractionData{CustomID: customID, Values: values},
+	}}
+}
+
+// lastMenuID returns the custom ID of the select menu in the last
+// interaction response.
+func lastMenuID(t *testing.T, dc *fakes.Discord) string {
+	t.Helper()
+	var callback struct {
+		Data struct {
+			Components []struct {
+				Components []struct {
+					CustomID string `json:"custom_id"`
+				} `json:"components"`
+			} `json:"components"`
+		} `json:"data"`
+	}
+	requests := dc.Requests()
+	if len(requests) == 0 {
+		t.Fatal("expected an interaction response")
+	}
+	if err := json.Unmarshal(requests[len(requests)-1].Body, &callback); err != nil {
+		t.Fatal(err)
+	}
+	if len(callback.Data.Components) == 0 {
+		return ""
+	}
+	return callback.Data.Components[0].Components[0].CustomID
+}
+
+func TestWizardSavesAllRolesOnce(t *testing.T) {
+	ctx := context.Background()
+	db, cache, dc := fakes.NewDB(), fakes.NewCache(), fakes.NewDiscord()
+	f := &Feature{db: db, cache: cache, i18n: fakes.I18n{}, logger: fakes.Logger{}}
+
+	// Start from the editor so no config lookup hits the database
+	_ = cache.SetJSON(ctx, cacheKeyPrefix+"guild-1", &OtherRolesConfig{GuildID: "guild-1"}, 0)
+	if err := f.HandleInteraction(ctx, dc.Session(), componentInteraction("otherroles:confirm_overwrite")); err != nil {
+		t.Fatalf("start wizard: %v", err)
+	}
+
+	for n, step := range roleSteps {
+		customID := lastMenuID(t, dc)
+		if want := "otherroles:role:" + step.id + ":select"; customID != want {
+			t.Fatalf("step %d: expected menu %q, got %q", n+1, want, customID)
+		}
+		if len(db.Execs()) != 0 {
+			t.Fatalf("step %d: expected nothing saved before the last step", n+1)
+		}
+		if err := f.HandleInteraction(ctx, dc.Session(), componentInteraction(customID, "role-"+step.id)); err != nil {
+			t.Fatalf("step %d: %v", n+1, err)
+		}
+	}
+
+	execs := db.Execs()
+	if len(execs) != 1 || !strings.Contains(execs[0].Query, "INSERT INTO guild_other_roles_config") {
+		t.Fatalf("expected a single upsert, got %+v", execs)
+	}
+	if len(execs[0].Args) != 12 || execs[0].Args[1] != "role-ero_ok" || execs[0].Args[11] != "role-user_event" {
+		t.Errorf("expected every role in one write, got %v", execs[0].Args)
+	}
+	if _, err := f.getWizardState(ctx, "guild-1"); err == nil {
+		t.Error("expected wizard state to be removed after saving")
+	}
+}
+
+func TestLegacyButtonsOpenCombinedWizard(t *testing.T) {
+	ctx := context.Background()
+	for _, customID := range []string{"menu:otherroles1:setup", "menu:otherroles2:setup", "otherroles2:dm_ok_role:select"} {
+		cache, dc := fakes.NewCache(), fakes.NewDiscord()
+		f := &Feature{db: fakes.NewDB(), cache: cache, i18n: fakes.I18n{}, logger: fakes.Logger{}}
+		_ = cache.SetJSON(ctx, cacheKeyPrefix+"guild-1", &OtherRolesConfig{GuildID: "guild-1"}, 0)
+
+		if err := f.HandleInteraction(ctx, dc.Session(), componentInteraction(customID)); err != nil {
+			t.Fatalf("%s: %v", customID, err)
+		}
+		if got := lastMenuID(t, dc); got != "otherroles:role:ero_ok:select" {
+			t.Errorf("%s: expected the combined wizard's first step, got %q", customID, got)
+		}
+	}
+}
+
+func TestWizardRejectsOutdatedPanels(t *testing.T) {
+	ctx := context.Background()
+	second := "otherroles:role:" + roleSteps[1].id + ":select"
+
+	tests := []struct {
+		name  string
+		state *WizardState
+		want  error
+	}{
+		{"expired", nil, bot.ErrWizardExpired},
+		{"restarted", &WizardState{GuildID: "guild-1", CurrentStep: 0}, bot.ErrWizardOutdated},
+		{"moved on", &WizardState{GuildID: "guild-1", CurrentStep: 4}, bot.ErrWizardOutdated},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cache, dc := fakes.NewCache(), fakes.NewDiscord()
+			f := &Feature{cache: cache, i18n: fakes.I18n{}, logger: fakes.Logger{}}
+			if tt.state != nil {
+				if err := f.saveWizardState(ctx, tt.state); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			err := f.HandleInteraction(ctx, dc.Session(), componentInteraction(second, "role-1"))
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("expected %v, got %v", tt.want, err)
+			}
+			if state, err := f.getWizardState(ctx, "guild-1"); err == nil && (*roleSteps[1].field(&state.Config) != "" || state.CurrentStep != tt.state.CurrentStep) {
+				t.Errorf("expected the wizard state to be left alone, got %+v", state)
+			}
+		})
+	}
+}
+
+func TestWizardFirstStepStartsAfterExpiry(t *testing.T) {
+	ctx := context.Background()
+	cache, dc := fakes.NewCache(), fakes.NewDiscord()
+	f := &Feature{cache: cache, i18n: fakes.I18n{}, logger: fakes.Logger{}}
+
+	first := "otherroles:role:" + roleSteps[0].id + ":select"
+	if err := f.HandleInteraction(ctx, dc.Session(), componentInteraction(first, "role-1")); err != nil {
+		t.Fatalf("HandleInteraction: %v", err)
+	}
+
+	state, err := f.getWizardState(ctx, "guild-1")
+	if err != nil {
+		t.Fatalf("expected a new wizard state: %v", err)
+	}
+	if *roleSteps[0].field(&state.Config) != "role-1" || state.CurrentStep != 1 {
+		t.Errorf("expected the role saved and the second step next, got %+v", state)
+	}
+}
@@ -0,0 +1,29 @@
+package otherroles
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"welcomebot/internal/fakes"
+)
+
+func TestDeleteOtherRolesConfig(t *testing.T) {
+	ctx := context.Background()
+	db, cache := fakes.NewDB(), fakes.NewCache()
+	f := &Feature{db: db, cache: cache, logger: fakes.Logger{}}
+
+	_ = cache.SetJSON(ctx, cacheKeyPrefix+"guild-1", &OtherRolesConfig{GuildID: "guild-1", EroOKRoleID: "r1", DMOKRoleID: "r2"}, 0)
+
+	if err := f.deleteOtherRolesConfig(ctx, "guild-1"); err != nil {
+		t.Fatalf("deleteOtherRolesConfig: %v", err)
+	}
+
+	execs := db.Execs()
+	if len(execs) != 1 || !strings.Contains(execs[0].Query, "DELETE FROM guild_other_roles_config") {
+		t.Fatalf("expected the row to be deleted, got %+v", execs)
+	}
+	if exists, _ := cache.Exists(ctx, cacheKeyPrefix+"guild-1"); exists {
+		t.Error("expected cache entry to be removed")
+	}
+}
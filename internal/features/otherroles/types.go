@@ -0,0 +1,67 @@
+package otherroles
+
+import "time"
+
+const (
+	cacheKeyPrefix = "welcomebot:otherroles:config:"
+)
+
+// OtherRolesConfig represents the other roles configuration for a guild.
+type OtherRolesConfig struct {
+	GuildID string `json:"guild_id"`
+
+	EroOKRoleID            string `json:"ero_ok_role_id,omitempty"`
+	EroNGRoleID            string `json:"ero_ng_role_id,omitempty"`
+	NeochiOKRoleID         string `json:"neochi_ok_role_id,omitempty"`
+	NeochiNGRoleID         string `json:"neochi_ng_role_id,omitempty"`
+	NeochiDisconnectRoleID string `json:"neochi_disconnect_role_id,omitempty"`
+	DMOKRoleID             string `json:"dm_ok_role_id,omitempty"`
+	DMNGRoleID             string `json:"dm_ng_role_id,omitempty"`
+	FriendOKRoleID         string `json:"friend_ok_role_id,omitempty"`
+	FriendNGRoleID         string `json:"friend_ng_role_id,omitempty"`
+	BunnyclubEventRoleID   string `json:"bunnyclub_event_role_id,omitempty"`
+	UserEventRoleID        string `json:"user_event_role_id,omitempty"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// WizardState tracks the configuration wizard progress. Config collects
+// the roles chosen so far.
+type WizardState struct {
+	GuildID     string           `json:"guild_id"`
+	Config      OtherRolesConfig `json:"config"`
+	CurrentStep int              `json:"current_step"`
+}
+
+// roleStep is one wizard step, setting a single role.
+type roleStep struct {
+	id    string // Custom ID segment, e.g. "ero_ok"
+	label string // Translation key of the role name shown to admins
+	field func(*OtherRolesConfig) *string
+}
+
+// roleSteps lists the wizard steps in order.
+var roleSteps = []roleStep{
+	{"ero_ok", "otherroles.role_ero_ok", func(c *OtherRolesConfig) *string { return &c.EroOKRoleID }},
+	{"ero_ng", "otherroles.role_ero_ng", func(c *OtherRolesConfig) *string { return &c.EroNGRoleID }},
+	{"neochi_ok", "otherroles.role_neochi_ok", func(c *OtherRolesConfig) *string { return &c.NeochiOKRoleID }},
+	{"neochi_ng", "otherroles.role_neochi_ng", func(c *OtherRolesConfig) *string { return &c.NeochiNGRoleID }},
+	{"neochi_disconnect", "otherroles.role_neochi_disconnect", func(c *OtherRolesConfig) *string { return &c.NeochiDisconnectRoleID }},
+	{"dm_ok", "otherroles.role_dm_ok", func(c *OtherRolesConfig) *string { return &c.DMOKRoleID }},
+	{"dm_ng", "otherroles.role_dm_ng", func(c *OtherRolesConfig) *string { return &c.DMNGRoleID }},
+	{"friend_ok", "otherroles.role_friend_ok", func(c *OtherRolesConfig) *string { return &c.FriendOKRoleID }},
+	{"friend_ng", "otherroles.role_friend_ng", func(c *OtherRolesConfig) *string { return &c.FriendNGRoleID }},
+	{"bunnyclub_event", "otherroles.role_bunnyclub_event", func(c *OtherRolesConfig) *string { return &c.BunnyclubEventRoleID }},
+	{"user_event", "otherroles.role_user_event", func(c *OtherRolesConfig) *string { return &c.UserEventRoleID }},
+}
+
+// stepIndex returns the position of the step with the given ID, or -1.
+func stepIndex(id string) int {
+	for n, step := range roleSteps {
+		if step.id == id {
+			return n
+		}
+	}
+	return -1
+}
@@ -1,7 +0,0 @@
-// Package otherroles1 provides other roles (set 1) configuration functionality.
-//
-// It allows administrators to configure other roles for guild members
-// (エロイプOK, エロイプNG, 寝落ちOK, 寝落ちNG, 寝落ち切断).
-// The configuration is stored in both PostgreSQL and Redis cache.
-package otherroles1
-
@@ -1,7 +0,0 @@
-// Package otherroles2 provides other roles (set 2) configuration functionality.
-//
-// It allows administrators to configure other roles for guild members
-// (DMOK, DMNG, フレンド OK, フレンド NG, BunnyClub イベント, ユーザーイベント).
-// The configuration is stored in both PostgreSQL and Redis cache.
-package otherroles2
-
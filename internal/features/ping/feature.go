@@ -7,6 +7,7 @@ import (
 
 	"welcomebot/internal/bot"
 	"welcomebot/internal/core/logger"
+	"welcomebot/internal/shared"
 
 	"github.com/bwmarrin/discordgo"
 )
@@ -25,7 +26,7 @@ func New(deps Dependencies) (*Feature, error) {
 	}
 
 	return &Feature{
-		logger: deps.Logger,
+		logger: deps.Logger.Named(featureName),
 	}, nil
 }
 
@@ -91,7 +92,7 @@ func (f *Feature) RegisterCommands() []*discordgo.ApplicationCommand {
 // GetMenuButton returns the menu button for this feature.
 func (f *Feature) GetMenuButton() *bot.MenuButton {
 	return &bot.MenuButton{
-		Label:       "🏓 Ping",
+		Label:       shared.WithEmoji(shared.EmojiPing, "Ping"),
 		CustomID:    "menu:ping",
 		Tier:        3,
 		Category:    "information",
@@ -9,6 +9,7 @@ import (
 	"welcomebot/internal/bot"
 	"welcomebot/internal/core/cache"
 	"welcomebot/internal/core/database"
+	"welcomebot/internal/core/discord"
 	"welcomebot/internal/core/i18n"
 	"welcomebot/internal/core/logger"
 	"welcomebot/internal/shared"
@@ -36,7 +37,7 @@ func New(deps Dependencies) (*Feature, error) {
 		db:     deps.DB,
 		cache:  deps.Cache,
 		i18n:   deps.I18n,
-		logger: deps.Logger,
+		logger: deps.Logger.Named(featureName),
 	}, nil
 }
 
@@ -58,6 +59,14 @@ func (f *Feature) HandleInteraction(ctx context.Context, s *discordgo.Session, i
 		return f.showStep1(ctx, s, i)
 	}
 
+	if customID == "selfintro:post_format" {
+		return f.showPostFormatModal(ctx, s, i)
+	}
+
+	if customID == "selfintro:post_format:modal" {
+		return f.handlePostFormatSubmit(ctx, s, i)
+	}
+
 	if customID == "selfintro:cancel" {
 		return f.respondCancelled(ctx, s, i, guildID)
 	}
@@ -99,13 +108,10 @@ func (f *Feature) startWizard(ctx context.Context, s *discordgo.Session, i *disc
 func (f *Feature) showOverwriteConfirmation(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate, config *SelfIntroConfig) error {
 	guildID := i.GuildID
 
-	maleChannel := fmt.Sprintf("<#%s>", config.MaleChannelID)
-	femaleChannel := fmt.Sprintf("<#%s>", config.FemaleChannelID)
-
 	desc := f.i18n.TWithArgs(ctx, guildID, "selfintro.current_config",
 		map[string]string{
-			"male":   maleChannel,
-			"female": femaleChannel,
+			"male":   discord.ChannelMention(config.MaleChannelID),
+			"female": discord.ChannelMention(config.FemaleChannelID),
 		})
 
 	embed := &discordgo.MessageEmbed{
@@ -122,6 +128,7 @@ func (f *Feature) showOverwriteConfirmation(ctx context.Context, s *discordgo.Se
 					Style:    discordgo.DangerButton,
 					CustomID: "selfintro:confirm_overwrite",
 				},
+				f.postFormatButton(ctx, guildID),
 				discordgo.Button{
 					Label:    f.i18n.T(ctx, guildID, "common.cancel"),
 					Style:    discordgo.SecondaryButton,
@@ -169,7 +176,7 @@ func (f *Feature) showStep2(ctx context.Context, s *discordgo.Session, i *discor
 func (f *Feature) handleMaleChannelSelection(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) error {
 	values := i.MessageComponentData().Values
 	if len(values) == 0 {
-		return fmt.Errorf("no channel selected")
+		return bot.ErrNoChannelSelected
 	}
 
 	maleChannelID := values[0]
@@ -189,7 +196,7 @@ func (f *Feature) handleFemaleChannelSelection(ctx context.Context, s *discordgo
 	values := i.MessageComponentData().Values
 
 	if len(values) == 0 {
-		return fmt.Errorf("no channel selected")
+		return bot.ErrNoChannelSelected
 	}
 
 	femaleChannelID := values[0]
@@ -230,6 +237,12 @@ func (f *Feature) saveConfig(ctx context.Context, guildID, maleChannelID, female
 		FemaleChannelID: femaleChannelID,
 		UpdatedAt:       time.Now(),
 	}
+	// The upsert leaves the post format alone; keep it in the cached copy
+	if existing, err := f.getConfig(ctx, guildID); err == nil {
+		config.PostTitle = existing.PostTitle
+		config.PostColor = existing.PostColor
+		config.CreatedAt = existing.CreatedAt
+	}
 
 	cacheKey := cacheKeyPrefix + guildID
 	if err := f.cache.SetJSON(ctx, cacheKey, config, 0); err != nil {
@@ -254,15 +267,23 @@ func (f *Feature) getConfig(ctx context.Context, guildID string) (*SelfIntroConf
 		return &config, nil
 	}
 
-	query := "SELECT guild_id, male_channel_id, female_channel_id, created_at, updated_at FROM guild_selfintro_channels WHERE guild_id = $1"
-	row := f.db.QueryRow(ctx, query, guildID)
+	query := "SELECT guild_id, male_channel_id, female_channel_id, post_title, post_color, created_at, updated_at FROM guild_selfintro_channels WHERE guild_id = $1"
+	row := f.db.QueryRow(database.ReadFromReplica(ctx), query, guildID)
 
-	err := row.Scan(&config.GuildID, &config.MaleChannelID, &config.FemaleChannelID, &config.CreatedAt, &config.UpdatedAt)
+	var postTitle *string
+	var postColor *int
+	err := row.Scan(&config.GuildID, &config.MaleChannelID, &config.FemaleChannelID, &postTitle, &postColor, &config.CreatedAt, &config.UpdatedAt)
 	if err != nil {
 		return nil, err
 	}
+	if postTitle != nil {
+		config.PostTitle = *postTitle
+	}
+	if postColor != nil {
+		config.PostColor = *postColor
+	}
 
-	f.cache.SetJSON(ctx, cacheKey, &config, 0)
+	f.cache.SetJSON(ctx, cacheKey, &config, database.ReplicaCacheTTL)
 
 	return &config, nil
 }
@@ -300,6 +321,8 @@ func extractCustomID(i *discordgo.InteractionCreate) string {
 	switch i.Type {
 	case discordgo.InteractionMessageComponent:
 		return i.MessageComponentData().CustomID
+	case discordgo.InteractionModalSubmit:
+		return i.ModalSubmitData().CustomID
 	default:
 		return ""
 	}
@@ -336,7 +359,13 @@ func (f *Feature) respondSuccess(ctx context.Context, s *discordgo.Session, i *d
 		Color:       int(shared.ColorSuccess),
 	}
 
-	return respond(s, i, embed, []discordgo.MessageComponent{})
+	components := []discordgo.MessageComponent{
+		discordgo.ActionsRow{
+			Components: []discordgo.MessageComponent{f.postFormatButton(ctx, guildID)},
+		},
+	}
+
+	return respond(s, i, embed, components)
 }
 
 // respondValidationError sends validation error.
@@ -0,0 +1,160 @@
+package selfintro
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// maxPostTitleLength is Discord's limit on embed titles.
+const maxPostTitleLength = 256
+
+// postFormatButton opens the intro post format modal.
+func (f *Feature) postFormatButton(ctx context.Context, guildID string) discordgo.Button {
+	return discordgo.Button{
+		Label:    f.i18n.T(ctx, guildID, "selfintro.post_format"),
+		Style:    discordgo.SecondaryButton,
+		CustomID: "selfintro:post_format",
+	}
+}
+
+// showPostFormatModal lets an admin edit how intro posts made on onboarding
+// completion look.
+func (f *Feature) showPostFormatModal(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	guildID := i.GuildID
+
+	config, err := f.getConfig(ctx, guildID)
+	if err != nil {
+		return f.respondError(ctx, s, i, guildID, fmt.Errorf("get config: %w", err))
+	}
+
+	color := ""
+	if config.PostColor != 0 {
+		color = formatColor(config.PostColor)
+	}
+
+	return s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseModal,
+		Data: &discordgo.InteractionResponseData{
+			CustomID: "selfintro:post_format:modal",
+			Title:    f.i18n.T(ctx, guildID, "selfintro.post_format_modal_title"),
+			Components: []discordgo.MessageComponent{
+				discordgo.ActionsRow{
+					Components: []discordgo.MessageComponent{
+						discordgo.TextInput{
+							CustomID:    "title",
+							Label:       f.i18n.T(ctx, guildID, "selfintro.post_title_label"),
+							Style:       discordgo.TextInputShort,
+							Placeholder: f.i18n.T(ctx, guildID, "selfintro.post_title_placeholder"),
+							Value:       config.PostTitle,
+							MaxLength:   maxPostTitleLength,
+						},
+					},
+				},
+				discordgo.ActionsRow{
+					Components: []discordgo.MessageComponent{
+						discordgo.TextInput{
+							CustomID:    "color",
+							Label:       f.i18n.T(ctx, guildID, "selfintro.post_color_label"),
+							Style:       discordgo.TextInputShort,
+							Placeholder: "#5865F2",
+							Value:       color,
+							MaxLength:   7,
+						},
+					},
+				},
+			},
+		},
+	})
+}
+
+// handlePostFormatSubmit saves the intro post format. Empty fields restore
+// the defaults.
+func (f *Feature) handlePostFormatSubmit(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	guildID := i.GuildID
+	data := i.ModalSubmitData()
+
+	title := strings.TrimSpace(modalValue(data, "title"))
+	color, err := parseColor(modalValue(data, "color"))
+	if err != nil {
+		return s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content: f.i18n.T(ctx, guildID, "selfintro.post_color_invalid"),
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		})
+	}
+
+	if err := f.savePostFormat(ctx, guildID, title, color); err != nil {
+		return f.respondError(ctx, s, i, guildID, err)
+	}
+
+	return s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content: f.i18n.T(ctx, guildID, "selfintro.post_format_saved"),
+			Flags:   discordgo.MessageFlagsEphemeral,
+		},
+	})
+}
+
+// savePostFormat stores the intro post format and refreshes the cache.
+func (f *Feature) savePostFormat(ctx context.Context, guildID, title string, color int) error {
+	query := `
+		UPDATE guild_selfintro_channels
+		SET post_title = NULLIF($2, ''), post_color = NULLIF($3, 0), updated_at = NOW()
+		WHERE guild_id = $1
+	`
+	if _, err := f.db.Exec(ctx, query, guildID, title, color); err != nil {
+		return fmt.Errorf("save post format: %w", err)
+	}
+
+	if err := f.cache.Delete(ctx, cacheKeyPrefix+guildID); err != nil {
+		f.logger.Warn("failed to invalidate selfintro config", "error", err)
+	}
+
+	f.logger.Info("selfintro post format configured", "guild_id", guildID)
+	return nil
+}
+
+// parseColor parses a "#RRGGBB" or "RRGGBB" hex color. An empty string is 0,
+// the default color.
+func parseColor(s string) (int, error) {
+	s = strings.TrimPrefix(strings.TrimSpace(s), "#")
+	if s == "" {
+		return 0, nil
+	}
+	if len(s) != 6 {
+		return 0, fmt.Errorf("invalid color %q", s)
+	}
+	color, err := strconv.ParseUint(s, 16, 32)
+	if err != nil {
+		return 0, fmt.Errorf("invalid color %q: %w", s, err)
+	}
+	return int(color), nil
+}
+
+// formatColor renders a color as "#RRGGBB".
+func formatColor(color int) string {
+	return fmt.Sprintf("#%06X", color)
+}
+
+// modalValue returns the value of a text input in a submitted modal.
+func modalValue(data discordgo.ModalSubmitInteractionData, customID string) string {
+	for _, component := range data.Components {
+		row, ok := component.(*discordgo.ActionsRow)
+		if !ok {
+			continue
+		}
+		for _, inner := range row.Components {
+			if input, ok := inner.(*discordgo.TextInput); ok && input.CustomID == customID {
+				return input.Value
+			}
+		}
+	}
+	return ""
+}
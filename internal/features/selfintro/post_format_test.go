@@ -0,0 +1,33 @@
+package selfintro
+
+import "testing"
+
+func TestParseColor(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    int
+		wantErr bool
+	}{
+		{in: "", want: 0},
+		{in: "  ", want: 0},
+		{in: "#5865F2", want: 0x5865F2},
+		{in: "ed4245", want: 0xED4245},
+		{in: "#FFF", wantErr: true},
+		{in: "#GGGGGG", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := parseColor(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseColor(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("parseColor(%q) = %#x, want %#x", tt.in, got, tt.want)
+		}
+	}
+
+	if got := formatColor(0x5865F2); got != "#5865F2" {
+		t.Errorf("formatColor = %q, want #5865F2", got)
+	}
+}
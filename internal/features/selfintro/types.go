@@ -7,6 +7,8 @@ type SelfIntroConfig struct {
 	GuildID         string    `json:"guild_id"`
 	MaleChannelID   string    `json:"male_channel_id"`
 	FemaleChannelID string    `json:"female_channel_id"`
+	PostTitle       string    `json:"post_title,omitempty"` // Intro post title template; {name} is the member
+	PostColor       int       `json:"post_color,omitempty"` // Intro post embed color; 0 uses the default
 	CreatedAt       time.Time `json:"created_at"`
 	UpdatedAt       time.Time `json:"updated_at"`
 }
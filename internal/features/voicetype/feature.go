@@ -2,6 +2,7 @@ package voicetype
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
@@ -9,6 +10,7 @@ import (
 	"welcomebot/internal/bot"
 	"welcomebot/internal/core/cache"
 	"welcomebot/internal/core/database"
+	"welcomebot/internal/core/discord"
 	"welcomebot/internal/core/i18n"
 	"welcomebot/internal/core/logger"
 	"welcomebot/internal/shared"
@@ -36,7 +38,7 @@ func New(deps Dependencies) (*Feature, error) {
 		db:     deps.DB,
 		cache:  deps.Cache,
 		i18n:   deps.I18n,
-		logger: deps.Logger,
+		logger: deps.Logger.Named(featureName),
 	}, nil
 }
 
@@ -57,7 +59,16 @@ func (f *Feature) HandleInteraction(ctx context.Context, s *discordgo.Session, i
 
 	// Overwrite confirmation
 	if customID == "voicetype:confirm_overwrite" {
-		return f.showStep1(ctx, s, i)
+		return f.editWizard(ctx, s, i)
+	}
+
+	// Reset: confirm, then delete the configuration
+	if customID == "voicetype:reset" {
+		return f.showResetConfirmation(ctx, s, i)
+	}
+
+	if customID == "voicetype:reset:confirm" {
+		return f.resetConfig(ctx, s, i)
 	}
 
 	if customID == "voicetype:cancel" {
@@ -100,7 +111,7 @@ func (f *Feature) RegisterCommands() []*discordgo.ApplicationCommand {
 // GetMenuButton returns the menu button for this feature.
 func (f *Feature) GetMenuButton() *bot.MenuButton {
 	return &bot.MenuButton{
-		Label:       "🎵 Set Voice Type Roles",
+		Label:       shared.WithEmoji(shared.EmojiVoiceType, "Set Voice Type Roles"),
 		CustomID:    "menu:voicetype:setup",
 		Tier:        3,
 		Category:    "admin",
@@ -132,6 +143,28 @@ func (f *Feature) deleteWizardState(ctx context.Context, guildID string) error {
 	return f.cache.Delete(ctx, key)
 }
 
+// wizardStateAt returns the wizard state a step panel may apply to. A
+// panel from an earlier run or an earlier step is outdated: applying it
+// would skip the wizard to the wrong step.
+func (f *Feature) wizardStateAt(ctx context.Context, guildID string, step int) (*WizardState, error) {
+	state, err := f.getWizardState(ctx, guildID)
+	if errors.Is(err, cache.ErrNotFound) {
+		return nil, bot.ErrWizardExpired
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get wizard state: %w", err)
+	}
+	if state.CurrentStep != step {
+		f.logger.Info("outdated wizard panel used",
+			"guild_id", guildID,
+			"panel_step", step,
+			"current_step", state.CurrentStep,
+		)
+		return nil, bot.ErrWizardOutdated
+	}
+	return state, nil
+}
+
 // startWizard initiates the voice type configuration wizard.
 func (f *Feature) startWizard(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) error {
 	guildID := i.GuildID
@@ -153,11 +186,43 @@ func (f *Feature) startWizard(ctx context.Context, s *discordgo.Session, i *disc
 	return f.showStep1(ctx, s, i)
 }
 
+// editWizard restarts the wizard from an existing configuration, so each
+// step's menu opens with the currently configured role selected.
+func (f *Feature) editWizard(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	guildID := i.GuildID
+
+	state := &WizardState{GuildID: guildID, CurrentStep: 1}
+	if config, err := f.getVoiceTypeConfig(ctx, guildID); err == nil && config != nil {
+		if err := bot.SeedWizardState(config, state); err != nil {
+			f.logger.Error("failed to seed wizard state", "error", err)
+		}
+	}
+	if err := f.saveWizardState(ctx, state); err != nil {
+		f.logger.Error("failed to save wizard state", "error", err)
+	}
+
+	return f.showStep1(ctx, s, i)
+}
+
 // showOverwriteConfirmation shows confirmation for overwriting existing config.
 func (f *Feature) showOverwriteConfirmation(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate, config *VoiceTypeConfig) error {
 	guildID := i.GuildID
 
-	desc := f.i18n.T(ctx, guildID, "voicetype.current_config")
+	// From high to low pitch, the order the wizard goes through them
+	types := []struct{ key, roleID string }{
+		{"voicetype.label_high", config.HighRoleID},
+		{"voicetype.label_mid_high", config.MidHighRoleID},
+		{"voicetype.label_mid", config.MidRoleID},
+		{"voicetype.label_mid_low", config.MidLowRoleID},
+		{"voicetype.label_low", config.LowRoleID},
+	}
+	lines := make([]string, len(types))
+	for n, t := range types {
+		lines[n] = f.i18n.T(ctx, guildID, t.key) + ": " + discord.RoleMention(t.roleID)
+	}
+	desc := f.i18n.TWithArgs(ctx, guildID, "voicetype.current_config", map[string]string{
+		"roles": strings.Join(lines, "\n"),
+	})
 
 	embed := &discordgo.MessageEmbed{
 		Title:       f.i18n.T(ctx, guildID, "voicetype.overwrite_title"),
@@ -173,6 +238,11 @@ func (f *Feature) showOverwriteConfirmation(ctx context.Context, s *discordgo.Se
 					Style:    discordgo.DangerButton,
 					CustomID: "voicetype:confirm_overwrite",
 				},
+				discordgo.Button{
+					Label:    f.i18n.T(ctx, guildID, "common.reset"),
+					Style:    discordgo.SecondaryButton,
+					CustomID: "voicetype:reset",
+				},
 				discordgo.Button{
 					Label:    f.i18n.T(ctx, guildID, "common.cancel"),
 					Style:    discordgo.SecondaryButton,
@@ -188,6 +258,7 @@ func (f *Feature) showOverwriteConfirmation(ctx context.Context, s *discordgo.Se
 // showStep1 shows 高音 role selection.
 func (f *Feature) showStep1(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) error {
 	guildID := i.GuildID
+	state := bot.WizardDefaults(f.getWizardState(ctx, guildID))
 
 	embed := &discordgo.MessageEmbed{
 		Title:       f.i18n.T(ctx, guildID, "voicetype.step1_title"),
@@ -198,11 +269,8 @@ func (f *Feature) showStep1(ctx context.Context, s *discordgo.Session, i *discor
 	components := []discordgo.MessageComponent{
 		discordgo.ActionsRow{
 			Components: []discordgo.MessageComponent{
-				discordgo.SelectMenu{
-					MenuType:    discordgo.RoleSelectMenu,
-					CustomID:    "voicetype:high_role:select",
-					Placeholder: f.i18n.T(ctx, guildID, "voicetype.select_high_role"),
-				},
+				discord.RoleSelectMenu("voicetype:high_role:select",
+					f.i18n.T(ctx, guildID, "voicetype.select_high_role"), state.HighRoleID),
 			},
 		},
 	}
@@ -213,6 +281,7 @@ func (f *Feature) showStep1(ctx context.Context, s *discordgo.Session, i *discor
 // showStep2 shows 中高音 role selection.
 func (f *Feature) showStep2(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) error {
 	guildID := i.GuildID
+	state := bot.WizardDefaults(f.getWizardState(ctx, guildID))
 
 	embed := &discordgo.MessageEmbed{
 		Title:       f.i18n.T(ctx, guildID, "voicetype.step2_title"),
@@ -223,11 +292,8 @@ func (f *Feature) showStep2(ctx context.Context, s *discordgo.Session, i *discor
 	components := []discordgo.MessageComponent{
 		discordgo.ActionsRow{
 			Components: []discordgo.MessageComponent{
-				discordgo.SelectMenu{
-					MenuType:    discordgo.RoleSelectMenu,
-					CustomID:    "voicetype:mid_high_role:select",
-					Placeholder: f.i18n.T(ctx, guildID, "voicetype.select_mid_high_role"),
-				},
+				discord.RoleSelectMenu("voicetype:mid_high_role:select",
+					f.i18n.T(ctx, guildID, "voicetype.select_mid_high_role"), state.MidHighRoleID),
 			},
 		},
 	}
@@ -238,6 +304,7 @@ func (f *Feature) showStep2(ctx context.Context, s *discordgo.Session, i *discor
 // showStep3 shows 中音 role selection.
 func (f *Feature) showStep3(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) error {
 	guildID := i.GuildID
+	state := bot.WizardDefaults(f.getWizardState(ctx, guildID))
 
 	embed := &discordgo.MessageEmbed{
 		Title:       f.i18n.T(ctx, guildID, "voicetype.step3_title"),
@@ -248,11 +315,8 @@ func (f *Feature) showStep3(ctx context.Context, s *discordgo.Session, i *discor
 	components := []discordgo.MessageComponent{
 		discordgo.ActionsRow{
 			Components: []discordgo.MessageComponent{
-				discordgo.SelectMenu{
-					MenuType:    discordgo.RoleSelectMenu,
-					CustomID:    "voicetype:mid_role:select",
-					Placeholder: f.i18n.T(ctx, guildID, "voicetype.select_mid_role"),
-				},
+				discord.RoleSelectMenu("voicetype:mid_role:select",
+					f.i18n.T(ctx, guildID, "voicetype.select_mid_role"), state.MidRoleID),
 			},
 		},
 	}
@@ -263,6 +327,7 @@ func (f *Feature) showStep3(ctx context.Context, s *discordgo.Session, i *discor
 // showStep4 shows 中低音 role selection.
 func (f *Feature) showStep4(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) error {
 	guildID := i.GuildID
+	state := bot.WizardDefaults(f.getWizardState(ctx, guildID))
 
 	embed := &discordgo.MessageEmbed{
 		Title:       f.i18n.T(ctx, guildID, "voicetype.step4_title"),
@@ -273,11 +338,8 @@ func (f *Feature) showStep4(ctx context.Context, s *discordgo.Session, i *discor
 	components := []discordgo.MessageComponent{
 		discordgo.ActionsRow{
 			Components: []discordgo.MessageComponent{
-				discordgo.SelectMenu{
-					MenuType:    discordgo.RoleSelectMenu,
-					CustomID:    "voicetype:mid_low_role:select",
-					Placeholder: f.i18n.T(ctx, guildID, "voicetype.select_mid_low_role"),
-				},
+				discord.RoleSelectMenu("voicetype:mid_low_role:select",
+					f.i18n.T(ctx, guildID, "voicetype.select_mid_low_role"), state.MidLowRoleID),
 			},
 		},
 	}
@@ -288,6 +350,7 @@ func (f *Feature) showStep4(ctx context.Context, s *discordgo.Session, i *discor
 // showStep5 shows 低音 role selection.
 func (f *Feature) showStep5(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) error {
 	guildID := i.GuildID
+	state := bot.WizardDefaults(f.getWizardState(ctx, guildID))
 
 	embed := &discordgo.MessageEmbed{
 		Title:       f.i18n.T(ctx, guildID, "voicetype.step5_title"),
@@ -298,11 +361,8 @@ func (f *Feature) showStep5(ctx context.Context, s *discordgo.Session, i *discor
 	components := []discordgo.MessageComponent{
 		discordgo.ActionsRow{
 			Components: []discordgo.MessageComponent{
-				discordgo.SelectMenu{
-					MenuType:    discordgo.RoleSelectMenu,
-					CustomID:    "voicetype:low_role:select",
-					Placeholder: f.i18n.T(ctx, guildID, "voicetype.select_low_role"),
-				},
+				discord.RoleSelectMenu("voicetype:low_role:select",
+					f.i18n.T(ctx, guildID, "voicetype.select_low_role"), state.LowRoleID),
 			},
 		},
 	}
@@ -316,15 +376,18 @@ func (f *Feature) handleHighRoleSelection(ctx context.Context, s *discordgo.Sess
 	values := i.MessageComponentData().Values
 
 	if len(values) == 0 {
-		return fmt.Errorf("no role selected")
+		return bot.ErrNoRoleSelected
 	}
 
 	roleID := values[0]
 
-	// Update wizard state
-	state, err := f.getWizardState(ctx, guildID)
+	// Update wizard state. Step 1 can begin again after the state expired.
+	state, err := f.wizardStateAt(ctx, guildID, 1)
+	if errors.Is(err, bot.ErrWizardExpired) {
+		state, err = &WizardState{GuildID: guildID, CurrentStep: 1}, nil
+	}
 	if err != nil {
-		state = &WizardState{GuildID: guildID}
+		return err
 	}
 	state.HighRoleID = roleID
 	state.CurrentStep = 2
@@ -341,15 +404,15 @@ func (f *Feature) handleMidHighRoleSelection(ctx context.Context, s *discordgo.S
 	values := i.MessageComponentData().Values
 
 	if len(values) == 0 {
-		return fmt.Errorf("no role selected")
+		return bot.ErrNoRoleSelected
 	}
 
 	roleID := values[0]
 
 	// Update wizard state
-	state, err := f.getWizardState(ctx, guildID)
+	state, err := f.wizardStateAt(ctx, guildID, 2)
 	if err != nil {
-		return fmt.Errorf("get wizard state: %w", err)
+		return err
 	}
 	state.MidHighRoleID = roleID
 	state.CurrentStep = 3
@@ -366,15 +429,15 @@ func (f *Feature) handleMidRoleSelection(ctx context.Context, s *discordgo.Sessi
 	values := i.MessageComponentData().Values
 
 	if len(values) == 0 {
-		return fmt.Errorf("no role selected")
+		return bot.ErrNoRoleSelected
 	}
 
 	roleID := values[0]
 
 	// Update wizard state
-	state, err := f.getWizardState(ctx, guildID)
+	state, err := f.wizardStateAt(ctx, guildID, 3)
 	if err != nil {
-		return fmt.Errorf("get wizard state: %w", err)
+		return err
 	}
 	state.MidRoleID = roleID
 	state.CurrentStep = 4
@@ -391,15 +454,15 @@ func (f *Feature) handleMidLowRoleSelection(ctx context.Context, s *discordgo.Se
 	values := i.MessageComponentData().Values
 
 	if len(values) == 0 {
-		return fmt.Errorf("no role selected")
+		return bot.ErrNoRoleSelected
 	}
 
 	roleID := values[0]
 
 	// Update wizard state
-	state, err := f.getWizardState(ctx, guildID)
+	state, err := f.wizardStateAt(ctx, guildID, 4)
 	if err != nil {
-		return fmt.Errorf("get wizard state: %w", err)
+		return err
 	}
 	state.MidLowRoleID = roleID
 	state.CurrentStep = 5
@@ -416,15 +479,15 @@ func (f *Feature) handleLowRoleSelection(ctx context.Context, s *discordgo.Sessi
 	values := i.MessageComponentData().Values
 
 	if len(values) == 0 {
-		return fmt.Errorf("no role selected")
+		return bot.ErrNoRoleSelected
 	}
 
 	roleID := values[0]
 
 	// Get final wizard state
-	state, err := f.getWizardState(ctx, guildID)
+	state, err := f.wizardStateAt(ctx, guildID, 5)
 	if err != nil {
-		return fmt.Errorf("get wizard state: %w", err)
+		return err
 	}
 	state.LowRoleID = roleID
 
@@ -509,7 +572,7 @@ func (f *Feature) getVoiceTypeConfig(ctx context.Context, guildID string) (*Voic
 		FROM guild_voice_type_config 
 		WHERE guild_id = $1
 	`
-	row := f.db.QueryRow(ctx, query, guildID)
+	row := f.db.QueryRow(database.ReadFromReplica(ctx), query, guildID)
 
 	var high, midHigh, mid, midLow, low *string
 	err := row.Scan(&config.GuildID,
@@ -535,7 +598,7 @@ func (f *Feature) getVoiceTypeConfig(ctx context.Context, guildID string) (*Voic
 		config.LowRoleID = *low
 	}
 
-	f.cache.SetJSON(ctx, cacheKey, &config, 0)
+	f.cache.SetJSON(ctx, cacheKey, &config, database.ReplicaCacheTTL)
 
 	return &config, nil
 }
@@ -0,0 +1,151 @@
+package welcome
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"welcomebot/internal/core/i18n"
+	"welcomebot/internal/onboard"
+	"welcomebot/internal/shared"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// activeSessionsPageSize is how many sessions are listed per page.
+const activeSessionsPageSize = 10
+
+// activeSession is the part of the worker's cached session JSON shown on
+// the dashboard.
+type activeSession struct {
+	UserID      string `json:"user_id"`
+	SlaveID     string `json:"slave_id"`
+	VCChannelID string `json:"vc_channel_id"`
+	Guide       string `json:"selected_guide"`
+	CurrentStep int    `json:"current_step"`
+	StartedAt   int64  `json:"started_at"` // Unix seconds
+}
+
+// showActiveSessions lists the members currently onboarding. Page buttons
+// re-read the cache, so every page is current.
+func (f *Feature) showActiveSessions(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	guildID := i.GuildID
+
+	page := 0
+	if n, err := strconv.Atoi(strings.TrimPrefix(extractCustomID(i), "welcome:active_sessions:page:")); err == nil {
+		page = n
+	}
+
+	sessions, err := f.activeSessions(ctx, guildID)
+	if err != nil {
+		return f.respondError(ctx, s, i, guildID, err)
+	}
+
+	embed, components := f.renderActiveSessions(ctx, guildID, sessions, page, time.Now())
+	return respond(s, i, embed, components)
+}
+
+// activeSessions loads the guild's cached sessions, longest running first.
+// Sessions that expire or fail to decode while being read are skipped.
+func (f *Feature) activeSessions(ctx context.Context, guildID string) ([]activeSession, error) {
+	keys, err := f.cache.Keys(ctx, sessionKeyPrefix+guildID+":*")
+	if err != nil {
+		return nil, fmt.Errorf("list sessions: %w", err)
+	}
+
+	sessions := make([]activeSession, 0, len(keys))
+	for _, key := range keys {
+		if onboard.IsSessionHeartbeatKey(key) {
+			continue
+		}
+		var session activeSession
+		if err := f.cache.GetJSON(ctx, key, &session); err != nil {
+			continue
+		}
+		sessions = append(sessions, session)
+	}
+
+	sort.Slice(sessions, func(a, b int) bool {
+		if sessions[a].StartedAt != sessions[b].StartedAt {
+			return sessions[a].StartedAt < sessions[b].StartedAt
+		}
+		return sessions[a].UserID < sessions[b].UserID
+	})
+	return sessions, nil
+}
+
+// renderActiveSessions builds one page of the dashboard. Out of range
+// pages are clamped, since sessions may have ended since the last view.
+func (f *Feature) renderActiveSessions(ctx context.Context, guildID string, sessions []activeSession, page int, now time.Time) (*discordgo.MessageEmbed, []discordgo.MessageComponent) {
+	locale, _ := f.i18n.GetGuildLanguage(ctx, guildID)
+
+	embed := &discordgo.MessageEmbed{
+		Title: f.i18n.TWithArgs(ctx, guildID, "welcome.active_sessions_title", map[string]string{
+			"count": i18n.FormatNumber(locale, int64(len(sessions))),
+		}),
+		Color: int(shared.ColorInfo),
+	}
+
+	if len(sessions) == 0 {
+		embed.Description = f.i18n.T(ctx, guildID, "welcome.active_sessions_none")
+		return embed, []discordgo.MessageComponent{}
+	}
+
+	pages := (len(sessions) + activeSessionsPageSize - 1) / activeSessionsPageSize
+	page = max(0, min(page, pages-1))
+
+	start := page * activeSessionsPageSize
+	end := min(start+activeSessionsPageSize, len(sessions))
+
+	lines := make([]string, 0, end-start)
+	for _, session := range sessions[start:end] {
+		step := strconv.Itoa(session.CurrentStep)
+		if session.CurrentStep == 0 {
+			step = f.i18n.T(ctx, guildID, "welcome.active_sessions_choosing_guide")
+		}
+		elapsed := now.Sub(time.Unix(session.StartedAt, 0)).Truncate(time.Second)
+
+		lines = append(lines, f.i18n.TWithArgs(ctx, guildID, "welcome.active_sessions_entry", map[string]string{
+			"user":    fmt.Sprintf("<@%s>", session.UserID),
+			"step":    step,
+			"elapsed": i18n.FormatDuration(locale, elapsed),
+			"slave":   session.SlaveID,
+			"channel": fmt.Sprintf("<#%s>", session.VCChannelID),
+		}))
+	}
+	embed.Description = strings.Join(lines, "\n")
+
+	if pages == 1 {
+		return embed, []discordgo.MessageComponent{}
+	}
+
+	embed.Footer = &discordgo.MessageEmbedFooter{
+		Text: f.i18n.TWithArgs(ctx, guildID, "welcome.active_sessions_page", map[string]string{
+			"page":  strconv.Itoa(page + 1),
+			"pages": strconv.Itoa(pages),
+		}),
+	}
+
+	components := []discordgo.MessageComponent{
+		discordgo.ActionsRow{
+			Components: []discordgo.MessageComponent{
+				discordgo.Button{
+					Label:    f.i18n.T(ctx, guildID, "welcome.active_sessions_previous"),
+					Style:    discordgo.SecondaryButton,
+					CustomID: fmt.Sprintf("welcome:active_sessions:page:%d", page-1),
+					Disabled: page == 0,
+				},
+				discordgo.Button{
+					Label:    f.i18n.T(ctx, guildID, "welcome.active_sessions_next"),
+					Style:    discordgo.SecondaryButton,
+					CustomID: fmt.Sprintf("welcome:active_sessions:page:%d", page+1),
+					Disabled: page == pages-1,
+				},
+			},
+		},
+	}
+	return embed, components
+}
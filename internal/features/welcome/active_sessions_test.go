@@ -0,0 +1,67 @@
+package welcome
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"welcomebot/internal/fakes"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func TestActiveSessions(t *testing.T) {
+	ctx := context.Background()
+	cache := fakes.NewCache()
+	f := &Feature{cache: cache, i18n: fakes.I18n{}, logger: fakes.Logger{}}
+	now := time.Unix(1_700_000_000, 0)
+
+	// Twelve sessions, oldest first after sorting, plus one in another guild
+	for n := 0; n < 12; n++ {
+		session := activeSession{
+			UserID:      fmt.Sprintf("user-%02d", n),
+			SlaveID:     "slave-1",
+			CurrentStep: n % 7,
+			StartedAt:   now.Add(-time.Duration(20-n) * time.Minute).Unix(),
+		}
+		if err := cache.SetJSON(ctx, sessionKeyPrefix+"guild-1:"+session.UserID, session, time.Hour); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := cache.SetJSON(ctx, sessionKeyPrefix+"guild-2:other", activeSession{UserID: "other"}, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+
+	sessions, err := f.activeSessions(ctx, "guild-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sessions) != 12 || sessions[0].UserID != "user-00" {
+		t.Fatalf("expected 12 guild-1 sessions, oldest first; got %d", len(sessions))
+	}
+
+	embed, components := f.renderActiveSessions(ctx, "guild-1", sessions, 0, now)
+	if !strings.Contains(embed.Description, "elapsed=20m") || !strings.Contains(embed.Description, "welcome.active_sessions_choosing_guide") {
+		t.Errorf("unexpected first page: %s", embed.Description)
+	}
+	if lines := strings.Count(embed.Description, "\n") + 1; lines != activeSessionsPageSize {
+		t.Errorf("expected %d sessions on the first page, got %d", activeSessionsPageSize, lines)
+	}
+	buttons := components[0].(discordgo.ActionsRow).Components
+	if !buttons[0].(discordgo.Button).Disabled || buttons[1].(discordgo.Button).Disabled {
+		t.Error("expected only the previous button to be disabled on the first page")
+	}
+
+	// Pages past the end show the last page
+	embed, _ = f.renderActiveSessions(ctx, "guild-1", sessions, 5, now)
+	if !strings.Contains(embed.Description, "user-11") || strings.Contains(embed.Description, "user-00") {
+		t.Errorf("unexpected last page: %s", embed.Description)
+	}
+
+	embed, components = f.renderActiveSessions(ctx, "guild-1", nil, 0, now)
+	if embed.Description != "welcome.active_sessions_none" || len(components) != 0 {
+		t.Errorf("unexpected empty dashboard: %q", embed.Description)
+	}
+}
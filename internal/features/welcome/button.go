@@ -0,0 +1,202 @@
+package welcome
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"welcomebot/internal/core/discord"
+	"welcomebot/internal/shared"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// welcomeButtonVersion is bumped whenever the welcome button message changes
+// in a way posted copies need, such as a new custom ID. Buttons posted by
+// an older version are edited to the current one at startup.
+const welcomeButtonVersion = 1
+
+// Custom IDs of the welcome button. Every version's ID starts onboarding,
+// so a button keeps working until it is refreshed.
+const (
+	legacyWelcomeButtonID = "welcome:start_onboarding" // Before versioning
+	welcomeButtonPrefix   = "welcome:start_onboarding:v"
+)
+
+// welcomeButtonID is the custom ID of the current welcome button.
+var welcomeButtonID = welcomeButtonPrefix + strconv.Itoa(welcomeButtonVersion)
+
+// isWelcomeButtonID reports whether customID is any version of the welcome
+// button, e.g. "welcome:start_onboarding:v1".
+func isWelcomeButtonID(customID string) bool {
+	if customID == legacyWelcomeButtonID {
+		return true
+	}
+	version, ok := strings.CutPrefix(customID, welcomeButtonPrefix)
+	if !ok {
+		return false
+	}
+	_, err := strconv.Atoi(version)
+	return err == nil
+}
+
+// welcomeButtonMessage builds the welcome button message from the guild's
+// current translations.
+func (f *Feature) welcomeButtonMessage(ctx context.Context, guildID string) (*discordgo.MessageEmbed, []discordgo.MessageComponent) {
+	embed := &discordgo.MessageEmbed{
+		Title:       f.i18n.T(ctx, guildID, "welcome.button_title"),
+		Description: f.i18n.T(ctx, guildID, "welcome.button_description"),
+		Color:       int(shared.ColorInfo),
+	}
+
+	components := []discordgo.MessageComponent{
+		discordgo.ActionsRow{
+			Components: []discordgo.MessageComponent{
+				discordgo.Button{
+					Label:    f.i18n.T(ctx, guildID, "welcome.start_button"),
+					Style:    discordgo.PrimaryButton,
+					CustomID: welcomeButtonID,
+					Emoji:    shared.ComponentEmoji(shared.EmojiWelcome),
+				},
+			},
+		},
+	}
+
+	return embed, components
+}
+
+// refreshWelcomeButton edits the posted welcome button message so it picks
+// up changed translations, keeping the message (and its pin) in place.
+func (f *Feature) refreshWelcomeButton(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	guildID := i.GuildID
+
+	config, err := f.getWelcomeConfig(ctx, guildID)
+	if err != nil {
+		return f.respondErrorMessage(ctx, s, i, guildID, "welcome.config_not_found")
+	}
+
+	reposted, err := f.updateWelcomeButton(ctx, config)
+	if err != nil {
+		return f.respondError(ctx, s, i, guildID, err)
+	}
+
+	messageKey := "welcome.refresh_button_done"
+	if reposted {
+		messageKey = "welcome.refresh_button_reposted"
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Title: f.i18n.T(ctx, guildID, "welcome.refresh_button_title"),
+		Description: f.i18n.TWithArgs(ctx, guildID, messageKey, map[string]string{
+			"channel": fmt.Sprintf("<#%s>", config.WelcomeChannelID),
+		}),
+		Color: int(shared.ColorSuccess),
+	}
+	return respond(s, i, embed, []discordgo.MessageComponent{})
+}
+
+// updateWelcomeButton edits the existing welcome button message, or posts a
+// new one if it was deleted or never recorded. It reports whether it posted.
+func (f *Feature) updateWelcomeButton(ctx context.Context, config *WelcomeConfig) (bool, error) {
+	if config.ButtonMessageID != "" {
+		err := f.editWelcomeButton(ctx, config)
+		if err == nil {
+			return false, nil
+		}
+		if !discord.IsNotFound(err) {
+			return false, err
+		}
+		f.logger.Info("welcome button message is gone, reposting", "guild_id", config.GuildID, "message_id", config.ButtonMessageID)
+	}
+
+	if err := f.postWelcomeButton(ctx, config.GuildID, config.WelcomeChannelID); err != nil {
+		return false, fmt.Errorf("post welcome button: %w", err)
+	}
+	return true, nil
+}
+
+// editWelcomeButton rewrites the posted welcome button message as the
+// current version and records that version.
+func (f *Feature) editWelcomeButton(ctx context.Context, config *WelcomeConfig) error {
+	embed, components := f.welcomeButtonMessage(ctx, config.GuildID)
+	embeds := []*discordgo.MessageEmbed{embed}
+
+	_, err := f.session.ChannelMessageEditComplex(&discordgo.MessageEdit{
+		ID:         config.ButtonMessageID,
+		Channel:    config.WelcomeChannelID,
+		Embeds:     &embeds,
+		Components: &components,
+	})
+	if err != nil {
+		return fmt.Errorf("edit welcome button: %w", err)
+	}
+
+	query := `UPDATE guild_welcome_config SET button_version = $1 WHERE guild_id = $2`
+	if _, err := f.db.Exec(ctx, query, welcomeButtonVersion, config.GuildID); err != nil {
+		f.logger.Warn("failed to record welcome button version", "error", err, "guild_id", config.GuildID)
+	}
+
+	f.logger.Info("welcome button refreshed", "guild_id", config.GuildID, "message_id", config.ButtonMessageID)
+	return nil
+}
+
+// RefreshOutdatedButtons edits every posted welcome button older than
+// welcomeButtonVersion to the current version, so buttons posted before an
+// upgrade keep their message and pin. A button whose message was deleted
+// is left for an admin to repost. It returns the number refreshed.
+func (f *Feature) RefreshOutdatedButtons(ctx context.Context) (int, error) {
+	rows, err := f.db.Query(ctx, `
+		SELECT guild_id FROM guild_welcome_config
+		WHERE button_message_id IS NOT NULL AND button_version < $1
+	`, welcomeButtonVersion)
+	if err != nil {
+		return 0, fmt.Errorf("query outdated welcome buttons: %w", err)
+	}
+	defer rows.Close()
+
+	var guildIDs []string
+	for rows.Next() {
+		var guildID string
+		if err := rows.Scan(&guildID); err != nil {
+			return 0, fmt.Errorf("scan outdated welcome button: %w", err)
+		}
+		guildIDs = append(guildIDs, guildID)
+	}
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("query outdated welcome buttons: %w", err)
+	}
+
+	return f.refreshButtons(ctx, guildIDs), nil
+}
+
+// refreshButtons edits the welcome button of each guild and returns how
+// many were edited. Failures are logged and skipped.
+func (f *Feature) refreshButtons(ctx context.Context, guildIDs []string) int {
+	refreshed := 0
+	for _, guildID := range guildIDs {
+		if ctx.Err() != nil {
+			break
+		}
+
+		config, err := f.getWelcomeConfig(ctx, guildID)
+		if err != nil {
+			f.logger.Warn("failed to load welcome config for button refresh", "error", err, "guild_id", guildID)
+			continue
+		}
+		if config.ButtonMessageID == "" {
+			continue
+		}
+
+		if err := f.editWelcomeButton(ctx, config); err != nil {
+			if discord.IsNotFound(err) {
+				f.logger.Info("outdated welcome button message is gone, skipping", "guild_id", guildID, "message_id", config.ButtonMessageID)
+			} else {
+				f.logger.Warn("failed to refresh outdated welcome button", "error", err, "guild_id", guildID)
+			}
+			continue
+		}
+		refreshed++
+	}
+	return refreshed
+}
@@ -0,0 +1,110 @@
+package welcome
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"welcomebot/internal/fakes"
+)
+
+func TestUpdateWelcomeButton(t *testing.T) {
+	ctx := context.Background()
+	dc := fakes.NewDiscord()
+	db := fakes.NewDB()
+	f := &Feature{session: dc.Session(), db: db, cache: fakes.NewCache(), logger: fakes.Logger{}, i18n: fakes.I18n{}}
+
+	config := &WelcomeConfig{GuildID: "guild-1", WelcomeChannelID: "chan-1", ButtonMessageID: "msg-1"}
+
+	reposted, err := f.updateWelcomeButton(ctx, config)
+	if err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if reposted {
+		t.Error("expected the existing message to be edited")
+	}
+	if !hasRequest(dc, http.MethodPatch, "channels/chan-1/messages/msg-1") {
+		t.Errorf("expected an edit of the button message, got %v", dc.Requests())
+	}
+	if hasRequest(dc, http.MethodPost, "channels/chan-1/messages") {
+		t.Error("expected no new message")
+	}
+
+	// The message was deleted: post a new one and record it
+	dc.SetNotFound("channels/chan-1/messages/msg-1")
+	reposted, err = f.updateWelcomeButton(ctx, config)
+	if err != nil {
+		t.Fatalf("update after delete: %v", err)
+	}
+	if !reposted {
+		t.Error("expected the button to be reposted")
+	}
+	if !hasRequest(dc, http.MethodPost, "channels/chan-1/messages") {
+		t.Error("expected a new button message")
+	}
+	execs := db.Execs()
+	if len(execs) != 2 || !strings.Contains(execs[1].Query, "button_message_id") || execs[1].Args[1] != welcomeButtonVersion {
+		t.Errorf("expected the new message ID and version to be saved, got %+v", execs)
+	}
+}
+
+func TestIsWelcomeButtonID(t *testing.T) {
+	for _, id := range []string{welcomeButtonID, "welcome:start_onboarding", "welcome:start_onboarding:v2"} {
+		if !isWelcomeButtonID(id) {
+			t.Errorf("expected %q to start onboarding", id)
+		}
+	}
+	for _, id := range []string{resumeOnboardingID, restartOnboardingID, "welcome:start_onboarding:v", "welcome:start_onboarding:vx"} {
+		if isWelcomeButtonID(id) {
+			t.Errorf("expected %q not to be the welcome button", id)
+		}
+	}
+}
+
+func TestRefreshButtons(t *testing.T) {
+	ctx := context.Background()
+	dc := fakes.NewDiscord()
+	db := fakes.NewDB()
+	cache := fakes.NewCache()
+	f := &Feature{session: dc.Session(), db: db, cache: cache, logger: fakes.Logger{}, i18n: fakes.I18n{}}
+
+	for _, config := range []*WelcomeConfig{
+		{GuildID: "guild-1", WelcomeChannelID: "chan-1", ButtonMessageID: "msg-1"},
+		{GuildID: "guild-2", WelcomeChannelID: "chan-2", ButtonMessageID: "msg-2"},
+	} {
+		if err := cache.SetJSON(ctx, cacheKeyPrefix+config.GuildID, config, 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+	dc.SetNotFound("channels/chan-2/messages/msg-2")
+
+	if got := f.refreshButtons(ctx, []string{"guild-1", "guild-2"}); got != 1 {
+		t.Errorf("expected one button refreshed, got %d", got)
+	}
+
+	var edit string
+	for _, req := range dc.Requests() {
+		if req.Method == http.MethodPatch && req.Path == "channels/chan-1/messages/msg-1" {
+			edit = string(req.Body)
+		}
+	}
+	if !strings.Contains(edit, welcomeButtonID) {
+		t.Errorf("expected the button edited to the current ID, got %q", edit)
+	}
+	if hasRequest(dc, http.MethodPost, "channels/chan-2/messages") {
+		t.Error("expected a deleted button not to be reposted at startup")
+	}
+	if execs := db.Execs(); len(execs) != 1 || execs[0].Args[1] != "guild-1" {
+		t.Errorf("expected only guild-1's version to be recorded, got %+v", execs)
+	}
+}
+
+func hasRequest(dc *fakes.Discord, method, path string) bool {
+	for _, req := range dc.Requests() {
+		if req.Method == method && req.Path == path {
+			return true
+		}
+	}
+	return false
+}
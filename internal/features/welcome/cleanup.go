@@ -0,0 +1,223 @@
+package welcome
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"welcomebot/internal/core/discord"
+	"welcomebot/internal/core/i18n"
+	"welcomebot/internal/shared"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// maxListedChannels caps how many orphaned channels are named in the
+// confirmation embed; the rest are summarised as a count.
+const maxListedChannels = 20
+
+// showOrphanedChannels lists leaked onboarding voice channels and asks the
+// admin to confirm deleting them.
+func (f *Feature) showOrphanedChannels(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	guildID := i.GuildID
+
+	orphaned, err := f.findOrphanedChannels(ctx, s, guildID)
+	if err != nil {
+		return f.respondCleanupError(ctx, s, i, guildID, err)
+	}
+
+	if len(orphaned) == 0 {
+		embed := &discordgo.MessageEmbed{
+			Title:       f.i18n.T(ctx, guildID, "welcome.cleanup_title"),
+			Description: f.i18n.T(ctx, guildID, "welcome.cleanup_none"),
+			Color:       int(shared.ColorSuccess),
+		}
+		return respond(s, i, embed, []discordgo.MessageComponent{})
+	}
+
+	locale, _ := f.i18n.GetGuildLanguage(ctx, guildID)
+	lines := make([]string, 0, maxListedChannels+1)
+	for n, ch := range orphaned {
+		if n == maxListedChannels {
+			lines = append(lines, f.i18n.TWithArgs(ctx, guildID, "welcome.cleanup_more", map[string]string{
+				"count": i18n.FormatNumber(locale, int64(len(orphaned)-maxListedChannels)),
+			}))
+			break
+		}
+		lines = append(lines, fmt.Sprintf("🔊 %s", ch.Name))
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Title: f.i18n.T(ctx, guildID, "welcome.cleanup_title"),
+		Description: f.i18n.TWithArgs(ctx, guildID, "welcome.cleanup_description", map[string]string{
+			"count": i18n.FormatNumber(locale, int64(len(orphaned))),
+		}) + "\n\n" + strings.Join(lines, "\n"),
+		Color: int(shared.ColorWarning),
+	}
+
+	components := []discordgo.MessageComponent{
+		discordgo.ActionsRow{
+			Components: []discordgo.MessageComponent{
+				discordgo.Button{
+					Label:    f.i18n.T(ctx, guildID, "welcome.cleanup_confirm"),
+					Style:    discordgo.DangerButton,
+					CustomID: "welcome:cleanup_channels:confirm",
+				},
+				discordgo.Button{
+					Label:    f.i18n.T(ctx, guildID, "common.cancel"),
+					Style:    discordgo.SecondaryButton,
+					CustomID: "welcome:cancel",
+				},
+			},
+		},
+	}
+
+	return respond(s, i, embed, components)
+}
+
+// deleteOrphanedChannels deletes the orphaned channels after confirmation.
+// The list is rebuilt so sessions started since it was shown are kept.
+func (f *Feature) deleteOrphanedChannels(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	guildID := i.GuildID
+
+	orphaned, err := f.findOrphanedChannels(ctx, s, guildID)
+	if err != nil {
+		return f.respondCleanupError(ctx, s, i, guildID, err)
+	}
+
+	deleted, failed := 0, 0
+	for _, ch := range orphaned {
+		if _, err := s.ChannelDelete(ch.ID); err != nil {
+			f.logger.Warn("failed to delete orphaned channel",
+				"error", err,
+				"guild_id", guildID,
+				"channel_id", ch.ID,
+			)
+			failed++
+			continue
+		}
+		deleted++
+	}
+
+	f.logger.Info("cleaned up orphaned onboarding channels",
+		"guild_id", guildID,
+		"deleted", deleted,
+		"failed", failed,
+	)
+
+	color := shared.ColorSuccess
+	if failed > 0 {
+		color = shared.ColorWarning
+	}
+
+	locale, _ := f.i18n.GetGuildLanguage(ctx, guildID)
+	embed := &discordgo.MessageEmbed{
+		Title: f.i18n.T(ctx, guildID, "welcome.cleanup_title"),
+		Description: f.i18n.TWithArgs(ctx, guildID, "welcome.cleanup_done", map[string]string{
+			"deleted": i18n.FormatNumber(locale, int64(deleted)),
+			"failed":  i18n.FormatNumber(locale, int64(failed)),
+		}),
+		Color: int(color),
+	}
+
+	return respond(s, i, embed, []discordgo.MessageComponent{})
+}
+
+// findOrphanedChannels returns voice channels under the guild's onboarding
+// categories that match its channel name template and have no active session.
+func (f *Feature) findOrphanedChannels(ctx context.Context, s *discordgo.Session, guildID string) ([]*discordgo.Channel, error) {
+	config, err := f.getWelcomeConfig(ctx, guildID)
+	if err != nil {
+		return nil, fmt.Errorf("get welcome config: %w", err)
+	}
+
+	channels, err := s.GuildChannels(guildID)
+	if err != nil {
+		return nil, fmt.Errorf("list guild channels: %w", err)
+	}
+
+	return f.orphanedChannels(ctx, guildID, s.State.User.ID, config, channels), nil
+}
+
+// orphanedChannels filters channels down to leaked onboarding channels.
+func (f *Feature) orphanedChannels(ctx context.Context, guildID, botID string, config *WelcomeConfig, channels []*discordgo.Channel) []*discordgo.Channel {
+	var orphaned []*discordgo.Channel
+	for _, ch := range channels {
+		// Text-only sessions use a text channel in place of the VC. Only
+		// ones created for a member count, so staff text channels are kept
+		switch ch.Type {
+		case discordgo.ChannelTypeGuildVoice:
+		case discordgo.ChannelTypeGuildText:
+			if !createdForMember(ch, botID) {
+				continue
+			}
+		default:
+			continue
+		}
+		if !slices.Contains(onboardingCategories(config), ch.ParentID) {
+			continue
+		}
+		if ch.ID == config.SharedVCChannelID {
+			continue
+		}
+		if !discord.MatchesChannelTemplate(config.VCNameTemplate, ch.Name) {
+			continue
+		}
+		if f.hasActiveSession(ctx, guildID, botID, ch) {
+			continue
+		}
+		orphaned = append(orphaned, ch)
+	}
+	return orphaned
+}
+
+// createdForMember reports whether the channel has a member overwrite other
+// than the bot's, as session channels do.
+func createdForMember(ch *discordgo.Channel, botID string) bool {
+	for _, overwrite := range ch.PermissionOverwrites {
+		if overwrite.Type == discordgo.PermissionOverwriteTypeMember && overwrite.ID != botID {
+			return true
+		}
+	}
+	return false
+}
+
+// hasActiveSession reports whether the member the channel was created for
+// still has a session in cache. Cache errors count as active so a channel
+// is never deleted on a guess.
+func (f *Feature) hasActiveSession(ctx context.Context, guildID, botID string, ch *discordgo.Channel) bool {
+	for _, overwrite := range ch.PermissionOverwrites {
+		if overwrite.Type != discordgo.PermissionOverwriteTypeMember || overwrite.ID == botID {
+			continue
+		}
+
+		sessionKey := fmt.Sprintf("%s%s:%s", sessionKeyPrefix, guildID, overwrite.ID)
+		exists, err := f.cache.Exists(ctx, sessionKey)
+		if err != nil {
+			f.logger.Warn("failed to check session, keeping channel",
+				"error", err,
+				"channel_id", ch.ID,
+				"user_id", overwrite.ID,
+			)
+			return true
+		}
+		if exists {
+			return true
+		}
+	}
+	return false
+}
+
+// respondCleanupError reports why the channel list could not be built.
+func (f *Feature) respondCleanupError(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate, guildID string, err error) error {
+	f.logger.Error("failed to find orphaned channels", "guild_id", guildID, "error", err)
+
+	embed := &discordgo.MessageEmbed{
+		Title:       f.i18n.T(ctx, guildID, "common.error"),
+		Description: f.i18n.T(ctx, guildID, "welcome.cleanup_failed"),
+		Color:       int(shared.ColorError),
+	}
+
+	return respond(s, i, embed, []discordgo.MessageComponent{})
+}
@@ -0,0 +1,87 @@
+package welcome
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"welcomebot/internal/fakes"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func TestOrphanedChannels(t *testing.T) {
+	ctx := context.Background()
+	cache := fakes.NewCache()
+	f := &Feature{cache: cache, logger: fakes.Logger{}}
+
+	// alice is still onboarding; bob's session is gone
+	if err := cache.Set(ctx, sessionKeyPrefix+"guild-1:alice", "{}", time.Hour); err != nil {
+		t.Fatalf("set session: %v", err)
+	}
+
+	member := func(id string) []*discordgo.PermissionOverwrite {
+		return []*discordgo.PermissionOverwrite{
+			{ID: id, Type: discordgo.PermissionOverwriteTypeMember},
+			{ID: "bot", Type: discordgo.PermissionOverwriteTypeMember},
+			{ID: "guild-1", Type: discordgo.PermissionOverwriteTypeRole},
+		}
+	}
+	config := &WelcomeConfig{VCCategoryID: "cat-1"}
+	channels := []*discordgo.Channel{
+		{ID: "1", Name: "onboarding-alice", Type: discordgo.ChannelTypeGuildVoice, ParentID: "cat-1", PermissionOverwrites: member("alice")},
+		{ID: "2", Name: "onboarding-bob", Type: discordgo.ChannelTypeGuildVoice, ParentID: "cat-1", PermissionOverwrites: member("bob")},
+		{ID: "3", Name: "onboarding-carol", Type: discordgo.ChannelTypeGuildVoice, ParentID: "other", PermissionOverwrites: member("carol")},
+		{ID: "4", Name: "lounge", Type: discordgo.ChannelTypeGuildVoice, ParentID: "cat-1"},
+		{ID: "5", Name: "onboarding-notes", Type: discordgo.ChannelTypeGuildText, ParentID: "cat-1"},
+	}
+
+	orphaned := f.orphanedChannels(ctx, "guild-1", "bot", config, channels)
+	if len(orphaned) != 1 || orphaned[0].ID != "2" {
+		t.Fatalf("expected only bob's channel to be orphaned, got %v", channelIDs(orphaned))
+	}
+
+	// A custom template changes which names are considered onboarding channels
+	config.VCNameTemplate = "welcome-{n}"
+	channels = append(channels, &discordgo.Channel{ID: "6", Name: "welcome-12", Type: discordgo.ChannelTypeGuildVoice, ParentID: "cat-1"})
+	orphaned = f.orphanedChannels(ctx, "guild-1", "bot", config, channels)
+	if len(orphaned) != 1 || orphaned[0].ID != "6" {
+		t.Errorf("expected only the templated channel to be orphaned, got %v", channelIDs(orphaned))
+	}
+
+	// Channels in an overflow category are onboarding channels too
+	config.OverflowCategoryIDs = []string{"other"}
+	channels = append(channels, &discordgo.Channel{ID: "7", Name: "welcome-13", Type: discordgo.ChannelTypeGuildVoice, ParentID: "other"})
+	orphaned = f.orphanedChannels(ctx, "guild-1", "bot", config, channels)
+	if len(orphaned) != 2 || orphaned[0].ID != "6" || orphaned[1].ID != "7" {
+		t.Errorf("expected the overflow category's channel to be orphaned too, got %v", channelIDs(orphaned))
+	}
+}
+
+func TestOrphanedTextChannels(t *testing.T) {
+	f := &Feature{cache: fakes.NewCache(), logger: fakes.Logger{}}
+
+	config := &WelcomeConfig{VCCategoryID: "cat-1", TextOnly: true}
+	channels := []*discordgo.Channel{
+		{ID: "1", Name: "onboarding-dave", Type: discordgo.ChannelTypeGuildText, ParentID: "cat-1",
+			PermissionOverwrites: []*discordgo.PermissionOverwrite{
+				{ID: "dave", Type: discordgo.PermissionOverwriteTypeMember},
+				{ID: "bot", Type: discordgo.PermissionOverwriteTypeMember},
+			}},
+		{ID: "2", Name: "onboarding-notes", Type: discordgo.ChannelTypeGuildText, ParentID: "cat-1"},
+	}
+
+	// A text-only session's channel is onboarding's; a staff channel isn't
+	orphaned := f.orphanedChannels(context.Background(), "guild-1", "bot", config, channels)
+	if len(orphaned) != 1 || orphaned[0].ID != "1" {
+		t.Errorf("expected only dave's text channel to be orphaned, got %v", channelIDs(orphaned))
+	}
+}
+
+func channelIDs(channels []*discordgo.Channel) []string {
+	ids := make([]string, len(channels))
+	for n, ch := range channels {
+		ids[n] = ch.ID
+	}
+	return ids
+}
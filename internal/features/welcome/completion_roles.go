@@ -0,0 +1,198 @@
+package welcome
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"welcomebot/internal/bot"
+	"welcomebot/internal/core/discord"
+	"welcomebot/internal/shared"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/lib/pq"
+)
+
+// Custom IDs of the completion roles panel.
+const (
+	completionGrantRolesID  = "welcome:completion_roles:grant"
+	completionRevokeRolesID = "welcome:completion_roles:revoke"
+	completionRolesResetID  = "welcome:completion_roles:reset"
+)
+
+// maxCompletionRoles caps each of the lists of roles granted and revoked
+// on completion.
+const maxCompletionRoles = 10
+
+// completionRoles returns the roles completing onboarding grants and
+// revokes: the guild's own lists, or the visitor and member roles and the
+// 説明会 roles by default.
+func completionRoles(config *WelcomeConfig) (grant, revoke []string) {
+	if config.CustomCompletionRoles {
+		return config.CompletionGrantRoleIDs, config.CompletionRevokeRoleIDs
+	}
+	grant = categorySelection([]string{config.VisitorRoleID, config.MemberRoleID}, nil, maxCompletionRoles)
+	revoke = categorySelection([]string{config.Setsumeikai1RoleID, config.Setsumeikai2RoleID, config.Setsumeikai3RoleID}, nil, maxCompletionRoles)
+	return grant, revoke
+}
+
+// saveCompletionRoles stores the roles granted and revoked on completion.
+// Without custom the guild goes back to the defaults and the lists are
+// cleared. @everyone and duplicates are dropped.
+func (f *Feature) saveCompletionRoles(ctx context.Context, config *WelcomeConfig, custom bool, grantIDs, revokeIDs []string) error {
+	var grant, revoke []string
+	if custom {
+		// categorySelection's cleanup works for any IDs
+		grant = categorySelection(grantIDs, []string{config.GuildID}, maxCompletionRoles)
+		revoke = categorySelection(revokeIDs, []string{config.GuildID}, maxCompletionRoles)
+	}
+
+	_, err := f.db.Exec(ctx, `
+		UPDATE guild_welcome_config
+		SET custom_completion_roles = $1, completion_grant_role_ids = $2, completion_revoke_role_ids = $3, updated_at = NOW()
+		WHERE guild_id = $4
+	`, custom, pq.Array(grant), pq.Array(revoke), config.GuildID)
+	if err != nil {
+		return fmt.Errorf("save completion roles: %w", err)
+	}
+	config.CustomCompletionRoles = custom
+	config.CompletionGrantRoleIDs = grant
+	config.CompletionRevokeRoleIDs = revoke
+
+	if err := f.cache.Delete(ctx, cacheKeyPrefix+config.GuildID); err != nil {
+		f.logger.Warn("failed to invalidate welcome config", "error", err, "guild_id", config.GuildID)
+	}
+	return nil
+}
+
+// showCompletionRoles shows the roles granted and revoked on completion,
+// with menus to change them.
+func (f *Feature) showCompletionRoles(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	guildID := i.GuildID
+
+	config, err := f.getWelcomeConfig(ctx, guildID)
+	if err != nil {
+		return f.respondErrorMessage(ctx, s, i, guildID, "welcome.config_not_found")
+	}
+
+	return respond(s, i, f.completionRolesEmbed(ctx, config), f.completionRolesComponents(ctx, config))
+}
+
+// handleCompletionRolesSelection saves the roles picked in one of the
+// panel's menus. The first change starts from the defaults, so the other
+// list keeps doing what it did. Roles an onboarding bot can't manage are
+// refused, since completion would fail to give or take them.
+func (f *Feature) handleCompletionRolesSelection(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate, customID string) error {
+	guildID := i.GuildID
+
+	config, err := f.getWelcomeConfig(ctx, guildID)
+	if err != nil {
+		return f.respondErrorMessage(ctx, s, i, guildID, "welcome.config_not_found")
+	}
+
+	selected := i.MessageComponentData().Values
+	if blocked := f.slaveUnmanageableRoles(ctx, s, guildID, selected); len(blocked) > 0 {
+		return bot.RoleAboveBotError(blocked[0])
+	}
+
+	grant, revoke := completionRoles(config)
+	if customID == completionGrantRolesID {
+		grant = selected
+	} else {
+		revoke = selected
+	}
+	if err := f.saveCompletionRoles(ctx, config, true, grant, revoke); err != nil {
+		return f.respondError(ctx, s, i, guildID, err)
+	}
+
+	f.logger.Info("completion roles updated",
+		"guild_id", guildID,
+		"grant", config.CompletionGrantRoleIDs,
+		"revoke", config.CompletionRevokeRoleIDs,
+	)
+
+	return respond(s, i, f.completionRolesEmbed(ctx, config), f.completionRolesComponents(ctx, config))
+}
+
+// handleCompletionRolesReset puts the guild back on the default
+// completion roles.
+func (f *Feature) handleCompletionRolesReset(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	guildID := i.GuildID
+
+	config, err := f.getWelcomeConfig(ctx, guildID)
+	if err != nil {
+		return f.respondErrorMessage(ctx, s, i, guildID, "welcome.config_not_found")
+	}
+
+	if err := f.saveCompletionRoles(ctx, config, false, nil, nil); err != nil {
+		return f.respondError(ctx, s, i, guildID, err)
+	}
+
+	f.logger.Info("completion roles reset to defaults", "guild_id", guildID)
+
+	return respond(s, i, f.completionRolesEmbed(ctx, config), f.completionRolesComponents(ctx, config))
+}
+
+func (f *Feature) completionRolesEmbed(ctx context.Context, config *WelcomeConfig) *discordgo.MessageEmbed {
+	guildID := config.GuildID
+
+	mentions := func(roleIDs []string) string {
+		if len(roleIDs) == 0 {
+			return f.i18n.T(ctx, guildID, "welcome.completion_roles_none")
+		}
+		var parts []string
+		for _, id := range roleIDs {
+			parts = append(parts, fmt.Sprintf("<@&%s>", id))
+		}
+		return strings.Join(parts, ", ")
+	}
+
+	mode := "welcome.completion_roles_default"
+	if config.CustomCompletionRoles {
+		mode = "welcome.completion_roles_custom"
+	}
+	grant, revoke := completionRoles(config)
+	lines := []string{
+		f.i18n.T(ctx, guildID, mode),
+		f.i18n.TWithArgs(ctx, guildID, "welcome.completion_roles_grant", map[string]string{"roles": mentions(grant)}),
+		f.i18n.TWithArgs(ctx, guildID, "welcome.completion_roles_revoke", map[string]string{"roles": mentions(revoke)}),
+	}
+
+	return &discordgo.MessageEmbed{
+		Title:       f.i18n.T(ctx, guildID, "welcome.completion_roles_title"),
+		Description: f.i18n.T(ctx, guildID, "welcome.completion_roles_description") + "\n\n" + strings.Join(lines, "\n"),
+		Color:       int(shared.ColorInfo),
+	}
+}
+
+func (f *Feature) completionRolesComponents(ctx context.Context, config *WelcomeConfig) []discordgo.MessageComponent {
+	guildID := config.GuildID
+	grantIDs, revokeIDs := completionRoles(config)
+
+	// Clearing a menu grants or revokes nothing
+	minValues := 0
+	grant := discord.RoleSelectMenu(completionGrantRolesID,
+		f.i18n.T(ctx, guildID, "welcome.select_completion_grant_roles"), grantIDs...)
+	grant.MinValues = &minValues
+	grant.MaxValues = maxCompletionRoles
+
+	revoke := discord.RoleSelectMenu(completionRevokeRolesID,
+		f.i18n.T(ctx, guildID, "welcome.select_completion_revoke_roles"), revokeIDs...)
+	revoke.MinValues = &minValues
+	revoke.MaxValues = maxCompletionRoles
+
+	return []discordgo.MessageComponent{
+		discordgo.ActionsRow{Components: []discordgo.MessageComponent{grant}},
+		discordgo.ActionsRow{Components: []discordgo.MessageComponent{revoke}},
+		discordgo.ActionsRow{
+			Components: []discordgo.MessageComponent{
+				discordgo.Button{
+					Label:    f.i18n.T(ctx, guildID, "welcome.completion_roles_reset"),
+					Style:    discordgo.SecondaryButton,
+					CustomID: completionRolesResetID,
+					Disabled: !config.CustomCompletionRoles,
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,94 @@
+package welcome
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"welcomebot/internal/bot"
+	"welcomebot/internal/fakes"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func TestSaveCompletionRoles(t *testing.T) {
+	ctx := context.Background()
+	cache := fakes.NewCache()
+	db := fakes.NewDB()
+	f := &Feature{cache: cache, db: db, logger: fakes.Logger{}}
+
+	config := &WelcomeConfig{
+		GuildID:            "guild-1",
+		MemberRoleID:       "role-member",
+		VisitorRoleID:      "role-visitor",
+		Setsumeikai1RoleID: "role-s1",
+		Setsumeikai2RoleID: "role-s2",
+	}
+	if got := fmt.Sprint(completionRoles(config)); got != "[role-visitor role-member] [role-s1 role-s2]" {
+		t.Errorf("default completion roles = %s", got)
+	}
+
+	// Keeping 説明会 as a marker: grant the member role, revoke nothing
+	if err := f.saveCompletionRoles(ctx, config, true, []string{"role-member", "guild-1", "role-member"}, nil); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	if got := fmt.Sprint(completionRoles(config)); got != "[role-member] []" {
+		t.Errorf("custom completion roles = %s, want [role-member] []", got)
+	}
+	if exists, _ := cache.Exists(ctx, cacheKeyPrefix+"guild-1"); exists {
+		t.Error("expected the cached config to be invalidated")
+	}
+	if execs := db.Execs(); len(execs) != 1 {
+		t.Errorf("expected one update, got %+v", execs)
+	}
+
+	onboarding := onboardingConfig(config, nil, nil, nil, nil, nil)
+	if c := onboarding.CompletionChanges; !c.Custom || fmt.Sprint(c.Grant, c.Revoke) != "[role-member] []" {
+		t.Errorf("payload completion changes = %+v", c)
+	}
+
+	if err := f.saveCompletionRoles(ctx, config, false, nil, nil); err != nil {
+		t.Fatalf("reset: %v", err)
+	}
+	if config.CustomCompletionRoles || config.CompletionGrantRoleIDs != nil {
+		t.Errorf("expected reset to clear the custom roles, got %+v", config)
+	}
+}
+
+func TestCompletionRolesSelectionRefusesUnmanageable(t *testing.T) {
+	ctx := context.Background()
+	d := fakes.NewDiscord()
+	d.SetGuildRoles("guild-1",
+		&discordgo.Role{ID: "role-bot", Position: 5},
+		&discordgo.Role{ID: "role-admin", Position: 9},
+	)
+	d.SetMemberRoles("guild-1", fakes.BotUserID, "role-bot")
+
+	cache := fakes.NewCache()
+	db := fakes.NewDB()
+	f := &Feature{cache: cache, db: db, i18n: fakes.I18n{}, logger: fakes.Logger{}}
+	_ = cache.Set(ctx, slaveUserKey+SlaveIDs[0], fakes.BotUserID, 0)
+	if err := cache.SetJSON(ctx, cacheKeyPrefix+"guild-1", &WelcomeConfig{GuildID: "guild-1"}, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	i := &discordgo.InteractionCreate{Interaction: &discordgo.Interaction{
+		ID: "interaction-1", Token: "token", GuildID: "guild-1",
+		Type:   discordgo.InteractionMessageComponent,
+		Member: &discordgo.Member{User: &discordgo.User{ID: "admin-1"}},
+		Data: discordgo.MessageComponentInteractionData{
+			CustomID: completionGrantRolesID,
+			Values:   []string{"role-admin"},
+		},
+	}}
+	err := f.HandleInteraction(ctx, d.Session(), i)
+
+	var verr *bot.ValidationError
+	if !errors.As(err, &verr) || verr.Key != "validation.role_above_bot" {
+		t.Fatalf("expected a role above bot error, got %v", err)
+	}
+	if execs := db.Execs(); len(execs) != 0 {
+		t.Errorf("expected nothing saved, got %+v", execs)
+	}
+}
@@ -0,0 +1,144 @@
+package welcome
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// ConfigGroup names the settings saved by one setup button. Each group is
+// named after the feature that owns its setup.
+type ConfigGroup string
+
+const (
+	ConfigGroupWelcome    ConfigGroup = "welcome"
+	ConfigGroupAgeRange   ConfigGroup = "agerange"
+	ConfigGroupGender     ConfigGroup = "gender"
+	ConfigGroupVoiceType  ConfigGroup = "voicetype"
+	ConfigGroupOtherRoles ConfigGroup = "otherroles"
+)
+
+// ConfigGroupStatus reports whether a group is configured.
+type ConfigGroupStatus struct {
+	Group    ConfigGroup
+	Set      bool
+	Required bool // Onboarding can't start without it; other groups only skip their roles
+}
+
+// ConfigStatus lists every config group onboarding reads, welcome first.
+type ConfigStatus struct {
+	Groups []ConfigGroupStatus
+}
+
+// Complete reports whether every required group is set.
+func (s ConfigStatus) Complete() bool {
+	for _, group := range s.Groups {
+		if group.Required && !group.Set {
+			return false
+		}
+	}
+	return true
+}
+
+// Missing returns the groups that are not set, required or not.
+func (s ConfigStatus) Missing() []ConfigGroup {
+	var missing []ConfigGroup
+	for _, group := range s.Groups {
+		if !group.Set {
+			missing = append(missing, group.Group)
+		}
+	}
+	return missing
+}
+
+// ConfigStatus reports which of the configs onboarding reads are set for a
+// guild. Welcome needs its channel and VC category; a role group counts as
+// set once any of its roles is.
+func (f *Feature) ConfigStatus(ctx context.Context, guildID string) (ConfigStatus, error) {
+	var status ConfigStatus
+
+	config, loadErr := f.getWelcomeConfig(ctx, guildID)
+	if err := addConfigGroup(&status, ConfigGroupWelcome, true, loadErr, func() bool {
+		return config.WelcomeChannelID != "" && config.VCCategoryID != ""
+	}); err != nil {
+		return ConfigStatus{}, err
+	}
+
+	ageRange, loadErr := f.getAgeRangeConfig(ctx, guildID)
+	if err := addConfigGroup(&status, ConfigGroupAgeRange, false, loadErr, func() bool {
+		return anySet(ageRange.Age20EarlyRoleID, ageRange.Age20LateRoleID,
+			ageRange.Age30EarlyRoleID, ageRange.Age30LateRoleID,
+			ageRange.Age40EarlyRoleID, ageRange.Age40LateRoleID)
+	}); err != nil {
+		return ConfigStatus{}, err
+	}
+
+	gender, loadErr := f.getGenderConfig(ctx, guildID)
+	if err := addConfigGroup(&status, ConfigGroupGender, false, loadErr, func() bool {
+		return anySet(gender.MaleRoleID, gender.FemaleRoleID)
+	}); err != nil {
+		return ConfigStatus{}, err
+	}
+
+	voiceType, loadErr := f.getVoiceTypeConfig(ctx, guildID)
+	if err := addConfigGroup(&status, ConfigGroupVoiceType, false, loadErr, func() bool {
+		return anySet(voiceType.HighRoleID, voiceType.MidHighRoleID, voiceType.MidRoleID,
+			voiceType.MidLowRoleID, voiceType.LowRoleID)
+	}); err != nil {
+		return ConfigStatus{}, err
+	}
+
+	otherRoles, loadErr := f.getOtherRolesConfig(ctx, guildID)
+	if err := addConfigGroup(&status, ConfigGroupOtherRoles, false, loadErr, func() bool {
+		return anySet(otherRoles.EroOkRoleID, otherRoles.EroNgRoleID,
+			otherRoles.NeochiOkRoleID, otherRoles.NeochiNgRoleID, otherRoles.NeochiDisconnectRoleID,
+			otherRoles.DmOkRoleID, otherRoles.DmNgRoleID,
+			otherRoles.FriendOkRoleID, otherRoles.FriendNgRoleID,
+			otherRoles.BunnyclubEventRoleID, otherRoles.UserEventRoleID)
+	}); err != nil {
+		return ConfigStatus{}, err
+	}
+
+	return status, nil
+}
+
+// addConfigGroup records a group from the result of loading its config. A
+// missing row means the group is not set; set is only called after a
+// successful load.
+func addConfigGroup(status *ConfigStatus, group ConfigGroup, required bool, loadErr error, set func() bool) error {
+	entry := ConfigGroupStatus{Group: group, Required: required}
+	switch {
+	case errors.Is(loadErr, sql.ErrNoRows):
+	case loadErr != nil:
+		return fmt.Errorf("get %s config: %w", group, loadErr)
+	default:
+		entry.Set = set()
+	}
+	status.Groups = append(status.Groups, entry)
+	return nil
+}
+
+func anySet(ids ...string) bool {
+	for _, id := range ids {
+		if id != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// SetupBadges maps each config group's setup button to whether the group
+// is set, for the menu's ✅/⚠️ badges.
+func (f *Feature) SetupBadges(ctx context.Context, guildID string) (map[string]bool, error) {
+	status, err := f.ConfigStatus(ctx, guildID)
+	if err != nil {
+		return nil, err
+	}
+
+	badges := make(map[string]bool, len(status.Groups))
+	for _, group := range status.Groups {
+		badges[fmt.Sprintf("menu:%s:setup", group.Group)] = group.Set
+	}
+	return badges, nil
+}
@@ -0,0 +1,60 @@
+package welcome
+
+import (
+	"context"
+	"testing"
+
+	"welcomebot/internal/fakes"
+)
+
+func TestConfigStatus(t *testing.T) {
+	ctx := context.Background()
+	cache := fakes.NewCache()
+	f := &Feature{cache: cache, db: fakes.NewDB(), logger: fakes.Logger{}}
+
+	configs := map[string]interface{}{
+		cacheKeyPrefix + "guild-1":           &WelcomeConfig{GuildID: "guild-1", WelcomeChannelID: "channel-1", VCCategoryID: "category-1"},
+		ageRangeCacheKeyPrefix + "guild-1":   &AgeRangeConfig{GuildID: "guild-1", Age30LateRoleID: "role-30late"},
+		genderCacheKeyPrefix + "guild-1":     &GenderConfig{GuildID: "guild-1"},
+		voiceTypeCacheKeyPrefix + "guild-1":  &VoiceTypeConfig{GuildID: "guild-1", LowRoleID: "role-low"},
+		otherRolesCacheKeyPrefix + "guild-1": &OtherRolesConfig{GuildID: "guild-1"},
+	}
+	for key, config := range configs {
+		if err := cache.SetJSON(ctx, key, config, 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	status, err := f.ConfigStatus(ctx, "guild-1")
+	if err != nil {
+		t.Fatalf("ConfigStatus: %v", err)
+	}
+	if !status.Complete() {
+		t.Errorf("expected the welcome config to complete setup, got %+v", status)
+	}
+	missing := status.Missing()
+	if len(missing) != 2 || missing[0] != ConfigGroupGender || missing[1] != ConfigGroupOtherRoles {
+		t.Errorf("expected gender and other roles to be missing, got %v", missing)
+	}
+
+	badges, err := f.SetupBadges(ctx, "guild-1")
+	if err != nil {
+		t.Fatalf("SetupBadges: %v", err)
+	}
+	if !badges["menu:welcome:setup"] || !badges["menu:agerange:setup"] || badges["menu:gender:setup"] {
+		t.Errorf("unexpected badges %v", badges)
+	}
+	if _, ok := badges["menu:gender:setup"]; !ok {
+		t.Error("expected a badge for the unset gender roles")
+	}
+}
+
+func TestConfigStatusRequiresWelcome(t *testing.T) {
+	status := ConfigStatus{Groups: []ConfigGroupStatus{
+		{Group: ConfigGroupWelcome, Required: true},
+		{Group: ConfigGroupGender, Set: true},
+	}}
+	if status.Complete() {
+		t.Error("expected an unset required group to leave setup incomplete")
+	}
+}
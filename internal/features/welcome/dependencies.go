@@ -1,13 +1,17 @@
 package welcome
 
 import (
+	"context"
 	"errors"
+	"time"
 
 	"welcomebot/internal/core/cache"
 	"welcomebot/internal/core/database"
 	"welcomebot/internal/core/i18n"
 	"welcomebot/internal/core/logger"
+	"welcomebot/internal/core/notify"
 	"welcomebot/internal/core/queue"
+	"welcomebot/internal/onboard"
 
 	"github.com/bwmarrin/discordgo"
 )
@@ -20,6 +24,35 @@ type Dependencies struct {
 	I18n    i18n.I18n
 	Logger  logger.Logger
 	Session *discordgo.Session
+
+	// Events is the master-bound queue workers report failures on (optional).
+	Events queue.Client
+
+	// MaxSessionsPerGuild caps simultaneous onboarding sessions in one guild
+	// (optional, defaults to DefaultMaxSessionsPerGuild).
+	MaxSessionsPerGuild int
+
+	// MaxAttemptsPerUser caps how often one member can start onboarding
+	// within AttemptWindow; further starts wait for the window to end
+	// (optional, default DefaultMaxAttemptsPerUser and DefaultAttemptWindow).
+	MaxAttemptsPerUser int
+	AttemptWindow      time.Duration
+
+	// ResumeExpiry is how long a member who left onboarding unfinished is
+	// offered to resume it; zero leaves resume off (optional).
+	ResumeExpiry time.Duration
+
+	// Notifier receives failure and completion notifications, on top of
+	// the staff channel from the setup wizard (optional).
+	Notifier notify.Notifier
+
+	// WizardTTL is how long an untouched setup wizard is kept before an
+	// admin has to start it again (optional, defaults to DefaultWizardTTL).
+	WizardTTL time.Duration
+
+	// NewAudioPlayer creates the player the test audio tool plays guide
+	// files through (optional; without it the tool is unavailable).
+	NewAudioPlayer func(ctx context.Context, s *discordgo.Session) onboard.AudioPlayer
 }
 
 // Validate ensures all required dependencies are present.
@@ -0,0 +1,282 @@
+package welcome
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"welcomebot/internal/core/database"
+	"welcomebot/internal/onboard"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// onboardingExportCommand sends session analytics as a CSV file.
+const onboardingExportCommand = "onboarding-export"
+
+const (
+	// exportDateLayout is how the command's from and to dates are written.
+	exportDateLayout = "2006-01-02"
+
+	// defaultExportDays is the range exported when no from date is given.
+	defaultExportDays = 30
+
+	// maxExportDays bounds the range one export may ask for.
+	maxExportDays = 366
+
+	// maxExportBytes keeps the file under Discord's smallest upload limit.
+	// The upload is built in memory, so this also bounds what an export
+	// holds at once.
+	maxExportBytes = 8 << 20
+)
+
+var (
+	errExportRange    = errors.New("invalid export range")
+	errExportTooLarge = errors.New("export too large")
+)
+
+// exportHeader names the CSV columns, one row per onboarding session.
+var exportHeader = []string{
+	"session_id", "user_id", "guide", "outcome", "started_at", "ended_at",
+	"duration_seconds", "steps_reached", "last_step",
+}
+
+// onboardingExportCommandDefinition is registered with Discord. Only
+// administrators see it; the handler checks again.
+func onboardingExportCommandDefinition() *discordgo.ApplicationCommand {
+	dmPermission := false
+	adminPermission := int64(discordgo.PermissionAdministrator)
+	return &discordgo.ApplicationCommand{
+		Name:                     onboardingExportCommand,
+		Description:              "Export onboarding sessions as a CSV file (admin only)",
+		DMPermission:             &dmPermission,
+		DefaultMemberPermissions: &adminPermission,
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "from",
+				Description: "First day to include, YYYY-MM-DD in UTC (default: 30 days ago)",
+			},
+			{
+				Type:        discordgo.ApplicationCommandOptionString,
+				Name:        "to",
+				Description: "Last day to include, YYYY-MM-DD in UTC (default: today)",
+			},
+		},
+	}
+}
+
+// parseExportRange turns the command's from and to dates into the range of
+// session start times to export, [start, end). to defaults to today and
+// from to defaultExportDays before it.
+func parseExportRange(from, to string, now time.Time) (time.Time, time.Time, error) {
+	last := now.UTC().Truncate(24 * time.Hour)
+	if to != "" {
+		t, err := time.Parse(exportDateLayout, to)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("%w: to %q is not YYYY-MM-DD", errExportRange, to)
+		}
+		last = t
+	}
+
+	first := last.AddDate(0, 0, -(defaultExportDays - 1))
+	if from != "" {
+		t, err := time.Parse(exportDateLayout, from)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("%w: from %q is not YYYY-MM-DD", errExportRange, from)
+		}
+		first = t
+	}
+
+	end := last.AddDate(0, 0, 1)
+	if !first.Before(end) {
+		return time.Time{}, time.Time{}, fmt.Errorf("%w: from is after to", errExportRange)
+	}
+	if end.Sub(first) > maxExportDays*24*time.Hour {
+		return time.Time{}, time.Time{}, fmt.Errorf("%w: more than %d days", errExportRange, maxExportDays)
+	}
+	return first, end, nil
+}
+
+// handleOnboardingExport sends administrators a CSV of the sessions that
+// started in the requested range.
+func (f *Feature) handleOnboardingExport(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	guildID := i.GuildID
+
+	if i.Member == nil || i.Member.Permissions&discordgo.PermissionAdministrator == 0 {
+		f.logger.Warn("onboarding export denied", "guild_id", guildID)
+		return f.respondErrorMessage(ctx, s, i, guildID, "welcome.export_admin_only")
+	}
+
+	var from, to string
+	for _, opt := range i.ApplicationCommandData().Options {
+		switch opt.Name {
+		case "from":
+			from = strings.TrimSpace(opt.StringValue())
+		case "to":
+			to = strings.TrimSpace(opt.StringValue())
+		}
+	}
+	start, end, err := parseExportRange(from, to, time.Now())
+	if err != nil {
+		return f.respondErrorMessage(ctx, s, i, guildID, "welcome.export_invalid_range")
+	}
+
+	// Large ranges take a while; the file follows up
+	if err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{Flags: discordgo.MessageFlagsEphemeral},
+	}); err != nil {
+		return fmt.Errorf("defer export response: %w", err)
+	}
+
+	if err := f.sendOnboardingExport(ctx, s, i, start, end); err != nil {
+		key := "welcome.export_failed"
+		if errors.Is(err, errExportTooLarge) {
+			key = "welcome.export_too_large"
+			f.logger.Warn("onboarding export too large", "guild_id", guildID, "from", start, "to", end)
+		} else {
+			f.logger.Error("onboarding export failed", "error", err, "guild_id", guildID)
+		}
+		_, _ = s.FollowupMessageCreate(i.Interaction, true, &discordgo.WebhookParams{
+			Content: f.i18n.T(ctx, guildID, key),
+			Flags:   discordgo.MessageFlagsEphemeral,
+		})
+	}
+	return nil
+}
+
+// sendOnboardingExport queries the sessions and uploads them as CSV. The
+// upload is sent in one request, so the file is built in memory first and
+// fails with errExportTooLarge past maxExportBytes.
+func (f *Feature) sendOnboardingExport(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate, start, end time.Time) error {
+	guildID := i.GuildID
+
+	rows, err := f.db.Query(database.ReadFromReplica(ctx), `
+		SELECT id, user_id, guide, outcome, started_at, ended_at, events
+		FROM session_timelines
+		WHERE guild_id = $1 AND started_at >= $2 AND started_at < $3
+		ORDER BY started_at
+	`, guildID, start, end)
+	if err != nil {
+		return fmt.Errorf("query sessions: %w", err)
+	}
+
+	defer rows.Close()
+
+	var file bytes.Buffer
+	if err := writeSessionsCSV(&limitedWriter{w: &file, left: maxExportBytes}, rows); err != nil {
+		return err
+	}
+
+	lastDay := end.AddDate(0, 0, -1).Format(exportDateLayout)
+	_, err = s.FollowupMessageCreate(i.Interaction, true, &discordgo.WebhookParams{
+		Content: f.i18n.TWithArgs(ctx, guildID, "welcome.export_ready", map[string]string{
+			"from": start.Format(exportDateLayout),
+			"to":   lastDay,
+		}),
+		Files: []*discordgo.File{{
+			Name:        fmt.Sprintf("onboarding-%s-%s.csv", start.Format(exportDateLayout), lastDay),
+			ContentType: "text/csv",
+			Reader:      &file,
+		}},
+		Flags: discordgo.MessageFlagsEphemeral,
+	})
+	if err != nil {
+		return fmt.Errorf("send export: %w", err)
+	}
+
+	f.logger.Info("onboarding export sent", "guild_id", guildID, "from", start, "to", end)
+	return nil
+}
+
+// limitedWriter fails with errExportTooLarge once more than left bytes
+// are written.
+type limitedWriter struct {
+	w    io.Writer
+	left int
+}
+
+func (l *limitedWriter) Write(p []byte) (int, error) {
+	if len(p) > l.left {
+		return 0, errExportTooLarge
+	}
+	l.left -= len(p)
+	return l.w.Write(p)
+}
+
+// sessionRows is the part of *sql.Rows writeSessionsCSV reads.
+type sessionRows interface {
+	Next() bool
+	Scan(dest ...any) error
+	Err() error
+}
+
+// writeSessionsCSV writes the header and one row per session timeline.
+func writeSessionsCSV(w io.Writer, rows sessionRows) error {
+	out := csv.NewWriter(w)
+	if err := out.Write(exportHeader); err != nil {
+		return err
+	}
+
+	for rows.Next() {
+		var id int64
+		var userID, outcome string
+		var guide sql.NullString
+		var startedAt, endedAt time.Time
+		var events []byte
+		if err := rows.Scan(&id, &userID, &guide, &outcome, &startedAt, &endedAt, &events); err != nil {
+			return fmt.Errorf("scan session: %w", err)
+		}
+
+		var timeline []onboard.TimelineEvent
+		if err := json.Unmarshal(events, &timeline); err != nil {
+			return fmt.Errorf("decode session %d events: %w", id, err)
+		}
+		reached, last := stepsReached(timeline)
+
+		if err := out.Write([]string{
+			strconv.FormatInt(id, 10),
+			userID,
+			guide.String,
+			outcome,
+			startedAt.UTC().Format(time.RFC3339),
+			endedAt.UTC().Format(time.RFC3339),
+			strconv.FormatInt(int64(endedAt.Sub(startedAt).Seconds()), 10),
+			strconv.Itoa(reached),
+			last,
+		}); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("read sessions: %w", err)
+	}
+
+	out.Flush()
+	return out.Error()
+}
+
+// stepsReached counts the distinct steps a session showed and returns the
+// last one. Skipped steps and step timeouts are not steps reached.
+func stepsReached(events []onboard.TimelineEvent) (int, string) {
+	seen := make(map[string]bool)
+	last := ""
+	for _, event := range events {
+		if event.Kind != onboard.TimelineStep ||
+			strings.HasSuffix(event.Detail, " skipped") || strings.Contains(event.Detail, ".timeout.") {
+			continue
+		}
+		seen[event.Detail] = true
+		last = event.Detail
+	}
+	return len(seen), last
+}
@@ -0,0 +1,130 @@
+package welcome
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/csv"
+	"errors"
+	"testing"
+	"time"
+)
+
+// fakeSessionRows serves session_timelines rows to writeSessionsCSV.
+type fakeSessionRows struct {
+	rows [][]any
+	next int
+	err  error
+}
+
+func (r *fakeSessionRows) Next() bool {
+	r.next++
+	return r.next <= len(r.rows)
+}
+
+func (r *fakeSessionRows) Scan(dest ...any) error {
+	row := r.rows[r.next-1]
+	*dest[0].(*int64) = row[0].(int64)
+	*dest[1].(*string) = row[1].(string)
+	*dest[2].(*sql.NullString) = row[2].(sql.NullString)
+	*dest[3].(*string) = row[3].(string)
+	*dest[4].(*time.Time) = row[4].(time.Time)
+	*dest[5].(*time.Time) = row[5].(time.Time)
+	*dest[6].(*[]byte) = []byte(row[6].(string))
+	return nil
+}
+
+func (r *fakeSessionRows) Err() error { return r.err }
+
+func TestParseExportRange(t *testing.T) {
+	now := time.Date(2026, 10, 16, 15, 4, 5, 0, time.UTC)
+	day := func(month time.Month, d int) time.Time { return time.Date(2026, month, d, 0, 0, 0, 0, time.UTC) }
+
+	tests := []struct {
+		name      string
+		from, to  string
+		wantStart time.Time
+		wantEnd   time.Time
+		wantErr   bool
+	}{
+		{"defaults to the last 30 days", "", "", day(9, 17), day(10, 17), false},
+		{"to is inclusive", "2026-10-01", "2026-10-01", day(10, 1), day(10, 2), false},
+		{"from only", "2026-10-10", "", day(10, 10), day(10, 17), false},
+		{"bad date", "10/01/2026", "", time.Time{}, time.Time{}, true},
+		{"from after to", "2026-10-02", "2026-10-01", time.Time{}, time.Time{}, true},
+		{"too long", "2025-01-01", "2026-10-01", time.Time{}, time.Time{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start, end, err := parseExportRange(tt.from, tt.to, now)
+			if tt.wantErr {
+				if !errors.Is(err, errExportRange) {
+					t.Errorf("expected errExportRange, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !start.Equal(tt.wantStart) || !end.Equal(tt.wantEnd) {
+				t.Errorf("got [%v, %v), want [%v, %v)", start, end, tt.wantStart, tt.wantEnd)
+			}
+		})
+	}
+}
+
+func TestWriteSessionsCSV(t *testing.T) {
+	start := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	rows := &fakeSessionRows{rows: [][]any{
+		{int64(7), "user-1", sql.NullString{String: "kk", Valid: true}, "completed", start, start.Add(185 * time.Second),
+			`[{"kind":"step","detail":"step1"},{"kind":"click","detail":"onboarding:step1_next:1"},
+			  {"kind":"step","detail":"step2 skipped"},{"kind":"step","detail":"step3"},
+			  {"kind":"step","detail":"step3"},{"kind":"step","detail":"step3.timeout.next"}]`},
+		{int64(8), "user-2", sql.NullString{}, "failed", start, start.Add(time.Second), `[]`},
+	}}
+
+	var out bytes.Buffer
+	if err := writeSessionsCSV(&out, rows); err != nil {
+		t.Fatalf("writeSessionsCSV: %v", err)
+	}
+
+	records, err := csv.NewReader(&out).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 3 {
+		t.Fatalf("expected a header and two rows, got %q", records)
+	}
+	want := []string{"7", "user-1", "kk", "completed", "2026-10-01T12:00:00Z", "2026-10-01T12:03:05Z", "185", "2", "step3"}
+	for n, v := range want {
+		if records[1][n] != v {
+			t.Errorf("column %s: got %q, want %q", exportHeader[n], records[1][n], v)
+		}
+	}
+	if records[2][2] != "" || records[2][7] != "0" || records[2][8] != "" {
+		t.Errorf("expected a session without guide or steps, got %q", records[2])
+	}
+}
+
+func TestWriteSessionsCSVTooLarge(t *testing.T) {
+	start := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	rows := &fakeSessionRows{}
+	for n := 0; n < 100; n++ {
+		rows.rows = append(rows.rows, []any{int64(n), "user-1", sql.NullString{}, "completed", start, start, `[]`})
+	}
+
+	var out bytes.Buffer
+	err := writeSessionsCSV(&limitedWriter{w: &out, left: 1024}, rows)
+	if !errors.Is(err, errExportTooLarge) {
+		t.Fatalf("expected errExportTooLarge, got %v", err)
+	}
+	if out.Len() > 1024 {
+		t.Errorf("wrote %d bytes past the limit", out.Len())
+	}
+}
+
+func TestWriteSessionsCSVError(t *testing.T) {
+	rows := &fakeSessionRows{err: errors.New("connection reset")}
+	if err := writeSessionsCSV(&bytes.Buffer{}, rows); err == nil {
+		t.Error("expected a failed read to fail the export")
+	}
+}
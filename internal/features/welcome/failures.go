@@ -0,0 +1,264 @@
+package welcome
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"welcomebot/internal/core/i18n"
+	"welcomebot/internal/core/notify"
+	"welcomebot/internal/core/queue"
+	"welcomebot/internal/shared"
+)
+
+const (
+	// staffNotifyLimit alerts are posted per guild within staffNotifyWindow;
+	// anything beyond that is counted and summarised in the next alert.
+	staffNotifyLimit  = 3
+	staffNotifyWindow = 10 * time.Minute
+
+	maxErrorSummaryLength = 1000
+
+	// notifyTimeout bounds sending one notification to the configured sinks.
+	notifyTimeout = 30 * time.Second
+)
+
+// ConsumeEvents processes worker events from the master queue until ctx is
+// cancelled, then waits for the notifications they started.
+func (f *Feature) ConsumeEvents(ctx context.Context) {
+	if f.events == nil {
+		return
+	}
+	defer f.notifying.Wait()
+
+	f.logger.Info("consuming worker events")
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
+
+		task, err := f.events.Dequeue(ctx, 30*time.Second)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			f.logger.Error("failed to dequeue worker event", "error", err)
+			time.Sleep(5 * time.Second)
+			continue
+		}
+
+		// No event available (timeout)
+		if task == nil {
+			continue
+		}
+
+		if err := f.handleEvent(ctx, task); err != nil {
+			f.logger.Error("worker event handling failed",
+				"task_id", task.ID,
+				"task_type", task.Type,
+				"retries", task.Retries,
+				"error", err,
+			)
+			if !f.retryEvent(ctx, task) {
+				// Left unacked: it is requeued when master restarts
+				continue
+			}
+		}
+
+		if err := f.events.Ack(context.Background(), task); err != nil {
+			f.logger.Warn("failed to ack worker event", "task_id", task.ID, "error", err)
+		}
+	}
+}
+
+// retryEvent puts a failed event back on the queue, or on the dead-letter
+// list once it has failed queue.MaxRetries times, so an event that keeps
+// failing can't be handled forever. It reports whether the event was
+// moved, and so can be acked.
+func (f *Feature) retryEvent(ctx context.Context, task *queue.Task) bool {
+	deadLettered, err := queue.Retry(ctx, f.events, *task)
+	if err != nil {
+		f.logger.Error("failed to requeue worker event", "task_id", task.ID, "error", err)
+		return false
+	}
+	if deadLettered {
+		f.logger.Warn("worker event failed too often, moved to dead-letter queue", "task_id", task.ID, "task_type", task.Type)
+	}
+	return true
+}
+
+// handleEvent routes worker events to their handlers.
+func (f *Feature) handleEvent(ctx context.Context, task *queue.Task) error {
+	switch task.Type {
+	case "onboarding_failed":
+		return f.handleOnboardingFailed(ctx, task)
+	case "onboarding_complete":
+		if err := f.handleOnboardingComplete(ctx, task); err != nil {
+			return err
+		}
+		f.notifyCompleted(ctx, task)
+		return nil
+	default:
+		f.logger.Warn("unknown worker event type", "task_type", task.Type)
+		return nil
+	}
+}
+
+// handleOnboardingFailed records a failed session, frees its slave and alerts staff.
+func (f *Feature) handleOnboardingFailed(ctx context.Context, task *queue.Task) error {
+	userID, _ := task.Payload["user_id"].(string)
+	slaveID, _ := task.Payload["slave_id"].(string)
+	step, _ := task.Payload["step"].(string)
+	errMsg, _ := task.Payload["error"].(string)
+
+	f.logger.Error("onboarding session failed",
+		"guild_id", task.GuildID,
+		"user_id", userID,
+		"slave_id", slaveID,
+		"step", step,
+		"error", errMsg,
+	)
+
+	// Free the slave and let the user start over
+	if slaveID != "" {
+		if err := f.setSlaveStatus(ctx, slaveID, SlaveStatusAvailable); err != nil {
+			f.logger.Warn("failed to mark slave as available", "error", err, "slave_id", slaveID)
+		}
+	}
+	sessionKey := fmt.Sprintf("%s%s:%s", sessionKeyPrefix, task.GuildID, userID)
+	if err := f.cache.Delete(ctx, sessionKey); err != nil {
+		f.logger.Warn("failed to clear session", "error", err, "user_id", userID)
+	}
+
+	return f.notifyStaff(ctx, task.GuildID, userID, step, errMsg)
+}
+
+// notifyStaff posts a failure alert in the guild's staff channel, if
+// configured, and sends it to the sinks configured for failures.
+func (f *Feature) notifyStaff(ctx context.Context, guildID, userID, step, errMsg string) error {
+	config, err := f.getWelcomeConfig(ctx, guildID)
+	if err != nil {
+		return fmt.Errorf("get welcome config: %w", err)
+	}
+	if config.StaffNotifyChannelID == "" && f.notifier == nil {
+		return nil
+	}
+
+	allowed, suppressed := f.staffThrottle.allow(guildID, time.Now())
+	if !allowed {
+		f.logger.Warn("staff notification throttled", "guild_id", guildID, "user_id", userID)
+		return nil
+	}
+
+	if step == "" {
+		step = "unknown"
+	}
+	if runes := []rune(errMsg); len(runes) > maxErrorSummaryLength {
+		errMsg = string(runes[:maxErrorSummaryLength]) + "…"
+	}
+
+	n := notify.Notification{
+		Kind:    notify.KindOnboardingFailed,
+		GuildID: guildID,
+		UserID:  userID,
+		Title:   f.i18n.T(ctx, guildID, "welcome.staff_alert_title"),
+		Message: f.i18n.TWithArgs(ctx, guildID, "welcome.staff_alert_description", map[string]string{
+			"user": fmt.Sprintf("<@%s>", userID),
+		}),
+		Color: int(shared.ColorError),
+		Fields: []notify.Field{
+			{Name: f.i18n.T(ctx, guildID, "welcome.staff_alert_user"), Value: fmt.Sprintf("<@%s> (%s)", userID, userID), Inline: true},
+			{Name: f.i18n.T(ctx, guildID, "welcome.staff_alert_step"), Value: step, Inline: true},
+			{Name: f.i18n.T(ctx, guildID, "welcome.staff_alert_error"), Value: fmt.Sprintf("```%s```", errMsg)},
+		},
+		Time:          time.Now(),
+		MentionRoleID: config.StaffRoleID,
+	}
+	if suppressed > 0 {
+		locale, _ := f.i18n.GetGuildLanguage(ctx, guildID)
+		n.Footer = f.i18n.TWithArgs(ctx, guildID, "welcome.staff_alert_suppressed", map[string]string{
+			"count": i18n.FormatNumber(locale, int64(suppressed)),
+		})
+	}
+
+	// Configured sinks are extra; only the staff channel holds the event back
+	f.sendNotification(ctx, n)
+
+	if config.StaffNotifyChannelID == "" {
+		return nil
+	}
+	staff := &notify.Channel{Session: f.session, ChannelID: config.StaffNotifyChannelID}
+	if err := staff.Notify(ctx, n); err != nil {
+		return fmt.Errorf("send staff notification: %w", err)
+	}
+
+	return nil
+}
+
+// sendNotification hands n to the configured sinks, if any, in the
+// background so a slow webhook doesn't hold up the event queue. Delivery
+// failures are logged; the event that caused n is still handled.
+func (f *Feature) sendNotification(ctx context.Context, n notify.Notification) {
+	if f.notifier == nil {
+		return
+	}
+
+	f.notifying.Add(1)
+	go func() {
+		defer f.notifying.Done()
+
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), notifyTimeout)
+		defer cancel()
+
+		if err := f.notifier.Notify(ctx, n); err != nil {
+			f.logger.Warn("failed to send notification", "error", err, "kind", n.Kind, "guild_id", n.GuildID, "user_id", n.UserID)
+		}
+	}()
+}
+
+// notifyThrottle limits alerts per guild within a rolling window.
+type notifyThrottle struct {
+	mu         sync.Mutex
+	limit      int
+	window     time.Duration
+	sent       map[string][]time.Time
+	suppressed map[string]int
+}
+
+func newNotifyThrottle(limit int, window time.Duration) *notifyThrottle {
+	return &notifyThrottle{
+		limit:      limit,
+		window:     window,
+		sent:       make(map[string][]time.Time),
+		suppressed: make(map[string]int),
+	}
+}
+
+// allow reports whether an alert for guildID may be sent now. When allowed it
+// also returns how many alerts were suppressed since the last one went out.
+func (t *notifyThrottle) allow(guildID string, now time.Time) (bool, int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	recent := t.sent[guildID][:0]
+	for _, sentAt := range t.sent[guildID] {
+		if now.Sub(sentAt) < t.window {
+			recent = append(recent, sentAt)
+		}
+	}
+
+	if len(recent) >= t.limit {
+		t.sent[guildID] = recent
+		t.suppressed[guildID]++
+		return false, 0
+	}
+
+	t.sent[guildID] = append(recent, now)
+	suppressed := t.suppressed[guildID]
+	delete(t.suppressed, guildID)
+	return true, suppressed
+}
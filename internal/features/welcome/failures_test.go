@@ -0,0 +1,101 @@
+package welcome
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"welcomebot/internal/core/queue"
+	"welcomebot/internal/fakes"
+)
+
+func TestNotifyThrottle(t *testing.T) {
+	throttle := newNotifyThrottle(2, time.Minute)
+	now := time.Now()
+
+	for n := 0; n < 2; n++ {
+		if ok, _ := throttle.allow("guild-1", now); !ok {
+			t.Fatalf("alert %d should be allowed", n)
+		}
+	}
+
+	if ok, _ := throttle.allow("guild-1", now); ok {
+		t.Error("expected third alert within window to be throttled")
+	}
+	if ok, _ := throttle.allow("guild-1", now); ok {
+		t.Error("expected fourth alert within window to be throttled")
+	}
+
+	// Other guilds are throttled independently
+	if ok, _ := throttle.allow("guild-2", now); !ok {
+		t.Error("expected alert for another guild to be allowed")
+	}
+
+	ok, suppressed := throttle.allow("guild-1", now.Add(2*time.Minute))
+	if !ok {
+		t.Fatal("expected alert to be allowed after window elapsed")
+	}
+	if suppressed != 2 {
+		t.Errorf("expected 2 suppressed alerts, got %d", suppressed)
+	}
+}
+
+func TestNotifyStaffTruncatesByRune(t *testing.T) {
+	ctx := context.Background()
+	cache := fakes.NewCache()
+	config := &WelcomeConfig{GuildID: "guild-1", StaffNotifyChannelID: "channel-staff"}
+	if err := cache.SetJSON(ctx, cacheKeyPrefix+"guild-1", config, 0); err != nil {
+		t.Fatal(err)
+	}
+	d := fakes.NewDiscord()
+	f := &Feature{cache: cache, i18n: fakes.I18n{}, logger: fakes.Logger{}, session: d.Session(),
+		staffThrottle: newNotifyThrottle(staffNotifyLimit, staffNotifyWindow)}
+
+	if err := f.notifyStaff(ctx, "guild-1", "user-1", "step3", strings.Repeat("エ", maxErrorSummaryLength+10)); err != nil {
+		t.Fatalf("notifyStaff: %v", err)
+	}
+
+	requests := d.Requests()
+	if len(requests) == 0 {
+		t.Fatal("expected the alert sent")
+	}
+	body := requests[len(requests)-1].Body
+	if !utf8.Valid(body) {
+		t.Error("expected the truncated error to stay valid UTF-8")
+	}
+	if n := strings.Count(string(body), "エ"); n != maxErrorSummaryLength {
+		t.Errorf("expected %d characters kept, got %d", maxErrorSummaryLength, n)
+	}
+}
+
+func TestConsumeEventsAcksHandledEvents(t *testing.T) {
+	events := fakes.NewQueue()
+	f := &Feature{events: events, logger: fakes.Logger{}}
+
+	if err := events.Enqueue(context.Background(), queue.Task{ID: "event-1", Type: "unknown"}); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		f.ConsumeEvents(ctx)
+	}()
+
+	deadline := time.Now().Add(time.Second)
+	for len(events.Tasks()) > 0 || len(events.Pending()) > 0 {
+		if time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	<-done
+
+	if pending := events.Pending(); len(pending) != 0 {
+		t.Errorf("expected handled event to be acked, %d still pending", len(pending))
+	}
+}
@@ -2,19 +2,26 @@ package welcome
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"os"
 	"strings"
+	"sync"
 	"time"
 
 	"welcomebot/internal/bot"
 	"welcomebot/internal/core/cache"
 	"welcomebot/internal/core/database"
+	"welcomebot/internal/core/discord"
 	"welcomebot/internal/core/i18n"
 	"welcomebot/internal/core/logger"
+	"welcomebot/internal/core/notify"
 	"welcomebot/internal/core/queue"
+	"welcomebot/internal/onboard"
 	"welcomebot/internal/shared"
 
 	"github.com/bwmarrin/discordgo"
+	"github.com/lib/pq"
 )
 
 const featureName = "welcome"
@@ -27,6 +34,26 @@ type Feature struct {
 	i18n    i18n.I18n
 	logger  logger.Logger
 	session *discordgo.Session
+	events  queue.Client
+
+	// notifier receives failure and completion notifications; nil sends none
+	notifier  notify.Notifier
+	notifying sync.WaitGroup // Notifications still being sent
+
+	staffThrottle    *notifyThrottle
+	maxGuildSessions int
+	maxAttempts      int           // Onboarding starts allowed per member per window
+	attemptWindow    time.Duration // How long a member's start count is kept
+	resumeExpiry     time.Duration // How long an unfinished onboarding can be resumed; 0 disables
+	wizardTTL        time.Duration // How long an untouched setup wizard is kept
+
+	// Test audio playback; nil newPlayer leaves the tool unavailable
+	newPlayer       func(ctx context.Context, s *discordgo.Session) onboard.AudioPlayer
+	testAudioMu     sync.Mutex
+	testAudioGuilds map[string]bool // Guilds with a test playback running
+
+	roleSyncMu     sync.Mutex
+	roleSyncGuilds map[string]bool // Guilds with a bulk role sync running
 }
 
 // New creates a new welcome feature.
@@ -35,13 +62,43 @@ func New(deps Dependencies) (*Feature, error) {
 		return nil, fmt.Errorf("validate dependencies: %w", err)
 	}
 
+	maxGuildSessions := deps.MaxSessionsPerGuild
+	if maxGuildSessions <= 0 {
+		maxGuildSessions = DefaultMaxSessionsPerGuild
+	}
+	maxAttempts := deps.MaxAttemptsPerUser
+	if maxAttempts <= 0 {
+		maxAttempts = DefaultMaxAttemptsPerUser
+	}
+	attemptWindow := deps.AttemptWindow
+	if attemptWindow <= 0 {
+		attemptWindow = DefaultAttemptWindow
+	}
+	wizardTTL := deps.WizardTTL
+	if wizardTTL <= 0 {
+		wizardTTL = DefaultWizardTTL
+	}
+
 	return &Feature{
 		db:      deps.DB,
 		cache:   deps.Cache,
 		queue:   deps.Queue,
 		i18n:    deps.I18n,
-		logger:  deps.Logger,
+		logger:  deps.Logger.Named(featureName),
 		session: deps.Session,
+		events:  deps.Events,
+
+		staffThrottle:    newNotifyThrottle(staffNotifyLimit, staffNotifyWindow),
+		maxGuildSessions: maxGuildSessions,
+		maxAttempts:      maxAttempts,
+		attemptWindow:    attemptWindow,
+		resumeExpiry:     deps.ResumeExpiry,
+		wizardTTL:        wizardTTL,
+		notifier:         deps.Notifier,
+
+		newPlayer:       deps.NewAudioPlayer,
+		testAudioGuilds: make(map[string]bool),
+		roleSyncGuilds:  make(map[string]bool),
 	}, nil
 }
 
@@ -55,19 +112,236 @@ func (f *Feature) HandleInteraction(ctx context.Context, s *discordgo.Session, i
 	customID := extractCustomID(i)
 	guildID := i.GuildID
 
+	if i.Type == discordgo.InteractionApplicationCommand {
+		switch i.ApplicationCommandData().Name {
+		case onboardingSummaryCommand:
+			return f.handleOnboardingSummary(ctx, s, i)
+		case onboardingExportCommand:
+			return f.handleOnboardingExport(ctx, s, i)
+		}
+		return bot.ErrNotHandled
+	}
+
 	// Menu button click - start configuration wizard
 	if customID == "menu:welcome:setup" {
 		return f.startWizard(ctx, s, i)
 	}
 
-	// Welcome button click - start onboarding
-	if customID == "welcome:start_onboarding" {
+	// Menu button click - list leaked onboarding voice channels
+	if customID == "menu:welcome:cleanup_channels" {
+		return f.showOrphanedChannels(ctx, s, i)
+	}
+
+	if customID == "welcome:cleanup_channels:confirm" {
+		return f.deleteOrphanedChannels(ctx, s, i)
+	}
+
+	// Menu button click - look up a member's last session timeline
+	if customID == "menu:welcome:timeline" {
+		return f.showTimelineModal(ctx, s, i)
+	}
+
+	if customID == "welcome:timeline:modal" {
+		return f.handleTimelineSubmit(ctx, s, i)
+	}
+
+	// Menu button click - list members currently onboarding
+	if customID == "menu:welcome:active_sessions" || strings.HasPrefix(customID, "welcome:active_sessions:page:") {
+		return f.showActiveSessions(ctx, s, i)
+	}
+
+	// Menu button click - play one guide file in the admin's voice channel
+	if customID == "menu:welcome:test_audio" {
+		return f.showTestAudioGuides(ctx, s, i)
+	}
+
+	// Menu button click - redraw the welcome button message in place
+	if customID == "menu:welcome:refresh_button" {
+		return f.refreshWelcomeButton(ctx, s, i)
+	}
+
+	// Menu button click - set the roles each guide grants on completion
+	if customID == "menu:welcome:guide_roles" {
+		return f.showGuideRoles(ctx, s, i, "")
+	}
+
+	if customID == "welcome:guide_roles:guide" {
+		return f.handleGuideRolesGuide(ctx, s, i)
+	}
+
+	if strings.HasPrefix(customID, "welcome:guide_roles:") {
+		return f.handleGuideRoleSelection(ctx, s, i, customID)
+	}
+
+	// Menu button click - set the categories used when the VC category is full
+	if customID == "menu:welcome:overflow_categories" {
+		return f.showOverflowCategories(ctx, s, i)
+	}
+
+	if customID == "welcome:overflow_categories:select" {
+		return f.handleOverflowCategorySelection(ctx, s, i)
+	}
+
+	// Menu button click - set the role members get as they join
+	if customID == "menu:welcome:initial_role" {
+		return f.showInitialRole(ctx, s, i)
+	}
+
+	if customID == "welcome:initial_role:select" {
+		return f.handleInitialRoleSelection(ctx, s, i)
+	}
+
+	// Menu button click - start onboarding for a member on their behalf
+	if customID == "menu:welcome:onboard_user" {
+		return f.showOnboardUser(ctx, s, i)
+	}
+
+	if customID == "welcome:onboard_user:select" {
+		return f.handleOnboardUserSelection(ctx, s, i)
+	}
+
+	// Menu button click - set the hours onboarding is available
+	if customID == "menu:welcome:schedule" {
+		return f.showSchedule(ctx, s, i)
+	}
+
+	if customID == "welcome:schedule:edit" {
+		return f.showScheduleModal(ctx, s, i)
+	}
+
+	if customID == "welcome:schedule:modal" {
+		return f.handleScheduleSubmit(ctx, s, i)
+	}
+
+	if customID == "welcome:schedule:clear" {
+		return f.clearSchedule(ctx, s, i)
+	}
+
+	// Menu button click - turn the welcome back flow on or off
+	if customID == "menu:welcome:welcome_back" {
+		return f.showWelcomeBack(ctx, s, i)
+	}
+
+	if customID == "welcome:welcome_back:toggle" {
+		return f.toggleWelcomeBack(ctx, s, i)
+	}
+
+	// Menu button click - let staff into onboarding channels
+	if customID == "menu:welcome:vc_access" {
+		return f.showVCAccess(ctx, s, i)
+	}
+
+	if customID == vcAccessRolesID || customID == vcAccessUsersID {
+		return f.handleVCAccessSelection(ctx, s, i, customID)
+	}
+
+	if customID == vcSyncCategoryID {
+		return f.toggleSyncCategory(ctx, s, i)
+	}
+
+	// Menu button click - set the roles granted and revoked on completion
+	if customID == "menu:welcome:completion_roles" {
+		return f.showCompletionRoles(ctx, s, i)
+	}
+
+	if customID == completionGrantRolesID || customID == completionRevokeRolesID {
+		return f.handleCompletionRolesSelection(ctx, s, i, customID)
+	}
+
+	if customID == completionRolesResetID {
+		return f.handleCompletionRolesReset(ctx, s, i)
+	}
+
+	// Menu button click - let members pick the session language
+	if customID == "menu:welcome:session_locales" {
+		return f.showSessionLocales(ctx, s, i)
+	}
+
+	if customID == sessionLocalesID {
+		return f.handleSessionLocalesSelection(ctx, s, i)
+	}
+
+	// Menu button click - post the panel members change their roles with
+	if customID == "menu:welcome:role_panel" {
+		return f.showRolePanelSettings(ctx, s, i)
+	}
+
+	if customID == rolePanelChannelSelectID {
+		return f.handleRolePanelChannelSelection(ctx, s, i)
+	}
+
+	// Menu button click - set the categories that share new VCs
+	if customID == "menu:welcome:spread_categories" {
+		return f.showSpreadCategories(ctx, s, i)
+	}
+
+	if customID == "welcome:spread_categories:select" {
+		return f.handleSpreadCategorySelection(ctx, s, i)
+	}
+
+	// Menu button click - replace role IDs the guild no longer has
+	if customID == "menu:admin:remap_roles" || strings.HasPrefix(customID, "welcome:remap_roles:page:") {
+		return f.showRemapRoles(ctx, s, i)
+	}
+
+	if strings.HasPrefix(customID, "welcome:remap_roles:pick:") {
+		return f.handleRemapSelection(ctx, s, i, customID)
+	}
+
+	if customID == "welcome:remap_roles:apply" {
+		return f.applyRemapRoles(ctx, s, i)
+	}
+
+	// Menu button click - give the member role to holders of another role
+	if customID == "menu:welcome:role_sync" {
+		return f.showRoleSync(ctx, s, i)
+	}
+
+	if customID == "welcome:role_sync:source" {
+		return f.handleRoleSyncSource(ctx, s, i)
+	}
+
+	if customID == "welcome:test_audio:guide" {
+		return f.handleTestAudioGuide(ctx, s, i)
+	}
+
+	if strings.HasPrefix(customID, "welcome:test_audio:step:") {
+		return f.handleTestAudioStep(ctx, s, i)
+	}
+
+	// Welcome button click - start onboarding. Buttons posted by older
+	// versions still work until they're refreshed.
+	if isWelcomeButtonID(customID) {
 		return f.handleOnboardingStart(ctx, s, i)
 	}
 
+	// Role panel - a member toggles one of their step 3 roles
+	if strings.HasPrefix(customID, rolePanelTogglePrefix) {
+		return f.handleRolePanelToggle(ctx, s, i)
+	}
+
+	// Resume prompt - continue an unfinished onboarding or start over
+	if customID == resumeOnboardingID || customID == restartOnboardingID {
+		return f.handleResumeChoice(ctx, s, i, customID)
+	}
+
+	// Welcome back prompt - restore a returning member's roles or onboard again
+	if customID == welcomeBackRestoreID || customID == welcomeBackOnboardID {
+		return f.handleWelcomeBackChoice(ctx, s, i, customID)
+	}
+
 	// Overwrite confirmation
 	if customID == "welcome:confirm_overwrite" {
-		return f.showStep1(ctx, s, i)
+		return f.editWizard(ctx, s, i)
+	}
+
+	// Reset: confirm, then delete the configuration
+	if customID == "welcome:reset" {
+		return f.showResetConfirmation(ctx, s, i)
+	}
+
+	if customID == "welcome:reset:confirm" {
+		return f.resetConfig(ctx, s, i)
 	}
 
 	if customID == "welcome:cancel" {
@@ -119,18 +393,48 @@ func (f *Feature) HandleInteraction(ctx context.Context, s *discordgo.Session, i
 		return f.handleVisitorRoleSelection(ctx, s, i)
 	}
 
+	// Step 10: Staff notification channel/role and shared VC (optional)
+	if strings.HasPrefix(customID, "welcome:staff_channel:") || strings.HasPrefix(customID, "welcome:staff_role:") ||
+		strings.HasPrefix(customID, "welcome:shared_vc:") || strings.HasPrefix(customID, "welcome:greeting:") {
+		return f.handleStaffSelection(ctx, s, i, customID)
+	}
+
+	if customID == "welcome:vc_name:edit" {
+		return f.showVCNameModal(ctx, s, i)
+	}
+
+	if customID == "welcome:ephemeral:toggle" {
+		return f.toggleEphemeralConfirmations(ctx, s, i)
+	}
+
+	if customID == "welcome:audio:toggle" {
+		return f.toggleTextOnly(ctx, s, i)
+	}
+
+	if customID == "welcome:vc_name:modal" {
+		return f.handleVCNameSubmit(ctx, s, i)
+	}
+
+	if customID == "welcome:finish" {
+		return f.finishWizard(ctx, s, i)
+	}
+
 	return bot.ErrNotHandled
 }
 
 // RegisterCommands returns slash commands for this feature.
 func (f *Feature) RegisterCommands() []*discordgo.ApplicationCommand {
-	return nil // Menu-driven only
+	// Setup is menu-driven; only the staff lookup is a command
+	return []*discordgo.ApplicationCommand{
+		onboardingSummaryCommandDefinition(),
+		onboardingExportCommandDefinition(),
+	}
 }
 
 // GetMenuButton returns the menu button for this feature.
 func (f *Feature) GetMenuButton() *bot.MenuButton {
 	return &bot.MenuButton{
-		Label:       "👋 Setup Welcome Onboarding",
+		Label:       shared.WithEmoji(shared.EmojiWelcome, "Setup Welcome Onboarding"),
 		CustomID:    "menu:welcome:setup",
 		Tier:        3,
 		Category:    "admin",
@@ -140,6 +444,175 @@ func (f *Feature) GetMenuButton() *bot.MenuButton {
 	}
 }
 
+// GetMenuButtons returns the setup button plus the admin tools.
+func (f *Feature) GetMenuButtons() []*bot.MenuButton {
+	return []*bot.MenuButton{
+		f.GetMenuButton(),
+		{
+			Label:       shared.WithEmoji(shared.EmojiGuide, "Guide Completion Roles"),
+			CustomID:    "menu:welcome:guide_roles",
+			Tier:        3,
+			Category:    "admin",
+			SubCategory: "configuration",
+			AdminOnly:   true,
+			IsCategory:  false,
+		},
+		{
+			Label:       shared.WithEmoji(shared.EmojiCategory, "Overflow VC Categories"),
+			CustomID:    "menu:welcome:overflow_categories",
+			Tier:        3,
+			Category:    "admin",
+			SubCategory: "configuration",
+			AdminOnly:   true,
+			IsCategory:  false,
+		},
+		{
+			Label:       shared.WithEmoji(shared.EmojiJoinRole, "Initial Join Role"),
+			CustomID:    "menu:welcome:initial_role",
+			Tier:        3,
+			Category:    "admin",
+			SubCategory: "configuration",
+			AdminOnly:   true,
+			IsCategory:  false,
+		},
+		{
+			Label:       shared.WithEmoji(shared.EmojiSchedule, "Onboarding Hours"),
+			CustomID:    "menu:welcome:schedule",
+			Tier:        3,
+			Category:    "admin",
+			SubCategory: "configuration",
+			AdminOnly:   true,
+			IsCategory:  false,
+		},
+		{
+			Label:       shared.WithEmoji(shared.EmojiWelcomeBack, "Welcome Back for Returning Members"),
+			CustomID:    "menu:welcome:welcome_back",
+			Tier:        3,
+			Category:    "admin",
+			SubCategory: "configuration",
+			AdminOnly:   true,
+			IsCategory:  false,
+		},
+		{
+			Label:       shared.WithEmoji(shared.EmojiCompletion, "Completion Roles"),
+			CustomID:    "menu:welcome:completion_roles",
+			Tier:        3,
+			Category:    "admin",
+			SubCategory: "configuration",
+			AdminOnly:   true,
+			IsCategory:  false,
+		},
+		{
+			Label:       shared.WithEmoji(shared.EmojiLanguage, "Session Languages"),
+			CustomID:    "menu:welcome:session_locales",
+			Tier:        3,
+			Category:    "admin",
+			SubCategory: "configuration",
+			AdminOnly:   true,
+			IsCategory:  false,
+		},
+		{
+			Label:       shared.WithEmoji(shared.EmojiRolePanel, "Role Panel"),
+			CustomID:    "menu:welcome:role_panel",
+			Tier:        3,
+			Category:    "admin",
+			SubCategory: "configuration",
+			AdminOnly:   true,
+			IsCategory:  false,
+		},
+		{
+			Label:       shared.WithEmoji(shared.EmojiVCAccess, "Onboarding VC Access"),
+			CustomID:    "menu:welcome:vc_access",
+			Tier:        3,
+			Category:    "admin",
+			SubCategory: "configuration",
+			AdminOnly:   true,
+			IsCategory:  false,
+		},
+		{
+			Label:       shared.WithEmoji(shared.EmojiCategory, "Spread VC Categories"),
+			CustomID:    "menu:welcome:spread_categories",
+			Tier:        3,
+			Category:    "admin",
+			SubCategory: "configuration",
+			AdminOnly:   true,
+			IsCategory:  false,
+		},
+		{
+			Label:       shared.WithEmoji(shared.EmojiRemap, "Remap Recreated Roles"),
+			CustomID:    "menu:admin:remap_roles",
+			Tier:        3,
+			Category:    "admin",
+			SubCategory: "tools",
+			AdminOnly:   true,
+			IsCategory:  false,
+		},
+		{
+			Label:       shared.WithEmoji(shared.EmojiRoleSync, "Sync Member Role"),
+			CustomID:    "menu:welcome:role_sync",
+			Tier:        3,
+			Category:    "admin",
+			SubCategory: "tools",
+			AdminOnly:   true,
+			IsCategory:  false,
+		},
+		{
+			Label:       shared.WithEmoji(shared.EmojiWelcome, "Onboard a Member"),
+			CustomID:    "menu:welcome:onboard_user",
+			Tier:        3,
+			Category:    "admin",
+			SubCategory: "tools",
+			AdminOnly:   true,
+			IsCategory:  false,
+		},
+		{
+			Label:       shared.WithEmoji(shared.EmojiCleanup, "Clean Up Onboarding VCs"),
+			CustomID:    "menu:welcome:cleanup_channels",
+			Tier:        3,
+			Category:    "admin",
+			SubCategory: "tools",
+			AdminOnly:   true,
+			IsCategory:  false,
+		},
+		{
+			Label:       shared.WithEmoji(shared.EmojiTimeline, "Onboarding Session Timeline"),
+			CustomID:    "menu:welcome:timeline",
+			Tier:        3,
+			Category:    "admin",
+			SubCategory: "tools",
+			AdminOnly:   true,
+			IsCategory:  false,
+		},
+		{
+			Label:       shared.WithEmoji(shared.EmojiSessions, "Active Onboarding Sessions"),
+			CustomID:    "menu:welcome:active_sessions",
+			Tier:        3,
+			Category:    "admin",
+			SubCategory: "tools",
+			AdminOnly:   true,
+			IsCategory:  false,
+		},
+		{
+			Label:       shared.WithEmoji(shared.EmojiTestAudio, "Test Guide Audio"),
+			CustomID:    "menu:welcome:test_audio",
+			Tier:        3,
+			Category:    "admin",
+			SubCategory: "tools",
+			AdminOnly:   true,
+			IsCategory:  false,
+		},
+		{
+			Label:       shared.WithEmoji(shared.EmojiRefresh, "Refresh Welcome Button"),
+			CustomID:    "menu:welcome:refresh_button",
+			Tier:        3,
+			Category:    "admin",
+			SubCategory: "tools",
+			AdminOnly:   true,
+			IsCategory:  false,
+		},
+	}
+}
+
 // getWizardState retrieves wizard state from cache.
 func (f *Feature) getWizardState(ctx context.Context, guildID string) (*WizardState, error) {
 	key := fmt.Sprintf("welcomebot:wizard:%s", guildID)
@@ -153,7 +626,11 @@ func (f *Feature) getWizardState(ctx context.Context, guildID string) (*WizardSt
 // saveWizardState saves wizard state to cache.
 func (f *Feature) saveWizardState(ctx context.Context, state *WizardState) error {
 	key := fmt.Sprintf("welcomebot:wizard:%s", state.GuildID)
-	return f.cache.SetJSON(ctx, key, state, 30*time.Minute)
+	ttl := f.wizardTTL
+	if ttl <= 0 {
+		ttl = DefaultWizardTTL
+	}
+	return f.cache.SetJSON(ctx, key, state, ttl)
 }
 
 // deleteWizardState removes wizard state from cache.
@@ -162,6 +639,61 @@ func (f *Feature) deleteWizardState(ctx context.Context, guildID string) error {
 	return f.cache.Delete(ctx, key)
 }
 
+var (
+	// errWizardExpired means no wizard state is saved: the wizard was
+	// finished, or abandoned until its state expired.
+	errWizardExpired = errors.New("wizard state expired")
+
+	// errWizardOutdated means a panel for one step was used while the
+	// wizard is on another, e.g. one left over from before a restart.
+	errWizardOutdated = errors.New("wizard is on another step")
+)
+
+// wizardStateAt returns the wizard state for a panel of the given step.
+// Applying an outdated panel would write into the current wizard and jump
+// it to the wrong step, so the state has to be on the panel's step.
+func (f *Feature) wizardStateAt(ctx context.Context, guildID string, step int) (*WizardState, error) {
+	state, err := f.getWizardState(ctx, guildID)
+	if errors.Is(err, cache.ErrNotFound) {
+		return nil, errWizardExpired
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get wizard state: %w", err)
+	}
+	if state.CurrentStep != step {
+		f.logger.Info("outdated wizard panel used",
+			"guild_id", guildID,
+			"panel_step", step,
+			"current_step", state.CurrentStep,
+		)
+		return nil, errWizardOutdated
+	}
+	return state, nil
+}
+
+// respondWizardUnavailable tells the admin a wizard panel can no longer be
+// used and to open the setup again. Other errors, such as the cache being
+// down, are returned as they are.
+func (f *Feature) respondWizardUnavailable(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate, err error) error {
+	switch {
+	case errors.Is(err, errWizardOutdated):
+		return f.respondErrorMessage(ctx, s, i, i.GuildID, "welcome.wizard_outdated")
+	case errors.Is(err, errWizardExpired):
+		return f.respondErrorMessage(ctx, s, i, i.GuildID, "welcome.wizard_expired")
+	}
+	return err
+}
+
+// resetWizardState replaces any leftover wizard state with state. The
+// leftover is deleted first, so a failed save leaves no state behind
+// rather than the abandoned one.
+func (f *Feature) resetWizardState(ctx context.Context, state *WizardState) error {
+	if err := f.deleteWizardState(ctx, state.GuildID); err != nil {
+		f.logger.Warn("failed to delete leftover wizard state", "error", err, "guild_id", state.GuildID)
+	}
+	return f.saveWizardState(ctx, state)
+}
+
 // startWizard initiates the welcome configuration wizard.
 func (f *Feature) startWizard(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) error {
 	guildID := i.GuildID
@@ -171,12 +703,27 @@ func (f *Feature) startWizard(ctx context.Context, s *discordgo.Session, i *disc
 		return f.showOverwriteConfirmation(ctx, s, i, config)
 	}
 
-	// Initialize wizard state
-	state := &WizardState{
-		GuildID:     guildID,
-		CurrentStep: 1,
+	// Start from a clean state; nothing from an abandoned wizard carries over
+	if err := f.resetWizardState(ctx, &WizardState{GuildID: guildID, CurrentStep: 1}); err != nil {
+		f.logger.Error("failed to save wizard state", "error", err)
 	}
-	if err := f.saveWizardState(ctx, state); err != nil {
+
+	return f.showStep1(ctx, s, i)
+}
+
+// editWizard restarts the wizard from the existing configuration. Seeding
+// the wizard state lets each step pre-select its current value, and keeps
+// settings the admin does not revisit.
+func (f *Feature) editWizard(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	guildID := i.GuildID
+
+	state := &WizardState{GuildID: guildID, CurrentStep: 1}
+	if config, err := f.getWelcomeConfig(ctx, guildID); err == nil && config != nil {
+		if err := bot.SeedWizardState(config, state); err != nil {
+			f.logger.Error("failed to seed wizard state", "error", err)
+		}
+	}
+	if err := f.resetWizardState(ctx, state); err != nil {
 		f.logger.Error("failed to save wizard state", "error", err)
 	}
 
@@ -187,14 +734,28 @@ func (f *Feature) startWizard(ctx context.Context, s *discordgo.Session, i *disc
 func (f *Feature) showOverwriteConfirmation(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate, config *WelcomeConfig) error {
 	guildID := i.GuildID
 
-	channel := fmt.Sprintf("<#%s>", config.WelcomeChannelID)
-	category := fmt.Sprintf("<#%s>", config.VCCategoryID)
+	// List the roles too, so admins see what the wizard will overwrite
+	roles := []string{
+		"Entrance: " + discord.RoleMention(config.EntranceRoleID),
+		"入会手続き: " + discord.RoleMention(config.NyukaiRoleID),
+		"説明会: " + discord.RoleMention(config.Setsumeikai1RoleID),
+		"説明会②: " + discord.RoleMention(config.Setsumeikai2RoleID),
+		"説明会③: " + discord.RoleMention(config.Setsumeikai3RoleID),
+		"会員: " + discord.RoleMention(config.MemberRoleID),
+		"Visitor: " + discord.RoleMention(config.VisitorRoleID),
+		"Staff: " + discord.RoleMention(config.StaffRoleID),
+	}
 
 	desc := f.i18n.TWithArgs(ctx, guildID, "welcome.current_config",
 		map[string]string{
-			"channel":  channel,
-			"category": category,
+			"channel":       discord.ChannelMention(config.WelcomeChannelID),
+			"category":      discord.ChannelMention(config.VCCategoryID),
+			"staff_channel": discord.ChannelMention(config.StaffNotifyChannelID),
+			"roles":         strings.Join(roles, "\n"),
 		})
+	if warning := f.hierarchyWarning(ctx, s, guildID, config); warning != "" {
+		desc += "\n\n" + warning
+	}
 
 	embed := &discordgo.MessageEmbed{
 		Title:       f.i18n.T(ctx, guildID, "welcome.overwrite_title"),
@@ -210,6 +771,11 @@ func (f *Feature) showOverwriteConfirmation(ctx context.Context, s *discordgo.Se
 					Style:    discordgo.DangerButton,
 					CustomID: "welcome:confirm_overwrite",
 				},
+				discordgo.Button{
+					Label:    f.i18n.T(ctx, guildID, "common.reset"),
+					Style:    discordgo.SecondaryButton,
+					CustomID: "welcome:reset",
+				},
 				discordgo.Button{
 					Label:    f.i18n.T(ctx, guildID, "common.cancel"),
 					Style:    discordgo.SecondaryButton,
@@ -225,6 +791,7 @@ func (f *Feature) showOverwriteConfirmation(ctx context.Context, s *discordgo.Se
 // showStep1 shows welcome channel selection.
 func (f *Feature) showStep1(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) error {
 	guildID := i.GuildID
+	state := bot.WizardDefaults(f.getWizardState(ctx, guildID))
 
 	embed := &discordgo.MessageEmbed{
 		Title:       f.i18n.T(ctx, guildID, "welcome.step1_title"),
@@ -235,14 +802,9 @@ func (f *Feature) showStep1(ctx context.Context, s *discordgo.Session, i *discor
 	components := []discordgo.MessageComponent{
 		discordgo.ActionsRow{
 			Components: []discordgo.MessageComponent{
-				discordgo.SelectMenu{
-					MenuType:    discordgo.ChannelSelectMenu,
-					CustomID:    "welcome:channel:select",
-					Placeholder: f.i18n.T(ctx, guildID, "welcome.select_channel"),
-					ChannelTypes: []discordgo.ChannelType{
-						discordgo.ChannelTypeGuildText,
-					},
-				},
+				discord.ChannelSelectMenu("welcome:channel:select",
+					f.i18n.T(ctx, guildID, "welcome.select_channel"),
+					[]discordgo.ChannelType{discordgo.ChannelTypeGuildText}, state.WelcomeChannelID),
 			},
 		},
 	}
@@ -253,6 +815,7 @@ func (f *Feature) showStep1(ctx context.Context, s *discordgo.Session, i *discor
 // showStep2 shows VC category selection.
 func (f *Feature) showStep2(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) error {
 	guildID := i.GuildID
+	state := bot.WizardDefaults(f.getWizardState(ctx, guildID))
 
 	embed := &discordgo.MessageEmbed{
 		Title:       f.i18n.T(ctx, guildID, "welcome.step2_title"),
@@ -263,14 +826,9 @@ func (f *Feature) showStep2(ctx context.Context, s *discordgo.Session, i *discor
 	components := []discordgo.MessageComponent{
 		discordgo.ActionsRow{
 			Components: []discordgo.MessageComponent{
-				discordgo.SelectMenu{
-					MenuType:    discordgo.ChannelSelectMenu,
-					CustomID:    "welcome:category:select",
-					Placeholder: f.i18n.T(ctx, guildID, "welcome.select_category"),
-					ChannelTypes: []discordgo.ChannelType{
-						discordgo.ChannelTypeGuildCategory,
-					},
-				},
+				discord.ChannelSelectMenu("welcome:category:select",
+					f.i18n.T(ctx, guildID, "welcome.select_category"),
+					[]discordgo.ChannelType{discordgo.ChannelTypeGuildCategory}, state.VCCategoryID),
 			},
 		},
 	}
@@ -283,7 +841,7 @@ func (f *Feature) handleChannelSelection(ctx context.Context, s *discordgo.Sessi
 	guildID := i.GuildID
 	values := i.MessageComponentData().Values
 	if len(values) == 0 {
-		return fmt.Errorf("no channel selected")
+		return bot.ErrNoChannelSelected
 	}
 
 	channelID := values[0]
@@ -293,10 +851,13 @@ func (f *Feature) handleChannelSelection(ctx context.Context, s *discordgo.Sessi
 		"channel_id", channelID,
 	)
 
-	// Update wizard state
-	state, err := f.getWizardState(ctx, guildID)
+	// Update wizard state. Step 1 can begin again after the state expired.
+	state, err := f.wizardStateAt(ctx, guildID, 1)
+	if errors.Is(err, errWizardExpired) {
+		state, err = &WizardState{GuildID: guildID, CurrentStep: 1}, nil
+	}
 	if err != nil {
-		state = &WizardState{GuildID: guildID}
+		return f.respondWizardUnavailable(ctx, s, i, err)
 	}
 	state.WelcomeChannelID = channelID
 	state.CurrentStep = 2
@@ -313,7 +874,7 @@ func (f *Feature) handleCategorySelection(ctx context.Context, s *discordgo.Sess
 	values := i.MessageComponentData().Values
 
 	if len(values) == 0 {
-		return fmt.Errorf("no category selected")
+		return bot.ErrNoCategorySelected
 	}
 
 	categoryID := values[0]
@@ -324,9 +885,9 @@ func (f *Feature) handleCategorySelection(ctx context.Context, s *discordgo.Sess
 	)
 
 	// Update wizard state
-	state, err := f.getWizardState(ctx, guildID)
+	state, err := f.wizardStateAt(ctx, guildID, 2)
 	if err != nil {
-		return fmt.Errorf("get wizard state: %w", err)
+		return f.respondWizardUnavailable(ctx, s, i, err)
 	}
 	state.VCCategoryID = categoryID
 	state.CurrentStep = 3
@@ -337,16 +898,23 @@ func (f *Feature) handleCategorySelection(ctx context.Context, s *discordgo.Sess
 	return f.showStep3(ctx, s, i)
 }
 
-// saveWelcomeConfig saves welcome configuration to database and cache.
+// saveWelcomeConfig saves welcome configuration to the database and drops
+// the cached copy.
 func (f *Feature) saveWelcomeConfig(ctx context.Context, config *WelcomeConfig) error {
 	query := `
 		INSERT INTO guild_welcome_config (
 			guild_id, welcome_channel_id, vc_category_id,
 			entrance_role_id, nyukai_role_id,
 			setsumeikai_1_role_id, setsumeikai_2_role_id, setsumeikai_3_role_id,
-			member_role_id, visitor_role_id, updated_at
+			member_role_id, visitor_role_id,
+			staff_notify_channel_id, staff_role_id, vc_name_template,
+			ephemeral_confirmations, shared_vc_channel_id, greeting_guide,
+			overflow_category_ids, spread_category_ids, initial_role_id, audio_enabled,
+			onboarding_schedule, welcome_back_enabled, vc_access_role_ids, vc_access_user_ids,
+			custom_completion_roles, completion_grant_role_ids, completion_revoke_role_ids,
+			session_locales, updated_at
 		)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, NOW())
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, NULLIF($19, ''), $20, NULLIF($21, ''), $22, $23, $24, $25, $26, $27, $28, NOW())
 		ON CONFLICT (guild_id)
 		DO UPDATE SET 
 			welcome_channel_id = $2,
@@ -358,6 +926,24 @@ func (f *Feature) saveWelcomeConfig(ctx context.Context, config *WelcomeConfig)
 			setsumeikai_3_role_id = $8,
 			member_role_id = $9,
 			visitor_role_id = $10,
+			staff_notify_channel_id = $11,
+			staff_role_id = $12,
+			vc_name_template = $13,
+			ephemeral_confirmations = $14,
+			shared_vc_channel_id = $15,
+			greeting_guide = $16,
+			overflow_category_ids = $17,
+			spread_category_ids = $18,
+			initial_role_id = NULLIF($19, ''),
+			audio_enabled = $20,
+			onboarding_schedule = NULLIF($21, ''),
+			welcome_back_enabled = $22,
+			vc_access_role_ids = $23,
+			vc_access_user_ids = $24,
+			custom_completion_roles = $25,
+			completion_grant_role_ids = $26,
+			completion_revoke_role_ids = $27,
+			session_locales = $28,
 			updated_at = NOW()
 	`
 
@@ -372,6 +958,24 @@ func (f *Feature) saveWelcomeConfig(ctx context.Context, config *WelcomeConfig)
 		config.Setsumeikai3RoleID,
 		config.MemberRoleID,
 		config.VisitorRoleID,
+		config.StaffNotifyChannelID,
+		config.StaffRoleID,
+		config.VCNameTemplate,
+		config.EphemeralConfirmations,
+		config.SharedVCChannelID,
+		config.GreetingGuide,
+		pq.Array(config.OverflowCategoryIDs),
+		pq.Array(config.SpreadCategoryIDs),
+		config.InitialRoleID,
+		!config.TextOnly,
+		config.Schedule,
+		config.WelcomeBack,
+		pq.Array(config.VCAccessRoleIDs),
+		pq.Array(config.VCAccessUserIDs),
+		config.CustomCompletionRoles,
+		pq.Array(config.CompletionGrantRoleIDs),
+		pq.Array(config.CompletionRevokeRoleIDs),
+		pq.Array(config.SessionLocales),
 	)
 	if err != nil {
 		return fmt.Errorf("save to database: %w", err)
@@ -379,10 +983,10 @@ func (f *Feature) saveWelcomeConfig(ctx context.Context, config *WelcomeConfig)
 
 	config.UpdatedAt = time.Now()
 
-	// Cache configuration
-	cacheKey := cacheKeyPrefix + config.GuildID
-	if err := f.cache.SetJSON(ctx, cacheKey, config, 0); err != nil {
-		f.logger.Warn("failed to cache welcome config", "error", err)
+	// The wizard doesn't set every column, e.g. the role panel, so config
+	// isn't the saved row; the next read loads it whole
+	if err := f.cache.Delete(ctx, cacheKeyPrefix+config.GuildID); err != nil {
+		f.logger.Warn("failed to invalidate welcome config", "error", err, "guild_id", config.GuildID)
 	}
 
 	f.logger.Info("welcome config saved",
@@ -398,32 +1002,68 @@ func (f *Feature) saveWelcomeConfig(ctx context.Context, config *WelcomeConfig)
 func (f *Feature) getWelcomeConfig(ctx context.Context, guildID string) (*WelcomeConfig, error) {
 	cacheKey := cacheKeyPrefix + guildID
 
-	var config WelcomeConfig
-	if err := f.cache.GetJSON(ctx, cacheKey, &config); err == nil {
-		return &config, nil
+	var cached WelcomeConfig
+	if err := f.cache.GetJSON(ctx, cacheKey, &cached); err == nil {
+		return &cached, nil
 	}
 
-	query := `
+	query := welcomeConfigQuery + `
+		WHERE guild_id = $1
+	`
+	config, err := scanWelcomeConfig(f.db.QueryRow(database.ReadFromReplica(ctx), query, guildID))
+	if err != nil {
+		return nil, err
+	}
+
+	f.cache.SetJSON(ctx, cacheKey, config, database.ReplicaCacheTTL)
+
+	return config, nil
+}
+
+// welcomeConfigQuery selects the columns scanWelcomeConfig reads.
+const welcomeConfigQuery = `
 		SELECT guild_id, welcome_channel_id, vc_category_id, button_message_id, 
 		       in_progress_role_id, completed_role_id,
 		       entrance_role_id, nyukai_role_id,
 		       setsumeikai_1_role_id, setsumeikai_2_role_id, setsumeikai_3_role_id,
-		       member_role_id, visitor_role_id, created_at, updated_at
-		FROM guild_welcome_config 
-		WHERE guild_id = $1
-	`
-	row := f.db.QueryRow(ctx, query, guildID)
+		       member_role_id, visitor_role_id,
+		       staff_notify_channel_id, staff_role_id, vc_name_template,
+		       ephemeral_confirmations, shared_vc_channel_id, greeting_guide, overflow_category_ids,
+		       spread_category_ids, initial_role_id, audio_enabled, onboarding_schedule,
+		       welcome_back_enabled, vc_access_role_ids, vc_access_user_ids,
+		       custom_completion_roles, completion_grant_role_ids, completion_revoke_role_ids,
+		       session_locales, role_panel_channel_id, role_panel_message_id,
+		       sync_category_permissions, created_at, updated_at
+		FROM guild_welcome_config`
+
+// rowScanner is a *sql.Row or *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
 
+// scanWelcomeConfig reads a row of welcomeConfigQuery.
+func scanWelcomeConfig(row rowScanner) (*WelcomeConfig, error) {
+	var config WelcomeConfig
 	var inProgressRole, completedRole, buttonMsg *string
 	var entranceRole, nyukaiRole, setsumeikai1Role, setsumeikai2Role, setsumeikai3Role, memberRole, visitorRole *string
+	var staffChannel, staffRole, vcNameTemplate, sharedVC, greetingGuide, initialRole, schedule *string
+	var rolePanelChannel, rolePanelMessage *string
+	var audioEnabled bool
 	err := row.Scan(&config.GuildID, &config.WelcomeChannelID, &config.VCCategoryID,
 		&buttonMsg, &inProgressRole, &completedRole,
 		&entranceRole, &nyukaiRole,
 		&setsumeikai1Role, &setsumeikai2Role, &setsumeikai3Role,
-		&memberRole, &visitorRole, &config.CreatedAt, &config.UpdatedAt)
+		&memberRole, &visitorRole, &staffChannel, &staffRole, &vcNameTemplate,
+		&config.EphemeralConfirmations, &sharedVC, &greetingGuide, pq.Array(&config.OverflowCategoryIDs),
+		pq.Array(&config.SpreadCategoryIDs), &initialRole, &audioEnabled, &schedule,
+		&config.WelcomeBack, pq.Array(&config.VCAccessRoleIDs), pq.Array(&config.VCAccessUserIDs),
+		&config.CustomCompletionRoles, pq.Array(&config.CompletionGrantRoleIDs), pq.Array(&config.CompletionRevokeRoleIDs),
+		pq.Array(&config.SessionLocales), &rolePanelChannel, &rolePanelMessage,
+		&config.SyncCategoryPermissions, &config.CreatedAt, &config.UpdatedAt)
 	if err != nil {
 		return nil, err
 	}
+	config.TextOnly = !audioEnabled
 
 	if buttonMsg != nil {
 		config.ButtonMessageID = *buttonMsg
@@ -455,34 +1095,40 @@ func (f *Feature) getWelcomeConfig(ctx context.Context, guildID string) (*Welcom
 	if visitorRole != nil {
 		config.VisitorRoleID = *visitorRole
 	}
-
-	f.cache.SetJSON(ctx, cacheKey, &config, 0)
+	if staffChannel != nil {
+		config.StaffNotifyChannelID = *staffChannel
+	}
+	if staffRole != nil {
+		config.StaffRoleID = *staffRole
+	}
+	if vcNameTemplate != nil {
+		config.VCNameTemplate = *vcNameTemplate
+	}
+	if sharedVC != nil {
+		config.SharedVCChannelID = *sharedVC
+	}
+	if greetingGuide != nil {
+		config.GreetingGuide = *greetingGuide
+	}
+	if initialRole != nil {
+		config.InitialRoleID = *initialRole
+	}
+	if schedule != nil {
+		config.Schedule = *schedule
+	}
+	if rolePanelChannel != nil {
+		config.RolePanelChannelID = *rolePanelChannel
+	}
+	if rolePanelMessage != nil {
+		config.RolePanelMessageID = *rolePanelMessage
+	}
 
 	return &config, nil
 }
 
 // postWelcomeButton posts the welcome button in the configured channel.
 func (f *Feature) postWelcomeButton(ctx context.Context, guildID, channelID string) error {
-	embed := &discordgo.MessageEmbed{
-		Title:       f.i18n.T(ctx, guildID, "welcome.button_title"),
-		Description: f.i18n.T(ctx, guildID, "welcome.button_description"),
-		Color:       int(shared.ColorInfo),
-	}
-
-	components := []discordgo.MessageComponent{
-		discordgo.ActionsRow{
-			Components: []discordgo.MessageComponent{
-				discordgo.Button{
-					Label:    f.i18n.T(ctx, guildID, "welcome.start_button"),
-					Style:    discordgo.PrimaryButton,
-					CustomID: "welcome:start_onboarding",
-					Emoji: &discordgo.ComponentEmoji{
-						Name: "👋",
-					},
-				},
-			},
-		},
-	}
+	embed, components := f.welcomeButtonMessage(ctx, guildID)
 
 	msg, err := f.session.ChannelMessageSendComplex(channelID, &discordgo.MessageSend{
 		Embeds:     []*discordgo.MessageEmbed{embed},
@@ -493,11 +1139,14 @@ func (f *Feature) postWelcomeButton(ctx context.Context, guildID, channelID stri
 	}
 
 	// Update button message ID in database
-	query := `UPDATE guild_welcome_config SET button_message_id = $1 WHERE guild_id = $2`
-	_, err = f.db.Exec(ctx, query, msg.ID, guildID)
+	query := `UPDATE guild_welcome_config SET button_message_id = $1, button_version = $2 WHERE guild_id = $3`
+	_, err = f.db.Exec(ctx, query, msg.ID, welcomeButtonVersion, guildID)
 	if err != nil {
 		f.logger.Warn("failed to update button message ID", "error", err)
 	}
+	if err := f.cache.Delete(ctx, cacheKeyPrefix+guildID); err != nil {
+		f.logger.Warn("failed to invalidate welcome config", "error", err)
+	}
 
 	f.logger.Info("welcome button posted",
 		"guild_id", guildID,
@@ -509,88 +1158,118 @@ func (f *Feature) postWelcomeButton(ctx context.Context, guildID, channelID stri
 }
 
 // handleOnboardingStart handles when a user clicks the start onboarding button.
+// Members with a checkpoint from an unfinished onboarding are asked whether
+// to resume it first.
 func (f *Feature) handleOnboardingStart(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) error {
 	guildID := i.GuildID
 	userID := i.Member.User.ID
 
-	// Get config
+	// Outside the guild's onboarding hours, say when they open instead
 	config, err := f.getWelcomeConfig(ctx, guildID)
-	if err != nil {
-		return f.respondErrorMessage(ctx, s, i, guildID, "welcome.config_not_found")
+	if err == nil {
+		now := time.Now()
+		if schedule, closed := f.onboardingClosed(config, now); closed {
+			return f.respondOnboardingClosed(ctx, s, i, guildID, schedule, now)
+		}
+	}
+
+	// Rapid repeat clicks collapse into the first one
+	if !f.claimStartClick(ctx, guildID, userID) {
+		return f.respondErrorMessage(ctx, s, i, guildID, "welcome.start_in_progress")
+	}
+
+	if !f.sessionActive(ctx, guildID, userID) {
+		if checkpoint := f.getCheckpoint(ctx, guildID, userID); checkpoint != nil {
+			return f.showResumePrompt(ctx, s, i, checkpoint)
+		}
+		if err == nil && config.WelcomeBack {
+			if summary := f.returningMember(ctx, guildID, userID); summary != nil {
+				return f.showWelcomeBackPrompt(ctx, s, i, summary)
+			}
+		}
 	}
+	return f.startOnboarding(ctx, s, i, userID, nil)
+}
+
+// sessionActive reports whether the member has an onboarding session.
+func (f *Feature) sessionActive(ctx context.Context, guildID, userID string) bool {
+	var existingSession OnboardingSession
+	return f.cache.GetJSON(ctx, fmt.Sprintf("%s%s:%s", sessionKeyPrefix, guildID, userID), &existingSession) == nil
+}
+
+// startOnboarding hands the member userID to a slave. A non-nil checkpoint
+// has the worker skip the steps it records as completed. When userID is not
+// the member who clicked, staff are onboarding them: the attempt cooldown
+// doesn't apply and the start isn't counted against the member.
+func (f *Feature) startOnboarding(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate, userID string, checkpoint *onboard.Checkpoint) error {
+	guildID := i.GuildID
+	assistedBy := ""
+	if userID != i.Member.User.ID {
+		assistedBy = i.Member.User.ID
+	}
+
+	// Get config, along with the role configs handed to the worker
+	cached := f.fetchStartConfigs(ctx, guildID)
+	config := cached.welcome
+	if config == nil {
+		var err error
+		if config, err = f.getWelcomeConfig(ctx, guildID); err != nil {
+			return f.respondErrorMessage(ctx, s, i, guildID, "welcome.config_not_found")
+		}
+	}
+
+	// Hold the member's start lock from the session check to the session write
+	unlock, ok := f.lockStart(ctx, guildID, userID)
+	if !ok {
+		return f.respondErrorMessage(ctx, s, i, guildID, "welcome.start_in_progress")
+	}
+	defer unlock()
 
 	// Check if user already has active session
 	sessionKey := fmt.Sprintf("%s%s:%s", sessionKeyPrefix, guildID, userID)
-	var existingSession OnboardingSession
-	if err := f.cache.GetJSON(ctx, sessionKey, &existingSession); err == nil {
+	if f.sessionActive(ctx, guildID, userID) {
+		if assistedBy != "" {
+			return f.respondErrorMessage(ctx, s, i, guildID, "welcome.onboard_user_active")
+		}
 		return f.respondErrorMessage(ctx, s, i, guildID, "welcome.session_already_active")
 	}
 
+	// Members who keep abandoning onboarding wait out a cooldown
+	if assistedBy == "" {
+		if wait := f.attemptCooldown(ctx, guildID, userID); wait > 0 {
+			return f.respondAttemptCooldown(ctx, s, i, guildID, wait)
+		}
+	}
+
+	// Every category at Discord's channel limit would fail in the worker
+	if config.SharedVCChannelID == "" && categoriesFull(s, guildID, config) {
+		f.logger.Warn("onboarding categories full", "guild_id", guildID)
+		return f.respondErrorMessage(ctx, s, i, guildID, "welcome.vc_capacity_reached")
+	}
+
+	// Keep one guild from taking every slave
+	if !f.acquireGuildSlot(ctx, guildID) {
+		return f.respondErrorMessage(ctx, s, i, guildID, "welcome.guild_sessions_full")
+	}
+
 	// Find available slave
 	slaveID, err := f.findAvailableSlave(ctx)
 	if err != nil || slaveID == "" {
+		f.releaseGuildSlot(ctx, guildID)
 		return f.respondErrorMessage(ctx, s, i, guildID, "welcome.no_slaves_available")
 	}
 
-	// Get age range, gender, voice type, and other roles configs
-	ageRangeConfig, _ := f.getAgeRangeConfig(ctx, guildID)
-	genderConfig, _ := f.getGenderConfig(ctx, guildID)
-	voiceTypeConfig, _ := f.getVoiceTypeConfig(ctx, guildID)
-	otherRolesConfig, _ := f.getOtherRolesConfig(ctx, guildID)
-
 	// Create onboarding task with all role configurations
+	onboarding := f.loadOnboardingConfig(ctx, config, cached)
+	onboarding.VCSeq = f.nextVCSeq(ctx, guildID)
 	payload := map[string]interface{}{
-		"user_id":            userID,
-		"category_id":        config.VCCategoryID,
-		"slave_id":           slaveID,
-		"in_progress_role":   config.InProgressRoleID,
-		"completed_role":     config.CompletedRoleID,
-		"entrance_role":      config.EntranceRoleID,
-		"nyukai_role":        config.NyukaiRoleID,
-		"setsumeikai_1_role": config.Setsumeikai1RoleID,
-		"setsumeikai_2_role": config.Setsumeikai2RoleID,
-		"setsumeikai_3_role": config.Setsumeikai3RoleID,
-		"member_role":        config.MemberRoleID,
-	}
-
-	// Add age range roles if configured
-	if ageRangeConfig != nil {
-		payload["age_20_early_role"] = ageRangeConfig.Age20EarlyRoleID
-		payload["age_20_late_role"] = ageRangeConfig.Age20LateRoleID
-		payload["age_30_early_role"] = ageRangeConfig.Age30EarlyRoleID
-		payload["age_30_late_role"] = ageRangeConfig.Age30LateRoleID
-		payload["age_40_early_role"] = ageRangeConfig.Age40EarlyRoleID
-		payload["age_40_late_role"] = ageRangeConfig.Age40LateRoleID
-	}
-
-	// Add gender roles if configured
-	if genderConfig != nil {
-		payload["male_role"] = genderConfig.MaleRoleID
-		payload["female_role"] = genderConfig.FemaleRoleID
-	}
-
-	// Add voice type roles if configured
-	if voiceTypeConfig != nil {
-		payload["high_voice_role"] = voiceTypeConfig.HighRoleID
-		payload["mid_high_voice_role"] = voiceTypeConfig.MidHighRoleID
-		payload["mid_voice_role"] = voiceTypeConfig.MidRoleID
-		payload["mid_low_voice_role"] = voiceTypeConfig.MidLowRoleID
-		payload["low_voice_role"] = voiceTypeConfig.LowRoleID
-	}
-
-	// Add other roles if configured
-	if otherRolesConfig != nil {
-		payload["ero_ok_role"] = otherRolesConfig.EroOkRoleID
-		payload["ero_ng_role"] = otherRolesConfig.EroNgRoleID
-		payload["neochi_ok_role"] = otherRolesConfig.NeochiOkRoleID
-		payload["neochi_ng_role"] = otherRolesConfig.NeochiNgRoleID
-		payload["neochi_disconnect_role"] = otherRolesConfig.NeochiDisconnectRoleID
-		payload["dm_ok_role"] = otherRolesConfig.DmOkRoleID
-		payload["dm_ng_role"] = otherRolesConfig.DmNgRoleID
-		payload["friend_ok_role"] = otherRolesConfig.FriendOkRoleID
-		payload["friend_ng_role"] = otherRolesConfig.FriendNgRoleID
-		payload["bunnyclub_event_role"] = otherRolesConfig.BunnyclubEventRoleID
-		payload["user_event_role"] = otherRolesConfig.UserEventRoleID
+		"user_id":                userID,
+		"slave_id":               slaveID,
+		onboard.ConfigVersionKey: onboard.OnboardingConfigVersion,
+		onboard.ConfigKey:        onboarding,
+	}
+	if checkpoint != nil {
+		payload["resume"] = checkpoint
 	}
 
 	task := queue.Task{
@@ -603,14 +1282,28 @@ func (f *Feature) handleOnboardingStart(ctx context.Context, s *discordgo.Sessio
 
 	// Enqueue task
 	if err := f.queue.Enqueue(ctx, task); err != nil {
+		f.releaseSlaveClaim(ctx, slaveID)
+		f.releaseGuildSlot(ctx, guildID)
+		if errors.Is(err, queue.ErrFull) {
+			// Workers are behind; a task queued now would start long after
+			// the member gave up waiting
+			depth, _ := f.queue.Depth(ctx)
+			f.logger.Warn("onboarding queue full", "guild_id", guildID, "user_id", userID, "depth", depth)
+			return f.respondErrorMessage(ctx, s, i, guildID, "welcome.queue_full")
+		}
 		f.logger.Error("failed to enqueue onboarding task", "error", err)
 		return f.respondErrorMessage(ctx, s, i, guildID, "welcome.enqueue_failed")
 	}
 
+	if assistedBy == "" {
+		f.recordAttempt(ctx, guildID, userID)
+	}
+
 	// Mark slave as busy
 	if err := f.setSlaveStatus(ctx, slaveID, SlaveStatusBusy); err != nil {
 		f.logger.Warn("failed to mark slave as busy", "error", err)
 	}
+	f.releaseSlaveClaim(ctx, slaveID)
 
 	// Create session record
 	session := OnboardingSession{
@@ -623,36 +1316,225 @@ func (f *Feature) handleOnboardingStart(ctx context.Context, s *discordgo.Sessio
 		f.logger.Warn("failed to cache session", "error", err)
 	}
 
-	f.logger.Info("onboarding started",
-		"guild_id", guildID,
-		"user_id", userID,
-		"slave_id", slaveID,
-	)
+	f.logger.Info("onboarding started",
+		"guild_id", guildID,
+		"user_id", userID,
+		"slave_id", slaveID,
+		"resumed", checkpoint != nil,
+		"assisted_by", assistedBy,
+	)
+
+	// Respond to user
+	embed := &discordgo.MessageEmbed{
+		Title:       f.i18n.T(ctx, guildID, "welcome.starting_title"),
+		Description: f.i18n.T(ctx, guildID, "welcome.starting_description"),
+		Color:       int(shared.ColorSuccess),
+	}
+	if assistedBy != "" {
+		embed.Description = f.i18n.TWithArgs(ctx, guildID, "welcome.onboard_user_started", map[string]string{
+			"user": fmt.Sprintf("<@%s>", userID),
+		})
+	}
+
+	return s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Embeds: []*discordgo.MessageEmbed{embed},
+			Flags:  discordgo.MessageFlagsEphemeral,
+		},
+	})
+}
+
+// onboardingConfig snapshots a guild's settings for an onboarding task.
+// Role groups that are reset or were never configured are nil and leave
+// their roles empty, which the worker reads as "skip".
+func onboardingConfig(
+	config *WelcomeConfig,
+	ageRange *AgeRangeConfig,
+	gender *GenderConfig,
+	voiceType *VoiceTypeConfig,
+	otherRoles *OtherRolesConfig,
+	guideRoles map[string]GuideCompletionRoles,
+) onboard.OnboardingConfig {
+	onboarding := onboard.OnboardingConfig{
+		CategoryID:              config.VCCategoryID,
+		SpreadCategoryIDs:       config.SpreadCategoryIDs,
+		OverflowCategoryIDs:     config.OverflowCategoryIDs,
+		VCNameTemplate:          config.VCNameTemplate,
+		EphemeralConfirmations:  config.EphemeralConfirmations,
+		SharedVCChannelID:       config.SharedVCChannelID,
+		GreetingGuide:           config.GreetingGuide,
+		TextOnly:                config.TextOnly,
+		VCAccessRoles:           config.VCAccessRoleIDs,
+		VCAccessUsers:           config.VCAccessUserIDs,
+		SyncCategoryPermissions: config.SyncCategoryPermissions,
+		InProgressRole:          config.InProgressRoleID,
+		CompletedRole:           config.CompletedRoleID,
+		EntranceRole:            config.EntranceRoleID,
+		InitialRole:             config.InitialRoleID,
+		NyukaiRole:              config.NyukaiRoleID,
+		Setsumeikai1Role:        config.Setsumeikai1RoleID,
+		Setsumeikai2Role:        config.Setsumeikai2RoleID,
+		Setsumeikai3Role:        config.Setsumeikai3RoleID,
+		MemberRole:              config.MemberRoleID,
+		VisitorRole:             config.VisitorRoleID,
+		CompletionChanges: onboard.CompletionChanges{
+			Custom: config.CustomCompletionRoles,
+			Grant:  config.CompletionGrantRoleIDs,
+			Revoke: config.CompletionRevokeRoleIDs,
+		},
+		Locales: config.SessionLocales,
+	}
+
+	if ageRange != nil {
+		onboarding.Age20EarlyRole = ageRange.Age20EarlyRoleID
+		onboarding.Age20LateRole = ageRange.Age20LateRoleID
+		onboarding.Age30EarlyRole = ageRange.Age30EarlyRoleID
+		onboarding.Age30LateRole = ageRange.Age30LateRoleID
+		onboarding.Age40EarlyRole = ageRange.Age40EarlyRoleID
+		onboarding.Age40LateRole = ageRange.Age40LateRoleID
+	}
+
+	if gender != nil {
+		onboarding.MaleRole = gender.MaleRoleID
+		onboarding.FemaleRole = gender.FemaleRoleID
+	}
+
+	if voiceType != nil {
+		onboarding.HighVoiceRole = voiceType.HighRoleID
+		onboarding.MidHighVoiceRole = voiceType.MidHighRoleID
+		onboarding.MidVoiceRole = voiceType.MidRoleID
+		onboarding.MidLowVoiceRole = voiceType.MidLowRoleID
+		onboarding.LowVoiceRole = voiceType.LowRoleID
+	}
+
+	if otherRoles != nil {
+		onboarding.EroOkRole = otherRoles.EroOkRoleID
+		onboarding.EroNgRole = otherRoles.EroNgRoleID
+		onboarding.NeochiOkRole = otherRoles.NeochiOkRoleID
+		onboarding.NeochiNgRole = otherRoles.NeochiNgRoleID
+		onboarding.NeochiDisconnectRole = otherRoles.NeochiDisconnectRoleID
+		onboarding.DmOkRole = otherRoles.DmOkRoleID
+		onboarding.DmNgRole = otherRoles.DmNgRoleID
+		onboarding.FriendOkRole = otherRoles.FriendOkRoleID
+		onboarding.FriendNgRole = otherRoles.FriendNgRoleID
+		onboarding.BunnyclubEventRole = otherRoles.BunnyclubEventRoleID
+		onboarding.UserEventRole = otherRoles.UserEventRoleID
+	}
+
+	// Guides with their own completion roles; the rest use the guild's
+	if len(guideRoles) > 0 {
+		onboarding.GuideCompletionRoles = guideCompletionRolesPayload(guideRoles)
+	}
+
+	return onboarding
+}
+
+// cachedConfigs are the configs an onboarding start reads, fetched from
+// the cache in one round trip. Nil fields weren't cached.
+type cachedConfigs struct {
+	welcome    *WelcomeConfig
+	ageRange   *AgeRangeConfig
+	gender     *GenderConfig
+	voiceType  *VoiceTypeConfig
+	otherRoles *OtherRolesConfig
+	guideRoles map[string]GuideCompletionRoles
+}
+
+// fetchStartConfigs reads the welcome config and the role configs an
+// onboarding start needs from the cache together, rather than a round
+// trip each on the way to answering the click.
+func (f *Feature) fetchStartConfigs(ctx context.Context, guildID string) *cachedConfigs {
+	var (
+		welcome    WelcomeConfig
+		ageRange   AgeRangeConfig
+		gender     GenderConfig
+		voiceType  VoiceTypeConfig
+		otherRoles OtherRolesConfig
+		guideRoles map[string]GuideCompletionRoles
+	)
+	found, err := f.cache.GetJSONMulti(ctx, []string{
+		cacheKeyPrefix + guildID,
+		ageRangeCacheKeyPrefix + guildID,
+		genderCacheKeyPrefix + guildID,
+		voiceTypeCacheKeyPrefix + guildID,
+		otherRolesCacheKeyPrefix + guildID,
+		guideRolesKeyPrefix + guildID,
+	}, &welcome, &ageRange, &gender, &voiceType, &otherRoles, &guideRoles)
+	if err != nil {
+		f.logger.Warn("failed to fetch onboarding configs", "error", err, "guild_id", guildID)
+		return &cachedConfigs{}
+	}
+
+	var cached cachedConfigs
+	if found[0] {
+		cached.welcome = &welcome
+	}
+	if found[1] {
+		cached.ageRange = &ageRange
+	}
+	if found[2] {
+		cached.gender = &gender
+	}
+	if found[3] {
+		cached.voiceType = &voiceType
+	}
+	if found[4] {
+		cached.otherRoles = &otherRoles
+	}
+	if found[5] {
+		cached.guideRoles = guideRoles
+	}
+	return &cached
+}
+
+// loadOnboardingConfig gathers the welcome config and the role configs
+// owned by other features into the settings a worker runs onboarding with.
+// Role configs missing from cached, which may be nil, are loaded through
+// their getters.
+func (f *Feature) loadOnboardingConfig(ctx context.Context, config *WelcomeConfig, cached *cachedConfigs) onboard.OnboardingConfig {
+	guildID := config.GuildID
+	if cached == nil {
+		cached = &cachedConfigs{}
+	}
 
-	// Respond to user
-	embed := &discordgo.MessageEmbed{
-		Title:       f.i18n.T(ctx, guildID, "welcome.starting_title"),
-		Description: f.i18n.T(ctx, guildID, "welcome.starting_description"),
-		Color:       int(shared.ColorSuccess),
+	// Get age range, gender, voice type, and other roles configs
+	ageRangeConfig := cached.ageRange
+	if ageRangeConfig == nil {
+		ageRangeConfig, _ = f.getAgeRangeConfig(ctx, guildID)
+	}
+	genderConfig := cached.gender
+	if genderConfig == nil {
+		genderConfig, _ = f.getGenderConfig(ctx, guildID)
+	}
+	voiceTypeConfig := cached.voiceType
+	if voiceTypeConfig == nil {
+		voiceTypeConfig, _ = f.getVoiceTypeConfig(ctx, guildID)
+	}
+	otherRolesConfig := cached.otherRoles
+	if otherRolesConfig == nil {
+		otherRolesConfig, _ = f.getOtherRolesConfig(ctx, guildID)
+	}
+	guideRoles := cached.guideRoles
+	if guideRoles == nil {
+		var err error
+		if guideRoles, err = f.getGuideCompletionRoles(ctx, guildID); err != nil {
+			f.logger.Warn("failed to load guide completion roles", "error", err, "guild_id", guildID)
+		}
 	}
 
-	return s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
-		Type: discordgo.InteractionResponseChannelMessageWithSource,
-		Data: &discordgo.InteractionResponseData{
-			Embeds: []*discordgo.MessageEmbed{embed},
-			Flags:  discordgo.MessageFlagsEphemeral,
-		},
-	})
+	return onboardingConfig(config, ageRangeConfig, genderConfig, voiceTypeConfig, otherRolesConfig, guideRoles)
 }
 
-// findAvailableSlave finds an available slave bot.
+// findAvailableSlave finds an available slave bot and claims it. The
+// caller releases the claim once the slave is marked busy.
 func (f *Feature) findAvailableSlave(ctx context.Context) (string, error) {
 	for _, slaveID := range SlaveIDs {
 		status, err := f.getSlaveStatus(ctx, slaveID)
 		if err != nil {
 			continue
 		}
-		if status == SlaveStatusAvailable {
+		if status == SlaveStatusAvailable && f.claimSlave(ctx, slaveID) {
 			return slaveID, nil
 		}
 	}
@@ -690,11 +1572,29 @@ func extractCustomID(i *discordgo.InteractionCreate) string {
 	switch i.Type {
 	case discordgo.InteractionMessageComponent:
 		return i.MessageComponentData().CustomID
+	case discordgo.InteractionModalSubmit:
+		return i.ModalSubmitData().CustomID
 	default:
 		return ""
 	}
 }
 
+// modalValue returns the value of a text input in a submitted modal.
+func modalValue(data discordgo.ModalSubmitInteractionData, customID string) string {
+	for _, component := range data.Components {
+		row, ok := component.(*discordgo.ActionsRow)
+		if !ok {
+			continue
+		}
+		for _, inner := range row.Components {
+			if input, ok := inner.(*discordgo.TextInput); ok && input.CustomID == customID {
+				return input.Value
+			}
+		}
+	}
+	return ""
+}
+
 // respond sends an interaction response.
 func respond(s *discordgo.Session, i *discordgo.InteractionCreate, embed *discordgo.MessageEmbed, components []discordgo.MessageComponent) error {
 	responseType := discordgo.InteractionResponseChannelMessageWithSource
@@ -712,13 +1612,19 @@ func respond(s *discordgo.Session, i *discordgo.InteractionCreate, embed *discor
 	})
 }
 
-// respondSuccess sends success message.
-func (f *Feature) respondSuccess(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate, guildID, channelID, categoryID string) error {
+// respondSuccess sends the success message, with the role hierarchy
+// warning when the onboarding bots cannot assign some configured roles.
+func (f *Feature) respondSuccess(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate, config *WelcomeConfig) error {
+	guildID := config.GuildID
 	desc := f.i18n.TWithArgs(ctx, guildID, "welcome.success",
 		map[string]string{
-			"channel":  fmt.Sprintf("<#%s>", channelID),
-			"category": fmt.Sprintf("<#%s>", categoryID),
+			"channel":  fmt.Sprintf("<#%s>", config.WelcomeChannelID),
+			"category": fmt.Sprintf("<#%s>", config.VCCategoryID),
 		})
+	// Saving isn't refused over roles the bots can't assign, so flag them here
+	if warning := f.hierarchyWarning(ctx, s, guildID, config); warning != "" {
+		desc += "\n\n" + warning
+	}
 
 	embed := &discordgo.MessageEmbed{
 		Title:       f.i18n.T(ctx, guildID, "common.success"),
@@ -776,6 +1682,7 @@ func (f *Feature) respondCancelled(ctx context.Context, s *discordgo.Session, i
 // showStep3 shows Entrance role selection.
 func (f *Feature) showStep3(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) error {
 	guildID := i.GuildID
+	state := bot.WizardDefaults(f.getWizardState(ctx, guildID))
 
 	embed := &discordgo.MessageEmbed{
 		Title:       f.i18n.T(ctx, guildID, "welcome.step3_title"),
@@ -786,11 +1693,8 @@ func (f *Feature) showStep3(ctx context.Context, s *discordgo.Session, i *discor
 	components := []discordgo.MessageComponent{
 		discordgo.ActionsRow{
 			Components: []discordgo.MessageComponent{
-				discordgo.SelectMenu{
-					MenuType:    discordgo.RoleSelectMenu,
-					CustomID:    "welcome:entrance_role:select",
-					Placeholder: f.i18n.T(ctx, guildID, "welcome.select_entrance_role"),
-				},
+				discord.RoleSelectMenu("welcome:entrance_role:select",
+					f.i18n.T(ctx, guildID, "welcome.select_entrance_role"), state.EntranceRoleID),
 			},
 		},
 	}
@@ -801,6 +1705,7 @@ func (f *Feature) showStep3(ctx context.Context, s *discordgo.Session, i *discor
 // showStep4 shows Nyukai role selection.
 func (f *Feature) showStep4(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) error {
 	guildID := i.GuildID
+	state := bot.WizardDefaults(f.getWizardState(ctx, guildID))
 
 	embed := &discordgo.MessageEmbed{
 		Title:       f.i18n.T(ctx, guildID, "welcome.step4_title"),
@@ -811,11 +1716,8 @@ func (f *Feature) showStep4(ctx context.Context, s *discordgo.Session, i *discor
 	components := []discordgo.MessageComponent{
 		discordgo.ActionsRow{
 			Components: []discordgo.MessageComponent{
-				discordgo.SelectMenu{
-					MenuType:    discordgo.RoleSelectMenu,
-					CustomID:    "welcome:nyukai_role:select",
-					Placeholder: f.i18n.T(ctx, guildID, "welcome.select_nyukai_role"),
-				},
+				discord.RoleSelectMenu("welcome:nyukai_role:select",
+					f.i18n.T(ctx, guildID, "welcome.select_nyukai_role"), state.NyukaiRoleID),
 			},
 		},
 	}
@@ -826,6 +1728,7 @@ func (f *Feature) showStep4(ctx context.Context, s *discordgo.Session, i *discor
 // showStep5 shows Setsumeikai 1 role selection.
 func (f *Feature) showStep5(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) error {
 	guildID := i.GuildID
+	state := bot.WizardDefaults(f.getWizardState(ctx, guildID))
 
 	embed := &discordgo.MessageEmbed{
 		Title:       f.i18n.T(ctx, guildID, "welcome.step5_title"),
@@ -836,11 +1739,8 @@ func (f *Feature) showStep5(ctx context.Context, s *discordgo.Session, i *discor
 	components := []discordgo.MessageComponent{
 		discordgo.ActionsRow{
 			Components: []discordgo.MessageComponent{
-				discordgo.SelectMenu{
-					MenuType:    discordgo.RoleSelectMenu,
-					CustomID:    "welcome:setsumeikai1_role:select",
-					Placeholder: f.i18n.T(ctx, guildID, "welcome.select_setsumeikai1_role"),
-				},
+				discord.RoleSelectMenu("welcome:setsumeikai1_role:select",
+					f.i18n.T(ctx, guildID, "welcome.select_setsumeikai1_role"), state.Setsumeikai1RoleID),
 			},
 		},
 	}
@@ -851,6 +1751,7 @@ func (f *Feature) showStep5(ctx context.Context, s *discordgo.Session, i *discor
 // showStep6 shows Setsumeikai 2 role selection.
 func (f *Feature) showStep6(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) error {
 	guildID := i.GuildID
+	state := bot.WizardDefaults(f.getWizardState(ctx, guildID))
 
 	embed := &discordgo.MessageEmbed{
 		Title:       f.i18n.T(ctx, guildID, "welcome.step6_title"),
@@ -861,11 +1762,8 @@ func (f *Feature) showStep6(ctx context.Context, s *discordgo.Session, i *discor
 	components := []discordgo.MessageComponent{
 		discordgo.ActionsRow{
 			Components: []discordgo.MessageComponent{
-				discordgo.SelectMenu{
-					MenuType:    discordgo.RoleSelectMenu,
-					CustomID:    "welcome:setsumeikai2_role:select",
-					Placeholder: f.i18n.T(ctx, guildID, "welcome.select_setsumeikai2_role"),
-				},
+				discord.RoleSelectMenu("welcome:setsumeikai2_role:select",
+					f.i18n.T(ctx, guildID, "welcome.select_setsumeikai2_role"), state.Setsumeikai2RoleID),
 			},
 		},
 	}
@@ -876,6 +1774,7 @@ func (f *Feature) showStep6(ctx context.Context, s *discordgo.Session, i *discor
 // showStep7 shows Setsumeikai 3 role selection.
 func (f *Feature) showStep7(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) error {
 	guildID := i.GuildID
+	state := bot.WizardDefaults(f.getWizardState(ctx, guildID))
 
 	embed := &discordgo.MessageEmbed{
 		Title:       f.i18n.T(ctx, guildID, "welcome.step7_title"),
@@ -886,11 +1785,8 @@ func (f *Feature) showStep7(ctx context.Context, s *discordgo.Session, i *discor
 	components := []discordgo.MessageComponent{
 		discordgo.ActionsRow{
 			Components: []discordgo.MessageComponent{
-				discordgo.SelectMenu{
-					MenuType:    discordgo.RoleSelectMenu,
-					CustomID:    "welcome:setsumeikai3_role:select",
-					Placeholder: f.i18n.T(ctx, guildID, "welcome.select_setsumeikai3_role"),
-				},
+				discord.RoleSelectMenu("welcome:setsumeikai3_role:select",
+					f.i18n.T(ctx, guildID, "welcome.select_setsumeikai3_role"), state.Setsumeikai3RoleID),
 			},
 		},
 	}
@@ -901,6 +1797,7 @@ func (f *Feature) showStep7(ctx context.Context, s *discordgo.Session, i *discor
 // showStep8 shows Member role selection.
 func (f *Feature) showStep8(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) error {
 	guildID := i.GuildID
+	state := bot.WizardDefaults(f.getWizardState(ctx, guildID))
 
 	embed := &discordgo.MessageEmbed{
 		Title:       f.i18n.T(ctx, guildID, "welcome.step8_title"),
@@ -911,11 +1808,8 @@ func (f *Feature) showStep8(ctx context.Context, s *discordgo.Session, i *discor
 	components := []discordgo.MessageComponent{
 		discordgo.ActionsRow{
 			Components: []discordgo.MessageComponent{
-				discordgo.SelectMenu{
-					MenuType:    discordgo.RoleSelectMenu,
-					CustomID:    "welcome:member_role:select",
-					Placeholder: f.i18n.T(ctx, guildID, "welcome.select_member_role"),
-				},
+				discord.RoleSelectMenu("welcome:member_role:select",
+					f.i18n.T(ctx, guildID, "welcome.select_member_role"), state.MemberRoleID),
 			},
 		},
 	}
@@ -926,6 +1820,7 @@ func (f *Feature) showStep8(ctx context.Context, s *discordgo.Session, i *discor
 // showStep9 shows Visitor role selection.
 func (f *Feature) showStep9(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) error {
 	guildID := i.GuildID
+	state := bot.WizardDefaults(f.getWizardState(ctx, guildID))
 
 	embed := &discordgo.MessageEmbed{
 		Title:       f.i18n.T(ctx, guildID, "welcome.step9_title"),
@@ -936,11 +1831,8 @@ func (f *Feature) showStep9(ctx context.Context, s *discordgo.Session, i *discor
 	components := []discordgo.MessageComponent{
 		discordgo.ActionsRow{
 			Components: []discordgo.MessageComponent{
-				discordgo.SelectMenu{
-					MenuType:    discordgo.RoleSelectMenu,
-					CustomID:    "welcome:visitor_role:select",
-					Placeholder: f.i18n.T(ctx, guildID, "welcome.select_visitor_role"),
-				},
+				discord.RoleSelectMenu("welcome:visitor_role:select",
+					f.i18n.T(ctx, guildID, "welcome.select_visitor_role"), state.VisitorRoleID),
 			},
 		},
 	}
@@ -954,15 +1846,18 @@ func (f *Feature) handleEntranceRoleSelection(ctx context.Context, s *discordgo.
 	values := i.MessageComponentData().Values
 
 	if len(values) == 0 {
-		return fmt.Errorf("no role selected")
+		return bot.ErrNoRoleSelected
 	}
 
 	roleID := values[0]
+	if err := f.checkSlaveRole(ctx, s, guildID, roleID); err != nil {
+		return err
+	}
 
 	// Update wizard state
-	state, err := f.getWizardState(ctx, guildID)
+	state, err := f.wizardStateAt(ctx, guildID, 3)
 	if err != nil {
-		return fmt.Errorf("get wizard state: %w", err)
+		return f.respondWizardUnavailable(ctx, s, i, err)
 	}
 	state.EntranceRoleID = roleID
 	state.CurrentStep = 4
@@ -979,15 +1874,18 @@ func (f *Feature) handleNyukaiRoleSelection(ctx context.Context, s *discordgo.Se
 	values := i.MessageComponentData().Values
 
 	if len(values) == 0 {
-		return fmt.Errorf("no role selected")
+		return bot.ErrNoRoleSelected
 	}
 
 	roleID := values[0]
+	if err := f.checkSlaveRole(ctx, s, guildID, roleID); err != nil {
+		return err
+	}
 
 	// Update wizard state
-	state, err := f.getWizardState(ctx, guildID)
+	state, err := f.wizardStateAt(ctx, guildID, 4)
 	if err != nil {
-		return fmt.Errorf("get wizard state: %w", err)
+		return f.respondWizardUnavailable(ctx, s, i, err)
 	}
 	state.NyukaiRoleID = roleID
 	state.CurrentStep = 5
@@ -1004,15 +1902,18 @@ func (f *Feature) handleSetsumeikai1RoleSelection(ctx context.Context, s *discor
 	values := i.MessageComponentData().Values
 
 	if len(values) == 0 {
-		return fmt.Errorf("no role selected")
+		return bot.ErrNoRoleSelected
 	}
 
 	roleID := values[0]
+	if err := f.checkSlaveRole(ctx, s, guildID, roleID); err != nil {
+		return err
+	}
 
 	// Update wizard state
-	state, err := f.getWizardState(ctx, guildID)
+	state, err := f.wizardStateAt(ctx, guildID, 5)
 	if err != nil {
-		return fmt.Errorf("get wizard state: %w", err)
+		return f.respondWizardUnavailable(ctx, s, i, err)
 	}
 	state.Setsumeikai1RoleID = roleID
 	state.CurrentStep = 6
@@ -1029,15 +1930,18 @@ func (f *Feature) handleSetsumeikai2RoleSelection(ctx context.Context, s *discor
 	values := i.MessageComponentData().Values
 
 	if len(values) == 0 {
-		return fmt.Errorf("no role selected")
+		return bot.ErrNoRoleSelected
 	}
 
 	roleID := values[0]
+	if err := f.checkSlaveRole(ctx, s, guildID, roleID); err != nil {
+		return err
+	}
 
 	// Update wizard state
-	state, err := f.getWizardState(ctx, guildID)
+	state, err := f.wizardStateAt(ctx, guildID, 6)
 	if err != nil {
-		return fmt.Errorf("get wizard state: %w", err)
+		return f.respondWizardUnavailable(ctx, s, i, err)
 	}
 	state.Setsumeikai2RoleID = roleID
 	state.CurrentStep = 7
@@ -1054,15 +1958,18 @@ func (f *Feature) handleSetsumeikai3RoleSelection(ctx context.Context, s *discor
 	values := i.MessageComponentData().Values
 
 	if len(values) == 0 {
-		return fmt.Errorf("no role selected")
+		return bot.ErrNoRoleSelected
 	}
 
 	roleID := values[0]
+	if err := f.checkSlaveRole(ctx, s, guildID, roleID); err != nil {
+		return err
+	}
 
 	// Update wizard state
-	state, err := f.getWizardState(ctx, guildID)
+	state, err := f.wizardStateAt(ctx, guildID, 7)
 	if err != nil {
-		return fmt.Errorf("get wizard state: %w", err)
+		return f.respondWizardUnavailable(ctx, s, i, err)
 	}
 	state.Setsumeikai3RoleID = roleID
 	state.CurrentStep = 8
@@ -1079,15 +1986,18 @@ func (f *Feature) handleMemberRoleSelection(ctx context.Context, s *discordgo.Se
 	values := i.MessageComponentData().Values
 
 	if len(values) == 0 {
-		return fmt.Errorf("no role selected")
+		return bot.ErrNoRoleSelected
 	}
 
 	roleID := values[0]
+	if err := f.checkSlaveRole(ctx, s, guildID, roleID); err != nil {
+		return err
+	}
 
 	// Update wizard state
-	state, err := f.getWizardState(ctx, guildID)
+	state, err := f.wizardStateAt(ctx, guildID, 8)
 	if err != nil {
-		return fmt.Errorf("get wizard state: %w", err)
+		return f.respondWizardUnavailable(ctx, s, i, err)
 	}
 	state.MemberRoleID = roleID
 	state.CurrentStep = 9
@@ -1098,36 +2008,348 @@ func (f *Feature) handleMemberRoleSelection(ctx context.Context, s *discordgo.Se
 	return f.showStep9(ctx, s, i)
 }
 
-// handleVisitorRoleSelection processes Visitor role selection and completes wizard.
+// handleVisitorRoleSelection processes Visitor role selection.
 func (f *Feature) handleVisitorRoleSelection(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) error {
 	guildID := i.GuildID
 	values := i.MessageComponentData().Values
 
 	if len(values) == 0 {
-		return fmt.Errorf("no role selected")
+		return bot.ErrNoRoleSelected
 	}
 
 	roleID := values[0]
+	if err := f.checkSlaveRole(ctx, s, guildID, roleID); err != nil {
+		return err
+	}
 
-	// Get final wizard state
-	state, err := f.getWizardState(ctx, guildID)
+	// Update wizard state
+	state, err := f.wizardStateAt(ctx, guildID, 9)
 	if err != nil {
-		return fmt.Errorf("get wizard state: %w", err)
+		return f.respondWizardUnavailable(ctx, s, i, err)
 	}
 	state.VisitorRoleID = roleID
+	state.CurrentStep = 10
+	if err := f.saveWizardState(ctx, state); err != nil {
+		f.logger.Error("failed to save wizard state", "error", err)
+	}
+
+	return f.showStep10(ctx, s, i)
+}
+
+// showStep10 shows the optional staff notification channel and role selection.
+func (f *Feature) showStep10(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	guildID := i.GuildID
+
+	state := bot.WizardDefaults(f.getWizardState(ctx, guildID))
+
+	confirmationsKey := "welcome.confirmations_public"
+	if state.EphemeralConfirmations {
+		confirmationsKey = "welcome.confirmations_ephemeral"
+	}
+	audioKey := "welcome.audio_on"
+	if state.TextOnly {
+		audioKey = "welcome.audio_off"
+	}
+
+	// Clearing the selection switches back to a private VC per member
+	sharedVCMenu := discord.ChannelSelectMenu("welcome:shared_vc:select",
+		f.i18n.T(ctx, guildID, "welcome.select_shared_vc"),
+		[]discordgo.ChannelType{discordgo.ChannelTypeGuildVoice}, state.SharedVCChannelID)
+	minValues := 0
+	sharedVCMenu.MinValues = &minValues
+
+	embed := &discordgo.MessageEmbed{
+		Title:       f.i18n.T(ctx, guildID, "welcome.step10_title"),
+		Description: f.i18n.T(ctx, guildID, "welcome.step10_description"),
+		Color:       int(shared.ColorInfo),
+	}
+
+	components := []discordgo.MessageComponent{
+		discordgo.ActionsRow{
+			Components: []discordgo.MessageComponent{
+				discord.ChannelSelectMenu("welcome:staff_channel:select",
+					f.i18n.T(ctx, guildID, "welcome.select_staff_channel"),
+					[]discordgo.ChannelType{discordgo.ChannelTypeGuildText}, state.StaffNotifyChannelID),
+			},
+		},
+		discordgo.ActionsRow{
+			Components: []discordgo.MessageComponent{
+				discord.RoleSelectMenu("welcome:staff_role:select",
+					f.i18n.T(ctx, guildID, "welcome.select_staff_role"), state.StaffRoleID),
+			},
+		},
+		discordgo.ActionsRow{
+			Components: []discordgo.MessageComponent{
+				sharedVCMenu,
+			},
+		},
+	}
+	if greetingMenu, ok := f.greetingMenu(ctx, guildID, state.GreetingGuide); ok {
+		components = append(components, discordgo.ActionsRow{
+			Components: []discordgo.MessageComponent{greetingMenu},
+		})
+	}
+	components = append(components,
+		discordgo.ActionsRow{
+			Components: []discordgo.MessageComponent{
+				discordgo.Button{
+					Label:    f.i18n.T(ctx, guildID, "welcome.set_vc_name"),
+					Style:    discordgo.SecondaryButton,
+					CustomID: "welcome:vc_name:edit",
+				},
+				discordgo.Button{
+					Label:    f.i18n.T(ctx, guildID, confirmationsKey),
+					Style:    discordgo.SecondaryButton,
+					CustomID: "welcome:ephemeral:toggle",
+				},
+				discordgo.Button{
+					Label:    f.i18n.T(ctx, guildID, audioKey),
+					Style:    discordgo.SecondaryButton,
+					CustomID: "welcome:audio:toggle",
+				},
+				discordgo.Button{
+					Label:    f.i18n.T(ctx, guildID, "welcome.finish_setup"),
+					Style:    discordgo.SuccessButton,
+					CustomID: "welcome:finish",
+				},
+			},
+		},
+	)
+
+	return respond(s, i, embed, components)
+}
+
+// handleStaffSelection stores an optional step 10 channel or role and keeps
+// step 10 open.
+func (f *Feature) handleStaffSelection(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate, customID string) error {
+	guildID := i.GuildID
+	values := i.MessageComponentData().Values
+
+	state, err := f.wizardStateAt(ctx, guildID, 10)
+	if err != nil {
+		return f.respondWizardUnavailable(ctx, s, i, err)
+	}
+
+	// An empty selection clears the setting
+	value := ""
+	if len(values) > 0 {
+		value = values[0]
+	}
+
+	switch {
+	case strings.HasPrefix(customID, "welcome:staff_channel:"):
+		state.StaffNotifyChannelID = value
+	case strings.HasPrefix(customID, "welcome:shared_vc:"):
+		state.SharedVCChannelID = value
+	case strings.HasPrefix(customID, "welcome:greeting:"):
+		if !isGuide(value) {
+			value = ""
+		}
+		state.GreetingGuide = value
+	default:
+		state.StaffRoleID = value
+	}
+	if err := f.saveWizardState(ctx, state); err != nil {
+		f.logger.Error("failed to save wizard state", "error", err)
+	}
+
+	return s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredMessageUpdate,
+	})
+}
+
+// toggleEphemeralConfirmations flips whether step confirmations are only
+// visible to the user and redraws step 10.
+func (f *Feature) toggleEphemeralConfirmations(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	state, err := f.wizardStateAt(ctx, i.GuildID, 10)
+	if err != nil {
+		return f.respondWizardUnavailable(ctx, s, i, err)
+	}
+
+	state.EphemeralConfirmations = !state.EphemeralConfirmations
+	if err := f.saveWizardState(ctx, state); err != nil {
+		f.logger.Error("failed to save wizard state", "error", err)
+	}
+
+	return f.showStep10(ctx, s, i)
+}
+
+// toggleTextOnly flips whether sessions play voice guidance or run
+// text-only, and redraws step 10.
+func (f *Feature) toggleTextOnly(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	state, err := f.wizardStateAt(ctx, i.GuildID, 10)
+	if err != nil {
+		return f.respondWizardUnavailable(ctx, s, i, err)
+	}
+
+	state.TextOnly = !state.TextOnly
+	if err := f.saveWizardState(ctx, state); err != nil {
+		f.logger.Error("failed to save wizard state", "error", err)
+	}
+
+	return f.showStep10(ctx, s, i)
+}
+
+// greetingMenu lists the guides that have a greeting recording, plus an
+// option to start sessions in silence. It reports false when no guide has
+// a greeting to offer.
+func (f *Feature) greetingMenu(ctx context.Context, guildID, current string) (discordgo.SelectMenu, bool) {
+	guides, err := onboard.ListGuides()
+	if err != nil {
+		f.logger.Warn("failed to list guides", "error", err)
+	}
+
+	options := []discordgo.SelectMenuOption{{
+		Label:   f.i18n.T(ctx, guildID, "welcome.greeting_off"),
+		Value:   "none",
+		Default: current == "",
+	}}
+	for _, guide := range guides {
+		manifest, err := onboard.LoadManifest(guide)
+		if err != nil {
+			continue
+		}
+		if _, err := os.Stat(onboard.AudioPath(guide, manifest.AudioFile(onboard.AudioWelcome))); err != nil {
+			continue
+		}
+		options = append(options, discordgo.SelectMenuOption{
+			Label:   f.i18n.T(ctx, guildID, fmt.Sprintf("onboarding.guides.%s.name", guide)),
+			Value:   guide,
+			Default: guide == current,
+		})
+	}
+	if len(options) == 1 {
+		return discordgo.SelectMenu{}, false
+	}
+
+	return discordgo.SelectMenu{
+		MenuType:    discordgo.StringSelectMenu,
+		CustomID:    "welcome:greeting:select",
+		Placeholder: f.i18n.T(ctx, guildID, "welcome.select_greeting"),
+		Options:     options,
+	}, true
+}
+
+// showVCNameModal opens a modal for editing the onboarding VC name template.
+func (f *Feature) showVCNameModal(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	guildID := i.GuildID
+
+	state, err := f.wizardStateAt(ctx, guildID, 10)
+	if err != nil {
+		return f.respondWizardUnavailable(ctx, s, i, err)
+	}
+
+	template := state.VCNameTemplate
+	if template == "" {
+		template = discord.DefaultChannelNameTemplate
+	}
+
+	return s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseModal,
+		Data: &discordgo.InteractionResponseData{
+			CustomID: "welcome:vc_name:modal",
+			Title:    f.i18n.T(ctx, guildID, "welcome.vc_name_modal_title"),
+			Components: []discordgo.MessageComponent{
+				discordgo.ActionsRow{
+					Components: []discordgo.MessageComponent{
+						discordgo.TextInput{
+							CustomID:    "template",
+							Label:       f.i18n.T(ctx, guildID, "welcome.vc_name_label"),
+							Style:       discordgo.TextInputShort,
+							Placeholder: f.i18n.T(ctx, guildID, "welcome.vc_name_placeholder"),
+							Value:       template,
+							Required:    true,
+							MaxLength:   discord.MaxChannelNameLength,
+						},
+					},
+				},
+			},
+		},
+	})
+}
+
+// handleVCNameSubmit validates and stores the VC name template from the modal.
+func (f *Feature) handleVCNameSubmit(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	guildID := i.GuildID
+	template := strings.TrimSpace(modalValue(i.ModalSubmitData(), "template"))
+
+	if !discord.ValidChannelNameTemplate(template) {
+		return f.respondErrorMessage(ctx, s, i, guildID, "welcome.vc_name_invalid")
+	}
+	example := discord.RenderChannelName(template, discord.ExampleChannelNameVars)
+
+	state, err := f.wizardStateAt(ctx, guildID, 10)
+	if err != nil {
+		return f.respondWizardUnavailable(ctx, s, i, err)
+	}
+
+	state.VCNameTemplate = template
+	if err := f.saveWizardState(ctx, state); err != nil {
+		f.logger.Error("failed to save wizard state", "error", err)
+	}
+
+	return s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content: f.i18n.TWithArgs(ctx, guildID, "welcome.vc_name_saved", map[string]string{
+				"template": template,
+				"example":  example,
+			}),
+			Flags: discordgo.MessageFlagsEphemeral,
+		},
+	})
+}
+
+// nextVCSeq returns the guild's next onboarding VC sequence number for {n}.
+func (f *Feature) nextVCSeq(ctx context.Context, guildID string) string {
+	seq, err := f.cache.Incr(ctx, vcSeqKeyPrefix+guildID)
+	if err != nil {
+		f.logger.Warn("failed to increment vc sequence", "error", err, "guild_id", guildID)
+		return fmt.Sprintf("%d", time.Now().Unix()%10000)
+	}
+	return fmt.Sprintf("%d", seq)
+}
+
+// finishWizard saves the configuration and posts the welcome button.
+func (f *Feature) finishWizard(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	guildID := i.GuildID
+
+	// Get final wizard state
+	state, err := f.wizardStateAt(ctx, guildID, 10)
+	if err != nil {
+		return f.respondWizardUnavailable(ctx, s, i, err)
+	}
 
 	// Convert wizard state to config and save
 	config := &WelcomeConfig{
-		GuildID:             guildID,
-		WelcomeChannelID:    state.WelcomeChannelID,
-		VCCategoryID:        state.VCCategoryID,
-		EntranceRoleID:      state.EntranceRoleID,
-		NyukaiRoleID:        state.NyukaiRoleID,
-		Setsumeikai1RoleID:  state.Setsumeikai1RoleID,
-		Setsumeikai2RoleID:  state.Setsumeikai2RoleID,
-		Setsumeikai3RoleID:  state.Setsumeikai3RoleID,
-		MemberRoleID:        state.MemberRoleID,
-		VisitorRoleID:       state.VisitorRoleID,
+		GuildID:                 guildID,
+		WelcomeChannelID:        state.WelcomeChannelID,
+		VCCategoryID:            state.VCCategoryID,
+		EntranceRoleID:          state.EntranceRoleID,
+		NyukaiRoleID:            state.NyukaiRoleID,
+		Setsumeikai1RoleID:      state.Setsumeikai1RoleID,
+		Setsumeikai2RoleID:      state.Setsumeikai2RoleID,
+		Setsumeikai3RoleID:      state.Setsumeikai3RoleID,
+		MemberRoleID:            state.MemberRoleID,
+		VisitorRoleID:           state.VisitorRoleID,
+		StaffNotifyChannelID:    state.StaffNotifyChannelID,
+		StaffRoleID:             state.StaffRoleID,
+		VCNameTemplate:          state.VCNameTemplate,
+		EphemeralConfirmations:  state.EphemeralConfirmations,
+		SharedVCChannelID:       state.SharedVCChannelID,
+		SpreadCategoryIDs:       state.SpreadCategoryIDs,
+		OverflowCategoryIDs:     state.OverflowCategoryIDs,
+		GreetingGuide:           state.GreetingGuide,
+		InitialRoleID:           state.InitialRoleID,
+		TextOnly:                state.TextOnly,
+		Schedule:                state.Schedule,
+		WelcomeBack:             state.WelcomeBack,
+		VCAccessRoleIDs:         state.VCAccessRoleIDs,
+		VCAccessUserIDs:         state.VCAccessUserIDs,
+		CustomCompletionRoles:   state.CustomCompletionRoles,
+		CompletionGrantRoleIDs:  state.CompletionGrantRoleIDs,
+		CompletionRevokeRoleIDs: state.CompletionRevokeRoleIDs,
+		SessionLocales:          state.SessionLocales,
 	}
 
 	if err := f.saveWelcomeConfig(ctx, config); err != nil {
@@ -1144,20 +2366,40 @@ func (f *Feature) handleVisitorRoleSelection(ctx context.Context, s *discordgo.S
 		f.logger.Error("failed to delete wizard state", "error", err)
 	}
 
-	return f.respondSuccess(ctx, s, i, guildID, state.WelcomeChannelID, state.VCCategoryID)
+	return f.respondSuccess(ctx, s, i, config)
 }
 
 // getAgeRangeConfig retrieves age range configuration.
 func (f *Feature) getAgeRangeConfig(ctx context.Context, guildID string) (*AgeRangeConfig, error) {
-	query := `
+	cacheKey := ageRangeCacheKeyPrefix + guildID
+
+	var cached AgeRangeConfig
+	if err := f.cache.GetJSON(ctx, cacheKey, &cached); err == nil {
+		return &cached, nil
+	}
+
+	query := ageRangeConfigQuery + `
+		WHERE guild_id = $1
+	`
+	config, err := scanAgeRangeConfig(f.db.QueryRow(database.ReadFromReplica(ctx), query, guildID))
+	if err != nil {
+		return nil, err
+	}
+
+	f.cache.SetJSON(ctx, cacheKey, config, database.ReplicaCacheTTL)
+
+	return config, nil
+}
+
+// ageRangeConfigQuery selects the columns scanAgeRangeConfig reads.
+const ageRangeConfigQuery = `
 		SELECT guild_id, age_20_early_role_id, age_20_late_role_id,
 		       age_30_early_role_id, age_30_late_role_id,
 		       age_40_early_role_id, age_40_late_role_id
-		FROM guild_age_range_config 
-		WHERE guild_id = $1
-	`
-	row := f.db.QueryRow(ctx, query, guildID)
+		FROM guild_age_range_config`
 
+// scanAgeRangeConfig reads a row of ageRangeConfigQuery.
+func scanAgeRangeConfig(row rowScanner) (*AgeRangeConfig, error) {
 	var config AgeRangeConfig
 	var age20Early, age20Late, age30Early, age30Late, age40Early, age40Late *string
 	err := row.Scan(&config.GuildID, &age20Early, &age20Late,
@@ -1190,13 +2432,33 @@ func (f *Feature) getAgeRangeConfig(ctx context.Context, guildID string) (*AgeRa
 
 // getGenderConfig retrieves gender configuration.
 func (f *Feature) getGenderConfig(ctx context.Context, guildID string) (*GenderConfig, error) {
-	query := `
-		SELECT guild_id, male_role_id, female_role_id
-		FROM guild_gender_roles 
+	cacheKey := genderCacheKeyPrefix + guildID
+
+	var cached GenderConfig
+	if err := f.cache.GetJSON(ctx, cacheKey, &cached); err == nil {
+		return &cached, nil
+	}
+
+	query := genderConfigQuery + `
 		WHERE guild_id = $1
 	`
-	row := f.db.QueryRow(ctx, query, guildID)
+	config, err := scanGenderConfig(f.db.QueryRow(database.ReadFromReplica(ctx), query, guildID))
+	if err != nil {
+		return nil, err
+	}
+
+	f.cache.SetJSON(ctx, cacheKey, config, database.ReplicaCacheTTL)
+
+	return config, nil
+}
 
+// genderConfigQuery selects the columns scanGenderConfig reads.
+const genderConfigQuery = `
+		SELECT guild_id, male_role_id, female_role_id
+		FROM guild_gender_roles`
+
+// scanGenderConfig reads a row of genderConfigQuery.
+func scanGenderConfig(row rowScanner) (*GenderConfig, error) {
 	var config GenderConfig
 	var male, female *string
 	err := row.Scan(&config.GuildID, &male, &female)
@@ -1216,14 +2478,34 @@ func (f *Feature) getGenderConfig(ctx context.Context, guildID string) (*GenderC
 
 // getVoiceTypeConfig retrieves voice type configuration.
 func (f *Feature) getVoiceTypeConfig(ctx context.Context, guildID string) (*VoiceTypeConfig, error) {
-	query := `
-		SELECT guild_id, high_role_id, mid_high_role_id,
-		       mid_role_id, mid_low_role_id, low_role_id
-		FROM guild_voice_type_config 
+	cacheKey := voiceTypeCacheKeyPrefix + guildID
+
+	var cached VoiceTypeConfig
+	if err := f.cache.GetJSON(ctx, cacheKey, &cached); err == nil {
+		return &cached, nil
+	}
+
+	query := voiceTypeConfigQuery + `
 		WHERE guild_id = $1
 	`
-	row := f.db.QueryRow(ctx, query, guildID)
+	config, err := scanVoiceTypeConfig(f.db.QueryRow(database.ReadFromReplica(ctx), query, guildID))
+	if err != nil {
+		return nil, err
+	}
+
+	f.cache.SetJSON(ctx, cacheKey, config, database.ReplicaCacheTTL)
+
+	return config, nil
+}
+
+// voiceTypeConfigQuery selects the columns scanVoiceTypeConfig reads.
+const voiceTypeConfigQuery = `
+		SELECT guild_id, high_role_id, mid_high_role_id,
+		       mid_role_id, mid_low_role_id, low_role_id
+		FROM guild_voice_type_config`
 
+// scanVoiceTypeConfig reads a row of voiceTypeConfigQuery.
+func scanVoiceTypeConfig(row rowScanner) (*VoiceTypeConfig, error) {
 	var config VoiceTypeConfig
 	var high, midHigh, mid, midLow, low *string
 	err := row.Scan(&config.GuildID, &high, &midHigh, &mid, &midLow, &low)
@@ -1252,17 +2534,37 @@ func (f *Feature) getVoiceTypeConfig(ctx context.Context, guildID string) (*Voic
 
 // getOtherRolesConfig retrieves other roles configuration.
 func (f *Feature) getOtherRolesConfig(ctx context.Context, guildID string) (*OtherRolesConfig, error) {
-	query := `
+	cacheKey := otherRolesCacheKeyPrefix + guildID
+
+	var cached OtherRolesConfig
+	if err := f.cache.GetJSON(ctx, cacheKey, &cached); err == nil {
+		return &cached, nil
+	}
+
+	query := otherRolesConfigQuery + `
+		WHERE guild_id = $1
+	`
+	config, err := scanOtherRolesConfig(f.db.QueryRow(database.ReadFromReplica(ctx), query, guildID))
+	if err != nil {
+		return nil, err
+	}
+
+	f.cache.SetJSON(ctx, cacheKey, config, database.ReplicaCacheTTL)
+
+	return config, nil
+}
+
+// otherRolesConfigQuery selects the columns scanOtherRolesConfig reads.
+const otherRolesConfigQuery = `
 		SELECT guild_id, ero_ok_role_id, ero_ng_role_id,
 		       neochi_ok_role_id, neochi_ng_role_id, neochi_disconnect_role_id,
 		       dm_ok_role_id, dm_ng_role_id,
 		       friend_ok_role_id, friend_ng_role_id,
 		       bunnyclub_event_role_id, user_event_role_id
-		FROM guild_other_roles_config 
-		WHERE guild_id = $1
-	`
-	row := f.db.QueryRow(ctx, query, guildID)
+		FROM guild_other_roles_config`
 
+// scanOtherRolesConfig reads a row of otherRolesConfigQuery.
+func scanOtherRolesConfig(row rowScanner) (*OtherRolesConfig, error) {
 	var config OtherRolesConfig
 	var eroOk, eroNg, neochiOk, neochiNg, neochiDisconnect *string
 	var dmOk, dmNg, friendOk, friendNg, bunnyclubEvent, userEvent *string
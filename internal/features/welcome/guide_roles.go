@@ -0,0 +1,233 @@
+package welcome
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"welcomebot/internal/core/database"
+	"welcomebot/internal/core/discord"
+	"welcomebot/internal/onboard"
+	"welcomebot/internal/shared"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// getGuideCompletionRoles returns the guild's per-guide completion role
+// overrides, keyed by guide.
+func (f *Feature) getGuideCompletionRoles(ctx context.Context, guildID string) (map[string]GuideCompletionRoles, error) {
+	cacheKey := guideRolesKeyPrefix + guildID
+
+	var overrides map[string]GuideCompletionRoles
+	if err := f.cache.GetJSON(ctx, cacheKey, &overrides); err == nil {
+		return overrides, nil
+	}
+
+	rows, err := f.db.Query(database.ReadFromReplica(ctx), `
+		SELECT guide, member_role_id, visitor_role_id
+		FROM guild_guide_completion_roles
+		WHERE guild_id = $1
+	`, guildID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	overrides = make(map[string]GuideCompletionRoles)
+	for rows.Next() {
+		var guide string
+		var member, visitor *string
+		if err := rows.Scan(&guide, &member, &visitor); err != nil {
+			return nil, err
+		}
+		var roles GuideCompletionRoles
+		if member != nil {
+			roles.MemberRoleID = *member
+		}
+		if visitor != nil {
+			roles.VisitorRoleID = *visitor
+		}
+		overrides[guide] = roles
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	f.cache.SetJSON(ctx, cacheKey, overrides, database.ReplicaCacheTTL)
+
+	return overrides, nil
+}
+
+// saveGuideCompletionRoles stores a guide's overrides. A guide with
+// neither role set goes back to the guild's roles.
+func (f *Feature) saveGuideCompletionRoles(ctx context.Context, guildID, guide string, roles GuideCompletionRoles) error {
+	var err error
+	if roles.MemberRoleID == "" && roles.VisitorRoleID == "" {
+		_, err = f.db.Exec(ctx, `
+			DELETE FROM guild_guide_completion_roles WHERE guild_id = $1 AND guide = $2
+		`, guildID, guide)
+	} else {
+		_, err = f.db.Exec(ctx, `
+			INSERT INTO guild_guide_completion_roles (guild_id, guide, member_role_id, visitor_role_id, updated_at)
+			VALUES ($1, $2, NULLIF($3, ''), NULLIF($4, ''), CURRENT_TIMESTAMP)
+			ON CONFLICT (guild_id, guide) DO UPDATE SET
+				member_role_id = EXCLUDED.member_role_id,
+				visitor_role_id = EXCLUDED.visitor_role_id,
+				updated_at = CURRENT_TIMESTAMP
+		`, guildID, guide, roles.MemberRoleID, roles.VisitorRoleID)
+	}
+	if err != nil {
+		return fmt.Errorf("save guide completion roles: %w", err)
+	}
+
+	if err := f.cache.Delete(ctx, guideRolesKeyPrefix+guildID); err != nil {
+		f.logger.Warn("failed to invalidate guide roles cache", "error", err, "guild_id", guildID)
+	}
+	return nil
+}
+
+// guideCompletionRolesPayload converts overrides to the form the worker
+// reads.
+func guideCompletionRolesPayload(overrides map[string]GuideCompletionRoles) map[string]onboard.CompletionRoles {
+	payload := make(map[string]onboard.CompletionRoles, len(overrides))
+	for guide, roles := range overrides {
+		payload[guide] = onboard.CompletionRoles{
+			MemberRoleID:  roles.MemberRoleID,
+			VisitorRoleID: roles.VisitorRoleID,
+		}
+	}
+	return payload
+}
+
+// showGuideRoles shows the per-guide completion roles. With a guide
+// chosen, its member and visitor role menus are shown too.
+func (f *Feature) showGuideRoles(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate, guide string) error {
+	guildID := i.GuildID
+
+	guides, err := onboard.ListGuides()
+	if err != nil {
+		f.logger.Warn("failed to list guides", "error", err)
+	}
+	if len(guides) == 0 {
+		embed := &discordgo.MessageEmbed{
+			Title:       f.i18n.T(ctx, guildID, "welcome.guide_roles_title"),
+			Description: f.i18n.T(ctx, guildID, "welcome.test_audio_no_guides"),
+			Color:       int(shared.ColorWarning),
+		}
+		return respond(s, i, embed, []discordgo.MessageComponent{})
+	}
+
+	overrides, err := f.getGuideCompletionRoles(ctx, guildID)
+	if err != nil {
+		f.logger.Warn("failed to load guide completion roles", "error", err, "guild_id", guildID)
+	}
+
+	var lines []string
+	options := make([]discordgo.SelectMenuOption, 0, len(guides))
+	for _, g := range guides {
+		name := f.i18n.T(ctx, guildID, fmt.Sprintf("onboarding.guides.%s.name", g))
+		roles := overrides[g]
+		lines = append(lines, f.i18n.TWithArgs(ctx, guildID, "welcome.guide_roles_line", map[string]string{
+			"guide":   name,
+			"member":  f.roleMention(ctx, guildID, roles.MemberRoleID),
+			"visitor": f.roleMention(ctx, guildID, roles.VisitorRoleID),
+		}))
+		options = append(options, discordgo.SelectMenuOption{
+			Label:   name,
+			Value:   g,
+			Default: g == guide,
+		})
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Title:       f.i18n.T(ctx, guildID, "welcome.guide_roles_title"),
+		Description: f.i18n.T(ctx, guildID, "welcome.guide_roles_description") + "\n\n" + strings.Join(lines, "\n"),
+		Color:       int(shared.ColorInfo),
+	}
+
+	components := []discordgo.MessageComponent{
+		discordgo.ActionsRow{
+			Components: []discordgo.MessageComponent{
+				discordgo.SelectMenu{
+					MenuType:    discordgo.StringSelectMenu,
+					CustomID:    "welcome:guide_roles:guide",
+					Placeholder: f.i18n.T(ctx, guildID, "welcome.guide_roles_select_guide"),
+					Options:     options,
+				},
+			},
+		},
+	}
+	if guide != "" {
+		// Clearing a menu falls back to the guild's role
+		minValues := 0
+		member := discord.RoleSelectMenu("welcome:guide_roles:member:"+guide,
+			f.i18n.T(ctx, guildID, "welcome.guide_roles_select_member"), overrides[guide].MemberRoleID)
+		member.MinValues = &minValues
+		visitor := discord.RoleSelectMenu("welcome:guide_roles:visitor:"+guide,
+			f.i18n.T(ctx, guildID, "welcome.guide_roles_select_visitor"), overrides[guide].VisitorRoleID)
+		visitor.MinValues = &minValues
+
+		components = append(components,
+			discordgo.ActionsRow{Components: []discordgo.MessageComponent{member}},
+			discordgo.ActionsRow{Components: []discordgo.MessageComponent{visitor}},
+		)
+	}
+
+	return respond(s, i, embed, components)
+}
+
+// handleGuideRolesGuide shows the role menus of the chosen guide.
+func (f *Feature) handleGuideRolesGuide(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	guide := selectedValue(i)
+	if !isGuide(guide) {
+		guide = ""
+	}
+	return f.showGuideRoles(ctx, s, i, guide)
+}
+
+// handleGuideRoleSelection saves a guide's member or visitor role.
+// Custom ID format: welcome:guide_roles:{member|visitor}:{guide}
+func (f *Feature) handleGuideRoleSelection(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate, customID string) error {
+	guildID := i.GuildID
+
+	parts := strings.SplitN(strings.TrimPrefix(customID, "welcome:guide_roles:"), ":", 2)
+	if len(parts) != 2 || !isGuide(parts[1]) {
+		return f.showGuideRoles(ctx, s, i, "")
+	}
+	kind, guide := parts[0], parts[1]
+
+	overrides, err := f.getGuideCompletionRoles(ctx, guildID)
+	if err != nil {
+		return f.respondError(ctx, s, i, guildID, err)
+	}
+	roles := overrides[guide]
+	switch kind {
+	case "member":
+		roles.MemberRoleID = selectedValue(i)
+	case "visitor":
+		roles.VisitorRoleID = selectedValue(i)
+	default:
+		return f.showGuideRoles(ctx, s, i, guide)
+	}
+
+	if err := f.saveGuideCompletionRoles(ctx, guildID, guide, roles); err != nil {
+		return f.respondError(ctx, s, i, guildID, err)
+	}
+
+	f.logger.Info("guide completion roles updated",
+		"guild_id", guildID,
+		"guide", guide,
+		"member_role", roles.MemberRoleID,
+		"visitor_role", roles.VisitorRoleID,
+	)
+
+	return f.showGuideRoles(ctx, s, i, guide)
+}
+
+// roleMention formats a role override, or the fallback label when unset.
+func (f *Feature) roleMention(ctx context.Context, guildID, roleID string) string {
+	if roleID == "" {
+		return f.i18n.T(ctx, guildID, "welcome.guide_roles_default")
+	}
+	return fmt.Sprintf("<@&%s>", roleID)
+}
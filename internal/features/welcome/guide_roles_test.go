@@ -0,0 +1,52 @@
+package welcome
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"welcomebot/internal/fakes"
+	"welcomebot/internal/onboard"
+)
+
+func TestSaveGuideCompletionRoles(t *testing.T) {
+	ctx := context.Background()
+	cache := fakes.NewCache()
+	db := fakes.NewDB()
+	f := &Feature{cache: cache, db: db, logger: fakes.Logger{}}
+
+	cached := map[string]GuideCompletionRoles{"kk": {MemberRoleID: "role-old"}}
+	if err := cache.SetJSON(ctx, guideRolesKeyPrefix+"guild-1", cached, 0); err != nil {
+		t.Fatal(err)
+	}
+	overrides, err := f.getGuideCompletionRoles(ctx, "guild-1")
+	if err != nil || overrides["kk"].MemberRoleID != "role-old" {
+		t.Fatalf("expected cached overrides, got %+v (%v)", overrides, err)
+	}
+
+	if err := f.saveGuideCompletionRoles(ctx, "guild-1", "kk", GuideCompletionRoles{MemberRoleID: "role-new"}); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	if exists, _ := cache.Exists(ctx, guideRolesKeyPrefix+"guild-1"); exists {
+		t.Error("expected the cached overrides to be invalidated")
+	}
+
+	// Clearing both roles removes the override
+	if err := f.saveGuideCompletionRoles(ctx, "guild-1", "kk", GuideCompletionRoles{}); err != nil {
+		t.Fatalf("clear: %v", err)
+	}
+	execs := db.Execs()
+	if len(execs) != 2 || !strings.Contains(execs[0].Query, "INSERT") || !strings.Contains(execs[1].Query, "DELETE") {
+		t.Errorf("expected an upsert then a delete, got %+v", execs)
+	}
+}
+
+func TestGuideCompletionRolesPayload(t *testing.T) {
+	payload := guideCompletionRolesPayload(map[string]GuideCompletionRoles{
+		"kk": {MemberRoleID: "role-tier2"},
+	})
+
+	if got := payload["kk"]; got != (onboard.CompletionRoles{MemberRoleID: "role-tier2"}) {
+		t.Errorf("unexpected payload %+v", payload)
+	}
+}
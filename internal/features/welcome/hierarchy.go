@@ -0,0 +1,74 @@
+package welcome
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"welcomebot/internal/bot"
+	"welcomebot/internal/core/discord"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// hierarchyWarning explains which configured roles the onboarding bots
+// cannot assign because of the role hierarchy, or returns "" if they can
+// manage them all. Slaves that have not published their user ID yet are
+// not checked.
+func (f *Feature) hierarchyWarning(ctx context.Context, s *discordgo.Session, guildID string, config *WelcomeConfig) string {
+	roleIDs := []string{
+		config.InProgressRoleID, config.CompletedRoleID,
+		config.EntranceRoleID, config.NyukaiRoleID,
+		config.Setsumeikai1RoleID, config.Setsumeikai2RoleID, config.Setsumeikai3RoleID,
+		config.MemberRoleID, config.VisitorRoleID, config.InitialRoleID,
+	}
+	if config.CustomCompletionRoles {
+		roleIDs = append(roleIDs, config.CompletionGrantRoleIDs...)
+		roleIDs = append(roleIDs, config.CompletionRevokeRoleIDs...)
+	}
+
+	var mentions []string
+	for _, roleID := range f.slaveUnmanageableRoles(ctx, s, guildID, roleIDs) {
+		mentions = append(mentions, fmt.Sprintf("<@&%s>", roleID))
+	}
+	if len(mentions) == 0 {
+		return ""
+	}
+	return f.i18n.TWithArgs(ctx, guildID, "welcome.hierarchy_warning", map[string]string{
+		"roles": strings.Join(mentions, ", "),
+	})
+}
+
+// checkSlaveRole refuses a role picked in the wizard that some onboarding
+// bot can't assign, rather than letting onboarding fail on it later.
+func (f *Feature) checkSlaveRole(ctx context.Context, s *discordgo.Session, guildID, roleID string) error {
+	if blocked := f.slaveUnmanageableRoles(ctx, s, guildID, []string{roleID}); len(blocked) > 0 {
+		return bot.RoleAboveBotError(roleID)
+	}
+	return nil
+}
+
+// slaveUnmanageableRoles returns the roles among roleIDs that some
+// onboarding bot cannot assign or remove, each once.
+func (f *Feature) slaveUnmanageableRoles(ctx context.Context, s *discordgo.Session, guildID string, roleIDs []string) []string {
+	var unmanageable []string
+	for _, slaveID := range SlaveIDs {
+		botUserID, err := f.cache.Get(ctx, slaveUserKey+slaveID)
+		if err != nil {
+			continue
+		}
+
+		blocked, err := discord.UnmanageableRoles(s, guildID, botUserID, roleIDs)
+		if err != nil {
+			f.logger.Warn("failed to check role hierarchy", "error", err, "guild_id", guildID, "slave_id", slaveID)
+			continue
+		}
+		for _, role := range blocked {
+			if !slices.Contains(unmanageable, role.ID) {
+				unmanageable = append(unmanageable, role.ID)
+			}
+		}
+	}
+	return unmanageable
+}
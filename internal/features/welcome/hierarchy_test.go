@@ -0,0 +1,84 @@
+package welcome
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"welcomebot/internal/bot"
+	"welcomebot/internal/fakes"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func TestHierarchyWarning(t *testing.T) {
+	ctx := context.Background()
+	d := fakes.NewDiscord()
+	d.SetGuildRoles("guild-1",
+		&discordgo.Role{ID: "role-bot", Position: 5},
+		&discordgo.Role{ID: "role-member", Position: 2},
+		&discordgo.Role{ID: "role-visitor", Position: 9},
+	)
+	d.SetMemberRoles("guild-1", fakes.BotUserID, "role-bot")
+
+	cache := fakes.NewCache()
+	f := &Feature{cache: cache, i18n: fakes.I18n{}, logger: fakes.Logger{}}
+	config := &WelcomeConfig{MemberRoleID: "role-member", VisitorRoleID: "role-visitor"}
+
+	// Nothing to check until a slave has published its user ID
+	if warning := f.hierarchyWarning(ctx, d.Session(), "guild-1", config); warning != "" {
+		t.Errorf("expected no warning without slave users, got %q", warning)
+	}
+
+	_ = cache.Set(ctx, slaveUserKey+SlaveIDs[0], fakes.BotUserID, 0)
+	warning := f.hierarchyWarning(ctx, d.Session(), "guild-1", config)
+	if !strings.Contains(warning, "<@&role-visitor>") || strings.Contains(warning, "role-member") {
+		t.Errorf("expected only the visitor role to be flagged, got %q", warning)
+	}
+}
+
+func TestWizardRefusesRoleAboveSlaves(t *testing.T) {
+	ctx := context.Background()
+	d := fakes.NewDiscord()
+	d.SetGuildRoles("guild-1",
+		&discordgo.Role{ID: "role-bot", Position: 5},
+		&discordgo.Role{ID: "role-visitor", Position: 9},
+	)
+	d.SetMemberRoles("guild-1", fakes.BotUserID, "role-bot")
+
+	cache := fakes.NewCache()
+	_ = cache.Set(ctx, slaveUserKey+SlaveIDs[0], fakes.BotUserID, 0)
+	f := &Feature{cache: cache, i18n: fakes.I18n{}, logger: fakes.Logger{}}
+
+	err := f.handleVisitorRoleSelection(ctx, d.Session(), wizardSelect("welcome:visitor_role:select", "role-visitor"))
+	var verr *bot.ValidationError
+	if !errors.As(err, &verr) || verr.Key != "validation.role_above_bot" {
+		t.Errorf("expected the role refused, got %v", err)
+	}
+}
+
+func TestSaveSuccessShowsHierarchyWarning(t *testing.T) {
+	ctx := context.Background()
+	d := fakes.NewDiscord()
+	d.SetGuildRoles("guild-1",
+		&discordgo.Role{ID: "role-bot", Position: 5},
+		&discordgo.Role{ID: "role-initial", Position: 9},
+	)
+	d.SetMemberRoles("guild-1", fakes.BotUserID, "role-bot")
+
+	cache := fakes.NewCache()
+	_ = cache.Set(ctx, slaveUserKey+SlaveIDs[0], fakes.BotUserID, 0)
+	f := &Feature{cache: cache, i18n: fakes.I18n{}, logger: fakes.Logger{}}
+
+	config := &WelcomeConfig{GuildID: "guild-1", InitialRoleID: "role-initial"}
+	if err := f.respondSuccess(ctx, d.Session(), wizardSelect("welcome:finish"), config); err != nil {
+		t.Fatalf("respond: %v", err)
+	}
+
+	requests := d.Requests()
+	last := requests[len(requests)-1]
+	if !strings.Contains(string(last.Body), "welcome.hierarchy_warning") {
+		t.Errorf("expected the hierarchy warning in the reply, got %s", last.Body)
+	}
+}
@@ -0,0 +1,217 @@
+package welcome
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+
+	"welcomebot/internal/core/database"
+	"welcomebot/internal/core/notify"
+	"welcomebot/internal/core/queue"
+	"welcomebot/internal/shared"
+)
+
+// maxIntroLength keeps the intro text inside Discord's 4096-rune embed
+// description limit.
+const maxIntroLength = 4000
+
+// handleOnboardingComplete posts the member's self-introduction, if they
+// wrote one, to the guild's self-intro channel.
+func (f *Feature) handleOnboardingComplete(ctx context.Context, task *queue.Task) error {
+	userID, _ := task.Payload["user_id"].(string)
+	intro, _ := task.Payload["intro"].(string)
+	intro = strings.TrimSpace(intro)
+	if intro == "" {
+		return nil
+	}
+
+	config, err := f.getSelfIntroConfig(ctx, task.GuildID)
+	if errors.Is(err, sql.ErrNoRows) {
+		f.logger.Info("no self-intro channel configured, skipping intro post", "guild_id", task.GuildID)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("get selfintro config: %w", err)
+	}
+
+	member, err := f.session.GuildMember(task.GuildID, userID)
+	if err != nil {
+		return fmt.Errorf("get member: %w", err)
+	}
+
+	var femaleRoleID string
+	if gender, err := f.getGenderConfig(ctx, task.GuildID); err == nil {
+		femaleRoleID = gender.FemaleRoleID
+	}
+	channelID := introChannel(config, member.Roles, femaleRoleID)
+	if channelID == "" {
+		return nil
+	}
+
+	var ageRoles, voiceRoles []string
+	if age, err := f.getAgeRangeConfig(ctx, task.GuildID); err == nil {
+		ageRoles = matchingRoles(member.Roles, age.Age20EarlyRoleID, age.Age20LateRoleID,
+			age.Age30EarlyRoleID, age.Age30LateRoleID, age.Age40EarlyRoleID, age.Age40LateRoleID)
+	}
+	if voice, err := f.getVoiceTypeConfig(ctx, task.GuildID); err == nil {
+		voiceRoles = matchingRoles(member.Roles, voice.HighRoleID, voice.MidHighRoleID,
+			voice.MidRoleID, voice.MidLowRoleID, voice.LowRoleID)
+	}
+
+	embed := f.buildIntroEmbed(ctx, task.GuildID, config, member, intro, ageRoles, voiceRoles)
+	_, err = f.session.ChannelMessageSendComplex(channelID, &discordgo.MessageSend{
+		Embeds:          []*discordgo.MessageEmbed{embed},
+		AllowedMentions: &discordgo.MessageAllowedMentions{},
+	})
+	if err != nil {
+		return fmt.Errorf("send intro post: %w", err)
+	}
+
+	f.logger.Info("posted self-introduction", "guild_id", task.GuildID, "user_id", userID, "channel_id", channelID)
+	return nil
+}
+
+// notifyCompleted tells the sinks configured for completions that a member
+// finished onboarding.
+func (f *Feature) notifyCompleted(ctx context.Context, task *queue.Task) {
+	if f.notifier == nil {
+		return
+	}
+	userID, _ := task.Payload["user_id"].(string)
+	guide, _ := task.Payload["guide"].(string)
+
+	n := notify.Notification{
+		Kind:    notify.KindOnboardingCompleted,
+		GuildID: task.GuildID,
+		UserID:  userID,
+		Title:   f.i18n.T(ctx, task.GuildID, "welcome.completed_notify_title"),
+		Message: f.i18n.TWithArgs(ctx, task.GuildID, "welcome.completed_notify_description", map[string]string{
+			"user": fmt.Sprintf("<@%s>", userID),
+		}),
+		Color: int(shared.ColorSuccess),
+		Fields: []notify.Field{
+			{Name: f.i18n.T(ctx, task.GuildID, "welcome.staff_alert_user"), Value: fmt.Sprintf("<@%s> (%s)", userID, userID), Inline: true},
+		},
+		Time: time.Now(),
+	}
+	if guide != "" {
+		n.Fields = append(n.Fields, notify.Field{Name: f.i18n.T(ctx, task.GuildID, "welcome.timeline_guide"), Value: guide, Inline: true})
+	}
+	f.sendNotification(ctx, n)
+}
+
+// buildIntroEmbed renders an intro post using the guild's configured format.
+func (f *Feature) buildIntroEmbed(ctx context.Context, guildID string, config *SelfIntroConfig, member *discordgo.Member, intro string, ageRoles, voiceRoles []string) *discordgo.MessageEmbed {
+	title := config.PostTitle
+	if title == "" {
+		title = f.i18n.T(ctx, guildID, "welcome.intro_post_title")
+	}
+	title = strings.ReplaceAll(title, "{name}", member.DisplayName())
+
+	color := config.PostColor
+	if color == 0 {
+		color = int(shared.ColorInfo)
+	}
+
+	if runes := []rune(intro); len(runes) > maxIntroLength {
+		intro = string(runes[:maxIntroLength]) + "…"
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Title:       title,
+		Description: intro,
+		Color:       color,
+		Thumbnail:   &discordgo.MessageEmbedThumbnail{URL: member.AvatarURL("256")},
+	}
+	if len(ageRoles) > 0 {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:   f.i18n.T(ctx, guildID, "welcome.intro_post_age"),
+			Value:  roleMentions(ageRoles),
+			Inline: true,
+		})
+	}
+	if len(voiceRoles) > 0 {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:   f.i18n.T(ctx, guildID, "welcome.intro_post_voice"),
+			Value:  roleMentions(voiceRoles),
+			Inline: true,
+		})
+	}
+	return embed
+}
+
+// introChannel picks the self-intro channel matching the member's gender
+// role, falling back to whichever channel is configured.
+func introChannel(config *SelfIntroConfig, memberRoles []string, femaleRoleID string) string {
+	if femaleRoleID != "" && config.FemaleChannelID != "" {
+		for _, roleID := range memberRoles {
+			if roleID == femaleRoleID {
+				return config.FemaleChannelID
+			}
+		}
+	}
+	if config.MaleChannelID != "" {
+		return config.MaleChannelID
+	}
+	return config.FemaleChannelID
+}
+
+// matchingRoles returns the candidate roles the member has, in candidate order.
+func matchingRoles(memberRoles []string, candidates ...string) []string {
+	has := make(map[string]bool, len(memberRoles))
+	for _, roleID := range memberRoles {
+		has[roleID] = true
+	}
+	var matched []string
+	for _, roleID := range candidates {
+		if roleID != "" && has[roleID] {
+			matched = append(matched, roleID)
+		}
+	}
+	return matched
+}
+
+func roleMentions(roleIDs []string) string {
+	mentions := make([]string, len(roleIDs))
+	for n, roleID := range roleIDs {
+		mentions[n] = "<@&" + roleID + ">"
+	}
+	return strings.Join(mentions, " ")
+}
+
+// getSelfIntroConfig retrieves self-intro channel configuration.
+func (f *Feature) getSelfIntroConfig(ctx context.Context, guildID string) (*SelfIntroConfig, error) {
+	query := `
+		SELECT guild_id, male_channel_id, female_channel_id, post_title, post_color
+		FROM guild_selfintro_channels
+		WHERE guild_id = $1
+	`
+	row := f.db.QueryRow(database.ReadFromReplica(ctx), query, guildID)
+
+	var config SelfIntroConfig
+	var male, female, title *string
+	var color *int
+	if err := row.Scan(&config.GuildID, &male, &female, &title, &color); err != nil {
+		return nil, err
+	}
+
+	if male != nil {
+		config.MaleChannelID = *male
+	}
+	if female != nil {
+		config.FemaleChannelID = *female
+	}
+	if title != nil {
+		config.PostTitle = *title
+	}
+	if color != nil {
+		config.PostColor = *color
+	}
+
+	return &config, nil
+}
@@ -0,0 +1,109 @@
+package welcome
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+
+	"welcomebot/internal/core/notify"
+	"welcomebot/internal/core/queue"
+	"welcomebot/internal/fakes"
+	"welcomebot/internal/shared"
+)
+
+func TestHandleOnboardingCompleteSkipsEmptyIntro(t *testing.T) {
+	// No DB or session: an empty intro must return before touching either
+	f := &Feature{logger: fakes.Logger{}}
+
+	task := &queue.Task{Type: "onboarding_complete", GuildID: "guild-1", Payload: map[string]interface{}{
+		"user_id": "user-1",
+		"intro":   "   ",
+	}}
+	if err := f.handleOnboardingComplete(context.Background(), task); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+// recordingNotifier keeps the notifications it is sent.
+type recordingNotifier struct {
+	got []notify.Notification
+}
+
+func (r *recordingNotifier) Notify(ctx context.Context, n notify.Notification) error {
+	r.got = append(r.got, n)
+	return nil
+}
+
+func TestCompletionNotified(t *testing.T) {
+	rec := &recordingNotifier{}
+	f := &Feature{logger: fakes.Logger{}, queue: fakes.NewQueue(), i18n: fakes.I18n{}, notifier: rec}
+
+	task := &queue.Task{ID: "done-1", Type: "onboarding_complete", GuildID: "guild-1", Payload: map[string]interface{}{
+		"user_id": "user-1",
+		"guide":   "visitor",
+	}}
+	if err := f.handleEvent(context.Background(), task); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	f.notifying.Wait()
+	if len(rec.got) != 1 {
+		t.Fatalf("expected one notification, got %d", len(rec.got))
+	}
+	n := rec.got[0]
+	if n.Kind != notify.KindOnboardingCompleted || n.GuildID != "guild-1" || n.UserID != "user-1" {
+		t.Errorf("unexpected notification: %+v", n)
+	}
+	if len(n.Fields) != 2 || n.Fields[1].Value != "visitor" {
+		t.Errorf("expected the guide in the fields, got %+v", n.Fields)
+	}
+}
+
+func TestIntroChannel(t *testing.T) {
+	both := &SelfIntroConfig{MaleChannelID: "male-ch", FemaleChannelID: "female-ch"}
+
+	if got := introChannel(both, []string{"female-role"}, "female-role"); got != "female-ch" {
+		t.Errorf("female member: got %q, want female-ch", got)
+	}
+	if got := introChannel(both, []string{"other"}, "female-role"); got != "male-ch" {
+		t.Errorf("other member: got %q, want male-ch", got)
+	}
+	if got := introChannel(&SelfIntroConfig{FemaleChannelID: "female-ch"}, nil, "female-role"); got != "female-ch" {
+		t.Errorf("fallback: got %q, want female-ch", got)
+	}
+}
+
+func TestBuildIntroEmbed(t *testing.T) {
+	f := &Feature{i18n: fakes.I18n{}}
+	member := &discordgo.Member{Nick: "Kuma", User: &discordgo.User{ID: "user-1", Username: "kuma"}}
+
+	embed := f.buildIntroEmbed(context.Background(), "guild-1", &SelfIntroConfig{}, member,
+		"hello", []string{"age-role"}, nil)
+	if embed.Title != "welcome.intro_post_title" {
+		t.Errorf("expected default title, got %q", embed.Title)
+	}
+	if embed.Color != int(shared.ColorInfo) {
+		t.Errorf("expected default color, got %#x", embed.Color)
+	}
+	if len(embed.Fields) != 1 || embed.Fields[0].Value != "<@&age-role>" {
+		t.Errorf("expected a single age field, got %+v", embed.Fields)
+	}
+	if embed.Thumbnail == nil || embed.Thumbnail.URL == "" {
+		t.Error("expected avatar thumbnail")
+	}
+
+	config := &SelfIntroConfig{PostTitle: "Welcome {name}", PostColor: 0x123456}
+	long := strings.Repeat("あ", maxIntroLength+10)
+	embed = f.buildIntroEmbed(context.Background(), "guild-1", config, member, long, nil, nil)
+	if embed.Title != "Welcome Kuma" {
+		t.Errorf("expected templated title, got %q", embed.Title)
+	}
+	if embed.Color != 0x123456 {
+		t.Errorf("expected configured color, got %#x", embed.Color)
+	}
+	if n := len([]rune(embed.Description)); n != maxIntroLength+1 {
+		t.Errorf("expected truncated intro of %d runes, got %d", maxIntroLength+1, n)
+	}
+}
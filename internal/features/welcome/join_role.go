@@ -0,0 +1,178 @@
+package welcome
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+
+	"welcomebot/internal/bot"
+	"welcomebot/internal/core/discord"
+	"welcomebot/internal/shared"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// JoinRoleFeature gives members the guild's initial role as they join, so
+// channels can stay hidden until they finish onboarding. It is registered
+// apart from Feature because member events need the privileged Server
+// Members intent, which a deployment has to opt into.
+type JoinRoleFeature struct {
+	welcome *Feature
+}
+
+// JoinRoles returns the member join handler for this feature's configs.
+func (f *Feature) JoinRoles() *JoinRoleFeature {
+	return &JoinRoleFeature{welcome: f}
+}
+
+// Name returns the feature name.
+func (j *JoinRoleFeature) Name() string {
+	return featureName + "_join_role"
+}
+
+// HandleInteraction is not used; the role is set from the welcome menu.
+func (j *JoinRoleFeature) HandleInteraction(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	return bot.ErrNotHandled
+}
+
+// RegisterCommands returns no commands.
+func (j *JoinRoleFeature) RegisterCommands() []*discordgo.ApplicationCommand {
+	return nil
+}
+
+// GetMenuButton returns nil; the welcome feature owns the menu button.
+func (j *JoinRoleFeature) GetMenuButton() *bot.MenuButton {
+	return nil
+}
+
+// HandleMemberJoin gives a new member the initial role, if the guild has
+// one. Bots and members who already have it are left alone, so a repeated
+// event changes nothing.
+func (j *JoinRoleFeature) HandleMemberJoin(ctx context.Context, s *discordgo.Session, m *discordgo.GuildMemberAdd) error {
+	if m.Member == nil || m.User == nil || m.User.Bot {
+		return nil
+	}
+
+	config, err := j.welcome.getWelcomeConfig(ctx, m.GuildID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("get welcome config: %w", err)
+	}
+	if config.InitialRoleID == "" || slices.Contains(m.Roles, config.InitialRoleID) {
+		return nil
+	}
+
+	err = discord.Retry(ctx, j.welcome.logger, "add initial role", discord.DefaultRetryPolicy, func() error {
+		return s.GuildMemberRoleAdd(m.GuildID, m.User.ID, config.InitialRoleID)
+	})
+	if err != nil {
+		return fmt.Errorf("add initial role: %w", err)
+	}
+
+	j.welcome.logger.Info("initial role added",
+		"guild_id", m.GuildID,
+		"user_id", m.User.ID,
+		"role_id", config.InitialRoleID,
+	)
+	return nil
+}
+
+// HandleMemberLeave does nothing; the role leaves with the member.
+func (j *JoinRoleFeature) HandleMemberLeave(ctx context.Context, s *discordgo.Session, m *discordgo.GuildMemberRemove) error {
+	return bot.ErrNotHandled
+}
+
+// saveInitialRole stores the role given on join; "" turns it off.
+func (f *Feature) saveInitialRole(ctx context.Context, config *WelcomeConfig, roleID string) error {
+	_, err := f.db.Exec(ctx, `
+		UPDATE guild_welcome_config
+		SET initial_role_id = NULLIF($1, ''), updated_at = NOW()
+		WHERE guild_id = $2
+	`, roleID, config.GuildID)
+	if err != nil {
+		return fmt.Errorf("save initial role: %w", err)
+	}
+	config.InitialRoleID = roleID
+
+	if err := f.cache.Delete(ctx, cacheKeyPrefix+config.GuildID); err != nil {
+		f.logger.Warn("failed to invalidate welcome config", "error", err, "guild_id", config.GuildID)
+	}
+	return nil
+}
+
+// showInitialRole shows the role given on join, with a menu to change it.
+func (f *Feature) showInitialRole(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	guildID := i.GuildID
+
+	config, err := f.getWelcomeConfig(ctx, guildID)
+	if err != nil {
+		return f.respondErrorMessage(ctx, s, i, guildID, "welcome.config_not_found")
+	}
+
+	return respond(s, i, f.initialRoleEmbed(ctx, config), f.initialRoleComponents(ctx, config))
+}
+
+// handleInitialRoleSelection saves the selected initial role.
+func (f *Feature) handleInitialRoleSelection(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	guildID := i.GuildID
+
+	config, err := f.getWelcomeConfig(ctx, guildID)
+	if err != nil {
+		return f.respondErrorMessage(ctx, s, i, guildID, "welcome.config_not_found")
+	}
+
+	var roleID string
+	if values := i.MessageComponentData().Values; len(values) > 0 {
+		roleID = values[0]
+	}
+
+	// Master gives this role itself, so its own place in the hierarchy counts
+	if roleID != "" && s.State.User != nil {
+		blocked, err := discord.UnmanageableRoles(s, guildID, s.State.User.ID, []string{roleID})
+		if err != nil {
+			f.logger.Warn("failed to check role hierarchy", "error", err, "guild_id", guildID)
+		} else if len(blocked) > 0 {
+			return bot.RoleAboveBotError(roleID)
+		}
+	}
+
+	if err := f.saveInitialRole(ctx, config, roleID); err != nil {
+		return f.respondError(ctx, s, i, guildID, err)
+	}
+
+	f.logger.Info("initial role updated", "guild_id", guildID, "role_id", roleID)
+
+	return respond(s, i, f.initialRoleEmbed(ctx, config), f.initialRoleComponents(ctx, config))
+}
+
+func (f *Feature) initialRoleEmbed(ctx context.Context, config *WelcomeConfig) *discordgo.MessageEmbed {
+	guildID := config.GuildID
+
+	current := f.i18n.T(ctx, guildID, "welcome.initial_role_none")
+	if config.InitialRoleID != "" {
+		current = fmt.Sprintf("<@&%s>", config.InitialRoleID)
+	}
+
+	return &discordgo.MessageEmbed{
+		Title:       f.i18n.T(ctx, guildID, "welcome.initial_role_title"),
+		Description: f.i18n.T(ctx, guildID, "welcome.initial_role_description") + "\n\n" + current,
+		Color:       int(shared.ColorInfo),
+	}
+}
+
+func (f *Feature) initialRoleComponents(ctx context.Context, config *WelcomeConfig) []discordgo.MessageComponent {
+	// Clearing the menu stops giving a role on join
+	menu := discord.RoleSelectMenu("welcome:initial_role:select",
+		f.i18n.T(ctx, config.GuildID, "welcome.select_initial_role"), config.InitialRoleID)
+	minValues := 0
+	menu.MinValues = &minValues
+	menu.MaxValues = 1
+
+	return []discordgo.MessageComponent{
+		discordgo.ActionsRow{Components: []discordgo.MessageComponent{menu}},
+	}
+}
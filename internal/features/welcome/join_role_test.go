@@ -0,0 +1,64 @@
+package welcome
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"welcomebot/internal/fakes"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func TestHandleMemberJoin(t *testing.T) {
+	ctx := context.Background()
+	d := fakes.NewDiscord()
+	cache := fakes.NewCache()
+	f := &Feature{cache: cache, db: fakes.NewDB(), logger: fakes.Logger{}}
+	joins := f.JoinRoles()
+
+	config := &WelcomeConfig{GuildID: "guild-1", InitialRoleID: "role-visitor"}
+	if err := cache.SetJSON(ctx, cacheKeyPrefix+"guild-1", config, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	join := func(user *discordgo.User, roles ...string) {
+		t.Helper()
+		m := &discordgo.GuildMemberAdd{Member: &discordgo.Member{GuildID: "guild-1", User: user, Roles: roles}}
+		if err := joins.HandleMemberJoin(ctx, d.Session(), m); err != nil {
+			t.Fatalf("HandleMemberJoin: %v", err)
+		}
+	}
+
+	join(&discordgo.User{ID: "user-1"})
+	if !d.HasRole("guild-1", "user-1", "role-visitor") {
+		t.Error("expected a new member to get the initial role")
+	}
+
+	// Members who already have it and bots get no request
+	join(&discordgo.User{ID: "user-2"}, "role-visitor")
+	join(&discordgo.User{ID: "bot-1", Bot: true})
+	if got := countRoleAdds(d); got != 1 {
+		t.Errorf("expected one role add, got %d", got)
+	}
+
+	// Clearing the role turns it off
+	config.InitialRoleID = ""
+	if err := cache.SetJSON(ctx, cacheKeyPrefix+"guild-1", config, 0); err != nil {
+		t.Fatal(err)
+	}
+	join(&discordgo.User{ID: "user-3"})
+	if got := countRoleAdds(d); got != 1 {
+		t.Errorf("expected no role add without an initial role, got %d", got)
+	}
+}
+
+func countRoleAdds(d *fakes.Discord) int {
+	n := 0
+	for _, r := range d.Requests() {
+		if r.Method == http.MethodPut {
+			n++
+		}
+	}
+	return n
+}
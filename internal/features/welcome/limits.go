@@ -0,0 +1,175 @@
+package welcome
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"welcomebot/internal/core/i18n"
+	"welcomebot/internal/shared"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// acquireGuildSlot reserves one of the guild's concurrent session slots.
+// If the counter cannot be reached the start is allowed rather than blocked.
+func (f *Feature) acquireGuildSlot(ctx context.Context, guildID string) bool {
+	key := guildSessionsKeyPrefix + guildID
+
+	active, err := f.cache.Incr(ctx, key)
+	if err != nil {
+		f.logger.Warn("failed to count guild sessions", "error", err, "guild_id", guildID)
+		return true
+	}
+	if err := f.cache.Expire(ctx, key, guildSessionsTTL); err != nil {
+		f.logger.Warn("failed to set guild session counter ttl", "error", err, "guild_id", guildID)
+	}
+
+	if active > int64(f.maxGuildSessions) {
+		f.releaseGuildSlot(ctx, guildID)
+		f.logger.Info("guild session limit reached",
+			"guild_id", guildID,
+			"limit", f.maxGuildSessions,
+		)
+		return false
+	}
+	return true
+}
+
+// releaseGuildSlot returns a slot taken by acquireGuildSlot.
+func (f *Feature) releaseGuildSlot(ctx context.Context, guildID string) {
+	if _, err := f.cache.Decr(ctx, guildSessionsKeyPrefix+guildID); err != nil {
+		f.logger.Warn("failed to release guild session slot", "error", err, "guild_id", guildID)
+	}
+}
+
+// claimStartClick reports whether this is the member's first welcome
+// button click within startClickWindow. Repeat clicks from an impatient
+// member or a laggy client are answered without doing any work. Like the
+// guild slots, an unreachable cache lets the click through.
+func (f *Feature) claimStartClick(ctx context.Context, guildID, userID string) bool {
+	ok, err := f.cache.SetNX(ctx, startClickKeyPrefix+guildID+":"+userID, "1", startClickWindow)
+	if err != nil {
+		f.logger.Warn("failed to dedupe welcome click", "error", err, "guild_id", guildID, "user_id", userID)
+		return true
+	}
+	return ok
+}
+
+// lockStart takes the member's start lock, so the active session check and
+// the session write of two starts for one member can't interleave. It
+// returns false if another start holds the lock; otherwise unlock must be
+// called once the start is done.
+func (f *Feature) lockStart(ctx context.Context, guildID, userID string) (unlock func(), ok bool) {
+	key := startLockKeyPrefix + guildID + ":" + userID
+	ok, err := f.cache.SetNX(ctx, key, "1", startLockTTL)
+	if err != nil {
+		f.logger.Warn("failed to lock onboarding start", "error", err, "guild_id", guildID, "user_id", userID)
+		return func() {}, true
+	}
+	if !ok {
+		return nil, false
+	}
+	return func() {
+		if err := f.cache.Delete(ctx, key); err != nil {
+			f.logger.Warn("failed to unlock onboarding start", "error", err, "guild_id", guildID, "user_id", userID)
+		}
+	}, true
+}
+
+// claimSlave reserves an available slave for one start, so concurrent
+// starts for different members don't pick the same slave before either
+// marks it busy.
+func (f *Feature) claimSlave(ctx context.Context, slaveID string) bool {
+	ok, err := f.cache.SetNX(ctx, slaveClaimKeyPrefix+slaveID, "1", slaveClaimTTL)
+	if err != nil {
+		f.logger.Warn("failed to claim slave", "error", err, "slave_id", slaveID)
+		return true
+	}
+	return ok
+}
+
+// releaseSlaveClaim drops a claim taken by claimSlave, once the slave is
+// marked busy or the start has failed.
+func (f *Feature) releaseSlaveClaim(ctx context.Context, slaveID string) {
+	if err := f.cache.Delete(ctx, slaveClaimKeyPrefix+slaveID); err != nil {
+		f.logger.Warn("failed to release slave claim", "error", err, "slave_id", slaveID)
+	}
+}
+
+// attemptCooldown returns how long the member must wait before starting
+// onboarding again, or 0 if they may start now. Like the guild slots, an
+// unreachable counter lets the start through.
+func (f *Feature) attemptCooldown(ctx context.Context, guildID, userID string) time.Duration {
+	key := attemptsKeyPrefix + guildID + ":" + userID
+
+	val, err := f.cache.Get(ctx, key)
+	if err != nil {
+		return 0
+	}
+	attempts, err := strconv.ParseInt(val, 10, 64)
+	if err != nil || attempts < int64(f.maxAttempts) {
+		return 0
+	}
+
+	wait, err := f.cache.TTL(ctx, key)
+	if err != nil {
+		f.logger.Warn("failed to read onboarding attempt ttl", "error", err, "guild_id", guildID, "user_id", userID)
+		return 0
+	}
+	if wait <= 0 {
+		// A counter that lost its TTL would block the member forever
+		if err := f.cache.Expire(ctx, key, f.attemptWindow); err != nil {
+			f.logger.Warn("failed to set onboarding attempt ttl", "error", err, "guild_id", guildID, "user_id", userID)
+		}
+		wait = f.attemptWindow
+	}
+
+	f.logger.Info("onboarding attempt limit reached",
+		"guild_id", guildID,
+		"user_id", userID,
+		"attempts", attempts,
+		"retry_in", wait,
+	)
+	return wait
+}
+
+// recordAttempt counts an onboarding start. The first start in a window
+// sets the counter's TTL, so the window runs from that start.
+func (f *Feature) recordAttempt(ctx context.Context, guildID, userID string) {
+	key := attemptsKeyPrefix + guildID + ":" + userID
+
+	attempts, err := f.cache.Incr(ctx, key)
+	if err != nil {
+		f.logger.Warn("failed to count onboarding attempt", "error", err, "guild_id", guildID, "user_id", userID)
+		return
+	}
+	if attempts == 1 {
+		if err := f.cache.Expire(ctx, key, f.attemptWindow); err != nil {
+			f.logger.Warn("failed to set onboarding attempt ttl", "error", err, "guild_id", guildID, "user_id", userID)
+		}
+	}
+}
+
+// respondAttemptCooldown tells the member when they can try again.
+func (f *Feature) respondAttemptCooldown(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate, guildID string, wait time.Duration) error {
+	locale, _ := f.i18n.GetGuildLanguage(ctx, guildID)
+
+	embed := &discordgo.MessageEmbed{
+		Title: f.i18n.T(ctx, guildID, "welcome.attempts_cooldown_title"),
+		Description: f.i18n.TWithArgs(ctx, guildID, "welcome.attempts_cooldown", map[string]string{
+			"attempts": fmt.Sprintf("%d", f.maxAttempts),
+			"wait":     i18n.FormatDuration(locale, max(wait.Round(time.Minute), time.Minute)),
+		}),
+		Color: int(shared.ColorWarning),
+	}
+
+	return s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Embeds: []*discordgo.MessageEmbed{embed},
+			Flags:  discordgo.MessageFlagsEphemeral,
+		},
+	})
+}
@@ -0,0 +1,196 @@
+package welcome
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"welcomebot/internal/core/queue"
+	"welcomebot/internal/fakes"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func TestGuildSlots(t *testing.T) {
+	ctx := context.Background()
+	f := &Feature{cache: fakes.NewCache(), logger: fakes.Logger{}, maxGuildSessions: 2}
+
+	for n := 0; n < 2; n++ {
+		if !f.acquireGuildSlot(ctx, "guild-1") {
+			t.Fatalf("slot %d should be available", n)
+		}
+	}
+	if f.acquireGuildSlot(ctx, "guild-1") {
+		t.Error("expected third session to be rejected")
+	}
+
+	// Other guilds have their own limit
+	if !f.acquireGuildSlot(ctx, "guild-2") {
+		t.Error("expected another guild to get a slot")
+	}
+
+	// A rejected start must not hold a slot
+	f.releaseGuildSlot(ctx, "guild-1")
+	if !f.acquireGuildSlot(ctx, "guild-1") {
+		t.Error("expected slot to be free after release")
+	}
+}
+
+func TestGuildSlotsFailOpen(t *testing.T) {
+	cache := fakes.NewCache()
+	f := &Feature{cache: cache, logger: fakes.Logger{}, maxGuildSessions: 1}
+
+	// A corrupt counter makes Incr fail; starts are still allowed
+	_ = cache.Set(context.Background(), guildSessionsKeyPrefix+"guild-1", "x", 0)
+	for n := 0; n < 3; n++ {
+		if !f.acquireGuildSlot(context.Background(), "guild-1") {
+			t.Fatal("expected start to be allowed when the counter is unavailable")
+		}
+	}
+}
+
+func TestAttemptCooldown(t *testing.T) {
+	ctx := context.Background()
+	cache := fakes.NewCache()
+	f := &Feature{cache: cache, logger: fakes.Logger{}, maxAttempts: 2, attemptWindow: time.Hour}
+
+	for n := 0; n < 2; n++ {
+		if wait := f.attemptCooldown(ctx, "guild-1", "alice"); wait != 0 {
+			t.Fatalf("attempt %d should be allowed, got cooldown %v", n, wait)
+		}
+		f.recordAttempt(ctx, "guild-1", "alice")
+	}
+
+	if wait := f.attemptCooldown(ctx, "guild-1", "alice"); wait != time.Hour {
+		t.Errorf("expected a one hour cooldown, got %v", wait)
+	}
+
+	// Other members are counted separately
+	if wait := f.attemptCooldown(ctx, "guild-1", "bob"); wait != 0 {
+		t.Errorf("expected another member to be allowed, got cooldown %v", wait)
+	}
+
+	// A counter without a TTL is given one rather than blocking forever
+	_ = cache.Set(ctx, attemptsKeyPrefix+"guild-1:carol", "5", 0)
+	if wait := f.attemptCooldown(ctx, "guild-1", "carol"); wait != time.Hour {
+		t.Errorf("expected the window as cooldown, got %v", wait)
+	}
+	if ttl, _ := cache.TTL(ctx, attemptsKeyPrefix+"guild-1:carol"); ttl != time.Hour {
+		t.Errorf("expected the counter to get a ttl, got %v", ttl)
+	}
+}
+
+func TestClaimStartClick(t *testing.T) {
+	ctx := context.Background()
+	f := &Feature{cache: fakes.NewCache(), logger: fakes.Logger{}}
+
+	if !f.claimStartClick(ctx, "guild-1", "alice") {
+		t.Fatal("expected the first click to go through")
+	}
+	if f.claimStartClick(ctx, "guild-1", "alice") {
+		t.Error("expected a repeat click to be deduped")
+	}
+	if !f.claimStartClick(ctx, "guild-1", "bob") || !f.claimStartClick(ctx, "guild-2", "alice") {
+		t.Error("expected other members and guilds to be unaffected")
+	}
+}
+
+func TestLockStart(t *testing.T) {
+	ctx := context.Background()
+	cache := fakes.NewCache()
+	f := &Feature{cache: cache, logger: fakes.Logger{}}
+
+	unlock, ok := f.lockStart(ctx, "guild-1", "alice")
+	if !ok {
+		t.Fatal("expected the lock to be free")
+	}
+	if _, ok := f.lockStart(ctx, "guild-1", "alice"); ok {
+		t.Error("expected a second start to be refused while the lock is held")
+	}
+	if ttl, _ := cache.TTL(ctx, startLockKeyPrefix+"guild-1:alice"); ttl != startLockTTL {
+		t.Error("expected the lock to expire on its own")
+	}
+
+	unlock()
+	if _, ok := f.lockStart(ctx, "guild-1", "alice"); !ok {
+		t.Error("expected the lock to be free after unlock")
+	}
+}
+
+func TestFindAvailableSlaveClaims(t *testing.T) {
+	ctx := context.Background()
+	f := &Feature{cache: fakes.NewCache(), logger: fakes.Logger{}}
+	_ = f.setSlaveStatus(ctx, SlaveIDs[0], SlaveStatusAvailable)
+	_ = f.setSlaveStatus(ctx, SlaveIDs[1], SlaveStatusAvailable)
+
+	// Two starts racing past the status check get different slaves
+	first, err := f.findAvailableSlave(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := f.findAvailableSlave(ctx)
+	if err != nil || second == first {
+		t.Fatalf("expected a second slave, got %q, %v", second, err)
+	}
+	if _, err := f.findAvailableSlave(ctx); err == nil {
+		t.Error("expected no slave while both are claimed")
+	}
+
+	f.releaseSlaveClaim(ctx, first)
+	if got, err := f.findAvailableSlave(ctx); err != nil || got != first {
+		t.Errorf("expected the released slave, got %q, %v", got, err)
+	}
+}
+
+func TestStartOnboardingQueueFull(t *testing.T) {
+	ctx := context.Background()
+	cache := fakes.NewCache()
+	q := &fakes.Queue{MaxDepth: 1}
+	f := &Feature{cache: cache, queue: q, i18n: fakes.I18n{}, logger: fakes.Logger{}, maxGuildSessions: 5, maxAttempts: 3}
+
+	seed := map[string]any{
+		cacheKeyPrefix + "guild-1":           &WelcomeConfig{GuildID: "guild-1"},
+		ageRangeCacheKeyPrefix + "guild-1":   &AgeRangeConfig{},
+		genderCacheKeyPrefix + "guild-1":     &GenderConfig{},
+		voiceTypeCacheKeyPrefix + "guild-1":  &VoiceTypeConfig{},
+		otherRolesCacheKeyPrefix + "guild-1": &OtherRolesConfig{},
+		guideRolesKeyPrefix + "guild-1":      map[string]GuideCompletionRoles{},
+	}
+	for key, value := range seed {
+		if err := cache.SetJSON(ctx, key, value, 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+	_ = f.setSlaveStatus(ctx, SlaveIDs[0], SlaveStatusAvailable)
+	if err := q.Enqueue(ctx, queue.Task{ID: "backlog"}); err != nil {
+		t.Fatal(err)
+	}
+
+	d := fakes.NewDiscord()
+	i := &discordgo.InteractionCreate{Interaction: &discordgo.Interaction{
+		ID: "interaction-1", Token: "token", GuildID: "guild-1",
+		Type:   discordgo.InteractionMessageComponent,
+		Member: &discordgo.Member{User: &discordgo.User{ID: "user-1"}},
+	}}
+	if err := f.startOnboarding(ctx, d.Session(), i, "user-1", nil); err != nil {
+		t.Fatalf("startOnboarding: %v", err)
+	}
+
+	requests := d.Requests()
+	last := requests[len(requests)-1]
+	if !strings.Contains(string(last.Body), "welcome.queue_full") {
+		t.Errorf("expected a queue_full response, got %s", last.Body)
+	}
+	if tasks := q.Tasks(); len(tasks) != 1 {
+		t.Errorf("expected only the backlog task, got %+v", tasks)
+	}
+
+	// The rejected start gives back its slave and guild slot
+	if slave, err := f.findAvailableSlave(ctx); err != nil || slave != SlaveIDs[0] {
+		t.Errorf("expected the slave to be free, got %q, %v", slave, err)
+	}
+	if n, _ := cache.Incr(ctx, guildSessionsKeyPrefix+"guild-1"); n != 1 {
+		t.Errorf("expected no guild slot held, counter at %d", n-1)
+	}
+}
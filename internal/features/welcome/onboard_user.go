@@ -0,0 +1,57 @@
+package welcome
+
+import (
+	"context"
+	"fmt"
+
+	"welcomebot/internal/core/discord"
+	"welcomebot/internal/shared"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// showOnboardUser asks staff for the member to onboard. The session starts
+// as if the member had pressed the welcome button, so staff can walk a
+// returning or special member through it without them finding the button.
+func (f *Feature) showOnboardUser(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	guildID := i.GuildID
+
+	if _, err := f.getWelcomeConfig(ctx, guildID); err != nil {
+		return f.respondErrorMessage(ctx, s, i, guildID, "welcome.config_not_found")
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Title:       f.i18n.T(ctx, guildID, "welcome.onboard_user_title"),
+		Description: f.i18n.T(ctx, guildID, "welcome.onboard_user_description"),
+		Color:       int(shared.ColorInfo),
+	}
+	menu := discord.UserSelectMenu("welcome:onboard_user:select", f.i18n.T(ctx, guildID, "welcome.onboard_user_select"))
+
+	return respond(s, i, embed, []discordgo.MessageComponent{
+		discordgo.ActionsRow{Components: []discordgo.MessageComponent{menu}},
+	})
+}
+
+// handleOnboardUserSelection starts onboarding for the selected member
+// after checking they are still in the guild.
+func (f *Feature) handleOnboardUserSelection(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	guildID := i.GuildID
+
+	userID := selectedValue(i)
+	if userID == "" {
+		return f.respondErrorMessage(ctx, s, i, guildID, "welcome.onboard_user_not_member")
+	}
+
+	member, err := s.GuildMember(guildID, userID)
+	if discord.IsNotFound(err) {
+		return f.respondErrorMessage(ctx, s, i, guildID, "welcome.onboard_user_not_member")
+	}
+	if err != nil {
+		return fmt.Errorf("get member: %w", err)
+	}
+	if member.User != nil && member.User.Bot {
+		return f.respondErrorMessage(ctx, s, i, guildID, "welcome.onboard_user_bot")
+	}
+
+	return f.startOnboarding(ctx, s, i, userID, nil)
+}
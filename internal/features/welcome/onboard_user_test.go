@@ -0,0 +1,62 @@
+package welcome
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"welcomebot/internal/fakes"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func TestHandleOnboardUserSelection(t *testing.T) {
+	ctx := context.Background()
+	cache := fakes.NewCache()
+	q := fakes.NewQueue()
+	f := &Feature{cache: cache, queue: q, i18n: fakes.I18n{}, logger: fakes.Logger{}}
+	if err := cache.SetJSON(ctx, cacheKeyPrefix+"guild-1", &WelcomeConfig{GuildID: "guild-1"}, 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := cache.SetJSON(ctx, sessionKeyPrefix+"guild-1:user-busy", OnboardingSession{GuildID: "guild-1", UserID: "user-busy"}, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		userID string
+		want   string
+	}{
+		{"not in the guild", "user-gone", "welcome.onboard_user_not_member"},
+		{"already onboarding", "user-busy", "welcome.onboard_user_active"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := fakes.NewDiscord()
+			d.SetNotFound("guilds/guild-1/members/user-gone")
+
+			i := &discordgo.InteractionCreate{Interaction: &discordgo.Interaction{
+				ID: "interaction-1", Token: "token", GuildID: "guild-1",
+				Type:   discordgo.InteractionMessageComponent,
+				Member: &discordgo.Member{User: &discordgo.User{ID: "admin-1"}},
+				Data: discordgo.MessageComponentInteractionData{
+					CustomID: "welcome:onboard_user:select",
+					Values:   []string{tt.userID},
+				},
+			}}
+			if err := f.HandleInteraction(ctx, d.Session(), i); err != nil {
+				t.Fatalf("HandleInteraction: %v", err)
+			}
+
+			requests := d.Requests()
+			last := requests[len(requests)-1]
+			if !strings.HasSuffix(last.Path, "/callback") || !strings.Contains(string(last.Body), tt.want) {
+				t.Errorf("expected a %s response, got %s %s", tt.want, last.Path, last.Body)
+			}
+		})
+	}
+
+	if tasks := q.Tasks(); len(tasks) != 0 {
+		t.Errorf("expected no onboarding task, got %+v", tasks)
+	}
+}
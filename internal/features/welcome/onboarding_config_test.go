@@ -0,0 +1,126 @@
+package welcome
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"welcomebot/internal/fakes"
+	"welcomebot/internal/onboard"
+)
+
+func TestOnboardingConfigRoundTrip(t *testing.T) {
+	onboarding := onboardingConfig(
+		&WelcomeConfig{VCCategoryID: "category-1", MemberRoleID: "role-member", VisitorRoleID: "role-visitor"},
+		&AgeRangeConfig{Age30LateRoleID: "role-30late"},
+		nil,
+		&VoiceTypeConfig{LowRoleID: "role-low"},
+		nil,
+		map[string]GuideCompletionRoles{"kk": {MemberRoleID: "role-tier2"}},
+	)
+	payload := map[string]interface{}{
+		"user_id":                "user-1",
+		"slave_id":               "slave-1",
+		onboard.ConfigVersionKey: onboard.OnboardingConfigVersion,
+		onboard.ConfigKey:        onboarding,
+	}
+
+	// Tasks reach the worker as JSON
+	data, err := json.Marshal(payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var raw map[string]interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		t.Fatal(err)
+	}
+
+	parsed, err := onboard.ParseStartPayload(raw)
+	if err != nil {
+		t.Fatalf("ParseStartPayload: %v", err)
+	}
+	if parsed.CategoryID != "category-1" || parsed.MemberRole != "role-member" || parsed.VisitorRole != "role-visitor" {
+		t.Errorf("welcome settings: got %+v", parsed.OnboardingConfig)
+	}
+	if parsed.Age30LateRole != "role-30late" || parsed.LowVoiceRole != "role-low" || parsed.MaleRole != "" {
+		t.Errorf("role groups: got %+v", parsed.OnboardingConfig)
+	}
+	if parsed.GuideCompletionRoles["kk"].MemberRoleID != "role-tier2" {
+		t.Errorf("guide completion roles: got %+v", parsed.GuideCompletionRoles)
+	}
+}
+
+// cacheStartConfigs caches every config an onboarding start reads.
+func cacheStartConfigs(tb testing.TB, cache *fakes.Cache, guildID string) {
+	tb.Helper()
+	ctx := context.Background()
+	for key, value := range map[string]interface{}{
+		cacheKeyPrefix + guildID:           &WelcomeConfig{GuildID: guildID, MemberRoleID: "role-member"},
+		ageRangeCacheKeyPrefix + guildID:   &AgeRangeConfig{GuildID: guildID, Age30LateRoleID: "role-30late"},
+		genderCacheKeyPrefix + guildID:     &GenderConfig{GuildID: guildID, MaleRoleID: "role-male"},
+		voiceTypeCacheKeyPrefix + guildID:  &VoiceTypeConfig{GuildID: guildID, LowRoleID: "role-low"},
+		otherRolesCacheKeyPrefix + guildID: &OtherRolesConfig{GuildID: guildID},
+		guideRolesKeyPrefix + guildID:      map[string]GuideCompletionRoles{"kk": {MemberRoleID: "role-tier2"}},
+	} {
+		if err := cache.SetJSON(ctx, key, value, 0); err != nil {
+			tb.Fatal(err)
+		}
+	}
+}
+
+func TestFetchStartConfigs(t *testing.T) {
+	ctx := context.Background()
+	cache := fakes.NewCache()
+	f := &Feature{cache: cache, db: fakes.NewDB(), logger: fakes.Logger{}}
+	cacheStartConfigs(t, cache, "guild-1")
+
+	cached := f.fetchStartConfigs(ctx, "guild-1")
+	if cached.welcome == nil || cached.welcome.MemberRoleID != "role-member" {
+		t.Fatalf("expected the cached welcome config, got %+v", cached.welcome)
+	}
+	onboarding := f.loadOnboardingConfig(ctx, cached.welcome, cached)
+	if onboarding.Age30LateRole != "role-30late" || onboarding.MaleRole != "role-male" || onboarding.LowVoiceRole != "role-low" {
+		t.Errorf("role groups: got %+v", onboarding)
+	}
+	if onboarding.GuideCompletionRoles["kk"].MemberRoleID != "role-tier2" {
+		t.Errorf("guide completion roles: got %+v", onboarding.GuideCompletionRoles)
+	}
+	if reads := cache.Reads(); reads != 1 {
+		t.Errorf("expected one cache round trip, got %d", reads)
+	}
+
+	if cached := f.fetchStartConfigs(ctx, "guild-2"); cached.welcome != nil || cached.ageRange != nil || cached.guideRoles != nil {
+		t.Errorf("expected nothing cached for another guild, got %+v", cached)
+	}
+}
+
+// BenchmarkLoadOnboardingConfig compares reading a start's configs one
+// key at a time with fetching them together. Both report the cache round
+// trips per start.
+func BenchmarkLoadOnboardingConfig(b *testing.B) {
+	ctx := context.Background()
+
+	b.Run("separate", func(b *testing.B) {
+		cache := fakes.NewCache()
+		f := &Feature{cache: cache, db: fakes.NewDB(), logger: fakes.Logger{}}
+		cacheStartConfigs(b, cache, "guild-1")
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			config, _ := f.getWelcomeConfig(ctx, "guild-1")
+			f.loadOnboardingConfig(ctx, config, nil)
+		}
+		b.ReportMetric(float64(cache.Reads())/float64(b.N), "round-trips/op")
+	})
+
+	b.Run("pipelined", func(b *testing.B) {
+		cache := fakes.NewCache()
+		f := &Feature{cache: cache, db: fakes.NewDB(), logger: fakes.Logger{}}
+		cacheStartConfigs(b, cache, "guild-1")
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			cached := f.fetchStartConfigs(ctx, "guild-1")
+			f.loadOnboardingConfig(ctx, cached.welcome, cached)
+		}
+		b.ReportMetric(float64(cache.Reads())/float64(b.N), "round-trips/op")
+	})
+}
@@ -0,0 +1,147 @@
+package welcome
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"welcomebot/internal/onboard"
+	"welcomebot/internal/shared"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// onboardingSummaryCommand shows what a member chose during onboarding.
+const onboardingSummaryCommand = "onboarding-summary"
+
+// onboardingSummary is one row of member_onboarding_selections.
+type onboardingSummary struct {
+	Guide       string
+	Selections  onboard.Selections
+	CompletedAt time.Time
+}
+
+// onboardingSummaryCommandDefinition is registered with Discord. Staff
+// access is checked when the command runs, since the staff role is set
+// per guild in the setup wizard.
+func onboardingSummaryCommandDefinition() *discordgo.ApplicationCommand {
+	dmPermission := false
+	return &discordgo.ApplicationCommand{
+		Name:         onboardingSummaryCommand,
+		Description:  "Show what a member selected during onboarding (staff only)",
+		DMPermission: &dmPermission,
+		Options: []*discordgo.ApplicationCommandOption{
+			{
+				Type:        discordgo.ApplicationCommandOptionUser,
+				Name:        "user",
+				Description: "Member to look up",
+				Required:    true,
+			},
+		},
+	}
+}
+
+// isStaff reports whether the member who ran an interaction may see other
+// members' onboarding records: administrators, and holders of the guild's
+// staff role.
+func isStaff(i *discordgo.InteractionCreate, config *WelcomeConfig) bool {
+	if i.Member == nil {
+		return false
+	}
+	if i.Member.Permissions&discordgo.PermissionAdministrator != 0 {
+		return true
+	}
+	return config != nil && config.StaffRoleID != "" && slices.Contains(i.Member.Roles, config.StaffRoleID)
+}
+
+// handleOnboardingSummary shows a member's recorded onboarding choices to
+// staff.
+func (f *Feature) handleOnboardingSummary(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	guildID := i.GuildID
+
+	// Without a config only administrators count as staff
+	config, err := f.getWelcomeConfig(ctx, guildID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return f.respondError(ctx, s, i, guildID, err)
+	}
+	if !isStaff(i, config) {
+		f.logger.Warn("onboarding summary denied", "guild_id", guildID)
+		return f.respondErrorMessage(ctx, s, i, guildID, "welcome.summary_staff_only")
+	}
+
+	var userID string
+	for _, opt := range i.ApplicationCommandData().Options {
+		if opt.Name == "user" {
+			userID = opt.UserValue(nil).ID
+		}
+	}
+
+	summary, err := f.loadOnboardingSummary(ctx, guildID, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return f.respondErrorMessage(ctx, s, i, guildID, "welcome.summary_not_found")
+	}
+	if err != nil {
+		return f.respondError(ctx, s, i, guildID, err)
+	}
+
+	return s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Embeds: []*discordgo.MessageEmbed{f.onboardingSummaryEmbed(ctx, guildID, userID, summary)},
+			Flags:  discordgo.MessageFlagsEphemeral,
+		},
+	})
+}
+
+// loadOnboardingSummary loads the choices from the member's last completed
+// onboarding.
+func (f *Feature) loadOnboardingSummary(ctx context.Context, guildID, userID string) (*onboardingSummary, error) {
+	query := `
+		SELECT guide, selections, completed_at
+		FROM member_onboarding_selections
+		WHERE guild_id = $1 AND user_id = $2
+	`
+
+	var summary onboardingSummary
+	var guide sql.NullString
+	var selections []byte
+	if err := f.db.QueryRow(ctx, query, guildID, userID).Scan(&guide, &selections, &summary.CompletedAt); err != nil {
+		return nil, err
+	}
+	summary.Guide = guide.String
+
+	if err := json.Unmarshal(selections, &summary.Selections); err != nil {
+		return nil, fmt.Errorf("decode onboarding selections: %w", err)
+	}
+	return &summary, nil
+}
+
+// onboardingSummaryEmbed lists the member's choices in the order they were
+// asked, with a dash for prompts they didn't answer.
+func (f *Feature) onboardingSummaryEmbed(ctx context.Context, guildID, userID string, summary *onboardingSummary) *discordgo.MessageEmbed {
+	fields := []*discordgo.MessageEmbedField{
+		{Name: f.i18n.T(ctx, guildID, "welcome.timeline_guide"), Value: orDash(summary.Guide), Inline: true},
+	}
+	for _, name := range onboard.SelectionNames() {
+		fields = append(fields, &discordgo.MessageEmbedField{
+			Name:   f.i18n.T(ctx, guildID, "welcome.summary_selection_"+name),
+			Value:  orDash(strings.Join(summary.Selections[name], ", ")),
+			Inline: true,
+		})
+	}
+
+	return &discordgo.MessageEmbed{
+		Title: f.i18n.TWithArgs(ctx, guildID, "welcome.summary_title", map[string]string{
+			"user": fmt.Sprintf("<@%s>", userID),
+		}),
+		Description: f.i18n.T(ctx, guildID, "welcome.summary_description"),
+		Color:       int(shared.ColorInfo),
+		Fields:      fields,
+		Timestamp:   summary.CompletedAt.Format(time.RFC3339),
+	}
+}
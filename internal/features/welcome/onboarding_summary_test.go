@@ -0,0 +1,62 @@
+package welcome
+
+import (
+	"context"
+	"testing"
+
+	"welcomebot/internal/fakes"
+	"welcomebot/internal/onboard"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func TestIsStaff(t *testing.T) {
+	config := &WelcomeConfig{StaffRoleID: "role-staff"}
+
+	tests := []struct {
+		name   string
+		member *discordgo.Member
+		config *WelcomeConfig
+		want   bool
+	}{
+		{"staff role", &discordgo.Member{Roles: []string{"role-staff"}}, config, true},
+		{"administrator", &discordgo.Member{Permissions: discordgo.PermissionAdministrator}, nil, true},
+		{"other roles", &discordgo.Member{Roles: []string{"role-member"}}, config, false},
+		{"no staff role set", &discordgo.Member{Roles: []string{""}}, &WelcomeConfig{}, false},
+		{"not in a guild", nil, config, false},
+	}
+	for _, tt := range tests {
+		i := &discordgo.InteractionCreate{Interaction: &discordgo.Interaction{Member: tt.member}}
+		if got := isStaff(i, tt.config); got != tt.want {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestOnboardingSummaryEmbed(t *testing.T) {
+	f := &Feature{i18n: fakes.I18n{}}
+	summary := &onboardingSummary{
+		Guide:      "kk",
+		Selections: onboard.Selections{"age": {"30late"}, "event": {"bunnyclub", "user"}},
+	}
+
+	embed := f.onboardingSummaryEmbed(context.Background(), "guild-1", "user-1", summary)
+
+	values := make(map[string]string)
+	for _, field := range embed.Fields {
+		values[field.Name] = field.Value
+	}
+	for name, want := range map[string]string{
+		"welcome.timeline_guide":           "kk",
+		"welcome.summary_selection_age":    "30late",
+		"welcome.summary_selection_event":  "bunnyclub, user",
+		"welcome.summary_selection_gender": "-",
+	} {
+		if values[name] != want {
+			t.Errorf("%s = %q, want %q", name, values[name], want)
+		}
+	}
+	if len(embed.Fields) != 1+len(onboard.SelectionNames()) {
+		t.Errorf("expected the guide and every selection, got %d fields", len(embed.Fields))
+	}
+}
@@ -0,0 +1,143 @@
+package welcome
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"welcomebot/internal/core/discord"
+	"welcomebot/internal/shared"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/lib/pq"
+)
+
+// maxOverflowCategories caps the categories tried after the VC category.
+const maxOverflowCategories = 5
+
+// onboardingCategories returns every category onboarding VCs are created
+// in: the VC category, its spread categories, then its overflow categories.
+func onboardingCategories(config *WelcomeConfig) []string {
+	categories := append([]string{config.VCCategoryID}, config.SpreadCategoryIDs...)
+	return append(categories, config.OverflowCategoryIDs...)
+}
+
+// categorySelection cleans up categories picked in a select menu: empty
+// IDs, repeats and the categories in exclude are dropped, and at most max
+// are kept.
+func categorySelection(categoryIDs, exclude []string, max int) []string {
+	var selected []string
+	for _, id := range categoryIDs {
+		if id == "" || slices.Contains(exclude, id) || slices.Contains(selected, id) {
+			continue
+		}
+		selected = append(selected, id)
+	}
+	if len(selected) > max {
+		selected = selected[:max]
+	}
+	return selected
+}
+
+// categoriesFull reports whether state shows every onboarding category at
+// Discord's channel limit.
+func categoriesFull(s *discordgo.Session, guildID string, config *WelcomeConfig) bool {
+	for _, categoryID := range onboardingCategories(config) {
+		if !discord.CategoryFull(s, guildID, categoryID) {
+			return false
+		}
+	}
+	return true
+}
+
+// saveOverflowCategories stores the guild's overflow categories. The VC
+// category, spread categories and duplicates are dropped.
+func (f *Feature) saveOverflowCategories(ctx context.Context, config *WelcomeConfig, categoryIDs []string) error {
+	exclude := append([]string{config.VCCategoryID}, config.SpreadCategoryIDs...)
+	overflow := categorySelection(categoryIDs, exclude, maxOverflowCategories)
+
+	_, err := f.db.Exec(ctx, `
+		UPDATE guild_welcome_config
+		SET overflow_category_ids = $1, updated_at = NOW()
+		WHERE guild_id = $2
+	`, pq.Array(overflow), config.GuildID)
+	if err != nil {
+		return fmt.Errorf("save overflow categories: %w", err)
+	}
+	config.OverflowCategoryIDs = overflow
+
+	if err := f.cache.Delete(ctx, cacheKeyPrefix+config.GuildID); err != nil {
+		f.logger.Warn("failed to invalidate welcome config", "error", err, "guild_id", config.GuildID)
+	}
+	return nil
+}
+
+// showOverflowCategories shows the categories used when the VC category
+// is full, with a menu to change them.
+func (f *Feature) showOverflowCategories(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	guildID := i.GuildID
+
+	config, err := f.getWelcomeConfig(ctx, guildID)
+	if err != nil {
+		return f.respondErrorMessage(ctx, s, i, guildID, "welcome.config_not_found")
+	}
+
+	return respond(s, i, f.overflowCategoriesEmbed(ctx, config), f.overflowCategoriesComponents(ctx, config))
+}
+
+// handleOverflowCategorySelection saves the selected overflow categories.
+func (f *Feature) handleOverflowCategorySelection(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	guildID := i.GuildID
+
+	config, err := f.getWelcomeConfig(ctx, guildID)
+	if err != nil {
+		return f.respondErrorMessage(ctx, s, i, guildID, "welcome.config_not_found")
+	}
+
+	if err := f.saveOverflowCategories(ctx, config, i.MessageComponentData().Values); err != nil {
+		return f.respondError(ctx, s, i, guildID, err)
+	}
+
+	f.logger.Info("overflow categories updated",
+		"guild_id", guildID,
+		"category_ids", config.OverflowCategoryIDs,
+	)
+
+	return respond(s, i, f.overflowCategoriesEmbed(ctx, config), f.overflowCategoriesComponents(ctx, config))
+}
+
+func (f *Feature) overflowCategoriesEmbed(ctx context.Context, config *WelcomeConfig) *discordgo.MessageEmbed {
+	guildID := config.GuildID
+
+	current := f.i18n.T(ctx, guildID, "welcome.overflow_categories_none")
+	if len(config.OverflowCategoryIDs) > 0 {
+		mentions := make([]string, len(config.OverflowCategoryIDs))
+		for n, id := range config.OverflowCategoryIDs {
+			mentions[n] = fmt.Sprintf("%d. <#%s>", n+1, id)
+		}
+		current = strings.Join(mentions, "\n")
+	}
+
+	return &discordgo.MessageEmbed{
+		Title: f.i18n.T(ctx, guildID, "welcome.overflow_categories_title"),
+		Description: f.i18n.TWithArgs(ctx, guildID, "welcome.overflow_categories_description", map[string]string{
+			"category": fmt.Sprintf("<#%s>", config.VCCategoryID),
+		}) + "\n\n" + current,
+		Color: int(shared.ColorInfo),
+	}
+}
+
+func (f *Feature) overflowCategoriesComponents(ctx context.Context, config *WelcomeConfig) []discordgo.MessageComponent {
+	// Clearing the menu removes every overflow category
+	menu := discord.ChannelSelectMenu("welcome:overflow_categories:select",
+		f.i18n.T(ctx, config.GuildID, "welcome.select_overflow_categories"),
+		[]discordgo.ChannelType{discordgo.ChannelTypeGuildCategory}, config.OverflowCategoryIDs...)
+	minValues := 0
+	menu.MinValues = &minValues
+	menu.MaxValues = maxOverflowCategories
+
+	return []discordgo.MessageComponent{
+		discordgo.ActionsRow{Components: []discordgo.MessageComponent{menu}},
+	}
+}
@@ -0,0 +1,84 @@
+package welcome
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"welcomebot/internal/fakes"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func TestSaveOverflowCategories(t *testing.T) {
+	ctx := context.Background()
+	cache := fakes.NewCache()
+	db := fakes.NewDB()
+	f := &Feature{cache: cache, db: db, logger: fakes.Logger{}}
+
+	config := &WelcomeConfig{GuildID: "guild-1", VCCategoryID: "cat-1"}
+	if err := cache.SetJSON(ctx, cacheKeyPrefix+"guild-1", config, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	// The VC category and repeats are not overflow categories
+	if err := f.saveOverflowCategories(ctx, config, []string{"cat-2", "cat-1", "cat-3", "cat-2"}); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	if got := fmt.Sprint(config.OverflowCategoryIDs); got != "[cat-2 cat-3]" {
+		t.Errorf("overflow categories = %s, want [cat-2 cat-3]", got)
+	}
+	if exists, _ := cache.Exists(ctx, cacheKeyPrefix+"guild-1"); exists {
+		t.Error("expected the cached config to be invalidated")
+	}
+	if execs := db.Execs(); len(execs) != 1 {
+		t.Errorf("expected one update, got %+v", execs)
+	}
+}
+
+func TestSaveSpreadCategories(t *testing.T) {
+	ctx := context.Background()
+	db := fakes.NewDB()
+	f := &Feature{cache: fakes.NewCache(), db: db, logger: fakes.Logger{}}
+
+	config := &WelcomeConfig{GuildID: "guild-1", VCCategoryID: "cat-1", OverflowCategoryIDs: []string{"cat-4"}}
+
+	// A category is either spread or overflow, never both
+	if err := f.saveSpreadCategories(ctx, config, []string{"cat-2", "cat-4", "cat-1", "cat-3"}); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	if got := fmt.Sprint(config.SpreadCategoryIDs); got != "[cat-2 cat-3]" {
+		t.Errorf("spread categories = %s, want [cat-2 cat-3]", got)
+	}
+	if got := fmt.Sprint(onboardingCategories(config)); got != "[cat-1 cat-2 cat-3 cat-4]" {
+		t.Errorf("onboarding categories = %s, want [cat-1 cat-2 cat-3 cat-4]", got)
+	}
+
+	if err := f.saveOverflowCategories(ctx, config, []string{"cat-3", "cat-5"}); err != nil {
+		t.Fatalf("save overflow: %v", err)
+	}
+	if got := fmt.Sprint(config.OverflowCategoryIDs); got != "[cat-5]" {
+		t.Errorf("overflow categories = %s, want [cat-5]", got)
+	}
+}
+
+func TestCategoriesFull(t *testing.T) {
+	s, _ := discordgo.New("")
+	guild := &discordgo.Guild{ID: "guild-1"}
+	for n := 0; n < 50; n++ {
+		guild.Channels = append(guild.Channels, &discordgo.Channel{ID: fmt.Sprintf("vc-%d", n), ParentID: "cat-1"})
+	}
+	if err := s.State.GuildAdd(guild); err != nil {
+		t.Fatal(err)
+	}
+
+	config := &WelcomeConfig{GuildID: "guild-1", VCCategoryID: "cat-1"}
+	if !categoriesFull(s, "guild-1", config) {
+		t.Error("expected a full VC category without overflow to be full")
+	}
+
+	config.OverflowCategoryIDs = []string{"cat-2"}
+	if categoriesFull(s, "guild-1", config) {
+		t.Error("expected an overflow category with room to take the session")
+	}
+}
@@ -0,0 +1,425 @@
+package welcome
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"welcomebot/internal/core/discord"
+	"welcomebot/internal/shared"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/lib/pq"
+)
+
+const (
+	// remapKeyPrefix holds the replacements an admin has picked so far,
+	// old role ID -> new role ID.
+	remapKeyPrefix = "welcomebot:remap_roles:"
+	remapTTL       = 30 * time.Minute
+
+	// remapPageSize leaves the last action row for the page and apply buttons.
+	remapPageSize = 4
+)
+
+// roleTable lists the columns of a config table that hold role IDs.
+type roleTable struct {
+	table        string
+	columns      []string
+	arrayColumns []string // TEXT[] columns holding a list of role IDs
+}
+
+// roleTables is every config column that stores a role ID. Remapping
+// updates all of them.
+var roleTables = []roleTable{
+	{"guild_welcome_config", []string{
+		"in_progress_role_id", "completed_role_id", "entrance_role_id", "nyukai_role_id",
+		"setsumeikai_1_role_id", "setsumeikai_2_role_id", "setsumeikai_3_role_id",
+		"member_role_id", "visitor_role_id", "staff_role_id", "initial_role_id",
+	}, []string{"completion_grant_role_ids", "completion_revoke_role_ids"}},
+	{"guild_age_range_config", []string{
+		"age_20_early_role_id", "age_20_late_role_id",
+		"age_30_early_role_id", "age_30_late_role_id",
+		"age_40_early_role_id", "age_40_late_role_id",
+	}, nil},
+	{"guild_gender_roles", []string{"male_role_id", "female_role_id"}, nil},
+	{"guild_voice_type_config", []string{
+		"high_role_id", "mid_high_role_id", "mid_role_id", "mid_low_role_id", "low_role_id",
+	}, nil},
+	{"guild_other_roles_config", []string{
+		"ero_ok_role_id", "ero_ng_role_id",
+		"neochi_ok_role_id", "neochi_ng_role_id", "neochi_disconnect_role_id",
+		"dm_ok_role_id", "dm_ng_role_id", "friend_ok_role_id", "friend_ng_role_id",
+		"bunnyclub_event_role_id", "user_event_role_id",
+	}, nil},
+	{"guild_guide_completion_roles", []string{"member_role_id", "visitor_role_id"}, nil},
+}
+
+// roleSlot is one configured role.
+type roleSlot struct {
+	Name   string // Shown to admins, e.g. "welcome: member"
+	RoleID string
+}
+
+// staleRole is a configured role ID that no longer exists in the guild,
+// with every slot that uses it.
+type staleRole struct {
+	RoleID string
+	Slots  []string
+}
+
+// configuredRoleSlots returns every role set in the guild's configs. A
+// config that was never saved has no slots.
+func (f *Feature) configuredRoleSlots(ctx context.Context, guildID string) ([]roleSlot, error) {
+	var slots []roleSlot
+	add := func(name string, pairs ...string) {
+		for i := 0; i+1 < len(pairs); i += 2 {
+			if pairs[i+1] != "" {
+				slots = append(slots, roleSlot{Name: name + ": " + strings.TrimSuffix(pairs[i], "_role_id"), RoleID: pairs[i+1]})
+			}
+		}
+	}
+	skip := func(err error) bool { return errors.Is(err, sql.ErrNoRows) }
+
+	welcome, err := f.getWelcomeConfig(ctx, guildID)
+	if err != nil && !skip(err) {
+		return nil, fmt.Errorf("get welcome config: %w", err)
+	}
+	if err == nil {
+		add("welcome",
+			"in_progress_role_id", welcome.InProgressRoleID, "completed_role_id", welcome.CompletedRoleID,
+			"entrance_role_id", welcome.EntranceRoleID, "nyukai_role_id", welcome.NyukaiRoleID,
+			"setsumeikai_1_role_id", welcome.Setsumeikai1RoleID, "setsumeikai_2_role_id", welcome.Setsumeikai2RoleID,
+			"setsumeikai_3_role_id", welcome.Setsumeikai3RoleID, "member_role_id", welcome.MemberRoleID,
+			"visitor_role_id", welcome.VisitorRoleID, "staff_role_id", welcome.StaffRoleID,
+			"initial_role_id", welcome.InitialRoleID)
+		for _, roleID := range welcome.CompletionGrantRoleIDs {
+			add("welcome", "completion_grant_role_id", roleID)
+		}
+		for _, roleID := range welcome.CompletionRevokeRoleIDs {
+			add("welcome", "completion_revoke_role_id", roleID)
+		}
+	}
+
+	ageRange, err := f.getAgeRangeConfig(ctx, guildID)
+	if err != nil && !skip(err) {
+		return nil, fmt.Errorf("get agerange config: %w", err)
+	}
+	if err == nil {
+		add("agerange",
+			"age_20_early_role_id", ageRange.Age20EarlyRoleID, "age_20_late_role_id", ageRange.Age20LateRoleID,
+			"age_30_early_role_id", ageRange.Age30EarlyRoleID, "age_30_late_role_id", ageRange.Age30LateRoleID,
+			"age_40_early_role_id", ageRange.Age40EarlyRoleID, "age_40_late_role_id", ageRange.Age40LateRoleID)
+	}
+
+	gender, err := f.getGenderConfig(ctx, guildID)
+	if err != nil && !skip(err) {
+		return nil, fmt.Errorf("get gender config: %w", err)
+	}
+	if err == nil {
+		add("gender", "male_role_id", gender.MaleRoleID, "female_role_id", gender.FemaleRoleID)
+	}
+
+	voiceType, err := f.getVoiceTypeConfig(ctx, guildID)
+	if err != nil && !skip(err) {
+		return nil, fmt.Errorf("get voicetype config: %w", err)
+	}
+	if err == nil {
+		add("voicetype",
+			"high_role_id", voiceType.HighRoleID, "mid_high_role_id", voiceType.MidHighRoleID,
+			"mid_role_id", voiceType.MidRoleID, "mid_low_role_id", voiceType.MidLowRoleID,
+			"low_role_id", voiceType.LowRoleID)
+	}
+
+	otherRoles, err := f.getOtherRolesConfig(ctx, guildID)
+	if err != nil && !skip(err) {
+		return nil, fmt.Errorf("get otherroles config: %w", err)
+	}
+	if err == nil {
+		add("otherroles",
+			"ero_ok_role_id", otherRoles.EroOkRoleID, "ero_ng_role_id", otherRoles.EroNgRoleID,
+			"neochi_ok_role_id", otherRoles.NeochiOkRoleID, "neochi_ng_role_id", otherRoles.NeochiNgRoleID,
+			"neochi_disconnect_role_id", otherRoles.NeochiDisconnectRoleID,
+			"dm_ok_role_id", otherRoles.DmOkRoleID, "dm_ng_role_id", otherRoles.DmNgRoleID,
+			"friend_ok_role_id", otherRoles.FriendOkRoleID, "friend_ng_role_id", otherRoles.FriendNgRoleID,
+			"bunnyclub_event_role_id", otherRoles.BunnyclubEventRoleID,
+			"user_event_role_id", otherRoles.UserEventRoleID)
+	}
+
+	guideRoles, err := f.getGuideCompletionRoles(ctx, guildID)
+	if err != nil {
+		return nil, fmt.Errorf("get guide completion roles: %w", err)
+	}
+	guides := make([]string, 0, len(guideRoles))
+	for guide := range guideRoles {
+		guides = append(guides, guide)
+	}
+	sort.Strings(guides)
+	for _, guide := range guides {
+		roles := guideRoles[guide]
+		add(fmt.Sprintf("guide_roles (%s)", guide),
+			"member_role_id", roles.MemberRoleID, "visitor_role_id", roles.VisitorRoleID)
+	}
+
+	return slots, nil
+}
+
+// staleRoles returns the configured roles missing from the guild, in the
+// order their first slot appears.
+func (f *Feature) staleRoles(ctx context.Context, s *discordgo.Session, guildID string) ([]staleRole, error) {
+	slots, err := f.configuredRoleSlots(ctx, guildID)
+	if err != nil {
+		return nil, err
+	}
+
+	roles, err := s.GuildRoles(guildID)
+	if err != nil {
+		return nil, fmt.Errorf("get guild roles: %w", err)
+	}
+	exists := make(map[string]bool, len(roles))
+	for _, role := range roles {
+		exists[role.ID] = true
+	}
+
+	var stale []staleRole
+	index := make(map[string]int)
+	for _, slot := range slots {
+		if exists[slot.RoleID] {
+			continue
+		}
+		i, ok := index[slot.RoleID]
+		if !ok {
+			i = len(stale)
+			index[slot.RoleID] = i
+			stale = append(stale, staleRole{RoleID: slot.RoleID})
+		}
+		stale[i].Slots = append(stale[i].Slots, slot.Name)
+	}
+	return stale, nil
+}
+
+// remapRolesQuery replaces role IDs in every column of roleTables. The
+// tables are updated by one statement, so the remap applies to all of them
+// or none. $1 is the guild, $2 and $3 the old and new IDs.
+func remapRolesQuery() string {
+	var b strings.Builder
+	b.WriteString("WITH remap (old_id, new_id) AS (SELECT * FROM unnest($2::text[], $3::text[]))")
+	for i, t := range roleTables {
+		var sets []string
+		for _, column := range t.columns {
+			sets = append(sets, fmt.Sprintf("%[1]s = COALESCE((SELECT new_id FROM remap WHERE old_id = %[1]s), %[1]s)", column))
+		}
+		// Lists keep their order and stay NULL when unset
+		roleIDs := fmt.Sprintf("ARRAY[%s]::text[]", strings.Join(t.columns, ", "))
+		for _, column := range t.arrayColumns {
+			sets = append(sets, fmt.Sprintf("%[1]s = CASE WHEN %[1]s IS NULL THEN NULL ELSE ARRAY("+
+				"SELECT COALESCE((SELECT new_id FROM remap WHERE old_id = r.id), r.id) "+
+				"FROM unnest(%[1]s) WITH ORDINALITY AS r(id, n) ORDER BY r.n) END", column))
+			roleIDs += fmt.Sprintf(" || COALESCE(%s, '{}')", column)
+		}
+		fmt.Fprintf(&b, ",\n\tu%d AS (UPDATE %s SET %s, updated_at = NOW() WHERE guild_id = $1 AND (%s) && $2::text[] RETURNING 1)",
+			i, t.table, strings.Join(sets, ", "), roleIDs)
+	}
+	b.WriteString("\nSELECT 1")
+	return b.String()
+}
+
+// remapRoles replaces each old role ID with its new one in every config,
+// then drops the cached configs.
+func (f *Feature) remapRoles(ctx context.Context, guildID string, remap map[string]string) error {
+	oldIDs := make([]string, 0, len(remap))
+	for oldID := range remap {
+		oldIDs = append(oldIDs, oldID)
+	}
+	sort.Strings(oldIDs)
+	newIDs := make([]string, len(oldIDs))
+	for i, oldID := range oldIDs {
+		newIDs[i] = remap[oldID]
+	}
+
+	if _, err := f.db.Exec(ctx, remapRolesQuery(), guildID, pq.Array(oldIDs), pq.Array(newIDs)); err != nil {
+		return fmt.Errorf("remap roles: %w", err)
+	}
+
+	for _, prefix := range []string{
+		cacheKeyPrefix, ageRangeCacheKeyPrefix, genderCacheKeyPrefix,
+		voiceTypeCacheKeyPrefix, otherRolesCacheKeyPrefix, guideRolesKeyPrefix,
+	} {
+		if err := f.cache.Delete(ctx, prefix+guildID); err != nil {
+			f.logger.Warn("failed to invalidate config", "error", err, "guild_id", guildID, "key", prefix+guildID)
+		}
+	}
+	return nil
+}
+
+// getRemapSelections returns the replacements picked so far.
+func (f *Feature) getRemapSelections(ctx context.Context, guildID string) map[string]string {
+	remap := make(map[string]string)
+	if err := f.cache.GetJSON(ctx, remapKeyPrefix+guildID, &remap); err != nil {
+		return make(map[string]string)
+	}
+	return remap
+}
+
+// showRemapRoles lists the stale roles, each with a menu to pick its
+// replacement.
+func (f *Feature) showRemapRoles(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	page := 0
+	if n, err := strconv.Atoi(strings.TrimPrefix(extractCustomID(i), "welcome:remap_roles:page:")); err == nil {
+		page = n
+	}
+	return f.renderRemapRoles(ctx, s, i, page)
+}
+
+// renderRemapRoles shows one page of stale roles.
+func (f *Feature) renderRemapRoles(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate, page int) error {
+	guildID := i.GuildID
+
+	stale, err := f.staleRoles(ctx, s, guildID)
+	if err != nil {
+		return f.respondError(ctx, s, i, guildID, err)
+	}
+	if len(stale) == 0 {
+		if err := f.cache.Delete(ctx, remapKeyPrefix+guildID); err != nil {
+			f.logger.Debug("failed to clear role remap", "error", err, "guild_id", guildID)
+		}
+	}
+
+	remap := f.getRemapSelections(ctx, guildID)
+	embed, components := f.remapRolesView(ctx, guildID, stale, remap, page)
+	return respond(s, i, embed, components)
+}
+
+// handleRemapSelection records the replacement picked for one stale role.
+// The custom ID is welcome:remap_roles:pick:<page>:<old role ID>.
+func (f *Feature) handleRemapSelection(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate, customID string) error {
+	guildID := i.GuildID
+
+	pageID, oldID, ok := strings.Cut(strings.TrimPrefix(customID, "welcome:remap_roles:pick:"), ":")
+	if !ok {
+		return fmt.Errorf("invalid remap custom ID %q", customID)
+	}
+	page, _ := strconv.Atoi(pageID)
+
+	remap := f.getRemapSelections(ctx, guildID)
+	if values := i.MessageComponentData().Values; len(values) > 0 {
+		remap[oldID] = values[0]
+	} else {
+		delete(remap, oldID)
+	}
+	if err := f.cache.SetJSON(ctx, remapKeyPrefix+guildID, remap, remapTTL); err != nil {
+		return f.respondError(ctx, s, i, guildID, err)
+	}
+
+	return f.renderRemapRoles(ctx, s, i, page)
+}
+
+// applyRemapRoles writes the picked replacements to every config.
+func (f *Feature) applyRemapRoles(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	guildID := i.GuildID
+
+	remap := f.getRemapSelections(ctx, guildID)
+	if len(remap) == 0 {
+		return f.renderRemapRoles(ctx, s, i, 0)
+	}
+
+	if err := f.remapRoles(ctx, guildID, remap); err != nil {
+		return f.respondError(ctx, s, i, guildID, err)
+	}
+	if err := f.cache.Delete(ctx, remapKeyPrefix+guildID); err != nil {
+		f.logger.Debug("failed to clear role remap", "error", err, "guild_id", guildID)
+	}
+
+	f.logger.Info("roles remapped", "guild_id", guildID, "roles", len(remap))
+
+	lines := make([]string, 0, len(remap))
+	for oldID, newID := range remap {
+		lines = append(lines, fmt.Sprintf("`%s` → <@&%s>", oldID, newID))
+	}
+	sort.Strings(lines)
+
+	embed := &discordgo.MessageEmbed{
+		Title:       f.i18n.T(ctx, guildID, "welcome.remap_roles_title"),
+		Description: f.i18n.T(ctx, guildID, "welcome.remap_roles_done") + "\n\n" + strings.Join(lines, "\n"),
+		Color:       int(shared.ColorSuccess),
+	}
+	return respond(s, i, embed, []discordgo.MessageComponent{})
+}
+
+func (f *Feature) remapRolesView(ctx context.Context, guildID string, stale []staleRole, remap map[string]string, page int) (*discordgo.MessageEmbed, []discordgo.MessageComponent) {
+	embed := &discordgo.MessageEmbed{
+		Title: f.i18n.T(ctx, guildID, "welcome.remap_roles_title"),
+		Color: int(shared.ColorInfo),
+	}
+	if len(stale) == 0 {
+		embed.Description = f.i18n.T(ctx, guildID, "welcome.remap_roles_none")
+		embed.Color = int(shared.ColorSuccess)
+		return embed, []discordgo.MessageComponent{}
+	}
+
+	pages := (len(stale) + remapPageSize - 1) / remapPageSize
+	page = max(0, min(page, pages-1))
+	start := page * remapPageSize
+	end := min(start+remapPageSize, len(stale))
+
+	var lines []string
+	var components []discordgo.MessageComponent
+	for _, role := range stale[start:end] {
+		replacement := f.i18n.T(ctx, guildID, "welcome.remap_roles_unset")
+		if newID := remap[role.RoleID]; newID != "" {
+			replacement = fmt.Sprintf("<@&%s>", newID)
+		}
+		lines = append(lines, f.i18n.TWithArgs(ctx, guildID, "welcome.remap_roles_entry", map[string]string{
+			"role":        "`" + role.RoleID + "`",
+			"replacement": replacement,
+			"slots":       strings.Join(role.Slots, ", "),
+		}))
+
+		menu := discord.RoleSelectMenu(fmt.Sprintf("welcome:remap_roles:pick:%d:%s", page, role.RoleID),
+			f.i18n.TWithArgs(ctx, guildID, "welcome.select_remap_role", map[string]string{"role": role.RoleID}),
+			remap[role.RoleID])
+		minValues := 0
+		menu.MinValues = &minValues
+		menu.MaxValues = 1
+		components = append(components, discordgo.ActionsRow{Components: []discordgo.MessageComponent{menu}})
+	}
+
+	embed.Description = f.i18n.T(ctx, guildID, "welcome.remap_roles_description") + "\n\n" + strings.Join(lines, "\n")
+	if pages > 1 {
+		embed.Footer = &discordgo.MessageEmbedFooter{
+			Text: f.i18n.TWithArgs(ctx, guildID, "welcome.active_sessions_page", map[string]string{
+				"page":  strconv.Itoa(page + 1),
+				"pages": strconv.Itoa(pages),
+			}),
+		}
+	}
+
+	buttons := []discordgo.MessageComponent{
+		discordgo.Button{
+			Label:    f.i18n.T(ctx, guildID, "welcome.active_sessions_previous"),
+			Style:    discordgo.SecondaryButton,
+			CustomID: fmt.Sprintf("welcome:remap_roles:page:%d", page-1),
+			Disabled: page == 0,
+		},
+		discordgo.Button{
+			Label:    f.i18n.T(ctx, guildID, "welcome.active_sessions_next"),
+			Style:    discordgo.SecondaryButton,
+			CustomID: fmt.Sprintf("welcome:remap_roles:page:%d", page+1),
+			Disabled: page >= pages-1,
+		},
+		discordgo.Button{
+			Label:    f.i18n.T(ctx, guildID, "welcome.remap_roles_apply"),
+			Style:    discordgo.SuccessButton,
+			CustomID: "welcome:remap_roles:apply",
+			Disabled: len(remap) == 0,
+		},
+	}
+	components = append(components, discordgo.ActionsRow{Components: buttons})
+
+	return embed, components
+}
@@ -0,0 +1,92 @@
+package welcome
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+
+	"welcomebot/internal/fakes"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/lib/pq"
+)
+
+func TestStaleRoles(t *testing.T) {
+	ctx := context.Background()
+	cache := fakes.NewCache()
+	f := &Feature{cache: cache, db: fakes.NewDB(), logger: fakes.Logger{}}
+
+	configs := map[string]interface{}{
+		cacheKeyPrefix + "guild-1": &WelcomeConfig{GuildID: "guild-1", MemberRoleID: "old-member", StaffRoleID: "role-staff",
+			CompletionGrantRoleIDs: []string{"role-staff", "old-grant"}},
+		ageRangeCacheKeyPrefix + "guild-1": &AgeRangeConfig{GuildID: "guild-1", Age30LateRoleID: "old-30late"},
+		guideRolesKeyPrefix + "guild-1":    map[string]GuideCompletionRoles{"kk": {MemberRoleID: "old-member"}},
+	}
+	for key, config := range configs {
+		if err := cache.SetJSON(ctx, key, config, 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, key := range []string{genderCacheKeyPrefix, voiceTypeCacheKeyPrefix, otherRolesCacheKeyPrefix} {
+		if err := cache.SetJSON(ctx, key+"guild-1", struct{}{}, 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	d := fakes.NewDiscord()
+	d.SetGuildRoles("guild-1", &discordgo.Role{ID: "role-staff"})
+
+	stale, err := f.staleRoles(ctx, d.Session(), "guild-1")
+	if err != nil {
+		t.Fatalf("staleRoles: %v", err)
+	}
+	if got := fmt.Sprint(stale); got != "[{old-member [welcome: member guide_roles (kk): member]} {old-grant [welcome: completion_grant]} {old-30late [agerange: age_30_late]}]" {
+		t.Errorf("unexpected stale roles %s", got)
+	}
+}
+
+func TestRemapRoles(t *testing.T) {
+	ctx := context.Background()
+	cache := fakes.NewCache()
+	db := fakes.NewDB()
+	f := &Feature{cache: cache, db: db, logger: fakes.Logger{}}
+
+	for _, key := range []string{cacheKeyPrefix, ageRangeCacheKeyPrefix, guideRolesKeyPrefix} {
+		if err := cache.Set(ctx, key+"guild-1", "{}", 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	remap := map[string]string{"old-b": "new-b", "old-a": "new-a"}
+	if err := f.remapRoles(ctx, "guild-1", remap); err != nil {
+		t.Fatalf("remapRoles: %v", err)
+	}
+
+	// Every table changes in the one statement
+	execs := db.Execs()
+	if len(execs) != 1 {
+		t.Fatalf("expected a single statement, got %d", len(execs))
+	}
+	for _, table := range roleTables {
+		if !strings.Contains(execs[0].Query, "UPDATE "+table.table+" ") {
+			t.Errorf("expected %s to be updated", table.table)
+		}
+		for _, column := range table.arrayColumns {
+			if !strings.Contains(execs[0].Query, column+" = CASE") {
+				t.Errorf("expected %s.%s to be remapped", table.table, column)
+			}
+		}
+	}
+	oldIDs, newIDs := *execs[0].Args[1].(*pq.StringArray), *execs[0].Args[2].(*pq.StringArray)
+	if !slices.Equal([]string(oldIDs), []string{"old-a", "old-b"}) || !slices.Equal([]string(newIDs), []string{"new-a", "new-b"}) {
+		t.Errorf("unexpected remap args %v -> %v", execs[0].Args[1], execs[0].Args[2])
+	}
+
+	for _, key := range []string{cacheKeyPrefix, ageRangeCacheKeyPrefix, guideRolesKeyPrefix} {
+		if exists, _ := cache.Exists(ctx, key+"guild-1"); exists {
+			t.Errorf("expected %s to be invalidated", key)
+		}
+	}
+}
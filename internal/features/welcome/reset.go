@@ -0,0 +1,84 @@
+package welcome
+
+import (
+	"context"
+	"fmt"
+
+	"welcomebot/internal/shared"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// showResetConfirmation asks the admin to confirm deleting the configuration.
+func (f *Feature) showResetConfirmation(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	guildID := i.GuildID
+
+	embed := &discordgo.MessageEmbed{
+		Title:       f.i18n.T(ctx, guildID, "welcome.reset_title"),
+		Description: f.i18n.T(ctx, guildID, "welcome.reset_description"),
+		Color:       int(shared.ColorWarning),
+	}
+
+	components := []discordgo.MessageComponent{
+		discordgo.ActionsRow{
+			Components: []discordgo.MessageComponent{
+				discordgo.Button{
+					Label:    f.i18n.T(ctx, guildID, "common.confirm_reset"),
+					Style:    discordgo.DangerButton,
+					CustomID: "welcome:reset:confirm",
+				},
+				discordgo.Button{
+					Label:    f.i18n.T(ctx, guildID, "common.cancel"),
+					Style:    discordgo.SecondaryButton,
+					CustomID: "welcome:cancel",
+				},
+			},
+		},
+	}
+
+	return respond(s, i, embed, components)
+}
+
+// resetConfig deletes the guild's welcome configuration. The posted
+// welcome button stays but reports that onboarding is not configured.
+func (f *Feature) resetConfig(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	guildID := i.GuildID
+
+	if err := f.deleteWelcomeConfig(ctx, guildID); err != nil {
+		f.logger.Error("failed to reset welcome config", "guild_id", guildID, "error", err)
+
+		embed := &discordgo.MessageEmbed{
+			Title:       f.i18n.T(ctx, guildID, "common.error"),
+			Description: f.i18n.T(ctx, guildID, "welcome.error_reset"),
+			Color:       int(shared.ColorError),
+		}
+		return respond(s, i, embed, []discordgo.MessageComponent{})
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Title:       f.i18n.T(ctx, guildID, "common.success"),
+		Description: f.i18n.T(ctx, guildID, "welcome.reset_done"),
+		Color:       int(shared.ColorSuccess),
+	}
+
+	return respond(s, i, embed, []discordgo.MessageComponent{})
+}
+
+// deleteWelcomeConfig removes the configuration row and its cache entry.
+func (f *Feature) deleteWelcomeConfig(ctx context.Context, guildID string) error {
+	query := `DELETE FROM guild_welcome_config WHERE guild_id = $1`
+	if _, err := f.db.Exec(ctx, query, guildID); err != nil {
+		return fmt.Errorf("delete from database: %w", err)
+	}
+
+	// Onboarding reads the cached copy first, so it must go too
+	if err := f.cache.Delete(ctx, cacheKeyPrefix+guildID); err != nil {
+		return fmt.Errorf("delete from cache: %w", err)
+	}
+	if err := f.deleteWizardState(ctx, guildID); err != nil {
+		f.logger.Warn("failed to delete wizard state", "error", err)
+	}
+
+	f.logger.Info("welcome config reset", "guild_id", guildID)
+	return nil
+}
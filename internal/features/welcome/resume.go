@@ -0,0 +1,141 @@
+package welcome
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"welcomebot/internal/onboard"
+	"welcomebot/internal/shared"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// Custom IDs of the resume prompt's buttons.
+const (
+	resumeOnboardingID  = "welcome:start_onboarding:resume"
+	restartOnboardingID = "welcome:start_onboarding:restart"
+)
+
+// getCheckpoint returns the member's checkpoint from an unfinished
+// onboarding, or nil when resume is off, there is none, or it is older
+// than resumeExpiry. Expired checkpoints are deleted as they are found.
+// Resuming is a convenience, so read errors start the member from step 1.
+func (f *Feature) getCheckpoint(ctx context.Context, guildID, userID string) *onboard.Checkpoint {
+	if f.resumeExpiry <= 0 {
+		return nil
+	}
+
+	query := `
+		SELECT guide, step, selections, updated_at
+		FROM member_onboarding_progress
+		WHERE guild_id = $1 AND user_id = $2
+	`
+	var checkpoint onboard.Checkpoint
+	var selections []byte
+	var updatedAt time.Time
+	err := f.db.QueryRow(ctx, query, guildID, userID).Scan(&checkpoint.Guide, &checkpoint.Step, &selections, &updatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		f.logger.Warn("failed to load onboarding checkpoint", "error", err, "guild_id", guildID, "user_id", userID)
+		return nil
+	}
+
+	if time.Since(updatedAt) > f.resumeExpiry {
+		f.deleteCheckpoint(ctx, guildID, userID)
+		return nil
+	}
+	if err := json.Unmarshal(selections, &checkpoint.Selections); err != nil {
+		f.logger.Warn("failed to decode checkpoint selections", "error", err, "guild_id", guildID, "user_id", userID)
+		return nil
+	}
+	if err := checkpoint.Validate(); err != nil {
+		f.logger.Warn("ignoring invalid onboarding checkpoint", "error", err, "guild_id", guildID, "user_id", userID)
+		return nil
+	}
+	return &checkpoint
+}
+
+// deleteCheckpoint forgets the member's progress, as when they choose to
+// start over.
+func (f *Feature) deleteCheckpoint(ctx context.Context, guildID, userID string) {
+	query := `DELETE FROM member_onboarding_progress WHERE guild_id = $1 AND user_id = $2`
+	if _, err := f.db.Exec(ctx, query, guildID, userID); err != nil {
+		f.logger.Warn("failed to delete onboarding checkpoint", "error", err, "guild_id", guildID, "user_id", userID)
+	}
+}
+
+// showResumePrompt asks a member with a checkpoint whether to resume or
+// start over. It replies with a new ephemeral message, leaving the welcome
+// button message as it is.
+func (f *Feature) showResumePrompt(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate, checkpoint *onboard.Checkpoint) error {
+	embed, components := f.resumePrompt(ctx, i.GuildID, checkpoint)
+
+	return s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Embeds:     []*discordgo.MessageEmbed{embed},
+			Components: components,
+			Flags:      discordgo.MessageFlagsEphemeral,
+		},
+	})
+}
+
+// resumePrompt builds the resume prompt for a checkpoint.
+func (f *Feature) resumePrompt(ctx context.Context, guildID string, checkpoint *onboard.Checkpoint) (*discordgo.MessageEmbed, []discordgo.MessageComponent) {
+	embed := &discordgo.MessageEmbed{
+		Title: f.i18n.T(ctx, guildID, "welcome.resume_title"),
+		Description: f.i18n.TWithArgs(ctx, guildID, "welcome.resume_description", map[string]string{
+			"completed": fmt.Sprint(checkpoint.Step),
+			"next":      fmt.Sprint(checkpoint.Step + 1),
+		}),
+		Color: int(shared.ColorInfo),
+	}
+
+	components := []discordgo.MessageComponent{
+		discordgo.ActionsRow{
+			Components: []discordgo.MessageComponent{
+				discordgo.Button{
+					Label:    f.i18n.T(ctx, guildID, "welcome.resume_button"),
+					Style:    discordgo.PrimaryButton,
+					CustomID: resumeOnboardingID,
+				},
+				discordgo.Button{
+					Label:    f.i18n.T(ctx, guildID, "welcome.start_over_button"),
+					Style:    discordgo.SecondaryButton,
+					CustomID: restartOnboardingID,
+				},
+			},
+		},
+	}
+	return embed, components
+}
+
+// handleResumeChoice starts onboarding from the member's checkpoint, or
+// from step 1 after deleting it. A checkpoint that expired while the
+// prompt was open starts from step 1 as well.
+func (f *Feature) handleResumeChoice(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate, customID string) error {
+	guildID := i.GuildID
+	userID := i.Member.User.ID
+
+	if customID == restartOnboardingID {
+		f.deleteCheckpoint(ctx, guildID, userID)
+		return f.startOnboarding(ctx, s, i, userID, nil)
+	}
+	return f.startOnboarding(ctx, s, i, userID, f.getCheckpoint(ctx, guildID, userID))
+}
+
+// RestartOnboarding starts onboarding again for a member whose session a
+// worker lost, answering the restart button the worker offered. The start
+// goes through the same locks, cooldown and limits as the welcome button,
+// so s and i may belong to a worker's bot; it picks up from the member's
+// checkpoint when there is one.
+func (f *Feature) RestartOnboarding(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	userID := i.Member.User.ID
+	return f.startOnboarding(ctx, s, i, userID, f.getCheckpoint(ctx, i.GuildID, userID))
+}
@@ -0,0 +1,47 @@
+package welcome
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"welcomebot/internal/fakes"
+	"welcomebot/internal/onboard"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func TestGetCheckpointDisabled(t *testing.T) {
+	// The fake DB panics on QueryRow, so a lookup would fail the test
+	f := &Feature{db: fakes.NewDB(), logger: fakes.Logger{}}
+	if checkpoint := f.getCheckpoint(context.Background(), "guild-1", "user-1"); checkpoint != nil {
+		t.Errorf("expected no checkpoint with resume off, got %+v", checkpoint)
+	}
+}
+
+func TestDeleteCheckpoint(t *testing.T) {
+	db := fakes.NewDB()
+	f := &Feature{db: db, logger: fakes.Logger{}}
+	f.deleteCheckpoint(context.Background(), "guild-1", "user-1")
+
+	execs := db.Execs()
+	if len(execs) != 1 || !strings.Contains(execs[0].Query, "DELETE FROM member_onboarding_progress") {
+		t.Fatalf("unexpected statements %+v", execs)
+	}
+	if execs[0].Args[0] != "guild-1" || execs[0].Args[1] != "user-1" {
+		t.Errorf("unexpected args %v", execs[0].Args)
+	}
+}
+
+func TestResumePrompt(t *testing.T) {
+	f := &Feature{i18n: fakes.I18n{}}
+	_, components := f.resumePrompt(context.Background(), "guild-1", &onboard.Checkpoint{Guide: "kk", Step: 2})
+
+	buttons := components[0].(discordgo.ActionsRow).Components
+	if len(buttons) != 2 {
+		t.Fatalf("expected resume and start over buttons, got %d", len(buttons))
+	}
+	if buttons[0].(discordgo.Button).CustomID != resumeOnboardingID || buttons[1].(discordgo.Button).CustomID != restartOnboardingID {
+		t.Errorf("unexpected buttons %+v", buttons)
+	}
+}
@@ -0,0 +1,330 @@
+package welcome
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"welcomebot/internal/core/discord"
+	"welcomebot/internal/onboard"
+	"welcomebot/internal/shared"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// Custom IDs of the role panel. The panel's menus are
+// rolePanelTogglePrefix followed by the group, e.g.
+// "welcome:role_panel:toggle:age".
+const (
+	rolePanelChannelSelectID = "welcome:role_panel:channel"
+	rolePanelTogglePrefix    = "welcome:role_panel:toggle:"
+)
+
+// rolePanelGroups lays out the panel: one menu per group, each offering
+// the choices of its step 3 selections. Discord allows five menus a
+// message, so the yes/no preferences share one.
+var rolePanelGroups = []struct {
+	key        string
+	selections []string
+}{
+	{"gender", []string{"gender"}},
+	{"age", []string{"age"}},
+	{"voice", []string{"voice"}},
+	{"preferences", []string{"eroipu", "neochi", "neochi_handling", "dm", "friend"}},
+	{"event", []string{"event"}},
+}
+
+// errNoRolePanelRoles is returned when posting a role panel for a guild
+// that has configured none of the step 3 roles.
+var errNoRolePanelRoles = errors.New("no step 3 roles configured")
+
+// rolePanelChanges returns the roles toggling one step 3 choice adds and
+// removes. The choice's role comes off if the member has it and goes on if
+// not, taking the member's other roles of a single-choice selection with
+// it, so picking 30代後半 replaces 30代前半. A choice without a role
+// changes nothing.
+func rolePanelChanges(onboarding onboard.OnboardingConfig, memberRoles []string, selection, value string) (add, remove []string) {
+	roleID := onboarding.ChoiceRole(selection, value)
+	if roleID == "" {
+		return nil, nil
+	}
+	if slices.Contains(memberRoles, roleID) {
+		return nil, []string{roleID}
+	}
+
+	add = []string{roleID}
+	if onboard.MultiChoice(selection) {
+		return add, nil
+	}
+	for _, other := range onboard.SelectionValues(selection) {
+		otherID := onboarding.ChoiceRole(selection, other)
+		if otherID != "" && otherID != roleID && slices.Contains(memberRoles, otherID) && !slices.Contains(remove, otherID) {
+			remove = append(remove, otherID)
+		}
+	}
+	return add, remove
+}
+
+// handleRolePanelToggle toggles the role of the choice a member picked on
+// the role panel and tells them what changed. The roles are looked up when
+// the member picks, so a panel keeps working after the roles are
+// reconfigured. Only members who completed onboarding may use the panel.
+func (f *Feature) handleRolePanelToggle(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	guildID := i.GuildID
+	userID := i.Member.User.ID
+
+	values := i.MessageComponentData().Values
+	if len(values) == 0 {
+		return nil
+	}
+	selection, value, _ := strings.Cut(values[0], ":")
+
+	cached := f.fetchStartConfigs(ctx, guildID)
+	config := cached.welcome
+	if config == nil {
+		var err error
+		if config, err = f.getWelcomeConfig(ctx, guildID); err != nil {
+			return f.respondErrorMessage(ctx, s, i, guildID, "welcome.config_not_found")
+		}
+	}
+
+	if config.CompletedRoleID != "" && !slices.Contains(i.Member.Roles, config.CompletedRoleID) {
+		return f.respondErrorMessage(ctx, s, i, guildID, "welcome.role_panel_not_completed")
+	}
+
+	add, remove := rolePanelChanges(f.loadOnboardingConfig(ctx, config, cached), i.Member.Roles, selection, value)
+	if len(add) == 0 && len(remove) == 0 {
+		return f.respondErrorMessage(ctx, s, i, guildID, "welcome.role_panel_unavailable")
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Title: f.i18n.T(ctx, guildID, "welcome.role_panel_title"),
+		Color: int(shared.ColorSuccess),
+	}
+	if failed := f.changeMemberRoles(ctx, s, guildID, userID, add, remove); failed > 0 {
+		embed.Description = f.i18n.T(ctx, guildID, "welcome.role_panel_failed")
+		embed.Color = int(shared.ColorError)
+	} else {
+		var lines []string
+		for _, roleID := range add {
+			lines = append(lines, f.i18n.TWithArgs(ctx, guildID, "welcome.role_panel_added", map[string]string{"role": discord.RoleMention(roleID)}))
+		}
+		for _, roleID := range remove {
+			lines = append(lines, f.i18n.TWithArgs(ctx, guildID, "welcome.role_panel_removed", map[string]string{"role": discord.RoleMention(roleID)}))
+		}
+		embed.Description = strings.Join(lines, "\n")
+	}
+
+	f.logger.Info("role panel toggled",
+		"guild_id", guildID,
+		"user_id", userID,
+		"selection", selection,
+		"value", value,
+		"added", add,
+		"removed", remove,
+	)
+
+	// Redrawing the panel clears the member's pick, so the same role can
+	// be picked again to toggle it back; the result is their own reply
+	if err := s.InteractionRespond(i.Interaction, rolePanelReset(i)); err != nil {
+		return fmt.Errorf("reset role panel: %w", err)
+	}
+	if _, err := s.FollowupMessageCreate(i.Interaction, true, &discordgo.WebhookParams{
+		Embeds: []*discordgo.MessageEmbed{embed},
+		Flags:  discordgo.MessageFlagsEphemeral,
+	}); err != nil {
+		return fmt.Errorf("send role panel result: %w", err)
+	}
+	return nil
+}
+
+// rolePanelReset answers a pick on the role panel by redrawing the panel
+// unchanged. A menu keeps showing what was picked until its message is
+// updated, and picking the shown option again sends nothing.
+func rolePanelReset(i *discordgo.InteractionCreate) *discordgo.InteractionResponse {
+	if i.Message == nil {
+		return &discordgo.InteractionResponse{Type: discordgo.InteractionResponseDeferredMessageUpdate}
+	}
+	return &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseUpdateMessage,
+		Data: &discordgo.InteractionResponseData{
+			Embeds:     i.Message.Embeds,
+			Components: i.Message.Components,
+		},
+	}
+}
+
+// postRolePanel posts the role panel to channelID and records it, then
+// deletes the panel posted before. If the new panel can't be recorded it
+// is deleted again and the old one stays.
+func (f *Feature) postRolePanel(ctx context.Context, s *discordgo.Session, config *WelcomeConfig, channelID string) error {
+	guildID := config.GuildID
+
+	// Menus are labelled with the role names; a missing name falls back
+	// to the choice
+	names := make(map[string]string)
+	if roles, err := s.GuildRoles(guildID); err != nil {
+		f.logger.Warn("failed to get role names for the role panel", "error", err, "guild_id", guildID)
+	} else {
+		for _, role := range roles {
+			names[role.ID] = role.Name
+		}
+	}
+
+	embed, components := f.rolePanelMessage(ctx, guildID, f.loadOnboardingConfig(ctx, config, nil), names)
+	if len(components) == 0 {
+		return errNoRolePanelRoles
+	}
+
+	msg, err := s.ChannelMessageSendComplex(channelID, &discordgo.MessageSend{
+		Embeds:     []*discordgo.MessageEmbed{embed},
+		Components: components,
+	})
+	if err != nil {
+		return fmt.Errorf("send role panel: %w", err)
+	}
+
+	_, err = f.db.Exec(ctx, `
+		UPDATE guild_welcome_config
+		SET role_panel_channel_id = $1, role_panel_message_id = $2, updated_at = NOW()
+		WHERE guild_id = $3
+	`, channelID, msg.ID, guildID)
+	if err != nil {
+		if err := s.ChannelMessageDelete(channelID, msg.ID); err != nil {
+			f.logger.Warn("failed to delete the unsaved role panel", "error", err, "guild_id", guildID, "message_id", msg.ID)
+		}
+		return fmt.Errorf("save role panel: %w", err)
+	}
+
+	if config.RolePanelMessageID != "" {
+		err := s.ChannelMessageDelete(config.RolePanelChannelID, config.RolePanelMessageID)
+		if err != nil && !discord.IsNotFound(err) {
+			f.logger.Warn("failed to delete the previous role panel", "error", err, "guild_id", guildID, "message_id", config.RolePanelMessageID)
+		}
+	}
+	config.RolePanelChannelID = channelID
+	config.RolePanelMessageID = msg.ID
+
+	if err := f.cache.Delete(ctx, cacheKeyPrefix+guildID); err != nil {
+		f.logger.Warn("failed to invalidate welcome config", "error", err, "guild_id", guildID)
+	}
+
+	f.logger.Info("role panel posted", "guild_id", guildID, "channel_id", channelID, "message_id", msg.ID)
+	return nil
+}
+
+// rolePanelMessage builds the role panel, with a menu for each group that
+// has a role configured. Options are "selection:value", e.g. "age:30late".
+func (f *Feature) rolePanelMessage(ctx context.Context, guildID string, onboarding onboard.OnboardingConfig, names map[string]string) (*discordgo.MessageEmbed, []discordgo.MessageComponent) {
+	embed := &discordgo.MessageEmbed{
+		Title:       f.i18n.T(ctx, guildID, "welcome.role_panel_title"),
+		Description: f.i18n.T(ctx, guildID, "welcome.role_panel_description"),
+		Color:       int(shared.ColorInfo),
+	}
+
+	var components []discordgo.MessageComponent
+	for _, group := range rolePanelGroups {
+		var options []discordgo.SelectMenuOption
+		for _, selection := range group.selections {
+			for _, value := range onboard.SelectionValues(selection) {
+				roleID := onboarding.ChoiceRole(selection, value)
+				if roleID == "" {
+					continue
+				}
+				label := names[roleID]
+				if label == "" {
+					label = selection + " " + value
+				}
+				options = append(options, discordgo.SelectMenuOption{
+					Label: label,
+					Value: selection + ":" + value,
+				})
+			}
+		}
+		if len(options) == 0 {
+			continue
+		}
+
+		components = append(components, discordgo.ActionsRow{
+			Components: []discordgo.MessageComponent{
+				discordgo.SelectMenu{
+					MenuType:    discordgo.StringSelectMenu,
+					CustomID:    rolePanelTogglePrefix + group.key,
+					Placeholder: f.i18n.T(ctx, guildID, "welcome.role_panel_pick_"+group.key),
+					Options:     options,
+				},
+			},
+		})
+	}
+	return embed, components
+}
+
+// showRolePanelSettings shows where the role panel is posted, with a menu
+// to post it to a channel.
+func (f *Feature) showRolePanelSettings(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	guildID := i.GuildID
+
+	config, err := f.getWelcomeConfig(ctx, guildID)
+	if err != nil {
+		return f.respondErrorMessage(ctx, s, i, guildID, "welcome.config_not_found")
+	}
+
+	return respond(s, i, f.rolePanelSettingsEmbed(ctx, config), f.rolePanelSettingsComponents(ctx, config))
+}
+
+// handleRolePanelChannelSelection posts the role panel to the channel
+// picked in the menu. Picking the same channel again reposts it, which
+// picks up changed roles.
+func (f *Feature) handleRolePanelChannelSelection(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	guildID := i.GuildID
+
+	config, err := f.getWelcomeConfig(ctx, guildID)
+	if err != nil {
+		return f.respondErrorMessage(ctx, s, i, guildID, "welcome.config_not_found")
+	}
+
+	values := i.MessageComponentData().Values
+	if len(values) == 0 {
+		return nil
+	}
+
+	if err := f.postRolePanel(ctx, s, config, values[0]); err != nil {
+		if errors.Is(err, errNoRolePanelRoles) {
+			return f.respondErrorMessage(ctx, s, i, guildID, "welcome.role_panel_no_roles")
+		}
+		return f.respondError(ctx, s, i, guildID, err)
+	}
+
+	return respond(s, i, f.rolePanelSettingsEmbed(ctx, config), f.rolePanelSettingsComponents(ctx, config))
+}
+
+func (f *Feature) rolePanelSettingsEmbed(ctx context.Context, config *WelcomeConfig) *discordgo.MessageEmbed {
+	guildID := config.GuildID
+
+	current := f.i18n.T(ctx, guildID, "welcome.role_panel_not_posted")
+	if config.RolePanelChannelID != "" {
+		current = f.i18n.TWithArgs(ctx, guildID, "welcome.role_panel_posted", map[string]string{
+			"channel": discord.ChannelMention(config.RolePanelChannelID),
+		})
+	}
+
+	return &discordgo.MessageEmbed{
+		Title:       f.i18n.T(ctx, guildID, "welcome.role_panel_settings_title"),
+		Description: f.i18n.T(ctx, guildID, "welcome.role_panel_settings_description") + "\n\n" + current,
+		Color:       int(shared.ColorInfo),
+	}
+}
+
+func (f *Feature) rolePanelSettingsComponents(ctx context.Context, config *WelcomeConfig) []discordgo.MessageComponent {
+	return []discordgo.MessageComponent{
+		discordgo.ActionsRow{
+			Components: []discordgo.MessageComponent{
+				discord.ChannelSelectMenu(rolePanelChannelSelectID,
+					f.i18n.T(ctx, config.GuildID, "welcome.select_role_panel_channel"),
+					[]discordgo.ChannelType{discordgo.ChannelTypeGuildText}, config.RolePanelChannelID),
+			},
+		},
+	}
+}
@@ -0,0 +1,199 @@
+package welcome
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+
+	"welcomebot/internal/fakes"
+	"welcomebot/internal/onboard"
+)
+
+func TestRolePanelChanges(t *testing.T) {
+	onboarding := onboard.OnboardingConfig{
+		Age20LateRole:      "role-20late",
+		Age30EarlyRole:     "role-30early",
+		Age30LateRole:      "role-30late",
+		BunnyclubEventRole: "role-bunnyclub",
+		UserEventRole:      "role-user-event",
+	}
+
+	tests := []struct {
+		name        string
+		memberRoles []string
+		selection   string
+		value       string
+		add, remove []string
+	}{
+		{"add when absent", nil, "age", "30late", []string{"role-30late"}, nil},
+		{"remove when present", []string{"role-30late"}, "age", "30late", nil, []string{"role-30late"}},
+		{"replace a single choice", []string{"role-20late", "role-30early"}, "age", "30late",
+			[]string{"role-30late"}, []string{"role-20late", "role-30early"}},
+		{"events add up", []string{"role-bunnyclub"}, "event", "user", []string{"role-user-event"}, nil},
+		{"no role configured", nil, "age", "40late", nil, nil},
+		{"unknown selection", nil, "staff", "yes", nil, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			add, remove := rolePanelChanges(onboarding, tt.memberRoles, tt.selection, tt.value)
+			if !slices.Equal(add, tt.add) || !slices.Equal(remove, tt.remove) {
+				t.Errorf("got add %v remove %v, want add %v remove %v", add, remove, tt.add, tt.remove)
+			}
+		})
+	}
+}
+
+func TestRolePanelToggle(t *testing.T) {
+	ctx := context.Background()
+	cache := fakes.NewCache()
+	f := &Feature{cache: cache, db: fakes.NewDB(), i18n: fakes.I18n{}, logger: fakes.Logger{}}
+	cacheStartConfigs(t, cache, "guild-1")
+	age := &AgeRangeConfig{GuildID: "guild-1", Age30EarlyRoleID: "role-30early", Age30LateRoleID: "role-30late"}
+	if err := cache.SetJSON(ctx, ageRangeCacheKeyPrefix+"guild-1", age, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	d := fakes.NewDiscord()
+	d.SetMemberRoles("guild-1", "user-1", "role-30early")
+	i := wizardSelect(rolePanelTogglePrefix+"age", "age:30late")
+	i.Member = &discordgo.Member{User: &discordgo.User{ID: "user-1"}, Roles: []string{"role-30early"}}
+	i.Message = &discordgo.Message{Components: []discordgo.MessageComponent{
+		discordgo.ActionsRow{Components: []discordgo.MessageComponent{
+			discordgo.SelectMenu{MenuType: discordgo.StringSelectMenu, CustomID: rolePanelTogglePrefix + "age"},
+		}},
+	}}
+
+	if err := f.HandleInteraction(ctx, d.Session(), i); err != nil {
+		t.Fatalf("HandleInteraction: %v", err)
+	}
+	if got := d.MemberRoles("guild-1", "user-1"); !slices.Equal(got, []string{"role-30late"}) {
+		t.Errorf("expected 30late to replace 30early, got %v", got)
+	}
+
+	// The panel is redrawn so the pick clears, and the result is a
+	// separate ephemeral reply
+	requests := d.Requests()
+	reset, body := string(requests[len(requests)-2].Body), string(requests[len(requests)-1].Body)
+	if !strings.Contains(reset, `"type":7`) || !strings.Contains(reset, rolePanelTogglePrefix+"age") {
+		t.Errorf("expected the panel redrawn, got %s", reset)
+	}
+	if !strings.Contains(body, "welcome.role_panel_added") || !strings.Contains(body, "welcome.role_panel_removed") {
+		t.Errorf("expected the member told what changed, got %s", body)
+	}
+	if !strings.Contains(body, `"flags":64`) {
+		t.Errorf("expected an ephemeral reply, got %s", body)
+	}
+}
+
+func TestRolePanelToggleNeedsCompletedRole(t *testing.T) {
+	ctx := context.Background()
+	cache := fakes.NewCache()
+	f := &Feature{cache: cache, db: fakes.NewDB(), i18n: fakes.I18n{}, logger: fakes.Logger{}}
+	cacheStartConfigs(t, cache, "guild-1")
+	config := &WelcomeConfig{GuildID: "guild-1", CompletedRoleID: "role-completed"}
+	if err := cache.SetJSON(ctx, cacheKeyPrefix+"guild-1", config, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	d := fakes.NewDiscord()
+	i := wizardSelect(rolePanelTogglePrefix+"age", "age:30late")
+	i.Member = &discordgo.Member{User: &discordgo.User{ID: "user-1"}}
+
+	if err := f.HandleInteraction(ctx, d.Session(), i); err != nil {
+		t.Fatalf("HandleInteraction: %v", err)
+	}
+	if got := d.MemberRoles("guild-1", "user-1"); len(got) != 0 {
+		t.Errorf("expected no roles changed, got %v", got)
+	}
+	requests := d.Requests()
+	if body := string(requests[len(requests)-1].Body); !strings.Contains(body, "welcome.role_panel_not_completed") {
+		t.Errorf("expected the member told to finish onboarding, got %s", body)
+	}
+}
+
+func TestPostRolePanel(t *testing.T) {
+	ctx := context.Background()
+	cache := fakes.NewCache()
+	db := fakes.NewDB()
+	f := &Feature{cache: cache, db: db, i18n: fakes.I18n{}, logger: fakes.Logger{}}
+	cacheStartConfigs(t, cache, "guild-1")
+
+	d := fakes.NewDiscord()
+	d.SetGuildRoles("guild-1", &discordgo.Role{ID: "role-30late", Name: "30代後半"})
+
+	config := &WelcomeConfig{GuildID: "guild-1", RolePanelChannelID: "channel-old", RolePanelMessageID: "message-old"}
+	if err := f.postRolePanel(ctx, d.Session(), config, "channel-roles"); err != nil {
+		t.Fatalf("post role panel: %v", err)
+	}
+	if config.RolePanelChannelID != "channel-roles" || config.RolePanelMessageID == "message-old" {
+		t.Errorf("expected the new panel recorded, got %+v", config)
+	}
+
+	var posted string
+	var deleted bool
+	for _, r := range d.Requests() {
+		switch {
+		case r.Method == http.MethodPost && r.Path == "channels/channel-roles/messages":
+			posted = string(r.Body)
+		case r.Method == http.MethodDelete && r.Path == "channels/channel-old/messages/message-old":
+			deleted = true
+		}
+	}
+	// The cached configs set a gender, age and voice role but no others
+	if n := strings.Count(posted, rolePanelTogglePrefix); n != 3 {
+		t.Errorf("expected a menu per configured group, got %d in %s", n, posted)
+	}
+	if !strings.Contains(posted, "30代後半") || !strings.Contains(posted, `"age:30late"`) {
+		t.Errorf("expected options labelled with role names, got %s", posted)
+	}
+	if !deleted {
+		t.Error("expected the previous panel deleted")
+	}
+	if execs := db.Execs(); len(execs) != 1 || !strings.Contains(execs[0].Query, "role_panel_message_id") {
+		t.Errorf("expected the panel saved, got %+v", execs)
+	}
+	if exists, _ := cache.Exists(ctx, cacheKeyPrefix+"guild-1"); exists {
+		t.Error("expected the cached config to be invalidated")
+	}
+
+	cacheStartConfigs(t, cache, "guild-2")
+	for key, value := range map[string]interface{}{
+		ageRangeCacheKeyPrefix + "guild-2":  &AgeRangeConfig{GuildID: "guild-2"},
+		genderCacheKeyPrefix + "guild-2":    &GenderConfig{GuildID: "guild-2"},
+		voiceTypeCacheKeyPrefix + "guild-2": &VoiceTypeConfig{GuildID: "guild-2"},
+	} {
+		if err := cache.SetJSON(ctx, key, value, 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := f.postRolePanel(ctx, d.Session(), &WelcomeConfig{GuildID: "guild-2"}, "channel-roles"); !errors.Is(err, errNoRolePanelRoles) {
+		t.Errorf("expected a guild without roles refused, got %v", err)
+	}
+
+	// A panel that can't be saved is taken down and the old one kept
+	failing := fakes.NewDB()
+	failing.FailExecs(errors.New("connection refused"))
+	f.db = failing
+	before := len(d.Requests())
+	config = &WelcomeConfig{GuildID: "guild-1", RolePanelChannelID: "channel-old", RolePanelMessageID: "message-old"}
+	if err := f.postRolePanel(ctx, d.Session(), config, "channel-roles"); err == nil {
+		t.Fatal("expected the failed save reported")
+	}
+	if config.RolePanelMessageID != "message-old" {
+		t.Errorf("expected the old panel still recorded, got %+v", config)
+	}
+	var deletes []string
+	for _, r := range d.Requests()[before:] {
+		if r.Method == http.MethodDelete {
+			deletes = append(deletes, r.Path)
+		}
+	}
+	if len(deletes) != 1 || !strings.HasPrefix(deletes[0], "channels/channel-roles/messages/") {
+		t.Errorf("expected only the new panel deleted, got %v", deletes)
+	}
+}
@@ -0,0 +1,286 @@
+package welcome
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"welcomebot/internal/bot"
+	"welcomebot/internal/core/discord"
+	"welcomebot/internal/core/i18n"
+	"welcomebot/internal/shared"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+const (
+	// roleSyncPageSize is the most members Discord returns per list call.
+	roleSyncPageSize = 1000
+
+	// roleSyncBatchSize is how many roles are added between progress
+	// updates and pauses.
+	roleSyncBatchSize = 25
+
+	// roleSyncTimeout bounds a whole sync. Interaction tokens last 15
+	// minutes, after which progress can no longer be shown anyway.
+	roleSyncTimeout = 14 * time.Minute
+)
+
+// roleSyncBatchPause spaces out batches so a large sync leaves room in the
+// per-guild role rate limit for onboarding sessions running at the same time.
+var roleSyncBatchPause = 2 * time.Second
+
+// roleSyncProgress counts the members a sync has handled so far.
+type roleSyncProgress struct {
+	Total  int // Members that hold the source role but lack the member role
+	Done   int
+	Added  int
+	Failed int
+}
+
+// showRoleSync asks for the role whose holders should get the member role.
+func (f *Feature) showRoleSync(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	guildID := i.GuildID
+
+	config, err := f.getWelcomeConfig(ctx, guildID)
+	if err != nil {
+		return f.respondErrorMessage(ctx, s, i, guildID, "welcome.config_not_found")
+	}
+	if config.MemberRoleID == "" {
+		return f.respondErrorMessage(ctx, s, i, guildID, "welcome.role_sync_no_member_role")
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Title: f.i18n.T(ctx, guildID, "welcome.role_sync_title"),
+		Description: f.i18n.TWithArgs(ctx, guildID, "welcome.role_sync_description", map[string]string{
+			"role": fmt.Sprintf("<@&%s>", config.MemberRoleID),
+		}),
+		Color: int(shared.ColorInfo),
+	}
+	menu := discord.RoleSelectMenu("welcome:role_sync:source", f.i18n.T(ctx, guildID, "welcome.role_sync_select_source"), "")
+
+	return respond(s, i, embed, []discordgo.MessageComponent{
+		discordgo.ActionsRow{Components: []discordgo.MessageComponent{menu}},
+	})
+}
+
+// handleRoleSyncSource starts giving the member role to every holder of the
+// selected role. The sync runs in the background and edits the response
+// with its progress after each batch.
+func (f *Feature) handleRoleSyncSource(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	guildID := i.GuildID
+
+	sourceRoleID := selectedValue(i)
+	if sourceRoleID == "" {
+		return bot.ErrNoRoleSelected
+	}
+
+	config, err := f.getWelcomeConfig(ctx, guildID)
+	if err != nil {
+		return f.respondErrorMessage(ctx, s, i, guildID, "welcome.config_not_found")
+	}
+	memberRoleID := config.MemberRoleID
+	if memberRoleID == "" {
+		return f.respondErrorMessage(ctx, s, i, guildID, "welcome.role_sync_no_member_role")
+	}
+
+	if s.State.User != nil {
+		blocked, err := discord.UnmanageableRoles(s, guildID, s.State.User.ID, []string{memberRoleID})
+		if err != nil {
+			f.logger.Warn("failed to check role hierarchy", "error", err, "guild_id", guildID)
+		} else if len(blocked) > 0 {
+			return bot.RoleAboveBotError(memberRoleID)
+		}
+	}
+
+	if !f.claimRoleSync(guildID) {
+		return f.respondErrorMessage(ctx, s, i, guildID, "welcome.role_sync_busy")
+	}
+
+	if err := respond(s, i, f.roleSyncEmbed(ctx, guildID, sourceRoleID, memberRoleID, roleSyncProgress{}, false), []discordgo.MessageComponent{}); err != nil {
+		f.releaseRoleSync(guildID)
+		return err
+	}
+
+	f.logger.Info("role sync started",
+		"guild_id", guildID,
+		"source_role_id", sourceRoleID,
+		"member_role_id", memberRoleID,
+	)
+
+	go func() {
+		defer f.releaseRoleSync(guildID)
+
+		syncCtx, cancel := context.WithTimeout(context.Background(), roleSyncTimeout)
+		defer cancel()
+
+		show := func(progress roleSyncProgress, done bool) {
+			embed := f.roleSyncEmbed(syncCtx, guildID, sourceRoleID, memberRoleID, progress, done)
+			if _, err := s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+				Embeds: &[]*discordgo.MessageEmbed{embed},
+			}); err != nil {
+				f.logger.Warn("failed to show role sync progress", "error", err, "guild_id", guildID)
+			}
+		}
+
+		progress, err := f.syncRoles(syncCtx, s, guildID, sourceRoleID, memberRoleID, func(p roleSyncProgress) {
+			show(p, false)
+		})
+		if errors.Is(err, context.DeadlineExceeded) {
+			f.logger.Warn("role sync timed out", "guild_id", guildID, "done", progress.Done, "total", progress.Total)
+			// syncCtx is done; the message still has to be sent
+			_, _ = s.FollowupMessageCreate(i.Interaction, true, &discordgo.WebhookParams{
+				Content: f.i18n.T(context.Background(), guildID, "welcome.role_sync_timed_out"),
+				Flags:   discordgo.MessageFlagsEphemeral,
+			})
+			return
+		}
+		if err != nil {
+			f.logger.Error("role sync failed", "error", err, "guild_id", guildID)
+			_, _ = s.FollowupMessageCreate(i.Interaction, true, &discordgo.WebhookParams{
+				Content: f.i18n.T(syncCtx, guildID, "welcome.role_sync_failed"),
+				Flags:   discordgo.MessageFlagsEphemeral,
+			})
+			return
+		}
+		show(progress, true)
+
+		f.logger.Info("role sync finished",
+			"guild_id", guildID,
+			"source_role_id", sourceRoleID,
+			"total", progress.Total,
+			"added", progress.Added,
+			"failed", progress.Failed,
+		)
+	}()
+
+	return nil
+}
+
+// syncRoles gives memberRoleID to every member holding sourceRoleID that
+// lacks it, in batches of roleSyncBatchSize with a pause between them.
+// report is called after each batch. A member that can't be updated is
+// counted as failed and does not stop the rest; only listing the members
+// fails the sync.
+func (f *Feature) syncRoles(ctx context.Context, s *discordgo.Session, guildID, sourceRoleID, memberRoleID string, report func(roleSyncProgress)) (roleSyncProgress, error) {
+	var progress roleSyncProgress
+
+	userIDs, err := f.roleSyncTargets(ctx, s, guildID, sourceRoleID, memberRoleID)
+	if err != nil {
+		return progress, err
+	}
+	progress.Total = len(userIDs)
+
+	for start := 0; start < len(userIDs); start += roleSyncBatchSize {
+		if start > 0 {
+			select {
+			case <-time.After(roleSyncBatchPause):
+			case <-ctx.Done():
+				return progress, ctx.Err()
+			}
+		}
+
+		for _, userID := range userIDs[start:min(start+roleSyncBatchSize, len(userIDs))] {
+			err := discord.Retry(ctx, f.logger, "sync member role", discord.DefaultRetryPolicy, func() error {
+				return s.GuildMemberRoleAdd(guildID, userID, memberRoleID)
+			})
+			if err != nil {
+				f.logger.Warn("failed to sync member role",
+					"error", err,
+					"guild_id", guildID,
+					"user_id", userID,
+				)
+				progress.Failed++
+			} else {
+				progress.Added++
+			}
+			progress.Done++
+		}
+
+		report(progress)
+	}
+	return progress, nil
+}
+
+// roleSyncTargets lists the members holding sourceRoleID but not
+// memberRoleID. Bots are skipped. Discord only lists members to a bot with
+// the privileged Server Members intent enabled in the Developer Portal.
+func (f *Feature) roleSyncTargets(ctx context.Context, s *discordgo.Session, guildID, sourceRoleID, memberRoleID string) ([]string, error) {
+	var userIDs []string
+	after := ""
+	for {
+		var members []*discordgo.Member
+		err := discord.Retry(ctx, f.logger, "list members", discord.DefaultRetryPolicy, func() error {
+			var err error
+			members, err = s.GuildMembers(guildID, after, roleSyncPageSize)
+			return err
+		})
+		if err != nil {
+			return nil, fmt.Errorf("list members: %w", err)
+		}
+
+		for _, m := range members {
+			if m.User == nil || m.User.Bot {
+				continue
+			}
+			if slices.Contains(m.Roles, sourceRoleID) && !slices.Contains(m.Roles, memberRoleID) {
+				userIDs = append(userIDs, m.User.ID)
+			}
+		}
+
+		if len(members) < roleSyncPageSize || members[len(members)-1].User == nil {
+			return userIDs, nil
+		}
+		after = members[len(members)-1].User.ID
+	}
+}
+
+// roleSyncEmbed shows how far a sync has got.
+func (f *Feature) roleSyncEmbed(ctx context.Context, guildID, sourceRoleID, memberRoleID string, progress roleSyncProgress, done bool) *discordgo.MessageEmbed {
+	locale, _ := f.i18n.GetGuildLanguage(ctx, guildID)
+	args := map[string]string{
+		"source": fmt.Sprintf("<@&%s>", sourceRoleID),
+		"role":   fmt.Sprintf("<@&%s>", memberRoleID),
+		"done":   i18n.FormatNumber(locale, int64(progress.Done)),
+		"total":  i18n.FormatNumber(locale, int64(progress.Total)),
+		"added":  i18n.FormatNumber(locale, int64(progress.Added)),
+		"failed": i18n.FormatNumber(locale, int64(progress.Failed)),
+	}
+
+	key, color := "welcome.role_sync_progress", shared.ColorInfo
+	if done {
+		key, color = "welcome.role_sync_done", shared.ColorSuccess
+		if progress.Failed > 0 {
+			color = shared.ColorWarning
+		}
+	}
+
+	return &discordgo.MessageEmbed{
+		Title:       f.i18n.T(ctx, guildID, "welcome.role_sync_title"),
+		Description: f.i18n.TWithArgs(ctx, guildID, key, args),
+		Color:       int(color),
+	}
+}
+
+// claimRoleSync reserves the guild for a sync, so two admins can't run
+// overlapping ones.
+func (f *Feature) claimRoleSync(guildID string) bool {
+	f.roleSyncMu.Lock()
+	defer f.roleSyncMu.Unlock()
+
+	if f.roleSyncGuilds[guildID] {
+		return false
+	}
+	f.roleSyncGuilds[guildID] = true
+	return true
+}
+
+// releaseRoleSync frees the guild for another sync.
+func (f *Feature) releaseRoleSync(guildID string) {
+	f.roleSyncMu.Lock()
+	defer f.roleSyncMu.Unlock()
+
+	delete(f.roleSyncGuilds, guildID)
+}
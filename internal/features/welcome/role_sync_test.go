@@ -0,0 +1,76 @@
+package welcome
+
+import (
+	"context"
+	"testing"
+
+	"welcomebot/internal/fakes"
+)
+
+func TestSyncRoles(t *testing.T) {
+	roleSyncBatchPause = 0
+
+	ctx := context.Background()
+	d := fakes.NewDiscord()
+	f := &Feature{logger: fakes.Logger{}}
+
+	// More holders than fit in one batch, plus members who must be left alone
+	var holders []string
+	for n := 0; n < roleSyncBatchSize+3; n++ {
+		userID := "user-" + string(rune('a'+n/26)) + string(rune('a'+n%26))
+		holders = append(holders, userID)
+		d.SetMemberRoles("guild-1", userID, "role-verified")
+	}
+	d.SetMemberRoles("guild-1", "zz-synced", "role-verified", "role-member")
+	d.SetMemberRoles("guild-1", "zz-other", "role-other")
+	d.SetMemberRoles("guild-2", "zz-elsewhere", "role-verified")
+
+	var reports []roleSyncProgress
+	progress, err := f.syncRoles(ctx, d.Session(), "guild-1", "role-verified", "role-member", func(p roleSyncProgress) {
+		reports = append(reports, p)
+	})
+	if err != nil {
+		t.Fatalf("syncRoles: %v", err)
+	}
+
+	want := roleSyncProgress{Total: len(holders), Done: len(holders), Added: len(holders)}
+	if progress != want {
+		t.Errorf("got %+v, want %+v", progress, want)
+	}
+	for _, userID := range holders {
+		if !d.HasRole("guild-1", userID, "role-member") {
+			t.Errorf("expected %s to get the member role", userID)
+		}
+	}
+	if d.HasRole("guild-1", "zz-other", "role-member") || d.HasRole("guild-2", "zz-elsewhere", "role-member") {
+		t.Error("expected members without the source role to be left alone")
+	}
+	if got := countRoleAdds(d); got != len(holders) {
+		t.Errorf("expected %d role adds, got %d", len(holders), got)
+	}
+
+	if len(reports) != 2 || reports[0].Done != roleSyncBatchSize {
+		t.Errorf("expected a report after each of two batches, got %+v", reports)
+	}
+}
+
+func TestSyncRolesCountsFailures(t *testing.T) {
+	roleSyncBatchPause = 0
+
+	d := fakes.NewDiscord()
+	f := &Feature{logger: fakes.Logger{}}
+	d.SetMemberRoles("guild-1", "user-1", "role-verified")
+	d.SetMemberRoles("guild-1", "user-2", "role-verified")
+	d.SetNotFound("guilds/guild-1/members/user-1/roles/role-member")
+
+	progress, err := f.syncRoles(context.Background(), d.Session(), "guild-1", "role-verified", "role-member", func(roleSyncProgress) {})
+	if err != nil {
+		t.Fatalf("syncRoles: %v", err)
+	}
+	if progress.Added != 1 || progress.Failed != 1 || progress.Done != 2 {
+		t.Errorf("expected one added and one failed, got %+v", progress)
+	}
+	if !d.HasRole("guild-1", "user-2", "role-member") {
+		t.Error("expected a failed member not to stop the rest")
+	}
+}
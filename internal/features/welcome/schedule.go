@@ -0,0 +1,389 @@
+package welcome
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"welcomebot/internal/core/i18n"
+	"welcomebot/internal/shared"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// A guild's onboarding schedule is stored as one line of days, hours and
+// timezone, e.g. "mon,tue,wed,thu,fri 18:00-23:00 Asia/Tokyo". Outside the
+// window the welcome button says when onboarding opens instead of starting
+// a session. An empty schedule means onboarding is always open.
+
+var (
+	errScheduleDays     = errors.New("invalid schedule days")
+	errScheduleHours    = errors.New("invalid schedule hours")
+	errScheduleTimezone = errors.New("invalid schedule timezone")
+)
+
+// weekdayNames are the day names accepted in a schedule, Sunday first to
+// match time.Weekday.
+var weekdayNames = []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}
+
+// Schedule is a weekly onboarding window.
+type Schedule struct {
+	Days     [7]bool // Indexed by time.Weekday; the day a window starts on
+	Start    int     // Minutes after midnight the window opens
+	End      int     // Minutes after midnight it closes; before Start for overnight windows
+	Location *time.Location
+}
+
+// ParseSchedule parses a stored schedule line.
+func ParseSchedule(spec string) (*Schedule, error) {
+	fields := strings.Fields(spec)
+	if len(fields) != 3 {
+		return nil, fmt.Errorf("schedule %q: want days, hours and timezone", spec)
+	}
+	return parseScheduleParts(fields[0], fields[1], fields[2])
+}
+
+// parseScheduleParts parses the days ("mon-fri,sun" or "daily"), hours
+// ("18:00-23:00") and IANA timezone of a schedule.
+func parseScheduleParts(days, hours, timezone string) (*Schedule, error) {
+	var schedule Schedule
+
+	days = strings.ToLower(strings.ReplaceAll(days, " ", ""))
+	if days == "daily" {
+		days = "sun-sat"
+	}
+	for _, part := range strings.Split(days, ",") {
+		from, to, isRange := strings.Cut(part, "-")
+		if !isRange {
+			to = from
+		}
+		first, firstOK := parseWeekday(from)
+		last, lastOK := parseWeekday(to)
+		if !firstOK || !lastOK {
+			return nil, fmt.Errorf("%w: %q", errScheduleDays, part)
+		}
+		// Ranges may wrap past Saturday, e.g. "fri-mon"
+		for d := first; ; d = (d + 1) % 7 {
+			schedule.Days[d] = true
+			if d == last {
+				break
+			}
+		}
+	}
+
+	from, to, ok := strings.Cut(strings.ReplaceAll(hours, " ", ""), "-")
+	start, startOK := parseClock(from)
+	end, endOK := parseClock(to)
+	if !ok || !startOK || !endOK || start == end || start == 24*60 {
+		return nil, fmt.Errorf("%w: %q", errScheduleHours, hours)
+	}
+	schedule.Start, schedule.End = start, end
+
+	loc, err := time.LoadLocation(strings.TrimSpace(timezone))
+	if err != nil || strings.TrimSpace(timezone) == "" {
+		return nil, fmt.Errorf("%w: %q", errScheduleTimezone, timezone)
+	}
+	schedule.Location = loc
+
+	return &schedule, nil
+}
+
+// parseWeekday parses a lowercase day name, abbreviated or in full.
+func parseWeekday(name string) (time.Weekday, bool) {
+	for d, short := range weekdayNames {
+		if name == short || name == strings.ToLower(time.Weekday(d).String()) {
+			return time.Weekday(d), true
+		}
+	}
+	return 0, false
+}
+
+// parseClock parses "HH:MM" into minutes after midnight. "24:00" is
+// accepted as the end of the day.
+func parseClock(s string) (int, bool) {
+	h, m, ok := strings.Cut(s, ":")
+	if !ok || len(m) != 2 {
+		return 0, false
+	}
+	hour, err := strconv.Atoi(h)
+	if err != nil {
+		return 0, false
+	}
+	minute, err := strconv.Atoi(m)
+	if err != nil || hour < 0 || minute < 0 || minute > 59 {
+		return 0, false
+	}
+	if hour > 23 && !(hour == 24 && minute == 0) {
+		return 0, false
+	}
+	return hour*60 + minute, true
+}
+
+// String returns the schedule in the stored form ParseSchedule reads.
+func (s *Schedule) String() string {
+	var days []string
+	for d, on := range s.Days {
+		if on {
+			days = append(days, weekdayNames[d])
+		}
+	}
+	return fmt.Sprintf("%s %s %s", strings.Join(days, ","), s.Hours(), s.Location)
+}
+
+// Hours returns the window's opening and closing times, "18:00-23:00".
+func (s *Schedule) Hours() string {
+	return fmt.Sprintf("%02d:%02d-%02d:%02d", s.Start/60, s.Start%60, s.End/60, s.End%60)
+}
+
+// Open reports whether onboarding is open at t. A window that runs past
+// midnight belongs to the day it starts on.
+func (s *Schedule) Open(t time.Time) bool {
+	t = t.In(s.Location)
+	minute := t.Hour()*60 + t.Minute()
+	today := t.Weekday()
+	yesterday := (today + 6) % 7
+
+	if s.Start < s.End {
+		return s.Days[today] && minute >= s.Start && minute < s.End
+	}
+	return (s.Days[today] && minute >= s.Start) || (s.Days[yesterday] && minute < s.End)
+}
+
+// NextOpen returns when the window next opens after t, in the schedule's
+// timezone. ok is false when no day is enabled.
+func (s *Schedule) NextOpen(t time.Time) (next time.Time, ok bool) {
+	t = t.In(s.Location)
+	for offset := 0; offset <= 7; offset++ {
+		day := t.AddDate(0, 0, offset)
+		if !s.Days[day.Weekday()] {
+			continue
+		}
+		opens := time.Date(day.Year(), day.Month(), day.Day(), s.Start/60, s.Start%60, 0, 0, s.Location)
+		if opens.After(t) {
+			return opens, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// onboardingClosed reports whether the guild's schedule keeps onboarding
+// from starting at now. A schedule that no longer parses is logged and
+// ignored, so a bad row can't lock members out.
+func (f *Feature) onboardingClosed(config *WelcomeConfig, now time.Time) (*Schedule, bool) {
+	if config.Schedule == "" {
+		return nil, false
+	}
+	schedule, err := ParseSchedule(config.Schedule)
+	if err != nil {
+		f.logger.Warn("ignoring invalid onboarding schedule", "error", err, "guild_id", config.GuildID)
+		return nil, false
+	}
+	return schedule, !schedule.Open(now)
+}
+
+// respondOnboardingClosed tells the member when onboarding is available.
+func (f *Feature) respondOnboardingClosed(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate, guildID string, schedule *Schedule, now time.Time) error {
+	description := f.i18n.TWithArgs(ctx, guildID, "welcome.schedule_closed", f.scheduleArgs(ctx, guildID, schedule))
+	if next, ok := schedule.NextOpen(now); ok {
+		locale, _ := f.i18n.GetGuildLanguage(ctx, guildID)
+		description += "\n" + f.i18n.TWithArgs(ctx, guildID, "welcome.schedule_next_open", map[string]string{
+			"next": i18n.FormatDate(locale, next),
+		})
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Title:       f.i18n.T(ctx, guildID, "welcome.schedule_closed_title"),
+		Description: description,
+		Color:       int(shared.ColorWarning),
+	}
+
+	return s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Embeds: []*discordgo.MessageEmbed{embed},
+			Flags:  discordgo.MessageFlagsEphemeral,
+		},
+	})
+}
+
+// scheduleArgs formats a schedule's days, hours and timezone for display.
+func (f *Feature) scheduleArgs(ctx context.Context, guildID string, schedule *Schedule) map[string]string {
+	locale, _ := f.i18n.GetGuildLanguage(ctx, guildID)
+
+	var days []string
+	for d, on := range schedule.Days {
+		if on {
+			days = append(days, i18n.FormatWeekday(locale, time.Weekday(d)))
+		}
+	}
+	return map[string]string{
+		"days":     strings.Join(days, ", "),
+		"hours":    schedule.Hours(),
+		"timezone": schedule.Location.String(),
+	}
+}
+
+// saveSchedule stores the guild's onboarding schedule; "" keeps onboarding
+// always open.
+func (f *Feature) saveSchedule(ctx context.Context, config *WelcomeConfig, schedule string) error {
+	_, err := f.db.Exec(ctx, `
+		UPDATE guild_welcome_config
+		SET onboarding_schedule = NULLIF($1, ''), updated_at = NOW()
+		WHERE guild_id = $2
+	`, schedule, config.GuildID)
+	if err != nil {
+		return fmt.Errorf("save onboarding schedule: %w", err)
+	}
+	config.Schedule = schedule
+
+	if err := f.cache.Delete(ctx, cacheKeyPrefix+config.GuildID); err != nil {
+		f.logger.Warn("failed to invalidate welcome config", "error", err, "guild_id", config.GuildID)
+	}
+	return nil
+}
+
+// showSchedule shows the guild's onboarding hours, with buttons to change
+// or clear them.
+func (f *Feature) showSchedule(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	guildID := i.GuildID
+
+	config, err := f.getWelcomeConfig(ctx, guildID)
+	if err != nil {
+		return f.respondErrorMessage(ctx, s, i, guildID, "welcome.config_not_found")
+	}
+
+	return respond(s, i, f.scheduleEmbed(ctx, config), f.scheduleComponents(ctx, config))
+}
+
+// showScheduleModal asks for the days, hours and timezone, prefilled with
+// the current schedule.
+func (f *Feature) showScheduleModal(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	guildID := i.GuildID
+
+	var days, hours, timezone string
+	if config, err := f.getWelcomeConfig(ctx, guildID); err == nil && config.Schedule != "" {
+		if fields := strings.Fields(config.Schedule); len(fields) == 3 {
+			days, hours, timezone = fields[0], fields[1], fields[2]
+		}
+	}
+
+	input := func(customID, labelKey, placeholderKey, value string) discordgo.MessageComponent {
+		return discordgo.ActionsRow{
+			Components: []discordgo.MessageComponent{
+				discordgo.TextInput{
+					CustomID:    customID,
+					Label:       f.i18n.T(ctx, guildID, labelKey),
+					Style:       discordgo.TextInputShort,
+					Placeholder: f.i18n.T(ctx, guildID, placeholderKey),
+					Value:       value,
+					Required:    true,
+					MaxLength:   64,
+				},
+			},
+		}
+	}
+
+	return s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseModal,
+		Data: &discordgo.InteractionResponseData{
+			CustomID: "welcome:schedule:modal",
+			Title:    f.i18n.T(ctx, guildID, "welcome.schedule_modal_title"),
+			Components: []discordgo.MessageComponent{
+				input("days", "welcome.schedule_days_label", "welcome.schedule_days_placeholder", days),
+				input("hours", "welcome.schedule_hours_label", "welcome.schedule_hours_placeholder", hours),
+				input("timezone", "welcome.schedule_timezone_label", "welcome.schedule_timezone_placeholder", timezone),
+			},
+		},
+	})
+}
+
+// handleScheduleSubmit validates and saves the schedule from the modal.
+func (f *Feature) handleScheduleSubmit(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	guildID := i.GuildID
+	data := i.ModalSubmitData()
+
+	schedule, err := parseScheduleParts(modalValue(data, "days"), modalValue(data, "hours"), modalValue(data, "timezone"))
+	switch {
+	case errors.Is(err, errScheduleDays):
+		return f.respondErrorMessage(ctx, s, i, guildID, "welcome.schedule_invalid_days")
+	case errors.Is(err, errScheduleHours):
+		return f.respondErrorMessage(ctx, s, i, guildID, "welcome.schedule_invalid_hours")
+	case errors.Is(err, errScheduleTimezone):
+		return f.respondErrorMessage(ctx, s, i, guildID, "welcome.schedule_invalid_timezone")
+	case err != nil:
+		return err
+	}
+
+	config, err := f.getWelcomeConfig(ctx, guildID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return f.respondErrorMessage(ctx, s, i, guildID, "welcome.config_not_found")
+	}
+	if err != nil {
+		return f.respondError(ctx, s, i, guildID, err)
+	}
+
+	if err := f.saveSchedule(ctx, config, schedule.String()); err != nil {
+		return f.respondError(ctx, s, i, guildID, err)
+	}
+
+	f.logger.Info("onboarding schedule updated", "guild_id", guildID, "schedule", config.Schedule)
+
+	return respond(s, i, f.scheduleEmbed(ctx, config), f.scheduleComponents(ctx, config))
+}
+
+// clearSchedule opens onboarding at all times again.
+func (f *Feature) clearSchedule(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	guildID := i.GuildID
+
+	config, err := f.getWelcomeConfig(ctx, guildID)
+	if err != nil {
+		return f.respondErrorMessage(ctx, s, i, guildID, "welcome.config_not_found")
+	}
+
+	if err := f.saveSchedule(ctx, config, ""); err != nil {
+		return f.respondError(ctx, s, i, guildID, err)
+	}
+
+	f.logger.Info("onboarding schedule cleared", "guild_id", guildID)
+
+	return respond(s, i, f.scheduleEmbed(ctx, config), f.scheduleComponents(ctx, config))
+}
+
+func (f *Feature) scheduleEmbed(ctx context.Context, config *WelcomeConfig) *discordgo.MessageEmbed {
+	guildID := config.GuildID
+
+	current := f.i18n.T(ctx, guildID, "welcome.schedule_always_open")
+	if schedule, err := ParseSchedule(config.Schedule); err == nil {
+		current = f.i18n.TWithArgs(ctx, guildID, "welcome.schedule_current", f.scheduleArgs(ctx, guildID, schedule))
+	}
+
+	return &discordgo.MessageEmbed{
+		Title:       f.i18n.T(ctx, guildID, "welcome.schedule_title"),
+		Description: f.i18n.T(ctx, guildID, "welcome.schedule_description") + "\n\n" + current,
+		Color:       int(shared.ColorInfo),
+	}
+}
+
+func (f *Feature) scheduleComponents(ctx context.Context, config *WelcomeConfig) []discordgo.MessageComponent {
+	return []discordgo.MessageComponent{
+		discordgo.ActionsRow{
+			Components: []discordgo.MessageComponent{
+				discordgo.Button{
+					Label:    f.i18n.T(ctx, config.GuildID, "welcome.schedule_set"),
+					Style:    discordgo.PrimaryButton,
+					CustomID: "welcome:schedule:edit",
+				},
+				discordgo.Button{
+					Label:    f.i18n.T(ctx, config.GuildID, "welcome.schedule_clear"),
+					Style:    discordgo.SecondaryButton,
+					CustomID: "welcome:schedule:clear",
+					Disabled: config.Schedule == "",
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,116 @@
+package welcome
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"welcomebot/internal/fakes"
+)
+
+func TestParseSchedule(t *testing.T) {
+	schedule, err := parseScheduleParts("Fri-Mon, wed", "22:00-02:30", "Asia/Tokyo")
+	if err != nil {
+		t.Fatalf("parseScheduleParts: %v", err)
+	}
+	if got, want := schedule.String(), "sun,mon,wed,fri,sat 22:00-02:30 Asia/Tokyo"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	// The stored form reads back the same
+	again, err := ParseSchedule(schedule.String())
+	if err != nil || again.String() != schedule.String() {
+		t.Errorf("round trip: got %+v, %v", again, err)
+	}
+
+	if daily, err := parseScheduleParts("daily", "09:00-24:00", "UTC"); err != nil || daily.String() != "sun,mon,tue,wed,thu,fri,sat 09:00-24:00 UTC" {
+		t.Errorf("daily: got %v, %v", daily, err)
+	}
+
+	tests := []struct {
+		days, hours, timezone string
+		want                  error
+	}{
+		{"", "09:00-17:00", "UTC", errScheduleDays},
+		{"mon-funday", "09:00-17:00", "UTC", errScheduleDays},
+		{"mon", "9-17", "UTC", errScheduleHours},
+		{"mon", "09:00-09:00", "UTC", errScheduleHours},
+		{"mon", "09:00-25:00", "UTC", errScheduleHours},
+		{"mon", "09:00-17:00", "Mars/Olympus", errScheduleTimezone},
+		{"mon", "09:00-17:00", "", errScheduleTimezone},
+	}
+	for _, tt := range tests {
+		if _, err := parseScheduleParts(tt.days, tt.hours, tt.timezone); !errors.Is(err, tt.want) {
+			t.Errorf("parseScheduleParts(%q, %q, %q) = %v, want %v", tt.days, tt.hours, tt.timezone, err, tt.want)
+		}
+	}
+}
+
+func TestScheduleOpen(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Skip("no timezone data")
+	}
+	// 2026-03-06 is a Friday
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2026, time.March, day, hour, minute, 0, 0, tokyo)
+	}
+
+	weekdays, _ := ParseSchedule("mon,tue,wed,thu,fri 18:00-23:00 Asia/Tokyo")
+	overnight, _ := ParseSchedule("fri 22:00-02:00 Asia/Tokyo")
+
+	tests := []struct {
+		name     string
+		schedule *Schedule
+		t        time.Time
+		want     bool
+	}{
+		{"inside", weekdays, at(6, 18, 0), true},
+		{"before", weekdays, at(6, 17, 59), false},
+		{"end is exclusive", weekdays, at(6, 23, 0), false},
+		{"day off", weekdays, at(7, 19, 0), false},
+		{"other timezone", weekdays, at(6, 19, 0).UTC(), true},
+		{"overnight start", overnight, at(6, 22, 30), true},
+		{"overnight after midnight", overnight, at(7, 1, 59), true},
+		{"overnight closed", overnight, at(7, 2, 0), false},
+		{"overnight wrong day", overnight, at(6, 1, 0), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.schedule.Open(tt.t); got != tt.want {
+				t.Errorf("Open(%v) = %v, want %v", tt.t, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScheduleNextOpen(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Skip("no timezone data")
+	}
+	schedule, _ := ParseSchedule("mon,fri 18:00-23:00 Asia/Tokyo")
+
+	// Friday evening after closing opens next on Monday
+	next, ok := schedule.NextOpen(time.Date(2026, time.March, 6, 23, 30, 0, 0, tokyo))
+	if want := time.Date(2026, time.March, 9, 18, 0, 0, 0, tokyo); !ok || !next.Equal(want) {
+		t.Errorf("got %v, want %v", next, want)
+	}
+
+	// Earlier the same day opens that evening
+	next, _ = schedule.NextOpen(time.Date(2026, time.March, 6, 9, 0, 0, 0, tokyo))
+	if want := time.Date(2026, time.March, 6, 18, 0, 0, 0, tokyo); !next.Equal(want) {
+		t.Errorf("got %v, want %v", next, want)
+	}
+}
+
+func TestOnboardingClosedIgnoresInvalidSchedule(t *testing.T) {
+	f := &Feature{logger: fakes.Logger{}}
+
+	if _, closed := f.onboardingClosed(&WelcomeConfig{}, time.Now()); closed {
+		t.Error("expected no schedule to mean always open")
+	}
+	if _, closed := f.onboardingClosed(&WelcomeConfig{Schedule: "someday 9-5 nowhere"}, time.Now()); closed {
+		t.Error("expected an invalid schedule to be ignored")
+	}
+}
@@ -0,0 +1,133 @@
+package welcome
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"welcomebot/internal/core/i18n"
+	"welcomebot/internal/shared"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/lib/pq"
+)
+
+// sessionLocalesID is the custom ID of the session languages menu.
+const sessionLocalesID = "welcome:session_locales:select"
+
+// saveSessionLocales stores the languages members pick from before guide
+// selection. Languages without translations and duplicates are dropped.
+func (f *Feature) saveSessionLocales(ctx context.Context, config *WelcomeConfig, locales []string) error {
+	available := f.i18n.AvailableLanguages()
+
+	var kept []string
+	for _, locale := range locales {
+		if slices.Contains(available, locale) && !slices.Contains(kept, locale) {
+			kept = append(kept, locale)
+		}
+	}
+
+	_, err := f.db.Exec(ctx, `
+		UPDATE guild_welcome_config
+		SET session_locales = $1, updated_at = NOW()
+		WHERE guild_id = $2
+	`, pq.Array(kept), config.GuildID)
+	if err != nil {
+		return fmt.Errorf("save session locales: %w", err)
+	}
+	config.SessionLocales = kept
+
+	if err := f.cache.Delete(ctx, cacheKeyPrefix+config.GuildID); err != nil {
+		f.logger.Warn("failed to invalidate welcome config", "error", err, "guild_id", config.GuildID)
+	}
+	return nil
+}
+
+// showSessionLocales shows the languages members pick from, with a menu to
+// change them.
+func (f *Feature) showSessionLocales(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	guildID := i.GuildID
+
+	config, err := f.getWelcomeConfig(ctx, guildID)
+	if err != nil {
+		return f.respondErrorMessage(ctx, s, i, guildID, "welcome.config_not_found")
+	}
+
+	return respond(s, i, f.sessionLocalesEmbed(ctx, config), f.sessionLocalesComponents(ctx, config))
+}
+
+// handleSessionLocalesSelection saves the languages picked in the menu.
+func (f *Feature) handleSessionLocalesSelection(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	guildID := i.GuildID
+
+	config, err := f.getWelcomeConfig(ctx, guildID)
+	if err != nil {
+		return f.respondErrorMessage(ctx, s, i, guildID, "welcome.config_not_found")
+	}
+
+	if err := f.saveSessionLocales(ctx, config, i.MessageComponentData().Values); err != nil {
+		return f.respondError(ctx, s, i, guildID, err)
+	}
+
+	f.logger.Info("session locales updated", "guild_id", guildID, "locales", config.SessionLocales)
+
+	return respond(s, i, f.sessionLocalesEmbed(ctx, config), f.sessionLocalesComponents(ctx, config))
+}
+
+// languageName returns the name of locale in that language, e.g. 日本語.
+func (f *Feature) languageName(ctx context.Context, guildID, locale string) string {
+	return f.i18n.T(i18n.WithLocale(ctx, locale), guildID, "onboarding.language_name")
+}
+
+func (f *Feature) sessionLocalesEmbed(ctx context.Context, config *WelcomeConfig) *discordgo.MessageEmbed {
+	guildID := config.GuildID
+
+	current := f.i18n.T(ctx, guildID, "welcome.session_locales_off")
+	if len(config.SessionLocales) > 1 {
+		var names []string
+		for _, locale := range config.SessionLocales {
+			names = append(names, f.languageName(ctx, guildID, locale))
+		}
+		current = f.i18n.TWithArgs(ctx, guildID, "welcome.session_locales_on", map[string]string{
+			"languages": strings.Join(names, ", "),
+		})
+	}
+
+	return &discordgo.MessageEmbed{
+		Title:       f.i18n.T(ctx, guildID, "welcome.session_locales_title"),
+		Description: f.i18n.T(ctx, guildID, "welcome.session_locales_description") + "\n\n" + current,
+		Color:       int(shared.ColorInfo),
+	}
+}
+
+func (f *Feature) sessionLocalesComponents(ctx context.Context, config *WelcomeConfig) []discordgo.MessageComponent {
+	guildID := config.GuildID
+	available := f.i18n.AvailableLanguages()
+
+	options := make([]discordgo.SelectMenuOption, 0, len(available))
+	for _, locale := range available {
+		options = append(options, discordgo.SelectMenuOption{
+			Label:   f.languageName(ctx, guildID, locale),
+			Value:   locale,
+			Default: slices.Contains(config.SessionLocales, locale),
+		})
+	}
+
+	// Clearing the menu turns the step off
+	minValues := 0
+	return []discordgo.MessageComponent{
+		discordgo.ActionsRow{
+			Components: []discordgo.MessageComponent{
+				discordgo.SelectMenu{
+					MenuType:    discordgo.StringSelectMenu,
+					CustomID:    sessionLocalesID,
+					Placeholder: f.i18n.T(ctx, guildID, "welcome.select_session_locales"),
+					MinValues:   &minValues,
+					MaxValues:   len(options),
+					Options:     options,
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,37 @@
+package welcome
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"welcomebot/internal/fakes"
+)
+
+func TestSaveSessionLocales(t *testing.T) {
+	ctx := context.Background()
+	cache := fakes.NewCache()
+	db := fakes.NewDB()
+	f := &Feature{cache: cache, db: db, i18n: fakes.I18n{}, logger: fakes.Logger{}}
+
+	config := &WelcomeConfig{GuildID: "guild-1"}
+	// fakes.I18n only has en
+	if err := f.saveSessionLocales(ctx, config, []string{"en", "xx", "en"}); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	if got := fmt.Sprint(config.SessionLocales); got != "[en]" {
+		t.Errorf("session locales = %s, want [en]", got)
+	}
+	if exists, _ := cache.Exists(ctx, cacheKeyPrefix+"guild-1"); exists {
+		t.Error("expected the cached config to be invalidated")
+	}
+	if execs := db.Execs(); len(execs) != 1 {
+		t.Errorf("expected one update, got %+v", execs)
+	}
+
+	config.SessionLocales = []string{"en", "ja"}
+	onboarding := onboardingConfig(config, nil, nil, nil, nil, nil)
+	if got := fmt.Sprint(onboarding.Locales); got != "[en ja]" {
+		t.Errorf("payload locales = %s, want [en ja]", got)
+	}
+}
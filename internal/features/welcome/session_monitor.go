@@ -0,0 +1,119 @@
+package welcome
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"welcomebot/internal/onboard"
+)
+
+const (
+	// DefaultSessionHeartbeatStale is how old a session's heartbeat may get
+	// before master treats its worker as dead: three missed beats.
+	DefaultSessionHeartbeatStale = 3 * onboard.SessionHeartbeatInterval
+
+	// sessionMonitorInterval is how often session heartbeats are checked.
+	sessionMonitorInterval = onboard.SessionHeartbeatInterval
+)
+
+// MonitorSessions fails sessions whose worker stopped beating until ctx is
+// cancelled. A worker that dies mid-session leaves its slave marked busy
+// and its member stuck until the slave status expires; this frees both
+// within a few heartbeats.
+func (f *Feature) MonitorSessions(ctx context.Context, staleAfter time.Duration) {
+	if staleAfter <= 0 {
+		staleAfter = DefaultSessionHeartbeatStale
+	}
+
+	ticker := time.NewTicker(sessionMonitorInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			f.checkSessionHeartbeats(ctx, now, staleAfter)
+		}
+	}
+}
+
+// checkSessionHeartbeats fails every session whose heartbeat is older than
+// staleAfter at now, and returns how many it failed. Sessions without a
+// heartbeat have not been picked up by a worker yet and are left alone.
+func (f *Feature) checkSessionHeartbeats(ctx context.Context, now time.Time, staleAfter time.Duration) int {
+	keys, err := f.cache.Keys(ctx, sessionKeyPrefix+"*")
+	if err != nil {
+		f.logger.Warn("failed to list session heartbeats", "error", err)
+		return 0
+	}
+
+	failed := 0
+	for _, key := range keys {
+		guildID, userID, ok := onboard.ParseSessionHeartbeatKey(key)
+		if !ok {
+			continue
+		}
+
+		value, err := f.cache.Get(ctx, key)
+		if err != nil {
+			continue // Ended while being listed
+		}
+		last, err := onboard.ParseSessionHeartbeat(value)
+		if err != nil {
+			f.logger.Warn("dropping unreadable session heartbeat", "error", err, "key", key)
+			_ = f.cache.Delete(ctx, key)
+			continue
+		}
+		if now.Sub(last) <= staleAfter {
+			continue
+		}
+
+		if f.failStaleSession(ctx, guildID, userID, key, now.Sub(last)) {
+			failed++
+		}
+	}
+	return failed
+}
+
+// failStaleSession recovers from a worker that died mid-session: the slave
+// and the guild's session slot are freed, the member can start again, and
+// staff are told. It returns false when the session had already ended.
+func (f *Feature) failStaleSession(ctx context.Context, guildID, userID, heartbeatKey string, age time.Duration) bool {
+	// Deleting the heartbeat first keeps another check from failing it twice
+	if err := f.cache.Delete(ctx, heartbeatKey); err != nil {
+		f.logger.Warn("failed to delete stale session heartbeat", "error", err, "key", heartbeatKey)
+		return false
+	}
+
+	sessionKey := fmt.Sprintf("%s%s:%s", sessionKeyPrefix, guildID, userID)
+	var session activeSession
+	if err := f.cache.GetJSON(ctx, sessionKey, &session); err != nil {
+		// The session ended; only its heartbeat was left behind
+		return false
+	}
+
+	f.logger.Error("onboarding session heartbeat stale, failing session",
+		"guild_id", guildID,
+		"user_id", userID,
+		"slave_id", session.SlaveID,
+		"last_beat", age.Round(time.Second),
+	)
+
+	if session.SlaveID != "" {
+		if err := f.setSlaveStatus(ctx, session.SlaveID, SlaveStatusAvailable); err != nil {
+			f.logger.Warn("failed to mark slave as available", "error", err, "slave_id", session.SlaveID)
+		}
+	}
+	if err := f.cache.Delete(ctx, sessionKey); err != nil {
+		f.logger.Warn("failed to clear session", "error", err, "user_id", userID)
+	}
+	f.releaseGuildSlot(ctx, guildID)
+
+	errMsg := fmt.Sprintf("worker %s stopped responding (no heartbeat for %s)", session.SlaveID, age.Round(time.Second))
+	if err := f.notifyStaff(ctx, guildID, userID, fmt.Sprintf("step%d", session.CurrentStep), errMsg); err != nil {
+		f.logger.Warn("failed to notify staff of stale session", "error", err, "guild_id", guildID)
+	}
+	return true
+}
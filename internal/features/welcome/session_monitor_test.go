@@ -0,0 +1,52 @@
+package welcome
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"welcomebot/internal/fakes"
+	"welcomebot/internal/onboard"
+)
+
+func TestCheckSessionHeartbeats(t *testing.T) {
+	ctx := context.Background()
+	cache := fakes.NewCache()
+	f := &Feature{cache: cache, logger: fakes.Logger{}, i18n: fakes.I18n{}, staffThrottle: newNotifyThrottle(staffNotifyLimit, staffNotifyWindow)}
+	now := time.Unix(1700000000, 0)
+
+	_ = cache.SetJSON(ctx, cacheKeyPrefix+"guild-1", &WelcomeConfig{GuildID: "guild-1"}, 0)
+	_ = cache.Set(ctx, guildSessionsKeyPrefix+"guild-1", "2", 0)
+	_ = cache.Set(ctx, slaveStatusKey+"slave-1", string(SlaveStatusBusy), 0)
+	beat := func(userID string, at time.Time) {
+		_ = cache.Set(ctx, onboard.SessionHeartbeatKey("guild-1", userID), strconv.FormatInt(at.Unix(), 10), 0)
+	}
+
+	// A dead worker, a live one, and a heartbeat left after its session ended
+	_ = cache.SetJSON(ctx, sessionKeyPrefix+"guild-1:dead", activeSession{UserID: "dead", SlaveID: "slave-1", CurrentStep: 4}, 0)
+	beat("dead", now.Add(-5*time.Minute))
+	_ = cache.SetJSON(ctx, sessionKeyPrefix+"guild-1:alive", activeSession{UserID: "alive", SlaveID: "slave-2"}, 0)
+	beat("alive", now.Add(-10*time.Second))
+	beat("ended", now.Add(-5*time.Minute))
+
+	if failed := f.checkSessionHeartbeats(ctx, now, DefaultSessionHeartbeatStale); failed != 1 {
+		t.Fatalf("expected one failed session, got %d", failed)
+	}
+
+	if exists, _ := cache.Exists(ctx, sessionKeyPrefix+"guild-1:dead"); exists {
+		t.Error("expected the dead session to be cleared")
+	}
+	if status, _ := cache.Get(ctx, slaveStatusKey+"slave-1"); status != string(SlaveStatusAvailable) {
+		t.Errorf("expected slave-1 to be available, got %q", status)
+	}
+	if active, _ := cache.Get(ctx, guildSessionsKeyPrefix+"guild-1"); active != "1" {
+		t.Errorf("expected the guild slot to be released, got %q", active)
+	}
+	if exists, _ := cache.Exists(ctx, sessionKeyPrefix+"guild-1:alive"); !exists {
+		t.Error("expected the live session to be kept")
+	}
+	if exists, _ := cache.Exists(ctx, onboard.SessionHeartbeatKey("guild-1", "ended")); exists {
+		t.Error("expected the leftover heartbeat to be removed")
+	}
+}
@@ -0,0 +1,109 @@
+package welcome
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"welcomebot/internal/core/discord"
+	"welcomebot/internal/shared"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/lib/pq"
+)
+
+// maxSpreadCategories caps the categories sharing new VCs with the VC
+// category.
+const maxSpreadCategories = 5
+
+// saveSpreadCategories stores the categories that take new onboarding VCs
+// in turn with the VC category. The VC category, overflow categories and
+// duplicates are dropped.
+func (f *Feature) saveSpreadCategories(ctx context.Context, config *WelcomeConfig, categoryIDs []string) error {
+	exclude := append([]string{config.VCCategoryID}, config.OverflowCategoryIDs...)
+	spread := categorySelection(categoryIDs, exclude, maxSpreadCategories)
+
+	_, err := f.db.Exec(ctx, `
+		UPDATE guild_welcome_config
+		SET spread_category_ids = $1, updated_at = NOW()
+		WHERE guild_id = $2
+	`, pq.Array(spread), config.GuildID)
+	if err != nil {
+		return fmt.Errorf("save spread categories: %w", err)
+	}
+	config.SpreadCategoryIDs = spread
+
+	if err := f.cache.Delete(ctx, cacheKeyPrefix+config.GuildID); err != nil {
+		f.logger.Warn("failed to invalidate welcome config", "error", err, "guild_id", config.GuildID)
+	}
+	return nil
+}
+
+// showSpreadCategories shows the categories that share new VCs with the VC
+// category, with a menu to change them.
+func (f *Feature) showSpreadCategories(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	guildID := i.GuildID
+
+	config, err := f.getWelcomeConfig(ctx, guildID)
+	if err != nil {
+		return f.respondErrorMessage(ctx, s, i, guildID, "welcome.config_not_found")
+	}
+
+	return respond(s, i, f.spreadCategoriesEmbed(ctx, config), f.spreadCategoriesComponents(ctx, config))
+}
+
+// handleSpreadCategorySelection saves the selected spread categories.
+func (f *Feature) handleSpreadCategorySelection(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	guildID := i.GuildID
+
+	config, err := f.getWelcomeConfig(ctx, guildID)
+	if err != nil {
+		return f.respondErrorMessage(ctx, s, i, guildID, "welcome.config_not_found")
+	}
+
+	if err := f.saveSpreadCategories(ctx, config, i.MessageComponentData().Values); err != nil {
+		return f.respondError(ctx, s, i, guildID, err)
+	}
+
+	f.logger.Info("spread categories updated",
+		"guild_id", guildID,
+		"category_ids", config.SpreadCategoryIDs,
+	)
+
+	return respond(s, i, f.spreadCategoriesEmbed(ctx, config), f.spreadCategoriesComponents(ctx, config))
+}
+
+func (f *Feature) spreadCategoriesEmbed(ctx context.Context, config *WelcomeConfig) *discordgo.MessageEmbed {
+	guildID := config.GuildID
+
+	current := f.i18n.T(ctx, guildID, "welcome.spread_categories_none")
+	if len(config.SpreadCategoryIDs) > 0 {
+		mentions := make([]string, 0, len(config.SpreadCategoryIDs)+1)
+		for _, id := range append([]string{config.VCCategoryID}, config.SpreadCategoryIDs...) {
+			mentions = append(mentions, fmt.Sprintf("• <#%s>", id))
+		}
+		current = strings.Join(mentions, "\n")
+	}
+
+	return &discordgo.MessageEmbed{
+		Title: f.i18n.T(ctx, guildID, "welcome.spread_categories_title"),
+		Description: f.i18n.TWithArgs(ctx, guildID, "welcome.spread_categories_description", map[string]string{
+			"category": fmt.Sprintf("<#%s>", config.VCCategoryID),
+		}) + "\n\n" + current,
+		Color: int(shared.ColorInfo),
+	}
+}
+
+func (f *Feature) spreadCategoriesComponents(ctx context.Context, config *WelcomeConfig) []discordgo.MessageComponent {
+	// Clearing the menu goes back to the VC category alone
+	menu := discord.ChannelSelectMenu("welcome:spread_categories:select",
+		f.i18n.T(ctx, config.GuildID, "welcome.select_spread_categories"),
+		[]discordgo.ChannelType{discordgo.ChannelTypeGuildCategory}, config.SpreadCategoryIDs...)
+	minValues := 0
+	menu.MinValues = &minValues
+	menu.MaxValues = maxSpreadCategories
+
+	return []discordgo.MessageComponent{
+		discordgo.ActionsRow{Components: []discordgo.MessageComponent{menu}},
+	}
+}
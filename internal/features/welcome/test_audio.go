@@ -0,0 +1,239 @@
+package welcome
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"welcomebot/internal/onboard"
+	"welcomebot/internal/shared"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// testAudioTimeout bounds a test playback, including the voice join.
+const testAudioTimeout = 10 * time.Minute
+
+// showTestAudioGuides asks which guide to test. The admin must already be
+// in a voice channel, since that is where the file will be played.
+func (f *Feature) showTestAudioGuides(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	guildID := i.GuildID
+
+	if f.newPlayer == nil {
+		return f.respondTestAudio(ctx, s, i, "welcome.test_audio_unavailable", shared.ColorWarning, nil)
+	}
+	if adminVoiceChannel(s, i) == "" {
+		return f.respondTestAudio(ctx, s, i, "welcome.test_audio_join_voice", shared.ColorWarning, nil)
+	}
+
+	guides, err := onboard.ListGuides()
+	if err != nil {
+		f.logger.Warn("failed to list guides", "error", err)
+	}
+	if len(guides) == 0 {
+		return f.respondTestAudio(ctx, s, i, "welcome.test_audio_no_guides", shared.ColorWarning, nil)
+	}
+
+	options := make([]discordgo.SelectMenuOption, 0, len(guides))
+	for _, guide := range guides {
+		options = append(options, discordgo.SelectMenuOption{
+			Label: f.i18n.T(ctx, guildID, fmt.Sprintf("onboarding.guides.%s.name", guide)),
+			Value: guide,
+		})
+	}
+
+	return f.respondTestAudio(ctx, s, i, "welcome.test_audio_choose_guide", shared.ColorInfo, []discordgo.MessageComponent{
+		discordgo.ActionsRow{
+			Components: []discordgo.MessageComponent{
+				discordgo.SelectMenu{
+					MenuType:    discordgo.StringSelectMenu,
+					CustomID:    "welcome:test_audio:guide",
+					Placeholder: f.i18n.T(ctx, guildID, "welcome.test_audio_guide_placeholder"),
+					Options:     options,
+				},
+			},
+		},
+	})
+}
+
+// handleTestAudioGuide asks which of the guide's files to play. Only files
+// present on disk are offered.
+func (f *Feature) handleTestAudioGuide(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	guildID := i.GuildID
+
+	guide := selectedValue(i)
+	if !isGuide(guide) {
+		return f.respondTestAudio(ctx, s, i, "welcome.test_audio_no_guides", shared.ColorWarning, nil)
+	}
+
+	manifest, err := onboard.LoadManifest(guide)
+	if err != nil {
+		f.logger.Warn("failed to load guide manifest, using default audio names", "guide", guide, "error", err)
+	}
+
+	var options []discordgo.SelectMenuOption
+	for _, key := range onboard.AudioKeys {
+		file := manifest.AudioFile(key)
+		if _, err := os.Stat(onboard.AudioPath(guide, file)); err != nil {
+			continue
+		}
+		options = append(options, discordgo.SelectMenuOption{
+			Label:       key,
+			Description: file,
+			Value:       key,
+		})
+	}
+	if len(options) == 0 {
+		return f.respondTestAudio(ctx, s, i, "welcome.test_audio_no_files", shared.ColorWarning, nil)
+	}
+
+	return f.respondTestAudio(ctx, s, i, "welcome.test_audio_choose_step", shared.ColorInfo, []discordgo.MessageComponent{
+		discordgo.ActionsRow{
+			Components: []discordgo.MessageComponent{
+				discordgo.SelectMenu{
+					MenuType:    discordgo.StringSelectMenu,
+					CustomID:    "welcome:test_audio:step:" + guide,
+					Placeholder: f.i18n.T(ctx, guildID, "welcome.test_audio_step_placeholder"),
+					Options:     options,
+				},
+			},
+		},
+	})
+}
+
+// handleTestAudioStep plays the chosen file in the admin's voice channel.
+// Playback runs in the background; a failure is reported as a follow-up.
+func (f *Feature) handleTestAudioStep(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	guildID := i.GuildID
+	guide := strings.TrimPrefix(extractCustomID(i), "welcome:test_audio:step:")
+	key := selectedValue(i)
+
+	if !isGuide(guide) || !slices.Contains(onboard.AudioKeys, key) {
+		return f.respondTestAudio(ctx, s, i, "welcome.test_audio_no_files", shared.ColorWarning, nil)
+	}
+	if f.newPlayer == nil {
+		return f.respondTestAudio(ctx, s, i, "welcome.test_audio_unavailable", shared.ColorWarning, nil)
+	}
+
+	channelID := adminVoiceChannel(s, i)
+	if channelID == "" {
+		return f.respondTestAudio(ctx, s, i, "welcome.test_audio_join_voice", shared.ColorWarning, nil)
+	}
+
+	// The bot has one voice connection per guild
+	if !f.claimTestAudio(guildID) {
+		return f.respondTestAudio(ctx, s, i, "welcome.test_audio_busy", shared.ColorWarning, nil)
+	}
+
+	manifest, err := onboard.LoadManifest(guide)
+	if err != nil {
+		f.logger.Warn("failed to load guide manifest, using default audio names", "guide", guide, "error", err)
+	}
+	file := manifest.AudioFile(key)
+
+	embed := &discordgo.MessageEmbed{
+		Title: f.i18n.T(ctx, guildID, "welcome.test_audio_title"),
+		Description: f.i18n.TWithArgs(ctx, guildID, "welcome.test_audio_playing", map[string]string{
+			"file":    guide + "/" + file,
+			"channel": fmt.Sprintf("<#%s>", channelID),
+		}),
+		Color: int(shared.ColorSuccess),
+	}
+	if err := respond(s, i, embed, []discordgo.MessageComponent{}); err != nil {
+		f.releaseTestAudio(guildID)
+		return err
+	}
+
+	go func() {
+		defer f.releaseTestAudio(guildID)
+
+		playCtx, cancel := context.WithTimeout(context.Background(), testAudioTimeout)
+		defer cancel()
+
+		player := f.newPlayer(playCtx, s)
+		if err := onboard.PlayOnce(playCtx, player, guildID, channelID, guide, file); err != nil {
+			f.logger.Warn("test audio failed",
+				"error", err,
+				"guild_id", guildID,
+				"guide", guide,
+				"file", file,
+			)
+			_, _ = s.FollowupMessageCreate(i.Interaction, true, &discordgo.WebhookParams{
+				Content: f.i18n.T(playCtx, guildID, "welcome.test_audio_failed"),
+				Flags:   discordgo.MessageFlagsEphemeral,
+			})
+			return
+		}
+
+		f.logger.Info("test audio played", "guild_id", guildID, "guide", guide, "file", file)
+	}()
+
+	return nil
+}
+
+// claimTestAudio reserves the guild's voice connection for a test playback.
+func (f *Feature) claimTestAudio(guildID string) bool {
+	f.testAudioMu.Lock()
+	defer f.testAudioMu.Unlock()
+
+	if f.testAudioGuilds[guildID] {
+		return false
+	}
+	f.testAudioGuilds[guildID] = true
+	return true
+}
+
+// releaseTestAudio frees the guild for another test playback.
+func (f *Feature) releaseTestAudio(guildID string) {
+	f.testAudioMu.Lock()
+	defer f.testAudioMu.Unlock()
+
+	delete(f.testAudioGuilds, guildID)
+}
+
+// respondTestAudio shows a test audio prompt or notice in place of the menu.
+func (f *Feature) respondTestAudio(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate, messageKey string, color shared.EmbedColor, components []discordgo.MessageComponent) error {
+	embed := &discordgo.MessageEmbed{
+		Title:       f.i18n.T(ctx, i.GuildID, "welcome.test_audio_title"),
+		Description: f.i18n.T(ctx, i.GuildID, messageKey),
+		Color:       int(color),
+	}
+	if components == nil {
+		components = []discordgo.MessageComponent{}
+	}
+	return respond(s, i, embed, components)
+}
+
+// adminVoiceChannel returns the voice channel the interacting member is
+// in, or "" if they are not in one.
+func adminVoiceChannel(s *discordgo.Session, i *discordgo.InteractionCreate) string {
+	if i.Member == nil || i.Member.User == nil {
+		return ""
+	}
+	state, err := s.State.VoiceState(i.GuildID, i.Member.User.ID)
+	if err != nil || state == nil {
+		return ""
+	}
+	return state.ChannelID
+}
+
+// isGuide reports whether name is an installed guide. It also keeps
+// user-supplied values from escaping the audio directory.
+func isGuide(name string) bool {
+	guides, err := onboard.ListGuides()
+	return err == nil && slices.Contains(guides, name)
+}
+
+// selectedValue returns the first value chosen in a select menu.
+func selectedValue(i *discordgo.InteractionCreate) string {
+	if i.Type != discordgo.InteractionMessageComponent {
+		return ""
+	}
+	if values := i.MessageComponentData().Values; len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
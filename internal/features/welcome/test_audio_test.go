@@ -0,0 +1,49 @@
+package welcome
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"welcomebot/internal/fakes"
+)
+
+func TestClaimTestAudio(t *testing.T) {
+	f := &Feature{testAudioGuilds: make(map[string]bool)}
+
+	if !f.claimTestAudio("guild-1") {
+		t.Fatal("expected first claim to succeed")
+	}
+	if f.claimTestAudio("guild-1") {
+		t.Error("expected second claim in the same guild to fail")
+	}
+	if !f.claimTestAudio("guild-2") {
+		t.Error("expected another guild to be independent")
+	}
+
+	f.releaseTestAudio("guild-1")
+	if !f.claimTestAudio("guild-1") {
+		t.Error("expected claim to succeed after release")
+	}
+}
+
+func TestIsGuideRejectsPaths(t *testing.T) {
+	for _, name := range []string{"", "..", "../etc", "kk/../.."} {
+		if isGuide(name) {
+			t.Errorf("isGuide(%q) = true, want false", name)
+		}
+	}
+}
+
+func TestTestAudioUnavailableWithoutPlayer(t *testing.T) {
+	d := fakes.NewDiscord()
+	f := &Feature{i18n: fakes.I18n{}, logger: fakes.Logger{}}
+
+	if err := f.showTestAudioGuides(context.Background(), d.Session(), wizardSelect("menu:welcome:test_audio")); err != nil {
+		t.Fatalf("showTestAudioGuides: %v", err)
+	}
+	requests := d.Requests()
+	if len(requests) != 1 || !bytes.Contains(requests[0].Body, []byte("welcome.test_audio_unavailable")) {
+		t.Errorf("expected the tool reported unavailable, got %v", requests)
+	}
+}
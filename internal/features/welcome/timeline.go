@@ -0,0 +1,144 @@
+package welcome
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"welcomebot/internal/onboard"
+	"welcomebot/internal/shared"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// maxTimelineLength keeps the rendered timeline inside an embed description.
+const maxTimelineLength = 3800
+
+// sessionTimeline is one row of session_timelines.
+type sessionTimeline struct {
+	SlaveID   string
+	Guide     string
+	Outcome   string
+	StartedAt time.Time
+	EndedAt   time.Time
+	Events    []onboard.TimelineEvent
+}
+
+// showTimelineModal asks for the user whose latest session to show.
+func (f *Feature) showTimelineModal(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	guildID := i.GuildID
+
+	return s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseModal,
+		Data: &discordgo.InteractionResponseData{
+			CustomID: "welcome:timeline:modal",
+			Title:    f.i18n.T(ctx, guildID, "welcome.timeline_modal_title"),
+			Components: []discordgo.MessageComponent{
+				discordgo.ActionsRow{
+					Components: []discordgo.MessageComponent{
+						discordgo.TextInput{
+							CustomID:  "user_id",
+							Label:     f.i18n.T(ctx, guildID, "welcome.timeline_user_label"),
+							Style:     discordgo.TextInputShort,
+							Required:  true,
+							MinLength: 15,
+							MaxLength: 20,
+						},
+					},
+				},
+			},
+		},
+	})
+}
+
+// handleTimelineSubmit shows the user's most recent session timeline.
+func (f *Feature) handleTimelineSubmit(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	guildID := i.GuildID
+	userID := strings.Trim(strings.TrimSpace(modalValue(i.ModalSubmitData(), "user_id")), "<@!>")
+
+	timeline, err := f.latestTimeline(ctx, guildID, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return f.respondErrorMessage(ctx, s, i, guildID, "welcome.timeline_not_found")
+	}
+	if err != nil {
+		return f.respondError(ctx, s, i, guildID, err)
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Title: f.i18n.TWithArgs(ctx, guildID, "welcome.timeline_title", map[string]string{
+			"user": userID,
+		}),
+		Description: "```\n" + formatTimeline(timeline) + "\n```",
+		Color:       int(shared.ColorInfo),
+		Fields: []*discordgo.MessageEmbedField{
+			{Name: f.i18n.T(ctx, guildID, "welcome.timeline_outcome"), Value: timeline.Outcome, Inline: true},
+			{Name: f.i18n.T(ctx, guildID, "welcome.timeline_guide"), Value: orDash(timeline.Guide), Inline: true},
+			{Name: f.i18n.T(ctx, guildID, "welcome.timeline_slave"), Value: orDash(timeline.SlaveID), Inline: true},
+		},
+		Timestamp: timeline.StartedAt.Format(time.RFC3339),
+	}
+
+	return s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Embeds: []*discordgo.MessageEmbed{embed},
+			Flags:  discordgo.MessageFlagsEphemeral,
+		},
+	})
+}
+
+// latestTimeline loads the user's most recently ended session.
+func (f *Feature) latestTimeline(ctx context.Context, guildID, userID string) (*sessionTimeline, error) {
+	query := `
+		SELECT slave_id, guide, outcome, started_at, ended_at, events
+		FROM session_timelines
+		WHERE guild_id = $1 AND user_id = $2
+		ORDER BY ended_at DESC
+		LIMIT 1
+	`
+
+	var timeline sessionTimeline
+	var slaveID, guide sql.NullString
+	var events []byte
+	err := f.db.QueryRow(ctx, query, guildID, userID).Scan(
+		&slaveID, &guide, &timeline.Outcome, &timeline.StartedAt, &timeline.EndedAt, &events)
+	if err != nil {
+		return nil, err
+	}
+	timeline.SlaveID = slaveID.String
+	timeline.Guide = guide.String
+
+	if err := json.Unmarshal(events, &timeline.Events); err != nil {
+		return nil, fmt.Errorf("decode timeline events: %w", err)
+	}
+	return &timeline, nil
+}
+
+// formatTimeline renders one line per event with its offset from the start.
+// The oldest events are dropped if the result would not fit in an embed.
+func formatTimeline(timeline *sessionTimeline) string {
+	lines := make([]string, 0, len(timeline.Events)+1)
+	for _, event := range timeline.Events {
+		offset := event.At.Sub(timeline.StartedAt).Round(time.Second)
+		lines = append(lines, fmt.Sprintf("+%-6s %-5s %s", offset, event.Kind, event.Detail))
+	}
+	lines = append(lines, fmt.Sprintf("+%-6s end   %s", timeline.EndedAt.Sub(timeline.StartedAt).Round(time.Second), timeline.Outcome))
+
+	out := strings.Join(lines, "\n")
+	for len(out) > maxTimelineLength && len(lines) > 1 {
+		lines = lines[1:]
+		out = "…\n" + strings.Join(lines, "\n")
+	}
+	return out
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
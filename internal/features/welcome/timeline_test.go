@@ -0,0 +1,52 @@
+package welcome
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"welcomebot/internal/onboard"
+)
+
+func TestFormatTimeline(t *testing.T) {
+	start := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	timeline := &sessionTimeline{
+		Outcome:   "completed",
+		StartedAt: start,
+		EndedAt:   start.Add(5 * time.Minute),
+		Events: []onboard.TimelineEvent{
+			{At: start.Add(2 * time.Second), Kind: "step", Detail: "step1"},
+			{At: start.Add(90 * time.Second), Kind: "click", Detail: "onboarding:step1_next:123"},
+		},
+	}
+
+	lines := strings.Split(formatTimeline(timeline), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("expected 3 lines, got %d: %q", len(lines), lines)
+	}
+	if !strings.HasPrefix(lines[0], "+2s") || !strings.HasSuffix(lines[0], "step1") {
+		t.Errorf("unexpected first line %q", lines[0])
+	}
+	if !strings.HasPrefix(lines[1], "+1m30s") {
+		t.Errorf("unexpected second line %q", lines[1])
+	}
+	if !strings.HasPrefix(lines[2], "+5m0s") || !strings.HasSuffix(lines[2], "completed") {
+		t.Errorf("unexpected last line %q", lines[2])
+	}
+}
+
+func TestFormatTimelineDropsOldestEvents(t *testing.T) {
+	start := time.Now()
+	timeline := &sessionTimeline{Outcome: "failed", StartedAt: start, EndedAt: start}
+	for n := 0; n < 500; n++ {
+		timeline.Events = append(timeline.Events, onboard.TimelineEvent{At: start, Kind: "click", Detail: strings.Repeat("x", 40)})
+	}
+
+	out := formatTimeline(timeline)
+	if len(out) > maxTimelineLength {
+		t.Errorf("expected at most %d characters, got %d", maxTimelineLength, len(out))
+	}
+	if !strings.HasPrefix(out, "…") || !strings.HasSuffix(out, "failed") {
+		t.Error("expected oldest events to be dropped and the outcome kept")
+	}
+}
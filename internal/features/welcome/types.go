@@ -5,9 +5,63 @@ import "time"
 const (
 	cacheKeyPrefix = "welcomebot:config:"
 	slaveStatusKey = "welcomebot:slaves:status:"
+	slaveUserKey   = "welcomebot:slaves:user:" // Slave ID -> bot user ID, set by the worker
 	sessionKeyPrefix = "welcomebot:session:"
+	vcSeqKeyPrefix   = "welcomebot:vc_seq:"
+
+	// Role configs are cached by the features that own them; onboarding
+	// reads the same keys so a start needs no database round trip.
+	ageRangeCacheKeyPrefix   = "welcomebot:agerange:config:"
+	genderCacheKeyPrefix     = "welcomebot:gender:"
+	voiceTypeCacheKeyPrefix  = "welcomebot:voicetype:config:"
+	otherRolesCacheKeyPrefix = "welcomebot:otherroles:config:"
+
+	// guideRolesKeyPrefix caches a guild's per-guide completion roles.
+	guideRolesKeyPrefix = "welcomebot:guide_roles:"
+
+	// guildSessionsKeyPrefix counts a guild's active sessions. Workers
+	// decrement it when a session ends.
+	guildSessionsKeyPrefix = "welcomebot:guild_sessions:"
+	// guildSessionsTTL lets a counter leaked by a crashed worker reset once
+	// the guild has been quiet for a full session timeout.
+	guildSessionsTTL = 60 * time.Minute
+
+	// attemptsKeyPrefix counts a member's onboarding starts. The counter
+	// expires one attempt window after the first start.
+	attemptsKeyPrefix = "welcomebot:onboard_attempts:"
+
+	// startClickKeyPrefix collapses a member's repeat welcome button
+	// clicks. startLockKeyPrefix is held while a start for them runs.
+	startClickKeyPrefix = "welcomebot:start_click:"
+	startLockKeyPrefix  = "welcomebot:start_lock:"
+	// slaveClaimKeyPrefix reserves an available slave for one start until
+	// the start marks it busy.
+	slaveClaimKeyPrefix = "welcomebot:slaves:claim:"
 )
 
+// startClickWindow is how long repeat welcome button clicks count as one.
+// startLockTTL and slaveClaimTTL free a lock or claim left by a master
+// that stopped mid-start.
+const (
+	startClickWindow = 3 * time.Second
+	startLockTTL     = 30 * time.Second
+	slaveClaimTTL    = 30 * time.Second
+)
+
+// DefaultMaxSessionsPerGuild leaves room for several guilds on a full fleet.
+const DefaultMaxSessionsPerGuild = 10
+
+// DefaultMaxAttemptsPerUser and DefaultAttemptWindow allow a few restarts
+// a day, enough for a dropped connection or two.
+const (
+	DefaultMaxAttemptsPerUser = 3
+	DefaultAttemptWindow      = 24 * time.Hour
+)
+
+// DefaultWizardTTL gives an admin time to look up roles mid-setup without
+// keeping abandoned wizards around for long.
+const DefaultWizardTTL = 30 * time.Minute
+
 // WelcomeConfig represents welcome configuration for a guild.
 type WelcomeConfig struct {
 	GuildID             string    `json:"guild_id"`
@@ -23,6 +77,27 @@ type WelcomeConfig struct {
 	Setsumeikai3RoleID  string    `json:"setsumeikai_3_role_id,omitempty"`
 	MemberRoleID        string    `json:"member_role_id,omitempty"`
 	VisitorRoleID       string    `json:"visitor_role_id,omitempty"`
+	StaffNotifyChannelID string   `json:"staff_notify_channel_id,omitempty"`
+	StaffRoleID         string    `json:"staff_role_id,omitempty"`
+	VCNameTemplate      string    `json:"vc_name_template,omitempty"`
+	EphemeralConfirmations bool   `json:"ephemeral_confirmations,omitempty"`
+	SharedVCChannelID   string    `json:"shared_vc_channel_id,omitempty"` // Onboard everyone in this VC instead of private ones
+	SpreadCategoryIDs   []string  `json:"spread_category_ids,omitempty"`   // Take new VCs in turn with VCCategoryID
+	InitialRoleID       string    `json:"initial_role_id,omitempty"`       // Given on join, removed on completion
+	OverflowCategoryIDs []string  `json:"overflow_category_ids,omitempty"` // Tried in order when VCCategoryID is full
+	GreetingGuide       string    `json:"greeting_guide,omitempty"`       // Guide whose welcome audio plays on join; empty for silence
+	TextOnly            bool      `json:"text_only,omitempty"`            // audio_enabled is off: no voice, a private text channel instead
+	Schedule            string    `json:"schedule,omitempty"`             // Onboarding hours, see ParseSchedule; empty for always open
+	WelcomeBack         bool      `json:"welcome_back,omitempty"`         // Offer returning members their previous roles instead of onboarding
+	VCAccessRoleIDs     []string  `json:"vc_access_role_ids,omitempty"`   // Roles also let into onboarding channels, e.g. staff
+	VCAccessUserIDs     []string  `json:"vc_access_user_ids,omitempty"`   // Members also let into onboarding channels
+	SyncCategoryPermissions bool  `json:"sync_category_permissions,omitempty"` // Onboarding channels keep their category's permissions instead of hiding from @everyone
+	CustomCompletionRoles   bool     `json:"custom_completion_roles,omitempty"`    // Completion grants and revokes the two lists below instead of the defaults
+	CompletionGrantRoleIDs  []string `json:"completion_grant_role_ids,omitempty"`  // Granted on completion instead of the visitor and member roles
+	CompletionRevokeRoleIDs []string `json:"completion_revoke_role_ids,omitempty"` // Revoked on completion instead of the 説明会 roles
+	SessionLocales      []string  `json:"session_locales,omitempty"`      // Languages members pick from before guide selection; fewer than two skips the step
+	RolePanelChannelID  string    `json:"role_panel_channel_id,omitempty"` // Where the role panel is posted; empty for none
+	RolePanelMessageID  string    `json:"role_panel_message_id,omitempty"`
 	CreatedAt           time.Time `json:"created_at"`
 	UpdatedAt           time.Time `json:"updated_at"`
 }
@@ -64,6 +139,24 @@ type WizardState struct {
 	Setsumeikai3RoleID  string `json:"setsumeikai_3_role_id"`
 	MemberRoleID        string `json:"member_role_id"`
 	VisitorRoleID       string `json:"visitor_role_id"`
+	StaffNotifyChannelID string `json:"staff_notify_channel_id"`
+	StaffRoleID         string `json:"staff_role_id"`
+	VCNameTemplate      string `json:"vc_name_template"`
+	EphemeralConfirmations bool `json:"ephemeral_confirmations"`
+	SharedVCChannelID   string `json:"shared_vc_channel_id"`
+	SpreadCategoryIDs   []string `json:"spread_category_ids"`
+	InitialRoleID       string   `json:"initial_role_id"`
+	OverflowCategoryIDs []string `json:"overflow_category_ids"`
+	GreetingGuide       string `json:"greeting_guide"`
+	TextOnly            bool   `json:"text_only"`
+	Schedule            string `json:"schedule"`
+	WelcomeBack         bool   `json:"welcome_back"`
+	VCAccessRoleIDs     []string `json:"vc_access_role_ids"`
+	VCAccessUserIDs     []string `json:"vc_access_user_ids"`
+	CustomCompletionRoles   bool     `json:"custom_completion_roles"`
+	CompletionGrantRoleIDs  []string `json:"completion_grant_role_ids"`
+	CompletionRevokeRoleIDs []string `json:"completion_revoke_role_ids"`
+	SessionLocales      []string `json:"session_locales"`
 	CurrentStep         int    `json:"current_step"`
 }
 
@@ -83,6 +176,13 @@ type AgeRangeConfig struct {
 	Age40LateRoleID  string `json:"age_40_late_role_id,omitempty"`
 }
 
+// GuideCompletionRoles overrides the roles a guide grants on completion.
+// An empty role falls back to the guild's member or visitor role.
+type GuideCompletionRoles struct {
+	MemberRoleID  string `json:"member_role_id,omitempty"`
+	VisitorRoleID string `json:"visitor_role_id,omitempty"`
+}
+
 // GenderConfig represents gender role configuration for a guild.
 type GenderConfig struct {
 	GuildID      string `json:"guild_id"`
@@ -100,6 +200,15 @@ type VoiceTypeConfig struct {
 	LowRoleID     string `json:"low_role_id,omitempty"`
 }
 
+// SelfIntroConfig represents self-introduction channel configuration for a guild.
+type SelfIntroConfig struct {
+	GuildID         string `json:"guild_id"`
+	MaleChannelID   string `json:"male_channel_id,omitempty"`
+	FemaleChannelID string `json:"female_channel_id,omitempty"`
+	PostTitle       string `json:"post_title,omitempty"`
+	PostColor       int    `json:"post_color,omitempty"`
+}
+
 // OtherRolesConfig represents other roles configuration for a guild.
 type OtherRolesConfig struct {
 	GuildID                string `json:"guild_id"`
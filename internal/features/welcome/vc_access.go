@@ -0,0 +1,190 @@
+package welcome
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"welcomebot/internal/core/discord"
+	"welcomebot/internal/shared"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/lib/pq"
+)
+
+// Custom IDs of the VC access panel.
+const (
+	vcAccessRolesID  = "welcome:vc_access:roles"
+	vcAccessUsersID  = "welcome:vc_access:users"
+	vcSyncCategoryID = "welcome:vc_access:sync_category"
+)
+
+// maxVCAccess caps the roles, and separately the members, let into
+// onboarding channels. Each one is a permission overwrite on every channel.
+const maxVCAccess = 10
+
+// saveVCAccess stores the roles and members let into onboarding channels
+// besides the member and the bot. @everyone and duplicates are dropped, so
+// the channels stay private to everyone else.
+func (f *Feature) saveVCAccess(ctx context.Context, config *WelcomeConfig, roleIDs, userIDs []string) error {
+	// categorySelection's cleanup works for any IDs
+	roles := categorySelection(roleIDs, []string{config.GuildID}, maxVCAccess)
+	users := categorySelection(userIDs, nil, maxVCAccess)
+
+	_, err := f.db.Exec(ctx, `
+		UPDATE guild_welcome_config
+		SET vc_access_role_ids = $1, vc_access_user_ids = $2, updated_at = NOW()
+		WHERE guild_id = $3
+	`, pq.Array(roles), pq.Array(users), config.GuildID)
+	if err != nil {
+		return fmt.Errorf("save vc access: %w", err)
+	}
+	config.VCAccessRoleIDs = roles
+	config.VCAccessUserIDs = users
+
+	if err := f.cache.Delete(ctx, cacheKeyPrefix+config.GuildID); err != nil {
+		f.logger.Warn("failed to invalidate welcome config", "error", err, "guild_id", config.GuildID)
+	}
+	return nil
+}
+
+// showVCAccess shows who besides the member can join onboarding channels,
+// with menus to change it.
+func (f *Feature) showVCAccess(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	guildID := i.GuildID
+
+	config, err := f.getWelcomeConfig(ctx, guildID)
+	if err != nil {
+		return f.respondErrorMessage(ctx, s, i, guildID, "welcome.config_not_found")
+	}
+
+	return respond(s, i, f.vcAccessEmbed(ctx, config), f.vcAccessComponents(ctx, config))
+}
+
+// handleVCAccessSelection saves the roles or members picked in one of the
+// panel's menus, keeping the other list.
+func (f *Feature) handleVCAccessSelection(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate, customID string) error {
+	guildID := i.GuildID
+
+	config, err := f.getWelcomeConfig(ctx, guildID)
+	if err != nil {
+		return f.respondErrorMessage(ctx, s, i, guildID, "welcome.config_not_found")
+	}
+
+	roles, users := config.VCAccessRoleIDs, config.VCAccessUserIDs
+	if customID == vcAccessRolesID {
+		roles = i.MessageComponentData().Values
+	} else {
+		users = i.MessageComponentData().Values
+	}
+	if err := f.saveVCAccess(ctx, config, roles, users); err != nil {
+		return f.respondError(ctx, s, i, guildID, err)
+	}
+
+	f.logger.Info("onboarding vc access updated",
+		"guild_id", guildID,
+		"role_ids", config.VCAccessRoleIDs,
+		"user_ids", config.VCAccessUserIDs,
+	)
+
+	return respond(s, i, f.vcAccessEmbed(ctx, config), f.vcAccessComponents(ctx, config))
+}
+
+// saveSyncCategory sets whether onboarding channels keep their category's
+// permissions. Synced channels are no longer hidden from @everyone, so
+// anyone who can see the category can see them.
+func (f *Feature) saveSyncCategory(ctx context.Context, config *WelcomeConfig, sync bool) error {
+	_, err := f.db.Exec(ctx, `
+		UPDATE guild_welcome_config
+		SET sync_category_permissions = $1, updated_at = NOW()
+		WHERE guild_id = $2
+	`, sync, config.GuildID)
+	if err != nil {
+		return fmt.Errorf("save sync category permissions: %w", err)
+	}
+	config.SyncCategoryPermissions = sync
+
+	if err := f.cache.Delete(ctx, cacheKeyPrefix+config.GuildID); err != nil {
+		f.logger.Warn("failed to invalidate welcome config", "error", err, "guild_id", config.GuildID)
+	}
+	return nil
+}
+
+// toggleSyncCategory switches onboarding channels between private and
+// synced to their category, and redraws the panel.
+func (f *Feature) toggleSyncCategory(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	guildID := i.GuildID
+
+	config, err := f.getWelcomeConfig(ctx, guildID)
+	if err != nil {
+		return f.respondErrorMessage(ctx, s, i, guildID, "welcome.config_not_found")
+	}
+
+	if err := f.saveSyncCategory(ctx, config, !config.SyncCategoryPermissions); err != nil {
+		return f.respondError(ctx, s, i, guildID, err)
+	}
+
+	f.logger.Info("onboarding channel permissions updated", "guild_id", guildID, "sync_category", config.SyncCategoryPermissions)
+
+	return respond(s, i, f.vcAccessEmbed(ctx, config), f.vcAccessComponents(ctx, config))
+}
+
+func (f *Feature) vcAccessEmbed(ctx context.Context, config *WelcomeConfig) *discordgo.MessageEmbed {
+	guildID := config.GuildID
+
+	var mentions []string
+	for _, id := range config.VCAccessRoleIDs {
+		mentions = append(mentions, fmt.Sprintf("<@&%s>", id))
+	}
+	for _, id := range config.VCAccessUserIDs {
+		mentions = append(mentions, fmt.Sprintf("<@%s>", id))
+	}
+	current := f.i18n.T(ctx, guildID, "welcome.vc_access_none")
+	if len(mentions) > 0 {
+		current = strings.Join(mentions, "\n")
+	}
+
+	mode := f.i18n.T(ctx, guildID, "welcome.vc_private")
+	if config.SyncCategoryPermissions {
+		mode = f.i18n.T(ctx, guildID, "welcome.vc_synced")
+	}
+
+	return &discordgo.MessageEmbed{
+		Title:       f.i18n.T(ctx, guildID, "welcome.vc_access_title"),
+		Description: f.i18n.T(ctx, guildID, "welcome.vc_access_description") + "\n\n" + current + "\n\n" + mode,
+		Color:       int(shared.ColorInfo),
+	}
+}
+
+func (f *Feature) vcAccessComponents(ctx context.Context, config *WelcomeConfig) []discordgo.MessageComponent {
+	// Clearing a menu removes everyone it lists
+	minValues := 0
+	roles := discord.RoleSelectMenu(vcAccessRolesID,
+		f.i18n.T(ctx, config.GuildID, "welcome.select_vc_access_roles"), config.VCAccessRoleIDs...)
+	roles.MinValues = &minValues
+	roles.MaxValues = maxVCAccess
+
+	users := discord.UserSelectMenu(vcAccessUsersID,
+		f.i18n.T(ctx, config.GuildID, "welcome.select_vc_access_users"), config.VCAccessUserIDs...)
+	users.MinValues = &minValues
+	users.MaxValues = maxVCAccess
+
+	label, style := "welcome.vc_sync_enable", discordgo.DangerButton
+	if config.SyncCategoryPermissions {
+		label, style = "welcome.vc_sync_disable", discordgo.SuccessButton
+	}
+
+	return []discordgo.MessageComponent{
+		discordgo.ActionsRow{Components: []discordgo.MessageComponent{roles}},
+		discordgo.ActionsRow{Components: []discordgo.MessageComponent{users}},
+		discordgo.ActionsRow{
+			Components: []discordgo.MessageComponent{
+				discordgo.Button{
+					Label:    f.i18n.T(ctx, config.GuildID, label),
+					Style:    style,
+					CustomID: vcSyncCategoryID,
+				},
+			},
+		},
+	}
+}
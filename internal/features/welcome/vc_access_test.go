@@ -0,0 +1,73 @@
+package welcome
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"welcomebot/internal/fakes"
+)
+
+func TestSaveVCAccess(t *testing.T) {
+	ctx := context.Background()
+	cache := fakes.NewCache()
+	db := fakes.NewDB()
+	f := &Feature{cache: cache, db: db, logger: fakes.Logger{}}
+
+	config := &WelcomeConfig{GuildID: "guild-1"}
+	if err := cache.SetJSON(ctx, cacheKeyPrefix+"guild-1", config, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	// @everyone would open every channel to the guild
+	if err := f.saveVCAccess(ctx, config, []string{"role-staff", "guild-1", "role-staff"}, []string{"user-1"}); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	if got := fmt.Sprint(config.VCAccessRoleIDs, config.VCAccessUserIDs); got != "[role-staff] [user-1]" {
+		t.Errorf("vc access = %s, want [role-staff] [user-1]", got)
+	}
+	if exists, _ := cache.Exists(ctx, cacheKeyPrefix+"guild-1"); exists {
+		t.Error("expected the cached config to be invalidated")
+	}
+	if execs := db.Execs(); len(execs) != 1 {
+		t.Errorf("expected one update, got %+v", execs)
+	}
+
+	onboarding := onboardingConfig(config, nil, nil, nil, nil, nil)
+	if got := fmt.Sprint(onboarding.VCAccessRoles, onboarding.VCAccessUsers); got != "[role-staff] [user-1]" {
+		t.Errorf("payload vc access = %s, want [role-staff] [user-1]", got)
+	}
+}
+
+func TestToggleSyncCategory(t *testing.T) {
+	ctx := context.Background()
+	db := fakes.NewDB()
+	cache := fakes.NewCache()
+	f := &Feature{db: db, cache: cache, i18n: fakes.I18n{}, logger: fakes.Logger{}}
+	if err := cache.SetJSON(ctx, cacheKeyPrefix+"guild-1", &WelcomeConfig{GuildID: "guild-1"}, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	d := fakes.NewDiscord()
+	if err := f.HandleInteraction(ctx, d.Session(), wizardSelect(vcSyncCategoryID)); err != nil {
+		t.Fatalf("HandleInteraction: %v", err)
+	}
+
+	execs := db.Execs()
+	if len(execs) != 1 || !strings.Contains(execs[0].Query, "sync_category_permissions") || execs[0].Args[0] != true {
+		t.Errorf("expected syncing saved on, got %+v", execs)
+	}
+	requests := d.Requests()
+	if body := string(requests[len(requests)-1].Body); !strings.Contains(body, "welcome.vc_synced") {
+		t.Errorf("expected the panel redrawn as synced, got %s", body)
+	}
+	if exists, _ := cache.Exists(ctx, cacheKeyPrefix+"guild-1"); exists {
+		t.Error("expected the cached config to be invalidated")
+	}
+
+	onboarding := onboardingConfig(&WelcomeConfig{GuildID: "guild-1", SyncCategoryPermissions: true}, nil, nil, nil, nil, nil)
+	if !onboarding.SyncCategoryPermissions {
+		t.Error("expected the payload to ask for synced channels")
+	}
+}
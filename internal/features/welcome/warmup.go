@@ -0,0 +1,153 @@
+package welcome
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"welcomebot/internal/core/database"
+)
+
+// warmEntry is one config to put in the cache.
+type warmEntry struct {
+	key   string
+	value interface{}
+}
+
+// WarmCache loads every guild's onboarding configs into the cache so the
+// first start after a deploy doesn't wait on the database. Each table is
+// read with one query; at most concurrency cache writes run at a time.
+// Keys that are already cached are left alone, so a config saved while
+// the warm-up runs is never replaced by the older row. It returns the
+// number of configs cached.
+func (f *Feature) WarmCache(ctx context.Context, concurrency int) (int, error) {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	loads := []struct {
+		table string
+		load  func(context.Context) ([]warmEntry, error)
+	}{
+		{"guild_welcome_config", f.loadWelcomeConfigs},
+		{"guild_age_range_config", f.loadAgeRangeConfigs},
+		{"guild_gender_roles", f.loadGenderConfigs},
+		{"guild_voice_type_config", f.loadVoiceTypeConfigs},
+		{"guild_other_roles_config", f.loadOtherRolesConfigs},
+	}
+
+	var entries []warmEntry
+	for _, l := range loads {
+		loaded, err := l.load(database.ReadFromReplica(ctx))
+		if err != nil {
+			return 0, fmt.Errorf("load %s: %w", l.table, err)
+		}
+		entries = append(entries, loaded...)
+	}
+
+	return f.cacheEntries(ctx, entries, concurrency)
+}
+
+// cacheEntries writes entries that aren't cached yet, at most concurrency
+// at a time, and returns how many it wrote.
+func (f *Feature) cacheEntries(ctx context.Context, entries []warmEntry, concurrency int) (int, error) {
+	var (
+		wg     sync.WaitGroup
+		mu     sync.Mutex
+		cached int
+		sem    = make(chan struct{}, concurrency)
+	)
+	for _, entry := range entries {
+		if ctx.Err() != nil {
+			break
+		}
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(entry warmEntry) {
+			defer func() { <-sem; wg.Done() }()
+
+			set, err := f.cache.SetNXJSON(ctx, entry.key, entry.value, database.ReplicaCacheTTL)
+			if err != nil {
+				f.logger.Warn("failed to warm config", "key", entry.key, "error", err)
+				return
+			}
+			if set {
+				mu.Lock()
+				cached++
+				mu.Unlock()
+			}
+		}(entry)
+	}
+	wg.Wait()
+
+	return cached, ctx.Err()
+}
+
+func (f *Feature) loadWelcomeConfigs(ctx context.Context) ([]warmEntry, error) {
+	return loadConfigs(ctx, f.db, welcomeConfigQuery, func(row rowScanner) (warmEntry, error) {
+		config, err := scanWelcomeConfig(row)
+		if err != nil {
+			return warmEntry{}, err
+		}
+		return warmEntry{cacheKeyPrefix + config.GuildID, config}, nil
+	})
+}
+
+func (f *Feature) loadAgeRangeConfigs(ctx context.Context) ([]warmEntry, error) {
+	return loadConfigs(ctx, f.db, ageRangeConfigQuery, func(row rowScanner) (warmEntry, error) {
+		config, err := scanAgeRangeConfig(row)
+		if err != nil {
+			return warmEntry{}, err
+		}
+		return warmEntry{ageRangeCacheKeyPrefix + config.GuildID, config}, nil
+	})
+}
+
+func (f *Feature) loadGenderConfigs(ctx context.Context) ([]warmEntry, error) {
+	return loadConfigs(ctx, f.db, genderConfigQuery, func(row rowScanner) (warmEntry, error) {
+		config, err := scanGenderConfig(row)
+		if err != nil {
+			return warmEntry{}, err
+		}
+		return warmEntry{genderCacheKeyPrefix + config.GuildID, config}, nil
+	})
+}
+
+func (f *Feature) loadVoiceTypeConfigs(ctx context.Context) ([]warmEntry, error) {
+	return loadConfigs(ctx, f.db, voiceTypeConfigQuery, func(row rowScanner) (warmEntry, error) {
+		config, err := scanVoiceTypeConfig(row)
+		if err != nil {
+			return warmEntry{}, err
+		}
+		return warmEntry{voiceTypeCacheKeyPrefix + config.GuildID, config}, nil
+	})
+}
+
+func (f *Feature) loadOtherRolesConfigs(ctx context.Context) ([]warmEntry, error) {
+	return loadConfigs(ctx, f.db, otherRolesConfigQuery, func(row rowScanner) (warmEntry, error) {
+		config, err := scanOtherRolesConfig(row)
+		if err != nil {
+			return warmEntry{}, err
+		}
+		return warmEntry{otherRolesCacheKeyPrefix + config.GuildID, config}, nil
+	})
+}
+
+// loadConfigs runs query and scans every row it returns.
+func loadConfigs(ctx context.Context, db database.Client, query string, scan func(rowScanner) (warmEntry, error)) ([]warmEntry, error) {
+	rows, err := db.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []warmEntry
+	for rows.Next() {
+		entry, err := scan(rows)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	return entries, rows.Err()
+}
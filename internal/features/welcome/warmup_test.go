@@ -0,0 +1,56 @@
+package welcome
+
+import (
+	"context"
+	"testing"
+
+	"welcomebot/internal/fakes"
+)
+
+func TestCacheEntries(t *testing.T) {
+	ctx := context.Background()
+	cache := fakes.NewCache()
+	f := &Feature{cache: cache, db: fakes.NewDB(), logger: fakes.Logger{}}
+
+	// Saved while the warm-up ran; must not be replaced by the older row
+	fresh := &AgeRangeConfig{GuildID: "guild-1", Age20EarlyRoleID: "role-new"}
+	if err := cache.SetJSON(ctx, ageRangeCacheKeyPrefix+"guild-1", fresh, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	entries := []warmEntry{
+		{ageRangeCacheKeyPrefix + "guild-1", &AgeRangeConfig{GuildID: "guild-1", Age20EarlyRoleID: "role-old"}},
+		{genderCacheKeyPrefix + "guild-1", &GenderConfig{GuildID: "guild-1", MaleRoleID: "role-male"}},
+		{cacheKeyPrefix + "guild-2", &WelcomeConfig{GuildID: "guild-2", WelcomeChannelID: "channel-1"}},
+	}
+	cached, err := f.cacheEntries(ctx, entries, 2)
+	if err != nil {
+		t.Fatalf("cacheEntries: %v", err)
+	}
+	if cached != 2 {
+		t.Errorf("expected 2 configs cached, got %d", cached)
+	}
+
+	ageRange, err := f.getAgeRangeConfig(ctx, "guild-1")
+	if err != nil || ageRange.Age20EarlyRoleID != "role-new" {
+		t.Errorf("expected the fresher age range config, got %+v (%v)", ageRange, err)
+	}
+
+	// Onboarding reads the warmed keys without touching the database
+	gender, err := f.getGenderConfig(ctx, "guild-1")
+	if err != nil || gender.MaleRoleID != "role-male" {
+		t.Errorf("expected the warmed gender config, got %+v (%v)", gender, err)
+	}
+	config, err := f.getWelcomeConfig(ctx, "guild-2")
+	if err != nil || config.WelcomeChannelID != "channel-1" {
+		t.Errorf("expected the warmed welcome config, got %+v (%v)", config, err)
+	}
+}
+
+func TestWarmCacheQueryError(t *testing.T) {
+	f := &Feature{cache: fakes.NewCache(), db: fakes.NewDB(), logger: fakes.Logger{}}
+
+	if _, err := f.WarmCache(context.Background(), 4); err == nil {
+		t.Error("expected the failed query to be reported")
+	}
+}
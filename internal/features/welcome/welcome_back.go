@@ -0,0 +1,252 @@
+package welcome
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"welcomebot/internal/core/discord"
+	"welcomebot/internal/onboard"
+	"welcomebot/internal/shared"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// Custom IDs of the welcome back prompt's buttons.
+const (
+	welcomeBackRestoreID = "welcome:welcome_back:restore"
+	welcomeBackOnboardID = "welcome:welcome_back:onboard"
+)
+
+// returningMember returns the choices a member made the last time they
+// completed onboarding, or nil if they never did. Welcome back is a
+// convenience, so read errors send the member through onboarding.
+func (f *Feature) returningMember(ctx context.Context, guildID, userID string) *onboardingSummary {
+	summary, err := f.loadOnboardingSummary(ctx, guildID, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		f.logger.Warn("failed to load previous onboarding", "error", err, "guild_id", guildID, "user_id", userID)
+		return nil
+	}
+	return summary
+}
+
+// showWelcomeBackPrompt offers a returning member their previous roles in
+// one click, or onboarding from the start. Like the resume prompt, it
+// replies with a new ephemeral message.
+func (f *Feature) showWelcomeBackPrompt(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate, summary *onboardingSummary) error {
+	guildID := i.GuildID
+
+	embed := &discordgo.MessageEmbed{
+		Title: f.i18n.T(ctx, guildID, "welcome.welcome_back_title"),
+		Description: f.i18n.TWithArgs(ctx, guildID, "welcome.welcome_back_description", map[string]string{
+			"date": fmt.Sprintf("<t:%d:D>", summary.CompletedAt.Unix()),
+		}),
+		Color: int(shared.ColorInfo),
+	}
+
+	components := []discordgo.MessageComponent{
+		discordgo.ActionsRow{
+			Components: []discordgo.MessageComponent{
+				discordgo.Button{
+					Label:    f.i18n.T(ctx, guildID, "welcome.welcome_back_restore_button"),
+					Style:    discordgo.PrimaryButton,
+					CustomID: welcomeBackRestoreID,
+				},
+				discordgo.Button{
+					Label:    f.i18n.T(ctx, guildID, "welcome.welcome_back_onboard_button"),
+					Style:    discordgo.SecondaryButton,
+					CustomID: welcomeBackOnboardID,
+				},
+			},
+		},
+	}
+
+	return s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Embeds:     []*discordgo.MessageEmbed{embed},
+			Components: components,
+			Flags:      discordgo.MessageFlagsEphemeral,
+		},
+	})
+}
+
+// handleWelcomeBackChoice gives a returning member their previous roles,
+// or starts onboarding. If welcome back was turned off or the record is
+// gone while the prompt was open, the member is onboarded as well.
+func (f *Feature) handleWelcomeBackChoice(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate, customID string) error {
+	guildID := i.GuildID
+	userID := i.Member.User.ID
+
+	if customID == welcomeBackOnboardID {
+		return f.startOnboarding(ctx, s, i, userID, nil)
+	}
+
+	config, err := f.getWelcomeConfig(ctx, guildID)
+	if err != nil {
+		return f.respondErrorMessage(ctx, s, i, guildID, "welcome.config_not_found")
+	}
+	var summary *onboardingSummary
+	if config.WelcomeBack {
+		summary = f.returningMember(ctx, guildID, userID)
+	}
+	if summary == nil {
+		return f.startOnboarding(ctx, s, i, userID, nil)
+	}
+	if f.sessionActive(ctx, guildID, userID) {
+		return f.respondErrorMessage(ctx, s, i, guildID, "welcome.session_already_active")
+	}
+
+	// Each role is its own request; answer before Discord's deadline
+	if err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredMessageUpdate,
+	}); err != nil {
+		return fmt.Errorf("defer welcome back response: %w", err)
+	}
+
+	failed := f.restoreRoles(ctx, s, guildID, userID, f.loadOnboardingConfig(ctx, config, nil), summary)
+
+	f.logger.Info("returning member welcomed back",
+		"guild_id", guildID,
+		"user_id", userID,
+		"guide", summary.Guide,
+		"failed_roles", failed,
+	)
+
+	embed := &discordgo.MessageEmbed{
+		Title:       f.i18n.T(ctx, guildID, "welcome.welcome_back_title"),
+		Description: f.i18n.T(ctx, guildID, "welcome.welcome_back_done"),
+		Color:       int(shared.ColorSuccess),
+	}
+	if failed > 0 {
+		embed.Description = f.i18n.T(ctx, guildID, "welcome.welcome_back_partial")
+		embed.Color = int(shared.ColorWarning)
+	}
+
+	components := []discordgo.MessageComponent{}
+	_, err = s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+		Embeds:     &[]*discordgo.MessageEmbed{embed},
+		Components: &components,
+	})
+	return err
+}
+
+// restoreRoles gives the member the roles their last completed onboarding
+// left them with and takes away the onboarding flow roles. It returns how
+// many role changes failed; the rest are still made.
+func (f *Feature) restoreRoles(ctx context.Context, s *discordgo.Session, guildID, userID string, onboarding onboard.OnboardingConfig, summary *onboardingSummary) int {
+	add, remove := onboarding.ReturnRoles(summary.Guide, summary.Selections)
+	return f.changeMemberRoles(ctx, s, guildID, userID, add, remove)
+}
+
+// changeMemberRoles adds and removes the member's roles, retrying each
+// change. It returns how many changes failed; the rest are still made.
+func (f *Feature) changeMemberRoles(ctx context.Context, s *discordgo.Session, guildID, userID string, add, remove []string) int {
+	failed := 0
+	for _, roleID := range add {
+		err := discord.Retry(ctx, f.logger, "add role", discord.DefaultRetryPolicy, func() error {
+			return s.GuildMemberRoleAdd(guildID, userID, roleID)
+		})
+		if err != nil {
+			f.logger.Warn("failed to add role", "error", err, "guild_id", guildID, "user_id", userID, "role_id", roleID)
+			failed++
+		}
+	}
+	for _, roleID := range remove {
+		err := discord.Retry(ctx, f.logger, "remove role", discord.DefaultRetryPolicy, func() error {
+			return s.GuildMemberRoleRemove(guildID, userID, roleID)
+		})
+		if err != nil {
+			f.logger.Warn("failed to remove role", "error", err, "guild_id", guildID, "user_id", userID, "role_id", roleID)
+			failed++
+		}
+	}
+	return failed
+}
+
+// saveWelcomeBack turns the welcome back flow on or off.
+func (f *Feature) saveWelcomeBack(ctx context.Context, config *WelcomeConfig, enabled bool) error {
+	_, err := f.db.Exec(ctx, `
+		UPDATE guild_welcome_config
+		SET welcome_back_enabled = $1, updated_at = NOW()
+		WHERE guild_id = $2
+	`, enabled, config.GuildID)
+	if err != nil {
+		return fmt.Errorf("save welcome back: %w", err)
+	}
+	config.WelcomeBack = enabled
+
+	if err := f.cache.Delete(ctx, cacheKeyPrefix+config.GuildID); err != nil {
+		f.logger.Warn("failed to invalidate welcome config", "error", err, "guild_id", config.GuildID)
+	}
+	return nil
+}
+
+// showWelcomeBack shows whether returning members are welcomed back, with
+// a button to switch it.
+func (f *Feature) showWelcomeBack(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	guildID := i.GuildID
+
+	config, err := f.getWelcomeConfig(ctx, guildID)
+	if err != nil {
+		return f.respondErrorMessage(ctx, s, i, guildID, "welcome.config_not_found")
+	}
+
+	return respond(s, i, f.welcomeBackEmbed(ctx, config), f.welcomeBackComponents(ctx, config))
+}
+
+// toggleWelcomeBack switches the welcome back flow and redraws its panel.
+func (f *Feature) toggleWelcomeBack(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) error {
+	guildID := i.GuildID
+
+	config, err := f.getWelcomeConfig(ctx, guildID)
+	if err != nil {
+		return f.respondErrorMessage(ctx, s, i, guildID, "welcome.config_not_found")
+	}
+
+	if err := f.saveWelcomeBack(ctx, config, !config.WelcomeBack); err != nil {
+		return f.respondError(ctx, s, i, guildID, err)
+	}
+
+	f.logger.Info("welcome back updated", "guild_id", guildID, "enabled", config.WelcomeBack)
+
+	return respond(s, i, f.welcomeBackEmbed(ctx, config), f.welcomeBackComponents(ctx, config))
+}
+
+func (f *Feature) welcomeBackEmbed(ctx context.Context, config *WelcomeConfig) *discordgo.MessageEmbed {
+	guildID := config.GuildID
+
+	status := f.i18n.T(ctx, guildID, "welcome.welcome_back_off")
+	if config.WelcomeBack {
+		status = f.i18n.T(ctx, guildID, "welcome.welcome_back_on")
+	}
+
+	return &discordgo.MessageEmbed{
+		Title:       f.i18n.T(ctx, guildID, "welcome.welcome_back_settings_title"),
+		Description: f.i18n.T(ctx, guildID, "welcome.welcome_back_settings_description") + "\n\n" + status,
+		Color:       int(shared.ColorInfo),
+	}
+}
+
+func (f *Feature) welcomeBackComponents(ctx context.Context, config *WelcomeConfig) []discordgo.MessageComponent {
+	label, style := "welcome.welcome_back_enable", discordgo.SuccessButton
+	if config.WelcomeBack {
+		label, style = "welcome.welcome_back_disable", discordgo.DangerButton
+	}
+
+	return []discordgo.MessageComponent{
+		discordgo.ActionsRow{
+			Components: []discordgo.MessageComponent{
+				discordgo.Button{
+					Label:    f.i18n.T(ctx, config.GuildID, label),
+					Style:    style,
+					CustomID: "welcome:welcome_back:toggle",
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,80 @@
+package welcome
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	"welcomebot/internal/fakes"
+	"welcomebot/internal/onboard"
+)
+
+func TestRestoreRoles(t *testing.T) {
+	d := fakes.NewDiscord()
+	d.SetMemberRoles("guild-1", "user-1", "role-initial")
+	d.SetNotFound("guilds/guild-1/members/user-1/roles/role-deleted")
+	f := &Feature{logger: fakes.Logger{}}
+
+	onboarding := onboard.OnboardingConfig{
+		MaleRole:     "role-male",
+		LowVoiceRole: "role-deleted",
+		MemberRole:   "role-member",
+		InitialRole:  "role-initial",
+	}
+	summary := &onboardingSummary{Guide: "kk", Selections: onboard.Selections{"gender": {"male"}, "voice": {"low"}}}
+
+	if failed := f.restoreRoles(context.Background(), d.Session(), "guild-1", "user-1", onboarding, summary); failed != 1 {
+		t.Errorf("expected the deleted role to fail, got %d failures", failed)
+	}
+	if got, want := d.MemberRoles("guild-1", "user-1"), []string{"role-male", "role-member"}; !slices.Equal(got, want) {
+		t.Errorf("expected roles %v, got %v", want, got)
+	}
+}
+
+func TestToggleWelcomeBack(t *testing.T) {
+	ctx := context.Background()
+	db := fakes.NewDB()
+	cache := fakes.NewCache()
+	f := &Feature{db: db, cache: cache, i18n: fakes.I18n{}, logger: fakes.Logger{}}
+	if err := cache.SetJSON(ctx, cacheKeyPrefix+"guild-1", &WelcomeConfig{GuildID: "guild-1"}, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	d := fakes.NewDiscord()
+	if err := f.HandleInteraction(ctx, d.Session(), wizardSelect("welcome:welcome_back:toggle")); err != nil {
+		t.Fatalf("HandleInteraction: %v", err)
+	}
+
+	execs := db.Execs()
+	if len(execs) != 1 || !strings.Contains(execs[0].Query, "welcome_back_enabled") || execs[0].Args[0] != true {
+		t.Errorf("expected welcome back saved on, got %+v", execs)
+	}
+	requests := d.Requests()
+	if body := string(requests[len(requests)-1].Body); !strings.Contains(body, "welcome.welcome_back_on") {
+		t.Errorf("expected the panel redrawn as on, got %s", body)
+	}
+	if exists, _ := cache.Exists(ctx, cacheKeyPrefix+"guild-1"); exists {
+		t.Error("expected the cached config to be invalidated")
+	}
+}
+
+func TestWelcomeBackPrompt(t *testing.T) {
+	d := fakes.NewDiscord()
+	f := &Feature{i18n: fakes.I18n{}, logger: fakes.Logger{}}
+
+	i := wizardSelect(welcomeButtonID)
+	if err := f.showWelcomeBackPrompt(context.Background(), d.Session(), i, &onboardingSummary{Guide: "kk"}); err != nil {
+		t.Fatal(err)
+	}
+
+	body := string(d.Requests()[0].Body)
+	for _, id := range []string{welcomeBackRestoreID, welcomeBackOnboardID} {
+		if !strings.Contains(body, id) {
+			t.Errorf("expected a %s button, got %s", id, body)
+		}
+	}
+	if !strings.Contains(body, `"flags":64`) {
+		t.Errorf("expected an ephemeral prompt, got %s", body)
+	}
+}
@@ -0,0 +1,183 @@
+package welcome
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"welcomebot/internal/bot"
+	"welcomebot/internal/core/cache"
+	"welcomebot/internal/fakes"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func wizardSelect(customID string, values ...string) *discordgo.InteractionCreate {
+	return &discordgo.InteractionCreate{Interaction: &discordgo.Interaction{
+		ID: "interaction-1", Token: "token", GuildID: "guild-1",
+		Type:   discordgo.InteractionMessageComponent,
+		Member: &discordgo.Member{User: &discordgo.User{ID: "admin-1"}},
+		Data:   discordgo.MessageComponentInteractionData{CustomID: customID, Values: values},
+	}}
+}
+
+func TestResetWizardState(t *testing.T) {
+	ctx := context.Background()
+	f := &Feature{cache: fakes.NewCache(), logger: fakes.Logger{}}
+
+	leftover := &WizardState{GuildID: "guild-1", CurrentStep: 7, EntranceRoleID: "role-old"}
+	if err := f.saveWizardState(ctx, leftover); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := f.resetWizardState(ctx, &WizardState{GuildID: "guild-1", CurrentStep: 1}); err != nil {
+		t.Fatalf("resetWizardState: %v", err)
+	}
+	state, err := f.getWizardState(ctx, "guild-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state.CurrentStep != 1 || state.EntranceRoleID != "" {
+		t.Errorf("expected a clean state on step 1, got %+v", state)
+	}
+}
+
+func TestWizardRejectsOutdatedPanels(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name  string
+		state *WizardState
+		want  string
+	}{
+		{"expired", nil, "welcome.wizard_expired"},
+		{"restarted", &WizardState{GuildID: "guild-1", CurrentStep: 1}, "welcome.wizard_outdated"},
+		{"moved on", &WizardState{GuildID: "guild-1", CurrentStep: 5}, "welcome.wizard_outdated"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := fakes.NewDiscord()
+			f := &Feature{cache: fakes.NewCache(), i18n: fakes.I18n{}, logger: fakes.Logger{}}
+			if tt.state != nil {
+				if err := f.saveWizardState(ctx, tt.state); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			// A step 3 panel
+			if err := f.HandleInteraction(ctx, d.Session(), wizardSelect("welcome:entrance_role:select", "role-1")); err != nil {
+				t.Fatalf("HandleInteraction: %v", err)
+			}
+
+			requests := d.Requests()
+			last := requests[len(requests)-1]
+			if !strings.Contains(string(last.Body), tt.want) {
+				t.Errorf("expected %s, got %s", tt.want, last.Body)
+			}
+			if state, err := f.getWizardState(ctx, "guild-1"); err == nil && (state.EntranceRoleID != "" || state.CurrentStep != tt.state.CurrentStep) {
+				t.Errorf("expected the wizard state to be left alone, got %+v", state)
+			}
+		})
+	}
+}
+
+func TestWizardStepOneStartsAfterExpiry(t *testing.T) {
+	ctx := context.Background()
+	d := fakes.NewDiscord()
+	f := &Feature{cache: fakes.NewCache(), i18n: fakes.I18n{}, logger: fakes.Logger{}}
+
+	if err := f.HandleInteraction(ctx, d.Session(), wizardSelect("welcome:channel:select", "channel-1")); err != nil {
+		t.Fatalf("HandleInteraction: %v", err)
+	}
+
+	state, err := f.getWizardState(ctx, "guild-1")
+	if err != nil {
+		t.Fatalf("expected a new wizard state: %v", err)
+	}
+	if state.WelcomeChannelID != "channel-1" || state.CurrentStep != 2 {
+		t.Errorf("expected the channel saved and step 2 next, got %+v", state)
+	}
+}
+
+// unreadableCache fails every read as if Redis were down.
+type unreadableCache struct {
+	*fakes.Cache
+}
+
+func (unreadableCache) GetJSON(ctx context.Context, key string, dest interface{}) error {
+	return cache.ErrUnavailable
+}
+
+func TestWizardCacheErrorIsNotExpiry(t *testing.T) {
+	ctx := context.Background()
+	d := fakes.NewDiscord()
+	f := &Feature{cache: unreadableCache{fakes.NewCache()}, i18n: fakes.I18n{}, logger: fakes.Logger{}}
+
+	err := f.HandleInteraction(ctx, d.Session(), wizardSelect("welcome:entrance_role:select", "role-1"))
+	if !errors.Is(err, cache.ErrUnavailable) {
+		t.Fatalf("expected the cache error, got %v", err)
+	}
+	if len(d.Requests()) != 0 {
+		t.Errorf("expected no expired reply, got %+v", d.Requests())
+	}
+}
+
+func TestSaveWelcomeConfigDropsCache(t *testing.T) {
+	ctx := context.Background()
+	cache := fakes.NewCache()
+	db := fakes.NewDB()
+	f := &Feature{cache: cache, db: db, logger: fakes.Logger{}}
+
+	// The role panel isn't part of the wizard, so a cached copy of what
+	// the wizard saved would lose it
+	cached := &WelcomeConfig{GuildID: "guild-1", RolePanelChannelID: "channel-roles", RolePanelMessageID: "message-1"}
+	if err := cache.SetJSON(ctx, cacheKeyPrefix+"guild-1", cached, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := f.saveWelcomeConfig(ctx, &WelcomeConfig{GuildID: "guild-1", WelcomeChannelID: "channel-welcome"}); err != nil {
+		t.Fatalf("saveWelcomeConfig: %v", err)
+	}
+	if len(db.Execs()) != 1 {
+		t.Errorf("expected the config saved, got %d statements", len(db.Execs()))
+	}
+	if exists, _ := cache.Exists(ctx, cacheKeyPrefix+"guild-1"); exists {
+		t.Error("expected the cached config dropped rather than replaced")
+	}
+}
+
+func TestSeedWizardStateCoversEverySetting(t *testing.T) {
+	// Fill every setting, so a setting WelcomeConfig can't carry over fails the test
+	want := WizardState{}
+	v := reflect.ValueOf(&want).Elem()
+	for n := 0; n < v.NumField(); n++ {
+		switch field := v.Field(n); field.Kind() {
+		case reflect.String:
+			field.SetString(v.Type().Field(n).Name)
+		case reflect.Bool:
+			field.SetBool(true)
+		case reflect.Slice:
+			field.Set(reflect.ValueOf([]string{v.Type().Field(n).Name}))
+		}
+	}
+
+	data, err := json.Marshal(want)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var config WelcomeConfig
+	if err := json.Unmarshal(data, &config); err != nil {
+		t.Fatal(err)
+	}
+
+	var got WizardState
+	if err := bot.SeedWizardState(&config, &got); err != nil {
+		t.Fatalf("seed: %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("seeded state = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,63 @@
+package onboard
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// AudioPlayer plays guide audio into a session's voice channel.
+type AudioPlayer interface {
+	// Connect joins the voice channel audio will be played in.
+	Connect(ctx context.Context, guildID, channelID string) error
+
+	// Play starts audio/<guide>/<filename>, replacing anything already playing.
+	// Playback is asynchronous; Play returns once the stream has started.
+	Play(guide, filename string) error
+
+	// Stop stops the current playback, if any.
+	Stop()
+
+	// Replay restarts the most recently played file from the beginning.
+	Replay() error
+
+	// Pause and Resume suspend and continue the current playback.
+	Pause()
+	Resume()
+
+	// Playing reports whether a stream is active (paused counts as active).
+	Playing() bool
+
+	// Disconnect leaves the voice channel.
+	Disconnect(ctx context.Context) error
+}
+
+// playOncePoll is how often PlayOnce checks whether playback has ended.
+var playOncePoll = 250 * time.Millisecond
+
+// PlayOnce joins a voice channel, plays one guide file to the end and
+// leaves. If ctx is done first, playback is stopped and ctx's error is
+// returned.
+func PlayOnce(ctx context.Context, player AudioPlayer, guildID, channelID, guide, filename string) error {
+	if err := player.Connect(ctx, guildID, channelID); err != nil {
+		return fmt.Errorf("connect: %w", err)
+	}
+	defer player.Disconnect(context.Background())
+
+	if err := player.Play(guide, filename); err != nil {
+		return fmt.Errorf("play: %w", err)
+	}
+
+	ticker := time.NewTicker(playOncePoll)
+	defer ticker.Stop()
+
+	for player.Playing() {
+		select {
+		case <-ctx.Done():
+			player.Stop()
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+	return nil
+}
@@ -0,0 +1,23 @@
+package onboard
+
+import (
+	"context"
+	"testing"
+
+	"welcomebot/internal/fakes"
+)
+
+func TestPlayOnce(t *testing.T) {
+	player := &fakes.AudioPlayer{}
+
+	if err := PlayOnce(context.Background(), player, "guild-1", "vc-1", "kk", "1-intro.dca"); err != nil {
+		t.Fatalf("PlayOnce: %v", err)
+	}
+
+	if played := player.Played(); len(played) != 1 || played[0] != "kk/1-intro.dca" {
+		t.Errorf("expected kk/1-intro.dca to be played once, got %v", played)
+	}
+	if ch := player.Channel(); ch != "" {
+		t.Errorf("expected player to leave the channel, still in %q", ch)
+	}
+}
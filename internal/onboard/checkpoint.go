@@ -0,0 +1,28 @@
+package onboard
+
+import "fmt"
+
+// lastResumableStep is the last step a checkpoint can record; completing
+// step 7 completes onboarding, which removes the checkpoint.
+const lastResumableStep = 6
+
+// Checkpoint is how far a member got in an onboarding they didn't finish,
+// kept in member_onboarding_progress so a later session can pick up after
+// the last step they completed.
+type Checkpoint struct {
+	Guide      string     `json:"guide"`
+	Step       int        `json:"step"` // Last completed step (1-6)
+	Selections Selections `json:"selections,omitempty"`
+}
+
+// Validate checks that the checkpoint names a guide and a step to resume
+// after.
+func (c *Checkpoint) Validate() error {
+	if c.Guide == "" {
+		return fmt.Errorf("resume is missing guide")
+	}
+	if c.Step < 1 || c.Step > lastResumableStep {
+		return fmt.Errorf("resume step must be between 1 and %d, got %d", lastResumableStep, c.Step)
+	}
+	return nil
+}
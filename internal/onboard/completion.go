@@ -0,0 +1,36 @@
+package onboard
+
+import "slices"
+
+// CompletionRoles are the roles granted when a member completes onboarding.
+type CompletionRoles struct {
+	MemberRoleID  string `json:"member_role,omitempty"`
+	VisitorRoleID string `json:"visitor_role,omitempty"`
+}
+
+// CompletionChanges are a guild's own roles to grant and revoke when a
+// member completes onboarding. Without Custom, completion grants the
+// visitor and member roles and revokes the 説明会 roles; with it, exactly
+// Grant and Revoke, either of which may be empty.
+type CompletionChanges struct {
+	Custom bool     `json:"custom,omitempty"`
+	Grant  []string `json:"grant,omitempty"`
+	Revoke []string `json:"revoke,omitempty"`
+}
+
+// Roles returns Grant and Revoke without unset roles or duplicates. A
+// role in both is only granted, so completion doesn't take back a role it
+// gave.
+func (c CompletionChanges) Roles() (grant, revoke []string) {
+	for _, roleID := range c.Grant {
+		if roleID != "" && !slices.Contains(grant, roleID) {
+			grant = append(grant, roleID)
+		}
+	}
+	for _, roleID := range c.Revoke {
+		if roleID != "" && !slices.Contains(grant, roleID) && !slices.Contains(revoke, roleID) {
+			revoke = append(revoke, roleID)
+		}
+	}
+	return grant, revoke
+}
@@ -0,0 +1,8 @@
+// Package onboard holds what the master bot and the onboarding workers
+// share about a session.
+//
+// It covers the onboarding_start payload and the guild settings it
+// carries, the checkpoint and step 3 selections kept for a member, the
+// session heartbeat and timeline records, and the guides installed on
+// disk. Features read these without depending on the worker itself.
+package onboard
@@ -0,0 +1,51 @@
+package onboard
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SessionHeartbeatInterval is how often a running session refreshes its
+// heartbeat. Master fails sessions whose heartbeat is a few intervals old.
+const SessionHeartbeatInterval = 30 * time.Second
+
+// sessionHeartbeatSuffix ends a session's heartbeat key, which sits next to
+// the session key: welcomebot:session:<guild>:<user>:hb.
+const sessionHeartbeatSuffix = ":hb"
+
+// SessionHeartbeatKey returns the key a session's heartbeat is written to.
+// Its value is the Unix time of the last beat.
+func SessionHeartbeatKey(guildID, userID string) string {
+	return fmt.Sprintf("welcomebot:session:%s:%s%s", guildID, userID, sessionHeartbeatSuffix)
+}
+
+// ParseSessionHeartbeatKey returns the guild and user of a heartbeat key.
+func ParseSessionHeartbeatKey(key string) (guildID, userID string, ok bool) {
+	rest, found := strings.CutPrefix(key, "welcomebot:session:")
+	if !found {
+		return "", "", false
+	}
+	rest, found = strings.CutSuffix(rest, sessionHeartbeatSuffix)
+	if !found {
+		return "", "", false
+	}
+	guildID, userID, ok = strings.Cut(rest, ":")
+	return guildID, userID, ok && guildID != "" && userID != "" && !strings.Contains(userID, ":")
+}
+
+// IsSessionHeartbeatKey reports whether key is a heartbeat rather than a
+// session, for code that lists the keys under welcomebot:session:.
+func IsSessionHeartbeatKey(key string) bool {
+	return strings.HasSuffix(key, sessionHeartbeatSuffix)
+}
+
+// ParseSessionHeartbeat reads the time of a heartbeat value.
+func ParseSessionHeartbeat(value string) (time.Time, error) {
+	unix, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid session heartbeat %q: %w", value, err)
+	}
+	return time.Unix(unix, 0), nil
+}
@@ -0,0 +1,19 @@
+package onboard
+
+import "testing"
+
+func TestSessionHeartbeatKey(t *testing.T) {
+	key := SessionHeartbeatKey("guild-1", "user-1")
+	if key != "welcomebot:session:guild-1:user-1:hb" {
+		t.Fatalf("unexpected key %s", key)
+	}
+	if guildID, userID, ok := ParseSessionHeartbeatKey(key); !ok || guildID != "guild-1" || userID != "user-1" {
+		t.Errorf("parse: got %s, %s, %v", guildID, userID, ok)
+	}
+
+	for _, key := range []string{"welcomebot:session:guild-1:user-1", "welcomebot:session::user-1:hb", "welcomebot:config:guild-1:hb"} {
+		if _, _, ok := ParseSessionHeartbeatKey(key); ok {
+			t.Errorf("%s should not parse as a heartbeat key", key)
+		}
+	}
+}
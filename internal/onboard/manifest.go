@@ -0,0 +1,226 @@
+package onboard
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+const (
+	audioRoot        = "audio"
+	imageRoot        = "assets/images/onboarding"
+	manifestFileName = "manifest.json"
+)
+
+// Audio keys used by the onboarding flow.
+const (
+	AudioWelcome = "welcome"
+	AudioPreview = "preview"
+	AudioStep1   = "step1"
+	AudioStep2   = "step2"
+	AudioStep3   = "step3"
+	AudioStep4   = "step4"
+	AudioStep5   = "step5"
+	AudioStep6   = "step6"
+	AudioStep7   = "step7"
+)
+
+// AudioKeys lists the audio keys in the order the flow plays them.
+var AudioKeys = []string{
+	AudioWelcome, AudioPreview, AudioStep1, AudioStep2, AudioStep3,
+	AudioStep4, AudioStep5, AudioStep6, AudioStep7,
+}
+
+// defaultAudioFiles is the naming scheme used by the built-in guides.
+var defaultAudioFiles = map[string]string{
+	AudioWelcome: "greeting.dca",
+	AudioPreview: "0-voice-select.dca",
+	AudioStep1:   "1-intro.dca",
+	AudioStep2:   "2-profile.dca",
+	AudioStep3:   "3-role.dca",
+	AudioStep4:   "4-point.dca",
+	AudioStep5:   "5-club.dca",
+	AudioStep6:   "6-membership.dca",
+	AudioStep7:   "7-end.dca",
+}
+
+// defaultStepImages reproduces the images the built-in guides have always
+// sent. Captions are sent as text before their image.
+var defaultStepImages = map[string][]StepImage{
+	AudioStep1: {{File: "step1.png"}},
+	AudioStep2: {{File: "step2.png"}},
+	AudioStep4: {{File: "step4.png"}},
+	AudioStep6: {
+		{File: "step6-1.png"},
+		{File: "step6-2.png", Caption: "onboarding.step6_description_part2"},
+	},
+}
+
+// Manifest describes a guide's assets (audio/<guide>/manifest.json).
+type Manifest struct {
+	// Audio maps an audio key (e.g. "step1") to a filename in the guide directory.
+	Audio map[string]string `json:"audio"`
+
+	// Images maps a step key to the images sent for it, in order. An
+	// empty list sends no images; a missing key uses the defaults.
+	Images map[string][]StepImage `json:"images,omitempty"`
+}
+
+// StepImage is one image sent during a step.
+type StepImage struct {
+	File    string            `json:"file"`              // Path under assets/images/onboarding
+	Caption string            `json:"caption,omitempty"` // Translation key (or literal text) sent before the image
+	Locales map[string]string `json:"locales,omitempty"` // Locale-specific replacements for File
+}
+
+// FileFor returns the image path for a locale, falling back to File.
+func (img StepImage) FileFor(locale string) string {
+	if name, ok := img.Locales[locale]; ok && name != "" {
+		return name
+	}
+	return img.File
+}
+
+// ListGuides returns the guides installed under audio/, sorted by name.
+func ListGuides() ([]string, error) {
+	entries, err := os.ReadDir(audioRoot)
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", audioRoot, err)
+	}
+
+	var guides []string
+	for _, entry := range entries {
+		if entry.IsDir() && !strings.HasPrefix(entry.Name(), ".") {
+			guides = append(guides, entry.Name())
+		}
+	}
+	return guides, nil
+}
+
+// ErrNoGuides is returned when no usable guide is installed, so onboarding
+// can't be offered.
+var ErrNoGuides = errors.New("no onboarding guides are installed")
+
+// UsableGuides returns the installed guides whose manifest can be read,
+// sorted by name. It returns ErrNoGuides when there are none.
+func UsableGuides() ([]string, error) {
+	guides, err := ListGuides()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrNoGuides, err)
+	}
+
+	var usable []string
+	for _, guide := range guides {
+		if _, err := LoadManifest(guide); err == nil {
+			usable = append(usable, guide)
+		}
+	}
+	if len(usable) == 0 {
+		return nil, ErrNoGuides
+	}
+	return usable, nil
+}
+
+// GuideDir returns the directory a guide's audio and manifest live in.
+func GuideDir(guide string) string {
+	return filepath.Join(audioRoot, guide)
+}
+
+// AudioPath returns where a guide's audio file lives on disk.
+func AudioPath(guide, filename string) string {
+	return filepath.Join(audioRoot, guide, filename)
+}
+
+// ImagePath returns where a guide image lives on disk. Image names come
+// from hand-written manifests, so one that would resolve outside
+// assets/images/onboarding, e.g. through "..", is refused.
+func ImagePath(file string) (string, error) {
+	if !filepath.IsLocal(file) {
+		return "", fmt.Errorf("image %q is outside %s", file, imageRoot)
+	}
+	return filepath.Join(imageRoot, file), nil
+}
+
+var (
+	manifestsMu sync.Mutex
+	manifests   = make(map[string]*Manifest)
+)
+
+// LoadManifest returns the manifest for a guide, reading it from disk once.
+// A guide without a manifest gets an empty one so defaults apply.
+func LoadManifest(guide string) (*Manifest, error) {
+	manifestsMu.Lock()
+	defer manifestsMu.Unlock()
+
+	if m, ok := manifests[guide]; ok {
+		return m, nil
+	}
+
+	m, err := ReadManifest(guide)
+	if err != nil {
+		return nil, err
+	}
+
+	manifests[guide] = m
+	return m, nil
+}
+
+// ReadManifest reads a guide's manifest from disk, bypassing LoadManifest's
+// cache.
+func ReadManifest(guide string) (*Manifest, error) {
+	return readManifest(filepath.Join(audioRoot, guide, manifestFileName))
+}
+
+// readManifest parses a manifest file. A missing file is not an error.
+func readManifest(path string) (*Manifest, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return &Manifest{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read manifest %s: %w", path, err)
+	}
+
+	var m Manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("parse manifest %s: %w", path, err)
+	}
+	return &m, nil
+}
+
+// AudioFile returns the filename for an audio key, falling back to the
+// built-in naming scheme when the manifest has no entry.
+func (m *Manifest) AudioFile(key string) string {
+	if m != nil {
+		if name, ok := m.Audio[key]; ok && name != "" {
+			return name
+		}
+	}
+	return defaultAudioFiles[key]
+}
+
+// Key returns the audio key a filename is registered under, or "" if the
+// file is not part of the guide's flow.
+func (m *Manifest) Key(filename string) string {
+	for key := range defaultAudioFiles {
+		if m.AudioFile(key) == filename {
+			return key
+		}
+	}
+	return ""
+}
+
+// StepImages returns the images to send for a step key, falling back to
+// the built-in images when the manifest has no entry.
+func (m *Manifest) StepImages(key string) []StepImage {
+	if m != nil {
+		if images, ok := m.Images[key]; ok {
+			return images
+		}
+	}
+	return defaultStepImages[key]
+}
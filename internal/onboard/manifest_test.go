@@ -0,0 +1,172 @@
+package onboard
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReadManifest_Missing(t *testing.T) {
+	m, err := readManifest(filepath.Join(t.TempDir(), "manifest.json"))
+	if err != nil {
+		t.Fatalf("expected no error for missing manifest, got %v", err)
+	}
+	if got := m.AudioFile(AudioStep1); got != "1-intro.dca" {
+		t.Errorf("expected default step1 audio, got '%s'", got)
+	}
+}
+
+func TestReadManifest_Overrides(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "manifest.json")
+	data := `{"audio": {"step1": "hello.dca", "step2": ""}}`
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatalf("write manifest: %v", err)
+	}
+
+	m, err := readManifest(path)
+	if err != nil {
+		t.Fatalf("read manifest: %v", err)
+	}
+
+	tests := []struct {
+		key  string
+		want string
+	}{
+		{AudioStep1, "hello.dca"},
+		{AudioStep2, "2-profile.dca"}, // empty entry falls back
+		{AudioStep7, "7-end.dca"},     // missing entry falls back
+	}
+	for _, tt := range tests {
+		if got := m.AudioFile(tt.key); got != tt.want {
+			t.Errorf("AudioFile(%q) = '%s', want '%s'", tt.key, got, tt.want)
+		}
+	}
+}
+
+func TestReadManifest_Invalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "manifest.json")
+	if err := os.WriteFile(path, []byte("{not json"), 0o644); err != nil {
+		t.Fatalf("write manifest: %v", err)
+	}
+
+	if _, err := readManifest(path); err == nil {
+		t.Error("expected error for invalid manifest, got nil")
+	}
+}
+
+func TestManifest_NilUsesDefaults(t *testing.T) {
+	var m *Manifest
+	if got := m.AudioFile(AudioPreview); got != "0-voice-select.dca" {
+		t.Errorf("expected default preview audio, got '%s'", got)
+	}
+}
+
+func TestManifest_Key(t *testing.T) {
+	m := &Manifest{Audio: map[string]string{AudioStep1: "welcome.dca"}}
+
+	if got := m.Key("welcome.dca"); got != AudioStep1 {
+		t.Errorf("Key(welcome.dca) = '%s', want '%s'", got, AudioStep1)
+	}
+	if got := m.Key("2-profile.dca"); got != AudioStep2 {
+		t.Errorf("Key(2-profile.dca) = '%s', want '%s'", got, AudioStep2)
+	}
+	if got := m.Key("1-intro.dca"); got != "" {
+		t.Errorf("expected overridden default to be unknown, got '%s'", got)
+	}
+}
+
+func TestReadManifest_Images(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "manifest.json")
+	data := `{"images": {
+		"step2": [
+			{"file": "kk/profile.png", "locales": {"ja": "kk/profile.ja.png"}},
+			{"file": "kk/profile-2.png", "caption": "onboarding.kk_profile_caption"}
+		],
+		"step6": []
+	}}`
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatalf("write manifest: %v", err)
+	}
+
+	m, err := readManifest(path)
+	if err != nil {
+		t.Fatalf("read manifest: %v", err)
+	}
+
+	images := m.StepImages(AudioStep2)
+	if len(images) != 2 {
+		t.Fatalf("expected 2 step2 images, got %d", len(images))
+	}
+	if got := images[0].FileFor("ja"); got != "kk/profile.ja.png" {
+		t.Errorf("expected ja variant, got '%s'", got)
+	}
+	if got := images[0].FileFor("en"); got != "kk/profile.png" {
+		t.Errorf("expected default file for en, got '%s'", got)
+	}
+	if got := images[1].Caption; got != "onboarding.kk_profile_caption" {
+		t.Errorf("expected caption, got '%s'", got)
+	}
+
+	// An explicit empty list disables the step's images
+	if got := m.StepImages(AudioStep6); len(got) != 0 {
+		t.Errorf("expected no step6 images, got %v", got)
+	}
+
+	// Steps missing from the manifest keep the built-in images
+	if got := m.StepImages(AudioStep4); len(got) != 1 || got[0].File != "step4.png" {
+		t.Errorf("expected default step4 image, got %v", got)
+	}
+}
+
+func TestManifestStepImages_Defaults(t *testing.T) {
+	var m *Manifest
+
+	step6 := m.StepImages(AudioStep6)
+	if len(step6) != 2 {
+		t.Fatalf("expected 2 default step6 images, got %d", len(step6))
+	}
+	if step6[1].Caption != "onboarding.step6_description_part2" {
+		t.Errorf("expected part 2 text before the second image, got '%s'", step6[1].Caption)
+	}
+	if got := m.StepImages(AudioStep3); len(got) != 0 {
+		t.Errorf("expected no step3 images, got %v", got)
+	}
+}
+
+func TestUsableGuides(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	if _, err := UsableGuides(); !errors.Is(err, ErrNoGuides) {
+		t.Errorf("expected ErrNoGuides without an audio directory, got %v", err)
+	}
+
+	for _, guide := range []string{"usable-ok", "usable-broken"} {
+		if err := os.MkdirAll(filepath.Join(audioRoot, guide), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	broken := filepath.Join(audioRoot, "usable-broken", manifestFileName)
+	if err := os.WriteFile(broken, []byte("{"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	guides, err := UsableGuides()
+	if err != nil {
+		t.Fatalf("UsableGuides: %v", err)
+	}
+	if len(guides) != 1 || guides[0] != "usable-ok" {
+		t.Errorf("expected only the guide with a readable manifest, got %v", guides)
+	}
+}
+
+func TestImagePath(t *testing.T) {
+	if path, err := ImagePath("kk/profile.png"); err != nil || path != filepath.Join(imageRoot, "kk", "profile.png") {
+		t.Errorf("expected the image under %s, got %q, %v", imageRoot, path, err)
+	}
+	for _, file := range []string{"../../../config.yaml", "kk/../../secret.png", "/etc/passwd", ""} {
+		if _, err := ImagePath(file); err == nil {
+			t.Errorf("expected %q refused", file)
+		}
+	}
+}
@@ -0,0 +1,264 @@
+package onboard
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// OnboardingConfigVersion is the version of OnboardingConfig that master
+// writes under ConfigKey. Bump it when a field changes meaning, so workers
+// that can't read the new form set the task aside instead of misreading it.
+const OnboardingConfigVersion = 1
+
+// Keys of an onboarding_start payload holding the guild's settings.
+const (
+	ConfigKey        = "config"
+	ConfigVersionKey = "config_version"
+)
+
+// StartPayload is the payload of an onboarding_start task, as built by
+// master's welcome feature: the member and slave, plus the guild's settings
+// under ConfigKey.
+type StartPayload struct {
+	UserID  string `json:"user_id"`
+	SlaveID string `json:"slave_id"`
+
+	// Resume, when set, skips the steps the member completed in an
+	// earlier session
+	Resume *Checkpoint `json:"resume,omitempty"`
+
+	// Payloads from before ConfigKey carry the settings at the top level,
+	// which the embedded fields still read
+	OnboardingConfig
+
+	// SkippedGuideRoles are the guides whose guide_completion_roles entry
+	// was malformed and left out, for the caller to log
+	SkippedGuideRoles []string `json:"-"`
+}
+
+// OnboardingConfig is the guild's onboarding settings, snapshotted when
+// the member starts. Role IDs that are empty are skipped.
+type OnboardingConfig struct {
+	CategoryID string `json:"category_id"`
+
+	// SpreadCategoryIDs take new channels in turn with CategoryID
+	SpreadCategoryIDs []string `json:"spread_category_ids,omitempty"`
+
+	// OverflowCategoryIDs are tried in order when CategoryID and the
+	// spread categories are full
+	OverflowCategoryIDs []string `json:"overflow_category_ids,omitempty"`
+
+	// Channel and reply settings
+	VCNameTemplate         string `json:"vc_name_template,omitempty"`
+	VCSeq                  string `json:"vc_seq,omitempty"`
+	EphemeralConfirmations bool   `json:"ephemeral_confirmations,omitempty"`
+	SharedVCChannelID      string `json:"shared_vc_channel_id,omitempty"`
+	GreetingGuide          string `json:"greeting_guide,omitempty"`
+	TextOnly               bool   `json:"text_only,omitempty"` // No voice; a private text channel replaces the VC
+
+	// VCAccessRoles and VCAccessUsers may also see and join each session's
+	// channel, e.g. staff assisting members. @everyone stays hidden.
+	VCAccessRoles []string `json:"vc_access_roles,omitempty"`
+	VCAccessUsers []string `json:"vc_access_users,omitempty"`
+
+	// SyncCategoryPermissions creates each session's channel with its
+	// category's permission overwrites plus the grants above, instead of
+	// hiding it from @everyone. Whoever can see the category can then see
+	// the channel.
+	SyncCategoryPermissions bool `json:"sync_category_permissions,omitempty"`
+
+	// Locales are the languages members pick from before guide selection.
+	// The chosen one replaces the guild's language for the session's text
+	// and audio. With fewer than two the guild's language is used.
+	Locales []string `json:"locales,omitempty"`
+
+	// Flow roles
+	InProgressRole   string `json:"in_progress_role,omitempty"`
+	CompletedRole    string `json:"completed_role,omitempty"`
+	EntranceRole     string `json:"entrance_role,omitempty"`
+	NyukaiRole       string `json:"nyukai_role,omitempty"`
+	Setsumeikai1Role string `json:"setsumeikai_1_role,omitempty"`
+	Setsumeikai2Role string `json:"setsumeikai_2_role,omitempty"`
+	Setsumeikai3Role string `json:"setsumeikai_3_role,omitempty"`
+	MemberRole       string `json:"member_role,omitempty"`
+	VisitorRole      string `json:"visitor_role,omitempty"`
+
+	// InitialRole is given by master as members join and removed here on
+	// completion
+	InitialRole string `json:"initial_role,omitempty"`
+
+	// Age range roles
+	Age20EarlyRole string `json:"age_20_early_role,omitempty"`
+	Age20LateRole  string `json:"age_20_late_role,omitempty"`
+	Age30EarlyRole string `json:"age_30_early_role,omitempty"`
+	Age30LateRole  string `json:"age_30_late_role,omitempty"`
+	Age40EarlyRole string `json:"age_40_early_role,omitempty"`
+	Age40LateRole  string `json:"age_40_late_role,omitempty"`
+
+	// Gender roles
+	MaleRole   string `json:"male_role,omitempty"`
+	FemaleRole string `json:"female_role,omitempty"`
+
+	// Voice type roles
+	HighVoiceRole    string `json:"high_voice_role,omitempty"`
+	MidHighVoiceRole string `json:"mid_high_voice_role,omitempty"`
+	MidVoiceRole     string `json:"mid_voice_role,omitempty"`
+	MidLowVoiceRole  string `json:"mid_low_voice_role,omitempty"`
+	LowVoiceRole     string `json:"low_voice_role,omitempty"`
+
+	// Other roles
+	EroOkRole            string `json:"ero_ok_role,omitempty"`
+	EroNgRole            string `json:"ero_ng_role,omitempty"`
+	NeochiOkRole         string `json:"neochi_ok_role,omitempty"`
+	NeochiNgRole         string `json:"neochi_ng_role,omitempty"`
+	NeochiDisconnectRole string `json:"neochi_disconnect_role,omitempty"`
+	DmOkRole             string `json:"dm_ok_role,omitempty"`
+	DmNgRole             string `json:"dm_ng_role,omitempty"`
+	FriendOkRole         string `json:"friend_ok_role,omitempty"`
+	FriendNgRole         string `json:"friend_ng_role,omitempty"`
+	BunnyclubEventRole   string `json:"bunnyclub_event_role,omitempty"`
+	UserEventRole        string `json:"user_event_role,omitempty"`
+
+	// GuideCompletionRoles overrides the member and visitor roles per guide
+	GuideCompletionRoles GuideCompletionRoles `json:"guide_completion_roles,omitempty"`
+
+	// CompletionChanges replaces the roles granted and revoked on
+	// completion when Custom is set
+	CompletionChanges CompletionChanges `json:"completion_changes,omitzero"`
+}
+
+// Categories returns every category the worker may create a voice channel
+// in: CategoryID, then the spread and overflow categories.
+func (c OnboardingConfig) Categories() []string {
+	categories := append([]string{c.CategoryID}, c.SpreadCategoryIDs...)
+	return append(categories, c.OverflowCategoryIDs...)
+}
+
+// PayloadError reports a task payload that can never be processed, so the
+// task should be set aside rather than retried.
+type PayloadError struct {
+	TaskType string
+	Err      error
+}
+
+func (e *PayloadError) Error() string {
+	return fmt.Sprintf("invalid %s payload: %v", e.TaskType, e.Err)
+}
+
+func (e *PayloadError) Unwrap() error {
+	return e.Err
+}
+
+// ParseStartPayload decodes and validates an onboarding_start payload.
+// Unknown keys are ignored so master can add settings before every worker
+// knows them; a known key of the wrong type is an error.
+func ParseStartPayload(raw map[string]interface{}) (*StartPayload, error) {
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil, &PayloadError{TaskType: "onboarding_start", Err: err}
+	}
+
+	var payload StartPayload
+	if err := json.Unmarshal(data, &payload); err != nil {
+		return nil, &PayloadError{TaskType: "onboarding_start", Err: describeTypeError(err)}
+	}
+
+	var versioned struct {
+		Version int             `json:"config_version"`
+		Config  json.RawMessage `json:"config"`
+	}
+	if err := json.Unmarshal(data, &versioned); err != nil {
+		return nil, &PayloadError{TaskType: "onboarding_start", Err: describeTypeError(err)}
+	}
+	if versioned.Version > OnboardingConfigVersion {
+		err := fmt.Errorf("unsupported config_version %d, this worker reads up to %d", versioned.Version, OnboardingConfigVersion)
+		return nil, &PayloadError{TaskType: "onboarding_start", Err: err}
+	}
+	config := data
+	if versioned.Version > 0 {
+		// Settings left at the top level by mistake must not leak in
+		config = versioned.Config
+		payload.OnboardingConfig = OnboardingConfig{}
+		if err := json.Unmarshal(config, &payload.OnboardingConfig); err != nil {
+			return nil, &PayloadError{TaskType: "onboarding_start", Err: describeTypeError(err)}
+		}
+	}
+	payload.SkippedGuideRoles = skippedGuideRoles(config, payload.GuideCompletionRoles)
+	if err := payload.Validate(); err != nil {
+		return nil, &PayloadError{TaskType: "onboarding_start", Err: err}
+	}
+	return &payload, nil
+}
+
+// GuideCompletionRoles maps a guide to its completion role overrides.
+type GuideCompletionRoles map[string]CompletionRoles
+
+// UnmarshalJSON leaves out malformed entries rather than failing the whole
+// payload: that guide falls back to the default roles, which beats setting
+// the member's onboarding aside.
+func (g *GuideCompletionRoles) UnmarshalJSON(data []byte) error {
+	var entries map[string]json.RawMessage
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return fmt.Errorf("guide_completion_roles must be an object, got %s", data)
+	}
+	*g = make(GuideCompletionRoles, len(entries))
+	for guide, entry := range entries {
+		var roles CompletionRoles
+		if json.Unmarshal(entry, &roles) == nil {
+			(*g)[guide] = roles
+		}
+	}
+	return nil
+}
+
+// skippedGuideRoles returns the guides of the config's
+// guide_completion_roles that didn't make it into decoded.
+func skippedGuideRoles(config json.RawMessage, decoded GuideCompletionRoles) []string {
+	var raw struct {
+		Roles map[string]json.RawMessage `json:"guide_completion_roles"`
+	}
+	if json.Unmarshal(config, &raw) != nil {
+		return nil
+	}
+	var skipped []string
+	for guide := range raw.Roles {
+		if _, ok := decoded[guide]; !ok {
+			skipped = append(skipped, guide)
+		}
+	}
+	slices.Sort(skipped)
+	return skipped
+}
+
+// describeTypeError names the field of a wrong-typed value.
+func describeTypeError(err error) error {
+	if typeErr, ok := err.(*json.UnmarshalTypeError); ok {
+		return fmt.Errorf("%s must be a %s, got %s", typeErr.Field, typeErr.Type, typeErr.Value)
+	}
+	return err
+}
+
+// Validate checks that the fields every session needs are present.
+func (p *StartPayload) Validate() error {
+	var missing []string
+	if p.UserID == "" {
+		missing = append(missing, "user_id")
+	}
+	if p.CategoryID == "" {
+		missing = append(missing, "category_id")
+	}
+	if p.SlaveID == "" {
+		missing = append(missing, "slave_id")
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("missing %s", strings.Join(missing, ", "))
+	}
+	if p.Resume != nil {
+		if err := p.Resume.Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,171 @@
+package onboard
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func validStartPayload() map[string]interface{} {
+	return map[string]interface{}{
+		"user_id":     "user-1",
+		"category_id": "category-1",
+		"slave_id":    "slave-1",
+	}
+}
+
+func TestParseStartPayload(t *testing.T) {
+	raw := validStartPayload()
+	raw["ephemeral_confirmations"] = true
+	raw["member_role"] = "role-member"
+	raw["guide_completion_roles"] = map[string]interface{}{
+		"kk": map[string]interface{}{"member_role": "role-tier2", "visitor_role": ""},
+	}
+	raw["added_later"] = 42
+
+	payload, err := ParseStartPayload(raw)
+	if err != nil {
+		t.Fatalf("ParseStartPayload: %v", err)
+	}
+	if payload.UserID != "user-1" || payload.CategoryID != "category-1" || payload.SlaveID != "slave-1" {
+		t.Errorf("required fields: got %+v", payload)
+	}
+	if !payload.EphemeralConfirmations || payload.MemberRole != "role-member" {
+		t.Errorf("optional fields: got %+v", payload)
+	}
+	if got := payload.GuideCompletionRoles["kk"]; got != (CompletionRoles{MemberRoleID: "role-tier2"}) {
+		t.Errorf("guide completion roles: got %+v", got)
+	}
+}
+
+func TestParseStartPayloadMissingFields(t *testing.T) {
+	for _, key := range []string{"user_id", "category_id", "slave_id"} {
+		raw := validStartPayload()
+		delete(raw, key)
+
+		_, err := ParseStartPayload(raw)
+		var payloadErr *PayloadError
+		if !errors.As(err, &payloadErr) {
+			t.Fatalf("without %s: got %v, want a PayloadError", key, err)
+		}
+		if !strings.Contains(err.Error(), "missing "+key) {
+			t.Errorf("without %s: error %q doesn't name the field", key, err)
+		}
+	}
+
+	_, err := ParseStartPayload(map[string]interface{}{})
+	if err == nil || !strings.Contains(err.Error(), "missing user_id, category_id, slave_id") {
+		t.Errorf("empty payload: got %v", err)
+	}
+}
+
+func TestParseStartPayloadWrongTypes(t *testing.T) {
+	tests := []struct {
+		key   string
+		value interface{}
+	}{
+		{"user_id", 12345},
+		{"slave_id", []interface{}{"slave-1"}},
+		{"ephemeral_confirmations", "yes"},
+		{"member_role", false},
+		{"guide_completion_roles", "not an object"},
+	}
+
+	for _, tt := range tests {
+		raw := validStartPayload()
+		raw[tt.key] = tt.value
+
+		_, err := ParseStartPayload(raw)
+		var payloadErr *PayloadError
+		if !errors.As(err, &payloadErr) {
+			t.Errorf("%s=%v: got %v, want a PayloadError", tt.key, tt.value, err)
+			continue
+		}
+		if !strings.Contains(err.Error(), tt.key) {
+			t.Errorf("%s=%v: error %q doesn't name the field", tt.key, tt.value, err)
+		}
+	}
+}
+
+func TestParseStartPayloadSkipsMalformedGuideRoles(t *testing.T) {
+	raw := validStartPayload()
+	raw["guide_completion_roles"] = map[string]interface{}{
+		"kk": map[string]interface{}{"member_role": "role-tier2"},
+		"yy": "not an object",
+		"zz": map[string]interface{}{"member_role": 42},
+	}
+
+	payload, err := ParseStartPayload(raw)
+	if err != nil {
+		t.Fatalf("ParseStartPayload: %v", err)
+	}
+	if len(payload.GuideCompletionRoles) != 1 || payload.GuideCompletionRoles["kk"].MemberRoleID != "role-tier2" {
+		t.Errorf("expected only the valid entry, got %+v", payload.GuideCompletionRoles)
+	}
+	if !slices.Equal(payload.SkippedGuideRoles, []string{"yy", "zz"}) {
+		t.Errorf("expected yy and zz to be reported, got %v", payload.SkippedGuideRoles)
+	}
+}
+
+func TestParseStartPayloadVersionedConfig(t *testing.T) {
+	raw := map[string]interface{}{
+		"user_id":        "user-1",
+		"slave_id":       "slave-1",
+		"member_role":    "role-stray", // Ignored next to a versioned config
+		ConfigVersionKey: OnboardingConfigVersion,
+		ConfigKey: map[string]interface{}{
+			"category_id": "category-1",
+			"nyukai_role": "role-nyukai",
+		},
+	}
+
+	payload, err := ParseStartPayload(raw)
+	if err != nil {
+		t.Fatalf("ParseStartPayload: %v", err)
+	}
+	if payload.CategoryID != "category-1" || payload.NyukaiRole != "role-nyukai" {
+		t.Errorf("config fields: got %+v", payload.OnboardingConfig)
+	}
+	if payload.MemberRole != "" {
+		t.Errorf("expected top-level settings to be ignored, got member role %q", payload.MemberRole)
+	}
+
+	raw[ConfigKey] = map[string]interface{}{"member_role": false}
+	if _, err := ParseStartPayload(raw); err == nil || !strings.Contains(err.Error(), "member_role") {
+		t.Errorf("wrong-typed config field: got %v", err)
+	}
+
+	raw[ConfigVersionKey] = OnboardingConfigVersion + 1
+	raw[ConfigKey] = map[string]interface{}{"category_id": "category-1"}
+	_, err = ParseStartPayload(raw)
+	var payloadErr *PayloadError
+	if !errors.As(err, &payloadErr) || !strings.Contains(err.Error(), "config_version") {
+		t.Errorf("newer config version: got %v, want a PayloadError", err)
+	}
+}
+
+func TestParseStartPayloadResume(t *testing.T) {
+	raw := validStartPayload()
+	raw["resume"] = map[string]interface{}{"guide": "kk", "step": 4, "selections": map[string]interface{}{"age": []string{"30late"}}}
+
+	payload, err := ParseStartPayload(raw)
+	if err != nil {
+		t.Fatalf("ParseStartPayload: %v", err)
+	}
+	if payload.Resume == nil || payload.Resume.Guide != "kk" || payload.Resume.Step != 4 {
+		t.Errorf("resume: got %+v", payload.Resume)
+	}
+
+	for _, bad := range []map[string]interface{}{
+		{"guide": "kk", "step": 7},
+		{"guide": "kk", "step": 0},
+		{"step": 2},
+	} {
+		raw := validStartPayload()
+		raw["resume"] = bad
+		if _, err := ParseStartPayload(raw); err == nil {
+			t.Errorf("resume %v should be rejected", bad)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package onboard
+
+import "slices"
+
+// choiceRoles maps each step 3 selection and value to the role it gives.
+func (c OnboardingConfig) choiceRoles() map[string]map[string]string {
+	return map[string]map[string]string{
+		"gender": {"male": c.MaleRole, "female": c.FemaleRole},
+		"age": {
+			"20early": c.Age20EarlyRole, "20late": c.Age20LateRole,
+			"30early": c.Age30EarlyRole, "30late": c.Age30LateRole,
+			"40early": c.Age40EarlyRole, "40late": c.Age40LateRole,
+		},
+		"voice": {
+			"high": c.HighVoiceRole, "midhigh": c.MidHighVoiceRole, "mid": c.MidVoiceRole,
+			"midlow": c.MidLowVoiceRole, "low": c.LowVoiceRole,
+		},
+		"eroipu":          {"ok": c.EroOkRole, "ng": c.EroNgRole},
+		"neochi":          {"ok": c.NeochiOkRole, "ng": c.NeochiNgRole},
+		"neochi_handling": {"disconnect": c.NeochiDisconnectRole},
+		"dm":              {"ok": c.DmOkRole, "ng": c.DmNgRole},
+		"friend":          {"ok": c.FriendOkRole, "ng": c.FriendNgRole},
+		"event":           {"bunnyclub": c.BunnyclubEventRole, "user": c.UserEventRole},
+	}
+}
+
+// ChoiceRole returns the role a step 3 choice gives, e.g. the 30代後半
+// role for ("age", "30late"), or "" if it gives none.
+func (c OnboardingConfig) ChoiceRole(selection, value string) string {
+	return c.choiceRoles()[selection][value]
+}
+
+// SelectionRoles returns the roles the step 3 choices give, in the order
+// the choices are asked. Choices whose role is unset give nothing.
+func (c OnboardingConfig) SelectionRoles(selections Selections) []string {
+	roles := c.choiceRoles()
+
+	var granted []string
+	for _, name := range SelectionNames() {
+		for _, value := range selections[name] {
+			if roleID := roles[name][value]; roleID != "" && !slices.Contains(granted, roleID) {
+				granted = append(granted, roleID)
+			}
+		}
+	}
+	return granted
+}
+
+// ReturnRoles returns the roles that put a returning member back where
+// completing onboarding with guide and selections left them: the selection
+// and completion roles to add, and the flow roles to remove.
+func (c OnboardingConfig) ReturnRoles(guide string, selections Selections) (add, remove []string) {
+	grant, revoke := c.completionRoleChanges(guide)
+
+	add = c.SelectionRoles(selections)
+	for _, roleID := range append(grant, c.CompletedRole) {
+		if roleID != "" && !slices.Contains(add, roleID) {
+			add = append(add, roleID)
+		}
+	}
+
+	// A flow role that doubles as one to add stays
+	flowRoles := append(append([]string{c.InProgressRole}, revoke...), c.EntranceRole, c.InitialRole, c.NyukaiRole)
+	for _, roleID := range flowRoles {
+		if roleID != "" && !slices.Contains(add, roleID) && !slices.Contains(remove, roleID) {
+			remove = append(remove, roleID)
+		}
+	}
+	return add, remove
+}
+
+// completionRoleChanges returns the roles completing onboarding with guide
+// grants and revokes, as the worker's
+// OnboardingSession.CompletionRoleChanges does.
+func (c OnboardingConfig) completionRoleChanges(guide string) (grant, revoke []string) {
+	if c.CompletionChanges.Custom {
+		return c.CompletionChanges.Roles()
+	}
+
+	completion := CompletionRoles{MemberRoleID: c.MemberRole, VisitorRoleID: c.VisitorRole}
+	if override := c.GuideCompletionRoles[guide]; override.MemberRoleID != "" {
+		completion.MemberRoleID = override.MemberRoleID
+	}
+	if override := c.GuideCompletionRoles[guide]; override.VisitorRoleID != "" {
+		completion.VisitorRoleID = override.VisitorRoleID
+	}
+	return CompletionChanges{
+		Grant:  []string{completion.VisitorRoleID, completion.MemberRoleID},
+		Revoke: []string{c.Setsumeikai1Role, c.Setsumeikai2Role, c.Setsumeikai3Role},
+	}.Roles()
+}
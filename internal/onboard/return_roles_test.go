@@ -0,0 +1,64 @@
+package onboard
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestReturnRoles(t *testing.T) {
+	c := OnboardingConfig{
+		MaleRole:             "role-male",
+		Age30LateRole:        "role-30late",
+		DmNgRole:             "role-dm-ng",
+		BunnyclubEventRole:   "role-bunnyclub",
+		UserEventRole:        "role-user-event",
+		MemberRole:           "role-member",
+		VisitorRole:          "role-visitor",
+		CompletedRole:        "role-completed",
+		EntranceRole:         "role-entrance",
+		InitialRole:          "role-entrance",
+		NyukaiRole:           "role-nyukai",
+		GuideCompletionRoles: map[string]CompletionRoles{"kk": {MemberRoleID: "role-tier2"}},
+	}
+	selections := Selections{
+		"gender": {"male"},
+		"age":    {"30late"},
+		"voice":  {"low"}, // No role configured
+		"dm":     {"ng"},
+		"event":  {"user", "bunnyclub"},
+	}
+
+	add, remove := c.ReturnRoles("kk", selections)
+
+	wantAdd := []string{"role-male", "role-30late", "role-dm-ng", "role-user-event", "role-bunnyclub",
+		"role-visitor", "role-tier2", "role-completed"}
+	if !slices.Equal(add, wantAdd) {
+		t.Errorf("add: got %v, want %v", add, wantAdd)
+	}
+	if want := []string{"role-entrance", "role-nyukai"}; !slices.Equal(remove, want) {
+		t.Errorf("remove: got %v, want %v", remove, want)
+	}
+}
+
+func TestReturnRolesCustomCompletion(t *testing.T) {
+	c := OnboardingConfig{
+		MemberRole:       "role-member",
+		VisitorRole:      "role-visitor",
+		Setsumeikai1Role: "role-s1",
+		Setsumeikai2Role: "role-s2",
+		NyukaiRole:       "role-nyukai",
+		CompletionChanges: CompletionChanges{
+			Custom: true,
+			Grant:  []string{"role-member"},
+			Revoke: []string{"role-s2"},
+		},
+	}
+
+	add, remove := c.ReturnRoles("kk", nil)
+	if want := []string{"role-member"}; !slices.Equal(add, want) {
+		t.Errorf("add: got %v, want %v", add, want)
+	}
+	if want := []string{"role-s2", "role-nyukai"}; !slices.Equal(remove, want) {
+		t.Errorf("remove: got %v, want %v", remove, want)
+	}
+}
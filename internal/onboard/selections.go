@@ -0,0 +1,42 @@
+package onboard
+
+import "slices"
+
+// selectionNames are the step 3 selections in flow order.
+var selectionNames = []string{
+	"gender", "age", "voice", "eroipu", "neochi", "neochi_handling", "dm", "friend", "event",
+}
+
+// selectionValues are the choices each step 3 prompt offers.
+var selectionValues = map[string][]string{
+	"gender":          {"male", "female"},
+	"age":             {"20early", "20late", "30early", "30late", "40early", "40late"},
+	"voice":           {"high", "midhigh", "mid", "midlow", "low"},
+	"eroipu":          {"ok", "ng"},
+	"neochi":          {"ok", "ng"},
+	"neochi_handling": {"room", "disconnect"},
+	"dm":              {"ok", "ng"},
+	"friend":          {"ok", "ng"},
+	"event":           {"bunnyclub", "user"},
+}
+
+// Selections maps a step 3 selection (e.g. "age") to the values chosen.
+// Only events can hold more than one value.
+type Selections map[string][]string
+
+// SelectionNames returns the step 3 selections in the order they are asked.
+func SelectionNames() []string {
+	return slices.Clone(selectionNames)
+}
+
+// SelectionValues returns the choices a step 3 selection offers, in the
+// order they are shown.
+func SelectionValues(name string) []string {
+	return slices.Clone(selectionValues[name])
+}
+
+// MultiChoice reports whether a step 3 selection can hold more than one
+// value. Every other selection keeps only the last choice.
+func MultiChoice(name string) bool {
+	return name == "event"
+}
@@ -0,0 +1,13 @@
+package onboard
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestSelectionNames(t *testing.T) {
+	want := "[gender age voice eroipu neochi neochi_handling dm friend event]"
+	if got := fmt.Sprint(SelectionNames()); got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
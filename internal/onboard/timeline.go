@@ -0,0 +1,28 @@
+package onboard
+
+import "time"
+
+// Timeline event kinds.
+const (
+	TimelineStep  = "step"
+	TimelineClick = "click"
+	TimelineAudio = "audio"
+	TimelineRole  = "role"
+	TimelineError = "error"
+)
+
+// Session outcomes stored with a flushed timeline.
+const (
+	OutcomeCompleted = "completed"
+	OutcomeFailed    = "failed"
+	OutcomeInactive  = "inactive"
+	OutcomeTimedOut  = "timed_out"
+	OutcomeEnded     = "ended" // Cancelled for any other reason (e.g. shutdown)
+)
+
+// TimelineEvent is one entry in a session timeline.
+type TimelineEvent struct {
+	At     time.Time `json:"at"`
+	Kind   string    `json:"kind"`
+	Detail string    `json:"detail,omitempty"`
+}
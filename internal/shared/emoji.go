@@ -0,0 +1,84 @@
+package shared
+
+import "github.com/bwmarrin/discordgo"
+
+// EmojiKey names a glyph shown on buttons, select options or menu labels.
+type EmojiKey string
+
+// Emoji keys used across features.
+const (
+	EmojiWelcome     EmojiKey = "welcome"
+	EmojiPreview     EmojiKey = "preview"
+	EmojiGuide       EmojiKey = "guide"
+	EmojiCleanup     EmojiKey = "cleanup"
+	EmojiTimeline    EmojiKey = "timeline"
+	EmojiSessions    EmojiKey = "sessions"
+	EmojiTestAudio   EmojiKey = "test_audio"
+	EmojiRefresh     EmojiKey = "refresh"
+	EmojiGender      EmojiKey = "gender"
+	EmojiAgeRange    EmojiKey = "age_range"
+	EmojiVoiceType   EmojiKey = "voice_type"
+	EmojiOtherRoles  EmojiKey = "other_roles"
+	EmojiLanguage    EmojiKey = "language"
+	EmojiPing        EmojiKey = "ping"
+	EmojiCategory    EmojiKey = "category"
+	EmojiConfigured  EmojiKey = "config_set"
+	EmojiMissing     EmojiKey = "config_missing"
+	EmojiJoinRole    EmojiKey = "join_role"
+	EmojiRemap       EmojiKey = "remap"
+	EmojiRoleSync    EmojiKey = "role_sync"
+	EmojiSchedule    EmojiKey = "schedule"
+	EmojiWelcomeBack EmojiKey = "welcome_back"
+	EmojiVCAccess    EmojiKey = "vc_access"
+	EmojiCompletion  EmojiKey = "completion"
+	EmojiRolePanel   EmojiKey = "role_panel"
+)
+
+// Emoji is the button emoji set. Replace entries before the bot starts to
+// theme it; it is not safe to change while interactions are handled.
+var Emoji = map[EmojiKey]string{
+	EmojiWelcome:     "👋",
+	EmojiPreview:     "🎧",
+	EmojiGuide:       "👤",
+	EmojiCleanup:     "🧹",
+	EmojiTimeline:    "🧾",
+	EmojiSessions:    "👥",
+	EmojiTestAudio:   "🔈",
+	EmojiRefresh:     "🔄",
+	EmojiGender:      "🚻",
+	EmojiAgeRange:    "📅",
+	EmojiVoiceType:   "🎵",
+	EmojiOtherRoles:  "📋",
+	EmojiLanguage:    "🌐",
+	EmojiPing:        "🏓",
+	EmojiCategory:    "📂",
+	EmojiConfigured:  "✅",
+	EmojiMissing:     "⚠️",
+	EmojiJoinRole:    "🚪",
+	EmojiRemap:       "🔀",
+	EmojiRoleSync:    "🏷️",
+	EmojiSchedule:    "🕒",
+	EmojiWelcomeBack: "🔁",
+	EmojiVCAccess:    "🛡️",
+	EmojiCompletion:  "🎓",
+	EmojiRolePanel:   "🎛️",
+}
+
+// WithEmoji prefixes label with the emoji for key. An unset or empty entry
+// leaves the label as is.
+func WithEmoji(key EmojiKey, label string) string {
+	if e := Emoji[key]; e != "" {
+		return e + " " + label
+	}
+	return label
+}
+
+// ComponentEmoji returns the emoji for key as a component emoji, or nil if
+// none is set. Discord rejects components with an empty emoji name.
+func ComponentEmoji(key EmojiKey) *discordgo.ComponentEmoji {
+	e := Emoji[key]
+	if e == "" {
+		return nil
+	}
+	return &discordgo.ComponentEmoji{Name: e}
+}
@@ -0,0 +1,30 @@
+package shared
+
+import (
+	"testing"
+	"unicode/utf8"
+)
+
+func TestEmojiSetIsValid(t *testing.T) {
+	for key, e := range Emoji {
+		if !utf8.ValidString(e) {
+			t.Errorf("%s: invalid UTF-8 %q", key, e)
+		}
+		// UTF-8 decoded as Latin-1 shows up as runes in U+0080..U+00FF
+		for _, r := range e {
+			if r >= 0x80 && r <= 0xFF {
+				t.Errorf("%s: %q looks mis-encoded", key, e)
+				break
+			}
+		}
+	}
+}
+
+func TestWithEmoji(t *testing.T) {
+	if got := WithEmoji(EmojiPreview, "Kuma"); got != "🎧 Kuma" {
+		t.Errorf("got %q", got)
+	}
+	if got := WithEmoji("missing", "Kuma"); got != "Kuma" {
+		t.Errorf("unset key: got %q", got)
+	}
+}
@@ -0,0 +1,198 @@
+package worker
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif" // Formats a guide image may be in
+	_ "image/jpeg"
+	_ "image/png"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"welcomebot/internal/audio"
+	"welcomebot/internal/onboard"
+)
+
+// AssetKind says what sort of file an AssetCheck is about.
+type AssetKind string
+
+// Asset kinds.
+const (
+	AssetAudio AssetKind = "audio"
+	AssetImage AssetKind = "image"
+)
+
+// optionalAudio are the audio keys a guide may leave out. Sessions skip
+// the greeting and disable the preview button when they're missing.
+var optionalAudio = []string{onboard.AudioWelcome, onboard.AudioPreview}
+
+// AssetCheck is the result of checking one of a guide's files.
+type AssetCheck struct {
+	Key    string // Audio key, or step key for images, e.g. "step4"
+	Kind   AssetKind
+	File   string // Path under the guide directory for audio, under assets/images/onboarding for images
+	Locale string // Set for an audio file's language variant
+
+	Size     int64
+	Duration time.Duration // Audio only
+	Width    int           // Images only
+	Height   int
+
+	// Absent marks an optional file that isn't installed, which is fine.
+	// A language variant is absent when the guide's file has none in its
+	// locale, so the file plays untranslated.
+	Absent bool
+	Err    error
+}
+
+// GuideAudit is the checklist of a guide's assets.
+type GuideAudit struct {
+	Guide  string
+	Err    error // Set when the manifest can't be read, leaving Checks empty
+	Checks []AssetCheck
+}
+
+// OK reports whether the guide and every one of its files passed.
+func (a GuideAudit) OK() bool {
+	if a.Err != nil {
+		return false
+	}
+	for _, c := range a.Checks {
+		if c.Err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// AuditAssets audits every guide installed under audio/. It fails only if
+// the guides can't be listed; problems with a guide are in its audit.
+func AuditAssets(limits audio.Limits) ([]GuideAudit, error) {
+	guides, err := onboard.ListGuides()
+	if err != nil {
+		return nil, err
+	}
+
+	audits := make([]GuideAudit, 0, len(guides))
+	for _, guide := range guides {
+		audits = append(audits, AuditGuide(guide, limits))
+	}
+	return audits, nil
+}
+
+// AuditGuide checks that each audio file the guide's flow plays, locale
+// variants included, decodes within limits, and that each image its steps
+// send opens. A missing step file is a failure even though sessions get
+// by without it, since members would miss that part of the onboarding.
+func AuditGuide(guide string, limits audio.Limits) GuideAudit {
+	audit := GuideAudit{Guide: guide}
+
+	// Read afresh rather than through LoadManifest's cache, so the audit
+	// sees what is on disk now
+	manifest, err := onboard.ReadManifest(guide)
+	if err != nil {
+		audit.Err = err
+		return audit
+	}
+
+	locales := AudioLocales(guide)
+	for _, key := range onboard.AudioKeys {
+		file := manifest.AudioFile(key)
+		check := auditAudio(guide, key, file, limits)
+		audit.Checks = append(audit.Checks, check)
+		if check.Err != nil || check.Absent {
+			continue
+		}
+
+		// Missing variants fall back to the file just checked
+		for _, locale := range locales {
+			variant := filepath.Join(locale, file)
+			if _, err := os.Stat(onboard.AudioPath(guide, variant)); err != nil {
+				audit.Checks = append(audit.Checks, AssetCheck{Key: key, Kind: AssetAudio, File: variant, Locale: locale, Absent: true})
+				continue
+			}
+			check := auditAudio(guide, key, variant, limits)
+			check.Locale = locale
+			audit.Checks = append(audit.Checks, check)
+		}
+	}
+
+	for _, def := range NewFlow(onboard.OnboardingConfig{}, nil) {
+		if !def.Images {
+			continue
+		}
+		for _, img := range manifest.StepImages(def.Audio) {
+			files := []string{img.File}
+			for _, locale := range slices.Sorted(maps.Keys(img.Locales)) {
+				if name := img.Locales[locale]; name != "" && !slices.Contains(files, name) {
+					files = append(files, name)
+				}
+			}
+			for _, file := range files {
+				audit.Checks = append(audit.Checks, auditImage(def.ID, file))
+			}
+		}
+	}
+	return audit
+}
+
+// auditAudio checks that a guide's DCA file decodes within limits.
+func auditAudio(guide, key, file string, limits audio.Limits) AssetCheck {
+	limits = limits.WithDefaults()
+	check := AssetCheck{Key: key, Kind: AssetAudio, File: file}
+	path := onboard.AudioPath(guide, file)
+
+	info, err := os.Stat(path)
+	if errors.Is(err, os.ErrNotExist) && slices.Contains(optionalAudio, key) {
+		check.Absent = true
+		return check
+	}
+	if err != nil {
+		check.Err = err
+		return check
+	}
+	check.Size = info.Size()
+	if check.Size > limits.MaxSize {
+		check.Err = fmt.Errorf("%w: %d bytes, limit %d", audio.ErrTooLarge, check.Size, limits.MaxSize)
+		return check
+	}
+
+	check.Duration, check.Err = audio.DCADuration(path, limits.MaxDuration)
+	if check.Err == nil && check.Duration == 0 {
+		check.Err = errors.New("no audio frames")
+	}
+	return check
+}
+
+// auditImage checks that an image under assets/images/onboarding opens
+// and decodes as an image.
+func auditImage(key, file string) AssetCheck {
+	check := AssetCheck{Key: key, Kind: AssetImage, File: file}
+
+	path, err := onboard.ImagePath(file)
+	if err != nil {
+		check.Err = err
+		return check
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		check.Err = err
+		return check
+	}
+	defer f.Close()
+
+	if info, err := f.Stat(); err == nil {
+		check.Size = info.Size()
+	}
+	config, _, err := image.DecodeConfig(f)
+	if err != nil {
+		check.Err = fmt.Errorf("decode image: %w", err)
+		return check
+	}
+	check.Width, check.Height = config.Width, config.Height
+	return check
+}
@@ -0,0 +1,94 @@
+package worker
+
+import (
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"welcomebot/internal/audio"
+	"welcomebot/internal/onboard"
+)
+
+// writePNG writes a w×h PNG under the image root.
+func writePNG(t *testing.T, file string, w, h int) {
+	t.Helper()
+
+	path, err := onboard.ImagePath(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if err := png.Encode(f, image.NewRGBA(image.Rect(0, 0, w, h))); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAuditGuide(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	for _, key := range onboard.AudioKeys[2:] {
+		writeDCA(t, onboard.AudioPath("kk", defaultAudioFile(key)), 50)
+	}
+	writeDCA(t, onboard.AudioPath("kk", "ja/1-intro.dca"), 100)
+	if err := os.WriteFile(onboard.AudioPath("kk", "7-end.dca"), []byte("not dca"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(onboard.AudioPath("kk", "manifest.json"), []byte(`{"images": {
+		"step1": [{"file": "one.png", "locales": {"en": "one.en.png"}}],
+		"step2": [], "step4": [], "step6": []
+	}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	writePNG(t, "one.png", 4, 3)
+
+	audit := AuditGuide("kk", audio.Limits{})
+	if audit.OK() {
+		t.Error("expected the broken files to fail the guide")
+	}
+
+	checks := make(map[string]AssetCheck)
+	for _, c := range audit.Checks {
+		checks[c.File] = c
+	}
+	if c := checks["greeting.dca"]; !c.Absent || c.Err != nil {
+		t.Errorf("expected the missing greeting to be optional, got %+v", c)
+	}
+	if c := checks["1-intro.dca"]; c.Err != nil || c.Duration != time.Second || c.Size == 0 {
+		t.Errorf("expected a one second step 1, got %+v", c)
+	}
+	if c := checks[filepath.Join("ja", "1-intro.dca")]; c.Err != nil || c.Duration != 2*time.Second || c.Locale != "ja" {
+		t.Errorf("expected the ja variant to be checked, got %+v", c)
+	}
+	if c := checks[filepath.Join("ja", "2-profile.dca")]; !c.Absent || c.Err != nil || c.Locale != "ja" {
+		t.Errorf("expected the missing ja variant to fall back, got %+v", c)
+	}
+	if _, ok := checks[filepath.Join("ja", "7-end.dca")]; ok {
+		t.Error("expected no variant check for a broken file")
+	}
+	if c := checks["7-end.dca"]; c.Err == nil {
+		t.Error("expected a file without frames to fail")
+	}
+	if c := checks["one.png"]; c.Err != nil || c.Width != 4 || c.Height != 3 || c.Key != "step1" {
+		t.Errorf("expected step 1's image to open, got %+v", c)
+	}
+	if c, ok := checks["one.en.png"]; !ok || c.Err == nil {
+		t.Errorf("expected the missing locale image to fail, got %+v", c)
+	}
+
+	if err := os.WriteFile(onboard.AudioPath("kk", "manifest.json"), []byte("{"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if audit := AuditGuide("kk", audio.Limits{}); audit.Err == nil || audit.OK() {
+		t.Error("expected an unreadable manifest to fail the guide")
+	}
+}
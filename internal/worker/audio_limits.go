@@ -0,0 +1,35 @@
+package worker
+
+import (
+	"errors"
+	"os"
+
+	"welcomebot/internal/audio"
+)
+
+// RejectFailedAudio treats the audio files that failed the audit as
+// missing from then on: sessions fall back to TTS or continue without
+// them, exactly as for a file that isn't installed. It returns the checks
+// of the files it rejected.
+func RejectFailedAudio(audit GuideAudit) []AssetCheck {
+	var rejected []AssetCheck
+	for _, c := range audit.Checks {
+		// A missing file is already skipped, and may still be installed
+		if c.Kind != AssetAudio || c.Err == nil || errors.Is(c.Err, os.ErrNotExist) {
+			continue
+		}
+		rejected = append(rejected, c)
+
+		audio.Reject(audit.Guide, c.File, c.Err)
+	}
+	return rejected
+}
+
+// SetAudioLimits caps how long the session's player streams a single clip.
+// A player without a voice connection, like the silent one in a shared VC,
+// ignores it. It must be called before Start.
+func (s *OnboardingSession) SetAudioLimits(l audio.Limits) {
+	if player, ok := s.player.(interface{ SetLimits(audio.Limits) }); ok {
+		player.SetLimits(l)
+	}
+}
@@ -0,0 +1,65 @@
+package worker
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"welcomebot/internal/audio"
+)
+
+// writeDCA writes a raw DCA file of n empty frames.
+func writeDCA(t *testing.T, path string, n int) {
+	t.Helper()
+
+	var buf bytes.Buffer
+	frame := make([]byte, 3)
+	for range n {
+		binary.Write(&buf, binary.LittleEndian, int16(len(frame)))
+		buf.Write(frame)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRejectFailedAudio(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	// Rejections outlive the test, so they go to a guide no other test
+	// plays. 50 frames is one second.
+	writeDCA(t, "audio/oversized/1-intro.dca", 50)
+	writeDCA(t, "audio/oversized/2-profile.dca", 200)
+	writeDCA(t, "audio/oversized/3-role.dca", 50)
+	writeDCA(t, "audio/oversized/ja/3-role.dca", 20000)
+
+	limits := audio.Limits{MaxSize: 60000, MaxDuration: 2 * time.Second}
+	rejected := RejectFailedAudio(AuditGuide("oversized", limits))
+	if len(rejected) != 2 {
+		t.Fatalf("expected two rejected files, got %+v", rejected)
+	}
+	if rejected[0].File != "2-profile.dca" || !errors.Is(rejected[0].Err, audio.ErrTooLong) {
+		t.Errorf("expected 2-profile.dca to be too long, got %+v", rejected[0])
+	}
+	if rejected[1].File != filepath.Join("ja", "3-role.dca") || !errors.Is(rejected[1].Err, audio.ErrTooLarge) {
+		t.Errorf("expected ja/3-role.dca to be too large, got %+v", rejected[1])
+	}
+
+	// Rejected files play as if they weren't installed
+	if !audio.Exists("oversized", "1-intro.dca") {
+		t.Error("expected the short file to be kept")
+	}
+	if audio.Exists("oversized", "2-profile.dca") {
+		t.Error("expected the rejected file to count as missing")
+	}
+	if got := LocalizedAudioFile("oversized", "ja", "", "3-role.dca"); got != "3-role.dca" {
+		t.Errorf("expected the rejected variant to fall back, got %q", got)
+	}
+}
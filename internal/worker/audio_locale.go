@@ -0,0 +1,71 @@
+package worker
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"welcomebot/internal/audio"
+	"welcomebot/internal/onboard"
+)
+
+// A guide can hold language variants of its audio in locale directories,
+// e.g. audio/kk/ja/1-intro.dca next to audio/kk/1-intro.dca. Sessions play
+// the variant for the guild's language, falling back to the default
+// locale's variant and then to the file in the guide directory itself.
+
+// LocalizedAudioFile returns the path under the guide directory to play
+// for filename in locale: "<locale>/<filename>" when that variant exists,
+// otherwise the fallback locale's variant, otherwise filename.
+func LocalizedAudioFile(guide, locale, fallback, filename string) string {
+	for _, l := range []string{locale, fallback} {
+		if l == "" {
+			continue
+		}
+		if variant := filepath.Join(l, filename); audio.Exists(guide, variant) {
+			return variant
+		}
+	}
+	return filename
+}
+
+// AudioLocales returns the locale directories installed in a guide, sorted.
+// Directories that hold none of the guide's flow audio are not locales.
+func AudioLocales(guide string) []string {
+	entries, err := os.ReadDir(onboard.GuideDir(guide))
+	if err != nil {
+		return nil
+	}
+
+	manifest, _ := onboard.LoadManifest(guide)
+	var locales []string
+	for _, entry := range entries {
+		if !entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		for _, key := range onboard.AudioKeys {
+			if audio.Exists(guide, filepath.Join(entry.Name(), manifest.AudioFile(key))) {
+				locales = append(locales, entry.Name())
+				break
+			}
+		}
+	}
+	sort.Strings(locales)
+	return locales
+}
+
+// SetDefaultAudioLocale sets the locale whose audio variants are played
+// when the guild's language has none. It must be called before Start.
+func (s *OnboardingSession) SetDefaultAudioLocale(locale string) {
+	s.defaultAudioLocale = locale
+}
+
+// localizedAudio resolves filename to the variant for the guild's language.
+func (s *OnboardingSession) localizedAudio(guide, filename string) string {
+	var locale string
+	if s.i18n != nil {
+		locale, _ = s.i18n.GetGuildLanguage(s.ctx, s.guildID)
+	}
+	return LocalizedAudioFile(guide, locale, s.defaultAudioLocale, filename)
+}
@@ -0,0 +1,56 @@
+package worker
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"welcomebot/internal/onboard"
+)
+
+func writeAudio(t *testing.T, guide string, files ...string) {
+	t.Helper()
+	for _, file := range files {
+		path := onboard.AudioPath(guide, file)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("dca"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestLocalizedAudioFile(t *testing.T) {
+	t.Chdir(t.TempDir())
+	writeAudio(t, "bilingual", "1-intro.dca", "2-profile.dca", "ja/1-intro.dca", "en/2-profile.dca")
+
+	tests := []struct {
+		locale, fallback, file string
+		want                   string
+	}{
+		{"ja", "", "1-intro.dca", "ja/1-intro.dca"},
+		{"ja", "", "2-profile.dca", "2-profile.dca"},      // no ja variant
+		{"ja", "en", "2-profile.dca", "en/2-profile.dca"}, // default locale's variant
+		{"", "", "1-intro.dca", "1-intro.dca"},
+		{"fr", "ja", "3-role.dca", "3-role.dca"}, // missing everywhere
+	}
+	for _, tt := range tests {
+		if got := LocalizedAudioFile("bilingual", tt.locale, tt.fallback, tt.file); got != tt.want {
+			t.Errorf("LocalizedAudioFile(%q, %q, %q) = %q, want %q", tt.locale, tt.fallback, tt.file, got, tt.want)
+		}
+	}
+}
+
+func TestAudioLocales(t *testing.T) {
+	t.Chdir(t.TempDir())
+	writeAudio(t, "bilingual", "1-intro.dca", "2-profile.dca", "ja/1-intro.dca", "ja/2-profile.dca", "en/1-intro.dca")
+	if err := os.MkdirAll(onboard.AudioPath("bilingual", "scratch"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := AudioLocales("bilingual"); !reflect.DeepEqual(got, []string{"en", "ja"}) {
+		t.Errorf("expected en and ja, got %v", got)
+	}
+}
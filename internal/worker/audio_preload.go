@@ -0,0 +1,14 @@
+package worker
+
+import "welcomebot/internal/audio"
+
+// recordGuideUse counts the member's guide towards the order
+// audio.PreloadGuides loads guides in.
+func (s *OnboardingSession) recordGuideUse(guide string) {
+	if s.cache == nil {
+		return
+	}
+	if err := audio.CountGuideUse(s.ctx, s.cache, guide); err != nil {
+		s.logger.Debug("failed to count guide use", "guide", guide, "error", err)
+	}
+}
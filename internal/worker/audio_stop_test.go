@@ -0,0 +1,95 @@
+package worker
+
+import (
+	"testing"
+	"time"
+
+	"welcomebot/internal/fakes"
+)
+
+func TestStopCurrentAudioStopsAsyncPlay(t *testing.T) {
+	player := &fakes.AudioPlayer{}
+	s := &OnboardingSession{player: player, logger: fakes.Logger{}}
+
+	s.PlayAudioFileAsync("kk", "3-roles.dca")
+
+	deadline := time.Now().Add(time.Second)
+	for len(player.Played()) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("async play never started")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	s.StopCurrentAudio()
+	if !player.Stopped() {
+		t.Error("expected async playback to be stopped")
+	}
+}
+
+func TestStopCurrentAudioCancelsPendingPlay(t *testing.T) {
+	player := &fakes.AudioPlayer{}
+	s := &OnboardingSession{player: player, logger: fakes.Logger{}}
+
+	// An async play that has not reached the player yet when the user
+	// moves on must not start afterwards
+	gen := s.audioGen
+	s.StopCurrentAudio()
+	if err := s.playAudioFileGen("kk", "3-roles.dca", gen); err != nil {
+		t.Fatal(err)
+	}
+
+	if played := player.Played(); len(played) != 0 {
+		t.Errorf("expected stale play to be dropped, played %v", played)
+	}
+
+	// Later plays are unaffected
+	if err := s.playAudioFile("kk", "4-next.dca"); err != nil {
+		t.Fatal(err)
+	}
+	if played := player.Played(); len(played) != 1 {
+		t.Errorf("expected one play after the stop, got %v", played)
+	}
+}
+
+// slowPlayer blocks in Play until released, like a stream whose fades are
+// still being encoded.
+type slowPlayer struct {
+	fakes.AudioPlayer
+	started, release chan struct{}
+}
+
+func (p *slowPlayer) Play(guide, filename string) error {
+	close(p.started)
+	<-p.release
+	return p.AudioPlayer.Play(guide, filename)
+}
+
+func TestStopCurrentAudioDuringSlowPlay(t *testing.T) {
+	player := &slowPlayer{started: make(chan struct{}), release: make(chan struct{})}
+	s := &OnboardingSession{player: player, logger: fakes.Logger{}}
+
+	played := make(chan error, 1)
+	go func() { played <- s.playAudioFile("kk", "3-roles.dca") }()
+	<-player.started
+
+	// Stopping doesn't wait for the stream to start
+	stopped := make(chan struct{})
+	go func() {
+		s.StopCurrentAudio()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("StopCurrentAudio waited for the stream to start")
+	}
+
+	close(player.release)
+	if err := <-played; err != nil {
+		t.Fatal(err)
+	}
+	if !player.Stopped() {
+		t.Error("expected the stream stopped once it started")
+	}
+}
@@ -0,0 +1,12 @@
+package worker
+
+import "welcomebot/internal/audio"
+
+// SetAudioTransitions applies fades and a gap to the session's audio. A
+// player that can't apply them, like the silent one in a shared VC, plays
+// clips unchanged. It must be called before Start.
+func (s *OnboardingSession) SetAudioTransitions(t audio.Transitions) {
+	if player, ok := s.player.(interface{ SetTransitions(audio.Transitions) }); ok {
+		player.SetTransitions(t)
+	}
+}
@@ -0,0 +1,102 @@
+package worker
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// ButtonRole is what a button does in the onboarding flow, which decides
+// its style.
+type ButtonRole string
+
+const (
+	ButtonNext     ButtonRole = "next"     // Moves to the next step
+	ButtonReplay   ButtonRole = "replay"   // Replays a step's audio or text
+	ButtonBack     ButtonRole = "back"     // Returns to guide selection
+	ButtonSkip     ButtonRole = "skip"     // Skips the greeting
+	ButtonPreview  ButtonRole = "preview"  // Plays a guide's voice preview
+	ButtonConfirm  ButtonRole = "confirm"  // Confirms a guide or the step 3 choices
+	ButtonComplete ButtonRole = "complete" // Finishes onboarding
+	ButtonChoice   ButtonRole = "choice"   // A step 3 option, e.g. an age range
+	ButtonOK       ButtonRole = "ok"       // The OK side of a step 3 OK/NG prompt
+	ButtonNG       ButtonRole = "ng"       // The NG side of a step 3 OK/NG prompt
+	ButtonToggle   ButtonRole = "toggle"   // An event that isn't selected
+	ButtonToggled  ButtonRole = "toggled"  // An event that is selected
+)
+
+// defaultButtonStyles are the styles used when ButtonStyles has no entry.
+var defaultButtonStyles = map[ButtonRole]discordgo.ButtonStyle{
+	ButtonNext:     discordgo.PrimaryButton,
+	ButtonReplay:   discordgo.SecondaryButton,
+	ButtonBack:     discordgo.SecondaryButton,
+	ButtonSkip:     discordgo.SecondaryButton,
+	ButtonPreview:  discordgo.SecondaryButton,
+	ButtonConfirm:  discordgo.SuccessButton,
+	ButtonComplete: discordgo.SuccessButton,
+	ButtonChoice:   discordgo.PrimaryButton,
+	ButtonOK:       discordgo.SuccessButton,
+	ButtonNG:       discordgo.DangerButton,
+	ButtonToggle:   discordgo.SecondaryButton,
+	ButtonToggled:  discordgo.SuccessButton,
+}
+
+// buttonStyleNames are the style names accepted by ParseButtonStyles.
+// Link buttons need a URL, so they can't be chosen.
+var buttonStyleNames = map[string]discordgo.ButtonStyle{
+	"primary":   discordgo.PrimaryButton,
+	"secondary": discordgo.SecondaryButton,
+	"success":   discordgo.SuccessButton,
+	"danger":    discordgo.DangerButton,
+}
+
+// ButtonStyles maps button roles to the styles a guild's community wants,
+// e.g. a green next button. Roles without an entry keep their default.
+type ButtonStyles map[ButtonRole]discordgo.ButtonStyle
+
+// ParseButtonStyles parses a comma-separated list of role=style entries,
+// e.g. "next=success,ng=secondary".
+func ParseButtonStyles(s string) (ButtonStyles, error) {
+	styles := make(ButtonStyles)
+	for _, entry := range strings.Split(s, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		role, name, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, fmt.Errorf("button style %q: want role=style", entry)
+		}
+		role, name = strings.TrimSpace(role), strings.TrimSpace(name)
+		if _, known := defaultButtonStyles[ButtonRole(role)]; !known {
+			return nil, fmt.Errorf("button style %q: unknown role %q", entry, role)
+		}
+		style, known := buttonStyleNames[strings.ToLower(name)]
+		if !known {
+			return nil, fmt.Errorf("button style %q: style must be primary, secondary, success or danger", entry)
+		}
+		styles[ButtonRole(role)] = style
+	}
+	return styles, nil
+}
+
+// Style returns the style for role.
+func (b ButtonStyles) Style(role ButtonRole) discordgo.ButtonStyle {
+	if style, ok := b[role]; ok {
+		return style
+	}
+	return defaultButtonStyles[role]
+}
+
+// SetButtonStyles replaces the session's button styles.
+// It must be called before Start.
+func (s *OnboardingSession) SetButtonStyles(styles ButtonStyles) {
+	s.buttonStyles = styles
+}
+
+// ButtonStyle returns the session's style for role.
+func (s *OnboardingSession) ButtonStyle(role ButtonRole) discordgo.ButtonStyle {
+	return s.buttonStyles.Style(role)
+}
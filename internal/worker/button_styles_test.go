@@ -0,0 +1,40 @@
+package worker
+
+import (
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func TestParseButtonStyles(t *testing.T) {
+	styles, err := ParseButtonStyles(" next=success, ng = Secondary ")
+	if err != nil {
+		t.Fatalf("ParseButtonStyles: %v", err)
+	}
+	if got := styles.Style(ButtonNext); got != discordgo.SuccessButton {
+		t.Errorf("next = %v, want success", got)
+	}
+	if got := styles.Style(ButtonNG); got != discordgo.SecondaryButton {
+		t.Errorf("ng = %v, want secondary", got)
+	}
+
+	// Roles without an entry keep the default
+	if got := styles.Style(ButtonReplay); got != discordgo.SecondaryButton {
+		t.Errorf("replay = %v, want the default secondary", got)
+	}
+	var none ButtonStyles
+	if got := none.Style(ButtonComplete); got != discordgo.SuccessButton {
+		t.Errorf("complete = %v, want the default success", got)
+	}
+}
+
+func TestParseButtonStylesErrors(t *testing.T) {
+	for _, bad := range []string{"next", "nope=primary", "next=link", "next=green"} {
+		if _, err := ParseButtonStyles(bad); err == nil {
+			t.Errorf("ParseButtonStyles(%q) should fail", bad)
+		}
+	}
+	if styles, err := ParseButtonStyles(""); err != nil || len(styles) != 0 {
+		t.Errorf("empty: got %v, %v", styles, err)
+	}
+}
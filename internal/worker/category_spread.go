@@ -0,0 +1,36 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+)
+
+// categoryCursorKey counts the channels a guild's spread categories have
+// been handed, so every slave rotates through them from the same place.
+func categoryCursorKey(guildID string) string {
+	return fmt.Sprintf("welcomebot:category_cursor:%s", guildID)
+}
+
+// categoryOrder returns the categories to try for a new voice channel. The
+// onboarding category and its spread categories take turns being first, in
+// round-robin order; the overflow categories follow once they are all full.
+// Without spread categories, or while the cache is unavailable, the
+// onboarding category always comes first.
+func (s *OnboardingSession) categoryOrder(ctx context.Context) []string {
+	spread := append([]string{s.categoryID}, s.spreadIDs...)
+
+	start := 0
+	if len(spread) > 1 && !s.cache.Degraded() {
+		n, err := s.cache.Incr(ctx, categoryCursorKey(s.guildID))
+		if err != nil {
+			s.logger.Warn("failed to advance category cursor, using the first category", "error", err)
+		} else {
+			start = int((n - 1) % int64(len(spread)))
+		}
+	}
+
+	order := make([]string, 0, len(spread)+len(s.overflowIDs))
+	order = append(order, spread[start:]...)
+	order = append(order, spread[:start]...)
+	return append(order, s.overflowIDs...)
+}
@@ -0,0 +1,55 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"welcomebot/internal/fakes"
+)
+
+func TestCategoryOrder(t *testing.T) {
+	ctx := context.Background()
+	c := fakes.NewCache()
+	newSession := func() *OnboardingSession {
+		return &OnboardingSession{
+			guildID:     "guild-1",
+			categoryID:  "cat-1",
+			spreadIDs:   []string{"cat-2", "cat-3"},
+			overflowIDs: []string{"cat-4"},
+			cache:       c,
+			logger:      fakes.Logger{},
+		}
+	}
+
+	// Each new session starts one category further along, across sessions
+	want := []string{
+		"[cat-1 cat-2 cat-3 cat-4]",
+		"[cat-2 cat-3 cat-1 cat-4]",
+		"[cat-3 cat-1 cat-2 cat-4]",
+		"[cat-1 cat-2 cat-3 cat-4]",
+	}
+	for n, w := range want {
+		if got := fmt.Sprint(newSession().categoryOrder(ctx)); got != w {
+			t.Errorf("session %d: got %s, want %s", n+1, got, w)
+		}
+	}
+
+	// Without the cache there's no shared turn, so the VC category goes first
+	c.SetDegraded(true)
+	if got := fmt.Sprint(newSession().categoryOrder(ctx)); got != want[0] {
+		t.Errorf("degraded: got %s, want %s", got, want[0])
+	}
+}
+
+func TestCategoryOrderSingleCategory(t *testing.T) {
+	c := fakes.NewCache()
+	s := &OnboardingSession{guildID: "guild-1", categoryID: "cat-1", overflowIDs: []string{"cat-2"}, cache: c, logger: fakes.Logger{}}
+
+	if got := fmt.Sprint(s.categoryOrder(context.Background())); got != "[cat-1 cat-2]" {
+		t.Errorf("got %s, want [cat-1 cat-2]", got)
+	}
+	if exists, _ := c.Exists(context.Background(), categoryCursorKey("guild-1")); exists {
+		t.Error("expected no cursor for a single category")
+	}
+}
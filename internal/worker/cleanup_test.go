@@ -0,0 +1,97 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"welcomebot/internal/fakes"
+	"welcomebot/internal/onboard"
+)
+
+// failingPlayer is an AudioPlayer whose Disconnect always fails.
+type failingPlayer struct {
+	*fakes.AudioPlayer
+}
+
+func (p failingPlayer) Disconnect(ctx context.Context) error {
+	return errors.New("voice gateway gone")
+}
+
+func newCleanupSession(t *testing.T, d *fakes.Discord, player onboard.AudioPlayer) (*OnboardingSession, *fakes.Cache) {
+	t.Helper()
+	delay := cleanupRetryDelay
+	cleanupRetryDelay = 0
+	t.Cleanup(func() { cleanupRetryDelay = delay })
+
+	c := fakes.NewCache()
+	_, cancel := context.WithCancel(context.Background())
+	return &OnboardingSession{
+		guildID:     "guild-1",
+		userID:      "user-1",
+		slaveID:     "slave-1",
+		vcChannelID: "vc-1",
+		session:     d.Session(),
+		db:          fakes.NewDB(),
+		cache:       c,
+		player:      player,
+		logger:      fakes.Logger{},
+		cancel:      cancel,
+	}, c
+}
+
+func TestCleanupRetriesChannelDelete(t *testing.T) {
+	d := fakes.NewDiscord()
+	d.FailRequests(http.MethodDelete, "channels/vc-1", 1)
+	s, _ := newCleanupSession(t, d, &fakes.AudioPlayer{})
+
+	s.cleanup()
+
+	if n := d.Count(http.MethodDelete, "channels/vc-1"); n != 2 {
+		t.Errorf("expected one retry, got %d deletes", n)
+	}
+	if n := d.Count(http.MethodGet, "channels/vc-1"); n != 0 {
+		t.Errorf("no verification needed after a successful retry, got %d lookups", n)
+	}
+}
+
+func TestCleanupVerifiesChannelGone(t *testing.T) {
+	d := fakes.NewDiscord()
+	d.FailRequests(http.MethodDelete, "channels/vc-1", 2)
+	d.SetNotFound("channels/vc-1")
+	s, _ := newCleanupSession(t, d, &fakes.AudioPlayer{})
+
+	// Both deletes fail, but the lookup shows the first one went through
+	if err := s.removeVoiceChannel(); err != nil {
+		t.Errorf("expected the channel counted as deleted, got %v", err)
+	}
+	if n := d.Count(http.MethodGet, "channels/vc-1"); n != 1 {
+		t.Errorf("expected the channel to be looked up once, got %d", n)
+	}
+}
+
+func TestCleanupReportsLeakedChannel(t *testing.T) {
+	d := fakes.NewDiscord()
+	d.FailRequests(http.MethodDelete, "channels/vc-1", 2)
+	s, _ := newCleanupSession(t, d, &fakes.AudioPlayer{})
+
+	if err := s.removeVoiceChannel(); err == nil {
+		t.Error("expected the channel that is still there reported")
+	}
+}
+
+func TestCleanupFreesSlaveWhenDisconnectFails(t *testing.T) {
+	d := fakes.NewDiscord()
+	s, c := newCleanupSession(t, d, failingPlayer{&fakes.AudioPlayer{}})
+
+	s.cleanup()
+
+	status, err := c.Get(context.Background(), "welcomebot:slaves:status:slave-1")
+	if err != nil || status != "available" {
+		t.Errorf("expected slave to be freed, got %q, %v", status, err)
+	}
+	if n := d.Count(http.MethodDelete, "channels/vc-1"); n != 1 {
+		t.Errorf("expected the channel to be deleted, got %d deletes", n)
+	}
+}
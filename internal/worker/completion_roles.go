@@ -0,0 +1,32 @@
+package worker
+
+import "welcomebot/internal/onboard"
+
+// CompletionRoles returns the roles to grant for the selected guide. A
+// role the guide doesn't override is the guild's member or visitor role.
+func (s *OnboardingSession) CompletionRoles() onboard.CompletionRoles {
+	roles := onboard.CompletionRoles{MemberRoleID: s.MemberRoleID, VisitorRoleID: s.VisitorRoleID}
+
+	override := s.guideCompletionRoles[s.selectedGuide]
+	if override.MemberRoleID != "" {
+		roles.MemberRoleID = override.MemberRoleID
+	}
+	if override.VisitorRoleID != "" {
+		roles.VisitorRoleID = override.VisitorRoleID
+	}
+	return roles
+}
+
+// CompletionRoleChanges returns the roles to grant and revoke as the
+// member completes onboarding with the selected guide. The entrance,
+// initial and nyukai roles are removed on completion regardless.
+func (s *OnboardingSession) CompletionRoleChanges() (grant, revoke []string) {
+	if s.completionChanges.Custom {
+		return s.completionChanges.Roles()
+	}
+	roles := s.CompletionRoles()
+	return onboard.CompletionChanges{
+		Grant:  []string{roles.VisitorRoleID, roles.MemberRoleID},
+		Revoke: []string{s.Setsumeikai1RoleID, s.Setsumeikai2RoleID, s.Setsumeikai3RoleID},
+	}.Roles()
+}
@@ -0,0 +1,62 @@
+package worker
+
+import (
+	"slices"
+	"testing"
+
+	"welcomebot/internal/onboard"
+)
+
+func TestCompletionRoles(t *testing.T) {
+	s := &OnboardingSession{
+		MemberRoleID:  "role-member",
+		VisitorRoleID: "role-visitor",
+		guideCompletionRoles: map[string]onboard.CompletionRoles{
+			"kk": {MemberRoleID: "role-tier2"},
+		},
+	}
+
+	s.selectedGuide = "kk"
+	if got := s.CompletionRoles(); got != (onboard.CompletionRoles{MemberRoleID: "role-tier2", VisitorRoleID: "role-visitor"}) {
+		t.Errorf("kk: got %+v", got)
+	}
+
+	// Guides without overrides use the guild's roles
+	s.selectedGuide = "other"
+	if got := s.CompletionRoles(); got != (onboard.CompletionRoles{MemberRoleID: "role-member", VisitorRoleID: "role-visitor"}) {
+		t.Errorf("other: got %+v", got)
+	}
+}
+
+func TestCompletionRoleChanges(t *testing.T) {
+	s := &OnboardingSession{
+		MemberRoleID:       "role-member",
+		VisitorRoleID:      "role-visitor",
+		Setsumeikai1RoleID: "role-s1",
+		Setsumeikai3RoleID: "role-s3",
+	}
+
+	// By default the visitor and member roles replace the 説明会 roles
+	grant, revoke := s.CompletionRoleChanges()
+	if want := []string{"role-visitor", "role-member"}; !slices.Equal(grant, want) {
+		t.Errorf("default grant: got %v, want %v", grant, want)
+	}
+	if want := []string{"role-s1", "role-s3"}; !slices.Equal(revoke, want) {
+		t.Errorf("default revoke: got %v, want %v", revoke, want)
+	}
+
+	// A guild keeping 説明会 as a marker revokes nothing; a role in both
+	// lists is only granted
+	s.completionChanges = onboard.CompletionChanges{
+		Custom: true,
+		Grant:  []string{"role-member", "role-s1", "role-member"},
+		Revoke: []string{"role-s1"},
+	}
+	grant, revoke = s.CompletionRoleChanges()
+	if want := []string{"role-member", "role-s1"}; !slices.Equal(grant, want) {
+		t.Errorf("custom grant: got %v, want %v", grant, want)
+	}
+	if len(revoke) != 0 {
+		t.Errorf("custom revoke: got %v, want none", revoke)
+	}
+}
@@ -0,0 +1,162 @@
+package worker
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"welcomebot/internal/onboard"
+)
+
+// Condition decides whether a step is shown. Every selection named in
+// ShowIf must be one of its listed values, e.g.
+// {"show_if": {"age": ["30late", "40early"]}}.
+type Condition struct {
+	ShowIf map[string][]string `json:"show_if"`
+}
+
+// StepConditions maps a step key to its condition. Steps without an entry
+// are always shown.
+type StepConditions map[string]Condition
+
+// conditionalSteps lists, in flow order, the steps that can be hidden and
+// the selection each step 3 prompt records. A condition may only refer to
+// selections made before its step.
+var conditionalSteps = []struct {
+	step      string
+	selection string
+}{
+	{"step3.gender", "gender"},
+	{"step3.age", "age"},
+	{"step3.voice", "voice"},
+	{"step3.eroipu", "eroipu"},
+	{"step3.neochi", "neochi"},
+	{"step3.neochi_handling", "neochi_handling"},
+	{"step3.dm", "dm"},
+	{"step3.friend", "friend"},
+	{"step3.event", "event"},
+	{"step4", ""},
+	{"step5", ""},
+	{"step6", ""},
+}
+
+// ParseStepConditions parses step conditions from JSON, e.g.
+// {"step6": {"show_if": {"age": ["30late", "40early"]}}}. An empty string
+// means no conditions.
+func ParseStepConditions(s string) (StepConditions, error) {
+	conditions := make(StepConditions)
+	if strings.TrimSpace(s) == "" {
+		return conditions, nil
+	}
+	if err := json.Unmarshal([]byte(s), &conditions); err != nil {
+		return nil, fmt.Errorf("invalid step conditions: %w", err)
+	}
+
+	for step, cond := range conditions {
+		earlier, ok := selectionsBefore(step)
+		if !ok {
+			return nil, fmt.Errorf("step %q cannot have a condition", step)
+		}
+		for selection, values := range cond.ShowIf {
+			if !earlier[selection] {
+				return nil, fmt.Errorf("step %s: %q is not selected before this step", step, selection)
+			}
+			for _, v := range values {
+				if !contains(onboard.SelectionValues(selection), v) {
+					return nil, fmt.Errorf("step %s: unknown %s value %q", step, selection, v)
+				}
+			}
+		}
+	}
+	return conditions, nil
+}
+
+// selectionsBefore returns the selections made before step, and false if
+// the step cannot be conditional.
+func selectionsBefore(step string) (map[string]bool, bool) {
+	earlier := make(map[string]bool)
+	for _, c := range conditionalSteps {
+		if c.step == step {
+			return earlier, true
+		}
+		if c.selection != "" {
+			earlier[c.selection] = true
+		}
+	}
+	return nil, false
+}
+
+// SetStepConditions replaces the session's step conditions.
+// It must be called before Start.
+func (s *OnboardingSession) SetStepConditions(conditions StepConditions) {
+	s.stepConditions = conditions
+}
+
+// RecordSelection stores a step 3 choice for later step conditions.
+func (s *OnboardingSession) RecordSelection(selection, value string) {
+	s.eventsMu.Lock()
+	defer s.eventsMu.Unlock()
+
+	if s.selections == nil {
+		s.selections = make(map[string]string)
+	}
+	s.selections[selection] = value
+}
+
+// selected returns the values chosen for a selection. Events allow several.
+func (s *OnboardingSession) selected(selection string) []string {
+	if onboard.MultiChoice(selection) {
+		return s.SelectedEvents()
+	}
+
+	s.eventsMu.Lock()
+	defer s.eventsMu.Unlock()
+	if v, ok := s.selections[selection]; ok {
+		return []string{v}
+	}
+	return nil
+}
+
+// stepVisible evaluates the step's condition. A selection that was never
+// made, e.g. because its prompt was hidden, does not match.
+func (s *OnboardingSession) stepVisible(step string) bool {
+	cond, ok := s.stepConditions[step]
+	if !ok {
+		return true
+	}
+
+	// Sorted so the logged reason is stable
+	selections := make([]string, 0, len(cond.ShowIf))
+	for selection := range cond.ShowIf {
+		selections = append(selections, selection)
+	}
+	sort.Strings(selections)
+
+	for _, selection := range selections {
+		if !matchesAny(s.selected(selection), cond.ShowIf[selection]) {
+			s.Record(onboard.TimelineStep, step+" skipped")
+			s.logger.Debug("step hidden by condition", "step", step, "selection", selection, "user_id", s.userID)
+			return false
+		}
+	}
+	return true
+}
+
+func matchesAny(chosen, allowed []string) bool {
+	for _, v := range chosen {
+		if contains(allowed, v) {
+			return true
+		}
+	}
+	return false
+}
+
+func contains(values []string, v string) bool {
+	for _, value := range values {
+		if value == v {
+			return true
+		}
+	}
+	return false
+}
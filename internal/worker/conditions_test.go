@@ -0,0 +1,75 @@
+package worker
+
+import (
+	"slices"
+	"testing"
+
+	"welcomebot/internal/fakes"
+	"welcomebot/internal/onboard"
+)
+
+func TestConditionalStepsFollowSelections(t *testing.T) {
+	var names []string
+	for _, c := range conditionalSteps {
+		if c.selection != "" {
+			names = append(names, c.selection)
+		}
+	}
+	if !slices.Equal(names, onboard.SelectionNames()) {
+		t.Errorf("step 3 prompts record %v, want %v", names, onboard.SelectionNames())
+	}
+}
+
+func TestParseStepConditions(t *testing.T) {
+	conditions, err := ParseStepConditions(`{
+		"step6": {"show_if": {"age": ["30late", "40early"], "event": ["bunnyclub"]}},
+		"step3.eroipu": {"show_if": {"gender": ["female"]}}
+	}`)
+	if err != nil {
+		t.Fatalf("ParseStepConditions: %v", err)
+	}
+	if got := conditions["step6"].ShowIf["age"]; len(got) != 2 {
+		t.Errorf("expected two age values, got %v", got)
+	}
+
+	if conditions, err := ParseStepConditions(""); err != nil || len(conditions) != 0 {
+		t.Errorf("expected no conditions for an empty string, got %v, %v", conditions, err)
+	}
+
+	for _, bad := range []string{
+		`{"step7": {"show_if": {"age": ["20early"]}}}`,   // step cannot be skipped
+		`{"step3.age": {"show_if": {"eroipu": ["ok"]}}}`, // selected after the step
+		`{"step4": {"show_if": {"age": ["50early"]}}}`,   // unknown value
+		`{"step4": {"show_if": {"height": ["tall"]}}}`,   // unknown selection
+		`{"step4": {"show_if": {"age": "20early"}}}`,     // not a list
+	} {
+		if _, err := ParseStepConditions(bad); err == nil {
+			t.Errorf("expected error for %s", bad)
+		}
+	}
+}
+
+func TestStepVisible(t *testing.T) {
+	s := &OnboardingSession{logger: fakes.Logger{}}
+	s.SetStepConditions(StepConditions{
+		"step6":        {ShowIf: map[string][]string{"age": {"30late", "40early"}}},
+		"step5":        {ShowIf: map[string][]string{"event": {"user"}}},
+		"step3.eroipu": {ShowIf: map[string][]string{"voice": {"low"}}},
+	})
+
+	s.RecordSelection("age", "40early")
+	s.ToggleEvent("bunnyclub")
+
+	if !s.stepVisible("step6") {
+		t.Error("expected step6 to be shown for 40early")
+	}
+	if s.stepVisible("step5") {
+		t.Error("expected step5 to be hidden without the user event")
+	}
+	if s.stepVisible("step3.eroipu") {
+		t.Error("expected a condition on a missing selection to hide the step")
+	}
+	if !s.stepVisible("step4") {
+		t.Error("expected steps without a condition to be shown")
+	}
+}
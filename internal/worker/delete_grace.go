@@ -0,0 +1,44 @@
+package worker
+
+import (
+	"sync"
+	"time"
+)
+
+// DefaultDeleteGrace is how long a completed session's voice channel is
+// kept so the user can read the final message before it disappears.
+const DefaultDeleteGrace = 5 * time.Second
+
+// pendingDeletes tracks voice channels waiting out their grace window.
+var pendingDeletes sync.WaitGroup
+
+// SetDeleteGrace replaces the delay between completion and deleting the
+// voice channel. Zero deletes it right away. It must be called before Start.
+func (s *OnboardingSession) SetDeleteGrace(grace time.Duration) {
+	s.deleteGrace = grace
+}
+
+// WaitPendingDeletes blocks until every delayed voice channel deletion has
+// finished. Deletions skip the rest of their grace window once the worker
+// context is cancelled, so this returns promptly on shutdown.
+func WaitPendingDeletes() {
+	pendingDeletes.Wait()
+}
+
+// deleteVoiceChannelLater deletes the voice channel after the grace window
+// without holding up the rest of cleanup.
+func (s *OnboardingSession) deleteVoiceChannelLater() {
+	pendingDeletes.Add(1)
+	go func() {
+		defer pendingDeletes.Done()
+
+		timer := time.NewTimer(s.deleteGrace)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-s.shutdown.Done():
+			s.logger.Debug("shutting down, deleting voice channel now", "channel_id", s.vcChannelID)
+		}
+		s.deleteVoiceChannel()
+	}()
+}
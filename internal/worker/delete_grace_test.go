@@ -0,0 +1,75 @@
+package worker
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"welcomebot/internal/fakes"
+	"welcomebot/internal/onboard"
+)
+
+func TestCompletedSessionKeepsChannelDuringGrace(t *testing.T) {
+	d := fakes.NewDiscord()
+	s, c := newCleanupSession(t, d, &fakes.AudioPlayer{})
+	s.shutdown = context.Background()
+	s.deleteGrace = 50 * time.Millisecond
+	s.setOutcome(onboard.OutcomeCompleted)
+
+	s.cleanup()
+
+	// The slave is free before the channel goes
+	status, _ := c.Get(context.Background(), "welcomebot:slaves:status:slave-1")
+	if status != "available" {
+		t.Errorf("expected slave to be freed immediately, got %q", status)
+	}
+	if n := d.Count(http.MethodDelete, "channels/vc-1"); n != 0 {
+		t.Errorf("expected channel to be kept during the grace window, got %d deletes", n)
+	}
+
+	WaitPendingDeletes()
+	if n := d.Count(http.MethodDelete, "channels/vc-1"); n != 1 {
+		t.Errorf("expected channel to be deleted after the grace window, got %d deletes", n)
+	}
+}
+
+func TestDeleteGraceEndsOnShutdown(t *testing.T) {
+	d := fakes.NewDiscord()
+	s, _ := newCleanupSession(t, d, &fakes.AudioPlayer{})
+	shutdown, stop := context.WithCancel(context.Background())
+	s.shutdown = shutdown
+	s.deleteGrace = time.Hour
+	s.setOutcome(onboard.OutcomeCompleted)
+
+	s.cleanup()
+	stop()
+
+	done := make(chan struct{})
+	go func() {
+		WaitPendingDeletes()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected shutdown to cut the grace window short")
+	}
+	if n := d.Count(http.MethodDelete, "channels/vc-1"); n != 1 {
+		t.Errorf("expected channel to be deleted on shutdown, got %d deletes", n)
+	}
+}
+
+func TestFailedSessionDeletesChannelImmediately(t *testing.T) {
+	d := fakes.NewDiscord()
+	s, _ := newCleanupSession(t, d, &fakes.AudioPlayer{})
+	s.shutdown = context.Background()
+	s.deleteGrace = time.Hour
+	s.setOutcome(onboard.OutcomeFailed)
+
+	s.cleanup()
+
+	if n := d.Count(http.MethodDelete, "channels/vc-1"); n != 1 {
+		t.Errorf("expected no grace window for a failed session, got %d deletes", n)
+	}
+}
@@ -0,0 +1,187 @@
+package worker
+
+import (
+	"fmt"
+
+	"welcomebot/internal/onboard"
+)
+
+// StepType says how a step of the onboarding flow is presented.
+type StepType string
+
+// Step types.
+const (
+	StepMessage StepType = "message" // Text, guide images and audio, then a next button
+	StepRoles   StepType = "roles"   // Step 3's role prompts, ending in a summary
+	StepFinish  StepType = "finish"  // Text and audio, then the complete button
+)
+
+// StepDef describes one step of the onboarding flow.
+type StepDef struct {
+	ID     string // Key of the step's timeline entries, condition, timeout and replay mode, e.g. "step4"
+	Number int    // Step number members see and the step's buttons carry
+	Type   StepType
+	Audio  string // Manifest key of the step's audio, e.g. AudioStep4
+	Images bool   // Whether the guide's images for Audio are sent
+
+	// AddRoles are given as the step starts, but not again when it is
+	// replayed
+	AddRoles []string
+
+	// SkipIfRole skips the step for members who already have the role,
+	// e.g. ones who attended part 3 in an earlier session
+	SkipIfRole string
+
+	// Condition hides the step unless the member's selections match; nil
+	// always shows it
+	Condition *Condition
+
+	show func(s *OnboardingSession, def StepDef) error
+}
+
+// Flow is the ordered list of steps a session moves through. Sessions
+// advance by position in the flow, so what comes next is decided here
+// rather than by each step.
+type Flow []StepDef
+
+// NewFlow builds the default flow: steps 1 to 7 in order, with the roles
+// in config and the step conditions.
+func NewFlow(config onboard.OnboardingConfig, conditions StepConditions) Flow {
+	flow := Flow{
+		{ID: "step1", Number: 1, Type: StepMessage, Audio: onboard.AudioStep1, Images: true, show: (*OnboardingSession).showStep1},
+		{ID: "step2", Number: 2, Type: StepMessage, Audio: onboard.AudioStep2, Images: true, AddRoles: roleList(config.Setsumeikai2Role), show: (*OnboardingSession).showStep2},
+		{ID: "step3", Number: 3, Type: StepRoles, Audio: onboard.AudioStep3, SkipIfRole: config.Setsumeikai3Role, show: (*OnboardingSession).showStep3},
+		{ID: "step4", Number: 4, Type: StepMessage, Audio: onboard.AudioStep4, Images: true, show: (*OnboardingSession).showStep4},
+		{ID: "step5", Number: 5, Type: StepMessage, Audio: onboard.AudioStep5, show: (*OnboardingSession).showStep5},
+		{ID: "step6", Number: 6, Type: StepMessage, Audio: onboard.AudioStep6, Images: true, show: (*OnboardingSession).showStep6},
+		{ID: "step7", Number: 7, Type: StepFinish, Audio: onboard.AudioStep7, show: (*OnboardingSession).showStep7},
+	}
+	for n := range flow {
+		if cond, ok := conditions[flow[n].ID]; ok {
+			flow[n].Condition = &cond
+		}
+	}
+	return flow
+}
+
+// Index returns the position of the step numbered number, or -1 if the
+// flow has no such step.
+func (f Flow) Index(number int) int {
+	for n, def := range f {
+		if def.Number == number {
+			return n
+		}
+	}
+	return -1
+}
+
+// roleList returns the role as a list, or nil if it isn't configured.
+func roleList(roleID string) []string {
+	if roleID == "" {
+		return nil
+	}
+	return []string{roleID}
+}
+
+// Flow returns the steps this session goes through, built from its roles
+// and step conditions.
+func (s *OnboardingSession) Flow() Flow {
+	return NewFlow(onboard.OnboardingConfig{
+		Setsumeikai2Role: s.Setsumeikai2RoleID,
+		Setsumeikai3Role: s.Setsumeikai3RoleID,
+	}, s.stepConditions)
+}
+
+// StartStep1 begins the flow with the guide the user confirmed.
+func (s *OnboardingSession) StartStep1(guide string) error {
+	s.selectedGuide = guide
+	s.recordGuideUse(guide)
+	return s.startFrom(s.Flow(), 0)
+}
+
+// Advance starts the step that follows step in the flow, skipping steps
+// the member shouldn't see.
+func (s *OnboardingSession) Advance(step int) error {
+	next, err := s.NextStep(step)
+	if err != nil {
+		return err
+	}
+	return s.EnterStep(next)
+}
+
+// NextStep returns the number of the step Advance(step) would start, so a
+// button can say where it leads before the step is shown.
+func (s *OnboardingSession) NextStep(step int) (int, error) {
+	flow := s.Flow()
+	n := flow.Index(step)
+	if n < 0 {
+		return 0, fmt.Errorf("step %d is not in the onboarding flow", step)
+	}
+	for m := n + 1; m < len(flow); m++ {
+		if !s.skipStep(flow[m]) {
+			return flow[m].Number, nil
+		}
+	}
+	return 0, fmt.Errorf("no onboarding step left after step %d", step)
+}
+
+// EnterStep starts the step numbered step, without checking whether the
+// member should skip it; use it with a step from NextStep.
+func (s *OnboardingSession) EnterStep(step int) error {
+	flow := s.Flow()
+	n := flow.Index(step)
+	if n < 0 {
+		return fmt.Errorf("step %d is not in the onboarding flow", step)
+	}
+	return s.enterStep(flow, n)
+}
+
+// startFrom starts the first step from position index on that the member
+// should see.
+func (s *OnboardingSession) startFrom(flow Flow, index int) error {
+	for n := index; n < len(flow); n++ {
+		if s.skipStep(flow[n]) {
+			continue
+		}
+		return s.enterStep(flow, n)
+	}
+	return fmt.Errorf("no onboarding step left after position %d", index)
+}
+
+// skipStep reports whether the member skips def, because they have its
+// skip role or its condition hides it.
+func (s *OnboardingSession) skipStep(def StepDef) bool {
+	if s.hasRole(def.SkipIfRole) {
+		s.logger.Info("member already has the step's role, skipping it", "user_id", s.userID, "step", def.ID)
+		return true
+	}
+	return def.Condition != nil && !s.stepVisible(def.ID)
+}
+
+// enterStep makes the step at position index current and shows it.
+// Entering a step completes the one before it, which is checkpointed.
+func (s *OnboardingSession) enterStep(flow Flow, index int) error {
+	def := flow[index]
+	s.closePreviews()
+	s.stepMu.Lock()
+	s.currentStep = def.Number
+	s.leftStep = 0
+	s.stepMu.Unlock()
+	s.Record(onboard.TimelineStep, def.ID)
+	s.currentSubStep = 0
+	s.UpdateActivity()
+	if index > 0 {
+		s.saveCheckpoint(flow[index-1].Number)
+	}
+
+	// A full replay already gave the step's roles
+	if !s.replaying {
+		for _, roleID := range def.AddRoles {
+			if err := s.addRole(roleID); err != nil {
+				s.logger.Warn("failed to add step role", "error", err, "step", def.ID, "role_id", roleID)
+			}
+		}
+	}
+
+	return def.show(s, def)
+}
@@ -0,0 +1,94 @@
+package worker
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"welcomebot/internal/fakes"
+	"welcomebot/internal/onboard"
+)
+
+func TestNewFlowDefault(t *testing.T) {
+	conditions := StepConditions{"step6": {ShowIf: map[string][]string{"age": {"30late"}}}}
+	flow := NewFlow(onboard.OnboardingConfig{Setsumeikai2Role: "role-2", Setsumeikai3Role: "role-3"}, conditions)
+
+	if len(flow) != 7 {
+		t.Fatalf("expected 7 steps, got %d", len(flow))
+	}
+	for n, def := range flow {
+		if def.Number != n+1 || def.show == nil {
+			t.Errorf("position %d: unexpected step %+v", n, def)
+		}
+	}
+	if got := flow[1].AddRoles; len(got) != 1 || got[0] != "role-2" {
+		t.Errorf("expected step 2 to add role-2, got %v", got)
+	}
+	if flow[2].SkipIfRole != "role-3" || flow[2].Type != StepRoles {
+		t.Errorf("expected step 3 to be the roles step skipped for role-3, got %+v", flow[2])
+	}
+	if flow[5].Condition == nil || flow[4].Condition != nil {
+		t.Error("expected only step 6 to carry a condition")
+	}
+	if flow[6].Type != StepFinish {
+		t.Errorf("expected step 7 to finish, got %s", flow[6].Type)
+	}
+
+	if flow.Index(4) != 3 || flow.Index(8) != -1 {
+		t.Errorf("unexpected Index results %d and %d", flow.Index(4), flow.Index(8))
+	}
+	if got := NewFlow(onboard.OnboardingConfig{}, nil)[1].AddRoles; got != nil {
+		t.Errorf("expected no roles without config, got %v", got)
+	}
+}
+
+func TestAdvanceSkipsHiddenStep(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	s := &OnboardingSession{
+		ctx:           context.Background(),
+		guildID:       "guild-1",
+		userID:        "user-1",
+		vcChannelID:   "vc-1",
+		selectedGuide: "kk",
+		currentStep:   5,
+		stepConditions: StepConditions{
+			"step6": {ShowIf: map[string][]string{"age": {"30late"}}},
+		},
+		selections: map[string]string{"age": "20early"},
+		session:    fakes.NewDiscord().Session(),
+		cache:      fakes.NewCache(),
+		db:         fakes.NewDB(),
+		player:     &fakes.AudioPlayer{},
+		logger:     fakes.Logger{},
+		i18n:       fakes.I18n{},
+	}
+
+	if err := s.Advance(5); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if s.currentStep != 7 {
+		t.Errorf("expected step 6 to be skipped, got step %d", s.currentStep)
+	}
+
+	if err := s.Advance(7); err == nil {
+		t.Error("expected advancing past the last step to fail")
+	}
+	if err := s.Advance(9); err == nil {
+		t.Error("expected advancing from an unknown step to fail")
+	}
+}
+
+func TestSendStepImagesFailsOnCaption(t *testing.T) {
+	d := fakes.NewDiscord()
+	s, _ := newCleanupSession(t, d, &fakes.AudioPlayer{})
+	s.ctx = context.Background()
+	s.i18n = fakes.I18n{}
+
+	// Step 6's second image is captioned with the second part of its text
+	d.FailRequests(http.MethodPost, "channels/vc-1/messages", 1)
+	err := s.sendStepImages("no-such-guide", StepDef{Audio: onboard.AudioStep6, Images: true})
+	if err == nil {
+		t.Fatal("expected the failed caption returned")
+	}
+}
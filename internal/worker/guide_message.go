@@ -0,0 +1,42 @@
+package worker
+
+// PinGuideMessage pins the message showing guide selection, so it stays
+// easy to find while the member works through the steps, replacing an
+// earlier pin from this session. Pinning needs Manage Messages in the
+// channel; without it the message simply stays unpinned.
+func (s *OnboardingSession) PinGuideMessage(messageID string) {
+	s.guideMessageMu.Lock()
+	defer s.guideMessageMu.Unlock()
+
+	previous := s.guideMessageID
+	if messageID == "" || messageID == previous {
+		return
+	}
+
+	if err := s.session.ChannelMessagePin(s.vcChannelID, messageID); err != nil {
+		s.logger.Warn("failed to pin guide selection message", "channel_id", s.vcChannelID, "message_id", messageID, "error", err)
+		return
+	}
+	s.guideMessageID = messageID
+
+	if previous != "" {
+		if err := s.session.ChannelMessageUnpin(s.vcChannelID, previous); err != nil {
+			s.logger.Debug("failed to unpin previous guide selection message", "message_id", previous, "error", err)
+		}
+	}
+}
+
+// unpinGuideMessage removes the session's pin. Only the shared VC needs
+// this; a private VC is deleted along with its pins.
+func (s *OnboardingSession) unpinGuideMessage() {
+	s.guideMessageMu.Lock()
+	defer s.guideMessageMu.Unlock()
+
+	if s.guideMessageID == "" {
+		return
+	}
+	if err := s.session.ChannelMessageUnpin(s.vcChannelID, s.guideMessageID); err != nil {
+		s.logger.Warn("failed to unpin guide selection message", "message_id", s.guideMessageID, "error", err)
+	}
+	s.guideMessageID = ""
+}
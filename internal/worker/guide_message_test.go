@@ -0,0 +1,59 @@
+package worker
+
+import (
+	"net/http"
+	"testing"
+
+	"welcomebot/internal/fakes"
+)
+
+func TestPinGuideMessageReplacesEarlierPin(t *testing.T) {
+	d := fakes.NewDiscord()
+	s, _ := newCleanupSession(t, d, &fakes.AudioPlayer{})
+
+	s.PinGuideMessage("msg-1")
+	s.PinGuideMessage("msg-1")
+	s.PinGuideMessage("msg-2")
+
+	if n := d.Count(http.MethodPut, "channels/vc-1/pins/msg-1"); n != 1 {
+		t.Errorf("expected the first message to be pinned once, got %d pins", n)
+	}
+	if n := d.Count(http.MethodPut, "channels/vc-1/pins/msg-2"); n != 1 {
+		t.Errorf("expected the later message to be pinned, got %d pins", n)
+	}
+	if n := d.Count(http.MethodDelete, "channels/vc-1/pins/msg-1"); n != 1 {
+		t.Errorf("expected the earlier pin to be removed, got %d unpins", n)
+	}
+}
+
+func TestPinGuideMessageFailureKeepsEarlierPin(t *testing.T) {
+	d := fakes.NewDiscord()
+	s, _ := newCleanupSession(t, d, &fakes.AudioPlayer{})
+	s.PinGuideMessage("msg-1")
+	d.FailRequests(http.MethodPut, "channels/vc-1/pins/msg-2", 1)
+
+	s.PinGuideMessage("msg-2")
+
+	if n := d.Count(http.MethodDelete, "channels/vc-1/pins/msg-1"); n != 0 {
+		t.Errorf("expected the earlier pin to stay when pinning fails, got %d unpins", n)
+	}
+	if s.guideMessageID != "msg-1" {
+		t.Errorf("expected msg-1 to stay pinned, got %q", s.guideMessageID)
+	}
+}
+
+func TestCleanupUnpinsGuideMessageInSharedVC(t *testing.T) {
+	d := fakes.NewDiscord()
+	s, _ := newCleanupSession(t, d, &fakes.AudioPlayer{})
+	s.sharedVC = true
+	s.PinGuideMessage("msg-1")
+
+	s.cleanup()
+
+	if n := d.Count(http.MethodDelete, "channels/vc-1/pins/msg-1"); n != 1 {
+		t.Errorf("expected the pin to be removed from the shared VC, got %d unpins", n)
+	}
+	if n := d.Count(http.MethodDelete, "channels/vc-1"); n != 0 {
+		t.Errorf("expected the shared VC to be kept, got %d deletes", n)
+	}
+}
@@ -0,0 +1,39 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+)
+
+// maxOptionDescription is the longest description Discord accepts on a
+// select menu option, in characters.
+const maxOptionDescription = 100
+
+// guideName returns a guide's localized display name
+// (onboarding.guides.<guide>.name), or the guide's directory name when it
+// has none.
+func (s *OnboardingSession) guideName(ctx context.Context, guide string) string {
+	key := fmt.Sprintf("onboarding.guides.%s.name", guide)
+
+	// T returns the key itself when no translation exists
+	if name := s.i18n.T(ctx, s.guildID, key); name != key {
+		return name
+	}
+	return guide
+}
+
+// guideDescription returns a guide's localized description
+// (onboarding.guides.<guide>.description) shortened to fit a select menu
+// option, or "" when it has none.
+func (s *OnboardingSession) guideDescription(ctx context.Context, guide string) string {
+	key := fmt.Sprintf("onboarding.guides.%s.description", guide)
+
+	description := s.i18n.T(ctx, s.guildID, key)
+	if description == key {
+		return ""
+	}
+	if r := []rune(description); len(r) > maxOptionDescription {
+		description = string(r[:maxOptionDescription-1]) + "…"
+	}
+	return description
+}
@@ -0,0 +1,41 @@
+package worker
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"welcomebot/internal/fakes"
+	"welcomebot/internal/onboard"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func TestGuideSelectionDescriptions(t *testing.T) {
+	t.Chdir(t.TempDir())
+	for _, dir := range []string{"kk", "plain"} {
+		if err := os.MkdirAll(onboard.GuideDir(dir), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	s := &OnboardingSession{logger: fakes.Logger{}, i18n: translations{
+		"onboarding.guides.kk.name":        "KK",
+		"onboarding.guides.kk.description": strings.Repeat("friendly ", 20),
+	}}
+	rows := s.BuildGuideSelectionComponents()
+	options := rows[1].(discordgo.ActionsRow).Components[0].(discordgo.SelectMenu).Options
+
+	if options[0].Label != "KK" {
+		t.Errorf("expected the localized name, got %q", options[0].Label)
+	}
+	if n := utf8.RuneCountInString(options[0].Description); n != maxOptionDescription || !strings.HasSuffix(options[0].Description, "…") {
+		t.Errorf("expected the description cut to %d characters, got %d: %q", maxOptionDescription, n, options[0].Description)
+	}
+
+	// Without translations the guide is listed by its directory name alone
+	if options[1].Label != "plain" || options[1].Description != "" {
+		t.Errorf("expected a plain option, got %+v", options[1])
+	}
+}
@@ -0,0 +1,64 @@
+package worker
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// InactivityWarning configures the notice posted before an idle session
+// is closed.
+type InactivityWarning struct {
+	Threshold float64 // Fraction of the inactivity timeout after which to warn; 0 disables
+	Message   string  // Translation key or literal text; {minutes} is the time left
+}
+
+// DefaultInactivityWarning warns four minutes before the 20 minute cutoff.
+var DefaultInactivityWarning = InactivityWarning{
+	Threshold: 0.8,
+	Message:   "onboarding.inactivity_warning",
+}
+
+// SetInactivityWarning replaces the session's inactivity warning settings.
+// It must be called before Start.
+func (s *OnboardingSession) SetInactivityWarning(warning InactivityWarning) {
+	s.inactivityWarning = warning
+}
+
+// checkInactivity reports whether the session should be warned or closed
+// at now. A warning is given once per idle period: activity after a
+// warning starts a new period that can be warned about again.
+func (s *OnboardingSession) checkInactivity(now time.Time) (warn, expire bool) {
+	lastActivity := s.lastActive()
+	idle := now.Sub(lastActivity)
+	if idle > inactivityTimeout {
+		return false, true
+	}
+
+	threshold := s.inactivityWarning.Threshold
+	if threshold <= 0 || threshold >= 1 || s.warnedActivity.Equal(lastActivity) {
+		return false, false
+	}
+	if idle < time.Duration(threshold*float64(inactivityTimeout)) {
+		return false, false
+	}
+
+	s.warnedActivity = lastActivity
+	return true, false
+}
+
+// warnInactive posts the inactivity warning in the voice channel.
+func (s *OnboardingSession) warnInactive(now time.Time) {
+	remaining := inactivityTimeout - now.Sub(s.lastActive())
+	minutes := int(math.Ceil(remaining.Minutes()))
+
+	message := s.i18n.TWithArgs(s.ctx, s.guildID, s.inactivityWarning.Message, map[string]string{
+		"minutes": fmt.Sprintf("%d", minutes),
+	})
+	if _, err := s.session.ChannelMessageSend(s.vcChannelID, message); err != nil {
+		s.logger.Warn("failed to send inactivity warning", "error", err)
+		return
+	}
+
+	s.logger.Info("session idle, warned user", "user_id", s.userID, "minutes_left", minutes)
+}
@@ -0,0 +1,60 @@
+package worker
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestCheckInactivity(t *testing.T) {
+	start := time.Now()
+	s := &OnboardingSession{lastActivity: start, inactivityWarning: DefaultInactivityWarning}
+
+	if warn, expire := s.checkInactivity(start.Add(10 * time.Minute)); warn || expire {
+		t.Errorf("at 10m: warn=%v expire=%v, want neither", warn, expire)
+	}
+
+	// 80% of 20 minutes
+	if warn, _ := s.checkInactivity(start.Add(16 * time.Minute)); !warn {
+		t.Error("expected a warning at 16m")
+	}
+	if warn, _ := s.checkInactivity(start.Add(17 * time.Minute)); warn {
+		t.Error("expected only one warning per idle period")
+	}
+	if _, expire := s.checkInactivity(start.Add(21 * time.Minute)); !expire {
+		t.Error("expected the session to expire after 20m")
+	}
+
+	// Activity starts a new idle period
+	s.lastActivity = start.Add(18 * time.Minute)
+	if warn, _ := s.checkInactivity(s.lastActivity.Add(17 * time.Minute)); !warn {
+		t.Error("expected a new warning after fresh activity")
+	}
+}
+
+func TestCheckInactivityDisabled(t *testing.T) {
+	start := time.Now()
+	s := &OnboardingSession{lastActivity: start, inactivityWarning: InactivityWarning{}}
+
+	if warn, expire := s.checkInactivity(start.Add(19 * time.Minute)); warn || expire {
+		t.Errorf("warn=%v expire=%v, want neither when warnings are disabled", warn, expire)
+	}
+}
+
+func TestSessionStale(t *testing.T) {
+	start := time.Now()
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &OnboardingSession{ctx: ctx, lastActivity: start}
+
+	if s.Stale(start.Add(30 * time.Minute)) {
+		t.Error("expected a running session to be kept")
+	}
+	if !s.Stale(start.Add(sessionTimeout + time.Minute)) {
+		t.Error("expected a session idle past the session timeout to be stale")
+	}
+
+	cancel()
+	if !s.Stale(start) {
+		t.Error("expected an ended session to be stale")
+	}
+}
@@ -0,0 +1,101 @@
+package worker
+
+import (
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// memberRolesTTL is how long a fetched role list is trusted. Changes made
+// through MemberRoles update it directly; the TTL only bounds how stale
+// changes made elsewhere (master, moderators) can be.
+const memberRolesTTL = 30 * time.Second
+
+// MemberRoles caches members' current roles so role changes that would be
+// no-ops, such as re-adding a role when a step is re-entered, can be
+// skipped. A nil *MemberRoles never skips.
+type MemberRoles struct {
+	session *discordgo.Session
+	now     func() time.Time
+
+	mu      sync.Mutex
+	members map[string]memberRoles // guildID:userID
+}
+
+type memberRoles struct {
+	roles     map[string]bool
+	fetchedAt time.Time
+}
+
+// NewMemberRoles creates a role cache that fetches members through session.
+func NewMemberRoles(session *discordgo.Session) *MemberRoles {
+	return &MemberRoles{
+		session: session,
+		now:     time.Now,
+		members: make(map[string]memberRoles),
+	}
+}
+
+// Has reports whether the member has the role. ok is false when the roles
+// could not be fetched; callers should then make the change anyway.
+func (m *MemberRoles) Has(guildID, userID, roleID string) (has, ok bool) {
+	if m == nil {
+		return false, false
+	}
+
+	key := guildID + ":" + userID
+	m.mu.Lock()
+	entry, cached := m.members[key]
+	m.mu.Unlock()
+
+	if !cached || m.now().Sub(entry.fetchedAt) > memberRolesTTL {
+		member, err := m.session.GuildMember(guildID, userID)
+		if err != nil {
+			return false, false
+		}
+		entry = memberRoles{roles: make(map[string]bool, len(member.Roles)), fetchedAt: m.now()}
+		for _, id := range member.Roles {
+			entry.roles[id] = true
+		}
+		m.mu.Lock()
+		m.members[key] = entry
+		m.mu.Unlock()
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.members[key].roles[roleID], true
+}
+
+// Set records a role change that was applied.
+func (m *MemberRoles) Set(guildID, userID, roleID string, has bool) {
+	if m == nil {
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	entry, cached := m.members[guildID+":"+userID]
+	if !cached {
+		return
+	}
+	entry.roles[roleID] = has
+}
+
+// Forget drops the cached roles of a member, e.g. when their session ends.
+func (m *MemberRoles) Forget(guildID, userID string) {
+	if m == nil {
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.members, guildID+":"+userID)
+}
+
+// SetMemberRoles shares a role cache with the session. It must be called
+// before Start.
+func (s *OnboardingSession) SetMemberRoles(m *MemberRoles) {
+	s.memberRoles = m
+}
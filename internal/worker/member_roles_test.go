@@ -0,0 +1,74 @@
+package worker
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"welcomebot/internal/fakes"
+)
+
+func TestMemberRolesCachesFetch(t *testing.T) {
+	d := fakes.NewDiscord()
+	d.SetMemberRoles("guild-1", "user-1", "role-a")
+	m := NewMemberRoles(d.Session())
+	now := time.Now()
+	m.now = func() time.Time { return now }
+
+	if has, ok := m.Has("guild-1", "user-1", "role-a"); !ok || !has {
+		t.Fatalf("expected role-a to be present, got has=%v ok=%v", has, ok)
+	}
+	m.Set("guild-1", "user-1", "role-b", true)
+	if has, _ := m.Has("guild-1", "user-1", "role-b"); !has {
+		t.Error("expected a recorded add to be visible")
+	}
+	if n := d.Count(http.MethodGet, "guilds/guild-1/members/user-1"); n != 1 {
+		t.Errorf("expected one member fetch, got %d", n)
+	}
+
+	// Past the TTL the member is fetched again
+	now = now.Add(memberRolesTTL + time.Second)
+	if has, _ := m.Has("guild-1", "user-1", "role-b"); has {
+		t.Error("expected stale entry to be refreshed from Discord")
+	}
+	if n := d.Count(http.MethodGet, "guilds/guild-1/members/user-1"); n != 2 {
+		t.Errorf("expected a refetch after the TTL, got %d fetches", n)
+	}
+}
+
+func TestAddRoleSkipsPresentRole(t *testing.T) {
+	d := fakes.NewDiscord()
+	d.SetMemberRoles("guild-1", "user-1", "role-a")
+	s := &OnboardingSession{
+		guildID:     "guild-1",
+		userID:      "user-1",
+		session:     d.Session(),
+		logger:      fakes.Logger{},
+		ctx:         context.Background(),
+		memberRoles: NewMemberRoles(d.Session()),
+	}
+
+	if err := s.addRole("role-a"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.removeRole("role-b"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.addRole("role-b"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.addRole("role-b"); err != nil {
+		t.Fatal(err)
+	}
+
+	if n := d.Count(http.MethodPut, "guilds/guild-1/members/user-1/roles/role-a"); n != 0 {
+		t.Errorf("expected no request for a role already held, got %d", n)
+	}
+	if n := d.Count(http.MethodDelete, "guilds/guild-1/members/user-1/roles/role-b"); n != 0 {
+		t.Errorf("expected no request to remove a missing role, got %d", n)
+	}
+	if n := d.Count(http.MethodPut, "guilds/guild-1/members/user-1/roles/role-b"); n != 1 {
+		t.Errorf("expected role-b to be added once, got %d", n)
+	}
+}
@@ -0,0 +1,86 @@
+package worker
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// maxMessageLength is Discord's limit on a message's content, in
+// characters. Longer messages are rejected outright.
+const maxMessageLength = 2000
+
+// splitBoundaries are where a long message is split, best first: between
+// paragraphs, between lines, after a sentence, between words.
+var splitBoundaries = []string{"\n\n", "\n", "。", ". ", " "}
+
+// splitMessage splits content into parts of at most limit characters. Each
+// part ends at the best boundary in its second half, or at the limit if
+// there is none, and whitespace at the split is dropped.
+func splitMessage(content string, limit int) []string {
+	var parts []string
+	for utf8.RuneCountInString(content) > limit {
+		cut := splitPoint(content, limit)
+		if part := strings.TrimRight(content[:cut], " \n"); part != "" {
+			parts = append(parts, part)
+		}
+		content = strings.TrimLeft(content[cut:], " \n")
+	}
+	if content != "" || len(parts) == 0 {
+		parts = append(parts, content)
+	}
+	return parts
+}
+
+// splitPoint returns the byte offset to split content at so the first part
+// fits in limit characters.
+func splitPoint(content string, limit int) int {
+	end := len(content)
+	for n := range content {
+		if limit == 0 {
+			end = n
+			break
+		}
+		limit--
+	}
+
+	head := content[:end]
+	for _, boundary := range splitBoundaries {
+		if n := strings.LastIndex(head, boundary); n > len(head)/2 {
+			return n + len(boundary)
+		}
+	}
+	return end
+}
+
+// sendText posts step text in the voice channel, split across messages if
+// it is over Discord's limit.
+func (s *OnboardingSession) sendText(content string) error {
+	_, err := s.sendMessage(&discordgo.MessageSend{Content: content})
+	return err
+}
+
+// sendSplit posts the leading parts of a message whose content is over
+// Discord's limit as plain messages, and returns a copy of data holding
+// the last part, to be sent with the embeds, files and components. A
+// verbose translation then still renders, and the buttons stay under the
+// end of the text.
+func (s *OnboardingSession) sendSplit(data *discordgo.MessageSend) (*discordgo.MessageSend, error) {
+	parts := splitMessage(data.Content, maxMessageLength)
+	s.logger.Info("message over Discord's length limit, splitting",
+		"length", utf8.RuneCountInString(data.Content),
+		"parts", len(parts),
+		"user_id", s.userID,
+	)
+
+	for _, part := range parts[:len(parts)-1] {
+		if _, err := s.sendMessage(&discordgo.MessageSend{Content: part}); err != nil {
+			return nil, err
+		}
+	}
+
+	last := *data
+	last.Content = parts[len(parts)-1]
+	return &last, nil
+}
@@ -0,0 +1,105 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/bwmarrin/discordgo"
+
+	"welcomebot/internal/fakes"
+)
+
+func TestSplitMessage(t *testing.T) {
+	if parts := splitMessage("short", 10); len(parts) != 1 || parts[0] != "short" {
+		t.Errorf("expected short content untouched, got %q", parts)
+	}
+
+	paragraphs := strings.Repeat("a", 6) + "\n\n" + strings.Repeat("b", 6)
+	if parts := splitMessage(paragraphs, 10); len(parts) != 2 || parts[0] != "aaaaaa" || parts[1] != "bbbbbb" {
+		t.Errorf("expected a split between paragraphs, got %q", parts)
+	}
+
+	// Characters, not bytes, count towards the limit
+	sentences := "あいうえおかきく。さしすせそ。"
+	parts := splitMessage(sentences, 10)
+	if len(parts) != 2 || parts[0] != "あいうえおかきく。" || parts[1] != "さしすせそ。" {
+		t.Errorf("expected a split after the sentence, got %q", parts)
+	}
+
+	// Without a boundary in the second half, cut at the limit
+	parts = splitMessage("a "+strings.Repeat("x", 25), 10)
+	for _, part := range parts {
+		if n := utf8.RuneCountInString(part); n > 10 {
+			t.Errorf("part of %d characters is over the limit: %q", n, part)
+		}
+	}
+	if got := strings.Join(parts, ""); got != "a "+strings.Repeat("x", 25) {
+		t.Errorf("expected the text kept, got %q", parts)
+	}
+}
+
+func TestSendMessageSplitsLongContent(t *testing.T) {
+	d := fakes.NewDiscord()
+	s, _ := newCleanupSession(t, d, &fakes.AudioPlayer{})
+	s.ctx = context.Background()
+
+	content := strings.Repeat(strings.Repeat("x", 99)+"\n", 30) // 3000 characters
+	_, err := s.sendMessage(&discordgo.MessageSend{
+		Content: content,
+		Components: []discordgo.MessageComponent{discordgo.ActionsRow{Components: []discordgo.MessageComponent{
+			discordgo.Button{Label: "Next", CustomID: "onboarding:step2_next:user-1"},
+		}}},
+	})
+	if err != nil {
+		t.Fatalf("send message: %v", err)
+	}
+
+	type message struct {
+		Content    string            `json:"content"`
+		Components []json.RawMessage `json:"components"`
+	}
+	var sent []message
+	for _, r := range d.Requests() {
+		if r.Method == http.MethodPost && r.Path == "channels/vc-1/messages" {
+			var msg message
+			if err := json.Unmarshal(r.Body, &msg); err != nil {
+				t.Fatal(err)
+			}
+			sent = append(sent, msg)
+		}
+	}
+	if len(sent) != 2 {
+		t.Fatalf("expected the content split over two messages, got %d", len(sent))
+	}
+	for n, msg := range sent {
+		if length := utf8.RuneCountInString(msg.Content); length > maxMessageLength {
+			t.Errorf("message %d is %d characters", n, length)
+		}
+	}
+	if len(sent[0].Components) != 0 || len(sent[1].Components) != 1 {
+		t.Error("expected the buttons on the last message only")
+	}
+}
+
+func TestSendMessageDoesNotRepeatServerErrors(t *testing.T) {
+	d := fakes.NewDiscord()
+	s, _ := newCleanupSession(t, d, &fakes.AudioPlayer{})
+	s.ctx = context.Background()
+
+	// The message may have been posted before the error, so it isn't sent again
+	d.FailRequests(http.MethodPost, "channels/vc-1/messages", 1)
+	_, err := s.sendMessage(&discordgo.MessageSend{
+		Content: "step 2",
+		Files:   []*discordgo.File{{Name: "image.png", Reader: strings.NewReader("png")}},
+	})
+	if err == nil {
+		t.Fatal("expected the server error returned")
+	}
+	if n := d.Count(http.MethodPost, "channels/vc-1/messages"); n != 1 {
+		t.Errorf("expected a single attempt, got %d", n)
+	}
+}
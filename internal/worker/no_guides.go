@@ -0,0 +1,29 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+
+	"welcomebot/internal/shared"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// sendNoGuidesMessage tells the member onboarding isn't available right
+// now. The voice channel is kept for the delete grace so it can be read.
+func (s *OnboardingSession) sendNoGuidesMessage() {
+	ctx := context.Background()
+	embed := &discordgo.MessageEmbed{
+		Title: s.i18n.T(ctx, s.guildID, "onboarding.no_guides_title"),
+		Description: s.i18n.TWithArgs(ctx, s.guildID, "onboarding.no_guides_description", map[string]string{
+			"user": fmt.Sprintf("<@%s>", s.userID),
+		}),
+		Color: int(shared.ColorError),
+	}
+
+	if _, err := s.sendMessage(&discordgo.MessageSend{Embeds: []*discordgo.MessageEmbed{embed}}); err != nil {
+		s.logger.Warn("failed to send no guides message", "error", err)
+		return
+	}
+	s.finalNotice = true
+}
@@ -1,20 +1,28 @@
 package worker
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
+	"sync"
 	"time"
+	"unicode/utf8"
 
+	"welcomebot/internal/audio"
 	"welcomebot/internal/core/cache"
 	"welcomebot/internal/core/database"
+	"welcomebot/internal/core/discord"
 	"welcomebot/internal/core/i18n"
 	"welcomebot/internal/core/logger"
 	"welcomebot/internal/core/queue"
+	"welcomebot/internal/onboard"
+	"welcomebot/internal/shared"
 
 	"github.com/bwmarrin/discordgo"
-	"github.com/jonas747/dca"
 )
 
 const (
@@ -22,17 +30,40 @@ const (
 	inactivityTimeout = 20 * time.Minute
 )
 
+// ErrCategoriesFull is returned when the onboarding category and every
+// overflow category have reached Discord's channel limit.
+var ErrCategoriesFull = errors.New("every onboarding category is full, try again later")
+
+// cleanupRetryDelay is the pause before retrying a failed channel delete.
+var cleanupRetryDelay = 2 * time.Second
+
 // OnboardingSession handles a single user's onboarding session.
+//
+// Start greets the member and offers the guides; from there the worker's
+// interaction handlers drive the session, confirming a guide with
+// StartStep1 and moving through the steps of its Flow with Advance. Every
+// button a session posts carries an "onboarding:" custom ID the worker
+// routes.
 type OnboardingSession struct {
 	guildID          string
 	userID           string
 	slaveID          string
 	categoryID       string
+	spreadIDs        []string // Categories sharing new channels with categoryID in turn
+	overflowIDs      []string // Categories tried when categoryID is full
 	vcChannelID      string
-	selectedGuide    string // Selected guide name (e.g., "kk")
-	currentStep      int    // Current tutorial step (0-7)
-	currentSubStep   int    // Current sub-step within a step (for multi-part steps like Step 3)
-	currentAudioFile string // Current audio file being played
+	sharedVC         bool     // vcChannelID is the guild's shared onboarding VC
+	textOnly         bool     // Audio is off; vcChannelID is a private text channel
+	vcNameTemplate   string   // Channel name template; empty uses the default
+	vcAccessRoles    []string // Roles also let into the channel, e.g. staff
+	vcAccessUsers    []string // Members also let into the channel
+	syncCategory     bool     // The channel keeps its category's permissions instead of hiding from @everyone
+	vcSeq            string   // Per-guild sequence number for {n}
+	ephemeralReplies bool     // Step confirmations visible only to the user
+	greetingGuide    string   // Guide whose greeting plays on join; empty for none
+	selectedGuide    string   // Selected guide name (e.g., "kk")
+	currentStep      int      // Current tutorial step (0-7); guarded by stepMu
+	currentSubStep   int      // Current sub-step within a step (for multi-part steps like Step 3)
 	inProgressRoleID string
 	completedRoleID  string
 	EntranceRoleID      string // Exported for handler access
@@ -42,6 +73,7 @@ type OnboardingSession struct {
 	Setsumeikai3RoleID  string // Exported for handler access
 	MemberRoleID        string // Exported for handler access
 	VisitorRoleID       string // Exported for handler access
+	InitialRoleID       string // Exported for handler access
 	// Age range roles (exported for handler access)
 	Age20EarlyRoleID string
 	Age20LateRoleID  string
@@ -70,140 +102,173 @@ type OnboardingSession struct {
 	FriendNgRoleID         string
 	BunnyclubEventRoleID   string
 	UserEventRoleID        string
+	guideCompletionRoles   map[string]onboard.CompletionRoles // Per-guide member/visitor overrides
+	completionChanges      onboard.CompletionChanges          // Guild's own completion roles, if set
+	selectedEvents         map[string]bool   // Event roles toggled on in step 3
+	selections             map[string]string // Other step 3 choices, for step conditions
+	eventsDone             bool              // 完了 was clicked on the event selection
+	eventsMu               sync.Mutex        // Guards selectedEvents, selections and eventsDone
+	stepConditions         StepConditions
+	resume                 *onboard.Checkpoint // Where to pick up an unfinished onboarding; nil starts at guide selection
 	startedAt              time.Time
 	lastActivity           time.Time
+	activityMu             sync.Mutex // Guards lastActivity, which the worker's sweeper also reads
+	inactivityWarning      InactivityWarning
+	warnedActivity         time.Time // lastActivity when the last inactivity warning was sent
+	stepTimeouts           StepTimeouts
+	stepTimedOut           time.Time // lastActivity when the last step timeout fired
+	stepMu                 sync.Mutex // Guards currentStep and leftStep; timeouts and buttons race on them
+	leftStep               int        // Step that a button or timeout has already claimed moving on from
+	replayModes            ReplayModes
+	buttonStyles           ButtonStyles // Per-role button styles; empty keeps the defaults
+	defaultAudioLocale     string       // Audio variant played when the guild's language has none
+	replaying              bool // Re-sending the current step; skip role changes
+	memberRoles            *MemberRoles // Skips role changes the member already has; nil disables
+	deleteGrace            time.Duration // Wait before deleting the VC after completion
+	finalNotice            bool          // A closing message was posted; keep the VC for deleteGrace
+	guideMessageID         string     // Pinned guide selection message
+	guideMessageMu         sync.Mutex // Guards guideMessageID
+	timeline               Timeline
+	outcome                string
+	outcomeOnce            sync.Once
+	audioMu                sync.Mutex // Guards audioGen; never held while the player starts a stream
+	audioGen               uint64     // Bumped by StopCurrentAudio; stale async plays are dropped
+	playMu                 sync.Mutex // Serializes starting streams, so a stale play can't replace a newer one
+	previewGuide           string     // Guide whose preview played last; guarded by audioMu
+	previewsClosed         bool       // The tutorial has started; guarded by audioMu
+	locales                []string   // Languages offered before guide selection; fewer than two skip the choice
+	locale                 string     // Language the member picked; empty uses the guild's
+	localeMu               sync.Mutex // Guards locale
 
 	session       *discordgo.Session
 	db            database.Client
 	cache         cache.Client
 	queue         queue.Client
+	events        queue.Client // Master-bound events (failures)
 	logger        logger.Logger
 	i18n          i18n.I18n
-	voiceConn     *discordgo.VoiceConnection
-	currentStream *dca.StreamingSession // Active audio stream
-	stopStream    chan struct{}         // Channel to signal stream stop
+	player        onboard.AudioPlayer
 	ctx           context.Context
 	cancel        context.CancelFunc
+	shutdown      context.Context // Worker context; cancelled on shutdown
 }
 
-// NewOnboardingSession creates a new onboarding session.
+// NewOnboardingSession creates a new onboarding session from a validated
+// start payload.
 func NewOnboardingSession(
 	ctx context.Context,
-	task *queue.Task,
+	guildID string,
+	payload *onboard.StartPayload,
 	session *discordgo.Session,
 	db database.Client,
 	cache cache.Client,
 	queue queue.Client,
+	events queue.Client,
 	logger logger.Logger,
 	i18nClient i18n.I18n,
+	player onboard.AudioPlayer,
 ) (*OnboardingSession, error) {
-	// Extract task payload
-	userID, ok := task.Payload["user_id"].(string)
-	if !ok {
-		return nil, fmt.Errorf("missing user_id in task payload")
-	}
-
-	categoryID, ok := task.Payload["category_id"].(string)
-	if !ok {
-		return nil, fmt.Errorf("missing category_id in task payload")
-	}
-
-	slaveID, ok := task.Payload["slave_id"].(string)
-	if !ok {
-		return nil, fmt.Errorf("missing slave_id in task payload")
-	}
-
-	// Optional role IDs
-	inProgressRole, _ := task.Payload["in_progress_role"].(string)
-	completedRole, _ := task.Payload["completed_role"].(string)
-	entranceRole, _ := task.Payload["entrance_role"].(string)
-	nyukaiRole, _ := task.Payload["nyukai_role"].(string)
-	setsumeikai1Role, _ := task.Payload["setsumeikai_1_role"].(string)
-	setsumeikai2Role, _ := task.Payload["setsumeikai_2_role"].(string)
-	setsumeikai3Role, _ := task.Payload["setsumeikai_3_role"].(string)
-	memberRole, _ := task.Payload["member_role"].(string)
-	visitorRole, _ := task.Payload["visitor_role"].(string)
-	// Age range roles
-	age20Early, _ := task.Payload["age_20_early_role"].(string)
-	age20Late, _ := task.Payload["age_20_late_role"].(string)
-	age30Early, _ := task.Payload["age_30_early_role"].(string)
-	age30Late, _ := task.Payload["age_30_late_role"].(string)
-	age40Early, _ := task.Payload["age_40_early_role"].(string)
-	age40Late, _ := task.Payload["age_40_late_role"].(string)
-	// Gender roles
-	maleRole, _ := task.Payload["male_role"].(string)
-	femaleRole, _ := task.Payload["female_role"].(string)
-	// Voice type roles
-	highVoice, _ := task.Payload["high_voice_role"].(string)
-	midHighVoice, _ := task.Payload["mid_high_voice_role"].(string)
-	midVoice, _ := task.Payload["mid_voice_role"].(string)
-	midLowVoice, _ := task.Payload["mid_low_voice_role"].(string)
-	lowVoice, _ := task.Payload["low_voice_role"].(string)
-	// Other roles
-	eroOk, _ := task.Payload["ero_ok_role"].(string)
-	eroNg, _ := task.Payload["ero_ng_role"].(string)
-	neochiOk, _ := task.Payload["neochi_ok_role"].(string)
-	neochiNg, _ := task.Payload["neochi_ng_role"].(string)
-	neochiDisconnect, _ := task.Payload["neochi_disconnect_role"].(string)
-	dmOk, _ := task.Payload["dm_ok_role"].(string)
-	dmNg, _ := task.Payload["dm_ng_role"].(string)
-	friendOk, _ := task.Payload["friend_ok_role"].(string)
-	friendNg, _ := task.Payload["friend_ng_role"].(string)
-	bunnyclubEvent, _ := task.Payload["bunnyclub_event_role"].(string)
-	userEvent, _ := task.Payload["user_event_role"].(string)
+	ephemeralReplies := payload.EphemeralConfirmations
+	sharedVCChannelID := payload.SharedVCChannelID
 
 	sessionCtx, cancel := context.WithTimeout(ctx, sessionTimeout)
 
-	return &OnboardingSession{
-		guildID:                task.GuildID,
-		userID:                 userID,
-		slaveID:                slaveID,
-		categoryID:             categoryID,
-		inProgressRoleID:       inProgressRole,
-		completedRoleID:        completedRole,
-		EntranceRoleID:         entranceRole,
-		NyukaiRoleID:           nyukaiRole,
-		Setsumeikai1RoleID:     setsumeikai1Role,
-		Setsumeikai2RoleID:     setsumeikai2Role,
-		Setsumeikai3RoleID:     setsumeikai3Role,
-		MemberRoleID:           memberRole,
-		VisitorRoleID:          visitorRole,
-		Age20EarlyRoleID:       age20Early,
-		Age20LateRoleID:        age20Late,
-		Age30EarlyRoleID:       age30Early,
-		Age30LateRoleID:        age30Late,
-		Age40EarlyRoleID:       age40Early,
-		Age40LateRoleID:        age40Late,
-		MaleRoleID:             maleRole,
-		FemaleRoleID:           femaleRole,
-		HighVoiceRoleID:        highVoice,
-		MidHighVoiceRoleID:     midHighVoice,
-		MidVoiceRoleID:         midVoice,
-		MidLowVoiceRoleID:      midLowVoice,
-		LowVoiceRoleID:         lowVoice,
-		EroOkRoleID:            eroOk,
-		EroNgRoleID:            eroNg,
-		NeochiOkRoleID:         neochiOk,
-		NeochiNgRoleID:         neochiNg,
-		NeochiDisconnectRoleID: neochiDisconnect,
-		DmOkRoleID:             dmOk,
-		DmNgRoleID:             dmNg,
-		FriendOkRoleID:         friendOk,
-		FriendNgRoleID:         friendNg,
-		BunnyclubEventRoleID:   bunnyclubEvent,
-		UserEventRoleID:        userEvent,
+	// In a shared VC, audio is skipped and confirmations are kept to the
+	// member so other people in the channel aren't flooded with them
+	sharedVC := sharedVCChannelID != ""
+	if sharedVC {
+		player = silentPlayer{}
+		ephemeralReplies = true
+	}
+
+	// With audio off nothing joins voice; the flow runs in a text channel
+	textOnly := payload.TextOnly && !sharedVC
+	if textOnly {
+		player = silentPlayer{}
+	}
+
+	// Default to streaming local DCA files
+	if player == nil {
+		player = audio.NewDCAPlayer(sessionCtx, session, logger)
+	}
+
+	s := &OnboardingSession{
+		guildID:                guildID,
+		userID:                 payload.UserID,
+		slaveID:                payload.SlaveID,
+		categoryID:             payload.CategoryID,
+		spreadIDs:              payload.SpreadCategoryIDs,
+		overflowIDs:            payload.OverflowCategoryIDs,
+		vcChannelID:            sharedVCChannelID,
+		sharedVC:               sharedVC,
+		textOnly:               textOnly,
+		vcNameTemplate:         payload.VCNameTemplate,
+		vcAccessRoles:          payload.VCAccessRoles,
+		vcAccessUsers:          payload.VCAccessUsers,
+		syncCategory:           payload.SyncCategoryPermissions,
+		vcSeq:                  payload.VCSeq,
+		ephemeralReplies:       ephemeralReplies,
+		greetingGuide:          payload.GreetingGuide,
+		inProgressRoleID:       payload.InProgressRole,
+		completedRoleID:        payload.CompletedRole,
+		EntranceRoleID:         payload.EntranceRole,
+		NyukaiRoleID:           payload.NyukaiRole,
+		Setsumeikai1RoleID:     payload.Setsumeikai1Role,
+		Setsumeikai2RoleID:     payload.Setsumeikai2Role,
+		Setsumeikai3RoleID:     payload.Setsumeikai3Role,
+		MemberRoleID:           payload.MemberRole,
+		VisitorRoleID:          payload.VisitorRole,
+		InitialRoleID:          payload.InitialRole,
+		Age20EarlyRoleID:       payload.Age20EarlyRole,
+		Age20LateRoleID:        payload.Age20LateRole,
+		Age30EarlyRoleID:       payload.Age30EarlyRole,
+		Age30LateRoleID:        payload.Age30LateRole,
+		Age40EarlyRoleID:       payload.Age40EarlyRole,
+		Age40LateRoleID:        payload.Age40LateRole,
+		MaleRoleID:             payload.MaleRole,
+		FemaleRoleID:           payload.FemaleRole,
+		HighVoiceRoleID:        payload.HighVoiceRole,
+		MidHighVoiceRoleID:     payload.MidHighVoiceRole,
+		MidVoiceRoleID:         payload.MidVoiceRole,
+		MidLowVoiceRoleID:      payload.MidLowVoiceRole,
+		LowVoiceRoleID:         payload.LowVoiceRole,
+		EroOkRoleID:            payload.EroOkRole,
+		EroNgRoleID:            payload.EroNgRole,
+		NeochiOkRoleID:         payload.NeochiOkRole,
+		NeochiNgRoleID:         payload.NeochiNgRole,
+		NeochiDisconnectRoleID: payload.NeochiDisconnectRole,
+		DmOkRoleID:             payload.DmOkRole,
+		DmNgRoleID:             payload.DmNgRole,
+		FriendOkRoleID:         payload.FriendOkRole,
+		FriendNgRoleID:         payload.FriendNgRole,
+		BunnyclubEventRoleID:   payload.BunnyclubEventRole,
+		UserEventRoleID:        payload.UserEventRole,
+		guideCompletionRoles:   payload.GuideCompletionRoles,
+		completionChanges:      payload.CompletionChanges,
+		resume:                 payload.Resume,
 		startedAt:              time.Now(),
 		lastActivity:           time.Now(),
+		inactivityWarning:      DefaultInactivityWarning,
 		session:                session,
 		db:                     db,
 		cache:                  cache,
 		queue:                  queue,
+		events:                 events,
 		logger:                 logger,
 		i18n:                   i18nClient,
-		stopStream:             make(chan struct{}),
+		player:                 player,
 		ctx:                    sessionCtx,
 		cancel:                 cancel,
-	}, nil
+		shutdown:               ctx,
+		deleteGrace:            DefaultDeleteGrace,
+		locales:                payload.Locales,
+	}
+
+	// Text and audio follow the language the member picks, once they have
+	if i18nClient != nil {
+		s.i18n = sessionI18n{I18n: i18nClient, session: s}
+	}
+	return s, nil
 }
 
 // Start begins the onboarding session.
@@ -214,6 +279,9 @@ func (s *OnboardingSession) Start() error {
 		"slave_id", s.slaveID,
 	)
 
+	// Let master notice if this worker dies mid-session
+	go s.sendHeartbeats()
+
 	// Add in-progress role if configured
 	if s.inProgressRoleID != "" {
 		if err := s.addRole(s.inProgressRoleID); err != nil {
@@ -221,20 +289,36 @@ func (s *OnboardingSession) Start() error {
 		}
 	}
 
-	// Create voice channel
-	vcChannel, err := s.createVoiceChannel()
-	if err != nil {
-		return fmt.Errorf("create voice channel: %w", err)
+	// Create voice channel, unless the guild onboards in a shared one
+	if s.sharedVC {
+		s.logger.Info("using shared voice channel", "channel_id", s.vcChannelID)
+	} else {
+		vcChannel, err := s.createVoiceChannel()
+		if err != nil {
+			s.reportFailure("create_voice_channel", err)
+			return fmt.Errorf("create voice channel: %w", err)
+		}
+		s.vcChannelID = vcChannel.ID
+
+		s.logger.Info("onboarding channel created",
+			"channel_id", s.vcChannelID,
+			"channel_name", vcChannel.Name,
+			"category_id", vcChannel.ParentID,
+			"text_only", s.textOnly,
+		)
 	}
-	s.vcChannelID = vcChannel.ID
 
-	s.logger.Info("voice channel created",
-		"channel_id", s.vcChannelID,
-		"channel_name", vcChannel.Name,
-	)
+	// An empty guide list would leave the member stuck at guide selection
+	if _, err := onboard.UsableGuides(); err != nil {
+		s.reportFailure("list_guides", err)
+		s.sendNoGuidesMessage()
+		s.cleanup()
+		return fmt.Errorf("list guides: %w", err)
+	}
 
 	// Join voice channel
 	if err := s.joinVoiceChannel(); err != nil {
+		s.reportFailure("join_voice_channel", err)
 		s.cleanup()
 		return fmt.Errorf("join voice channel: %w", err)
 	}
@@ -244,9 +328,15 @@ func (s *OnboardingSession) Start() error {
 		s.logger.Warn("failed to save session to cache", "error", err)
 	}
 
-	// Send welcome message in VC text channel
-	if err := s.sendWelcomeMessage(); err != nil {
-		s.logger.Warn("failed to send welcome message", "error", err)
+	// Multilingual guilds let the member pick a language first; the rest
+	// of the session waits for ChooseLocale
+	if s.NeedsLocale() {
+		if err := s.sendLanguageMessage(); err != nil {
+			s.logger.Warn("failed to send language selection, using the guild's language", "error", err)
+			s.begin()
+		}
+	} else {
+		s.begin()
 	}
 
 	// Start inactivity monitor
@@ -258,6 +348,7 @@ func (s *OnboardingSession) Start() error {
 		s.logger.Info("session context cancelled")
 	case <-time.After(sessionTimeout):
 		s.logger.Warn("session exceeded maximum duration")
+		s.setOutcome(onboard.OutcomeTimedOut)
 	}
 
 	// Cleanup
@@ -266,7 +357,35 @@ func (s *OnboardingSession) Start() error {
 	return nil
 }
 
-// createVoiceChannel creates a temporary voice channel for the user.
+// begin resumes the member's unfinished onboarding, or greets them and
+// offers the guides.
+func (s *OnboardingSession) begin() {
+	if s.canResume(s.resume) {
+		// Pick up after the last step the member completed last time
+		go func() {
+			if err := s.resumeFromCheckpoint(s.resume); err != nil {
+				s.Fail("resume", err)
+			}
+		}()
+		return
+	}
+
+	// Send welcome message in VC text channel
+	if err := s.sendWelcomeMessage(); err != nil {
+		s.logger.Warn("failed to send welcome message", "error", err)
+	}
+
+	// Greet the user so they know audio works while they pick a guide
+	if s.HasGreeting() {
+		s.PlayAudioFileAsync(s.greetingGuide, s.AudioFile(s.greetingGuide, onboard.AudioWelcome))
+	}
+}
+
+// createVoiceChannel creates a temporary voice channel for the user in the
+// onboarding category, or in the next of its spread categories when it has
+// any, falling back to the rest in categoryOrder when one is full.
+// ErrCategoriesFull is returned when every category is full. Text-only
+// sessions get a private text channel instead.
 func (s *OnboardingSession) createVoiceChannel() (*discordgo.Channel, error) {
 	// Get user info for channel name
 	user, err := s.session.User(s.userID)
@@ -274,77 +393,60 @@ func (s *OnboardingSession) createVoiceChannel() (*discordgo.Channel, error) {
 		return nil, fmt.Errorf("get user: %w", err)
 	}
 
-	channelName := fmt.Sprintf("onboarding-%s", user.Username)
+	channelName := discord.RenderChannelName(s.vcNameTemplate, discord.ChannelNameVars{
+		Username: user.Username,
+		UserID:   s.userID,
+		Seq:      s.vcSeq,
+	})
 
 	bitrate := 96000 // 96kbps (Discord's maximum)
 	userLimit := 2   // Max 2 users (user + bot)
+	var access int64 = discordgo.PermissionViewChannel |
+		discordgo.PermissionVoiceConnect |
+		discordgo.PermissionVoiceSpeak
+
+	// Staff let in by the guild may join alongside the member
+	if len(s.vcAccessRoles) > 0 || len(s.vcAccessUsers) > 0 {
+		userLimit = 0
+	}
 
-	channel, err := s.session.GuildChannelCreateComplex(s.guildID, discordgo.GuildChannelCreateData{
+	data := discordgo.GuildChannelCreateData{
 		Name:      channelName,
 		Type:      discordgo.ChannelTypeGuildVoice,
-		ParentID:  s.categoryID,
 		Bitrate:   bitrate,
 		UserLimit: userLimit,
-		PermissionOverwrites: []*discordgo.PermissionOverwrite{
-			// Only the user and the bot can see/join
-			{
-				ID:   s.userID,
-				Type: discordgo.PermissionOverwriteTypeMember,
-				Allow: discordgo.PermissionViewChannel |
-					discordgo.PermissionVoiceConnect |
-					discordgo.PermissionVoiceSpeak,
-			},
-			{
-				ID:   s.session.State.User.ID,
-				Type: discordgo.PermissionOverwriteTypeMember,
-				Allow: discordgo.PermissionViewChannel |
-					discordgo.PermissionVoiceConnect |
-					discordgo.PermissionVoiceSpeak,
-			},
-			// Hide from @everyone
-			{
-				ID:   s.guildID,
-				Type: discordgo.PermissionOverwriteTypeRole,
-				Deny: discordgo.PermissionViewChannel,
-			},
-		},
-	})
-	if err != nil {
-		return nil, fmt.Errorf("create channel: %w", err)
 	}
 
-	return channel, nil
-}
-
-// joinVoiceChannel joins the created voice channel.
-func (s *OnboardingSession) joinVoiceChannel() error {
-	// Use context with timeout for voice join
-	ctx, cancel := context.WithTimeout(s.ctx, 10*time.Second)
-	defer cancel()
-
-	vc, err := s.session.ChannelVoiceJoin(ctx, s.guildID, s.vcChannelID, false, true)
-	if err != nil {
-		return fmt.Errorf("join voice: %w", err)
+	// Text-only sessions have no audio; the user and bot only need to chat
+	if s.textOnly {
+		access = discordgo.PermissionViewChannel |
+			discordgo.PermissionSendMessages |
+			discordgo.PermissionReadMessageHistory
+		data.Type = discordgo.ChannelTypeGuildText
+		data.Bitrate = 0
+		data.UserLimit = 0
 	}
 
-	s.voiceConn = vc
-
-	// Wait for voice connection to be ready (with timeout)
-	timeout := time.After(10 * time.Second)
-	ticker := time.NewTicker(100 * time.Millisecond)
-	defer ticker.Stop()
-
-	for {
-		select {
-		case <-timeout:
-			return fmt.Errorf("timeout waiting for voice connection to be ready")
-		case <-ticker.C:
-			if vc.Status == discordgo.VoiceConnectionStatusReady {
-				s.logger.Info("joined voice channel successfully", "channel_id", s.vcChannelID)
-				return nil
-			}
+	for _, categoryID := range s.categoryOrder(context.Background()) {
+		data.ParentID = categoryID
+		data.PermissionOverwrites = s.sessionOverwrites(categoryID, access)
+		channel, err := s.session.GuildChannelCreateComplex(s.guildID, data)
+		if discord.IsCategoryFull(err) {
+			s.logger.Warn("onboarding category full, trying the next", "category_id", categoryID)
+			continue
 		}
+		if err != nil {
+			return nil, fmt.Errorf("create channel: %w", err)
+		}
+		return channel, nil
 	}
+
+	return nil, ErrCategoriesFull
+}
+
+// joinVoiceChannel joins the created voice channel.
+func (s *OnboardingSession) joinVoiceChannel() error {
+	return s.player.Connect(s.ctx, s.guildID, s.vcChannelID)
 }
 
 // sendWelcomeMessage sends a welcome message with guide selection.
@@ -355,6 +457,13 @@ func (s *OnboardingSession) sendWelcomeMessage() error {
 		"user": fmt.Sprintf("<@%s>", s.userID),
 	})
 
+	if s.sharedVC {
+		description += "\n\n" + s.i18n.T(ctx, s.guildID, "onboarding.shared_vc_notice")
+	}
+	if s.textOnly {
+		description += "\n\n" + s.i18n.T(ctx, s.guildID, "onboarding.text_only_notice")
+	}
+
 	embed := &discordgo.MessageEmbed{
 		Title:       title,
 		Description: description,
@@ -363,53 +472,80 @@ func (s *OnboardingSession) sendWelcomeMessage() error {
 
 	// Build guide selection components
 	components := s.BuildGuideSelectionComponents()
+	if s.HasGreeting() {
+		components = append(components, discordgo.ActionsRow{
+			Components: []discordgo.MessageComponent{
+				discordgo.Button{
+					Label:    s.i18n.T(ctx, s.guildID, "onboarding.skip_greeting"),
+					Style:    s.ButtonStyle(ButtonSkip),
+					CustomID: fmt.Sprintf("onboarding:skip_greeting:%s", s.userID),
+				},
+			},
+		})
+	}
 
-	_, err := s.session.ChannelMessageSendComplex(s.vcChannelID, &discordgo.MessageSend{
+	msg, err := s.sendMessage(&discordgo.MessageSend{
 		Embeds:     []*discordgo.MessageEmbed{embed},
 		Components: components,
 	})
 	if err != nil {
 		return fmt.Errorf("send message: %w", err)
 	}
+	s.PinGuideMessage(msg.ID)
 
 	return nil
 }
 
 // BuildGuideSelectionComponents builds the UI for guide selection (exported for handlers).
 func (s *OnboardingSession) BuildGuideSelectionComponents() []discordgo.MessageComponent {
-	// TODO: In future, scan audio/ directory for available guides
-	// For now, hardcode "kk" as the only available guide
-	guides := []string{"kk"}
+	guides := s.availableGuides()
 	ctx := context.Background()
 
 	components := []discordgo.MessageComponent{}
 
-	// Preview buttons (one per guide)
+	// Preview buttons (one per guide). Guides without a preview can still
+	// be chosen; their button is disabled.
 	previewButtons := []discordgo.MessageComponent{}
+	// The guide being previewed is marked so members comparing guides know
+	// which one they're hearing.
+	previewing := s.PreviewGuide()
 	for _, guide := range guides {
-		guideName := s.i18n.T(ctx, s.guildID, fmt.Sprintf("onboarding.guides.%s.name", guide))
-		previewButtons = append(previewButtons, discordgo.Button{
-			Label:    guideName + " 🎧",
-			Style:    discordgo.SecondaryButton,
+		guideName := s.guideName(ctx, guide)
+		button := discordgo.Button{
+			Label:    guideName,
+			Style:    s.ButtonStyle(ButtonPreview),
+			Emoji:    shared.ComponentEmoji(shared.EmojiPreview),
 			CustomID: fmt.Sprintf("onboarding:preview:%s:%s", guide, s.userID),
-		})
+		}
+		if guide == previewing {
+			button.Label = s.i18n.TWithArgs(ctx, s.guildID, "onboarding.preview_playing_label", map[string]string{
+				"guide": guideName,
+			})
+			button.Style = s.ButtonStyle(ButtonToggled)
+		}
+		if !s.HasPreview(guide) {
+			button.Label = s.i18n.TWithArgs(ctx, s.guildID, "onboarding.preview_unavailable_label", map[string]string{
+				"guide": guideName,
+			})
+			button.Emoji = nil
+			button.Disabled = true
+		}
+		previewButtons = append(previewButtons, button)
 	}
 
 	components = append(components, discordgo.ActionsRow{
 		Components: previewButtons,
 	})
 
-	// Dropdown menu for final selection
+	// Dropdown menu for final selection. Descriptions tell guides apart;
+	// a guide without one just shows its name.
 	options := []discordgo.SelectMenuOption{}
 	for _, guide := range guides {
-		guideName := s.i18n.T(ctx, s.guildID, fmt.Sprintf("onboarding.guides.%s.name", guide))
-		
 		options = append(options, discordgo.SelectMenuOption{
-			Label: guideName,
-			Value: guide,
-			Emoji: &discordgo.ComponentEmoji{
-				Name: "👤",
-			},
+			Label:       s.guideName(ctx, guide),
+			Value:       guide,
+			Description: s.guideDescription(ctx, guide),
+			Emoji:       shared.ComponentEmoji(shared.EmojiGuide),
 		})
 	}
 
@@ -427,110 +563,60 @@ func (s *OnboardingSession) BuildGuideSelectionComponents() []discordgo.MessageC
 	return components
 }
 
-// runOnboardingFlow executes the interactive onboarding flow.
-func (s *OnboardingSession) runOnboardingFlow() {
-	defer s.Complete()
-
-	// Step 1: Play welcome audio and show button
-	if err := s.step1Welcome(); err != nil {
-		s.logger.Error("step 1 failed", "error", err)
-		return
-	}
-
-	// Wait for user interaction or timeout
-	select {
-	case <-s.ctx.Done():
-		s.logger.Info("session timed out")
-		return
-	case <-time.After(2 * time.Minute):
-		// Continue to next step after waiting
-	}
+// VCChannelID returns the ID of the session's voice channel.
+func (s *OnboardingSession) VCChannelID() string {
+	return s.vcChannelID
+}
 
-	// Step 2: Voice selection
-	if err := s.step2VoiceSelection(); err != nil {
-		s.logger.Error("step 2 failed", "error", err)
-		return
+// AudioFile resolves the filename for an audio key from the guide's manifest.
+func (s *OnboardingSession) AudioFile(guide, key string) string {
+	manifest, err := onboard.LoadManifest(guide)
+	if err != nil {
+		s.logger.Warn("failed to load guide manifest, using default audio names", "guide", guide, "error", err)
 	}
+	return manifest.AudioFile(key)
+}
 
-	// Wait for completion
-	select {
-	case <-s.ctx.Done():
-		return
-	case <-time.After(1 * time.Minute):
-		// Auto-complete after demonstration
+// HasPreview reports whether the guide's preview can be played, either
+// from its recording or, with a TTS player, by speaking its description.
+func (s *OnboardingSession) HasPreview(guide string) bool {
+	file := s.localizedAudio(guide, s.AudioFile(guide, onboard.AudioPreview))
+	if player, ok := s.player.(interface{ CanPlay(guide, filename string) bool }); ok {
+		return player.CanPlay(guide, file)
 	}
+	return audio.Exists(guide, file)
 }
 
-// step1Welcome plays welcome audio and shows initial message.
-func (s *OnboardingSession) step1Welcome() error {
-	s.UpdateActivity()
-
-	// TODO: This is old flow - will be replaced by guide selection flow
-	// Play welcome audio (placeholder)
-	// if err := s.playAudioFile("guide_name", "1-intro.dca"); err != nil {
-	// 	s.logger.Warn("failed to play welcome audio", "error", err)
-	// }
-
-	// Send message with button
-	embed := &discordgo.MessageEmbed{
-		Title:       "Step 1: Welcome",
-		Description: "Welcome to the server! Let's get you set up.",
-		Color:       0x3498db,
+// HasGreeting reports whether a greeting is configured and can be played.
+// Guides without a welcome file, shared VCs and text-only sessions start
+// in silence.
+func (s *OnboardingSession) HasGreeting() bool {
+	if s.greetingGuide == "" || s.sharedVC || s.textOnly {
+		return false
 	}
-
-	components := []discordgo.MessageComponent{
-		discordgo.ActionsRow{
-			Components: []discordgo.MessageComponent{
-				discordgo.Button{
-					Label:    "Continue",
-					Style:    discordgo.PrimaryButton,
-					CustomID: fmt.Sprintf("onboard:step1:%s", s.userID),
-				},
-			},
-		},
+	file := s.localizedAudio(s.greetingGuide, s.AudioFile(s.greetingGuide, onboard.AudioWelcome))
+	if player, ok := s.player.(interface{ CanPlay(guide, filename string) bool }); ok {
+		return player.CanPlay(s.greetingGuide, file)
 	}
-
-	_, err := s.session.ChannelMessageSendComplex(s.vcChannelID, &discordgo.MessageSend{
-		Embeds:     []*discordgo.MessageEmbed{embed},
-		Components: components,
-	})
-
-	return err
+	return audio.Exists(s.greetingGuide, file)
 }
 
-// step2VoiceSelection allows user to select voice preference.
-func (s *OnboardingSession) step2VoiceSelection() error {
-	s.UpdateActivity()
-
-	embed := &discordgo.MessageEmbed{
-		Title:       "Step 2: Voice Selection",
-		Description: "Which voice would you like to hear?",
-		Color:       0x9b59b6,
+// availableGuides returns the guides a member can choose from. Start ends
+// the session before guide selection when there are none.
+func (s *OnboardingSession) availableGuides() []string {
+	guides, err := onboard.UsableGuides()
+	if err != nil {
+		s.logger.Error("no guides to offer", "error", err)
 	}
+	return guides
+}
 
-	components := []discordgo.MessageComponent{
-		discordgo.ActionsRow{
-			Components: []discordgo.MessageComponent{
-				discordgo.Button{
-					Label:    "Voice A",
-					Style:    discordgo.PrimaryButton,
-					CustomID: fmt.Sprintf("onboard:voice:a:%s", s.userID),
-				},
-				discordgo.Button{
-					Label:    "Voice B",
-					Style:    discordgo.SecondaryButton,
-					CustomID: fmt.Sprintf("onboard:voice:b:%s", s.userID),
-				},
-			},
-		},
+// ReplyFlags returns the message flags for step confirmation replies.
+func (s *OnboardingSession) ReplyFlags() discordgo.MessageFlags {
+	if s.ephemeralReplies {
+		return discordgo.MessageFlagsEphemeral
 	}
-
-	_, err := s.session.ChannelMessageSendComplex(s.vcChannelID, &discordgo.MessageSend{
-		Embeds:     []*discordgo.MessageEmbed{embed},
-		Components: components,
-	})
-
-	return err
+	return 0
 }
 
 // GetUserID returns the user ID for this session.
@@ -538,78 +624,72 @@ func (s *OnboardingSession) GetUserID() string {
 	return s.userID
 }
 
-// PlayAudioFile plays an audio file in the voice channel using DCA StreamingSession.
+// PlayAudioFile plays an audio file in the voice channel.
 // This is exported so interaction handlers can trigger audio playback.
 func (s *OnboardingSession) PlayAudioFile(guide, filename string) error {
 	return s.playAudioFile(guide, filename)
 }
 
-// playAudioFile plays an audio file in the voice channel using DCA StreamingSession.
-// This runs in a goroutine and can be stopped via StopCurrentAudio()
+// PlayAudioFileAsync plays an audio file without blocking the caller, for
+// audio that should not hold up the step's UI. A StopCurrentAudio call made
+// before playback gets going cancels it.
+func (s *OnboardingSession) PlayAudioFileAsync(guide, filename string) {
+	s.audioMu.Lock()
+	gen := s.audioGen
+	s.audioMu.Unlock()
+
+	go func() {
+		if err := s.playAudioFileGen(guide, filename, gen); err != nil {
+			s.logger.Error("failed to play audio", "error", err, "file", filename)
+		}
+	}()
+}
+
+// playAudioFile plays an audio file in the voice channel.
+// Playback is asynchronous and can be stopped via StopCurrentAudio().
 func (s *OnboardingSession) playAudioFile(guide, filename string) error {
-	s.UpdateActivity()
+	s.audioMu.Lock()
+	gen := s.audioGen
+	s.audioMu.Unlock()
 
-	audioPath := fmt.Sprintf("audio/%s/%s", guide, filename)
-	s.logger.Info("playing audio", "path", audioPath)
+	return s.playAudioFileGen(guide, filename, gen)
+}
 
-	// Check if file exists
-	if _, err := os.Stat(audioPath); os.IsNotExist(err) {
-		return fmt.Errorf("audio file not found: %s", audioPath)
+// playAudioFileGen starts playback unless audio was stopped since gen was
+// read. Starting a stream can take a while (fades run ffmpeg), so stops
+// don't wait for it: a stop that lands while the stream starts is applied
+// once it has.
+func (s *OnboardingSession) playAudioFileGen(guide, filename string, gen uint64) error {
+	s.UpdateActivity()
+	if s.sharedVC {
+		return nil
 	}
+	filename = s.localizedAudio(guide, filename)
 
-	// Check if voice connection is ready
-	if s.voiceConn == nil || s.voiceConn.Status != discordgo.VoiceConnectionStatusReady {
-		return fmt.Errorf("voice connection not ready")
-	}
+	s.playMu.Lock()
+	defer s.playMu.Unlock()
 
-	// Stop any currently playing audio
-	if s.currentStream != nil {
-		s.currentStream.SetPaused(true)
-		s.currentStream = nil
+	if s.audioStopped(gen) {
+		s.logger.Debug("skipping audio stopped before it started", "file", filename)
+		return nil
 	}
+	s.Record(onboard.TimelineAudio, guide+"/"+filename)
 
-	// Open DCA file
-	file, err := os.Open(audioPath)
-	if err != nil {
-		return fmt.Errorf("open audio file: %w", err)
+	if err := s.player.Play(guide, filename); err != nil {
+		return err
 	}
+	if s.audioStopped(gen) {
+		s.player.Stop()
+	}
+	return nil
+}
 
-	// Create decoder (implements OpusReader interface)
-	decoder := dca.NewDecoder(file)
-	
-	// Create streaming session - this handles sending frames automatically
-	done := make(chan error)
-	stream := dca.NewStream(decoder, s.voiceConn, done)
-	
-	// Store stream reference and audio file name
-	s.currentStream = stream
-	s.currentAudioFile = filename
-	
-	// Run in goroutine to allow non-blocking playback
-	go func() {
-		defer file.Close()
-		
-		// Wait for playback to complete or stop signal
-		select {
-		case err := <-done:
-			if err != nil && err != io.EOF {
-				s.logger.Error("playback error", "error", err)
-			} else {
-				s.logger.Info("audio playback completed", "path", audioPath)
-			}
-			s.currentStream = nil
-		case <-s.stopStream:
-			stream.SetPaused(true)
-			s.logger.Info("audio playback stopped", "path", audioPath)
-			s.currentStream = nil
-		case <-s.ctx.Done():
-			stream.SetPaused(true)
-			s.logger.Info("audio playback cancelled", "path", audioPath)
-			s.currentStream = nil
-		}
-	}()
+// audioStopped reports whether audio was stopped since gen was read.
+func (s *OnboardingSession) audioStopped(gen uint64) bool {
+	s.audioMu.Lock()
+	defer s.audioMu.Unlock()
 
-	return nil
+	return s.audioGen != gen
 }
 
 // stopAudio stops any currently playing audio.
@@ -623,10 +703,29 @@ func (s *OnboardingSession) stopAudio() {
 // UpdateActivity updates the last activity timestamp.
 // This should be called whenever the user interacts with the onboarding session.
 func (s *OnboardingSession) UpdateActivity() {
+	s.activityMu.Lock()
+	defer s.activityMu.Unlock()
+
 	s.lastActivity = time.Now()
 }
 
-// monitorInactivity monitors for user inactivity.
+// lastActive returns when the user last interacted with the session.
+func (s *OnboardingSession) lastActive() time.Time {
+	s.activityMu.Lock()
+	defer s.activityMu.Unlock()
+
+	return s.lastActivity
+}
+
+// Stale reports whether the session has ended, or has gone a whole
+// session timeout without activity. The worker drops stale sessions that
+// were not removed when they finished.
+func (s *OnboardingSession) Stale(now time.Time) bool {
+	return s.ctx.Err() != nil || now.Sub(s.lastActive()) > sessionTimeout
+}
+
+// monitorInactivity warns idle users and closes the session once the
+// inactivity timeout passes.
 func (s *OnboardingSession) monitorInactivity() {
 	ticker := time.NewTicker(30 * time.Second)
 	defer ticker.Stop()
@@ -635,12 +734,20 @@ func (s *OnboardingSession) monitorInactivity() {
 		select {
 		case <-s.ctx.Done():
 			return
-		case <-ticker.C:
-			if time.Since(s.lastActivity) > inactivityTimeout {
+		case now := <-ticker.C:
+			warn, expire := s.checkInactivity(now)
+			if expire {
 				s.logger.Info("session inactive, closing")
+				s.setOutcome(onboard.OutcomeInactive)
 				s.cancel()
 				return
 			}
+			if warn {
+				s.warnInactive(now)
+			}
+			if timeout, ok := s.checkStepTimeout(now); ok {
+				s.handleStepTimeout(timeout)
+			}
 		}
 	}
 }
@@ -650,27 +757,74 @@ func (s *OnboardingSession) addRole(roleID string) error {
 	if roleID == "" {
 		return nil
 	}
-
-	err := s.session.GuildMemberRoleAdd(s.guildID, s.userID, roleID)
+	applied, err := ChangeRole(s.ctx, s.session, s.memberRoles, s.logger, s.guildID, s.userID, roleID, true)
 	if err != nil {
 		return fmt.Errorf("add role: %w", err)
 	}
+	if !applied {
+		s.logger.Debug("role already present", "role_id", roleID, "user_id", s.userID)
+		return nil
+	}
 
+	s.Record(onboard.TimelineRole, "+"+roleID)
 	s.logger.Info("role added", "role_id", roleID, "user_id", s.userID)
 	return nil
 }
 
+// sendMessage posts a step message in the voice channel, retrying on rate
+// limits so the user is never left without the buttons to continue.
+// Content over Discord's limit is split across messages.
+func (s *OnboardingSession) sendMessage(data *discordgo.MessageSend) (*discordgo.Message, error) {
+	if utf8.RuneCountInString(data.Content) > maxMessageLength {
+		var err error
+		if data, err = s.sendSplit(data); err != nil {
+			return nil, err
+		}
+	}
+
+	// An attempt drains the files' readers, so each one is sent from a
+	// copy read up front
+	files := make([][]byte, len(data.Files))
+	for n, file := range data.Files {
+		body, err := io.ReadAll(file.Reader)
+		if err != nil {
+			return nil, fmt.Errorf("read %s: %w", file.Name, err)
+		}
+		files[n] = body
+	}
+
+	var msg *discordgo.Message
+	err := discord.Retry(s.ctx, s.logger, "send message", discord.SendRetryPolicy, func() error {
+		attempt := *data
+		if len(files) > 0 {
+			attempt.Files = make([]*discordgo.File, len(files))
+			for n, file := range data.Files {
+				attempt.Files[n] = &discordgo.File{Name: file.Name, ContentType: file.ContentType, Reader: bytes.NewReader(files[n])}
+			}
+		}
+
+		var err error
+		msg, err = s.session.ChannelMessageSendComplex(s.vcChannelID, &attempt)
+		return err
+	})
+	return msg, err
+}
+
 // removeRole removes a role from the user.
 func (s *OnboardingSession) removeRole(roleID string) error {
 	if roleID == "" {
 		return nil
 	}
-
-	err := s.session.GuildMemberRoleRemove(s.guildID, s.userID, roleID)
+	applied, err := ChangeRole(s.ctx, s.session, s.memberRoles, s.logger, s.guildID, s.userID, roleID, false)
 	if err != nil {
 		return fmt.Errorf("remove role: %w", err)
 	}
+	if !applied {
+		s.logger.Debug("role already absent", "role_id", roleID, "user_id", s.userID)
+		return nil
+	}
 
+	s.Record(onboard.TimelineRole, "-"+roleID)
 	s.logger.Info("role removed", "role_id", roleID, "user_id", s.userID)
 	return nil
 }
@@ -692,7 +846,17 @@ func (s *OnboardingSession) Complete() {
 		}
 	}
 
-	// Send completion task to master
+	s.setOutcome(onboard.OutcomeCompleted)
+
+	// Keep what the member chose for staff once the roles are all that's left
+	if err := s.saveSelections(context.Background()); err != nil {
+		s.logger.Warn("failed to save onboarding selections", "error", err)
+	}
+	if err := s.deleteCheckpoint(context.Background()); err != nil {
+		s.logger.Warn("failed to delete onboarding checkpoint", "error", err)
+	}
+
+	// Tell master, which posts the self-introduction and notifies staff
 	completionTask := queue.Task{
 		ID:      fmt.Sprintf("complete-%s-%s-%d", s.guildID, s.userID, time.Now().Unix()),
 		Type:    "onboarding_complete",
@@ -700,20 +864,70 @@ func (s *OnboardingSession) Complete() {
 		Payload: map[string]interface{}{
 			"user_id":  s.userID,
 			"slave_id": s.slaveID,
+			"guide":    s.selectedGuide,
 		},
 		CreatedAt: time.Now(),
 	}
 
-	if err := s.queue.Enqueue(context.Background(), completionTask); err != nil {
-		s.logger.Error("failed to enqueue completion task", "error", err)
+	if s.events != nil {
+		if err := s.events.Enqueue(context.Background(), completionTask); err != nil {
+			s.logger.Error("failed to enqueue completion task", "error", err)
+		}
 	}
 
 	// Cancel context to trigger Start() to unblock and cleanup
 	s.cancel()
 }
 
+// Fail reports an unrecoverable error to master and ends the session.
+func (s *OnboardingSession) Fail(step string, cause error) {
+	s.logger.Error("onboarding session failed",
+		"guild_id", s.guildID,
+		"user_id", s.userID,
+		"step", step,
+		"error", cause,
+	)
+
+	s.reportFailure(step, cause)
+	s.cancel()
+}
+
+// reportFailure enqueues an onboarding_failed event for master.
+func (s *OnboardingSession) reportFailure(step string, cause error) {
+	s.setOutcome(onboard.OutcomeFailed)
+	s.Record(onboard.TimelineError, fmt.Sprintf("%s: %v", step, cause))
+
+	if s.events == nil {
+		return
+	}
+
+	failedTask := queue.Task{
+		ID:      fmt.Sprintf("failed-%s-%s-%d", s.guildID, s.userID, time.Now().Unix()),
+		Type:    "onboarding_failed",
+		GuildID: s.guildID,
+		Payload: map[string]interface{}{
+			"user_id":  s.userID,
+			"slave_id": s.slaveID,
+			"step":     step,
+			"error":    cause.Error(),
+		},
+		CreatedAt: time.Now(),
+	}
+
+	if err := s.events.Enqueue(context.Background(), failedTask); err != nil {
+		s.logger.Error("failed to enqueue failure task", "error", err)
+	}
+}
+
 // saveSessionToCache stores session data in Redis for interaction handlers.
+// While the cache is degraded this is a no-op; handlers rely on the worker's
+// in-memory session map instead.
 func (s *OnboardingSession) saveSessionToCache() error {
+	if s.cache.Degraded() {
+		s.logger.Debug("cache degraded, skipping session save", "user_id", s.userID)
+		return nil
+	}
+
 	sessionKey := fmt.Sprintf("welcomebot:session:%s:%s", s.guildID, s.userID)
 	
 	sessionData := map[string]interface{}{
@@ -722,9 +936,12 @@ func (s *OnboardingSession) saveSessionToCache() error {
 		"slave_id":       s.slaveID,
 		"vc_channel_id":  s.vcChannelID,
 		"selected_guide": s.selectedGuide,
-		"current_step":   s.currentStep,
+		"current_step":   s.CurrentStep(),
 		"started_at":     s.startedAt.Unix(),
 	}
+	if events := s.SelectedEvents(); len(events) > 0 {
+		sessionData["selected_events"] = events
+	}
 
 	// Store with expiration (session timeout)
 	return s.cache.SetJSON(context.Background(), sessionKey, sessionData, sessionTimeout)
@@ -734,33 +951,55 @@ func (s *OnboardingSession) saveSessionToCache() error {
 func (s *OnboardingSession) cleanup() {
 	s.logger.Info("cleaning up session", "user_id", s.userID)
 
+	// Keep a trace of the session for support investigations
+	if err := s.saveTimeline(context.Background()); err != nil {
+		s.logger.Warn("failed to save session timeline", "error", err)
+	} else {
+		s.logger.Debug("session timeline saved")
+	}
+
 	// Remove session from cache
 	sessionKey := fmt.Sprintf("welcomebot:session:%s:%s", s.guildID, s.userID)
 	if err := s.cache.Delete(context.Background(), sessionKey); err != nil {
 		s.logger.Warn("failed to delete session from cache", "error", err)
+	} else {
+		s.logger.Debug("session removed from cache")
 	}
+	s.clearHeartbeat()
 
-	// Disconnect from voice
-	if s.voiceConn != nil {
-		// Use background context with timeout for cleanup to avoid indefinite hang
-		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-		defer cancel()
-		if err := s.voiceConn.Disconnect(ctx); err != nil {
-			s.logger.Warn("failed to disconnect voice", "error", err)
-		}
-	}
+	// Disconnect from voice. Errors here must not keep the slave busy, so
+	// every later step runs regardless.
+	s.disconnectVoice()
 
-	// Delete voice channel
-	if s.vcChannelID != "" {
-		if _, err := s.session.ChannelDelete(s.vcChannelID); err != nil {
-			s.logger.Warn("failed to delete voice channel", "error", err)
+	// Delete voice channel; the shared one outlives every session, so only
+	// this session's pin is removed there. After a completion a private
+	// channel stays up briefly so the final message can be read.
+	if s.sharedVC {
+		s.unpinGuideMessage()
+	}
+	if s.vcChannelID != "" && !s.sharedVC {
+		if (s.outcome == onboard.OutcomeCompleted || s.finalNotice) && s.deleteGrace > 0 {
+			s.deleteVoiceChannelLater()
+		} else {
+			s.deleteVoiceChannel()
 		}
 	}
 
 	// Mark slave as available
 	key := fmt.Sprintf("welcomebot:slaves:status:%s", s.slaveID)
 	if err := s.cache.Set(context.Background(), key, "available", 30*time.Minute); err != nil {
-		s.logger.Warn("failed to mark slave as available", "error", err)
+		s.logger.Error("failed to mark slave as available", "slave_id", s.slaveID, "error", err)
+	} else {
+		s.logger.Debug("slave marked available", "slave_id", s.slaveID)
+	}
+
+	// Free the guild's concurrent session slot
+	slotKey := fmt.Sprintf("welcomebot:guild_sessions:%s", s.guildID)
+	if active, err := s.cache.Decr(context.Background(), slotKey); err != nil {
+		s.logger.Warn("failed to release guild session slot", "error", err)
+	} else if active < 0 {
+		// Counter expired while the session ran; don't let it go negative
+		_ = s.cache.Delete(context.Background(), slotKey)
 	}
 
 	// Cancel context
@@ -769,13 +1008,71 @@ func (s *OnboardingSession) cleanup() {
 	s.logger.Info("session cleanup complete")
 }
 
-// StartStep1 begins step 1 of the onboarding tutorial.
-// This is called after the user confirms their guide selection.
-func (s *OnboardingSession) StartStep1(guide string) error {
-	s.selectedGuide = guide
-	s.currentStep = 1
-	s.UpdateActivity()
+// disconnectVoice leaves the voice channel. A panicking player is logged
+// rather than allowed to abort the rest of cleanup.
+func (s *OnboardingSession) disconnectVoice() {
+	defer func() {
+		if r := recover(); r != nil {
+			s.logger.Error("voice disconnect panicked", "panic", r)
+		}
+	}()
 
+	// Use background context with timeout for cleanup to avoid indefinite hang
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := s.player.Disconnect(ctx); err != nil {
+		s.logger.Warn("failed to disconnect voice", "error", err)
+		return
+	}
+	s.logger.Debug("voice disconnected")
+}
+
+// deleteVoiceChannel deletes the session's voice channel, and logs an
+// error naming it if it was leaked.
+func (s *OnboardingSession) deleteVoiceChannel() {
+	if err := s.removeVoiceChannel(); err != nil {
+		s.logger.Error("voice channel leaked, delete it manually", "channel_id", s.vcChannelID, "error", err)
+		return
+	}
+	s.logger.Debug("voice channel deleted", "channel_id", s.vcChannelID)
+}
+
+// removeVoiceChannel deletes the session's voice channel, retrying once.
+// When both attempts fail it checks whether the channel is gone anyway
+// (the first delete may have landed before its response was lost), and
+// only returns an error if the channel is still there.
+func (s *OnboardingSession) removeVoiceChannel() error {
+	channelID := s.vcChannelID
+
+	err := s.deleteChannel(channelID)
+	if err == nil {
+		return nil
+	}
+	s.logger.Warn("failed to delete voice channel, retrying", "channel_id", channelID, "error", err)
+
+	time.Sleep(cleanupRetryDelay)
+	if err = s.deleteChannel(channelID); err == nil {
+		return nil
+	}
+
+	if _, getErr := s.session.Channel(channelID); discord.IsNotFound(getErr) {
+		s.logger.Info("voice channel already gone", "channel_id", channelID)
+		return nil
+	}
+	return err
+}
+
+// deleteChannel deletes a channel, treating one that no longer exists as
+// deleted.
+func (s *OnboardingSession) deleteChannel(channelID string) error {
+	if _, err := s.session.ChannelDelete(channelID); err != nil && !discord.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+// showStep1 shows step 1 of the onboarding tutorial.
+func (s *OnboardingSession) showStep1(def StepDef) error {
 	// Remove "Entrance" role if configured - MOVED TO END
 	// if s.EntranceRoleID != "" {
 	// 	if err := s.session.GuildMemberRoleRemove(s.guildID, s.userID, s.EntranceRoleID); err != nil {
@@ -794,22 +1091,17 @@ func (s *OnboardingSession) StartStep1(guide string) error {
 
 	components := []discordgo.MessageComponent{
 		discordgo.ActionsRow{
-			Components: []discordgo.MessageComponent{
+			Components: append([]discordgo.MessageComponent{
 				discordgo.Button{
 					Label:    s.i18n.T(s.ctx, s.guildID, "onboarding.button_next"),
-					Style:    discordgo.PrimaryButton,
+					Style:    s.ButtonStyle(ButtonNext),
 					CustomID: fmt.Sprintf("onboarding:step1_next:%s", s.userID),
 				},
-				discordgo.Button{
-					Label:    s.i18n.T(s.ctx, s.guildID, "onboarding.button_replay"),
-					Style:    discordgo.SecondaryButton,
-					CustomID: fmt.Sprintf("onboarding:step1_replay:%s", s.userID),
-				},
-			},
+			}, s.replayButtons(1)...),
 		},
 	}
 
-	_, err := s.session.ChannelMessageSendComplex(s.vcChannelID, &discordgo.MessageSend{
+	_, err := s.sendMessage(&discordgo.MessageSend{
 		Embeds:     []*discordgo.MessageEmbed{embed},
 		Components: components,
 	})
@@ -822,14 +1114,13 @@ func (s *OnboardingSession) StartStep1(guide string) error {
 		s.logger.Warn("failed to save session to cache", "error", err)
 	}
 
-	// Send guide image (if available)
-	if err := s.sendGuideImage("step1.png"); err != nil {
-		s.logger.Warn("failed to send step 1 guide image", "error", err)
-		// Don't fail the step if image sending fails
+	// Send guide images (if any)
+	if err := s.sendStepImages(s.selectedGuide, def); err != nil {
+		return fmt.Errorf("send step 1 images: %w", err)
 	}
 
 	// Play step 1 intro audio
-	if err := s.playAudioFile(guide, "1-intro.dca"); err != nil {
+	if err := s.playAudioFile(s.selectedGuide, s.AudioFile(s.selectedGuide, def.Audio)); err != nil {
 		s.logger.Error("failed to play step 1 audio", "error", err)
 		return fmt.Errorf("play step 1 audio: %w", err)
 	}
@@ -839,48 +1130,37 @@ func (s *OnboardingSession) StartStep1(guide string) error {
 
 // StopCurrentAudio stops the currently playing audio.
 func (s *OnboardingSession) StopCurrentAudio() {
-	if s.currentStream != nil {
-		select {
-		case s.stopStream <- struct{}{}:
-			s.logger.Info("sent stop signal to audio stream")
-		default:
-			s.logger.Warn("stop channel full, audio may already be stopping")
-		}
-	}
+	s.audioMu.Lock()
+	defer s.audioMu.Unlock()
+
+	s.audioGen++
+	s.player.Stop()
 }
 
 // ReplayCurrentAudio replays the current step's audio from the beginning.
 func (s *OnboardingSession) ReplayCurrentAudio() error {
-	if s.selectedGuide == "" || s.currentAudioFile == "" {
-		return fmt.Errorf("no audio file to replay")
-	}
+	s.UpdateActivity()
+	s.Record(onboard.TimelineAudio, "replay")
 
-	s.logger.Info("replaying audio", "guide", s.selectedGuide, "file", s.currentAudioFile)
-	
-	// Stop current playback
-	s.StopCurrentAudio()
-	
-	// Small delay to ensure previous playback stops
-	time.Sleep(500 * time.Millisecond)
-	
-	// Replay the same audio file
-	return s.playAudioFile(s.selectedGuide, s.currentAudioFile)
-}
+	s.audioMu.Lock()
+	gen := s.audioGen
+	s.audioMu.Unlock()
 
-// StartStep2 begins step 2 of the onboarding tutorial.
-func (s *OnboardingSession) StartStep2() error {
-	s.currentStep = 2
-	s.UpdateActivity()
+	s.playMu.Lock()
+	defer s.playMu.Unlock()
 
-	// Add "説明会②" role if configured
-	if s.Setsumeikai2RoleID != "" {
-		if err := s.session.GuildMemberRoleAdd(s.guildID, s.userID, s.Setsumeikai2RoleID); err != nil {
-			s.logger.Warn("failed to add setsumeikai2 role", "error", err, "role_id", s.Setsumeikai2RoleID)
-		} else {
-			s.logger.Info("added setsumeikai2 role", "user_id", s.userID, "role_id", s.Setsumeikai2RoleID)
-		}
+	if err := s.player.Replay(); err != nil {
+		return err
+	}
+	if s.audioStopped(gen) {
+		s.player.Stop()
 	}
+	return nil
+}
 
+// showStep2 shows step 2 of the onboarding tutorial. The flow gives the
+// "説明会②" role as it starts.
+func (s *OnboardingSession) showStep2(def StepDef) error {
 	// Remove "入会手続き" role if configured - MOVED TO END
 	// if s.NyukaiRoleID != "" {
 	// 	if err := s.session.GuildMemberRoleRemove(s.guildID, s.userID, s.NyukaiRoleID); err != nil {
@@ -892,53 +1172,31 @@ func (s *OnboardingSession) StartStep2() error {
 
 	// Message 1: First part of text
 	part1 := s.i18n.T(s.ctx, s.guildID, "onboarding.step2_description_part1")
-	_, err := s.session.ChannelMessageSend(s.vcChannelID, part1)
+	err := s.sendText(part1)
 	if err != nil {
 		return fmt.Errorf("send step 2 part 1: %w", err)
 	}
 
-	// Message 2: Image
-	imagePath := "assets/images/onboarding/step2.png"
-	file, err := os.Open(imagePath)
-	if err != nil {
-		s.logger.Warn("failed to open step 2 image", "error", err, "path", imagePath)
-	} else {
-		defer file.Close()
-		_, err = s.session.ChannelMessageSendComplex(s.vcChannelID, &discordgo.MessageSend{
-			Files: []*discordgo.File{
-				{
-					Name:   "step2.png",
-					Reader: file,
-				},
-			},
-		})
-		if err != nil {
-			s.logger.Warn("failed to send step 2 image", "error", err)
-		} else {
-			s.logger.Info("sent step 2 guide image")
-		}
+	// Message 2: Guide images
+	if err := s.sendStepImages(s.selectedGuide, def); err != nil {
+		return fmt.Errorf("send step 2 images: %w", err)
 	}
 
 	// Message 3: Second part of text with buttons
 	part2 := s.i18n.T(s.ctx, s.guildID, "onboarding.step2_description_part2")
 	components := []discordgo.MessageComponent{
 		discordgo.ActionsRow{
-			Components: []discordgo.MessageComponent{
+			Components: append([]discordgo.MessageComponent{
 				discordgo.Button{
 					Label:    s.i18n.T(s.ctx, s.guildID, "onboarding.button_next"),
-					Style:    discordgo.PrimaryButton,
+					Style:    s.ButtonStyle(ButtonNext),
 					CustomID: fmt.Sprintf("onboarding:step2_next:%s", s.userID),
 				},
-				discordgo.Button{
-					Label:    s.i18n.T(s.ctx, s.guildID, "onboarding.button_replay"),
-					Style:    discordgo.SecondaryButton,
-					CustomID: fmt.Sprintf("onboarding:step2_replay:%s", s.userID),
-				},
-			},
+			}, s.replayButtons(2)...),
 		},
 	}
 
-	_, err = s.session.ChannelMessageSendComplex(s.vcChannelID, &discordgo.MessageSend{
+	_, err = s.sendMessage(&discordgo.MessageSend{
 		Content:    part2,
 		Components: components,
 	})
@@ -952,7 +1210,7 @@ func (s *OnboardingSession) StartStep2() error {
 	}
 
 	// Play step 2 profile audio
-	if err := s.playAudioFile(s.selectedGuide, "2-profile.dca"); err != nil {
+	if err := s.playAudioFile(s.selectedGuide, s.AudioFile(s.selectedGuide, def.Audio)); err != nil {
 		s.logger.Error("failed to play step 2 audio", "error", err)
 		return fmt.Errorf("play step 2 audio: %w", err)
 	}
@@ -960,25 +1218,17 @@ func (s *OnboardingSession) StartStep2() error {
 	return nil
 }
 
-// StartStep3 begins step 3 of the onboarding tutorial (role selection).
-func (s *OnboardingSession) StartStep3() error {
-	s.currentStep = 3
-	s.currentSubStep = 0 // Reset sub-step
-	s.UpdateActivity()
-
+// showStep3 shows step 3 of the onboarding tutorial (role selection).
+func (s *OnboardingSession) showStep3(def StepDef) error {
 	// Show initial message (plain markdown)
 	content := s.i18n.T(s.ctx, s.guildID, "onboarding.step3_description")
-	_, err := s.session.ChannelMessageSend(s.vcChannelID, content)
+	err := s.sendText(content)
 	if err != nil {
 		return fmt.Errorf("send step 3 initial message: %w", err)
 	}
 
 	// Play step 3 role audio (non-blocking)
-	go func() {
-		if err := s.playAudioFile(s.selectedGuide, "3-role.dca"); err != nil {
-			s.logger.Error("failed to play step 3 audio", "error", err)
-		}
-	}()
+	s.PlayAudioFileAsync(s.selectedGuide, s.AudioFile(s.selectedGuide, def.Audio))
 
 	// Save updated session state
 	if err := s.saveSessionToCache(); err != nil {
@@ -991,7 +1241,12 @@ func (s *OnboardingSession) StartStep3() error {
 
 // ShowGenderSelection displays gender selection buttons.
 func (s *OnboardingSession) ShowGenderSelection() error {
+	if !s.promptConfigured("step3.gender") || !s.stepVisible("step3.gender") {
+		return s.ShowAgeSelection()
+	}
+
 	s.currentSubStep = 1
+	s.Record(onboard.TimelineStep, "step3.1")
 	s.UpdateActivity()
 
 	embed := &discordgo.MessageEmbed{
@@ -1004,19 +1259,19 @@ func (s *OnboardingSession) ShowGenderSelection() error {
 			Components: []discordgo.MessageComponent{
 				discordgo.Button{
 					Label:    "男性",
-					Style:    discordgo.PrimaryButton,
+					Style:    s.ButtonStyle(ButtonChoice),
 					CustomID: fmt.Sprintf("onboarding:gender:male:%s", s.userID),
 				},
 				discordgo.Button{
 					Label:    "女性",
-					Style:    discordgo.PrimaryButton,
+					Style:    s.ButtonStyle(ButtonChoice),
 					CustomID: fmt.Sprintf("onboarding:gender:female:%s", s.userID),
 				},
 			},
 		},
 	}
 
-	_, err := s.session.ChannelMessageSendComplex(s.vcChannelID, &discordgo.MessageSend{
+	_, err := s.sendMessage(&discordgo.MessageSend{
 		Embeds:     []*discordgo.MessageEmbed{embed},
 		Components: components,
 	})
@@ -1029,7 +1284,12 @@ func (s *OnboardingSession) ShowGenderSelection() error {
 
 // ShowAgeSelection displays age range selection buttons.
 func (s *OnboardingSession) ShowAgeSelection() error {
+	if !s.promptConfigured("step3.age") || !s.stepVisible("step3.age") {
+		return s.ShowVoiceTypeSelection()
+	}
+
 	s.currentSubStep = 2
+	s.Record(onboard.TimelineStep, "step3.2")
 	s.UpdateActivity()
 
 	embed := &discordgo.MessageEmbed{
@@ -1042,17 +1302,17 @@ func (s *OnboardingSession) ShowAgeSelection() error {
 			Components: []discordgo.MessageComponent{
 				discordgo.Button{
 					Label:    "20代前半",
-					Style:    discordgo.PrimaryButton,
+					Style:    s.ButtonStyle(ButtonChoice),
 					CustomID: fmt.Sprintf("onboarding:age:20early:%s", s.userID),
 				},
 				discordgo.Button{
 					Label:    "20代後半",
-					Style:    discordgo.PrimaryButton,
+					Style:    s.ButtonStyle(ButtonChoice),
 					CustomID: fmt.Sprintf("onboarding:age:20late:%s", s.userID),
 				},
 				discordgo.Button{
 					Label:    "30代前半",
-					Style:    discordgo.PrimaryButton,
+					Style:    s.ButtonStyle(ButtonChoice),
 					CustomID: fmt.Sprintf("onboarding:age:30early:%s", s.userID),
 				},
 			},
@@ -1061,24 +1321,24 @@ func (s *OnboardingSession) ShowAgeSelection() error {
 			Components: []discordgo.MessageComponent{
 				discordgo.Button{
 					Label:    "30代後半",
-					Style:    discordgo.PrimaryButton,
+					Style:    s.ButtonStyle(ButtonChoice),
 					CustomID: fmt.Sprintf("onboarding:age:30late:%s", s.userID),
 				},
 				discordgo.Button{
 					Label:    "40代前半",
-					Style:    discordgo.PrimaryButton,
+					Style:    s.ButtonStyle(ButtonChoice),
 					CustomID: fmt.Sprintf("onboarding:age:40early:%s", s.userID),
 				},
 				discordgo.Button{
 					Label:    "40代後半",
-					Style:    discordgo.PrimaryButton,
+					Style:    s.ButtonStyle(ButtonChoice),
 					CustomID: fmt.Sprintf("onboarding:age:40late:%s", s.userID),
 				},
 			},
 		},
 	}
 
-	_, err := s.session.ChannelMessageSendComplex(s.vcChannelID, &discordgo.MessageSend{
+	_, err := s.sendMessage(&discordgo.MessageSend{
 		Embeds:     []*discordgo.MessageEmbed{embed},
 		Components: components,
 	})
@@ -1091,7 +1351,12 @@ func (s *OnboardingSession) ShowAgeSelection() error {
 
 // ShowVoiceTypeSelection displays voice type selection buttons.
 func (s *OnboardingSession) ShowVoiceTypeSelection() error {
+	if !s.promptConfigured("step3.voice") || !s.stepVisible("step3.voice") {
+		return s.ShowEroipuSelection()
+	}
+
 	s.currentSubStep = 3
+	s.Record(onboard.TimelineStep, "step3.3")
 	s.UpdateActivity()
 
 	embed := &discordgo.MessageEmbed{
@@ -1104,34 +1369,34 @@ func (s *OnboardingSession) ShowVoiceTypeSelection() error {
 			Components: []discordgo.MessageComponent{
 				discordgo.Button{
 					Label:    "高音",
-					Style:    discordgo.PrimaryButton,
+					Style:    s.ButtonStyle(ButtonChoice),
 					CustomID: fmt.Sprintf("onboarding:voice:high:%s", s.userID),
 				},
 				discordgo.Button{
 					Label:    "中高音",
-					Style:    discordgo.PrimaryButton,
+					Style:    s.ButtonStyle(ButtonChoice),
 					CustomID: fmt.Sprintf("onboarding:voice:midhigh:%s", s.userID),
 				},
 				discordgo.Button{
 					Label:    "中音",
-					Style:    discordgo.PrimaryButton,
+					Style:    s.ButtonStyle(ButtonChoice),
 					CustomID: fmt.Sprintf("onboarding:voice:mid:%s", s.userID),
 				},
 				discordgo.Button{
 					Label:    "中低音",
-					Style:    discordgo.PrimaryButton,
+					Style:    s.ButtonStyle(ButtonChoice),
 					CustomID: fmt.Sprintf("onboarding:voice:midlow:%s", s.userID),
 				},
 				discordgo.Button{
 					Label:    "低音",
-					Style:    discordgo.PrimaryButton,
+					Style:    s.ButtonStyle(ButtonChoice),
 					CustomID: fmt.Sprintf("onboarding:voice:low:%s", s.userID),
 				},
 			},
 		},
 	}
 
-	_, err := s.session.ChannelMessageSendComplex(s.vcChannelID, &discordgo.MessageSend{
+	_, err := s.sendMessage(&discordgo.MessageSend{
 		Embeds:     []*discordgo.MessageEmbed{embed},
 		Components: components,
 	})
@@ -1144,7 +1409,12 @@ func (s *OnboardingSession) ShowVoiceTypeSelection() error {
 
 // ShowEroipuSelection displays eroipu OK/NG buttons.
 func (s *OnboardingSession) ShowEroipuSelection() error {
+	if !s.promptConfigured("step3.eroipu") || !s.stepVisible("step3.eroipu") {
+		return s.ShowNeochiOkNgSelection()
+	}
+
 	s.currentSubStep = 4
+	s.Record(onboard.TimelineStep, "step3.4")
 	s.UpdateActivity()
 
 	embed := &discordgo.MessageEmbed{
@@ -1157,19 +1427,19 @@ func (s *OnboardingSession) ShowEroipuSelection() error {
 			Components: []discordgo.MessageComponent{
 				discordgo.Button{
 					Label:    "エロイプOK",
-					Style:    discordgo.SuccessButton,
+					Style:    s.ButtonStyle(ButtonOK),
 					CustomID: fmt.Sprintf("onboarding:eroipu:ok:%s", s.userID),
 				},
 				discordgo.Button{
 					Label:    "エロイプNG",
-					Style:    discordgo.DangerButton,
+					Style:    s.ButtonStyle(ButtonNG),
 					CustomID: fmt.Sprintf("onboarding:eroipu:ng:%s", s.userID),
 				},
 			},
 		},
 	}
 
-	_, err := s.session.ChannelMessageSendComplex(s.vcChannelID, &discordgo.MessageSend{
+	_, err := s.sendMessage(&discordgo.MessageSend{
 		Embeds:     []*discordgo.MessageEmbed{embed},
 		Components: components,
 	})
@@ -1182,7 +1452,12 @@ func (s *OnboardingSession) ShowEroipuSelection() error {
 
 // ShowNeochiOkNgSelection displays neochi OK/NG buttons.
 func (s *OnboardingSession) ShowNeochiOkNgSelection() error {
+	if !s.promptConfigured("step3.neochi") || !s.stepVisible("step3.neochi") {
+		return s.ShowNeochiHandlingSelection()
+	}
+
 	s.currentSubStep = 5
+	s.Record(onboard.TimelineStep, "step3.5")
 	s.UpdateActivity()
 
 	embed := &discordgo.MessageEmbed{
@@ -1195,19 +1470,19 @@ func (s *OnboardingSession) ShowNeochiOkNgSelection() error {
 			Components: []discordgo.MessageComponent{
 				discordgo.Button{
 					Label:    "寝落ちOK",
-					Style:    discordgo.SuccessButton,
+					Style:    s.ButtonStyle(ButtonOK),
 					CustomID: fmt.Sprintf("onboarding:neochi:ok:%s", s.userID),
 				},
 				discordgo.Button{
 					Label:    "寝落ちNG",
-					Style:    discordgo.DangerButton,
+					Style:    s.ButtonStyle(ButtonNG),
 					CustomID: fmt.Sprintf("onboarding:neochi:ng:%s", s.userID),
 				},
 			},
 		},
 	}
 
-	_, err := s.session.ChannelMessageSendComplex(s.vcChannelID, &discordgo.MessageSend{
+	_, err := s.sendMessage(&discordgo.MessageSend{
 		Embeds:     []*discordgo.MessageEmbed{embed},
 		Components: components,
 	})
@@ -1220,7 +1495,12 @@ func (s *OnboardingSession) ShowNeochiOkNgSelection() error {
 
 // ShowNeochiHandlingSelection displays neochi handling buttons.
 func (s *OnboardingSession) ShowNeochiHandlingSelection() error {
+	if !s.promptConfigured("step3.neochi_handling") || !s.stepVisible("step3.neochi_handling") {
+		return s.ShowDMSelection()
+	}
+
 	s.currentSubStep = 6
+	s.Record(onboard.TimelineStep, "step3.6")
 	s.UpdateActivity()
 
 	embed := &discordgo.MessageEmbed{
@@ -1233,19 +1513,19 @@ func (s *OnboardingSession) ShowNeochiHandlingSelection() error {
 			Components: []discordgo.MessageComponent{
 				discordgo.Button{
 					Label:    "寝落ち部屋",
-					Style:    discordgo.PrimaryButton,
+					Style:    s.ButtonStyle(ButtonChoice),
 					CustomID: fmt.Sprintf("onboarding:neochi_handling:room:%s", s.userID),
 				},
 				discordgo.Button{
 					Label:    "寝落ち切断",
-					Style:    discordgo.PrimaryButton,
+					Style:    s.ButtonStyle(ButtonChoice),
 					CustomID: fmt.Sprintf("onboarding:neochi_handling:disconnect:%s", s.userID),
 				},
 			},
 		},
 	}
 
-	_, err := s.session.ChannelMessageSendComplex(s.vcChannelID, &discordgo.MessageSend{
+	_, err := s.sendMessage(&discordgo.MessageSend{
 		Embeds:     []*discordgo.MessageEmbed{embed},
 		Components: components,
 	})
@@ -1258,7 +1538,12 @@ func (s *OnboardingSession) ShowNeochiHandlingSelection() error {
 
 // ShowDMSelection displays DM OK/NG buttons.
 func (s *OnboardingSession) ShowDMSelection() error {
+	if !s.promptConfigured("step3.dm") || !s.stepVisible("step3.dm") {
+		return s.ShowFriendSelection()
+	}
+
 	s.currentSubStep = 7
+	s.Record(onboard.TimelineStep, "step3.7")
 	s.UpdateActivity()
 
 	embed := &discordgo.MessageEmbed{
@@ -1271,19 +1556,19 @@ func (s *OnboardingSession) ShowDMSelection() error {
 			Components: []discordgo.MessageComponent{
 				discordgo.Button{
 					Label:    "DMOK",
-					Style:    discordgo.SuccessButton,
+					Style:    s.ButtonStyle(ButtonOK),
 					CustomID: fmt.Sprintf("onboarding:dm:ok:%s", s.userID),
 				},
 				discordgo.Button{
 					Label:    "DMNG",
-					Style:    discordgo.DangerButton,
+					Style:    s.ButtonStyle(ButtonNG),
 					CustomID: fmt.Sprintf("onboarding:dm:ng:%s", s.userID),
 				},
 			},
 		},
 	}
 
-	_, err := s.session.ChannelMessageSendComplex(s.vcChannelID, &discordgo.MessageSend{
+	_, err := s.sendMessage(&discordgo.MessageSend{
 		Embeds:     []*discordgo.MessageEmbed{embed},
 		Components: components,
 	})
@@ -1296,7 +1581,12 @@ func (s *OnboardingSession) ShowDMSelection() error {
 
 // ShowFriendSelection displays friend OK/NG buttons.
 func (s *OnboardingSession) ShowFriendSelection() error {
+	if !s.promptConfigured("step3.friend") || !s.stepVisible("step3.friend") {
+		return s.ShowEventSelection()
+	}
+
 	s.currentSubStep = 8
+	s.Record(onboard.TimelineStep, "step3.8")
 	s.UpdateActivity()
 
 	embed := &discordgo.MessageEmbed{
@@ -1309,19 +1599,19 @@ func (s *OnboardingSession) ShowFriendSelection() error {
 			Components: []discordgo.MessageComponent{
 				discordgo.Button{
 					Label:    "フレンド OK",
-					Style:    discordgo.SuccessButton,
+					Style:    s.ButtonStyle(ButtonOK),
 					CustomID: fmt.Sprintf("onboarding:friend:ok:%s", s.userID),
 				},
 				discordgo.Button{
 					Label:    "フレンド NG",
-					Style:    discordgo.DangerButton,
+					Style:    s.ButtonStyle(ButtonNG),
 					CustomID: fmt.Sprintf("onboarding:friend:ng:%s", s.userID),
 				},
 			},
 		},
 	}
 
-	_, err := s.session.ChannelMessageSendComplex(s.vcChannelID, &discordgo.MessageSend{
+	_, err := s.sendMessage(&discordgo.MessageSend{
 		Embeds:     []*discordgo.MessageEmbed{embed},
 		Components: components,
 	})
@@ -1332,36 +1622,38 @@ func (s *OnboardingSession) ShowFriendSelection() error {
 	return s.saveSessionToCache()
 }
 
-// ShowEventSelection displays event role buttons (users can select both).
+// eventOptions are the step 3 event roles, in button order.
+var eventOptions = []struct {
+	key      string
+	labelKey string
+}{
+	{"bunnyclub", "onboarding.button_event_bunnyclub"},
+	{"user", "onboarding.button_event_user"},
+}
+
+// ShowEventSelection displays event role toggles. Users can select any of
+// them and click 完了 when done.
 func (s *OnboardingSession) ShowEventSelection() error {
+	if !s.promptConfigured("step3.event") || !s.stepVisible("step3.event") {
+		return s.ShowStep3Completion()
+	}
+
 	s.currentSubStep = 9
+	s.Record(onboard.TimelineStep, "step3.9")
 	s.UpdateActivity()
 
+	s.eventsMu.Lock()
+	s.eventsDone = false
+	s.eventsMu.Unlock()
+
 	embed := &discordgo.MessageEmbed{
 		Description: s.i18n.T(s.ctx, s.guildID, "onboarding.step3_event_prompt"),
 		Color:       0x9b59b6,
 	}
 
-	components := []discordgo.MessageComponent{
-		discordgo.ActionsRow{
-			Components: []discordgo.MessageComponent{
-				discordgo.Button{
-					Label:    "BunnyClub イベント",
-					Style:    discordgo.PrimaryButton,
-					CustomID: fmt.Sprintf("onboarding:event:bunnyclub:%s", s.userID),
-				},
-				discordgo.Button{
-					Label:    "ユーザーイベント",
-					Style:    discordgo.PrimaryButton,
-					CustomID: fmt.Sprintf("onboarding:event:user:%s", s.userID),
-				},
-			},
-		},
-	}
-
-	_, err := s.session.ChannelMessageSendComplex(s.vcChannelID, &discordgo.MessageSend{
+	_, err := s.sendMessage(&discordgo.MessageSend{
 		Embeds:     []*discordgo.MessageEmbed{embed},
-		Components: components,
+		Components: s.EventSelectionComponents(),
 	})
 	if err != nil {
 		return fmt.Errorf("send event selection: %w", err)
@@ -1370,9 +1662,88 @@ func (s *OnboardingSession) ShowEventSelection() error {
 	return s.saveSessionToCache()
 }
 
+// ToggleEvent flips the selection of an event role and reports whether it
+// is now selected.
+func (s *OnboardingSession) ToggleEvent(event string) bool {
+	s.eventsMu.Lock()
+	defer s.eventsMu.Unlock()
+
+	if s.selectedEvents == nil {
+		s.selectedEvents = make(map[string]bool)
+	}
+	s.selectedEvents[event] = !s.selectedEvents[event]
+	return s.selectedEvents[event]
+}
+
+// FinishEventSelection claims clicking 完了 on the event selection. It
+// reports true to exactly one caller while the member is on step 3, so a
+// double click can't show the step's completion twice.
+func (s *OnboardingSession) FinishEventSelection() bool {
+	if s.CurrentStep() != 3 {
+		return false
+	}
+
+	s.eventsMu.Lock()
+	defer s.eventsMu.Unlock()
+
+	if s.eventsDone {
+		return false
+	}
+	s.eventsDone = true
+	return true
+}
+
+// SelectedEvents returns the selected event roles in button order.
+func (s *OnboardingSession) SelectedEvents() []string {
+	s.eventsMu.Lock()
+	defer s.eventsMu.Unlock()
+
+	var events []string
+	for _, option := range eventOptions {
+		if s.selectedEvents[option.key] {
+			events = append(events, option.key)
+		}
+	}
+	return events
+}
+
+// EventSelectionComponents returns the event toggles, styled by whether
+// each is selected, and the 完了 button.
+func (s *OnboardingSession) EventSelectionComponents() []discordgo.MessageComponent {
+	selected := make(map[string]bool)
+	for _, event := range s.SelectedEvents() {
+		selected[event] = true
+	}
+
+	var buttons []discordgo.MessageComponent
+	for _, option := range eventOptions {
+		label := s.i18n.T(s.ctx, s.guildID, option.labelKey)
+		button := discordgo.Button{
+			Label:    label,
+			Style:    s.ButtonStyle(ButtonToggle),
+			CustomID: fmt.Sprintf("onboarding:event:%s:%s", option.key, s.userID),
+		}
+		if selected[option.key] {
+			button.Label = "✅ " + label
+			button.Style = s.ButtonStyle(ButtonToggled)
+		}
+		buttons = append(buttons, button)
+	}
+	buttons = append(buttons, discordgo.Button{
+		Label:    s.i18n.T(s.ctx, s.guildID, "onboarding.button_event_done"),
+		Style:    s.ButtonStyle(ButtonNext),
+		CustomID: fmt.Sprintf("onboarding:event_done:%s", s.userID),
+	})
+
+	return []discordgo.MessageComponent{
+		discordgo.ActionsRow{Components: buttons},
+	}
+}
+
 // ShowStep3Completion shows the final message of step 3 with next button.
 func (s *OnboardingSession) ShowStep3Completion() error {
 	s.currentSubStep = 10
+	s.Record(onboard.TimelineStep, "step3.10")
 	s.UpdateActivity()
 
 	embed := &discordgo.MessageEmbed{
@@ -1385,14 +1756,14 @@ func (s *OnboardingSession) ShowStep3Completion() error {
 			Components: []discordgo.MessageComponent{
 				discordgo.Button{
 					Label:    s.i18n.T(s.ctx, s.guildID, "onboarding.button_next"),
-					Style:    discordgo.SuccessButton,
+					Style:    s.ButtonStyle(ButtonConfirm),
 					CustomID: fmt.Sprintf("onboarding:step3_next:%s", s.userID),
 				},
 			},
 		},
 	}
 
-	_, err := s.session.ChannelMessageSendComplex(s.vcChannelID, &discordgo.MessageSend{
+	_, err := s.sendMessage(&discordgo.MessageSend{
 		Embeds:     []*discordgo.MessageEmbed{embed},
 		Components: components,
 	})
@@ -1403,60 +1774,35 @@ func (s *OnboardingSession) ShowStep3Completion() error {
 	return s.saveSessionToCache()
 }
 
-// StartStep4 begins step 4 of the onboarding tutorial.
-func (s *OnboardingSession) StartStep4() error {
-	s.currentStep = 4
-	s.UpdateActivity()
-
+// showStep4 shows step 4 of the onboarding tutorial.
+func (s *OnboardingSession) showStep4(def StepDef) error {
 	// Message 1: First part of text
 	part1 := s.i18n.T(s.ctx, s.guildID, "onboarding.step4_description_part1")
-	_, err := s.session.ChannelMessageSend(s.vcChannelID, part1)
+	err := s.sendText(part1)
 	if err != nil {
 		return fmt.Errorf("send step 4 part 1: %w", err)
 	}
 
-	// Message 2: Image
-	imagePath := "assets/images/onboarding/step4.png"
-	file, err := os.Open(imagePath)
-	if err != nil {
-		s.logger.Warn("failed to open step 4 image", "error", err, "path", imagePath)
-	} else {
-		defer file.Close()
-		_, err = s.session.ChannelMessageSendComplex(s.vcChannelID, &discordgo.MessageSend{
-			Files: []*discordgo.File{
-				{
-					Name:   "step4.png",
-					Reader: file,
-				},
-			},
-		})
-		if err != nil {
-			s.logger.Warn("failed to send step 4 image", "error", err)
-		} else {
-			s.logger.Info("sent step 4 guide image")
-		}
+	// Message 2: Guide images
+	if err := s.sendStepImages(s.selectedGuide, def); err != nil {
+		return fmt.Errorf("send step 4 images: %w", err)
 	}
 
 	// Message 3: Second part of text with buttons
 	part2 := s.i18n.T(s.ctx, s.guildID, "onboarding.step4_description_part2")
 	components := []discordgo.MessageComponent{
 		discordgo.ActionsRow{
-			Components: []discordgo.MessageComponent{
+			Components: append([]discordgo.MessageComponent{
 				discordgo.Button{
 					Label:    s.i18n.T(s.ctx, s.guildID, "onboarding.button_next"),
-					Style:    discordgo.PrimaryButton,
+					Style:    s.ButtonStyle(ButtonNext),
 					CustomID: fmt.Sprintf("onboarding:step4_next:%s", s.userID),
 				},
-				discordgo.Button{
-					Label:    s.i18n.T(s.ctx, s.guildID, "onboarding.button_replay"),
-					Style:    discordgo.SecondaryButton,
-					CustomID: fmt.Sprintf("onboarding:step4_replay:%s", s.userID),
-				},
-			},
+			}, s.replayButtons(4)...),
 		},
 	}
 
-	_, err = s.session.ChannelMessageSendComplex(s.vcChannelID, &discordgo.MessageSend{
+	_, err = s.sendMessage(&discordgo.MessageSend{
 		Content:    part2,
 		Components: components,
 	})
@@ -1470,7 +1816,7 @@ func (s *OnboardingSession) StartStep4() error {
 	}
 
 	// Play step 4 point audio
-	if err := s.playAudioFile(s.selectedGuide, "4-point.dca"); err != nil {
+	if err := s.playAudioFile(s.selectedGuide, s.AudioFile(s.selectedGuide, def.Audio)); err != nil {
 		s.logger.Error("failed to play step 4 audio", "error", err)
 		return fmt.Errorf("play step 4 audio: %w", err)
 	}
@@ -1478,31 +1824,23 @@ func (s *OnboardingSession) StartStep4() error {
 	return nil
 }
 
-// StartStep5 begins step 5 of the onboarding tutorial.
-func (s *OnboardingSession) StartStep5() error {
-	s.currentStep = 5
-	s.UpdateActivity()
-
+// showStep5 shows step 5 of the onboarding tutorial.
+func (s *OnboardingSession) showStep5(def StepDef) error {
 	// Send plain markdown message with buttons
 	content := s.i18n.T(s.ctx, s.guildID, "onboarding.step5_description")
 	components := []discordgo.MessageComponent{
 		discordgo.ActionsRow{
-			Components: []discordgo.MessageComponent{
+			Components: append([]discordgo.MessageComponent{
 				discordgo.Button{
 					Label:    s.i18n.T(s.ctx, s.guildID, "onboarding.button_next"),
-					Style:    discordgo.PrimaryButton,
+					Style:    s.ButtonStyle(ButtonNext),
 					CustomID: fmt.Sprintf("onboarding:step5_next:%s", s.userID),
 				},
-				discordgo.Button{
-					Label:    s.i18n.T(s.ctx, s.guildID, "onboarding.button_replay"),
-					Style:    discordgo.SecondaryButton,
-					CustomID: fmt.Sprintf("onboarding:step5_replay:%s", s.userID),
-				},
-			},
+			}, s.replayButtons(5)...),
 		},
 	}
 
-	_, err := s.session.ChannelMessageSendComplex(s.vcChannelID, &discordgo.MessageSend{
+	_, err := s.sendMessage(&discordgo.MessageSend{
 		Content:    content,
 		Components: components,
 	})
@@ -1516,7 +1854,7 @@ func (s *OnboardingSession) StartStep5() error {
 	}
 
 	// Play step 5 club audio
-	if err := s.playAudioFile(s.selectedGuide, "5-club.dca"); err != nil {
+	if err := s.playAudioFile(s.selectedGuide, s.AudioFile(s.selectedGuide, def.Audio)); err != nil {
 		s.logger.Error("failed to play step 5 audio", "error", err)
 		return fmt.Errorf("play step 5 audio: %w", err)
 	}
@@ -1524,88 +1862,35 @@ func (s *OnboardingSession) StartStep5() error {
 	return nil
 }
 
-// StartStep6 begins step 6 of the onboarding tutorial.
-func (s *OnboardingSession) StartStep6() error {
-	s.currentStep = 6
-	s.UpdateActivity()
-
+// showStep6 shows step 6 of the onboarding tutorial.
+func (s *OnboardingSession) showStep6(def StepDef) error {
 	// Message 1: First part of text
 	part1 := s.i18n.T(s.ctx, s.guildID, "onboarding.step6_description_part1")
-	_, err := s.session.ChannelMessageSend(s.vcChannelID, part1)
+	err := s.sendText(part1)
 	if err != nil {
 		return fmt.Errorf("send step 6 part 1: %w", err)
 	}
 
-	// Message 2: First image
-	imagePath1 := "assets/images/onboarding/step6-1.png"
-	file1, err := os.Open(imagePath1)
-	if err != nil {
-		s.logger.Warn("failed to open step 6 image 1", "error", err, "path", imagePath1)
-	} else {
-		defer file1.Close()
-		_, err = s.session.ChannelMessageSendComplex(s.vcChannelID, &discordgo.MessageSend{
-			Files: []*discordgo.File{
-				{
-					Name:   "step6-1.png",
-					Reader: file1,
-				},
-			},
-		})
-		if err != nil {
-			s.logger.Warn("failed to send step 6 image 1", "error", err)
-		} else {
-			s.logger.Info("sent step 6 guide image 1")
-		}
-	}
-
-	// Message 3: Second part of text
-	part2 := s.i18n.T(s.ctx, s.guildID, "onboarding.step6_description_part2")
-	_, err = s.session.ChannelMessageSend(s.vcChannelID, part2)
-	if err != nil {
-		return fmt.Errorf("send step 6 part 2: %w", err)
-	}
-
-	// Message 4: Second image
-	imagePath2 := "assets/images/onboarding/step6-2.png"
-	file2, err := os.Open(imagePath2)
-	if err != nil {
-		s.logger.Warn("failed to open step 6 image 2", "error", err, "path", imagePath2)
-	} else {
-		defer file2.Close()
-		_, err = s.session.ChannelMessageSendComplex(s.vcChannelID, &discordgo.MessageSend{
-			Files: []*discordgo.File{
-				{
-					Name:   "step6-2.png",
-					Reader: file2,
-				},
-			},
-		})
-		if err != nil {
-			s.logger.Warn("failed to send step 6 image 2", "error", err)
-		} else {
-			s.logger.Info("sent step 6 guide image 2")
-		}
+	// Messages 2-4: Guide images, with the second part of the text as the
+	// caption of the second image by default
+	if err := s.sendStepImages(s.selectedGuide, def); err != nil {
+		return fmt.Errorf("send step 6 images: %w", err)
 	}
 
 	// Message 5: Buttons
 	components := []discordgo.MessageComponent{
 		discordgo.ActionsRow{
-			Components: []discordgo.MessageComponent{
+			Components: append([]discordgo.MessageComponent{
 				discordgo.Button{
 					Label:    s.i18n.T(s.ctx, s.guildID, "onboarding.button_next"),
-					Style:    discordgo.PrimaryButton,
+					Style:    s.ButtonStyle(ButtonNext),
 					CustomID: fmt.Sprintf("onboarding:step6_next:%s", s.userID),
 				},
-				discordgo.Button{
-					Label:    s.i18n.T(s.ctx, s.guildID, "onboarding.button_replay"),
-					Style:    discordgo.SecondaryButton,
-					CustomID: fmt.Sprintf("onboarding:step6_replay:%s", s.userID),
-				},
-			},
+			}, s.replayButtons(6)...),
 		},
 	}
 
-	_, err = s.session.ChannelMessageSendComplex(s.vcChannelID, &discordgo.MessageSend{
+	_, err = s.sendMessage(&discordgo.MessageSend{
 		Components: components,
 	})
 	if err != nil {
@@ -1618,7 +1903,7 @@ func (s *OnboardingSession) StartStep6() error {
 	}
 
 	// Play step 6 membership audio
-	if err := s.playAudioFile(s.selectedGuide, "6-membership.dca"); err != nil {
+	if err := s.playAudioFile(s.selectedGuide, s.AudioFile(s.selectedGuide, def.Audio)); err != nil {
 		s.logger.Error("failed to play step 6 audio", "error", err)
 		return fmt.Errorf("play step 6 audio: %w", err)
 	}
@@ -1626,31 +1911,23 @@ func (s *OnboardingSession) StartStep6() error {
 	return nil
 }
 
-// StartStep7 begins step 7 of the onboarding tutorial (final step).
-func (s *OnboardingSession) StartStep7() error {
-	s.currentStep = 7
-	s.UpdateActivity()
-
+// showStep7 shows step 7 of the onboarding tutorial (final step).
+func (s *OnboardingSession) showStep7(def StepDef) error {
 	// Send plain markdown message with buttons
 	content := s.i18n.T(s.ctx, s.guildID, "onboarding.step7_description")
 	components := []discordgo.MessageComponent{
 		discordgo.ActionsRow{
-			Components: []discordgo.MessageComponent{
+			Components: append([]discordgo.MessageComponent{
 				discordgo.Button{
 					Label:    s.i18n.T(s.ctx, s.guildID, "onboarding.button_complete"),
-					Style:    discordgo.SuccessButton,
+					Style:    s.ButtonStyle(ButtonComplete),
 					CustomID: fmt.Sprintf("onboarding:step7_complete:%s", s.userID),
 				},
-				discordgo.Button{
-					Label:    s.i18n.T(s.ctx, s.guildID, "onboarding.button_replay"),
-					Style:    discordgo.SecondaryButton,
-					CustomID: fmt.Sprintf("onboarding:step7_replay:%s", s.userID),
-				},
-			},
+			}, s.replayButtons(7)...),
 		},
 	}
 
-	_, err := s.session.ChannelMessageSendComplex(s.vcChannelID, &discordgo.MessageSend{
+	_, err := s.sendMessage(&discordgo.MessageSend{
 		Content:    content,
 		Components: components,
 	})
@@ -1664,7 +1941,7 @@ func (s *OnboardingSession) StartStep7() error {
 	}
 
 	// Play step 7 end audio
-	if err := s.playAudioFile(s.selectedGuide, "7-end.dca"); err != nil {
+	if err := s.playAudioFile(s.selectedGuide, s.AudioFile(s.selectedGuide, def.Audio)); err != nil {
 		s.logger.Error("failed to play step 7 audio", "error", err)
 		return fmt.Errorf("play step 7 audio: %w", err)
 	}
@@ -1672,41 +1949,68 @@ func (s *OnboardingSession) StartStep7() error {
 	return nil
 }
 
-// sendGuideImage sends a guide image to the voice channel.
-// This is a helper method to send images from the assets/images/onboarding directory.
-func (s *OnboardingSession) sendGuideImage(filename string) error {
-	imagePath := fmt.Sprintf("assets/images/onboarding/%s", filename)
-	s.logger.Info("sending guide image", "path", imagePath)
+// sendStepImages sends a step's guide images, each preceded by its caption.
+// Images come from the guide manifest and are picked for the guild's
+// locale. A missing or unsendable image is logged and skipped so the step
+// still goes ahead, but a caption is step text and failing to send one is
+// returned. Steps without Images send none.
+func (s *OnboardingSession) sendStepImages(guide string, def StepDef) error {
+	if !def.Images {
+		return nil
+	}
+	key := def.Audio
+
+	manifest, err := onboard.LoadManifest(guide)
+	if err != nil {
+		s.logger.Warn("failed to load guide manifest, using default images", "guide", guide, "error", err)
+	}
+
+	images := manifest.StepImages(key)
+	if len(images) == 0 {
+		return nil
+	}
 
-	// Check if file exists
-	if _, err := os.Stat(imagePath); os.IsNotExist(err) {
-		s.logger.Warn("guide image not found", "path", imagePath)
-		return nil // Don't fail the step if image is missing
+	locale, _ := s.i18n.GetGuildLanguage(s.ctx, s.guildID)
+	for _, img := range images {
+		if img.Caption != "" {
+			if err := s.sendText(s.i18n.T(s.ctx, s.guildID, img.Caption)); err != nil {
+				return fmt.Errorf("send caption of %s: %w", img.File, err)
+			}
+		}
+		s.sendGuideImage(img.FileFor(locale))
 	}
+	return nil
+}
 
-	// Open image file
-	file, err := os.Open(imagePath)
+// sendGuideImage sends an image from the assets/images/onboarding directory
+// to the voice channel. Failures are logged, never returned.
+func (s *OnboardingSession) sendGuideImage(filename string) {
+	path, err := onboard.ImagePath(filename)
 	if err != nil {
-		s.logger.Error("failed to open guide image", "error", err, "path", imagePath)
-		return nil // Don't fail the step if image can't be opened
+		s.logger.Warn("refused guide image", "error", err)
+		return
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		s.logger.Warn("failed to open guide image", "error", err, "path", path)
+		return
 	}
 	defer file.Close()
 
-	// Send image as attachment
-	_, err = s.session.ChannelMessageSendComplex(s.vcChannelID, &discordgo.MessageSend{
+	_, err = s.sendMessage(&discordgo.MessageSend{
 		Files: []*discordgo.File{
 			{
-				Name:   filename,
+				Name:   filepath.Base(filename),
 				Reader: file,
 			},
 		},
 	})
 	if err != nil {
-		s.logger.Error("failed to send guide image", "error", err, "path", imagePath)
-		return nil // Don't fail the step if image send fails
+		s.logger.Warn("failed to send guide image", "error", err, "path", path)
+		return
 	}
 
-	s.logger.Info("guide image sent successfully", "path", imagePath)
-	return nil
+	s.logger.Info("sent guide image", "path", path)
 }
 
@@ -0,0 +1,58 @@
+package worker
+
+import (
+	"errors"
+
+	"welcomebot/internal/onboard"
+)
+
+// ErrPreviewsClosed is returned by PlayPreview once the tutorial has
+// started, so a leftover preview button can't replace step audio.
+var ErrPreviewsClosed = errors.New("guide previews are closed once the tutorial starts")
+
+// PlayPreview plays a guide's preview, cutting off the greeting or the
+// preview already playing so two never overlap. Playback starts in the
+// background; when previews are clicked in quick succession, the last
+// click wins even if an earlier one is still starting.
+func (s *OnboardingSession) PlayPreview(guide string) error {
+	s.audioMu.Lock()
+	if s.previewsClosed {
+		s.audioMu.Unlock()
+		return ErrPreviewsClosed
+	}
+	s.audioGen++
+	s.player.Stop()
+	s.previewGuide = guide
+	gen := s.audioGen
+	s.audioMu.Unlock()
+
+	go func() {
+		if err := s.playAudioFileGen(guide, s.AudioFile(guide, onboard.AudioPreview), gen); err != nil {
+			s.logger.Error("failed to play preview", "error", err, "guide", guide)
+		}
+	}()
+	return nil
+}
+
+// PreviewGuide returns the guide whose preview was played last, or "" if
+// none was or the tutorial has started.
+func (s *OnboardingSession) PreviewGuide() string {
+	s.audioMu.Lock()
+	defer s.audioMu.Unlock()
+
+	return s.previewGuide
+}
+
+// closePreviews stops a playing preview and refuses further ones. Steps
+// call it before their audio starts.
+func (s *OnboardingSession) closePreviews() {
+	s.audioMu.Lock()
+	defer s.audioMu.Unlock()
+
+	if s.previewGuide != "" {
+		s.audioGen++
+		s.player.Stop()
+		s.previewGuide = ""
+	}
+	s.previewsClosed = true
+}
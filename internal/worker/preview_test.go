@@ -0,0 +1,162 @@
+package worker
+
+import (
+	"errors"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+
+	"welcomebot/internal/fakes"
+	"welcomebot/internal/onboard"
+)
+
+// defaultAudioFile is the file a guide without a manifest plays for key.
+func defaultAudioFile(key string) string {
+	return new(onboard.Manifest).AudioFile(key)
+}
+
+func TestHasPreview(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	for _, dir := range []string{"preview-test-recorded", "preview-test-silent"} {
+		if err := os.MkdirAll(onboard.GuideDir(dir), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	preview := onboard.AudioPath("preview-test-recorded", defaultAudioFile(onboard.AudioPreview))
+	if err := os.WriteFile(preview, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	s := &OnboardingSession{}
+	if !s.HasPreview("preview-test-recorded") {
+		t.Error("expected recorded preview to be available")
+	}
+	if s.HasPreview("preview-test-silent") {
+		t.Error("expected guide without a recording to have no preview")
+	}
+
+	guides := s.availableGuides()
+	if len(guides) != 2 || guides[0] != "preview-test-recorded" {
+		t.Errorf("expected both installed guides, got %v", guides)
+	}
+}
+
+func TestHasGreeting(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	for _, dir := range []string{"greeting-test-recorded", "greeting-test-silent"} {
+		if err := os.MkdirAll(onboard.GuideDir(dir), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	greeting := onboard.AudioPath("greeting-test-recorded", defaultAudioFile(onboard.AudioWelcome))
+	if err := os.WriteFile(greeting, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if (&OnboardingSession{}).HasGreeting() {
+		t.Error("expected no greeting when none is configured")
+	}
+	if !(&OnboardingSession{greetingGuide: "greeting-test-recorded"}).HasGreeting() {
+		t.Error("expected recorded greeting to be available")
+	}
+	if (&OnboardingSession{greetingGuide: "greeting-test-silent"}).HasGreeting() {
+		t.Error("expected guide without a greeting file to start in silence")
+	}
+	if (&OnboardingSession{greetingGuide: "greeting-test-recorded", sharedVC: true}).HasGreeting() {
+		t.Error("expected shared VCs to skip the greeting")
+	}
+}
+
+func TestGuideSelectionEmoji(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	for _, dir := range []string{"preview-test-recorded", "preview-test-silent"} {
+		if err := os.MkdirAll(onboard.GuideDir(dir), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	preview := onboard.AudioPath("preview-test-recorded", defaultAudioFile(onboard.AudioPreview))
+	if err := os.WriteFile(preview, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	s := &OnboardingSession{i18n: fakes.I18n{}, logger: fakes.Logger{}}
+	rows := s.BuildGuideSelectionComponents()
+
+	buttons := rows[0].(discordgo.ActionsRow).Components
+	recorded := buttons[0].(discordgo.Button)
+	if recorded.Emoji == nil || recorded.Emoji.Name != "🎧" {
+		t.Errorf("expected headphones on the preview button, got %+v", recorded.Emoji)
+	}
+	if strings.Contains(recorded.Label, "🎧") {
+		t.Errorf("emoji should not be repeated in the label %q", recorded.Label)
+	}
+	if silent := buttons[1].(discordgo.Button); silent.Emoji != nil || !silent.Disabled {
+		t.Errorf("expected a plain disabled button without a preview, got %+v", silent)
+	}
+
+	menu := rows[1].(discordgo.ActionsRow).Components[0].(discordgo.SelectMenu)
+	for _, opt := range menu.Options {
+		if opt.Emoji == nil || opt.Emoji.Name != "👤" {
+			t.Errorf("option %s: unexpected emoji %+v", opt.Value, opt.Emoji)
+		}
+	}
+}
+
+func TestPlayPreview(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	for _, guide := range []string{"aa", "bb"} {
+		if err := os.MkdirAll(onboard.GuideDir(guide), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(onboard.AudioPath(guide, defaultAudioFile(onboard.AudioPreview)), nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	player := &fakes.AudioPlayer{}
+	s := &OnboardingSession{ctx: t.Context(), player: player, i18n: fakes.I18n{}, logger: fakes.Logger{}}
+
+	if err := s.PlayPreview("aa"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.PlayPreview("bb"); err != nil {
+		t.Fatal(err)
+	}
+	want := "bb/" + defaultAudioFile(onboard.AudioPreview)
+	deadline := time.Now().Add(time.Second)
+	for !slices.Contains(player.Played(), want) && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	played := player.Played()
+	if len(played) == 0 || played[len(played)-1] != want {
+		t.Errorf("expected the last preview clicked to play last, got %v", played)
+	}
+	if s.PreviewGuide() != "bb" {
+		t.Errorf("expected bb to be previewing, got %q", s.PreviewGuide())
+	}
+
+	buttons := s.BuildGuideSelectionComponents()[0].(discordgo.ActionsRow).Components
+	if b := buttons[1].(discordgo.Button); !strings.HasPrefix(b.Label, "onboarding.preview_playing_label") || b.Style != s.ButtonStyle(ButtonToggled) {
+		t.Errorf("expected bb's button marked as playing, got %+v", b)
+	}
+	if b := buttons[0].(discordgo.Button); b.Style != s.ButtonStyle(ButtonPreview) {
+		t.Errorf("expected aa's button left alone, got %+v", b)
+	}
+
+	// Steps close previews so leftover buttons can't replace step audio
+	s.closePreviews()
+	if s.PreviewGuide() != "" || !player.Stopped() {
+		t.Error("expected the preview to be stopped when the tutorial starts")
+	}
+	if err := s.PlayPreview("aa"); !errors.Is(err, ErrPreviewsClosed) {
+		t.Errorf("expected previews to be refused, got %v", err)
+	}
+}
@@ -0,0 +1,110 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	"welcomebot/internal/onboard"
+)
+
+// saveCheckpoint records that the member completed step, with the guide
+// and the choices made so far. A failed write only costs the resume.
+func (s *OnboardingSession) saveCheckpoint(step int) {
+	selections, err := json.Marshal(s.Selections())
+	if err != nil {
+		s.logger.Warn("failed to marshal checkpoint selections", "error", err)
+		return
+	}
+
+	query := `
+		INSERT INTO member_onboarding_progress (guild_id, user_id, guide, step, selections, updated_at)
+		VALUES ($1, $2, $3, $4, $5, NOW())
+		ON CONFLICT (guild_id, user_id)
+		DO UPDATE SET guide = $3, step = $4, selections = $5, updated_at = NOW()
+	`
+	if _, err := s.db.Exec(s.ctx, query, s.guildID, s.userID, s.selectedGuide, step, string(selections)); err != nil {
+		s.logger.Warn("failed to save onboarding checkpoint", "error", err, "step", step)
+	}
+}
+
+// deleteCheckpoint removes the member's checkpoint once there is nothing
+// left to resume.
+func (s *OnboardingSession) deleteCheckpoint(ctx context.Context) error {
+	query := `DELETE FROM member_onboarding_progress WHERE guild_id = $1 AND user_id = $2`
+	if _, err := s.db.Exec(ctx, query, s.guildID, s.userID); err != nil {
+		return fmt.Errorf("delete checkpoint: %w", err)
+	}
+	return nil
+}
+
+// restoreSelections brings back the step 3 choices of a checkpoint, so
+// step conditions and the saved selections see them as if made here.
+func (s *OnboardingSession) restoreSelections(selections onboard.Selections) {
+	for name, values := range selections {
+		if onboard.MultiChoice(name) {
+			s.eventsMu.Lock()
+			if s.selectedEvents == nil {
+				s.selectedEvents = make(map[string]bool)
+			}
+			for _, event := range values {
+				s.selectedEvents[event] = true
+			}
+			s.eventsMu.Unlock()
+			continue
+		}
+		if len(values) > 0 {
+			s.RecordSelection(name, values[0])
+		}
+	}
+}
+
+// resumeFromCheckpoint skips guide selection and the steps the member
+// already completed, starting the one after the checkpoint's step. The
+// checkpoint's guide must still be available.
+func (s *OnboardingSession) resumeFromCheckpoint(checkpoint *onboard.Checkpoint) error {
+	s.selectedGuide = checkpoint.Guide
+	s.restoreSelections(checkpoint.Selections)
+	s.restoreStepRoles(checkpoint.Step)
+	s.Record(onboard.TimelineStep, fmt.Sprintf("resume.step%d", checkpoint.Step))
+	s.logger.Info("resuming onboarding", "user_id", s.userID, "guide", checkpoint.Guide, "completed_step", checkpoint.Step)
+
+	notice := s.i18n.TWithArgs(s.ctx, s.guildID, "onboarding.resumed", map[string]string{
+		"user": fmt.Sprintf("<@%s>", s.userID),
+		"step": fmt.Sprint(checkpoint.Step + 1),
+	})
+	if _, err := s.session.ChannelMessageSend(s.vcChannelID, notice); err != nil {
+		s.logger.Warn("failed to send resume notice", "error", err)
+	}
+
+	// Step 3 ends with its summary rather than a next button
+	if checkpoint.Step == 3 {
+		return s.Advance(3)
+	}
+	return s.advanceStep(checkpoint.Step)
+}
+
+// restoreStepRoles gives again the roles of the steps up to step, which
+// the member may have lost since, e.g. by leaving the guild in between.
+// Steps their selections hid gave no roles and are left out.
+func (s *OnboardingSession) restoreStepRoles(step int) {
+	for _, def := range s.Flow() {
+		if def.Number > step {
+			break
+		}
+		if def.Condition != nil && !s.stepVisible(def.ID) {
+			continue
+		}
+		for _, roleID := range def.AddRoles {
+			if err := s.addRole(roleID); err != nil {
+				s.logger.Warn("failed to restore step role", "error", err, "step", def.ID, "role_id", roleID)
+			}
+		}
+	}
+}
+
+// canResume reports whether a checkpoint's guide is still offered.
+func (s *OnboardingSession) canResume(checkpoint *onboard.Checkpoint) bool {
+	return checkpoint != nil && slices.Contains(s.availableGuides(), checkpoint.Guide)
+}
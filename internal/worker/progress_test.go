@@ -0,0 +1,107 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"welcomebot/internal/fakes"
+	"welcomebot/internal/onboard"
+)
+
+func TestSaveCheckpoint(t *testing.T) {
+	db := fakes.NewDB()
+	s := &OnboardingSession{guildID: "guild-1", userID: "user-1", selectedGuide: "kk", db: db, logger: fakes.Logger{}, ctx: context.Background()}
+	s.RecordSelection("age", "30late")
+
+	s.saveCheckpoint(3)
+	if err := s.deleteCheckpoint(context.Background()); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+
+	execs := db.Execs()
+	if len(execs) != 2 {
+		t.Fatalf("expected an upsert and a delete, got %d statements", len(execs))
+	}
+	if execs[0].Args[2] != "kk" || execs[0].Args[3] != 3 {
+		t.Errorf("guide, step = %v, %v; want kk, 3", execs[0].Args[2], execs[0].Args[3])
+	}
+	var saved onboard.Selections
+	if err := json.Unmarshal([]byte(execs[0].Args[4].(string)), &saved); err != nil {
+		t.Fatalf("decode selections: %v", err)
+	}
+	if fmt.Sprint(saved) != fmt.Sprint(onboard.Selections{"age": {"30late"}}) {
+		t.Errorf("selections = %v", saved)
+	}
+	if !strings.HasPrefix(strings.TrimSpace(execs[1].Query), "DELETE FROM member_onboarding_progress") {
+		t.Errorf("expected the checkpoint to be deleted, got %q", execs[1].Query)
+	}
+}
+
+func TestRestoreSelections(t *testing.T) {
+	s := &OnboardingSession{}
+	s.restoreSelections(onboard.Selections{"gender": {"female"}, "event": {"user", "bunnyclub"}})
+
+	want := onboard.Selections{"gender": {"female"}, "event": {"bunnyclub", "user"}}
+	if got := s.Selections(); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestCanResume(t *testing.T) {
+	t.Chdir(t.TempDir())
+	if err := os.MkdirAll(onboard.GuideDir("kk"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	s := &OnboardingSession{logger: fakes.Logger{}}
+
+	if !s.canResume(&onboard.Checkpoint{Guide: "kk", Step: 2}) {
+		t.Error("expected a checkpoint of an installed guide to resume")
+	}
+	if s.canResume(&onboard.Checkpoint{Guide: "removed", Step: 2}) {
+		t.Error("expected a checkpoint of a removed guide to start over")
+	}
+	if s.canResume(nil) {
+		t.Error("expected no checkpoint to start over")
+	}
+}
+
+func TestResumeFromCheckpoint(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	d := fakes.NewDiscord()
+	db := fakes.NewDB()
+	s := &OnboardingSession{
+		ctx:                context.Background(),
+		guildID:            "guild-1",
+		userID:             "user-1",
+		vcChannelID:        "vc-1",
+		Setsumeikai2RoleID: "role-2",
+		session:            d.Session(),
+		cache:              fakes.NewCache(),
+		db:                 db,
+		player:             &fakes.AudioPlayer{},
+		logger:             fakes.Logger{},
+		i18n:               fakes.I18n{},
+	}
+
+	checkpoint := &onboard.Checkpoint{Guide: "kk", Step: 4, Selections: onboard.Selections{"age": {"30late"}}}
+	if err := s.resumeFromCheckpoint(checkpoint); err != nil {
+		t.Fatalf("resume: %v", err)
+	}
+	if s.currentStep != 5 || s.selectedGuide != "kk" {
+		t.Errorf("expected step 5 of kk, got step %d of %q", s.currentStep, s.selectedGuide)
+	}
+	if !d.HasRole("guild-1", "user-1", "role-2") {
+		t.Error("expected step 2's role given again on resume")
+	}
+	if fmt.Sprint(s.Selections()) != fmt.Sprint(onboard.Selections{"age": {"30late"}}) {
+		t.Errorf("expected the selections restored, got %v", s.Selections())
+	}
+	if execs := db.Execs(); len(execs) != 1 || execs[0].Args[3] != 4 {
+		t.Errorf("expected the checkpoint kept at step 4, got %+v", execs)
+	}
+}
@@ -0,0 +1,113 @@
+package worker
+
+import (
+	"fmt"
+	"strings"
+
+	"welcomebot/internal/onboard"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// ReplayMode is what a step's replay offers.
+type ReplayMode string
+
+const (
+	// ReplayAudio replays the step's audio only.
+	ReplayAudio ReplayMode = "audio"
+	// ReplayFull also offers re-sending the step's text and images.
+	ReplayFull ReplayMode = "full"
+)
+
+// ReplayModes maps a step key ("step1" to "step7") to its replay mode.
+// Steps without an entry replay audio only.
+type ReplayModes map[string]ReplayMode
+
+// fullReplaySteps are the steps that can be re-sent in full. Step 3 is
+// interactive; re-sending it would repeat its role choices.
+var fullReplaySteps = map[string]bool{
+	"step1": true, "step2": true, "step4": true, "step5": true, "step6": true, "step7": true,
+}
+
+// ParseReplayModes parses a comma-separated list of step=mode entries,
+// e.g. "step2=full,step4=full".
+func ParseReplayModes(s string) (ReplayModes, error) {
+	modes := make(ReplayModes)
+	for _, entry := range strings.Split(s, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		step, mode, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid replay mode %q, want step=mode", entry)
+		}
+		step = strings.TrimSpace(step)
+		if !isStepKey(step) {
+			return nil, fmt.Errorf("unknown step %q", step)
+		}
+
+		switch m := ReplayMode(strings.TrimSpace(mode)); m {
+		case ReplayAudio:
+			modes[step] = m
+		case ReplayFull:
+			if !fullReplaySteps[step] {
+				return nil, fmt.Errorf("step %s cannot be replayed in full", step)
+			}
+			modes[step] = m
+		default:
+			return nil, fmt.Errorf("unknown replay mode %q", mode)
+		}
+	}
+	return modes, nil
+}
+
+// SetReplayModes replaces the session's per-step replay modes.
+// It must be called before Start.
+func (s *OnboardingSession) SetReplayModes(modes ReplayModes) {
+	s.replayModes = modes
+}
+
+// replayButtons returns the replay buttons for a step: audio replay, plus
+// full replay when the step is configured for it. Text-only sessions have
+// no audio to replay.
+func (s *OnboardingSession) replayButtons(step int) []discordgo.MessageComponent {
+	var buttons []discordgo.MessageComponent
+	if !s.textOnly {
+		buttons = append(buttons, discordgo.Button{
+			Label:    s.i18n.T(s.ctx, s.guildID, "onboarding.button_replay"),
+			Style:    s.ButtonStyle(ButtonReplay),
+			CustomID: fmt.Sprintf("onboarding:step%d_replay:%s", step, s.userID),
+		})
+	}
+	if s.replayModes[fmt.Sprintf("step%d", step)] == ReplayFull {
+		buttons = append(buttons, discordgo.Button{
+			Label:    s.i18n.T(s.ctx, s.guildID, "onboarding.button_replay_full"),
+			Style:    s.ButtonStyle(ButtonReplay),
+			CustomID: fmt.Sprintf("onboarding:step%d_replay_full:%s", step, s.userID),
+		})
+	}
+	return buttons
+}
+
+// ReplayStep re-sends the current step's text, images and audio. Role
+// changes the step makes on entry are not applied again.
+func (s *OnboardingSession) ReplayStep(step int) error {
+	if step != s.CurrentStep() {
+		return fmt.Errorf("step %d is not the current step", step)
+	}
+	flow := s.Flow()
+	n := flow.Index(step)
+	if n < 0 || !fullReplaySteps[flow[n].ID] {
+		return fmt.Errorf("step %d cannot be replayed in full", step)
+	}
+
+	s.StopCurrentAudio()
+	s.Record(onboard.TimelineAudio, "replay_full."+flow[n].ID)
+
+	s.replaying = true
+	defer func() { s.replaying = false }()
+
+	return s.enterStep(flow, n)
+}
@@ -0,0 +1,87 @@
+package worker
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+
+	"welcomebot/internal/fakes"
+)
+
+func TestParseReplayModes(t *testing.T) {
+	modes, err := ParseReplayModes("step2=full, step4=audio")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if modes["step2"] != ReplayFull || modes["step4"] != ReplayAudio {
+		t.Errorf("unexpected modes: %v", modes)
+	}
+
+	for _, bad := range []string{"step2", "step9=full", "step3=full", "step2=loud"} {
+		if _, err := ParseReplayModes(bad); err == nil {
+			t.Errorf("ParseReplayModes(%q) should fail", bad)
+		}
+	}
+}
+
+func TestReplayButtons(t *testing.T) {
+	s := &OnboardingSession{ctx: context.Background(), i18n: fakes.I18n{}, userID: "user-1",
+		replayModes: ReplayModes{"step2": ReplayFull}}
+
+	if got := s.replayButtons(1); len(got) != 1 {
+		t.Errorf("expected audio replay only for step 1, got %d buttons", len(got))
+	}
+	got := s.replayButtons(2)
+	if len(got) != 2 || got[1].(discordgo.Button).CustomID != "onboarding:step2_replay_full:user-1" {
+		t.Errorf("expected a full replay button for step 2, got %+v", got)
+	}
+}
+
+func TestReplayButtonsTextOnly(t *testing.T) {
+	s := &OnboardingSession{ctx: context.Background(), i18n: fakes.I18n{}, userID: "user-1", textOnly: true,
+		replayModes: ReplayModes{"step2": ReplayFull}}
+
+	if got := s.replayButtons(1); len(got) != 0 {
+		t.Errorf("expected no audio replay in a text-only session, got %+v", got)
+	}
+	got := s.replayButtons(2)
+	if len(got) != 1 || got[0].(discordgo.Button).CustomID != "onboarding:step2_replay_full:user-1" {
+		t.Errorf("expected only the full replay button for step 2, got %+v", got)
+	}
+}
+
+func TestReplayStepSkipsRoles(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	d := fakes.NewDiscord()
+	s := &OnboardingSession{
+		ctx:                context.Background(),
+		guildID:            "guild-1",
+		userID:             "user-1",
+		vcChannelID:        "vc-1",
+		selectedGuide:      "kk",
+		currentStep:        2,
+		Setsumeikai2RoleID: "role-2",
+		session:            d.Session(),
+		cache:              fakes.NewCache(),
+		db:                 fakes.NewDB(),
+		player:             &fakes.AudioPlayer{},
+		logger:             fakes.Logger{},
+		i18n:               fakes.I18n{},
+	}
+
+	if err := s.ReplayStep(2); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if d.HasRole("guild-1", "user-1", "role-2") {
+		t.Error("full replay must not add the step's role again")
+	}
+	if s.currentStep != 2 {
+		t.Errorf("expected to stay on step 2, got %d", s.currentStep)
+	}
+
+	if err := s.ReplayStep(3); err == nil {
+		t.Error("expected replaying a step other than the current one to fail")
+	}
+}
@@ -0,0 +1,62 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+
+	"welcomebot/internal/core/discord"
+	"welcomebot/internal/core/logger"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// ChangeRole grants (add) or revokes a member's role, retrying when
+// Discord rate limits the request. It is the one path every onboarding
+// role change takes: a change the member's cached roles show is already
+// made is skipped, and a role above the bot's highest role is refused
+// with an explanation instead of failing with a 403. It reports whether
+// the change was applied.
+func ChangeRole(ctx context.Context, session *discordgo.Session, roles *MemberRoles, log logger.Logger, guildID, userID, roleID string, add bool) (bool, error) {
+	if has, ok := roles.Has(guildID, userID, roleID); ok && has == add {
+		return false, nil
+	}
+	if err := CheckAssignable(session, log, guildID, roleID); err != nil {
+		return false, err
+	}
+
+	var err error
+	if add {
+		err = discord.Retry(ctx, log, "add role", discord.DefaultRetryPolicy, func() error {
+			return session.GuildMemberRoleAdd(guildID, userID, roleID)
+		})
+	} else {
+		err = discord.Retry(ctx, log, "remove role", discord.DefaultRetryPolicy, func() error {
+			return session.GuildMemberRoleRemove(guildID, userID, roleID)
+		})
+	}
+	if err != nil {
+		return false, err
+	}
+
+	roles.Set(guildID, userID, roleID, add)
+	return true, nil
+}
+
+// CheckAssignable refuses a role change the bot is known to lack the rank
+// for, so the failure is explained instead of surfacing as a 403. If the
+// hierarchy cannot be read the change is attempted anyway.
+func CheckAssignable(session *discordgo.Session, log logger.Logger, guildID, roleID string) error {
+	blocked, err := discord.UnmanageableRoles(session, guildID, session.State.User.ID, []string{roleID})
+	if err != nil || len(blocked) == 0 {
+		return nil
+	}
+
+	role := blocked[0]
+	log.Error("role is above the bot's highest role; move the bot's role above it",
+		"guild_id", guildID,
+		"role_id", role.ID,
+		"role_name", role.Name,
+		"managed", role.Managed,
+	)
+	return fmt.Errorf("role %s (%s) is not below the bot's highest role", role.Name, role.ID)
+}
@@ -0,0 +1,47 @@
+package worker
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"welcomebot/internal/fakes"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func TestSessionRoleChangesCheckHierarchy(t *testing.T) {
+	d := fakes.NewDiscord()
+	d.SetGuildRoles("guild-1",
+		&discordgo.Role{ID: "role-bot", Position: 5},
+		&discordgo.Role{ID: "role-progress", Position: 2},
+		&discordgo.Role{ID: "role-completed", Position: 7},
+	)
+	d.SetMemberRoles("guild-1", fakes.BotUserID, "role-bot")
+	d.SetMemberRoles("guild-1", "user-1", "role-progress")
+	s := &OnboardingSession{
+		guildID:          "guild-1",
+		userID:           "user-1",
+		session:          d.Session(),
+		logger:           fakes.Logger{},
+		ctx:              context.Background(),
+		inProgressRoleID: "role-progress",
+		completedRoleID:  "role-completed",
+	}
+
+	// The in-progress and completed roles take the same path as the
+	// roles granted from button handlers
+	if err := s.removeRole(s.inProgressRoleID); err != nil {
+		t.Fatalf("expected role below the bot to be removed: %v", err)
+	}
+	if err := s.addRole(s.completedRoleID); err == nil {
+		t.Error("expected role above the bot to be refused")
+	}
+
+	if n := d.Count(http.MethodPut, "guilds/guild-1/members/user-1/roles/role-completed"); n != 0 {
+		t.Errorf("expected no request for a role above the bot, got %d", n)
+	}
+	if d.HasRole("guild-1", "user-1", "role-progress") {
+		t.Errorf("unexpected roles: %v", d.MemberRoles("guild-1", "user-1"))
+	}
+}
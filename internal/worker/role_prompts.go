@@ -0,0 +1,46 @@
+package worker
+
+import "welcomebot/internal/onboard"
+
+// step3Roles returns the roles a step 3 prompt can give. A guild that
+// configured only some role groups leaves the others empty in the payload.
+func (s *OnboardingSession) step3Roles(step string) []string {
+	switch step {
+	case "step3.gender":
+		return []string{s.MaleRoleID, s.FemaleRoleID}
+	case "step3.age":
+		return []string{s.Age20EarlyRoleID, s.Age20LateRoleID, s.Age30EarlyRoleID,
+			s.Age30LateRoleID, s.Age40EarlyRoleID, s.Age40LateRoleID}
+	case "step3.voice":
+		return []string{s.HighVoiceRoleID, s.MidHighVoiceRoleID, s.MidVoiceRoleID,
+			s.MidLowVoiceRoleID, s.LowVoiceRoleID}
+	case "step3.eroipu":
+		return []string{s.EroOkRoleID, s.EroNgRoleID}
+	case "step3.neochi":
+		return []string{s.NeochiOkRoleID, s.NeochiNgRoleID}
+	case "step3.neochi_handling":
+		// Choosing the room gives no role; only disconnect does
+		return []string{s.NeochiDisconnectRoleID}
+	case "step3.dm":
+		return []string{s.DmOkRoleID, s.DmNgRoleID}
+	case "step3.friend":
+		return []string{s.FriendOkRoleID, s.FriendNgRoleID}
+	case "step3.event":
+		return []string{s.BunnyclubEventRoleID, s.UserEventRoleID}
+	}
+	return nil
+}
+
+// promptConfigured reports whether a step 3 prompt has any role to give.
+// Prompts whose roles are all unset are skipped rather than letting the
+// member pick something that assigns nothing.
+func (s *OnboardingSession) promptConfigured(step string) bool {
+	for _, roleID := range s.step3Roles(step) {
+		if roleID != "" {
+			return true
+		}
+	}
+	s.Record(onboard.TimelineStep, step+" skipped")
+	s.logger.Debug("step hidden, no roles configured", "step", step, "user_id", s.userID)
+	return false
+}
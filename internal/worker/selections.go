@@ -0,0 +1,41 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"welcomebot/internal/onboard"
+)
+
+// Selections returns the choices the member has made so far. Prompts they
+// skipped or never saw are left out.
+func (s *OnboardingSession) Selections() onboard.Selections {
+	selections := make(onboard.Selections)
+	for _, name := range onboard.SelectionNames() {
+		if values := s.selected(name); len(values) > 0 {
+			selections[name] = values
+		}
+	}
+	return selections
+}
+
+// saveSelections records the member's choices in
+// member_onboarding_selections, replacing those of an earlier completion.
+func (s *OnboardingSession) saveSelections(ctx context.Context) error {
+	selections, err := json.Marshal(s.Selections())
+	if err != nil {
+		return fmt.Errorf("marshal selections: %w", err)
+	}
+
+	query := `
+		INSERT INTO member_onboarding_selections (guild_id, user_id, guide, selections, completed_at)
+		VALUES ($1, $2, $3, $4, NOW())
+		ON CONFLICT (guild_id, user_id)
+		DO UPDATE SET guide = $3, selections = $4, completed_at = NOW()
+	`
+	if _, err := s.db.Exec(ctx, query, s.guildID, s.userID, s.selectedGuide, string(selections)); err != nil {
+		return fmt.Errorf("save selections: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,39 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"welcomebot/internal/fakes"
+	"welcomebot/internal/onboard"
+)
+
+func TestSaveSelections(t *testing.T) {
+	db := fakes.NewDB()
+	s := &OnboardingSession{guildID: "guild-1", userID: "user-1", selectedGuide: "kk", db: db}
+	s.RecordSelection("gender", "female")
+	s.RecordSelection("age", "30late")
+	s.ToggleEvent("user")
+
+	if err := s.saveSelections(context.Background()); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+
+	execs := db.Execs()
+	if len(execs) != 1 {
+		t.Fatalf("expected one insert, got %d", len(execs))
+	}
+	if execs[0].Args[2] != "kk" {
+		t.Errorf("guide = %v, want kk", execs[0].Args[2])
+	}
+	var saved onboard.Selections
+	if err := json.Unmarshal([]byte(execs[0].Args[3].(string)), &saved); err != nil {
+		t.Fatalf("decode selections: %v", err)
+	}
+	want := onboard.Selections{"gender": {"female"}, "age": {"30late"}, "event": {"user"}}
+	if fmt.Sprint(saved) != fmt.Sprint(want) {
+		t.Errorf("got %v, want %v", saved, want)
+	}
+}
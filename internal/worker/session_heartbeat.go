@@ -0,0 +1,44 @@
+package worker
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"welcomebot/internal/onboard"
+)
+
+// beat writes the session's heartbeat. The key outlives a few missed beats
+// so master sees a crashed worker's last beat rather than no key at all,
+// which it reads as a session that has not started yet.
+func (s *OnboardingSession) beat(now time.Time) {
+	value := strconv.FormatInt(now.Unix(), 10)
+	if err := s.cache.Set(context.Background(), onboard.SessionHeartbeatKey(s.guildID, s.userID), value, sessionTimeout); err != nil {
+		s.logger.Warn("failed to write session heartbeat", "error", err, "user_id", s.userID)
+	}
+}
+
+// sendHeartbeats beats every SessionHeartbeatInterval until the session
+// ends.
+func (s *OnboardingSession) sendHeartbeats() {
+	s.beat(time.Now())
+
+	ticker := time.NewTicker(onboard.SessionHeartbeatInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case now := <-ticker.C:
+			s.beat(now)
+		}
+	}
+}
+
+// clearHeartbeat removes the heartbeat once the session has ended cleanly.
+func (s *OnboardingSession) clearHeartbeat() {
+	if err := s.cache.Delete(context.Background(), onboard.SessionHeartbeatKey(s.guildID, s.userID)); err != nil {
+		s.logger.Warn("failed to delete session heartbeat", "error", err)
+	}
+}
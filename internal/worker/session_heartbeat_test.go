@@ -0,0 +1,30 @@
+package worker
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"welcomebot/internal/fakes"
+	"welcomebot/internal/onboard"
+)
+
+func TestSessionBeat(t *testing.T) {
+	cache := fakes.NewCache()
+	s := &OnboardingSession{guildID: "guild-1", userID: "user-1", cache: cache, logger: fakes.Logger{}}
+	now := time.Unix(1700000000, 0)
+
+	s.beat(now)
+	value, err := cache.Get(context.Background(), onboard.SessionHeartbeatKey("guild-1", "user-1"))
+	if err != nil {
+		t.Fatalf("expected a heartbeat, got %v", err)
+	}
+	if last, err := onboard.ParseSessionHeartbeat(value); err != nil || !last.Equal(now) {
+		t.Errorf("heartbeat = %v, %v; want %v", last, err, now)
+	}
+
+	s.clearHeartbeat()
+	if exists, _ := cache.Exists(context.Background(), onboard.SessionHeartbeatKey("guild-1", "user-1")); exists {
+		t.Error("expected the heartbeat to be cleared")
+	}
+}
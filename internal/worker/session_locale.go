@@ -0,0 +1,125 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"welcomebot/internal/core/i18n"
+	"welcomebot/internal/onboard"
+	"welcomebot/internal/shared"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// ErrLocaleChosen is returned by ChooseLocale once the member has picked a
+// language, so a second click can't switch languages mid-session.
+var ErrLocaleChosen = errors.New("session language already chosen")
+
+// sessionI18n translates in the language the member picked for the
+// session, or the guild's until they have.
+type sessionI18n struct {
+	i18n.I18n
+	session *OnboardingSession
+}
+
+func (t sessionI18n) T(ctx context.Context, guildID, key string) string {
+	return t.I18n.T(t.session.LocaleContext(ctx), guildID, key)
+}
+
+func (t sessionI18n) TWithArgs(ctx context.Context, guildID, key string, args map[string]string) string {
+	return t.I18n.TWithArgs(t.session.LocaleContext(ctx), guildID, key, args)
+}
+
+func (t sessionI18n) GetGuildLanguage(ctx context.Context, guildID string) (string, error) {
+	return t.I18n.GetGuildLanguage(t.session.LocaleContext(ctx), guildID)
+}
+
+// Locale returns the language the member picked, or "" if they haven't.
+func (s *OnboardingSession) Locale() string {
+	s.localeMu.Lock()
+	defer s.localeMu.Unlock()
+
+	return s.locale
+}
+
+// LocaleContext returns ctx set to translate in the member's language, for
+// handlers answering the member with the worker's own translator.
+func (s *OnboardingSession) LocaleContext(ctx context.Context) context.Context {
+	if locale := s.Locale(); locale != "" {
+		return i18n.WithLocale(ctx, locale)
+	}
+	return ctx
+}
+
+// NeedsLocale reports whether the member still has to pick a language.
+func (s *OnboardingSession) NeedsLocale() bool {
+	return len(s.locales) > 1 && s.Locale() == ""
+}
+
+// ChooseLocale sets the session's language and carries on with the
+// greeting and guide selection, or the resumed step, in it.
+func (s *OnboardingSession) ChooseLocale(locale string) error {
+	if !slices.Contains(s.locales, locale) {
+		return fmt.Errorf("locale %q is not offered", locale)
+	}
+
+	s.localeMu.Lock()
+	if s.locale != "" {
+		s.localeMu.Unlock()
+		return ErrLocaleChosen
+	}
+	s.locale = locale
+	s.localeMu.Unlock()
+
+	// Spoken steps follow the language too
+	if player, ok := s.player.(interface{ SetLocale(string) }); ok {
+		player.SetLocale(locale)
+	}
+	s.UpdateActivity()
+	s.Record(onboard.TimelineClick, "locale:"+locale)
+	s.logger.Info("session language chosen", "locale", locale)
+
+	s.begin()
+	return nil
+}
+
+// sendLanguageMessage asks the member to pick a language, with a button
+// per offered locale labelled in that language.
+func (s *OnboardingSession) sendLanguageMessage() error {
+	ctx := context.Background()
+
+	var buttons []discordgo.MessageComponent
+	for _, locale := range s.locales {
+		buttons = append(buttons, discordgo.Button{
+			Label:    s.i18n.T(i18n.WithLocale(ctx, locale), s.guildID, "onboarding.language_name"),
+			Style:    s.ButtonStyle(ButtonChoice),
+			Emoji:    shared.ComponentEmoji(shared.EmojiLanguage),
+			CustomID: fmt.Sprintf("onboarding:language:%s:%s", locale, s.userID),
+		})
+	}
+
+	// Discord allows five buttons per row
+	var rows []discordgo.MessageComponent
+	for chunk := range slices.Chunk(buttons, 5) {
+		rows = append(rows, discordgo.ActionsRow{Components: chunk})
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Title: s.i18n.T(ctx, s.guildID, "onboarding.choose_language_title"),
+		Description: s.i18n.TWithArgs(ctx, s.guildID, "onboarding.choose_language_description", map[string]string{
+			"user": fmt.Sprintf("<@%s>", s.userID),
+		}),
+		Color: int(shared.ColorInfo),
+	}
+
+	_, err := s.sendMessage(&discordgo.MessageSend{
+		Embeds:     []*discordgo.MessageEmbed{embed},
+		Components: rows,
+	})
+	if err != nil {
+		return fmt.Errorf("send message: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"welcomebot/internal/fakes"
+)
+
+func TestChooseLocale(t *testing.T) {
+	t.Chdir(t.TempDir())
+	ctx := context.Background()
+	d := fakes.NewDiscord()
+	s, _ := newCleanupSession(t, d, &fakes.AudioPlayer{})
+	s.ctx = ctx
+	s.locales = []string{"en", "ja"}
+	s.i18n = sessionI18n{I18n: fakes.I18n{}, session: s}
+
+	if (&OnboardingSession{locales: []string{"ja"}}).NeedsLocale() {
+		t.Error("expected a single locale to skip the step")
+	}
+	if !s.NeedsLocale() {
+		t.Fatal("expected the member to pick a language first")
+	}
+
+	if err := s.sendLanguageMessage(); err != nil {
+		t.Fatalf("send language message: %v", err)
+	}
+	var buttons int
+	for _, r := range d.Requests() {
+		if r.Method == http.MethodPost && r.Path == "channels/vc-1/messages" {
+			buttons += strings.Count(string(r.Body), "onboarding:language:")
+		}
+	}
+	if buttons != 2 {
+		t.Errorf("expected a button per locale, got %d", buttons)
+	}
+
+	if err := s.ChooseLocale("fr"); err == nil {
+		t.Error("expected a locale that isn't offered to be refused")
+	}
+	if err := s.ChooseLocale("ja"); err != nil {
+		t.Fatalf("choose locale: %v", err)
+	}
+	if s.NeedsLocale() {
+		t.Error("expected the language step to be done")
+	}
+	if lang, _ := s.i18n.GetGuildLanguage(ctx, s.guildID); lang != "ja" {
+		t.Errorf("expected the session to use ja, got %q", lang)
+	}
+	if !errors.Is(s.ChooseLocale("en"), ErrLocaleChosen) {
+		t.Error("expected a second choice to be refused")
+	}
+	if s.Locale() != "ja" {
+		t.Errorf("expected ja to stay chosen, got %q", s.Locale())
+	}
+}
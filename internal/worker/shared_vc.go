@@ -0,0 +1,21 @@
+package worker
+
+import "context"
+
+// silentPlayer stands in for voice playback in a shared onboarding channel.
+// Several members, each with their own session, can share that channel, and
+// one bot per guild cannot stream a separate recording to each of them, so
+// shared sessions run the text portions only.
+type silentPlayer struct{}
+
+func (silentPlayer) Connect(ctx context.Context, guildID, channelID string) error { return nil }
+func (silentPlayer) Play(guide, filename string) error                            { return nil }
+func (silentPlayer) Stop()                                                        {}
+func (silentPlayer) Replay() error                                                { return nil }
+func (silentPlayer) Pause()                                                       {}
+func (silentPlayer) Resume()                                                      {}
+func (silentPlayer) Playing() bool                                                { return false }
+func (silentPlayer) Disconnect(ctx context.Context) error                         { return nil }
+
+// CanPlay reports false so guide previews are offered as unavailable.
+func (silentPlayer) CanPlay(guide, filename string) bool { return false }
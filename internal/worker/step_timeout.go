@@ -0,0 +1,196 @@
+package worker
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"welcomebot/internal/onboard"
+)
+
+// StepTimeoutAction is what happens when a step sits idle too long.
+type StepTimeoutAction string
+
+const (
+	// StepTimeoutNudge posts a reminder to press the step's button.
+	StepTimeoutNudge StepTimeoutAction = "nudge"
+	// StepTimeoutAdvance moves on as if the user had pressed next.
+	StepTimeoutAdvance StepTimeoutAction = "advance"
+)
+
+// StepTimeout configures one step's idle timeout.
+type StepTimeout struct {
+	After  time.Duration
+	Action StepTimeoutAction
+}
+
+// StepTimeouts maps a step key ("guide" for guide selection, "step1" to
+// "step7") to its timeout. Steps without an entry never time out.
+type StepTimeouts map[string]StepTimeout
+
+// advanceableSteps are the informational steps a timeout may skip past.
+// The others need the user's own choices.
+var advanceableSteps = map[string]bool{
+	"step1": true, "step2": true, "step4": true, "step5": true, "step6": true,
+}
+
+// ParseStepTimeouts parses a comma-separated list of step=action:duration
+// entries, e.g. "step1=advance:3m,step3=nudge:5m".
+func ParseStepTimeouts(s string) (StepTimeouts, error) {
+	timeouts := make(StepTimeouts)
+	for _, entry := range strings.Split(s, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		step, rule, ok := strings.Cut(entry, "=")
+		action, after, ok2 := strings.Cut(rule, ":")
+		if !ok || !ok2 {
+			return nil, fmt.Errorf("invalid step timeout %q, want step=action:duration", entry)
+		}
+		step = strings.TrimSpace(step)
+		if step != "guide" && !isStepKey(step) {
+			return nil, fmt.Errorf("unknown step %q", step)
+		}
+
+		timeout := StepTimeout{Action: StepTimeoutAction(strings.TrimSpace(action))}
+		switch timeout.Action {
+		case StepTimeoutNudge:
+		case StepTimeoutAdvance:
+			if !advanceableSteps[step] {
+				return nil, fmt.Errorf("step %s cannot be advanced automatically", step)
+			}
+		default:
+			return nil, fmt.Errorf("unknown step timeout action %q", action)
+		}
+
+		duration, err := time.ParseDuration(strings.TrimSpace(after))
+		if err != nil || duration <= 0 {
+			return nil, fmt.Errorf("invalid duration for %s: %q", step, after)
+		}
+		timeout.After = duration
+
+		timeouts[step] = timeout
+	}
+	return timeouts, nil
+}
+
+func isStepKey(key string) bool {
+	for n := 1; n <= 7; n++ {
+		if key == fmt.Sprintf("step%d", n) {
+			return true
+		}
+	}
+	return false
+}
+
+// SetStepTimeouts replaces the session's per-step timeouts.
+// It must be called before Start.
+func (s *OnboardingSession) SetStepTimeouts(timeouts StepTimeouts) {
+	s.stepTimeouts = timeouts
+}
+
+// CurrentStep returns the tutorial step the user is on; 0 is guide selection.
+func (s *OnboardingSession) CurrentStep() int {
+	s.stepMu.Lock()
+	defer s.stepMu.Unlock()
+
+	return s.currentStep
+}
+
+// LeaveStep claims moving on from step. It reports true to exactly one
+// caller while the member is on step, so a step timeout, a double click
+// and the next button can't each advance them.
+func (s *OnboardingSession) LeaveStep(step int) bool {
+	s.stepMu.Lock()
+	defer s.stepMu.Unlock()
+
+	if s.currentStep != step || s.leftStep == step {
+		return false
+	}
+	s.leftStep = step
+	return true
+}
+
+// stepKey returns the StepTimeouts key for the current step.
+func (s *OnboardingSession) stepKey() string {
+	step := s.CurrentStep()
+	if step == 0 {
+		return "guide"
+	}
+	return fmt.Sprintf("step%d", step)
+}
+
+// checkStepTimeout reports whether the current step has been idle past its
+// timeout. It fires once per idle period, so any interaction re-arms it.
+func (s *OnboardingSession) checkStepTimeout(now time.Time) (StepTimeout, bool) {
+	lastActivity := s.lastActive()
+	timeout, ok := s.stepTimeouts[s.stepKey()]
+	if !ok || s.stepTimedOut.Equal(lastActivity) {
+		return StepTimeout{}, false
+	}
+	if now.Sub(lastActivity) < timeout.After {
+		return StepTimeout{}, false
+	}
+
+	s.stepTimedOut = lastActivity
+	return timeout, true
+}
+
+// handleStepTimeout nudges the user or advances the idle step.
+func (s *OnboardingSession) handleStepTimeout(timeout StepTimeout) {
+	step := s.CurrentStep()
+
+	// The member may have pressed next since the timeout fired
+	if timeout.Action == StepTimeoutAdvance && !s.LeaveStep(step) {
+		return
+	}
+	s.Record(onboard.TimelineStep, fmt.Sprintf("%s.timeout.%s", s.stepKey(), timeout.Action))
+
+	if timeout.Action == StepTimeoutAdvance {
+		s.logger.Info("step idle, advancing", "user_id", s.userID, "step", step)
+		if _, err := s.session.ChannelMessageSend(s.vcChannelID, s.i18n.T(s.ctx, s.guildID, "onboarding.step_auto_advanced")); err != nil {
+			s.logger.Warn("failed to send auto-advance notice", "error", err)
+		}
+		if err := s.advanceStep(step); err != nil {
+			s.Fail(fmt.Sprintf("step%d_timeout", step), err)
+		}
+		return
+	}
+
+	s.logger.Info("step idle, nudging user", "user_id", s.userID, "step", step)
+	if _, err := s.session.ChannelMessageSend(s.vcChannelID, s.i18n.T(s.ctx, s.guildID, "onboarding.step_nudge")); err != nil {
+		s.logger.Warn("failed to send step nudge", "error", err)
+	}
+}
+
+// advanceStep does what the step's next button does. Only message steps
+// have one; step 3 ends with its summary and step 7 completes onboarding.
+func (s *OnboardingSession) advanceStep(step int) error {
+	flow := s.Flow()
+	if n := flow.Index(step); n < 0 || flow[n].Type != StepMessage {
+		return fmt.Errorf("step %d cannot be advanced automatically", step)
+	}
+
+	s.StopCurrentAudio()
+	return s.Advance(step)
+}
+
+// hasRole reports whether the user currently has roleID.
+func (s *OnboardingSession) hasRole(roleID string) bool {
+	if roleID == "" {
+		return false
+	}
+	member, err := s.session.GuildMember(s.guildID, s.userID)
+	if err != nil {
+		s.logger.Warn("failed to get member roles", "error", err)
+		return false
+	}
+	for _, id := range member.Roles {
+		if id == roleID {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,100 @@
+package worker
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseStepTimeouts(t *testing.T) {
+	timeouts, err := ParseStepTimeouts("step1=advance:3m, step3=nudge:90s,guide=nudge:5m")
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	want := StepTimeouts{
+		"step1": {After: 3 * time.Minute, Action: StepTimeoutAdvance},
+		"step3": {After: 90 * time.Second, Action: StepTimeoutNudge},
+		"guide": {After: 5 * time.Minute, Action: StepTimeoutNudge},
+	}
+	if len(timeouts) != len(want) {
+		t.Fatalf("got %v, want %v", timeouts, want)
+	}
+	for key, timeout := range want {
+		if timeouts[key] != timeout {
+			t.Errorf("%s: got %+v, want %+v", key, timeouts[key], timeout)
+		}
+	}
+
+	if timeouts, err := ParseStepTimeouts(""); err != nil || len(timeouts) != 0 {
+		t.Errorf("empty: got %v, %v; want no timeouts", timeouts, err)
+	}
+
+	for _, bad := range []string{
+		"step1",
+		"step1=advance",
+		"step9=nudge:1m",
+		"step1=skip:1m",
+		"step1=nudge:soon",
+		"step1=nudge:0s",
+		"step3=advance:1m", // role choices can't be skipped
+		"step7=advance:1m",
+	} {
+		if _, err := ParseStepTimeouts(bad); err == nil {
+			t.Errorf("ParseStepTimeouts(%q) should fail", bad)
+		}
+	}
+}
+
+func TestCheckStepTimeout(t *testing.T) {
+	start := time.Now()
+	s := &OnboardingSession{
+		currentStep:  1,
+		lastActivity: start,
+		stepTimeouts: StepTimeouts{"step1": {After: 2 * time.Minute, Action: StepTimeoutNudge}},
+	}
+
+	if _, ok := s.checkStepTimeout(start.Add(time.Minute)); ok {
+		t.Error("expected no timeout before the limit")
+	}
+	if timeout, ok := s.checkStepTimeout(start.Add(3 * time.Minute)); !ok || timeout.Action != StepTimeoutNudge {
+		t.Errorf("expected a nudge after the limit, got %+v, %v", timeout, ok)
+	}
+	if _, ok := s.checkStepTimeout(start.Add(4 * time.Minute)); ok {
+		t.Error("expected one timeout per idle period")
+	}
+
+	// Interacting re-arms the timeout
+	s.lastActivity = start.Add(5 * time.Minute)
+	if _, ok := s.checkStepTimeout(s.lastActivity.Add(3 * time.Minute)); !ok {
+		t.Error("expected the timeout to fire again after fresh activity")
+	}
+
+	// Steps without a timeout never fire
+	s.currentStep = 2
+	s.lastActivity = start
+	if _, ok := s.checkStepTimeout(start.Add(time.Hour)); ok {
+		t.Error("expected no timeout for an unconfigured step")
+	}
+}
+
+func TestLeaveStep(t *testing.T) {
+	s := &OnboardingSession{currentStep: 2}
+
+	if s.LeaveStep(1) {
+		t.Error("expected a step the member isn't on refused")
+	}
+
+	// A timeout and a click racing to leave step 2: exactly one wins
+	wins := make(chan bool, 2)
+	for range 2 {
+		go func() { wins <- s.LeaveStep(2) }()
+	}
+	if first, second := <-wins, <-wins; first == second {
+		t.Errorf("expected exactly one claim, got %v and %v", first, second)
+	}
+
+	// Entering the next step can be left again
+	s.enterStep(Flow{{ID: "step3", Number: 3, show: func(*OnboardingSession, StepDef) error { return nil }}}, 0)
+	if !s.LeaveStep(3) {
+		t.Error("expected the new step claimable")
+	}
+}
@@ -0,0 +1,100 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"welcomebot/internal/onboard"
+)
+
+// maxTimelineEvents bounds a timeline's memory; later events are counted
+// but not kept.
+const maxTimelineEvents = 500
+
+// Timeline is an append-only trace of what happened in one session,
+// flushed to the session_timelines table when the session ends.
+type Timeline struct {
+	mu      sync.Mutex
+	events  []onboard.TimelineEvent
+	dropped int
+}
+
+// Add appends an event.
+func (t *Timeline) Add(kind, detail string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if len(t.events) >= maxTimelineEvents {
+		t.dropped++
+		return
+	}
+	t.events = append(t.events, onboard.TimelineEvent{At: time.Now(), Kind: kind, Detail: detail})
+}
+
+// Events returns a copy of the recorded events. If any were dropped, a
+// final error event says how many.
+func (t *Timeline) Events() []onboard.TimelineEvent {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	events := make([]onboard.TimelineEvent, len(t.events), len(t.events)+1)
+	copy(events, t.events)
+	if t.dropped > 0 {
+		events = append(events, onboard.TimelineEvent{
+			At:     time.Now(),
+			Kind:   onboard.TimelineError,
+			Detail: fmt.Sprintf("%d events dropped", t.dropped),
+		})
+	}
+	return events
+}
+
+// Record adds an event to the session's timeline.
+func (s *OnboardingSession) Record(kind, detail string) {
+	s.timeline.Add(kind, detail)
+}
+
+// setOutcome records how the session ended. The first outcome wins, so a
+// failure is not overwritten by the cancellation that follows it.
+func (s *OnboardingSession) setOutcome(outcome string) {
+	s.outcomeOnce.Do(func() {
+		s.outcome = outcome
+	})
+}
+
+// Outcome returns how the session ended, or "" while it is running.
+func (s *OnboardingSession) Outcome() string {
+	return s.outcome
+}
+
+// saveTimeline writes the session's timeline to the database.
+func (s *OnboardingSession) saveTimeline(ctx context.Context) error {
+	s.setOutcome(onboard.OutcomeEnded)
+
+	events, err := json.Marshal(s.timeline.Events())
+	if err != nil {
+		return fmt.Errorf("marshal timeline: %w", err)
+	}
+
+	query := `
+		INSERT INTO session_timelines (
+			guild_id, user_id, slave_id, guide, outcome, started_at, ended_at, events
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, NOW(), $7)
+	`
+	if _, err := s.db.Exec(ctx, query,
+		s.guildID,
+		s.userID,
+		s.slaveID,
+		s.selectedGuide,
+		s.outcome,
+		s.startedAt,
+		string(events),
+	); err != nil {
+		return fmt.Errorf("save timeline: %w", err)
+	}
+	return nil
+}
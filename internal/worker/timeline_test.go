@@ -0,0 +1,32 @@
+package worker
+
+import (
+	"testing"
+
+	"welcomebot/internal/onboard"
+)
+
+func TestTimelineDropsEventsBeyondLimit(t *testing.T) {
+	var timeline Timeline
+	for n := 0; n < maxTimelineEvents+3; n++ {
+		timeline.Add(onboard.TimelineClick, "button")
+	}
+
+	events := timeline.Events()
+	if len(events) != maxTimelineEvents+1 {
+		t.Fatalf("expected %d events, got %d", maxTimelineEvents+1, len(events))
+	}
+	if last := events[len(events)-1]; last.Kind != onboard.TimelineError || last.Detail != "3 events dropped" {
+		t.Errorf("unexpected final event %+v", last)
+	}
+}
+
+func TestSessionOutcomeFirstWins(t *testing.T) {
+	s := &OnboardingSession{}
+	s.setOutcome(onboard.OutcomeFailed)
+	s.setOutcome(onboard.OutcomeCompleted)
+
+	if s.outcome != onboard.OutcomeFailed {
+		t.Errorf("expected first outcome to be kept, got %q", s.outcome)
+	}
+}
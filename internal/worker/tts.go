@@ -0,0 +1,141 @@
+package worker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/jonas747/dca"
+)
+
+// TTSProvider turns text into speech. The returned audio may be in any
+// format ffmpeg can decode; it is converted to DCA before playback.
+type TTSProvider interface {
+	Synthesize(ctx context.Context, locale, text string) ([]byte, error)
+}
+
+// TTS synthesizes step audio and caches the encoded DCA per locale and step.
+// One TTS is shared by all sessions on a worker.
+type TTS struct {
+	provider TTSProvider
+
+	mu    sync.Mutex
+	cache map[string][]byte // locale:textKey -> DCA
+}
+
+// NewTTS creates a TTS backed by provider.
+func NewTTS(provider TTSProvider) *TTS {
+	return &TTS{
+		provider: provider,
+		cache:    make(map[string][]byte),
+	}
+}
+
+// Speech returns DCA audio for text, synthesizing it on first use.
+// textKey identifies the text for caching (e.g. "onboarding.step1_description").
+func (t *TTS) Speech(ctx context.Context, locale, textKey, text string) ([]byte, error) {
+	cacheKey := locale + ":" + textKey
+
+	t.mu.Lock()
+	data, ok := t.cache[cacheKey]
+	t.mu.Unlock()
+	if ok {
+		return data, nil
+	}
+
+	audio, err := t.provider.Synthesize(ctx, locale, text)
+	if err != nil {
+		return nil, fmt.Errorf("synthesize: %w", err)
+	}
+
+	data, err = encodeDCA(audio)
+	if err != nil {
+		return nil, err
+	}
+
+	t.mu.Lock()
+	t.cache[cacheKey] = data
+	t.mu.Unlock()
+
+	return data, nil
+}
+
+// encodeDCA converts audio to DCA using ffmpeg. Replaced in tests.
+var encodeDCA = func(audio []byte) ([]byte, error) {
+	session, err := dca.EncodeMem(bytes.NewReader(audio), dca.StdEncodeOptions)
+	if err != nil {
+		return nil, fmt.Errorf("start dca encoder: %w", err)
+	}
+	defer session.Cleanup()
+
+	data, err := io.ReadAll(session)
+	if err != nil {
+		return nil, fmt.Errorf("encode dca: %w", err)
+	}
+	if err := session.Error(); err != nil {
+		return nil, fmt.Errorf("encode dca: %w", err)
+	}
+	if len(data) == 0 {
+		return nil, fmt.Errorf("encode dca: no audio produced")
+	}
+
+	return data, nil
+}
+
+// HTTPTTSProvider posts {"text": ..., "locale": ...} as JSON to URL and
+// treats the response body as audio.
+type HTTPTTSProvider struct {
+	URL    string
+	APIKey string // Sent as a bearer token when set
+	Client *http.Client
+}
+
+// NewHTTPTTSProvider creates a provider for a TTS HTTP endpoint.
+func NewHTTPTTSProvider(url, apiKey string) *HTTPTTSProvider {
+	return &HTTPTTSProvider{
+		URL:    url,
+		APIKey: apiKey,
+		Client: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// Synthesize requests speech for text.
+func (p *HTTPTTSProvider) Synthesize(ctx context.Context, locale, text string) ([]byte, error) {
+	body, err := json.Marshal(map[string]string{
+		"text":   text,
+		"locale": locale,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.URL, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if p.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+p.APIKey)
+	}
+
+	resp, err := p.Client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request speech: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("tts provider returned %s", resp.Status)
+	}
+
+	audio, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read speech: %w", err)
+	}
+	return audio, nil
+}
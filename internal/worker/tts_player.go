@@ -0,0 +1,259 @@
+package worker
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"welcomebot/internal/audio"
+	"welcomebot/internal/core/i18n"
+	"welcomebot/internal/core/logger"
+	"welcomebot/internal/onboard"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// TTSPlayer voices the onboarding for guides without recordings by speaking
+// each step's localized text. Recorded files are still played when present.
+// If synthesis fails the step continues without audio (text-only mode).
+type TTSPlayer struct {
+	session *discordgo.Session
+	logger  logger.Logger
+	i18n    i18n.I18n
+	tts     *TTS
+
+	mu          sync.Mutex
+	dca         *audio.DCAPlayer // Created on Connect
+	ctx         context.Context
+	guildID     string
+	guide       string            // Last requested guide, for Replay
+	filename    string            // Last requested file, for Replay
+	transitions audio.Transitions // Passed on to the DCA player
+	readiness   audio.Readiness   // Passed on to the DCA player
+	limits      audio.Limits      // Passed on to the DCA player
+	locale      string            // Language picked for the session, if any
+}
+
+// NewTTSPlayer creates a TTS-backed player for one session.
+func NewTTSPlayer(session *discordgo.Session, log logger.Logger, i18nClient i18n.I18n, tts *TTS) *TTSPlayer {
+	return &TTSPlayer{
+		session: session,
+		logger:  log,
+		i18n:    i18nClient,
+		tts:     tts,
+	}
+}
+
+// Connect joins the voice channel.
+func (p *TTSPlayer) Connect(ctx context.Context, guildID, channelID string) error {
+	player := audio.NewDCAPlayer(ctx, p.session, p.logger)
+
+	p.mu.Lock()
+	player.SetTransitions(p.transitions)
+	player.SetReadiness(p.readiness)
+	player.SetLimits(p.limits)
+	p.dca = player
+	p.ctx = ctx
+	p.guildID = guildID
+	p.mu.Unlock()
+
+	return player.Connect(ctx, guildID, channelID)
+}
+
+// SetTransitions replaces the fades and gap applied to recorded and spoken
+// clips alike.
+func (p *TTSPlayer) SetTransitions(t audio.Transitions) {
+	p.mu.Lock()
+	p.transitions = t
+	player := p.dca
+	p.mu.Unlock()
+
+	if player != nil {
+		player.SetTransitions(t)
+	}
+}
+
+// SetLimits caps how long recorded and spoken clips may play.
+func (p *TTSPlayer) SetLimits(l audio.Limits) {
+	p.mu.Lock()
+	p.limits = l
+	player := p.dca
+	p.mu.Unlock()
+
+	if player != nil {
+		player.SetLimits(l)
+	}
+}
+
+// SetReadiness changes how long Connect waits for the voice connection.
+func (p *TTSPlayer) SetReadiness(r audio.Readiness) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.readiness = r
+}
+
+// SetLocale speaks steps in locale rather than the guild's language.
+func (p *TTSPlayer) SetLocale(locale string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.locale = locale
+}
+
+// speechContext returns the context steps are spoken in, set to the
+// session's language when one was picked. p.mu must be held.
+func (p *TTSPlayer) speechContext() context.Context {
+	ctx := p.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if p.locale != "" {
+		ctx = i18n.WithLocale(ctx, p.locale)
+	}
+	return ctx
+}
+
+// CanPlay reports whether Play would produce audio for the file: either it
+// is recorded, or its step has text to speak.
+func (p *TTSPlayer) CanPlay(guide, filename string) bool {
+	if audio.Exists(guide, filename) {
+		return true
+	}
+
+	p.mu.Lock()
+	ctx, guildID := p.speechContext(), p.guildID
+	p.mu.Unlock()
+
+	_, text := p.stepText(ctx, guildID, guide, filename)
+	return text != ""
+}
+
+// Play plays the recorded file if it exists, otherwise speaks the text of
+// the step it belongs to.
+func (p *TTSPlayer) Play(guide, filename string) error {
+	p.mu.Lock()
+	player, ctx, guildID := p.dca, p.speechContext(), p.guildID
+	p.guide, p.filename = guide, filename
+	p.mu.Unlock()
+
+	if player == nil {
+		return fmt.Errorf("voice connection not ready")
+	}
+
+	if audio.Exists(guide, filename) {
+		return player.Play(guide, filename)
+	}
+
+	textKey, text := p.stepText(ctx, guildID, guide, filename)
+	if text == "" {
+		p.logger.Info("no text to speak, continuing without audio", "guide", guide, "file", filename)
+		return nil
+	}
+
+	locale, _ := p.i18n.GetGuildLanguage(ctx, guildID)
+	speech, err := p.tts.Speech(ctx, locale, textKey, text)
+	if err != nil {
+		p.logger.Warn("tts failed, continuing in text-only mode",
+			"error", err,
+			"guide", guide,
+			"text_key", textKey,
+			"locale", locale,
+		)
+		return nil
+	}
+
+	p.logger.Info("playing tts audio", "text_key", textKey, "locale", locale)
+	return player.Stream("tts:"+textKey, io.NopCloser(bytes.NewReader(speech)))
+}
+
+// stepText resolves the i18n key and text spoken for a guide file.
+// It returns an empty text when the file has no associated text.
+func (p *TTSPlayer) stepText(ctx context.Context, guildID, guide, filename string) (string, string) {
+	manifest, err := onboard.LoadManifest(guide)
+	if err != nil {
+		p.logger.Warn("failed to load guide manifest", "error", err, "guide", guide)
+	}
+
+	var textKey string
+	switch key := manifest.Key(filename); key {
+	case "":
+		return "", ""
+	case onboard.AudioPreview:
+		textKey = fmt.Sprintf("onboarding.guides.%s.description", guide)
+	default:
+		textKey = fmt.Sprintf("onboarding.%s_description", key)
+	}
+
+	// T returns the key itself when no translation exists
+	text := p.i18n.T(ctx, guildID, textKey)
+	if text == textKey {
+		return textKey, ""
+	}
+	return textKey, text
+}
+
+// Stop stops the current playback.
+func (p *TTSPlayer) Stop() {
+	if player := p.player(); player != nil {
+		player.Stop()
+	}
+}
+
+// Replay plays the last requested file again.
+func (p *TTSPlayer) Replay() error {
+	p.mu.Lock()
+	guide, filename := p.guide, p.filename
+	p.mu.Unlock()
+
+	if filename == "" {
+		return fmt.Errorf("no audio file to replay")
+	}
+
+	p.Stop()
+
+	// Small delay to ensure previous playback stops
+	time.Sleep(audio.ReplayDelay)
+
+	return p.Play(guide, filename)
+}
+
+// Pause pauses the current playback.
+func (p *TTSPlayer) Pause() {
+	if player := p.player(); player != nil {
+		player.Pause()
+	}
+}
+
+// Resume continues paused playback.
+func (p *TTSPlayer) Resume() {
+	if player := p.player(); player != nil {
+		player.Resume()
+	}
+}
+
+// Playing reports whether recorded or spoken audio is playing.
+func (p *TTSPlayer) Playing() bool {
+	if player := p.player(); player != nil {
+		return player.Playing()
+	}
+	return false
+}
+
+// Disconnect leaves the voice channel.
+func (p *TTSPlayer) Disconnect(ctx context.Context) error {
+	if player := p.player(); player != nil {
+		return player.Disconnect(ctx)
+	}
+	return nil
+}
+
+func (p *TTSPlayer) player() *audio.DCAPlayer {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.dca
+}
@@ -0,0 +1,153 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"welcomebot/internal/audio"
+	"welcomebot/internal/fakes"
+)
+
+type stubProvider struct {
+	calls int
+	err   error
+}
+
+func (p *stubProvider) Synthesize(ctx context.Context, locale, text string) ([]byte, error) {
+	p.calls++
+	if p.err != nil {
+		return nil, p.err
+	}
+	return []byte(locale + ":" + text), nil
+}
+
+func stubEncoder(t *testing.T) {
+	t.Helper()
+
+	prev := encodeDCA
+	encodeDCA = func(audio []byte) ([]byte, error) {
+		return audio, nil
+	}
+	t.Cleanup(func() { encodeDCA = prev })
+}
+
+func TestTTSSpeechCachesPerLocaleAndStep(t *testing.T) {
+	stubEncoder(t)
+	provider := &stubProvider{}
+	tts := NewTTS(provider)
+	ctx := context.Background()
+
+	for n := 0; n < 2; n++ {
+		data, err := tts.Speech(ctx, "en", "onboarding.step1_description", "hello")
+		if err != nil {
+			t.Fatalf("speech: %v", err)
+		}
+		if string(data) != "en:hello" {
+			t.Errorf("unexpected audio %q", data)
+		}
+	}
+	if provider.calls != 1 {
+		t.Errorf("expected 1 synthesis for repeated step, got %d", provider.calls)
+	}
+
+	if _, err := tts.Speech(ctx, "ja", "onboarding.step1_description", "こんにちは"); err != nil {
+		t.Fatalf("speech: %v", err)
+	}
+	if _, err := tts.Speech(ctx, "en", "onboarding.step2_description", "next"); err != nil {
+		t.Fatalf("speech: %v", err)
+	}
+	if provider.calls != 3 {
+		t.Errorf("expected separate synthesis per locale and step, got %d", provider.calls)
+	}
+}
+
+func TestTTSSpeechDoesNotCacheFailures(t *testing.T) {
+	stubEncoder(t)
+	provider := &stubProvider{err: errors.New("provider down")}
+	tts := NewTTS(provider)
+
+	if _, err := tts.Speech(context.Background(), "en", "k", "text"); err == nil {
+		t.Fatal("expected error from failing provider")
+	}
+
+	provider.err = nil
+	if _, err := tts.Speech(context.Background(), "en", "k", "text"); err != nil {
+		t.Fatalf("expected retry to succeed: %v", err)
+	}
+}
+
+func TestTTSPlayerStepText(t *testing.T) {
+	p := NewTTSPlayer(nil, fakes.Logger{}, fakes.I18n{}, nil)
+	ctx := context.Background()
+
+	tests := []struct {
+		filename string
+		wantKey  string
+	}{
+		{"1-intro.dca", "onboarding.step1_description"},
+		{"7-end.dca", "onboarding.step7_description"},
+		{"0-voice-select.dca", "onboarding.guides.tts-test.description"},
+		{"unknown.dca", ""},
+	}
+
+	for _, tt := range tests {
+		key, _ := p.stepText(ctx, "guild-1", "tts-test", tt.filename)
+		if key != tt.wantKey {
+			t.Errorf("stepText(%q) key = %q, want %q", tt.filename, key, tt.wantKey)
+		}
+	}
+}
+
+func TestTTSPlayerFallsBackToTextOnly(t *testing.T) {
+	stubEncoder(t)
+	tts := NewTTS(&stubProvider{err: errors.New("provider down")})
+	p := NewTTSPlayer(nil, fakes.Logger{}, translations{"onboarding.step1_description": "Welcome"}, tts)
+
+	if err := p.Play("tts-test", "1-intro.dca"); err == nil {
+		t.Error("expected error before connecting")
+	}
+
+	// Simulate a connected session without a real voice connection
+	p.dca = audio.NewDCAPlayer(context.Background(), nil, fakes.Logger{})
+	p.ctx = context.Background()
+
+	if err := p.Play("tts-test", "1-intro.dca"); err != nil {
+		t.Errorf("expected provider failure to fall back to text-only, got %v", err)
+	}
+
+	// Steps without text are skipped silently
+	if err := p.Play("tts-test", "2-profile.dca"); err != nil {
+		t.Errorf("expected missing text to be skipped, got %v", err)
+	}
+}
+
+// translations is an i18n stub backed by a fixed key/value map.
+type translations map[string]string
+
+func (tr translations) T(ctx context.Context, guildID, key string) string {
+	if text, ok := tr[key]; ok {
+		return text
+	}
+	return key
+}
+
+func (tr translations) TWithArgs(ctx context.Context, guildID, key string, args map[string]string) string {
+	return tr.T(ctx, guildID, key)
+}
+
+func (translations) SetGuildLanguage(ctx context.Context, guildID, langCode string) error {
+	return nil
+}
+
+func (translations) GetGuildLanguage(ctx context.Context, guildID string) (string, error) {
+	return "en", nil
+}
+
+func (translations) HasGuildLanguage(ctx context.Context, guildID string) bool {
+	return true
+}
+
+func (translations) AvailableLanguages() []string {
+	return []string{"en"}
+}
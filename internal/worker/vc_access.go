@@ -0,0 +1,85 @@
+package worker
+
+import (
+	"slices"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// channelOverwrites returns the permission overwrites for a session's
+// channel. The member, the bot and the extra access roles and members are
+// allowed access; everyone else is hidden by denying @everyone. An access
+// entry for @everyone is ignored so it can't open the channel to the guild.
+func channelOverwrites(guildID, userID, botID string, access int64, accessRoles, accessUsers []string) []*discordgo.PermissionOverwrite {
+	overwrites := []*discordgo.PermissionOverwrite{
+		// Only the user and the bot can see/join
+		{ID: userID, Type: discordgo.PermissionOverwriteTypeMember, Allow: access},
+		{ID: botID, Type: discordgo.PermissionOverwriteTypeMember, Allow: access},
+		// Hide from @everyone
+		{ID: guildID, Type: discordgo.PermissionOverwriteTypeRole, Deny: discordgo.PermissionViewChannel},
+	}
+
+	seen := []string{userID, botID, guildID}
+	for _, roleID := range accessRoles {
+		if roleID == "" || slices.Contains(seen, roleID) {
+			continue
+		}
+		seen = append(seen, roleID)
+		overwrites = append(overwrites, &discordgo.PermissionOverwrite{ID: roleID, Type: discordgo.PermissionOverwriteTypeRole, Allow: access})
+	}
+	for _, memberID := range accessUsers {
+		if memberID == "" || slices.Contains(seen, memberID) {
+			continue
+		}
+		seen = append(seen, memberID)
+		overwrites = append(overwrites, &discordgo.PermissionOverwrite{ID: memberID, Type: discordgo.PermissionOverwriteTypeMember, Allow: access})
+	}
+	return overwrites
+}
+
+// syncedOverwrites returns the permission overwrites for a session's
+// channel synced to its category: the category's own overwrites, with the
+// member, the bot and the extra access roles and members allowed in on top.
+// @everyone keeps whatever the category gives it, so a category the guild
+// can see makes the channel visible to the guild too.
+func syncedOverwrites(category []*discordgo.PermissionOverwrite, guildID, userID, botID string, access int64, accessRoles, accessUsers []string) []*discordgo.PermissionOverwrite {
+	overwrites := make([]*discordgo.PermissionOverwrite, 0, len(category)+2)
+	byID := make(map[string]*discordgo.PermissionOverwrite, len(category))
+	for _, o := range category {
+		o := *o
+		overwrites = append(overwrites, &o)
+		byID[o.ID] = &o
+	}
+
+	for _, grant := range channelOverwrites(guildID, userID, botID, access, accessRoles, accessUsers) {
+		if grant.ID == guildID {
+			continue // The @everyone deny
+		}
+		if o := byID[grant.ID]; o != nil && o.Type == grant.Type {
+			o.Allow |= grant.Allow
+			o.Deny &^= grant.Allow
+			continue
+		}
+		overwrites = append(overwrites, grant)
+	}
+	return overwrites
+}
+
+// sessionOverwrites returns the permission overwrites for the session's
+// channel in categoryID: synced to the category if the guild asked for it,
+// private otherwise. A category whose permissions can't be read gets a
+// private channel, so a failed lookup never opens one up.
+func (s *OnboardingSession) sessionOverwrites(categoryID string, access int64) []*discordgo.PermissionOverwrite {
+	botID := s.session.State.User.ID
+	if s.syncCategory {
+		category, err := s.session.State.Channel(categoryID)
+		if err != nil {
+			category, err = s.session.Channel(categoryID)
+		}
+		if err == nil {
+			return syncedOverwrites(category.PermissionOverwrites, s.guildID, s.userID, botID, access, s.vcAccessRoles, s.vcAccessUsers)
+		}
+		s.logger.Warn("failed to read category permissions, creating a private channel", "category_id", categoryID, "error", err)
+	}
+	return channelOverwrites(s.guildID, s.userID, botID, access, s.vcAccessRoles, s.vcAccessUsers)
+}
@@ -0,0 +1,101 @@
+package worker
+
+import (
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+
+	"welcomebot/internal/fakes"
+)
+
+func TestChannelOverwrites(t *testing.T) {
+	var access int64 = discordgo.PermissionViewChannel | discordgo.PermissionVoiceConnect
+
+	// Without extra access only the member and the bot get in
+	overwrites := channelOverwrites("guild-1", "user-1", "bot-1", access, nil, nil)
+	if len(overwrites) != 3 {
+		t.Fatalf("expected the default three overwrites, got %d", len(overwrites))
+	}
+	if everyone := overwrites[2]; everyone.ID != "guild-1" || everyone.Deny != discordgo.PermissionViewChannel {
+		t.Errorf("expected @everyone to be hidden, got %+v", everyone)
+	}
+
+	// @everyone and repeats of the member or bot are ignored
+	overwrites = channelOverwrites("guild-1", "user-1", "bot-1", access,
+		[]string{"role-staff", "guild-1", ""}, []string{"user-1", "mod-1", "mod-1"})
+	if len(overwrites) != 5 {
+		t.Fatalf("expected two extra overwrites, got %d", len(overwrites))
+	}
+	staff, mod := overwrites[3], overwrites[4]
+	if staff.ID != "role-staff" || staff.Type != discordgo.PermissionOverwriteTypeRole || staff.Allow != access {
+		t.Errorf("unexpected staff overwrite %+v", staff)
+	}
+	if mod.ID != "mod-1" || mod.Type != discordgo.PermissionOverwriteTypeMember || mod.Allow != access {
+		t.Errorf("unexpected member overwrite %+v", mod)
+	}
+	if overwrites[2].Allow != 0 {
+		t.Error("@everyone must not be allowed in")
+	}
+}
+
+func TestSyncedOverwrites(t *testing.T) {
+	var access int64 = discordgo.PermissionViewChannel | discordgo.PermissionVoiceConnect
+	category := []*discordgo.PermissionOverwrite{
+		{ID: "guild-1", Type: discordgo.PermissionOverwriteTypeRole, Allow: discordgo.PermissionViewChannel},
+		{ID: "role-staff", Type: discordgo.PermissionOverwriteTypeRole, Deny: discordgo.PermissionVoiceConnect},
+		{ID: "role-muted", Type: discordgo.PermissionOverwriteTypeRole, Deny: discordgo.PermissionVoiceSpeak},
+	}
+
+	overwrites := syncedOverwrites(category, "guild-1", "user-1", "bot-1", access, []string{"role-staff"}, nil)
+	if len(overwrites) != 5 {
+		t.Fatalf("expected the category's three overwrites plus the member and bot, got %d", len(overwrites))
+	}
+	if everyone := overwrites[0]; everyone.Deny != 0 || everyone.Allow != discordgo.PermissionViewChannel {
+		t.Errorf("expected @everyone to keep the category's overwrite, got %+v", everyone)
+	}
+	if staff := overwrites[1]; staff.Allow != access || staff.Deny != 0 {
+		t.Errorf("expected the access grant to override the category's deny, got %+v", staff)
+	}
+	if member := overwrites[3]; member.ID != "user-1" || member.Allow != access {
+		t.Errorf("expected the member let in, got %+v", member)
+	}
+	if category[1].Allow != 0 {
+		t.Error("the category's overwrites must not be modified")
+	}
+}
+
+func TestSessionOverwrites(t *testing.T) {
+	var access int64 = discordgo.PermissionViewChannel
+	d := fakes.NewDiscord()
+	d.SetNotFound("channels/cat-missing")
+	s, _ := newCleanupSession(t, d, &fakes.AudioPlayer{})
+	s.syncCategory = true
+	s.session.State.GuildAdd(&discordgo.Guild{ID: "guild-1", Channels: []*discordgo.Channel{{
+		ID: "cat-1", GuildID: "guild-1", Type: discordgo.ChannelTypeGuildCategory,
+		PermissionOverwrites: []*discordgo.PermissionOverwrite{
+			{ID: "role-members", Type: discordgo.PermissionOverwriteTypeRole, Allow: discordgo.PermissionViewChannel},
+		},
+	}}})
+
+	hidden := func(overwrites []*discordgo.PermissionOverwrite) bool {
+		for _, o := range overwrites {
+			if o.ID == "guild-1" && o.Deny&discordgo.PermissionViewChannel != 0 {
+				return true
+			}
+		}
+		return false
+	}
+
+	synced := s.sessionOverwrites("cat-1", access)
+	if hidden(synced) || synced[0].ID != "role-members" {
+		t.Errorf("expected the category's overwrites without the @everyone deny, got %+v", synced)
+	}
+	if !hidden(s.sessionOverwrites("cat-missing", access)) {
+		t.Error("expected an unreadable category to get a private channel")
+	}
+
+	s.syncCategory = false
+	if !hidden(s.sessionOverwrites("cat-1", access)) {
+		t.Error("expected a private channel by default")
+	}
+}
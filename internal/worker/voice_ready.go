@@ -0,0 +1,12 @@
+package worker
+
+import "welcomebot/internal/audio"
+
+// SetVoiceReadiness changes how long the session's player waits for its
+// voice connection. A player without a voice connection, like the silent
+// one in a shared VC, ignores it. It must be called before Start.
+func (s *OnboardingSession) SetVoiceReadiness(r audio.Readiness) {
+	if player, ok := s.player.(interface{ SetReadiness(audio.Readiness) }); ok {
+		player.SetReadiness(r)
+	}
+}